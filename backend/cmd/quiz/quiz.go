@@ -1,8 +1,47 @@
+// Command quiz runs the quiz server.
+//
+// Usage:
+//
+//	quiz
+//	quiz create-admin -email <email> -name <name> -password <password>
+//
+// The second form creates an admin account straight in the configured database and exits,
+// for the first admin of a deployment.
 package main
 
-import "quiz.com/quiz/internal"
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"quiz.com/quiz/internal"
+)
 
 func main() {
 	app := internal.App{}
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		createAdmin(&app, os.Args[2:])
+		return
+	}
+
 	app.Init()
 }
+
+// createAdmin creates an admin account from the arguments of the create-admin command
+// Parameters:
+// - app: the application, connected to the database by the command
+// - args: the arguments following create-admin
+func createAdmin(app *internal.App, args []string) {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := flags.String("email", "", "email address of the admin")
+	name := flags.String("name", "", "display name of the admin")
+	password := flags.String("password", "", "password of the admin, at least 8 characters")
+	flags.Parse(args)
+
+	if err := app.CreateAdmin(*email, *name, *password); err != nil {
+		fmt.Fprintln(os.Stderr, "quiz:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("created admin", *email)
+}
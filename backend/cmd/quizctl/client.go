@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Client talks to the HTTP API of a quiz server
+type Client struct {
+	server string       // Base URL of the server, e.g. http://localhost:3000
+	token  string       // Access token sent as bearer token, empty for anonymous requests
+	http   *http.Client // Client used for all requests
+}
+
+// NewClient creates a new Client instance
+// Parameters:
+// - server: base URL of the server
+// - token: access token of an admin, or empty
+// Returns:
+// - A pointer to a new Client instance
+func NewClient(server string, token string) *Client {
+	return &Client{
+		server: strings.TrimSuffix(server, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends a request and fails unless the server answers with a 2xx status
+// Parameters:
+// - method: the HTTP method
+// - path: the path of the endpoint, starting with /
+// - body: the request body, or nil
+// - contentType: the content type of the body, empty if there is none
+// Returns:
+// - []byte: the response body
+// - error: any error encountered while sending the request, or the status of a failed request
+func (c *Client) do(method string, path string, body io.Reader, contentType string) ([]byte, error) {
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s %s: %s", method, path, res.Status)
+	}
+
+	return data, nil
+}
+
+// getJson sends a GET request and decodes the JSON response
+// Parameters:
+// - path: the path of the endpoint
+// - result: the value to decode the response into
+// Returns:
+// - error: any error encountered during the request or decoding
+func (c *Client) getJson(path string, result any) error {
+	data, err := c.do(http.MethodGet, path, nil, "")
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, result)
+}
+
+// sendJson sends a request with a JSON body and decodes the JSON response
+// Parameters:
+// - method: the HTTP method
+// - path: the path of the endpoint
+// - body: the value to encode as the request body
+// - result: the value to decode the response into, or nil to ignore it
+// Returns:
+// - error: any error encountered during the request or decoding
+func (c *Client) sendJson(method string, path string, body any, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	data, err := c.do(method, path, bytes.NewReader(payload), "application/json")
+	if err != nil || result == nil {
+		return err
+	}
+
+	return json.Unmarshal(data, result)
+}
+
+// uploadFile sends a file as the multipart form field "file" together with other form fields and decodes the JSON response
+// Parameters:
+// - path: the path of the endpoint
+// - file: the path of the file to upload
+// - fields: other form fields, empty values are left out
+// - result: the value to decode the response into
+// Returns:
+// - error: any error encountered while reading the file, during the request or decoding
+func (c *Client) uploadFile(path string, file string, fields map[string]string, result any) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	body := bytes.Buffer{}
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+
+	part, err := form.CreateFormFile("file", filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(content); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	data, err := c.do(http.MethodPost, path, &body, form.FormDataContentType())
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,430 @@
+// Command quizctl administers a quiz server through its HTTP API.
+//
+// Usage:
+//
+//	quizctl [-server url] [-token token] <command> [arguments]
+//
+// The server and token default to the QUIZCTL_SERVER and QUIZCTL_TOKEN environment variables.
+// Run quizctl without arguments for the list of commands.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+const usage = `Usage: quizctl [-server url] [-token token] <command> [arguments]
+
+Commands:
+  login -email <email> -password <password>    Print an access token to use with -token
+  quizzes list [-json]                         List all quizzes
+  quizzes seed <file.json>                     Create the quizzes of a JSON list, as printed by quizzes list -json
+  quizzes import [-format gift|moodle] [-name <name>] <file>
+                                               Create a quiz from a Moodle GIFT or XML file
+  quizzes export [-format qti] [-o <file>] <quizId>
+                                               Download a quiz as a QTI package
+  quizzes template [-off] <quizId>             Mark a quiz as a template, or with -off as an ordinary quiz
+  games list                                   List the active games
+  games end <code>                             End a game
+  users create -email <email> -name <name> -password <password> [-role user|admin]
+                                               Create an account
+  migrate                                      Apply pending database migrations
+  backup export [-o <file>]                    Download a backup of the database, without password hashes or media content
+  backup restore <file>                        Insert the documents of a backup the database does not have yet
+
+Importing quizzes requires a token; games, users, quizzes seed, quizzes template, migrate and backup require the token of an admin.
+`
+
+// Names of the game states, indexed by their number
+var gameStates = []string{"lobby", "play", "intermission", "reveal", "end"}
+
+// errUsage is returned when a command is called with the wrong arguments
+var errUsage = errors.New("invalid arguments")
+
+// GameSummary describes an active game, as listed by GET /api/admin/games
+type GameSummary struct {
+	Code     string `json:"code"`
+	QuizId   string `json:"quizId"`
+	QuizName string `json:"quizName"`
+	State    int    `json:"state"`
+	Question int    `json:"question"`
+	Players  int    `json:"players"`
+	Ended    bool   `json:"ended"`
+}
+
+// ImportIssue describes an item of an imported file that could not be converted
+type ImportIssue struct {
+	Item   int    `json:"item"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+func main() {
+	flags := flag.NewFlagSet("quizctl", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	server := flags.String("server", envString("QUIZCTL_SERVER", "http://localhost:3000"), "base URL of the quiz server")
+	token := flags.String("token", os.Getenv("QUIZCTL_TOKEN"), "access token of an admin")
+	flags.Parse(os.Args[1:])
+
+	err := run(NewClient(*server, *token), flags.Args())
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "quizctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes a command
+// Parameters:
+// - client: the client of the quiz server
+// - args: the command and its arguments
+// Returns:
+// - error: errUsage if the command is unknown, or any error encountered while running it
+func run(client *Client, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	command, args := args[0], args[1:]
+	if command == "login" {
+		return login(client, args)
+	}
+
+	if command == "migrate" {
+		if err := client.sendJson(http.MethodPost, "/api/admin/migrations", nil, nil); err != nil {
+			return err
+		}
+
+		fmt.Println("migrations applied")
+		return nil
+	}
+
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch command + " " + subcommand {
+	case "quizzes list":
+		return listQuizzes(client, args)
+	case "quizzes seed":
+		return seedQuizzes(client, args)
+	case "quizzes import":
+		return importQuiz(client, args)
+	case "quizzes export":
+		return exportQuiz(client, args)
+	case "quizzes template":
+		return setTemplate(client, args)
+	case "games list":
+		return listGames(client)
+	case "games end":
+		return endGame(client, args)
+	case "users create":
+		return createUser(client, args)
+	case "backup export":
+		return exportBackup(client, args)
+	case "backup restore":
+		return restoreBackup(client, args)
+	}
+
+	return errUsage
+}
+
+// login signs in and prints the access token
+func login(client *Client, args []string) error {
+	flags := flag.NewFlagSet("login", flag.ContinueOnError)
+	email := flags.String("email", "", "email address of the account")
+	password := flags.String("password", "", "password of the account")
+	if flags.Parse(args) != nil || *email == "" || *password == "" {
+		return errUsage
+	}
+
+	var res struct {
+		Token string      `json:"token"`
+		User  entity.User `json:"user"`
+	}
+	err := client.sendJson(http.MethodPost, "/api/auth/login", map[string]string{
+		"email":    *email,
+		"password": *password,
+	}, &res)
+	if err != nil {
+		return err
+	}
+
+	if res.User.Role != entity.AdminRole {
+		fmt.Fprintln(os.Stderr, "warning:", res.User.Email, "is not an admin")
+	}
+
+	fmt.Println(res.Token)
+	return nil
+}
+
+// listQuizzes prints all quizzes, or their JSON with -json
+func listQuizzes(client *Client, args []string) error {
+	flags := flag.NewFlagSet("quizzes list", flag.ContinueOnError)
+	asJson := flags.Bool("json", false, "print the quizzes as JSON, which quizzes seed reads")
+	if flags.Parse(args) != nil {
+		return errUsage
+	}
+
+	var quizzes []entity.Quiz
+	if err := client.getJson("/api/quizzes", &quizzes); err != nil {
+		return err
+	}
+
+	if *asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(quizzes)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tNAME\tQUESTIONS")
+	for _, quiz := range quizzes {
+		fmt.Fprintf(table, "%s\t%s\t%d\n", quiz.Id.Hex(), quiz.Name, len(quiz.Questions))
+	}
+
+	return table.Flush()
+}
+
+// seedQuizzes creates the quizzes of a JSON file
+func seedQuizzes(client *Client, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	var quizzes []entity.Quiz
+	if err := json.Unmarshal(data, &quizzes); err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	var created []entity.Quiz
+	if err := client.sendJson(http.MethodPost, "/api/admin/quizzes", quizzes, &created); err != nil {
+		return err
+	}
+
+	for _, quiz := range created {
+		fmt.Println("created", quiz.Id.Hex(), quiz.Name)
+	}
+
+	return nil
+}
+
+// importQuiz creates a quiz from a GIFT or Moodle XML file and lists the skipped items
+func importQuiz(client *Client, args []string) error {
+	flags := flag.NewFlagSet("quizzes import", flag.ContinueOnError)
+	format := flags.String("format", "", "gift or moodle, guessed from the file extension if empty")
+	name := flags.String("name", "", "name of the quiz, the file name if empty")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	var res struct {
+		Quiz    entity.Quiz   `json:"quiz"`
+		Skipped []ImportIssue `json:"skipped"`
+	}
+	err := client.uploadFile("/api/quizzes/import?format="+url.QueryEscape(*format), flags.Arg(0), map[string]string{
+		"name": *name,
+	}, &res)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("created", res.Quiz.Id.Hex(), res.Quiz.Name, "with", len(res.Quiz.Questions), "questions")
+	for _, issue := range res.Skipped {
+		fmt.Printf("skipped item %d %q: %s\n", issue.Item, issue.Name, issue.Reason)
+	}
+
+	return nil
+}
+
+// exportQuiz downloads a quiz as a QTI package
+func exportQuiz(client *Client, args []string) error {
+	flags := flag.NewFlagSet("quizzes export", flag.ContinueOnError)
+	format := flags.String("format", "qti", "export format")
+	output := flags.String("o", "", "file to write, quiz-<id>.zip if empty")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	quizId := flags.Arg(0)
+	data, err := client.do(http.MethodGet, "/api/quizzes/"+url.PathEscape(quizId)+"/export?format="+url.QueryEscape(*format), nil, "")
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		*output = "quiz-" + quizId + ".zip"
+	}
+
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Println("wrote", *output)
+	return nil
+}
+
+// listGames prints the active games
+func listGames(client *Client) error {
+	var games []GameSummary
+	if err := client.getJson("/api/admin/games", &games); err != nil {
+		return err
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "CODE\tQUIZ\tSTATE\tQUESTION\tPLAYERS")
+	for _, game := range games {
+		state := "unknown"
+		if game.State >= 0 && game.State < len(gameStates) {
+			state = gameStates[game.State]
+		}
+		if game.Ended {
+			state = "ended"
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%d\n", game.Code, game.QuizName, state, game.Question+1, game.Players)
+	}
+
+	return table.Flush()
+}
+
+// endGame ends a game by its join code
+func endGame(client *Client, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	if _, err := client.do(http.MethodDelete, "/api/admin/games/"+url.PathEscape(args[0]), nil, ""); err != nil {
+		return err
+	}
+
+	fmt.Println("ended game", args[0])
+	return nil
+}
+
+// setTemplate marks a quiz as a template, or as an ordinary quiz with -off
+func setTemplate(client *Client, args []string) error {
+	flags := flag.NewFlagSet("quizzes template", flag.ContinueOnError)
+	off := flags.Bool("off", false, "mark the quiz as an ordinary quiz again")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	quizId := flags.Arg(0)
+	err := client.sendJson(http.MethodPut, "/api/admin/quizzes/"+url.PathEscape(quizId)+"/template", map[string]bool{
+		"template": !*off,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if *off {
+		fmt.Println("quiz", quizId, "is no longer a template")
+	} else {
+		fmt.Println("quiz", quizId, "is a template")
+	}
+
+	return nil
+}
+
+// createUser creates an account
+func createUser(client *Client, args []string) error {
+	flags := flag.NewFlagSet("users create", flag.ContinueOnError)
+	email := flags.String("email", "", "email address of the account")
+	name := flags.String("name", "", "display name of the account")
+	password := flags.String("password", "", "password of the account, at least 8 characters")
+	role := flags.String("role", entity.UserRole, "user or admin")
+	if flags.Parse(args) != nil || *email == "" || *name == "" || *password == "" {
+		return errUsage
+	}
+
+	var user entity.User
+	err := client.sendJson(http.MethodPost, "/api/admin/users", map[string]string{
+		"email":    *email,
+		"name":     *name,
+		"password": *password,
+		"role":     *role,
+	}, &user)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("created", user.Role, user.Id.Hex(), user.Email)
+	return nil
+}
+
+// exportBackup downloads a backup of the database
+func exportBackup(client *Client, args []string) error {
+	flags := flag.NewFlagSet("backup export", flag.ContinueOnError)
+	output := flags.String("o", "", "file to write, quiz-backup-<date>.zip if empty")
+	if flags.Parse(args) != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	data, err := client.do(http.MethodGet, "/api/admin/backup", nil, "")
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		*output = "quiz-backup-" + time.Now().UTC().Format("2006-01-02") + ".zip"
+	}
+
+	if err := os.WriteFile(*output, data, 0o600); err != nil {
+		return err
+	}
+
+	fmt.Println("wrote", *output)
+	return nil
+}
+
+// restoreBackup uploads a backup and prints how many documents of each collection were inserted
+func restoreBackup(client *Client, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	var restored map[string]int
+	if err := client.uploadFile("/api/admin/backup/restore", args[0], nil, &restored); err != nil {
+		return err
+	}
+
+	names := slices.Sorted(maps.Keys(restored))
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "COLLECTION\tRESTORED")
+	for _, name := range names {
+		fmt.Fprintf(table, "%s\t%d\n", name, restored[name])
+	}
+
+	return table.Flush()
+}
+
+// envString returns the value of an environment variable or a default if it is unset
+func envString(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+
+	return fallback
+}
@@ -2,79 +2,508 @@ package internal
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/config"
 	"quiz.com/quiz/internal/controller"
+	"quiz.com/quiz/internal/entity"
 	"quiz.com/quiz/internal/service"
 )
 
+// shutdownTimeout is how long open requests may take to finish once the server is stopped
+const shutdownTimeout = 10 * time.Second
+
 // App struct represents the main application, containing the HTTP server, database connection, and service instances.
 type App struct {
+	config     config.Config   // Runtime configuration loaded from the environment
 	httpServer *fiber.App      // Fiber app instance for handling HTTP requests
 	database   *mongo.Database // MongoDB database connection
 
-	quizService *service.QuizService // QuizService for managing quiz data
-	netService  *service.NetService  // NetService for managing WebSocket connections
+	quizService   *service.QuizService   // QuizService for managing quiz data
+	resultService *service.ResultService // ResultService for storing finished games
+	userService   *service.UserService   // UserService for accounts, authentication and ratings
+
+	tournamentService *service.TournamentService // TournamentService for multi-game tournaments
+	invitationService *service.InvitationService // InvitationService for emailing invitations
+	mediaService      *service.MediaService      // MediaService for uploaded images and sounds
+	netService        *service.NetService        // NetService for managing WebSocket connections
+	editorService     *service.EditorService     // EditorService for the presence of quiz editors
+	commentService    *service.CommentService    // CommentService for review comments on questions
+	quotaService      *service.QuotaService      // QuotaService for the limits of the users' plans
+	challengeService  *service.ChallengeService  // ChallengeService for games hosted on a daily or weekly schedule
+	searchService     *service.SearchService     // SearchService for finding questions across the library
+	backupService     *service.BackupService     // BackupService for backups of the whole database
+	jobService        *service.JobService        // JobService for heavy tasks run in the background
+	translator        service.Translator         // Machine translator of quizzes, nil if none is configured
+	bus               service.MessageBus         // Message bus shared with the other instances, nil for a single instance
 }
 
 // Init initializes the application by setting up the database, services, and HTTP server.
 // It also starts the HTTP server and logs any fatal errors.
 func (a *App) Init() {
-	a.setupDb()       // Setup the database connection
-	a.setupServices() // Setup the services used by the application
-	a.setupHttp()     // Setup the HTTP routes and start the server
+	a.config = config.Load() // Load the configuration from the environment
+	a.setupDb()              // Setup the database connection
+	a.setupServices()        // Setup the services used by the application
+	a.setupHttp()            // Setup the HTTP routes and start the server
+	go a.shutdownOnSignal()  // Close connections cleanly when the server is stopped
+
+	// Start the HTTP server on the configured address, until it is shut down
+	if err := a.httpServer.Listen(a.config.HttpAddr); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// CreateAdmin creates an admin account straight in the database. Registering never makes an admin,
+// so this is how the first admin of a deployment is created; later ones can be created through the admin API.
+// Parameters:
+// - email: the email address used to sign in.
+// - name: the display name.
+// - password: the plain text password, at least 8 characters.
+// Returns:
+// - An error if the input is invalid or the email is taken.
+func (a *App) CreateAdmin(email string, name string, password string) error {
+	a.config = config.Load()
+	a.setupDb()
+
+	transactor := collection.Transaction(a.database.Client(), a.config.MongoTransactions)
+	users := service.User(collection.User(a.database.Collection("users")), transactor, service.Mail(a.config), a.config)
 
-	// Start the HTTP server on port 3000
-	log.Fatal(a.httpServer.Listen(":3000"))
+	_, err := users.CreateUser(context.Background(), email, name, password, entity.AdminRole)
+	return err
+}
+
+// shutdownOnSignal waits for the server to be stopped, then tells every client why it is disconnected
+// before shutting down the HTTP server, so players see the server restarting rather than a dropped connection.
+func (a *App) shutdownOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	fmt.Println("shutting down")
+	a.netService.Shutdown()
+	if err := a.httpServer.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		fmt.Println(err)
+	}
+	a.jobService.Close()
+	if a.bus != nil {
+		a.bus.Close()
+	}
 }
 
 // setupHttp configures the HTTP server and routes for the application.
 func (a *App) setupHttp() {
-	app := fiber.New()  // Create a new Fiber app instance
-	app.Use(cors.New()) // Enable CORS middleware
+	// A proxy header is only read from the trusted proxies, refusing to start if there are none
+	proxies, err := service.Proxies(a.config.ProxyHeader, splitList(a.config.TrustedProxies))
+	if err != nil {
+		panic(err)
+	}
+
+	// Create a new Fiber app instance, reading client addresses from the proxy header if there is one
+	app := fiber.New(fiber.Config{
+		BodyLimit:               a.config.MaxBodySize,
+		ProxyHeader:             a.config.ProxyHeader,
+		EnableIPValidation:      true,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          splitList(a.config.TrustedProxies),
+	})
+	app.Use(controller.ClientAddress(proxies)) // Resolve the client address behind the proxies
+	app.Use(cors.New())                        // Enable CORS middleware
+
+	// Initialize the QuizController and set up the quiz-related routes.
+	// Quizzes with an owner are only open to the users and links they are shared with.
+	quizController := controller.Quiz(a.quizService, a.editorService, a.quotaService, a.jobService, a.translator)
+	optionalUser := controller.OptionalUser(a.userService)
+	requireUser := controller.RequireUser(a.userService)
+	canView := controller.RequireQuizPermission(a.quizService, entity.ViewPermission)
+	canEdit := controller.RequireQuizPermission(a.quizService, entity.EditPermission)
+	app.Get("/api/quizzes", optionalUser, quizController.GetQuizzes)                             // Get all quizzes the user may see
+	app.Get("/api/quizzes/:quizId", optionalUser, canView, quizController.GetQuizById)           // Get a quiz by its ID
+	app.Put("/api/quizzes/:quizId", optionalUser, canEdit, quizController.UpdateQuizById)        // Update a quiz by its ID
+	app.Post("/api/quizzes/:quizId/lint", optionalUser, canView, quizController.LintQuiz)        // Check the questions of a quiz for issues
+	app.Put("/api/quizzes/:quizId/time", optionalUser, canEdit, quizController.SetQuestionTimes) // Give every question of a quiz the same time
+	app.Get("/api/quizzes/:quizId/export", optionalUser, canView, quizController.ExportQuiz)     // Download a quiz as a QTI package
+	app.Get("/api/health", controller.Health(a.bus).GetHealth)                                   // Check the instance and its message bus
+
+	// Webhook URLs are secret, so only signed in users may see or change them
+	app.Get("/api/quizzes/:quizId/webhooks", requireUser, canEdit, quizController.GetWebhooks)    // Get the chat webhooks of a quiz
+	app.Put("/api/quizzes/:quizId/webhooks", requireUser, canEdit, quizController.UpdateWebhooks) // Replace the chat webhooks of a quiz
+	app.Post("/api/quizzes/import", requireUser, quizController.ImportQuiz)                       // Create a quiz owned by the user from a GIFT or Moodle XML file
+
+	// Templates are marked by admins and started from by duplicating them
+	app.Get("/api/templates", requireUser, quizController.GetTemplates)                            // List the quiz templates
+	app.Post("/api/quizzes/:quizId/duplicate", requireUser, canView, quizController.DuplicateQuiz) // Copy a quiz into a new quiz owned by the user
+	app.Post("/api/quizzes/:quizId/translate", requireUser, canView, quizController.TranslateQuiz) // Machine-translate a quiz into a draft owned by the user
+
+	// Only the owner of a quiz may share it
+	app.Get("/api/quizzes/:quizId/shares", requireUser, quizController.GetSharing)               // List who a quiz is shared with
+	app.Put("/api/quizzes/:quizId/shares", requireUser, quizController.ShareQuiz)                // Grant a user a right on a quiz, or take it away
+	app.Post("/api/quizzes/:quizId/links", requireUser, quizController.CreateShareLink)          // Create a link granting a right on a quiz
+	app.Delete("/api/quizzes/:quizId/links/:token", requireUser, quizController.RevokeShareLink) // Stop a share link from working
+
+	// Initialize the QuestionController and set up the search of questions across the library
+	questionController := controller.Question(a.searchService)
+	app.Get("/api/questions/search", requireUser, questionController.SearchQuestions) // Find questions of the quizzes the user may see by meaning
+
+	// Initialize the PublicController and set up the cacheable routes of join pages and link previews
+	publicController := controller.Public(a.quizService, a.userService, a.mediaService, a.netService, a.config.MediaUrl, a.config.PublicUrl)
+	app.Get("/api/public/quizzes/:quizId", publicController.GetQuizMetadata)    // Get the title, question count, author and cover of a public quiz
+	app.Get("/api/public/quizzes/:quizId/cover", publicController.GetQuizCover) // Redirect to the cover image of a public quiz
+	app.Get("/api/previews/quizzes/:quizId", publicController.GetQuizPreview)   // Serve the link preview tags of a public quiz
+	app.Get("/api/previews/games/:code", publicController.GetGamePreview)       // Serve the link preview tags of the join link of a game
+
+	// Initialize the CommentController and set up the review routes, reviewers are named by their account
+	commentController := controller.Comment(a.commentService)
+	app.Get("/api/quizzes/:quizId/comments", requireUser, canView, commentController.GetComments)                       // List the open review comments of a quiz
+	app.Post("/api/quizzes/:quizId/questions/:questionId/comments", requireUser, canView, commentController.AddComment) // Comment on a question
+	app.Put("/api/quizzes/:quizId/comments/:commentId/resolve", requireUser, canEdit, commentController.ResolveComment) // Mark a comment as dealt with
+
+	// Initialize the UserController and set up the account routes
+	userController := controller.User(a.userService)
+	app.Post("/api/auth/register", userController.Register)                         // Create an account
+	app.Post("/api/auth/login", userController.Login)                               // Sign in and receive an access token
+	app.Get("/api/auth/verify", userController.VerifyEmail)                         // Verify an email address through the emailed link
+	app.Post("/api/auth/verify", requireUser, userController.SendVerification)      // Email a new link verifying the user's address
+	app.Get("/api/me", controller.RequireUser(a.userService), userController.GetMe) // Get the signed in user's profile
+	app.Get("/api/users/:userId/profile", userController.GetProfile)                // Get a user's public profile and rating
+
+	// Initialize the QuotaController and set up the route reporting the usage of a plan
+	quotaController := controller.Quota(a.quotaService)
+	app.Get("/api/me/usage", requireUser, quotaController.GetUsage) // Get what the signed in user used of their plan
+
+	// Initialize the ResultController and set up the high-score routes
+	resultController := controller.Result(a.resultService, a.quizService, a.jobService)
+	app.Get("/api/highscores", resultController.GetGlobalHighScores)                                      // Get the best scores across all quizzes
+	app.Get("/api/quizzes/:quizId/highscores", optionalUser, canView, resultController.GetQuizHighScores) // Get the best scores of a quiz
+	app.Get("/api/results/:token", resultController.GetSharedResult)                                      // View the public results page of a game
+	app.Get("/api/quizzes/:quizId/item-analysis", requireUser, canView, resultController.GetItemAnalysis) // Analyse the questions of a quiz over past games
+	app.Get("/api/me/progress", requireUser, resultController.GetProgress)                                // Get the signed in student's attempts at every quiz
+
+	// Initialize the TournamentController and set up the tournament routes; only the owner of a tournament sees its rounds
+	tournamentController := controller.Tournament(a.tournamentService, a.invitationService)
+	app.Post("/api/tournaments", requireUser, tournamentController.CreateTournament)                        // Schedule a tournament owned by the user
+	app.Get("/api/tournaments/:tournamentId", requireUser, tournamentController.GetTournamentById)          // Get a tournament and its rounds
+	app.Get("/api/tournaments/:tournamentId/standings", requireUser, tournamentController.GetStandings)     // Get the tournament leaderboard
+	app.Post("/api/tournaments/:tournamentId/invitations", requireUser, tournamentController.Invite)        // Email invitations to the owned tournament
+	app.Get("/api/tournaments/:tournamentId/invitations", requireUser, tournamentController.GetInvitations) // Get the delivery state of invitations
 
-	// Initialize the QuizController and set up the quiz-related routes
-	quizController := controller.Quiz(a.quizService)
-	app.Get("/api/quizzes", quizController.GetQuizzes)             // Get all quizzes
-	app.Get("/api/quizzes/:quizId", quizController.GetQuizById)    // Get a quiz by its ID
-	app.Put("/api/quizzes/:quizId", quizController.UpdateQuizById) // Update a quiz by its ID
+	// Initialize the ChallengeController and set up the routes of scheduled challenges
+	challengeController := controller.Challenge(a.challengeService)
+	app.Post("/api/challenges", requireUser, challengeController.CreateChallenge)                  // Schedule a daily or weekly challenge
+	app.Get("/api/challenges/:challengeId", challengeController.GetChallengeById)                  // Get a challenge and its hosted games
+	app.Get("/api/challenges/:challengeId/leaderboard", challengeController.GetLeaderboard)        // Get the leaderboard of the current season
+	app.Post("/api/challenges/:challengeId/seasons", requireUser, challengeController.StartSeason) // Start a new season of a challenge
+	app.Delete("/api/challenges/:challengeId", requireUser, challengeController.DeleteChallenge)   // Stop a challenge
+
+	// Initialize the MediaController and set up the media routes
+	mediaController := controller.Media(a.mediaService)
+	app.Post("/api/media", controller.RequireUser(a.userService), mediaController.Upload) // Upload an image or sound
+	app.Get("/api/media/:mediaId", mediaController.Get)                                   // Serve media through a signed URL
+
+	// Initialize the GameController and set up the routes for games without a host and for host dashboards
+	gameController := controller.Game(a.netService)
+	app.Post("/api/games", controller.RequireUser(a.userService), gameController.HostHeadless)                 // Start a game the server runs on autopilot
+	app.Get("/api/host/games/:code/state", controller.RequireUser(a.userService), gameController.GetHostState) // Get the full state of a game for its host
+	app.Get("/api/games/:code/exists", gameController.CheckJoinCode)                                           // Check a join code before joining, limited per address
+
+	// Initialize the AdminController and set up the administration routes used by quizctl
+	adminController := controller.Admin(a.netService, a.quizService, a.userService, a.backupService, a.jobService, func(ctx context.Context) error {
+		return collection.Migrate(ctx, a.database)
+	})
+	admin := app.Group("/api/admin", controller.RequireAdmin(a.userService))
+	admin.Get("/games", adminController.GetGames)                       // List the active games
+	admin.Delete("/games/:code", adminController.EndGame)               // End a game
+	admin.Get("/connections", adminController.GetConnections)           // Count the failed writes to clients
+	admin.Post("/users", adminController.CreateUser)                    // Create an account with a role
+	admin.Post("/quizzes", adminController.CreateQuizzes)               // Seed quizzes
+	admin.Put("/quizzes/:quizId/template", adminController.SetTemplate) // Mark a quiz as a template
+	admin.Put("/users/:userId/quota", quotaController.SetQuota)         // Change the limits of a user's plan
+	admin.Post("/migrations", adminController.Migrate)                  // Apply pending database migrations
+	admin.Get("/backup", adminController.GetBackup)                     // Download a backup of the database
+	admin.Post("/backup/restore", adminController.RestoreBackup)        // Insert the missing documents of a backup
+
+	// The operational metrics of the instance are for admins only
+	app.Get("/api/metrics/cache", controller.RequireAdmin(a.userService), quizController.GetCacheStats) // Get the hit rate of the quiz cache
+
+	// Initialize the JobController and set up the routes reporting background jobs, asked for with ?async=true
+	jobController := controller.Job(a.jobService)
+	app.Get("/api/jobs/:jobId", optionalUser, jobController.GetJob)              // Get the state and progress of a job
+	app.Get("/api/jobs/:jobId/result", optionalUser, jobController.GetJobResult) // Download the result of a finished job
 
 	// Initialize the WebSocket controller and set up the WebSocket route
-	wsController := controller.Ws(a.netService)
-	app.Get("/ws", websocket.New(wsController.Ws)) // WebSocket endpoint for real-time communication
+	wsController := controller.Ws(a.netService, a.editorService, a.config.WsCompressionLevel)
+	app.Get("/ws", websocket.New(wsController.Ws, websocket.Config{
+		EnableCompression: a.config.WsCompression, // Negotiate per-message deflate when enabled
+	})) // WebSocket endpoint for real-time communication
+	app.Get("/ws/leaderboard/:code", websocket.New(wsController.Leaderboard))                                               // Read-only live leaderboard for overlays and big screens
+	app.Get("/ws/editor/:quizId", controller.RequireSocketUser(a.userService), canEdit, websocket.New(wsController.Editor)) // Presence and saved changes of the editors of a quiz
+	app.Get("/ws/admin", controller.RequireSocketAdmin(a.userService), websocket.New(wsController.Dashboard))               // Live stats of the server for ops dashboards
 
 	a.httpServer = app // Assign the Fiber app instance to the App struct
 }
 
 // setupServices initializes the services used by the application.
-// It connects the QuizService with the QuizCollection and the NetService with the QuizService.
+// It connects the QuizService, ResultService, UserService and TournamentService with their collections and the NetService with all of them.
 func (a *App) setupServices() {
-	// Initialize the QuizService with the quizzes collection from the database
-	a.quizService = service.Quiz(collection.Quiz(a.database.Collection("quizzes")))
+	// Connect to the message bus shared with the other instances, if the deployment is scaled out
+	bus, err := a.setupMessageBus()
+	if err != nil {
+		panic(err)
+	}
+	a.bus = bus
+
+	// Initialize the JobService with its workers, keeping the jobs in Redis if one is configured
+	jobStore, err := a.setupJobStore()
+	if err != nil {
+		panic(err)
+	}
+	a.jobService = service.Jobs(jobStore, a.config.JobWorkers, a.config.JobQueueSize, a.config.JobTtl)
+
+	// Initialize the QuizService with the quizzes collection from the database, keeping the caches of all instances fresh over the bus
+	a.quizService = service.Quiz(collection.Quiz(a.database.Collection("quizzes")), a.config.QuizCacheTtl, a.bus)
+
+	// Initialize the machine translator of quizzes, if one is configured
+	translator, err := a.setupTranslator()
+	if err != nil {
+		panic(err)
+	}
+	a.translator = translator
+
+	// Initialize the SearchService with the cache of embeddings and the embedder of questions, if one is configured
+	embedder, err := a.setupEmbedder()
+	if err != nil {
+		panic(err)
+	}
+	a.searchService = service.Search(a.quizService, collection.Embedding(a.database.Collection("embeddings")), embedder)
+
+	// Initialize the BackupService with the whole database and the QuizService to drop its caches after a restore
+	a.backupService = service.Backup(collection.Backup(a.database), a.quizService)
+
+	// Initialize the CommentService with the comments collection and the QuizService to check the questions
+	a.commentService = service.Comment(collection.Comment(a.database.Collection("comments")), a.quizService)
+
+	// Multi-document writes share one transactor on the database's client
+	transactor := collection.Transaction(a.database.Client(), a.config.MongoTransactions)
+
+	// Initialize the ResultService with the results and high-score collections from the database
+	a.resultService = service.Result(
+		collection.Result(a.database.Collection("results")),
+		collection.HighScore(a.database.Collection("highscores")),
+		transactor,
+	)
+
+	// Invitations and verification links are emailed through the same SMTP server
+	mailService := service.Mail(a.config)
+
+	// Initialize the UserService with the users collection from the database
+	a.userService = service.User(collection.User(a.database.Collection("users")), transactor, mailService, a.config)
+
+	// Initialize the TournamentService with the tournaments and results collections from the database
+	a.tournamentService = service.Tournament(
+		collection.Tournament(a.database.Collection("tournaments")),
+		collection.Result(a.database.Collection("results")),
+	)
+
+	// Initialize the InvitationService with the invitations collection and the MailService
+	a.invitationService = service.Invitation(
+		collection.Invitation(a.database.Collection("invitations")),
+		mailService,
+		a.config,
+	)
+
+	// Initialize the MediaService with the media collection and the configured storage for the content
+	storage, err := a.setupMediaStorage()
+	if err != nil {
+		panic(err)
+	}
+	a.mediaService = service.Media(collection.Media(a.database.Collection("media")), storage, a.config)
+
+	// Initialize the AnnouncementService with the webhooks every game is announced in
+	webhooks, err := service.ParseWebhooks(a.config.Webhooks)
+	if err != nil {
+		panic(err)
+	}
+	announcementService := service.Announcement(webhooks)
+
+	// Initialize the EditorService, which only keeps the connected editors in memory
+	a.editorService = service.Editor()
+
+	// Initialize the QuotaService with the daily usage and the default limits of plans
+	a.quotaService = service.Quota(collection.Usage(a.database.Collection("usage")), collection.User(a.database.Collection("users")), a.quizService, a.config)
+
+	// Initialize the GeoIP database used to restrict games to countries, if one is configured
+	geoResolver, err := a.setupGeoResolver()
+	if err != nil {
+		panic(err)
+	}
+
+	// Initialize the stream of game events to an analytics stack, if one is configured
+	streamService, err := a.setupStreamService()
+	if err != nil {
+		panic(err)
+	}
+
+	// Initialize the NetService with the other services
+	a.netService = service.Net(service.NetOptions{
+		QuizService:         a.quizService,
+		ResultService:       a.resultService,
+		UserService:         a.userService,
+		TournamentService:   a.tournamentService,
+		InvitationService:   a.invitationService,
+		AnnouncementService: announcementService,
+		MediaService:        a.mediaService,
+		QuotaService:        a.quotaService,
+		GeoResolver:         geoResolver,
+		StreamService:       streamService,
+	}, a.config)
+	go a.netService.RunHibernation()
+
+	// Initialize the ChallengeService and start hosting the games of due challenges
+	a.challengeService = service.Challenge(
+		collection.Challenge(a.database.Collection("challenges")),
+		collection.Result(a.database.Collection("results")),
+		a.quizService,
+		a.netService,
+		announcementService,
+	)
+	go a.challengeService.Run()
+}
+
+// setupMediaStorage creates the storage driver selected by the configuration.
+// Returns:
+// - The storage and an error if the driver is unknown or misconfigured.
+func (a *App) setupMediaStorage() (collection.MediaStorage, error) {
+	switch a.config.MediaStorage {
+	case collection.GridFSStorageDriver:
+		bucket, err := gridfs.NewBucket(a.database)
+		if err != nil {
+			return nil, err
+		}
+
+		return collection.GridFS(bucket), nil
+	case collection.S3StorageDriver:
+		return collection.S3(a.config.S3Endpoint, a.config.S3Region, a.config.S3Bucket, a.config.S3AccessKey, a.config.S3SecretKey)
+	}
+
+	return nil, fmt.Errorf("unknown media storage %q", a.config.MediaStorage)
+}
+
+// setupGeoResolver loads the GeoIP database selected by the configuration.
+// Returns:
+// - The resolver, nil if no database is configured, and an error if the database cannot be read.
+func (a *App) setupGeoResolver() (service.GeoResolver, error) {
+	if a.config.GeoIpCsv == "" {
+		return nil, nil
+	}
+
+	return service.GeoCsv(a.config.GeoIpCsv)
+}
+
+// setupMessageBus connects to the message bus selected by the configuration.
+// Returns:
+// - The bus, nil if none is configured, and an error if the bus is unknown or misconfigured.
+func (a *App) setupMessageBus() (service.MessageBus, error) {
+	if a.config.Bus == "" {
+		return nil, nil
+	}
+
+	return service.NewMessageBus(a.config.Bus, a.config.BusUrl)
+}
+
+// setupJobStore creates the store of background jobs selected by the configuration.
+// Returns:
+// - The store, in memory if no Redis server is configured, and an error if the Redis address is invalid.
+func (a *App) setupJobStore() (service.JobStore, error) {
+	if a.config.JobRedisUrl == "" {
+		return service.MemoryJobs(), nil
+	}
+
+	return service.RedisJobs(a.config.JobRedisUrl)
+}
+
+// setupTranslator creates the machine translator selected by the configuration.
+// Returns:
+// - The translator, nil if none is configured, and an error if the translator is unknown or misconfigured.
+func (a *App) setupTranslator() (service.Translator, error) {
+	if a.config.Translator == "" {
+		return nil, nil
+	}
+
+	return service.NewTranslator(a.config.Translator, a.config.TranslatorUrl, a.config.TranslatorKey)
+}
+
+// setupEmbedder creates the embedder of questions selected by the configuration.
+// Returns:
+// - The embedder, nil if none is configured, and an error if the embedder is unknown or misconfigured.
+func (a *App) setupEmbedder() (service.Embedder, error) {
+	if a.config.Embedder == "" {
+		return nil, nil
+	}
 
-	// Initialize the NetService with the QuizService
-	a.netService = service.Net(a.quizService)
+	return service.NewEmbedder(a.config.Embedder, a.config.EmbedderUrl, a.config.EmbedderKey, a.config.EmbedderModel)
+}
+
+// setupStreamService connects the stream of game events to the sink selected by the configuration.
+// Returns:
+// - The service, nil if no stream is configured, and an error if the sink is unknown or misconfigured.
+func (a *App) setupStreamService() (*service.StreamService, error) {
+	if a.config.Stream == "" {
+		return nil, nil
+	}
+
+	sink, err := service.NewStreamSink(a.config.Stream, a.config.StreamUrl, a.config.StreamTopic)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Stream(sink), nil
+}
+
+// splitList splits a comma separated configuration value, leaving out empty entries
+// Parameters:
+// - value: the configuration value
+// Returns:
+// - []string: the entries without surrounding whitespace
+func splitList(value string) []string {
+	entries := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
 }
 
 // setupDb establishes a connection to the MongoDB database.
-// It connects to the MongoDB server, selects the "quiz" database, and assigns it to the App struct.
+// It connects to the MongoDB server, selects the configured database, and assigns it to the App struct.
 func (a *App) setupDb() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Connect to the MongoDB server using the specified URI
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	// Connect to the MongoDB server using the configured URI
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(a.config.MongoUri))
 	if err != nil {
 		panic(err) // Panic if the database connection fails
 	}
 
-	// Select the "quiz" database and assign it to the App struct
-	a.database = client.Database("quiz")
+	// Select the configured database and assign it to the App struct
+	a.database = client.Database(a.config.Database)
+
+	// Bring the indexes and schema up to date before anything reads from the database
+	if err := collection.Migrate(ctx, a.database); err != nil {
+		panic(err) // Panic if the database cannot be migrated
+	}
 }
@@ -0,0 +1,107 @@
+package collection
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// duplicateKeyCode is the MongoDB error code of a write conflicting with a unique index
+const duplicateKeyCode = 11000
+
+// backupProjections leave secrets out of the backups of collections, by collection name
+var backupProjections = map[string]bson.M{
+	"users": {"passwordhash": 0}, // Password hashes never leave the database
+}
+
+// BackupCollection reads and writes the raw documents of whole collections, for backups of the database
+type BackupCollection struct {
+	database *mongo.Database
+}
+
+// Backup creates a new BackupCollection instance
+// Parameters:
+// - database: the MongoDB database that is backed up and restored
+// Returns:
+// - A pointer to a new BackupCollection
+func Backup(database *mongo.Database) *BackupCollection {
+	return &BackupCollection{
+		database: database,
+	}
+}
+
+// ExportDocuments passes every document of a collection to a function, without secrets such as password hashes.
+// Whole collections take longer than a single operation may, so only the caller's context bounds the export.
+// Parameters:
+// - ctx: the context bounding the export
+// - name: the name of the collection
+// - write: called with each document in turn; an error stops the export
+// Returns:
+// - int: the number of documents exported
+// - error: any error encountered during the export, or nil if successful
+func (c BackupCollection) ExportDocuments(ctx context.Context, name string, write func(document bson.Raw) error) (int, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	if projection, ok := backupProjections[name]; ok {
+		opts.SetProjection(projection)
+	}
+
+	cursor, err := c.database.Collection(name).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	count := 0
+	for cursor.Next(ctx) {
+		if err := write(cursor.Current); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, cursor.Err()
+}
+
+// RestoreDocuments inserts the documents of a backup a collection does not have yet.
+// Documents whose ID or unique fields are taken are left as they are in the database.
+// Parameters:
+// - ctx: the context bounding the operation
+// - name: the name of the collection
+// - documents: the documents to insert
+// Returns:
+// - int: the number of documents inserted
+// - error: any error other than conflicts with existing documents, or nil if successful
+func (c BackupCollection) RestoreDocuments(ctx context.Context, name string, documents []bson.D) (int, error) {
+	if len(documents) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	inserts := make([]any, len(documents))
+	for i, document := range documents {
+		inserts[i] = document
+	}
+
+	_, err := c.database.Collection(name).InsertMany(ctx, inserts, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return len(documents), nil
+	}
+
+	var bulk mongo.BulkWriteException
+	if !errors.As(err, &bulk) || bulk.WriteConcernError != nil {
+		return 0, err
+	}
+
+	for _, writeErr := range bulk.WriteErrors {
+		if writeErr.Code != duplicateKeyCode {
+			return 0, err
+		}
+	}
+
+	return len(documents) - len(bulk.WriteErrors), nil
+}
@@ -0,0 +1,173 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"quiz.com/quiz/internal/entity"
+)
+
+// ChallengeCollection wraps the MongoDB collection for Challenge entities
+type ChallengeCollection struct {
+	collection *mongo.Collection
+}
+
+// Challenge creates a new ChallengeCollection instance
+// Parameters:
+// - collection: the MongoDB collection where challenges are stored
+// Returns:
+// - A pointer to a new ChallengeCollection
+func Challenge(collection *mongo.Collection) *ChallengeCollection {
+	return &ChallengeCollection{
+		collection: collection,
+	}
+}
+
+// InsertChallenge adds a new challenge to the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - challenge: the challenge entity to be inserted
+// Returns:
+// - error: any error encountered during the insertion, or nil if successful
+func (c ChallengeCollection) InsertChallenge(ctx context.Context, challenge entity.Challenge) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.InsertOne(ctx, challenge)
+	return err
+}
+
+// GetChallengeById retrieves a challenge by its ID from the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the challenge to retrieve
+// Returns:
+// - *entity.Challenge: a pointer to the retrieved challenge entity, or nil if it does not exist
+// - error: any error encountered during the retrieval, or nil if successful
+func (c ChallengeCollection) GetChallengeById(ctx context.Context, id primitive.ObjectID) (*entity.Challenge, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var challenge entity.Challenge
+	err := c.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&challenge)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// GetDueChallenges retrieves the challenges whose next game should have been hosted by now
+// Parameters:
+// - ctx: the context bounding the operation
+// - now: the current time
+// Returns:
+// - []entity.Challenge: the due challenges
+// - error: any error encountered during the retrieval, or nil if successful
+func (c ChallengeCollection) GetDueChallenges(ctx context.Context, now time.Time) ([]entity.Challenge, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, bson.M{"nextrunat": bson.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+
+	challenges := []entity.Challenge{}
+	err = cursor.All(ctx, &challenges)
+	if err != nil {
+		return nil, err
+	}
+
+	return challenges, nil
+}
+
+// ClaimChallengeRun moves the next game of a challenge to a later time, unless another instance already did.
+// Only the instance that claims a run hosts its game.
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the challenge
+// - due: the time the run was due, as read by GetDueChallenges
+// - next: the time the following game is due
+// Returns:
+// - bool: true if the run was claimed
+// - error: any error encountered during the update, or nil if successful
+func (c ChallengeCollection) ClaimChallengeRun(ctx context.Context, id primitive.ObjectID, due time.Time, next time.Time) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id":       id,
+		"nextrunat": due,
+	}, bson.M{
+		"$set": bson.M{"nextrunat": next},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+// AddChallengeRun records a game hosted for a challenge
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the challenge
+// - run: the hosted game
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c ChallengeCollection) AddChallengeRun(ctx context.Context, id primitive.ObjectID, run entity.ChallengeRun) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$push": bson.M{"runs": run},
+	})
+
+	return err
+}
+
+// StartChallengeSeason starts a new season of a challenge, from which the leaderboard counts again
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the challenge
+// - at: when the season starts
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c ChallengeCollection) StartChallengeSeason(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$inc": bson.M{"season": 1},
+		"$set": bson.M{"seasonstartedat": at},
+	})
+
+	return err
+}
+
+// DeleteChallenge removes a challenge, so no more games are hosted for it
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the challenge
+// Returns:
+// - error: any error encountered during the deletion, or nil if successful
+func (c ChallengeCollection) DeleteChallenge(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
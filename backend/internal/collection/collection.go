@@ -0,0 +1,19 @@
+package collection
+
+import (
+	"context"
+	"time"
+)
+
+// operationTimeout bounds every single database operation, so a slow or unreachable
+// database fails requests instead of hanging them
+const operationTimeout = 5 * time.Second
+
+// withTimeout derives the context of a single database operation
+// Parameters:
+// - ctx: the context of the caller
+// Returns:
+// - The derived context, cancelled when the caller's context is or the timeout expires, and its cancel function
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, operationTimeout)
+}
@@ -0,0 +1,106 @@
+package collection
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quiz.com/quiz/internal/entity"
+)
+
+// CommentCollection wraps the MongoDB collection for Comment entities
+type CommentCollection struct {
+	collection *mongo.Collection
+}
+
+// Comment creates a new CommentCollection instance
+// Parameters:
+// - collection: the MongoDB collection where comments are stored
+// Returns:
+// - A pointer to a new CommentCollection
+func Comment(collection *mongo.Collection) *CommentCollection {
+	return &CommentCollection{
+		collection: collection,
+	}
+}
+
+// InsertComment adds a new comment to the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - comment: the comment entity to be inserted
+// Returns:
+// - error: any error encountered during the insertion, or nil if successful
+func (c CommentCollection) InsertComment(ctx context.Context, comment entity.Comment) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.InsertOne(ctx, comment)
+	return err
+}
+
+// GetCommentsByQuiz retrieves the comments on the questions of a quiz
+// Parameters:
+// - ctx: the context bounding the operation
+// - quizId: the ObjectID of the quiz
+// - resolved: whether to include resolved comments
+// Returns:
+// - []entity.Comment: the comments, oldest first
+// - error: any error encountered during the retrieval, or nil if successful
+func (c CommentCollection) GetCommentsByQuiz(ctx context.Context, quizId primitive.ObjectID, resolved bool) ([]entity.Comment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{"quizid": quizId}
+	if !resolved {
+		filter["resolved"] = false
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}})
+	cursor, err := c.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []entity.Comment{}
+	err = cursor.All(ctx, &comments)
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// ResolveComment marks an open comment on a quiz as resolved
+// Parameters:
+// - ctx: the context bounding the operation
+// - quizId: the ObjectID of the quiz the comment belongs to
+// - id: the ObjectID of the comment
+// - by: the name of the user resolving the comment
+// - at: when the comment is resolved
+// Returns:
+// - bool: false if the quiz has no such open comment
+// - error: any error encountered during the update, or nil if successful
+func (c CommentCollection) ResolveComment(ctx context.Context, quizId primitive.ObjectID, id primitive.ObjectID, by string, at time.Time) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id":      id,
+		"quizid":   quizId,
+		"resolved": false,
+	}, bson.M{
+		"$set": bson.M{
+			"resolved":   true,
+			"resolvedby": by,
+			"resolvedat": at,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
@@ -0,0 +1,76 @@
+package collection
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quiz.com/quiz/internal/entity"
+)
+
+// EmbeddingCollection wraps the MongoDB collection for Embedding entities
+type EmbeddingCollection struct {
+	collection *mongo.Collection
+}
+
+// Embedding creates a new EmbeddingCollection instance
+// Parameters:
+// - collection: the MongoDB collection where the embeddings of questions are cached
+// Returns:
+// - A pointer to a new EmbeddingCollection
+func Embedding(collection *mongo.Collection) *EmbeddingCollection {
+	return &EmbeddingCollection{
+		collection: collection,
+	}
+}
+
+// GetEmbeddings retrieves several cached embeddings at once
+// Parameters:
+// - ctx: the context bounding the operation
+// - ids: the keys of the embeddings
+// Returns:
+// - []entity.Embedding: the embeddings that are cached, in no particular order
+// - error: any error encountered during the retrieval, or nil if successful
+func (c EmbeddingCollection) GetEmbeddings(ctx context.Context, ids []string) ([]entity.Embedding, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := []entity.Embedding{}
+	if err := cursor.All(ctx, &embeddings); err != nil {
+		return nil, err
+	}
+
+	return embeddings, nil
+}
+
+// SaveEmbeddings caches embeddings, replacing any cached under the same keys
+// Parameters:
+// - ctx: the context bounding the operation
+// - embeddings: the embeddings to cache
+// Returns:
+// - error: any error encountered during the write, or nil if successful
+func (c EmbeddingCollection) SaveEmbeddings(ctx context.Context, embeddings []entity.Embedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	writes := []mongo.WriteModel{}
+	for _, embedding := range embeddings {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": embedding.Id}).
+			SetReplacement(embedding).
+			SetUpsert(true))
+	}
+
+	_, err := c.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
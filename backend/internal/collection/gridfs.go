@@ -0,0 +1,92 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+)
+
+// GridFSStorage stores media content in a MongoDB GridFS bucket, next to the rest of the data
+type GridFSStorage struct {
+	bucket *gridfs.Bucket
+}
+
+// GridFS creates a new GridFSStorage instance
+// Parameters:
+// - bucket: the GridFS bucket where media content is stored
+// Returns:
+// - A pointer to a new GridFSStorage
+func GridFS(bucket *gridfs.Bucket) *GridFSStorage {
+	return &GridFSStorage{
+		bucket: bucket,
+	}
+}
+
+// Put stores content in the bucket, using the key as the file ID
+// Parameters:
+// - ctx: the context whose deadline bounds the upload
+// - key: the key of the content
+// - content: the content to store
+// - size: the size of the content in bytes
+// - contentType: the MIME type of the content
+// Returns:
+// - error: any error encountered during the upload, or nil if successful
+func (c GridFSStorage) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	upload, err := c.bucket.OpenUploadStreamWithID(key, key)
+	if err != nil {
+		return err
+	}
+
+	upload.SetWriteDeadline(streamDeadline(ctx))
+
+	if _, err := io.Copy(upload, content); err != nil {
+		upload.Abort()
+		return err
+	}
+
+	return upload.Close()
+}
+
+// Get opens content from the bucket
+// Parameters:
+// - ctx: the context whose deadline bounds reading the content
+// - key: the key of the content
+// Returns:
+// - io.ReadCloser: the content
+// - error: ErrContentNotFound if nothing is stored under the key, any other error encountered, or nil if successful
+func (c GridFSStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	download, err := c.bucket.OpenDownloadStream(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, ErrContentNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	download.SetReadDeadline(streamDeadline(ctx))
+	return download, nil
+}
+
+// Delete removes content from the bucket
+// Parameters:
+// - ctx: the context bounding the operation
+// - key: the key of the content
+// Returns:
+// - error: any error encountered during the deletion, or nil if successful
+func (c GridFSStorage) Delete(ctx context.Context, key string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return c.bucket.DeleteContext(ctx, key)
+}
+
+// SignURL always fails, GridFS content can only be served through the app
+// Returns:
+// - ErrSignUnsupported
+func (c GridFSStorage) SignURL(key string, signedAt time.Time, ttl time.Duration) (string, error) {
+	return "", ErrSignUnsupported
+}
@@ -0,0 +1,82 @@
+package collection
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quiz.com/quiz/internal/entity"
+)
+
+// HighScoreCollection wraps the MongoDB collection for HighScore entities
+type HighScoreCollection struct {
+	collection *mongo.Collection
+}
+
+// HighScore creates a new HighScoreCollection instance
+// Parameters:
+// - collection: the MongoDB collection where high scores are stored
+// Returns:
+// - A pointer to a new HighScoreCollection
+func HighScore(collection *mongo.Collection) *HighScoreCollection {
+	return &HighScoreCollection{
+		collection: collection,
+	}
+}
+
+// InsertHighScores adds the scores of a finished game to the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - scores: the high score entities to be inserted
+// Returns:
+// - error: any error encountered during the insertion, or nil if successful
+func (c HighScoreCollection) InsertHighScores(ctx context.Context, scores []entity.HighScore) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if len(scores) == 0 {
+		return nil
+	}
+
+	documents := []interface{}{}
+	for _, score := range scores {
+		documents = append(documents, score)
+	}
+
+	_, err := c.collection.InsertMany(ctx, documents)
+	return err
+}
+
+// GetHighScores retrieves the best scores, optionally restricted to a single quiz
+// Parameters:
+// - ctx: the context bounding the operation
+// - quizId: the ObjectID of the quiz, or primitive.NilObjectID for scores across all quizzes
+// - limit: the maximum number of scores to return
+// Returns:
+// - []entity.HighScore: the scores ordered from highest to lowest
+// - error: any error encountered during the retrieval, or nil if successful
+func (c HighScoreCollection) GetHighScores(ctx context.Context, quizId primitive.ObjectID, limit int64) ([]entity.HighScore, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{}
+	if !quizId.IsZero() {
+		filter["quizid"] = quizId
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "points", Value: -1}}).SetLimit(limit)
+	cursor, err := c.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := []entity.HighScore{}
+	err = cursor.All(ctx, &scores)
+	if err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
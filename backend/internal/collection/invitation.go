@@ -0,0 +1,99 @@
+package collection
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quiz.com/quiz/internal/entity"
+)
+
+// InvitationCollection wraps the MongoDB collection for Invitation entities
+type InvitationCollection struct {
+	collection *mongo.Collection
+}
+
+// Invitation creates a new InvitationCollection instance
+// Parameters:
+// - collection: the MongoDB collection where invitations are stored
+// Returns:
+// - A pointer to a new InvitationCollection
+func Invitation(collection *mongo.Collection) *InvitationCollection {
+	return &InvitationCollection{
+		collection: collection,
+	}
+}
+
+// InsertInvitations adds new invitations to the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - invitations: the invitation entities to be inserted
+// Returns:
+// - error: any error encountered during the insertion, or nil if successful
+func (c InvitationCollection) InsertInvitations(ctx context.Context, invitations []entity.Invitation) error {
+	if len(invitations) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	documents := []interface{}{}
+	for _, invitation := range invitations {
+		documents = append(documents, invitation)
+	}
+
+	_, err := c.collection.InsertMany(ctx, documents)
+	return err
+}
+
+// UpdateInvitation stores the delivery state of an invitation
+// Parameters:
+// - ctx: the context bounding the operation
+// - invitation: the invitation with its updated status, error and send time
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c InvitationCollection) UpdateInvitation(ctx context.Context, invitation entity.Invitation) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": invitation.Id,
+	}, bson.M{
+		"$set": bson.M{
+			"status": invitation.Status,
+			"error":  invitation.Error,
+			"sentat": invitation.SentAt,
+		},
+	})
+
+	return err
+}
+
+// GetInvitationsByTournament retrieves the invitations to a tournament
+// Parameters:
+// - ctx: the context bounding the operation
+// - tournamentId: the ObjectID of the tournament
+// Returns:
+// - []entity.Invitation: the invitations, oldest first
+// - error: any error encountered during the retrieval, or nil if successful
+func (c InvitationCollection) GetInvitationsByTournament(ctx context.Context, tournamentId primitive.ObjectID) ([]entity.Invitation, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}})
+	cursor, err := c.collection.Find(ctx, bson.M{"tournamentid": tournamentId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := []entity.Invitation{}
+	err = cursor.All(ctx, &invitations)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
@@ -0,0 +1,89 @@
+package collection
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"quiz.com/quiz/internal/entity"
+)
+
+// MediaCollection wraps the MongoDB collection for Media entities; their content lives in a MediaStorage
+type MediaCollection struct {
+	collection *mongo.Collection
+}
+
+// Media creates a new MediaCollection instance
+// Parameters:
+// - collection: the MongoDB collection where media metadata is stored
+// Returns:
+// - A pointer to a new MediaCollection
+func Media(collection *mongo.Collection) *MediaCollection {
+	return &MediaCollection{
+		collection: collection,
+	}
+}
+
+// InsertMedia adds the metadata of uploaded media to the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - media: the media entity to be inserted
+// Returns:
+// - error: any error encountered during the insertion, or nil if successful
+func (c MediaCollection) InsertMedia(ctx context.Context, media entity.Media) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.InsertOne(ctx, media)
+	return err
+}
+
+// GetMediaById retrieves the metadata of media by its ID
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the media
+// Returns:
+// - *entity.Media: the media, or nil if it does not exist
+// - error: any error encountered during the retrieval, or nil if successful
+func (c MediaCollection) GetMediaById(ctx context.Context, id primitive.ObjectID) (*entity.Media, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var media entity.Media
+	err := c.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&media)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+// GetMediaByIds retrieves the metadata of several media at once
+// Parameters:
+// - ctx: the context bounding the operation
+// - ids: the ObjectIDs of the media
+// Returns:
+// - []entity.Media: the media that exist, in no particular order
+// - error: any error encountered during the retrieval, or nil if successful
+func (c MediaCollection) GetMediaByIds(ctx context.Context, ids []primitive.ObjectID) ([]entity.Media, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	media := []entity.Media{}
+	if err := cursor.All(ctx, &media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
@@ -0,0 +1,203 @@
+package collection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Migration is a versioned change to the database schema, applied once at startup
+type Migration struct {
+	Version int                                                 // Unique, increasing version of the migration
+	Name    string                                              // Short description of the change
+	Up      func(ctx context.Context, db *mongo.Database) error // Applies the change
+}
+
+// appliedMigration records a migration that has been applied to the database
+type appliedMigration struct {
+	Version   int       `bson:"_id"` // Version of the applied migration
+	Name      string    // Name of the applied migration
+	AppliedAt time.Time // When the migration was applied
+}
+
+// migrations is the registry of every schema change, in the order they are applied.
+// Append new migrations with a higher version; never change or remove applied ones.
+var migrations = []Migration{
+	{Version: 1, Name: "create indexes", Up: createIndexes},
+	{Version: 2, Name: "index result share tokens", Up: indexShareTokens},
+	{Version: 3, Name: "index question comments", Up: indexComments},
+	{Version: 4, Name: "index daily usage", Up: indexDailyUsage},
+	{Version: 5, Name: "index challenges", Up: indexChallenges},
+	{Version: 6, Name: "make account names unique", Up: uniqueAccountNames},
+}
+
+// Migrate applies all migrations that have not been applied to the database yet
+// Parameters:
+// - ctx: the context bounding the migration
+// - db: the database to migrate
+// Returns:
+// - error: the first error encountered, or nil if the database is up to date
+func Migrate(ctx context.Context, db *mongo.Database) error {
+	history := db.Collection("migrations")
+
+	cursor, err := history.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	applied := []appliedMigration{}
+	if err := cursor.All(ctx, &applied); err != nil {
+		return err
+	}
+
+	done := map[int]bool{}
+	for _, migration := range applied {
+		done[migration.Version] = true
+	}
+
+	for _, migration := range migrations {
+		if done[migration.Version] {
+			continue
+		}
+
+		if err := migration.Up(ctx, db); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+
+		_, err := history.InsertOne(ctx, appliedMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("applied migration", migration.Version, migration.Name)
+	}
+
+	return nil
+}
+
+// createIndexes creates the indexes used by the queries of the collections
+func createIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		"quizzes": {
+			{Keys: bson.D{{Key: "name", Value: "text"}}, Options: options.Index().SetName("name_text")},
+			{Keys: bson.D{{Key: "ownerid", Value: 1}}, Options: options.Index().SetName("ownerid")},
+			{Keys: bson.D{{Key: "tags", Value: 1}}, Options: options.Index().SetName("tags")},
+		},
+		"results": {
+			{Keys: bson.D{{Key: "quizid", Value: 1}, {Key: "endedat", Value: -1}}, Options: options.Index().SetName("quizid_endedat")},
+			{Keys: bson.D{{Key: "tournamentid", Value: 1}, {Key: "round", Value: 1}}, Options: options.Index().SetName("tournamentid_round")},
+		},
+		"highscores": {
+			{Keys: bson.D{{Key: "quizid", Value: 1}, {Key: "points", Value: -1}}, Options: options.Index().SetName("quizid_points")},
+			{Keys: bson.D{{Key: "points", Value: -1}}, Options: options.Index().SetName("points")},
+		},
+		"users": {
+			{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetName("email").SetUnique(true)},
+		},
+	}
+
+	for name, models := range indexes {
+		if _, err := db.Collection(name).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("indexes of %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// indexShareTokens indexes the tokens of public results links so they can be looked up
+func indexShareTokens(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("results").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "sharetoken", Value: 1}},
+		Options: options.Index().SetName("sharetoken"),
+	})
+
+	return err
+}
+
+// indexComments indexes the comments on questions by quiz, in the order they are listed
+func indexComments(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("comments").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "quizid", Value: 1}, {Key: "createdat", Value: 1}},
+		Options: options.Index().SetName("quizid_createdat"),
+	})
+
+	return err
+}
+
+// indexDailyUsage makes the daily usage of a user unique, so concurrent counts land on the same document
+func indexDailyUsage(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("usage").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userid", Value: 1}, {Key: "day", Value: 1}},
+		Options: options.Index().SetName("userid_day").SetUnique(true),
+	})
+
+	return err
+}
+
+// indexChallenges creates the indexes used to find due challenges and the results of their seasons
+func indexChallenges(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("challenges").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "nextrunat", Value: 1}},
+		Options: options.Index().SetName("nextrunat"),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("results").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "challengeid", Value: 1}, {Key: "endedat", Value: 1}},
+		Options: options.Index().SetName("challengeid_endedat"),
+	})
+
+	return err
+}
+
+// uniqueAccountNames gives every account a name key and indexes it as unique. The oldest account keeps a name
+// shared by several and the others are numbered, e.g. "Ada 2", so players can no longer pass for each other.
+func uniqueAccountNames(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+	cursor, err := users.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+
+	var accounts []entity.User
+	if err := cursor.All(ctx, &accounts); err != nil {
+		return err
+	}
+
+	taken := map[string]bool{}
+	for _, account := range accounts {
+		base := strings.Join(strings.Fields(account.Name), " ")
+		name := base
+		for n := 2; taken[entity.NameKey(name)]; n++ {
+			name = fmt.Sprintf("%s %d", base, n)
+		}
+		taken[entity.NameKey(name)] = true
+
+		_, err := users.UpdateOne(ctx, bson.M{"_id": account.Id}, bson.M{
+			"$set": bson.M{"name": name, "namekey": entity.NameKey(name)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "namekey", Value: 1}},
+		Options: options.Index().SetName("namekey").SetUnique(true),
+	})
+
+	return err
+}
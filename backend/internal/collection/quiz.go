@@ -6,6 +6,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"quiz.com/quiz/internal/entity"
 )
 
@@ -27,26 +28,76 @@ func Quiz(collection *mongo.Collection) *QuizCollection {
 
 // InsertQuiz adds a new quiz to the collection
 // Parameters:
+// - ctx: the context bounding the operation
 // - quiz: the quiz entity to be inserted
 // Returns:
 // - error: any error encountered during the insertion, or nil if successful
-func (c QuizCollection) InsertQuiz(quiz entity.Quiz) error {
-	_, err := c.collection.InsertOne(context.Background(), quiz)
+func (c QuizCollection) InsertQuiz(ctx context.Context, quiz entity.Quiz) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.InsertOne(ctx, quiz)
 	return err
 }
 
 // GetQuizzes retrieves all quizzes from the collection
+// Parameters:
+// - ctx: the context bounding the operation
 // Returns:
 // - []entity.Quiz: a slice of all quiz entities
 // - error: any error encountered during the retrieval, or nil if successful
-func (c QuizCollection) GetQuizzes() ([]entity.Quiz, error) {
-	cursor, err := c.collection.Find(context.Background(), bson.M{})
+func (c QuizCollection) GetQuizzes(ctx context.Context) ([]entity.Quiz, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var quizzes []entity.Quiz
+	err = cursor.All(ctx, &quizzes)
+	if err != nil {
+		return nil, err
+	}
+
+	return quizzes, nil
+}
+
+// GetVisibleQuestions retrieves the names and question texts of the quizzes a user may view:
+// the open library, and for signed in users their own quizzes, those shared with them and the templates.
+// Only the fields needed to tell who may view a quiz and to compare its questions are loaded.
+// Parameters:
+// - ctx: the context bounding the operation
+// - userId: the ObjectID of the user, zero for anonymous requests
+// Returns:
+// - []entity.Quiz: the quizzes, with only their ID, name, owner, template flag, shares and question IDs and names
+// - error: any error encountered during the retrieval, or nil if successful
+func (c QuizCollection) GetVisibleQuestions(ctx context.Context, userId primitive.ObjectID) ([]entity.Quiz, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	// Quizzes stored before they had owners have no owner field at all
+	visible := bson.A{bson.M{"ownerid": bson.M{"$in": bson.A{nil, primitive.NilObjectID}}}}
+	if !userId.IsZero() {
+		visible = append(visible, bson.M{"ownerid": userId}, bson.M{"shares.userid": userId}, bson.M{"template": true})
+	}
+
+	opts := options.Find().SetProjection(bson.M{
+		"name":           1,
+		"ownerid":        1,
+		"template":       1,
+		"shares":         1,
+		"questions.id":   1,
+		"questions.name": 1,
+	})
+	cursor, err := c.collection.Find(ctx, bson.M{"$or": visible}, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	var quizzes []entity.Quiz
-	err = cursor.All(context.Background(), &quizzes)
+	err = cursor.All(ctx, &quizzes)
 	if err != nil {
 		return nil, err
 	}
@@ -56,12 +107,16 @@ func (c QuizCollection) GetQuizzes() ([]entity.Quiz, error) {
 
 // GetQuizById retrieves a quiz by its ID from the collection
 // Parameters:
+// - ctx: the context bounding the operation
 // - id: the ObjectID of the quiz to retrieve
 // Returns:
 // - *entity.Quiz: a pointer to the retrieved quiz entity
 // - error: any error encountered during the retrieval, or nil if successful
-func (c QuizCollection) GetQuizById(id primitive.ObjectID) (*entity.Quiz, error) {
-	result := c.collection.FindOne(context.Background(), bson.M{"_id": id})
+func (c QuizCollection) GetQuizById(ctx context.Context, id primitive.ObjectID) (*entity.Quiz, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result := c.collection.FindOne(ctx, bson.M{"_id": id})
 
 	var quiz entity.Quiz
 	err := result.Decode(&quiz)
@@ -74,11 +129,15 @@ func (c QuizCollection) GetQuizById(id primitive.ObjectID) (*entity.Quiz, error)
 
 // UpdateQuiz updates an existing quiz in the collection
 // Parameters:
+// - ctx: the context bounding the operation
 // - quiz: the quiz entity with updated data
 // Returns:
 // - error: any error encountered during the update, or nil if successful
-func (c QuizCollection) UpdateQuiz(quiz entity.Quiz) error {
-	_, err := c.collection.UpdateOne(context.Background(), bson.M{
+func (c QuizCollection) UpdateQuiz(ctx context.Context, quiz entity.Quiz) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
 		"_id": quiz.Id,
 	}, bson.M{
 		"$set": quiz,
@@ -86,3 +145,68 @@ func (c QuizCollection) UpdateQuiz(quiz entity.Quiz) error {
 
 	return err
 }
+
+// UpdateQuizWebhooks replaces the webhooks games of a quiz are announced to
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the quiz
+// - webhooks: the new webhooks
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c QuizCollection) UpdateQuizWebhooks(ctx context.Context, id primitive.ObjectID, webhooks []entity.Webhook) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$set": bson.M{"webhooks": webhooks},
+	})
+
+	return err
+}
+
+// UpdateQuizSharing replaces the users and links a quiz is shared with
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the quiz
+// - shares: the users granted rights on the quiz
+// - links: the links granting rights on the quiz
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c QuizCollection) UpdateQuizSharing(ctx context.Context, id primitive.ObjectID, shares []entity.QuizShare, links []entity.QuizShareLink) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$set": bson.M{"shares": shares, "sharelinks": links},
+	})
+
+	return err
+}
+
+// UpdateQuizTemplate marks a quiz as a template or an ordinary quiz
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the quiz
+// - template: whether the quiz is a template
+// Returns:
+// - bool: false if no quiz has the ID
+// - error: any error encountered during the update, or nil if successful
+func (c QuizCollection) UpdateQuizTemplate(ctx context.Context, id primitive.ObjectID, template bool) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$set": bson.M{"template": template},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
@@ -0,0 +1,171 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quiz.com/quiz/internal/entity"
+)
+
+// ResultCollection wraps the MongoDB collection for GameResult entities
+type ResultCollection struct {
+	collection *mongo.Collection
+}
+
+// Result creates a new ResultCollection instance
+// Parameters:
+// - collection: the MongoDB collection where game results are stored
+// Returns:
+// - A pointer to a new ResultCollection
+func Result(collection *mongo.Collection) *ResultCollection {
+	return &ResultCollection{
+		collection: collection,
+	}
+}
+
+// InsertResult adds a new game result to the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - result: the game result entity to be inserted
+// Returns:
+// - error: any error encountered during the insertion, or nil if successful
+func (c ResultCollection) InsertResult(ctx context.Context, result entity.GameResult) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.InsertOne(ctx, result)
+	return err
+}
+
+// GetResultByShareToken retrieves the game result shared under a public results link
+// Parameters:
+// - ctx: the context bounding the operation
+// - token: the secret token of the link
+// Returns:
+// - *entity.GameResult: the result, or nil if no result is shared under the token
+// - error: any error encountered during the retrieval, or nil if successful
+func (c ResultCollection) GetResultByShareToken(ctx context.Context, token string) (*entity.GameResult, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var result entity.GameResult
+	err := c.collection.FindOne(ctx, bson.M{"sharetoken": token}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetResultsByQuiz retrieves the results of every game played of a quiz
+// Parameters:
+// - ctx: the context bounding the operation
+// - quizId: the ObjectID of the quiz
+// Returns:
+// - []entity.GameResult: the results
+// - error: any error encountered during the retrieval, or nil if successful
+func (c ResultCollection) GetResultsByQuiz(ctx context.Context, quizId primitive.ObjectID) ([]entity.GameResult, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, bson.M{"quizid": quizId})
+	if err != nil {
+		return nil, err
+	}
+
+	results := []entity.GameResult{}
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetResultsByTournament retrieves the results of every played round of a tournament
+// Parameters:
+// - ctx: the context bounding the operation
+// - tournamentId: the ObjectID of the tournament
+// Returns:
+// - []entity.GameResult: the results ordered by round
+// - error: any error encountered during the retrieval, or nil if successful
+func (c ResultCollection) GetResultsByTournament(ctx context.Context, tournamentId primitive.ObjectID) ([]entity.GameResult, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "round", Value: 1}})
+	cursor, err := c.collection.Find(ctx, bson.M{"tournamentid": tournamentId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []entity.GameResult{}
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetResultsByChallenge retrieves the results of the games hosted for a challenge since a point in time
+// Parameters:
+// - ctx: the context bounding the operation
+// - challengeId: the ObjectID of the challenge
+// - since: the earliest end of a game to include
+// Returns:
+// - []entity.GameResult: the results ordered by when the games ended
+// - error: any error encountered during the retrieval, or nil if successful
+func (c ResultCollection) GetResultsByChallenge(ctx context.Context, challengeId primitive.ObjectID, since time.Time) ([]entity.GameResult, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "endedat", Value: 1}})
+	cursor, err := c.collection.Find(ctx, bson.M{"challengeid": challengeId, "endedat": bson.M{"$gte": since}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []entity.GameResult{}
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetResultsByUser retrieves the results of every game a signed in user played
+// Parameters:
+// - ctx: the context bounding the operation
+// - userId: the ObjectID of the user
+// Returns:
+// - []entity.GameResult: the results ordered by when the games ended
+// - error: any error encountered during the retrieval, or nil if successful
+func (c ResultCollection) GetResultsByUser(ctx context.Context, userId primitive.ObjectID) ([]entity.GameResult, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "endedat", Value: 1}})
+	cursor, err := c.collection.Find(ctx, bson.M{"players.userid": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []entity.GameResult{}
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,320 @@
+package collection
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constants of AWS Signature Version 4 as used by S3
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3Service         = "s3"
+	s3DateFormat      = "20060102T150405Z"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3MaxPresignTtl   = 7 * 24 * time.Hour // Longest validity S3 accepts for presigned URLs
+)
+
+// S3Storage stores media content in an S3-compatible object store such as AWS S3 or MinIO.
+// Objects are addressed path-style, which every S3-compatible store supports.
+type S3Storage struct {
+	client    *http.Client
+	endpoint  *url.URL // Base URL of the object store, e.g. https://s3.eu-west-1.amazonaws.com
+	region    string   // Region the bucket lives in
+	bucket    string   // Name of the bucket
+	accessKey string   // Access key ID used to sign requests
+	secretKey string   // Secret access key used to sign requests
+}
+
+// S3 creates a new S3Storage instance
+// Parameters:
+// - endpoint: the base URL of the object store
+// - region: the region the bucket lives in
+// - bucket: the name of the bucket
+// - accessKey: the access key ID used to sign requests
+// - secretKey: the secret access key used to sign requests
+// Returns:
+// - A pointer to a new S3Storage and an error if the endpoint or bucket is invalid
+func S3(endpoint string, region string, bucket string, accessKey string, secretKey string) (*S3Storage, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+
+	if bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+
+	return &S3Storage{
+		client:    &http.Client{Timeout: streamTimeout},
+		endpoint:  parsed,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+	}, nil
+}
+
+// Put uploads content as an object
+// Parameters:
+// - ctx: the context bounding the upload
+// - key: the key of the object
+// - content: the content to store
+// - size: the size of the content in bytes
+// - contentType: the MIME type of the content
+// Returns:
+// - error: any error encountered during the upload, or nil if successful
+func (c S3Storage) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectUrl(key).String(), content)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+// Get downloads an object
+// Parameters:
+// - ctx: the context bounding the download
+// - key: the key of the object
+// Returns:
+// - io.ReadCloser: the content of the object
+// - error: ErrContentNotFound if the object does not exist, any other error encountered, or nil if successful
+func (c S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.objectUrl(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+// Delete removes an object
+// Parameters:
+// - ctx: the context bounding the operation
+// - key: the key of the object
+// Returns:
+// - error: any error encountered during the deletion, or nil if successful
+func (c S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.objectUrl(key).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+// SignURL returns a presigned URL downloading an object straight from the object store
+// Parameters:
+// - key: the key of the object
+// - signedAt: the time the URL is valid from
+// - ttl: how long the URL is valid, at most seven days
+// Returns:
+// - string: the presigned URL
+// - error: always nil
+func (c S3Storage) SignURL(key string, signedAt time.Time, ttl time.Duration) (string, error) {
+	return c.presign(http.MethodGet, c.objectUrl(key), signedAt, min(ttl, s3MaxPresignTtl)), nil
+}
+
+// objectUrl returns the path-style URL of an object
+func (c S3Storage) objectUrl(key string) *url.URL {
+	object := *c.endpoint
+	object.Path = object.Path + "/" + c.bucket + "/" + key
+	return &object
+}
+
+// do signs and sends a request, turning error responses into errors
+func (c S3Storage) do(req *http.Request) (*http.Response, error) {
+	c.sign(req, time.Now())
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrContentNotFound
+	}
+
+	if res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		res.Body.Close()
+		return nil, fmt.Errorf("S3 %s %s: %s %s", req.Method, req.URL.Path, res.Status, body)
+	}
+
+	return res, nil
+}
+
+// sign adds an AWS Signature Version 4 authorization header to a request. The payload is left
+// unsigned, so content can be streamed without hashing it up front.
+func (c S3Storage) sign(req *http.Request, now time.Time) {
+	date := now.UTC().Format(s3DateFormat)
+	req.Header.Set("X-Amz-Date", date)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": s3UnsignedPayload,
+		"x-amz-date":           date,
+	}
+
+	signedHeaders, canonicalHeaders := canonicalS3Headers(headers)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		canonicalS3Query(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	scope := c.scope(now)
+	signature := c.signature(now, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, c.accessKey, scope, signedHeaders, signature))
+}
+
+// presign returns a URL carrying an AWS Signature Version 4 in its query, so it works without credentials
+func (c S3Storage) presign(method string, target *url.URL, signedAt time.Time, ttl time.Duration) string {
+	scope := c.scope(signedAt)
+
+	query := target.Query()
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", c.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", signedAt.UTC().Format(s3DateFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	signedHeaders, canonicalHeaders := canonicalS3Headers(map[string]string{"host": target.Host})
+	canonicalRequest := strings.Join([]string{
+		method,
+		s3EscapePath(target.Path),
+		canonicalS3Query(query),
+		canonicalHeaders,
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	signed := *target
+	signed.RawQuery = canonicalS3Query(query) + "&X-Amz-Signature=" + c.signature(signedAt, scope, canonicalRequest)
+	return signed.String()
+}
+
+// scope returns the credential scope of a signature made at the given time
+func (c S3Storage) scope(now time.Time) string {
+	return now.UTC().Format("20060102") + "/" + c.region + "/" + s3Service + "/aws4_request"
+}
+
+// signature signs a canonical request with a key derived from the secret key, date, region and service
+func (c S3Storage) signature(now time.Time, scope string, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.UTC().Format(s3DateFormat),
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSha256([]byte("AWS4"+c.secretKey), now.UTC().Format("20060102"))
+	key = hmacSha256(key, c.region)
+	key = hmacSha256(key, s3Service)
+	key = hmacSha256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSha256(key, stringToSign))
+}
+
+// hmacSha256 computes the HMAC-SHA256 of data with a key
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalS3Headers returns the list of signed header names and the canonical header block of lowercase headers
+func canonicalS3Headers(headers map[string]string) (string, string) {
+	names := []string{}
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonical := ""
+	for _, name := range names {
+		canonical += name + ":" + strings.TrimSpace(headers[name]) + "\n"
+	}
+
+	return strings.Join(names, ";"), canonical
+}
+
+// canonicalS3Query returns the query sorted by name with names and values escaped as S3 expects
+func canonicalS3Query(query url.Values) string {
+	names := []string{}
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := []string{}
+	for _, name := range names {
+		values := append([]string{}, query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(name)+"="+s3Escape(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// s3EscapePath escapes every segment of a path, keeping the slashes
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// s3Escape percent-encodes everything but the unreserved characters of RFC 3986
+func s3Escape(value string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '-' || b == '.' || b == '_' || b == '~' {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+
+	return escaped.String()
+}
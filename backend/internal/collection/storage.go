@@ -0,0 +1,45 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Names of the available media storage drivers, selected with the QUIZ_MEDIA_STORAGE setting
+const (
+	GridFSStorageDriver = "gridfs"
+	S3StorageDriver     = "s3"
+)
+
+// streamTimeout bounds uploading or downloading media content when the context has no deadline
+const streamTimeout = 30 * time.Second
+
+// ErrSignUnsupported is returned by storages that cannot hand out URLs to their content
+var ErrSignUnsupported = errors.New("storage cannot sign URLs")
+
+// ErrContentNotFound is returned when no content is stored under a key
+var ErrContentNotFound = errors.New("content not found")
+
+// MediaStorage stores the content of uploaded media by key
+type MediaStorage interface {
+	// Put stores content of a known size under a key
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Get opens the content stored under a key; the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content stored under a key
+	Delete(ctx context.Context, key string) error
+	// SignURL returns a URL serving the content directly from the storage, valid from signedAt for ttl,
+	// or ErrSignUnsupported if the content can only be served through the app
+	SignURL(key string, signedAt time.Time, ttl time.Duration) (string, error)
+}
+
+// streamDeadline returns when streaming content must be done: the deadline of the context, or streamTimeout from now
+func streamDeadline(ctx context.Context) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+
+	return time.Now().Add(streamTimeout)
+}
@@ -0,0 +1,145 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"quiz.com/quiz/internal/entity"
+)
+
+// TournamentCollection wraps the MongoDB collection for Tournament entities
+type TournamentCollection struct {
+	collection *mongo.Collection
+}
+
+// Tournament creates a new TournamentCollection instance
+// Parameters:
+// - collection: the MongoDB collection where tournaments are stored
+// Returns:
+// - A pointer to a new TournamentCollection
+func Tournament(collection *mongo.Collection) *TournamentCollection {
+	return &TournamentCollection{
+		collection: collection,
+	}
+}
+
+// InsertTournament adds a new tournament to the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - tournament: the tournament entity to be inserted
+// Returns:
+// - error: any error encountered during the insertion, or nil if successful
+func (c TournamentCollection) InsertTournament(ctx context.Context, tournament entity.Tournament) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.InsertOne(ctx, tournament)
+	return err
+}
+
+// GetTournamentById retrieves a tournament by its ID from the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the tournament to retrieve
+// Returns:
+// - *entity.Tournament: a pointer to the retrieved tournament entity, or nil if it does not exist
+// - error: any error encountered during the retrieval, or nil if successful
+func (c TournamentCollection) GetTournamentById(ctx context.Context, id primitive.ObjectID) (*entity.Tournament, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var tournament entity.Tournament
+	err := c.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&tournament)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tournament, nil
+}
+
+// SetRoundResult links a played round of a tournament to its stored game result
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the tournament
+// - round: the index of the round
+// - resultId: the ObjectID of the game result
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c TournamentCollection) SetRoundResult(ctx context.Context, id primitive.ObjectID, round int, resultId primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$set": bson.M{fmt.Sprintf("rounds.%d.resultid", round): resultId},
+	})
+
+	return err
+}
+
+// ReserveRound claims an unplayed round of a tournament for a game, unless another game claimed it since the stale time
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the tournament
+// - round: the index of the round
+// - code: the join code of the game claiming the round
+// - now: the current time, stored as the time of the claim
+// - staleBefore: claims made before this time are given up and may be taken over
+// Returns:
+// - bool: whether the round was claimed, false if it was played or another game holds it
+// - error: any error encountered during the update, or nil if successful
+func (c TournamentCollection) ReserveRound(ctx context.Context, id primitive.ObjectID, round int, code string, now time.Time, staleBefore time.Time) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	prefix := fmt.Sprintf("rounds.%d.", round)
+	result, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id":               id,
+		prefix + "resultid": bson.M{"$in": bson.A{nil, primitive.NilObjectID}},
+		"$or": bson.A{
+			bson.M{prefix + "gamecode": bson.M{"$in": bson.A{nil, ""}}},
+			bson.M{prefix + "reservedat": bson.M{"$lt": staleBefore}},
+		},
+	}, bson.M{
+		"$set": bson.M{prefix + "gamecode": code, prefix + "reservedat": now},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// ReleaseRound gives up the claim of a game on a round that was not played, so another game can be hosted for it
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the tournament
+// - round: the index of the round
+// - code: the join code of the game that claimed the round
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c TournamentCollection) ReleaseRound(ctx context.Context, id primitive.ObjectID, round int, code string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	prefix := fmt.Sprintf("rounds.%d.", round)
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id":               id,
+		prefix + "gamecode": code,
+		prefix + "resultid": bson.M{"$in": bson.A{nil, primitive.NilObjectID}},
+	}, bson.M{
+		"$set": bson.M{prefix + "gamecode": ""},
+	})
+
+	return err
+}
@@ -0,0 +1,52 @@
+package collection
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transactor runs groups of writes atomically in MongoDB transactions
+type Transactor struct {
+	client  *mongo.Client // Client used to start sessions
+	enabled bool          // Whether transactions are used; they require a replica set
+}
+
+// Transaction creates a new Transactor instance
+// Parameters:
+// - client: the MongoDB client the collections belong to
+// - enabled: whether to use transactions; standalone servers do not support them
+// Returns:
+// - A pointer to a new Transactor
+func Transaction(client *mongo.Client, enabled bool) *Transactor {
+	return &Transactor{
+		client:  client,
+		enabled: enabled,
+	}
+}
+
+// Run executes fn in a transaction, committing its writes only if it succeeds.
+// The transaction is retried on transient errors, so fn must be safe to run again.
+// When transactions are disabled, or t is nil, fn runs without one.
+// Parameters:
+// - ctx: the context bounding the transaction
+// - fn: the writes to run, which must pass the context it receives to every collection call
+// Returns:
+// - error: the error returned by fn or by the transaction, or nil if it was committed
+func (t *Transactor) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if t == nil || !t.enabled {
+		return fn(ctx)
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(ctx)
+	})
+
+	return err
+}
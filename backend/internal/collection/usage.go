@@ -0,0 +1,71 @@
+package collection
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quiz.com/quiz/internal/entity"
+)
+
+// UsageCollection wraps the MongoDB collection for DailyUsage entities
+type UsageCollection struct {
+	collection *mongo.Collection
+}
+
+// Usage creates a new UsageCollection instance
+// Parameters:
+// - collection: the MongoDB collection where daily usage is counted
+// Returns:
+// - A pointer to a new UsageCollection
+func Usage(collection *mongo.Collection) *UsageCollection {
+	return &UsageCollection{
+		collection: collection,
+	}
+}
+
+// GetDailyUsage retrieves what a user did on a day
+// Parameters:
+// - ctx: the context bounding the operation
+// - userId: the ObjectID of the user
+// - day: the day in UTC, formatted as 2006-01-02
+// Returns:
+// - entity.DailyUsage: the counts, zero if the user did nothing that day
+// - error: any error encountered during the retrieval, or nil if successful
+func (c UsageCollection) GetDailyUsage(ctx context.Context, userId primitive.ObjectID, day string) (entity.DailyUsage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var usage entity.DailyUsage
+	err := c.collection.FindOne(ctx, bson.M{"userid": userId, "day": day}).Decode(&usage)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entity.DailyUsage{UserId: userId, Day: day}, nil
+	}
+
+	return usage, err
+}
+
+// IncrementGames counts a game hosted by a user on a day
+// Parameters:
+// - ctx: the context bounding the operation
+// - userId: the ObjectID of the user
+// - day: the day in UTC, formatted as 2006-01-02
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c UsageCollection) IncrementGames(ctx context.Context, userId primitive.ObjectID, day string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"userid": userId,
+		"day":    day,
+	}, bson.M{
+		"$inc":         bson.M{"games": 1},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+	}, options.Update().SetUpsert(true))
+
+	return err
+}
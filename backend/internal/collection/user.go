@@ -0,0 +1,187 @@
+package collection
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"quiz.com/quiz/internal/entity"
+)
+
+// UserCollection wraps the MongoDB collection for User entities
+type UserCollection struct {
+	collection *mongo.Collection
+}
+
+// User creates a new UserCollection instance
+// Parameters:
+// - collection: the MongoDB collection where users are stored
+// Returns:
+// - A pointer to a new UserCollection
+func User(collection *mongo.Collection) *UserCollection {
+	return &UserCollection{
+		collection: collection,
+	}
+}
+
+// InsertUser adds a new user to the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - user: the user entity to be inserted
+// Returns:
+// - error: any error encountered during the insertion, or nil if successful
+func (c UserCollection) InsertUser(ctx context.Context, user entity.User) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.InsertOne(ctx, user)
+	return err
+}
+
+// GetUserById retrieves a user by its ID from the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the user to retrieve
+// Returns:
+// - *entity.User: a pointer to the retrieved user entity, or nil if it does not exist
+// - error: any error encountered during the retrieval, or nil if successful
+func (c UserCollection) GetUserById(ctx context.Context, id primitive.ObjectID) (*entity.User, error) {
+	return c.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetUserByEmail retrieves a user by its email address from the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - email: the email address of the user to retrieve
+// Returns:
+// - *entity.User: a pointer to the retrieved user entity, or nil if it does not exist
+// - error: any error encountered during the retrieval, or nil if successful
+func (c UserCollection) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return c.findOne(ctx, bson.M{"email": email})
+}
+
+// GetUserByNameKey retrieves a user by the folded form of their display name
+// Parameters:
+// - ctx: the context bounding the operation
+// - nameKey: the name folded by entity.NameKey
+// Returns:
+// - *entity.User: a pointer to the retrieved user entity, or nil if it does not exist
+// - error: any error encountered during the retrieval, or nil if successful
+func (c UserCollection) GetUserByNameKey(ctx context.Context, nameKey string) (*entity.User, error) {
+	return c.findOne(ctx, bson.M{"namekey": nameKey})
+}
+
+// GetUsersByIds retrieves all users with the given IDs from the collection
+// Parameters:
+// - ctx: the context bounding the operation
+// - ids: the ObjectIDs of the users to retrieve
+// Returns:
+// - []entity.User: the users that exist
+// - error: any error encountered during the retrieval, or nil if successful
+func (c UserCollection) GetUsersByIds(ctx context.Context, ids []primitive.ObjectID) ([]entity.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	var users []entity.User
+	err = cursor.All(ctx, &users)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// UpdateRating stores a user's new rating and increments their number of rated games
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the user
+// - rating: the new rating
+// Returns:
+// - error: any error encountered during the update, or nil if successful
+func (c UserCollection) UpdateRating(ctx context.Context, id primitive.ObjectID, rating int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$set": bson.M{"rating": rating},
+		"$inc": bson.M{"gamesplayed": 1},
+	})
+
+	return err
+}
+
+// UpdateQuota stores the limits of a user's plan
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the user
+// - quota: the limits, nil for the deployment's defaults
+// Returns:
+// - bool: false if no user has the ID
+// - error: any error encountered during the update, or nil if successful
+func (c UserCollection) UpdateQuota(ctx context.Context, id primitive.ObjectID, quota *entity.Quota) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$set": bson.M{"quota": quota},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// VerifyEmail marks the email address of a user as verified, if it is still their address
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ObjectID of the user
+// - email: the address that was verified
+// Returns:
+// - bool: false if no user has the ID and address
+// - error: any error encountered during the update, or nil if successful
+func (c UserCollection) VerifyEmail(ctx context.Context, id primitive.ObjectID, email string) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := c.collection.UpdateOne(ctx, bson.M{
+		"_id":   id,
+		"email": email,
+	}, bson.M{
+		"$set": bson.M{"emailverified": true},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// findOne retrieves the first user matching a filter
+func (c UserCollection) findOne(ctx context.Context, filter bson.M) (*entity.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var user entity.User
+	err := c.collection.FindOne(ctx, filter).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
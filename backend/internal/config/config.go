@@ -0,0 +1,245 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the runtime configuration of the application, read from environment variables
+type Config struct {
+	HttpAddr string // Address the HTTP server listens on
+	MongoUri string // Connection string of the MongoDB server
+	Database string // Name of the MongoDB database
+
+	MongoTransactions bool // Whether multi-document writes use transactions; requires a replica set
+
+	MaxBodySize int // Largest request body accepted, in bytes; raise it to restore large backups
+
+	WsCompression        bool          // Whether per-message deflate is negotiated on WebSocket connections
+	WsCompressionLevel   int           // Flate compression level used for outgoing messages (1-9)
+	WsCompressionMinSize int           // Packets smaller than this many bytes are sent uncompressed
+	WsWriteTimeout       time.Duration // Longest a single write to a client may take before the client is disconnected; zero for no limit
+	GameFanOutLimit      int           // Most packets a game broadcasts per second before optional updates are thinned out; zero for no limit
+	SessionTtl           time.Duration // How long a disconnected player may resume their place in the game; zero disables resuming
+	HibernateAfter       time.Duration // How long a lobby may go without packets from its host and players before it hibernates; zero disables hibernation
+	HibernateDir         string        // Directory hibernated lobbies are written to
+
+	AuthSecret   string        // Key used to sign access tokens; random per process if unset
+	AuthTokenTtl time.Duration // How long issued access tokens are valid
+
+	AdminFeedInterval time.Duration // How often the admin dashboard feed sends the stats of the server
+
+	QuizCacheTtl time.Duration // How long quizzes are cached in memory; zero disables the cache
+
+	ResultShareTtl time.Duration // How long public results links work; zero disables them
+
+	PublicUrl       string // Base URL of the frontend, used in links sent to players
+	ApiUrl          string // Base URL the API is reached at, used in emailed links that call it
+	SmtpHost        string // Host name of the SMTP server; email is disabled if empty
+	SmtpPort        int    // Port of the SMTP server
+	SmtpUser        string // User name for SMTP authentication, empty to send without authentication
+	SmtpPassword    string // Password for SMTP authentication
+	SmtpFrom        string // Sender address of emails
+	InvitationLimit int    // Most invitation emails a sender may have sent per day; zero for no limit
+
+	MediaUrl    string        // Base URL media is served from, e.g. a CDN in front of the server
+	MediaUrlTtl time.Duration // Minimum time signed media URLs stay valid
+
+	MediaStorage string // Where media content is stored, "gridfs" or "s3"
+	S3Endpoint   string // Base URL of the S3-compatible object store, e.g. https://s3.eu-west-1.amazonaws.com
+	S3Region     string // Region of the bucket
+	S3Bucket     string // Bucket media content is stored in
+	S3AccessKey  string // Access key ID of the object store
+	S3SecretKey  string // Secret access key of the object store
+
+	Webhooks string // Comma separated kind=url pairs of Slack or Discord webhooks every game is announced in
+
+	GameCodeAlphabet string        // Characters game join codes are drawn from
+	GameCodeLength   int           // Number of characters of game join codes, at most 16
+	RandomSeed       int           // Seed of the random source of games, for reproducible runs; zero seeds from the time
+	JoinCheckLimit   int           // Most join codes a client address may look up per minute before joining; zero for no limit
+	JoinAttemptLimit int           // Most unknown join codes a client address may try to join per minute before it is locked out; zero for no limit
+	JoinLockout      time.Duration // How long an address that tried too many unknown join codes is locked out
+
+	AnswerGrace time.Duration // How long answers are still accepted after the timer of a question runs out
+
+	QuotaQuizzes     int // Most quizzes a user can own unless their plan says otherwise; zero for no limit
+	QuotaPlayers     int // Most players who can join a game unless the host's plan says otherwise; zero for no limit
+	QuotaGamesPerDay int // Most games a user can host per day unless their plan says otherwise; zero for no limit
+
+	GeoIpCsv       string // Path of a CSV GeoIP country database, for games restricted to countries; empty to disable
+	ProxyHeader    string // Header holding the client address set by a reverse proxy, e.g. X-Forwarded-For; empty to use the peer address
+	TrustedProxies string // Comma separated addresses and CIDR ranges of the proxies the header is read from; required with a proxy header
+
+	Stream      string // Where answers and reveals are streamed for analytics, "nats" or "kafka"; empty to disable
+	StreamUrl   string // nats:// URL of the NATS server, or base URL of the Kafka REST Proxy
+	StreamTopic string // NATS subject or Kafka topic the events are published to
+
+	Translator    string // Machine translator of quizzes, "libretranslate" or "deepl"; empty to disable translation
+	TranslatorUrl string // Base URL of the translation API
+	TranslatorKey string // API key of the translator
+
+	Embedder      string // Service computing the embeddings of questions for semantic search, "openai"; empty to search by keywords
+	EmbedderUrl   string // Base URL of the embeddings API, e.g. https://api.openai.com or a compatible server
+	EmbedderKey   string // API key of the embeddings API, empty for servers that need none
+	EmbedderModel string // Embedding model to ask for
+
+	Bus    string // Message bus shared by the instances of a scaled out deployment, "nats"; empty for a single instance
+	BusUrl string // nats:// URL of the NATS server of the bus
+
+	JobWorkers   int           // Number of background jobs, such as imports and reports, run at once
+	JobQueueSize int           // Most background jobs that may wait for a worker before new ones are turned away
+	JobTtl       time.Duration // How long the status and result of a background job are kept after it finished
+	JobRedisUrl  string        // redis:// URL of a Redis server the jobs are kept on; empty to keep them in memory
+}
+
+// Load reads the configuration from the environment, falling back to defaults for unset values
+// Returns:
+// - The loaded configuration
+func Load() Config {
+	config := Config{
+		HttpAddr: envString("QUIZ_HTTP_ADDR", ":3000"),
+		MongoUri: envString("QUIZ_MONGO_URI", "mongodb://localhost:27017"),
+		Database: envString("QUIZ_DATABASE", "quiz"),
+
+		MongoTransactions: envBool("QUIZ_MONGO_TRANSACTIONS", false),
+
+		MaxBodySize: envInt("QUIZ_MAX_BODY_SIZE", 4<<20),
+
+		WsCompression:        envBool("QUIZ_WS_COMPRESSION", false),
+		WsCompressionLevel:   envInt("QUIZ_WS_COMPRESSION_LEVEL", 1),
+		WsCompressionMinSize: envInt("QUIZ_WS_COMPRESSION_MIN_SIZE", 512),
+		WsWriteTimeout:       envDuration("QUIZ_WS_WRITE_TIMEOUT", 5*time.Second),
+		GameFanOutLimit:      envInt("QUIZ_GAME_FANOUT_LIMIT", 20000),
+		SessionTtl:           envDuration("QUIZ_SESSION_TTL", 2*time.Minute),
+		HibernateAfter:       envDuration("QUIZ_HIBERNATE_AFTER", 30*time.Minute),
+		HibernateDir:         envString("QUIZ_HIBERNATE_DIR", "hibernated"),
+
+		AuthSecret:   os.Getenv("QUIZ_AUTH_SECRET"),
+		AuthTokenTtl: envDuration("QUIZ_AUTH_TOKEN_TTL", 7*24*time.Hour),
+
+		AdminFeedInterval: envDuration("QUIZ_ADMIN_FEED_INTERVAL", 5*time.Second),
+
+		QuizCacheTtl: envDuration("QUIZ_CACHE_TTL", time.Minute),
+
+		ResultShareTtl: envDuration("QUIZ_RESULT_SHARE_TTL", 7*24*time.Hour),
+
+		PublicUrl:       envString("QUIZ_PUBLIC_URL", "http://localhost:5173"),
+		ApiUrl:          envString("QUIZ_API_URL", "http://localhost:3000"),
+		SmtpHost:        envString("QUIZ_SMTP_HOST", ""),
+		SmtpPort:        envInt("QUIZ_SMTP_PORT", 587),
+		SmtpUser:        envString("QUIZ_SMTP_USER", ""),
+		SmtpPassword:    envString("QUIZ_SMTP_PASSWORD", ""),
+		SmtpFrom:        envString("QUIZ_SMTP_FROM", "quiz@localhost"),
+		InvitationLimit: envInt("QUIZ_INVITATION_LIMIT", 200),
+
+		MediaUrl:    envString("QUIZ_MEDIA_URL", "http://localhost:3000"),
+		MediaUrlTtl: envDuration("QUIZ_MEDIA_URL_TTL", time.Hour),
+
+		MediaStorage: envString("QUIZ_MEDIA_STORAGE", "gridfs"),
+		S3Endpoint:   envString("QUIZ_S3_ENDPOINT", ""),
+		S3Region:     envString("QUIZ_S3_REGION", "us-east-1"),
+		S3Bucket:     envString("QUIZ_S3_BUCKET", ""),
+		S3AccessKey:  envString("QUIZ_S3_ACCESS_KEY", ""),
+		S3SecretKey:  envString("QUIZ_S3_SECRET_KEY", ""),
+
+		Webhooks: envString("QUIZ_WEBHOOKS", ""),
+
+		GameCodeAlphabet: envString("QUIZ_GAME_CODE_ALPHABET", "0123456789"),
+		GameCodeLength:   envInt("QUIZ_GAME_CODE_LENGTH", 6),
+		RandomSeed:       envInt("QUIZ_RANDOM_SEED", 0),
+		JoinCheckLimit:   envInt("QUIZ_JOIN_CHECK_LIMIT", 10),
+		JoinAttemptLimit: envInt("QUIZ_JOIN_ATTEMPT_LIMIT", 10),
+		JoinLockout:      envDuration("QUIZ_JOIN_LOCKOUT", 15*time.Minute),
+
+		AnswerGrace: envDuration("QUIZ_ANSWER_GRACE", 500*time.Millisecond),
+
+		QuotaQuizzes:     envInt("QUIZ_QUOTA_QUIZZES", 0),
+		QuotaPlayers:     envInt("QUIZ_QUOTA_PLAYERS", 0),
+		QuotaGamesPerDay: envInt("QUIZ_QUOTA_GAMES_PER_DAY", 0),
+
+		GeoIpCsv:       envString("QUIZ_GEOIP_CSV", ""),
+		ProxyHeader:    envString("QUIZ_PROXY_HEADER", ""),
+		TrustedProxies: envString("QUIZ_TRUSTED_PROXIES", ""),
+
+		Stream:      envString("QUIZ_STREAM", ""),
+		StreamUrl:   envString("QUIZ_STREAM_URL", ""),
+		StreamTopic: envString("QUIZ_STREAM_TOPIC", "quiz.events"),
+
+		Translator:    envString("QUIZ_TRANSLATOR", ""),
+		TranslatorUrl: envString("QUIZ_TRANSLATOR_URL", ""),
+		TranslatorKey: envString("QUIZ_TRANSLATOR_KEY", ""),
+
+		Embedder:      envString("QUIZ_EMBEDDER", ""),
+		EmbedderUrl:   envString("QUIZ_EMBEDDER_URL", ""),
+		EmbedderKey:   envString("QUIZ_EMBEDDER_KEY", ""),
+		EmbedderModel: envString("QUIZ_EMBEDDER_MODEL", "text-embedding-3-small"),
+
+		Bus:    envString("QUIZ_BUS", ""),
+		BusUrl: envString("QUIZ_BUS_URL", ""),
+
+		JobWorkers:   envInt("QUIZ_JOB_WORKERS", 2),
+		JobQueueSize: envInt("QUIZ_JOB_QUEUE_SIZE", 100),
+		JobTtl:       envDuration("QUIZ_JOB_TTL", 24*time.Hour),
+		JobRedisUrl:  envString("QUIZ_JOB_REDIS_URL", ""),
+	}
+
+	// Without a configured secret tokens are still safe, but do not survive a restart
+	if config.AuthSecret == "" {
+		config.AuthSecret = randomSecret()
+	}
+
+	return config
+}
+
+// randomSecret generates a random hex encoded signing key
+func randomSecret() string {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(bytes)
+}
+
+// envString returns the value of an environment variable or a default if it is unset
+func envString(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+
+	return fallback
+}
+
+// envInt returns the integer value of an environment variable or a default if it is unset or invalid
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// envDuration returns the duration value of an environment variable (e.g. "24h") or a default if it is unset or invalid
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// envBool returns the boolean value of an environment variable or a default if it is unset or invalid
+func envBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+	"quiz.com/quiz/internal/service"
+)
+
+// AdminController handles HTTP requests of the administration API used by quizctl
+type AdminController struct {
+	netService    *service.NetService
+	quizService   *service.QuizService
+	userService   *service.UserService
+	backupService *service.BackupService
+	jobService    *service.JobService
+	migrate       func(ctx context.Context) error // Applies pending database migrations
+}
+
+// Admin creates a new AdminController instance
+// Parameters:
+// - netService: the service layer that runs the active games
+// - quizService: the service layer that handles quiz-related operations
+// - userService: the service layer that handles user accounts
+// - backupService: the service layer that backs up and restores the database
+// - jobService: the service layer that runs restores asked for with ?async=true
+// - migrate: applies pending database migrations
+// Returns:
+// - A new instance of AdminController
+func Admin(netService *service.NetService, quizService *service.QuizService, userService *service.UserService, backupService *service.BackupService, jobService *service.JobService, migrate func(ctx context.Context) error) AdminController {
+	return AdminController{
+		netService:    netService,
+		quizService:   quizService,
+		userService:   userService,
+		backupService: backupService,
+		jobService:    jobService,
+		migrate:       migrate,
+	}
+}
+
+// GetGames handles the HTTP request to list the active games
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) GetGames(ctx *fiber.Ctx) error {
+	return ctx.JSON(c.netService.GetGames())
+}
+
+// GetConnections handles the HTTP request for the failed writes to clients
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) GetConnections(ctx *fiber.Ctx) error {
+	return ctx.JSON(c.netService.GetConnectionMetrics())
+}
+
+// EndGame handles the HTTP request to end a game by its join code
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) EndGame(ctx *fiber.Ctx) error {
+	if !c.netService.EndGame(ctx.Params("code")) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// SetTemplateRequest represents the structure of the request body for marking a quiz as a template
+type SetTemplateRequest struct {
+	Template bool `json:"template"`
+}
+
+// SetTemplate handles the HTTP request to mark a quiz as a template or an ordinary quiz
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) SetTemplate(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	var req SetTemplateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	err = c.quizService.SetTemplate(ctx.UserContext(), quizId, req.Template)
+	if errors.Is(err, service.ErrQuizNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// CreateUserRequest represents the structure of the request body for creating an account as an admin
+type CreateUserRequest struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Role     string `json:"role"` // "user" or "admin", defaults to "user"
+}
+
+// CreateUser handles the HTTP request to create an account with a given role
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) CreateUser(ctx *fiber.Ctx) error {
+	var req CreateUserRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	if req.Role == "" {
+		req.Role = entity.UserRole
+	}
+
+	user, err := c.userService.CreateUser(ctx.UserContext(), req.Email, req.Name, req.Password, req.Role)
+	if errors.Is(err, service.ErrInvalidUser) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the input or role is invalid
+	}
+
+	if errors.Is(err, service.ErrEmailTaken) || errors.Is(err, service.ErrNameTaken) {
+		return ctx.SendStatus(fiber.StatusConflict) // Return 409 if the email or name is already registered
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(user)
+}
+
+// CreateQuizzes handles the HTTP request to seed quizzes from a list of quizzes in the format GET /api/quizzes returns.
+// The IDs of the quizzes are ignored, every quiz is created anew.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) CreateQuizzes(ctx *fiber.Ctx) error {
+	var quizzes []UpdateQuizRequest
+	if err := ctx.BodyParser(&quizzes); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	created := []entity.Quiz{}
+	for _, quiz := range quizzes {
+		if quiz.Name == "" {
+			return ctx.SendStatus(fiber.StatusBadRequest)
+		}
+	}
+
+	for _, quiz := range quizzes {
+		result, err := c.quizService.CreateQuiz(ctx.UserContext(), quiz.Name, quiz.QuizSettings, quiz.Questions)
+		if errors.Is(err, service.ErrInvalidQuizSettings) {
+			return ctx.SendStatus(fiber.StatusBadRequest)
+		}
+		if err != nil {
+			return err
+		}
+
+		created = append(created, *result)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(created)
+}
+
+// Migrate handles the HTTP request to apply pending database migrations
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) Migrate(ctx *fiber.Ctx) error {
+	if err := c.migrate(ctx.UserContext()); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// GetBackup handles the HTTP request to download a backup of the whole database as a zip archive.
+// The archive is streamed as it is written; one cut short has no manifest and cannot be restored.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) GetBackup(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Attachment("quiz-backup-" + time.Now().UTC().Format("2006-01-02") + ".zip")
+
+	// The request context is done once the handler returns, before the body is written
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := c.backupService.ExportBackup(context.Background(), w); err != nil {
+			fmt.Println("failed to write backup:", err)
+			return
+		}
+
+		w.Flush()
+	})
+	return nil
+}
+
+// RestoreBackup handles the HTTP request to restore a backup uploaded as the "file" of a multipart form.
+// Documents the database already has are kept, so only missing ones are inserted.
+// With ?async=true it is answered with a background job reporting the progress of the restore.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c AdminController) RestoreBackup(ctx *fiber.Ctx) error {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// The uploaded file is gone once the request is answered, so a job restores a copy
+	if runsAsJob(ctx) {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+
+		return startJob(ctx, c.jobService, "restore", func(jobCtx context.Context, progress func(done float64)) (service.JobOutput, error) {
+			restored, err := c.backupService.RestoreBackup(jobCtx, bytes.NewReader(data), int64(len(data)), progress)
+			if err != nil {
+				return service.JobOutput{}, err
+			}
+
+			return service.JsonOutput(restored)
+		})
+	}
+
+	restored, err := c.backupService.RestoreBackup(ctx.UserContext(), file, header.Size, nil)
+	if errors.Is(err, service.ErrInvalidBackup) {
+		return ctx.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(restored)
+}
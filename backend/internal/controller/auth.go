@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+	"quiz.com/quiz/internal/service"
+)
+
+// Keys under which the authenticated user is stored in the request locals
+const (
+	userIdLocal = "userId"
+	claimsLocal = "claims"
+)
+
+// RequireUser creates a middleware that rejects requests without a valid bearer token
+// Parameters:
+// - userService: the service layer used to verify access tokens
+// Returns:
+// - A fiber handler storing the authenticated user's ID and claims in the request locals
+func RequireUser(userService *service.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !authenticate(ctx, userService) {
+			return ctx.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		return ctx.Next()
+	}
+}
+
+// RequireAdmin creates a middleware that rejects requests without a valid bearer token of an admin
+// Parameters:
+// - userService: the service layer used to verify access tokens
+// Returns:
+// - A fiber handler storing the authenticated admin's ID and claims in the request locals
+func RequireAdmin(userService *service.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !authenticate(ctx, userService) {
+			return ctx.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		claims := ctx.Locals(claimsLocal).(*service.TokenClaims)
+		if claims.Role != entity.AdminRole {
+			return ctx.SendStatus(fiber.StatusForbidden)
+		}
+
+		return ctx.Next()
+	}
+}
+
+// RequireSocketUser creates a middleware that rejects WebSocket upgrades without a valid token.
+// Browsers cannot set headers on WebSocket requests, so the token is read from the "token" query parameter.
+// Parameters:
+// - userService: the service layer used to verify access tokens
+// Returns:
+// - A fiber handler storing the authenticated user's ID and claims in the request locals
+func RequireSocketUser(userService *service.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !verifyToken(ctx, userService, ctx.Query("token")) {
+			return ctx.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		return ctx.Next()
+	}
+}
+
+// RequireSocketAdmin creates a middleware that rejects WebSocket upgrades without the token of an admin.
+// Like RequireSocketUser, the token is read from the "token" query parameter.
+// Parameters:
+// - userService: the service layer used to verify access tokens
+// Returns:
+// - A fiber handler storing the authenticated admin's ID and claims in the request locals
+func RequireSocketAdmin(userService *service.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !verifyToken(ctx, userService, ctx.Query("token")) {
+			return ctx.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		claims := ctx.Locals(claimsLocal).(*service.TokenClaims)
+		if claims.Role != entity.AdminRole {
+			return ctx.SendStatus(fiber.StatusForbidden)
+		}
+
+		return ctx.Next()
+	}
+}
+
+// shareTokenHeader carries the token of a quiz share link; the "share" query parameter is read as well
+const shareTokenHeader = "X-Share-Token"
+
+// RequireQuizPermission creates a middleware that rejects requests on a quiz the user has no right on.
+// It runs after OptionalUser, RequireUser or RequireSocketUser, so the user is known if there is one;
+// a share link token grants rights as well. Quizzes the user cannot even see are reported as missing.
+// Parameters:
+// - quizService: the service layer used to read the quiz named by the quizId route parameter
+// - permission: the right needed, see entity.ViewPermission
+// Returns:
+// - A fiber handler passing on requests with the right
+func RequireQuizPermission(quizService *service.QuizService, permission string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+		if err != nil {
+			return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+		}
+
+		quiz, err := quizService.GetQuizById(ctx.UserContext(), quizId)
+		if err != nil {
+			return err
+		}
+
+		userId, token := getUserId(ctx), getShareToken(ctx)
+		if quiz == nil || !service.CanAccessQuiz(*quiz, userId, token, entity.ViewPermission) {
+			return ctx.SendStatus(fiber.StatusNotFound)
+		}
+
+		if !service.CanAccessQuiz(*quiz, userId, token, permission) {
+			return ctx.SendStatus(fiber.StatusForbidden)
+		}
+
+		// Templates are only changed by admins, everyone else starts from a duplicate
+		if quiz.Template && permission == entity.EditPermission && !isAdmin(ctx) {
+			return ctx.SendStatus(fiber.StatusForbidden)
+		}
+
+		return ctx.Next()
+	}
+}
+
+// getShareToken returns the token of the quiz share link a request carries
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - The token, or an empty string if the request carries none
+func getShareToken(ctx *fiber.Ctx) string {
+	if token := ctx.Get(shareTokenHeader); token != "" {
+		return token
+	}
+
+	return ctx.Query("share")
+}
+
+// authenticate verifies the bearer token of a request and stores the user's ID and claims in the request locals
+// Parameters:
+// - ctx: the context of the HTTP request
+// - userService: the service layer used to verify access tokens
+// Returns:
+// - false if the request carries no valid token
+func authenticate(ctx *fiber.Ctx, userService *service.UserService) bool {
+	token, found := strings.CutPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !found {
+		return false
+	}
+
+	return verifyToken(ctx, userService, token)
+}
+
+// verifyToken verifies an access token and stores the user's ID and claims in the request locals
+// Parameters:
+// - ctx: the context of the HTTP request
+// - userService: the service layer used to verify access tokens
+// - token: the access token
+// Returns:
+// - false if the token cannot be trusted
+func verifyToken(ctx *fiber.Ctx, userService *service.UserService, token string) bool {
+	claims, err := userService.VerifyToken(token)
+	if err != nil {
+		return false
+	}
+
+	userId, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		return false
+	}
+
+	ctx.Locals(userIdLocal, userId)
+	ctx.Locals(claimsLocal, claims)
+	return true
+}
+
+// OptionalUser creates a middleware that authenticates requests carrying a valid bearer token
+// and lets all other requests through anonymously
+// Parameters:
+// - userService: the service layer used to verify access tokens
+// Returns:
+// - A fiber handler storing the authenticated user's ID and claims in the request locals, if any
+func OptionalUser(userService *service.UserService) fiber.Handler {
+	requireUser := RequireUser(userService)
+	return func(ctx *fiber.Ctx) error {
+		if ctx.Get(fiber.HeaderAuthorization) == "" {
+			return ctx.Next()
+		}
+
+		return requireUser(ctx)
+	}
+}
+
+// getUserId returns the ID of the user authenticated by RequireUser
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - The user's ObjectID, or primitive.NilObjectID if the request is not authenticated
+func getUserId(ctx *fiber.Ctx) primitive.ObjectID {
+	userId, ok := ctx.Locals(userIdLocal).(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID
+	}
+
+	return userId
+}
+
+// getUserName returns the display name of the user authenticated by RequireUser
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - The user's name, or an empty string if the request is not authenticated
+func getUserName(ctx *fiber.Ctx) string {
+	claims, ok := ctx.Locals(claimsLocal).(*service.TokenClaims)
+	if !ok {
+		return ""
+	}
+
+	return claims.Name
+}
+
+// isAdmin reports whether the user authenticated by RequireUser is an admin
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - true if the request carries the token of an admin
+func isAdmin(ctx *fiber.Ctx) bool {
+	claims, ok := ctx.Locals(claimsLocal).(*service.TokenClaims)
+	return ok && claims.Role == entity.AdminRole
+}
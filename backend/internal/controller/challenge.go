@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+	"quiz.com/quiz/internal/service"
+)
+
+// ChallengeController handles HTTP requests related to scheduled challenges
+type ChallengeController struct {
+	challengeService *service.ChallengeService
+}
+
+// Challenge creates a new ChallengeController instance
+// Parameters:
+// - challengeService: the service layer that handles challenge-related operations
+// Returns:
+// - A new instance of ChallengeController
+func Challenge(challengeService *service.ChallengeService) ChallengeController {
+	return ChallengeController{
+		challengeService: challengeService,
+	}
+}
+
+// CreateChallengeRequest represents the structure of the request body for scheduling a challenge
+type CreateChallengeRequest struct {
+	Name         string           `json:"name"`
+	QuizId       string           `json:"quizId"`       // Quiz played, empty to pick a random quiz of the open library every time
+	Interval     string           `json:"interval"`     // "daily" or "weekly"
+	LobbySeconds int              `json:"lobbySeconds"` // How long players can join a game before it starts, 60 if zero
+	StartAt      time.Time        `json:"startAt"`      // When the first game is hosted, right away if omitted
+	Webhooks     []entity.Webhook `json:"webhooks"`     // Channels the join codes are posted to
+}
+
+// CreateChallenge handles the HTTP request to schedule a challenge
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ChallengeController) CreateChallenge(ctx *fiber.Ctx) error {
+	// Parse the request body into the CreateChallengeRequest struct
+	var req CreateChallengeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	quizId := primitive.NilObjectID
+	if req.QuizId != "" {
+		id, err := primitive.ObjectIDFromHex(req.QuizId)
+		if err != nil {
+			return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+		}
+		quizId = id
+	}
+
+	lobby := service.DefaultHeadlessLobby
+	if req.LobbySeconds > 0 {
+		lobby = time.Duration(req.LobbySeconds) * time.Second
+	}
+
+	challenge, err := c.challengeService.CreateChallenge(ctx.UserContext(), getUserId(ctx), req.Name, quizId, req.Interval, lobby, req.StartAt, req.Webhooks)
+	if errors.Is(err, service.ErrInvalidChallenge) || errors.Is(err, service.ErrInvalidWebhook) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the input is invalid
+	}
+
+	if errors.Is(err, service.ErrQuizNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if errors.Is(err, service.ErrQuizForbidden) {
+		return ctx.SendStatus(fiber.StatusForbidden) // Return 403 if the quiz is not shared with the user for hosting
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(challenge)
+}
+
+// GetChallengeById handles the HTTP request to get a challenge and the games hosted for it
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ChallengeController) GetChallengeById(ctx *fiber.Ctx) error {
+	// Retrieve the challenge ID from the URL parameters
+	challengeId, err := primitive.ObjectIDFromHex(ctx.Params("challengeId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	challenge, err := c.challengeService.GetChallengeById(ctx.UserContext(), challengeId)
+	if err != nil {
+		return err
+	}
+
+	// If the challenge is not found, return 404 status
+	if challenge == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	return ctx.JSON(challenge)
+}
+
+// GetLeaderboard handles the HTTP request to get the leaderboard of the current season of a challenge
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ChallengeController) GetLeaderboard(ctx *fiber.Ctx) error {
+	// Retrieve the challenge ID from the URL parameters
+	challengeId, err := primitive.ObjectIDFromHex(ctx.Params("challengeId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	challenge, err := c.challengeService.GetChallengeById(ctx.UserContext(), challengeId)
+	if err != nil {
+		return err
+	}
+
+	// If the challenge is not found, return 404 status
+	if challenge == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	standings, err := c.challengeService.GetLeaderboard(ctx.UserContext(), *challenge)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(standings)
+}
+
+// StartSeason handles the HTTP request of the owner of a challenge to start a new season
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ChallengeController) StartSeason(ctx *fiber.Ctx) error {
+	// Retrieve the challenge ID from the URL parameters
+	challengeId, err := primitive.ObjectIDFromHex(ctx.Params("challengeId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	challenge, err := c.challengeService.StartSeason(ctx.UserContext(), challengeId, getUserId(ctx))
+	if err != nil {
+		return sendChallengeError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(challenge)
+}
+
+// DeleteChallenge handles the HTTP request of the owner of a challenge to stop it
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ChallengeController) DeleteChallenge(ctx *fiber.Ctx) error {
+	// Retrieve the challenge ID from the URL parameters
+	challengeId, err := primitive.ObjectIDFromHex(ctx.Params("challengeId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	if err := c.challengeService.DeleteChallenge(ctx.UserContext(), challengeId, getUserId(ctx)); err != nil {
+		return sendChallengeError(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// sendChallengeError answers a request to change a challenge that failed
+// Parameters:
+// - ctx: the context of the HTTP request
+// - err: the error of the service layer
+// Returns:
+// - error: the error if it is not about the challenge, or nil once the status is sent
+func sendChallengeError(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrChallengeNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if errors.Is(err, service.ErrNotChallengeOwner) {
+		return ctx.SendStatus(fiber.StatusForbidden) // Return 403 if the user did not schedule the challenge
+	}
+
+	return err
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/service"
+)
+
+// CommentController handles HTTP requests related to review comments on quiz questions
+type CommentController struct {
+	commentService *service.CommentService
+}
+
+// Comment creates a new CommentController instance
+// Parameters:
+// - commentService: the service layer that handles comment-related operations
+// Returns:
+// - A new instance of CommentController
+func Comment(commentService *service.CommentService) CommentController {
+	return CommentController{
+		commentService: commentService,
+	}
+}
+
+// AddCommentRequest represents the structure of the request body for commenting on a question
+type AddCommentRequest struct {
+	Text string `json:"text"`
+}
+
+// AddComment handles the HTTP request to comment on a question of a quiz
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c CommentController) AddComment(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	var req AddCommentRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	comment, err := c.commentService.AddComment(ctx.UserContext(), quizId, ctx.Params("questionId"), getUserId(ctx), getUserName(ctx), req.Text)
+	if errors.Is(err, service.ErrInvalidComment) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the comment is empty, too long or about an unknown question
+	}
+	if err != nil {
+		return err
+	}
+
+	// If the quiz is not found, return 404 status
+	if comment == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(comment)
+}
+
+// GetComments handles the HTTP request to list the comments on the questions of a quiz.
+// Resolved comments are only listed with the query parameter resolved=true.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c CommentController) GetComments(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	comments, err := c.commentService.GetComments(ctx.UserContext(), quizId, ctx.QueryBool("resolved"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(comments)
+}
+
+// ResolveComment handles the HTTP request to mark a comment as dealt with
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c CommentController) ResolveComment(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	commentId, err := primitive.ObjectIDFromHex(ctx.Params("commentId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	err = c.commentService.ResolveComment(ctx.UserContext(), quizId, commentId, getUserName(ctx))
+	if errors.Is(err, service.ErrCommentNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound) // Return 404 if the comment does not exist or is already resolved
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
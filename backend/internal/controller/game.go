@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/service"
+)
+
+// GameController handles HTTP requests to run games without a host connection
+type GameController struct {
+	netService *service.NetService
+}
+
+// Game creates a new GameController instance
+// Parameters:
+// - netService: the service layer that runs the games
+// Returns:
+// - A new instance of GameController
+func Game(netService *service.NetService) GameController {
+	return GameController{
+		netService: netService,
+	}
+}
+
+// HostHeadlessRequest represents the structure of the request body for starting a headless game
+type HostHeadlessRequest struct {
+	QuizId       string `json:"quizId"`
+	LobbySeconds int    `json:"lobbySeconds"` // How long players can join before the quiz starts, 60 if zero
+}
+
+// HostHeadlessResponse represents the response returned after starting a headless game
+type HostHeadlessResponse struct {
+	Code string `json:"code"` // Join code of the game, also used to watch it on /ws/leaderboard/:code
+}
+
+// HostHeadless handles the HTTP request to start a game the server runs on autopilot
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c GameController) HostHeadless(ctx *fiber.Ctx) error {
+	var req HostHeadlessRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	quizId, err := primitive.ObjectIDFromHex(req.QuizId)
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	lobby := service.DefaultHeadlessLobby
+	if req.LobbySeconds > 0 {
+		lobby = time.Duration(req.LobbySeconds) * time.Second
+	}
+
+	code, err := c.netService.HostHeadless(ctx.UserContext(), getUserId(ctx), quizId, lobby)
+	if errors.Is(err, service.ErrQuizNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if errors.Is(err, service.ErrQuizForbidden) {
+		return ctx.SendStatus(fiber.StatusForbidden) // Return 403 if the quiz is not shared with the user for hosting
+	}
+
+	if err != nil {
+		return sendQuotaError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(HostHeadlessResponse{
+		Code: code,
+	})
+}
+
+// GetHostState handles the HTTP request of a host for the current state of their game
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c GameController) GetHostState(ctx *fiber.Ctx) error {
+	state, err := c.netService.GetHostState(ctx.Params("code"), getUserId(ctx))
+	if errors.Is(err, service.ErrGameNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if errors.Is(err, service.ErrNotHost) {
+		return ctx.SendStatus(fiber.StatusForbidden)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(state)
+}
+
+// CheckJoinCode handles the HTTP request of a join page to check a code, and show the quiz it joins, before opening a WebSocket.
+// Lookups are limited per client address, found or not, so active games cannot be found by trying codes.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c GameController) CheckJoinCode(ctx *fiber.Ctx) error {
+	check, err := c.netService.CheckJoinCode(ctx.Params("code"), ctx.IP())
+	var limited service.RateLimitError
+	if errors.As(err, &limited) {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		return ctx.SendStatus(fiber.StatusTooManyRequests)
+	}
+	if errors.Is(err, service.ErrGameNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.JSON(check)
+}
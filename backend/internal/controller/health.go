@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"quiz.com/quiz/internal/service"
+)
+
+// HealthController reports whether an instance can serve requests, for load balancers and orchestrators
+type HealthController struct {
+	bus service.MessageBus
+}
+
+// Health creates a new HealthController instance
+// Parameters:
+// - bus: the message bus shared with the other instances, nil for a single instance
+// Returns:
+// - A new instance of HealthController
+func Health(bus service.MessageBus) HealthController {
+	return HealthController{
+		bus: bus,
+	}
+}
+
+// GetHealth handles the HTTP request for the health of the instance.
+// An instance cut off from the message bus would serve stale quizzes, so it reports itself unavailable.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c HealthController) GetHealth(ctx *fiber.Ctx) error {
+	if c.bus == nil {
+		return ctx.JSON(fiber.Map{"status": "ok", "bus": "disabled"})
+	}
+
+	if err := c.bus.Healthy(); err != nil {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "unavailable", "bus": err.Error()})
+	}
+
+	return ctx.JSON(fiber.Map{"status": "ok", "bus": "connected"})
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"quiz.com/quiz/internal/service"
+)
+
+// JobController handles HTTP requests for the status and results of background jobs
+type JobController struct {
+	jobService *service.JobService
+}
+
+// Job creates a new JobController instance
+// Parameters:
+// - jobService: the service layer that runs background jobs
+// Returns:
+// - A new instance of JobController
+func Job(jobService *service.JobService) JobController {
+	return JobController{
+		jobService: jobService,
+	}
+}
+
+// GetJob handles the HTTP request for the state and progress of a background job
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c JobController) GetJob(ctx *fiber.Ctx) error {
+	job, _, err := c.jobService.GetJob(ctx.UserContext(), ctx.Params("jobId"), getUserId(ctx))
+	if errors.Is(err, service.ErrJobNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(job)
+}
+
+// GetJobResult handles the HTTP request for the result of a finished background job
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c JobController) GetJobResult(ctx *fiber.Ctx) error {
+	job, result, err := c.jobService.GetJob(ctx.UserContext(), ctx.Params("jobId"), getUserId(ctx))
+	if errors.Is(err, service.ErrJobNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	if job.State != service.JobDone {
+		return ctx.Status(fiber.StatusConflict).JSON(job) // Return 409 with the state while the job runs or if it failed
+	}
+
+	ctx.Set(fiber.HeaderContentType, job.ResultType)
+	if job.ResultName != "" {
+		ctx.Attachment(job.ResultName)
+	}
+	return ctx.Send(result)
+}
+
+// runsAsJob reports whether a request asks for its work to be done by a background job, with ?async=true
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - bool: true if the request should be answered with a job
+func runsAsJob(ctx *fiber.Ctx) bool {
+	return ctx.QueryBool("async", false)
+}
+
+// startJob queues the work of a request as a background job and answers with 202 and the job, whose status is at its Location.
+// The work must not use the request context or values read from it without copying them, as both are reused after the response.
+// Parameters:
+// - ctx: the context of the HTTP request
+// - jobService: the service layer that runs background jobs
+// - kind: what the job does, e.g. "import"
+// - fn: the work of the job
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func startJob(ctx *fiber.Ctx, jobService *service.JobService, kind string, fn service.JobFunc) error {
+	job, err := jobService.Enqueue(ctx.UserContext(), getUserId(ctx), kind, fn)
+	if errors.Is(err, service.ErrJobQueueFull) {
+		return ctx.SendStatus(fiber.StatusServiceUnavailable) // Return 503 until the workers catch up
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.Location("/api/jobs/" + job.Id)
+	return ctx.Status(fiber.StatusAccepted).JSON(job)
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+	"quiz.com/quiz/internal/service"
+)
+
+// MediaController handles HTTP requests related to uploaded media
+type MediaController struct {
+	mediaService *service.MediaService
+}
+
+// Media creates a new MediaController instance
+// Parameters:
+// - mediaService: the service layer that handles media-related operations
+// Returns:
+// - A new instance of MediaController
+func Media(mediaService *service.MediaService) MediaController {
+	return MediaController{
+		mediaService: mediaService,
+	}
+}
+
+// MediaResponse represents the response returned after a successful upload
+type MediaResponse struct {
+	Media entity.Media `json:"media"`
+	Url   string       `json:"url"` // Signed URL to preview the media with
+}
+
+// Upload handles the HTTP request to upload an image or sound, sent as the multipart form field "file"
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c MediaController) Upload(ctx *fiber.Ctx) error {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	media, err := c.mediaService.Upload(ctx.UserContext(), getUserId(ctx), header.Filename, file)
+	if errors.Is(err, service.ErrInvalidMedia) {
+		return ctx.SendStatus(fiber.StatusUnsupportedMediaType) // Return 415 if the file is not a supported image or sound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(MediaResponse{
+		Media: *media,
+		Url:   c.mediaService.SignUrl(media.Id, ""),
+	})
+}
+
+// Get handles the HTTP request to serve media through a signed URL.
+// Responses are public and cacheable until the URL expires, so a CDN can take the load off the server.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c MediaController) Get(ctx *fiber.Ctx) error {
+	// Retrieve the media ID from the URL parameters
+	mediaId, err := primitive.ObjectIDFromHex(ctx.Params("mediaId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	expires := ctx.Query("expires")
+	media, content, err := c.mediaService.Open(ctx.UserContext(), mediaId, ctx.Query("size"), expires, ctx.Query("sig"))
+	if errors.Is(err, service.ErrInvalidSignature) {
+		return ctx.SendStatus(fiber.StatusForbidden) // Return 403 if the URL is forged or expired
+	}
+
+	if errors.Is(err, service.ErrMediaNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// The signature was verified, so expires is a valid timestamp
+	expiresAt, _ := strconv.ParseInt(expires, 10, 64)
+	maxAge := max(int64(time.Until(time.Unix(expiresAt, 0))/time.Second), 0)
+
+	ctx.Set(fiber.HeaderContentType, media.ContentType)
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d, immutable", maxAge))
+	ctx.Set(fiber.HeaderETag, `"`+media.Hash+`"`)
+
+	// The stream is closed once it has been sent
+	return ctx.SendStream(content, int(media.Bytes))
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"quiz.com/quiz/internal/service"
+)
+
+// ClientAddress creates a middleware that replaces the proxy header with the single address of the client.
+// Fiber reads the leftmost address of the header, which the client can choose, so the hops are resolved here
+// and every later handler, WebSocket connections included, sees the address through ctx.IP().
+// Parameters:
+// - proxies: the chain of reverse proxies in front of the server
+// Returns:
+// - A fiber handler rewriting the proxy header, doing nothing without one
+func ClientAddress(proxies *service.ProxyChain) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		header := proxies.Header()
+		if header == "" {
+			return ctx.Next()
+		}
+
+		// A header sent more than once counts as a single list, in the order received
+		var hops []string
+		for _, value := range ctx.Request().Header.PeekAll(header) {
+			hops = append(hops, string(value))
+		}
+
+		peer := ctx.Context().RemoteIP().String()
+		ctx.Request().Header.Set(header, proxies.ClientAddress(peer, strings.Join(hops, ",")))
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+	"quiz.com/quiz/internal/service"
+)
+
+// Caching of the public metadata, short enough that changes to a quiz show up soon
+const (
+	publicMetadataMaxAge = 300 // Seconds CDNs and browsers may serve metadata without asking again
+	publicCoverMaxAge    = 60  // Seconds the redirect to a signed cover URL may be cached, well within the URL's lifetime
+	gamePreviewMaxAge    = 30  // Seconds the preview of a game may be cached, so the player count stays close
+)
+
+// PublicController handles the cacheable, read-only HTTP requests of join pages and link previews
+type PublicController struct {
+	quizService  *service.QuizService
+	userService  *service.UserService
+	mediaService *service.MediaService
+	netService   *service.NetService
+	baseUrl      string
+	publicUrl    string
+}
+
+// Public creates a new PublicController instance
+// Parameters:
+// - quizService: the service layer that reads the quizzes
+// - userService: the service layer that names the authors of quizzes
+// - mediaService: the service layer that signs the URLs of uploaded covers
+// - netService: the service layer that describes the running games
+// - baseUrl: the base URL the public endpoints are reached at, used in links to covers
+// - publicUrl: the base URL of the frontend, which link previews send people on to
+// Returns:
+// - A new instance of PublicController
+func Public(quizService *service.QuizService, userService *service.UserService, mediaService *service.MediaService, netService *service.NetService, baseUrl string, publicUrl string) PublicController {
+	return PublicController{
+		quizService:  quizService,
+		userService:  userService,
+		mediaService: mediaService,
+		netService:   netService,
+		baseUrl:      baseUrl,
+		publicUrl:    publicUrl,
+	}
+}
+
+// getPublicQuiz reads the quiz named by the quizId route parameter, if its metadata is public
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - *entity.Quiz: the quiz, nil if the response was already sent
+// - error: any error encountered while reading the quiz
+func (c PublicController) getPublicQuiz(ctx *fiber.Ctx) (*entity.Quiz, error) {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return nil, ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	quiz, err := c.quizService.GetQuizById(ctx.UserContext(), quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Private quizzes look the same as missing ones
+	if quiz == nil || !service.IsPublicQuiz(*quiz) {
+		return nil, ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	return quiz, nil
+}
+
+// GetQuizMetadata handles the HTTP request for the metadata of a public quiz. Responses carry an ETag and,
+// for quizzes saved since changes are tracked, a Last-Modified date; conditional requests are answered with 304.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c PublicController) GetQuizMetadata(ctx *fiber.Ctx) error {
+	quiz, err := c.getPublicQuiz(ctx)
+	if quiz == nil {
+		return err
+	}
+
+	metadata, err := c.getQuizMetadata(ctx, *quiz)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", publicMetadataMaxAge))
+	ctx.Set(fiber.HeaderETag, metadata.ETag())
+	if metadata.UpdatedAt != nil {
+		ctx.Set(fiber.HeaderLastModified, metadata.UpdatedAt.Format(http.TimeFormat))
+	}
+
+	// The client's copy is still current
+	if ctx.Fresh() {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	return ctx.JSON(metadata)
+}
+
+// getQuizMetadata describes a public quiz, naming its author
+// Parameters:
+// - ctx: the context of the HTTP request
+// - quiz: the quiz
+// Returns:
+// - service.QuizMetadata: the metadata
+// - error: any error encountered while reading the profile of the author
+func (c PublicController) getQuizMetadata(ctx *fiber.Ctx, quiz entity.Quiz) (service.QuizMetadata, error) {
+	author := ""
+	if !quiz.OwnerId.IsZero() {
+		profile, err := c.userService.GetProfile(ctx.UserContext(), quiz.OwnerId)
+		if err != nil {
+			return service.QuizMetadata{}, err
+		}
+
+		if profile != nil {
+			author = profile.Name
+		}
+	}
+
+	return service.NewQuizMetadata(quiz, author, c.baseUrl), nil
+}
+
+// GetQuizCover handles the HTTP request for the cover image of a public quiz, redirecting to a signed URL
+// of the uploaded image or to the external image
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c PublicController) GetQuizCover(ctx *fiber.Ctx) error {
+	quiz, err := c.getPublicQuiz(ctx)
+	if quiz == nil {
+		return err
+	}
+
+	cover := service.GetQuizCover(*quiz)
+	if cover == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	target := cover.Url
+	if !cover.MediaId.IsZero() {
+		target = c.mediaService.SignUrl(cover.MediaId, "")
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", publicCoverMaxAge))
+	return ctx.Redirect(target, fiber.StatusFound)
+}
+
+// GetQuizPreview handles the HTTP request of a chat app for the Open Graph and Twitter card tags of a public
+// quiz; browsers following the link are sent on to the frontend
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c PublicController) GetQuizPreview(ctx *fiber.Ctx) error {
+	quiz, err := c.getPublicQuiz(ctx)
+	if quiz == nil {
+		return err
+	}
+
+	metadata, err := c.getQuizMetadata(ctx, *quiz)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", publicMetadataMaxAge))
+	return sendLinkPreview(ctx, service.NewQuizPreview(metadata, c.publicUrl))
+}
+
+// GetGamePreview handles the HTTP request of a chat app for the Open Graph and Twitter card tags of the join
+// link of a running game; browsers following the link are sent on to join it
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c PublicController) GetGamePreview(ctx *fiber.Ctx) error {
+	preview, err := c.netService.GetGamePreview(ctx.Params("code"))
+	if errors.Is(err, service.ErrGameNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", gamePreviewMaxAge))
+	return sendLinkPreview(ctx, preview)
+}
+
+// sendLinkPreview answers with the page of a link preview
+// Parameters:
+// - ctx: the context of the HTTP request
+// - preview: the card
+// Returns:
+// - error: any error encountered while rendering the page, or nil if successful
+func sendLinkPreview(ctx *fiber.Ctx, preview service.LinkPreview) error {
+	page, err := service.RenderLinkPreview(preview)
+	if err != nil {
+		return err
+	}
+
+	ctx.Type("html")
+	return ctx.SendString(page)
+}
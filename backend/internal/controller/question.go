@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"quiz.com/quiz/internal/service"
+)
+
+// QuestionController handles HTTP requests about the questions of the library, across quizzes
+type QuestionController struct {
+	searchService *service.SearchService
+}
+
+// Question creates a new QuestionController instance
+// Parameters:
+// - searchService: the service layer that searches the questions of the library
+// Returns:
+// - A new instance of QuestionController
+func Question(searchService *service.SearchService) QuestionController {
+	return QuestionController{
+		searchService: searchService,
+	}
+}
+
+// SearchQuestions handles the HTTP request to find questions of the library by what they are about, from the
+// q query parameter; limit caps the number of questions returned
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuestionController) SearchQuestions(ctx *fiber.Ctx) error {
+	matches, err := c.searchService.SearchQuestions(ctx.UserContext(), getUserId(ctx), ctx.Query("q"), ctx.QueryInt("limit"))
+	if errors.Is(err, service.ErrInvalidQuery) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the query is empty or too long
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(matches)
+}
@@ -1,6 +1,14 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"quiz.com/quiz/internal/entity"
@@ -9,17 +17,29 @@ import (
 
 // QuizController handles HTTP requests related to quizzes
 type QuizController struct {
-	quizService *service.QuizService
+	quizService   *service.QuizService
+	editorService *service.EditorService
+	quotaService  *service.QuotaService
+	jobService    *service.JobService
+	translator    service.Translator
 }
 
 // Quiz creates a new QuizController instance
 // Parameters:
 // - quizService: the service layer that handles quiz-related operations
+// - editorService: the service layer that tells the editors of a quiz about saved changes
+// - quotaService: the service layer that limits how many quizzes a user owns
+// - jobService: the service layer that runs imports, exports and translations asked for with ?async=true
+// - translator: the machine translator of quizzes, nil if none is configured
 // Returns:
 // - A new instance of QuizController
-func Quiz(quizService *service.QuizService) QuizController {
+func Quiz(quizService *service.QuizService, editorService *service.EditorService, quotaService *service.QuotaService, jobService *service.JobService, translator service.Translator) QuizController {
 	return QuizController{
-		quizService: quizService,
+		quizService:   quizService,
+		editorService: editorService,
+		quotaService:  quotaService,
+		jobService:    jobService,
+		translator:    translator,
 	}
 }
 
@@ -37,7 +57,7 @@ func (c QuizController) GetQuizById(ctx *fiber.Ctx) error {
 	}
 
 	// Fetch the quiz by its ID using the service layer
-	quiz, err := c.quizService.GetQuizById(quizId)
+	quiz, err := c.quizService.GetQuizById(ctx.UserContext(), quizId)
 	if err != nil {
 		return err
 	}
@@ -47,16 +67,27 @@ func (c QuizController) GetQuizById(ctx *fiber.Ctx) error {
 		return ctx.SendStatus(fiber.StatusNotFound)
 	}
 
+	// Host notes are only shown to those who may edit the quiz
+	if !service.CanAccessQuiz(*quiz, getUserId(ctx), getShareToken(ctx), entity.EditPermission) {
+		*quiz = stripHostNotes(*quiz)
+	}
+
 	// Return the quiz in JSON format
 	return ctx.JSON(quiz)
 }
 
 // UpdateQuizRequest represents the structure of the request body for updating a quiz
 type UpdateQuizRequest struct {
-	Name      string                `json:"name"`
+	Name string `json:"name"`
+	entity.QuizSettings
 	Questions []entity.QuizQuestion `json:"questions"`
 }
 
+// UpdateQuizResponse represents the response to updating a quiz
+type UpdateQuizResponse struct {
+	Warnings []service.DuplicateWarning `json:"warnings"` // Questions that nearly repeat another one
+}
+
 // UpdateQuizById handles the HTTP request to update a quiz by its ID
 // Parameters:
 // - ctx: the context of the HTTP request
@@ -76,8 +107,176 @@ func (c QuizController) UpdateQuizById(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	// The saved questions are compared against the new ones to tell the other editors what changed
+	before, err := c.quizService.GetQuizById(ctx.UserContext(), quizId)
+	if err != nil {
+		return err
+	}
+
+	// Questions sent without host notes keep the ones already saved
+	var notes hostNotesRequest
+	if err := ctx.BodyParser(&notes); err != nil {
+		return err
+	}
+	if before != nil {
+		keepHostNotes(before.Questions, req.Questions, notes)
+	}
+
 	// Update the quiz using the service layer
-	if err := c.quizService.UpdateQuiz(quizId, req.Name, req.Questions); err != nil {
+	warnings, err := c.quizService.UpdateQuiz(ctx.UserContext(), quizId, getUserId(ctx), req.Name, req.QuizSettings, req.Questions)
+	if errors.Is(err, service.ErrInvalidQuizSettings) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the default time or total points are out of range
+	}
+	if err != nil {
+		return err
+	}
+
+	if before != nil {
+		c.editorService.AnnounceChanges(quizId, getUserName(ctx), before.Questions, req.Questions)
+	}
+
+	// The quiz is saved either way, duplicates are only pointed out to the author
+	return ctx.JSON(UpdateQuizResponse{
+		Warnings: warnings,
+	})
+}
+
+// LintQuiz handles the HTTP request to check the questions of a quiz for issues.
+// Editors send their unsaved questions in the body; without a body, the saved questions are checked.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) LintQuiz(ctx *fiber.Ctx) error {
+	// Retrieve the quiz ID from the URL parameters
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	quiz, err := c.quizService.GetQuizById(ctx.UserContext(), quizId)
+	if err != nil {
+		return err
+	}
+
+	// If the quiz is not found, return 404 status
+	if quiz == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	questions, defaultTime := quiz.Questions, quiz.DefaultTime
+	if len(ctx.Body()) > 0 {
+		var req UpdateQuizRequest
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.SendStatus(fiber.StatusBadRequest)
+		}
+
+		questions, defaultTime = req.Questions, req.DefaultTime
+	}
+
+	return ctx.JSON(service.LintQuestions(questions, defaultTime))
+}
+
+// SetQuestionTimesRequest represents the request body for giving every question of a quiz the same time
+type SetQuestionTimesRequest struct {
+	Time int `json:"time"` // Time in seconds to give every question
+}
+
+// SetQuestionTimes handles the HTTP request to give every question of a quiz the same time to answer
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) SetQuestionTimes(ctx *fiber.Ctx) error {
+	// Retrieve the quiz ID from the URL parameters
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	var req SetQuestionTimesRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	before, err := c.quizService.GetQuizById(ctx.UserContext(), quizId)
+	if err != nil {
+		return err
+	}
+
+	quiz, err := c.quizService.SetQuestionTimes(ctx.UserContext(), quizId, req.Time)
+	if errors.Is(err, service.ErrInvalidQuestionTime) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the time is out of range
+	}
+	if err != nil {
+		return err
+	}
+
+	// If the quiz is not found, return 404 status
+	if quiz == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if before != nil {
+		c.editorService.AnnounceChanges(quizId, getUserName(ctx), before.Questions, quiz.Questions)
+	}
+
+	return ctx.JSON(*quiz)
+}
+
+// GetWebhooks handles the HTTP request to get the chat webhooks games of a quiz are announced in
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) GetWebhooks(ctx *fiber.Ctx) error {
+	// Retrieve the quiz ID from the URL parameters
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	quiz, err := c.quizService.GetQuizById(ctx.UserContext(), quizId)
+	if err != nil {
+		return err
+	}
+
+	// If the quiz is not found, return 404 status
+	if quiz == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	webhooks := quiz.Webhooks
+	if webhooks == nil {
+		webhooks = []entity.Webhook{}
+	}
+
+	return ctx.JSON(webhooks)
+}
+
+// UpdateWebhooks handles the HTTP request to replace the chat webhooks games of a quiz are announced in
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) UpdateWebhooks(ctx *fiber.Ctx) error {
+	// Retrieve the quiz ID from the URL parameters
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	// Parse the request body into a list of webhooks
+	var webhooks []entity.Webhook
+	if err := ctx.BodyParser(&webhooks); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	err = c.quizService.UpdateWebhooks(ctx.UserContext(), quizId, webhooks)
+	if errors.Is(err, service.ErrInvalidWebhook) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if a webhook is not a Slack or Discord URL
+	}
+	if err != nil {
 		return err
 	}
 
@@ -85,6 +284,337 @@ func (c QuizController) UpdateQuizById(ctx *fiber.Ctx) error {
 	return ctx.SendStatus(fiber.StatusOK)
 }
 
+// GetTemplates handles the HTTP request to list the quiz templates every signed in user can start from
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) GetTemplates(ctx *fiber.Ctx) error {
+	templates, err := c.quizService.GetTemplates(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(templates)
+}
+
+// DuplicateQuiz handles the HTTP request to copy a quiz into a new quiz owned by the user
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) DuplicateQuiz(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	if err := c.quotaService.CheckQuizzes(ctx.UserContext(), getUserId(ctx)); err != nil {
+		return sendQuotaError(ctx, err)
+	}
+
+	quiz, err := c.quizService.DuplicateQuiz(ctx.UserContext(), quizId, getUserId(ctx))
+	if errors.Is(err, service.ErrQuizNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(quiz)
+}
+
+// TranslateQuiz handles the HTTP request to machine-translate a quiz into a draft owned by the user
+// With ?async=true it is answered with a background job whose result is the response.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) TranslateQuiz(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	if err := c.quotaService.CheckQuizzes(ctx.UserContext(), getUserId(ctx)); err != nil {
+		return sendQuotaError(ctx, err)
+	}
+
+	if runsAsJob(ctx) {
+		userId, language := getUserId(ctx), strings.Clone(ctx.Query("lang"))
+		return startJob(ctx, c.jobService, "translate", func(jobCtx context.Context, progress func(done float64)) (service.JobOutput, error) {
+			quiz, err := c.quizService.TranslateQuiz(jobCtx, c.translator, quizId, userId, language)
+			if err != nil {
+				return service.JobOutput{}, err
+			}
+
+			return service.JsonOutput(quiz)
+		})
+	}
+
+	quiz, err := c.quizService.TranslateQuiz(ctx.UserContext(), c.translator, quizId, getUserId(ctx), ctx.Query("lang"))
+	if errors.Is(err, service.ErrInvalidLanguage) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the language code is malformed
+	}
+	if errors.Is(err, service.ErrTranslationDisabled) {
+		return ctx.SendStatus(fiber.StatusNotImplemented) // Return 501 if no translator is configured
+	}
+	if errors.Is(err, service.ErrQuizNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(quiz)
+}
+
+// ShareQuizRequest represents the structure of the request body for sharing a quiz with a user
+type ShareQuizRequest struct {
+	UserId     primitive.ObjectID `json:"userId"`
+	Permission string             `json:"permission"` // "view", "host" or "edit", empty to take all rights away
+}
+
+// CreateShareLinkRequest represents the structure of the request body for creating a quiz share link
+type CreateShareLinkRequest struct {
+	Permission     string `json:"permission"`     // "view", "host" or "edit"
+	ExpiresInHours int    `json:"expiresInHours"` // How long the link works, 0 for a week
+}
+
+// GetSharing handles the HTTP request of a quiz's owner to list who the quiz is shared with
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) GetSharing(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	sharing, err := c.quizService.GetSharing(ctx.UserContext(), quizId, getUserId(ctx))
+	if err != nil {
+		return sendShareError(ctx, err)
+	}
+
+	return ctx.JSON(sharing)
+}
+
+// ShareQuiz handles the HTTP request of a quiz's owner to grant a user a right on the quiz
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) ShareQuiz(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	var req ShareQuizRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	err = c.quizService.ShareQuiz(ctx.UserContext(), quizId, getUserId(ctx), req.UserId, req.Permission)
+	if err != nil {
+		return sendShareError(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateShareLink handles the HTTP request of a quiz's owner to create a link granting a right on the quiz
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) CreateShareLink(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	var req CreateShareLinkRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	link, err := c.quizService.CreateShareLink(ctx.UserContext(), quizId, getUserId(ctx), req.Permission, time.Duration(req.ExpiresInHours)*time.Hour)
+	if err != nil {
+		return sendShareError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(link)
+}
+
+// RevokeShareLink handles the HTTP request of a quiz's owner to stop a share link from working
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) RevokeShareLink(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	err = c.quizService.RevokeShareLink(ctx.UserContext(), quizId, getUserId(ctx), ctx.Params("token"))
+	if err != nil {
+		return sendShareError(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// sendShareError responds with the status matching an error of sharing a quiz
+// Parameters:
+// - ctx: the context of the HTTP request
+// - err: the error returned by the service layer
+// Returns:
+// - error: the error itself if it has no matching status
+func sendShareError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidShare):
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the right, expiry or user is invalid
+	case errors.Is(err, service.ErrQuizNotFound):
+		return ctx.SendStatus(fiber.StatusNotFound)
+	case errors.Is(err, service.ErrQuizForbidden):
+		return ctx.SendStatus(fiber.StatusForbidden) // Return 403 if the quiz is not the user's to share
+	}
+
+	return err
+}
+
+// maxImportSize is the largest GIFT or Moodle XML file that can be imported
+const maxImportSize = 1 << 20
+
+// ImportQuizResponse represents the response returned after importing a quiz
+type ImportQuizResponse struct {
+	Quiz    entity.Quiz           `json:"quiz"`
+	Skipped []service.ImportIssue `json:"skipped"` // Items of the file that could not be converted
+}
+
+// ImportQuiz handles the HTTP request to create a quiz from a GIFT or Moodle XML file, sent as the multipart form field "file".
+// The format is taken from the "format" query parameter, or guessed from the file extension.
+// With ?async=true it is answered with a background job whose result is the response.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) ImportQuiz(ctx *fiber.Ctx) error {
+	header, err := ctx.FormFile("file")
+	if err != nil || header.Size > maxImportSize {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	extension := filepath.Ext(header.Filename)
+	format := ctx.Query("format")
+	if format == "" {
+		format = service.GiftFormat
+		if strings.EqualFold(extension, ".xml") {
+			format = service.MoodleFormat
+		}
+	}
+
+	// The quiz is named after the file unless a name is given
+	name := ctx.FormValue("name")
+	if name == "" {
+		name = strings.TrimSuffix(header.Filename, extension)
+	}
+
+	if err := c.quotaService.CheckQuizzes(ctx.UserContext(), getUserId(ctx)); err != nil {
+		return sendQuotaError(ctx, err)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	if runsAsJob(ctx) {
+		userId, name, format := getUserId(ctx), strings.Clone(name), strings.Clone(format)
+		return startJob(ctx, c.jobService, "import", func(jobCtx context.Context, progress func(done float64)) (service.JobOutput, error) {
+			quiz, skipped, err := c.quizService.ImportQuiz(jobCtx, userId, name, format, data)
+			if err != nil {
+				return service.JobOutput{}, err
+			}
+
+			return service.JsonOutput(ImportQuizResponse{
+				Quiz:    *quiz,
+				Skipped: skipped,
+			})
+		})
+	}
+
+	quiz, skipped, err := c.quizService.ImportQuiz(ctx.UserContext(), getUserId(ctx), name, format, data)
+	if errors.Is(err, service.ErrUnknownImportFormat) || errors.Is(err, service.ErrInvalidImport) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the file cannot be read
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(ImportQuizResponse{
+		Quiz:    *quiz,
+		Skipped: skipped,
+	})
+}
+
+// ExportQuiz handles the HTTP request to download a quiz in a format other assessment platforms can import.
+// The format is taken from the "format" query parameter, currently only "qti".
+// With ?async=true it is answered with a background job whose result is the response.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) ExportQuiz(ctx *fiber.Ctx) error {
+	// Retrieve the quiz ID from the URL parameters
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	quiz, err := c.quizService.GetQuizById(ctx.UserContext(), quizId)
+	if err != nil {
+		return err
+	}
+
+	if quiz == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if runsAsJob(ctx) {
+		format, exported := strings.Clone(ctx.Query("format")), *quiz
+		return startJob(ctx, c.jobService, "export", func(jobCtx context.Context, progress func(done float64)) (service.JobOutput, error) {
+			data, err := service.ExportQuiz(format, exported)
+			if err != nil {
+				return service.JobOutput{}, err
+			}
+
+			return service.JobOutput{Data: data, ContentType: "application/zip", FileName: "quiz-" + exported.Id.Hex() + ".zip"}, nil
+		})
+	}
+
+	data, err := service.ExportQuiz(ctx.Query("format"), *quiz)
+	if errors.Is(err, service.ErrUnknownExportFormat) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the format is not supported
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Attachment("quiz-" + quiz.Id.Hex() + ".zip")
+	return ctx.Send(data)
+}
+
 // GetQuizzes handles the HTTP request to retrieve all quizzes
 // Parameters:
 // - ctx: the context of the HTTP request
@@ -92,11 +622,79 @@ func (c QuizController) UpdateQuizById(ctx *fiber.Ctx) error {
 // - error: any error encountered during the process, or nil if successful
 func (c QuizController) GetQuizzes(ctx *fiber.Ctx) error {
 	// Fetch all quizzes using the service layer
-	quizzes, err := c.quizService.GetQuizzes()
+	quizzes, err := c.quizService.GetQuizzes(ctx.UserContext())
 	if err != nil {
 		return err
 	}
 
+	// Only quizzes of the open library and those shared with the user are listed
+	userId := getUserId(ctx)
+	quizzes = slices.DeleteFunc(quizzes, func(quiz entity.Quiz) bool {
+		return !service.CanAccessQuiz(quiz, userId, "", entity.ViewPermission)
+	})
+
+	// Host notes are only shown to those who may edit the quiz
+	for i, quiz := range quizzes {
+		if !service.CanAccessQuiz(quiz, userId, "", entity.EditPermission) {
+			quizzes[i] = stripHostNotes(quiz)
+		}
+	}
+
 	// Return the quizzes in JSON format
 	return ctx.JSON(quizzes)
 }
+
+// GetCacheStats handles the HTTP request to get the statistics of the quiz cache
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuizController) GetCacheStats(ctx *fiber.Ctx) error {
+	return ctx.JSON(c.quizService.GetCacheStats())
+}
+
+// hostNotesRequest tells which questions of a request to update a quiz carry host notes
+type hostNotesRequest struct {
+	Questions []struct {
+		HostNotes *string `json:"hostNotes"` // nil if the question was sent without host notes
+	} `json:"questions"`
+}
+
+// keepHostNotes gives the edited questions sent without host notes the notes saved for them,
+// so editors that do not show the notes cannot wipe them by saving
+// Parameters:
+// - stored: the questions before the edit
+// - edited: the questions after the edit, updated in place
+// - notes: which of the edited questions were sent with host notes
+func keepHostNotes(stored []entity.QuizQuestion, edited []entity.QuizQuestion, notes hostNotesRequest) {
+	saved := map[string]string{}
+	for _, question := range stored {
+		if question.Id != "" {
+			saved[question.Id] = question.HostNotes
+		}
+	}
+
+	for i := range edited {
+		if i < len(notes.Questions) && notes.Questions[i].HostNotes != nil {
+			continue
+		}
+
+		edited[i].HostNotes = saved[edited[i].Id]
+	}
+}
+
+// stripHostNotes returns a copy of a quiz without the private host notes of its questions
+// Parameters:
+// - quiz: the quiz to copy
+// Returns:
+// - entity.Quiz: the quiz as it may be shown publicly
+func stripHostNotes(quiz entity.Quiz) entity.Quiz {
+	questions := []entity.QuizQuestion{}
+	for _, question := range quiz.Questions {
+		question.HostNotes = ""
+		questions = append(questions, question)
+	}
+
+	quiz.Questions = questions
+	return quiz
+}
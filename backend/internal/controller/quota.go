@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+	"quiz.com/quiz/internal/service"
+)
+
+// QuotaController handles HTTP requests related to the limits of the users' plans
+type QuotaController struct {
+	quotaService *service.QuotaService
+}
+
+// Quota creates a new QuotaController instance
+// Parameters:
+// - quotaService: the service layer that enforces the limits of plans
+// Returns:
+// - A new instance of QuotaController
+func Quota(quotaService *service.QuotaService) QuotaController {
+	return QuotaController{
+		quotaService: quotaService,
+	}
+}
+
+// SetQuotaRequest represents the structure of the request body for changing the plan of a user
+type SetQuotaRequest struct {
+	Quota *entity.Quota `json:"quota"` // Limits of the user's plan, null for the deployment's defaults
+}
+
+// GetUsage handles the HTTP request of a user for what they used of their plan
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuotaController) GetUsage(ctx *fiber.Ctx) error {
+	usage, err := c.quotaService.GetUsage(ctx.UserContext(), getUserId(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(usage)
+}
+
+// SetQuota handles the HTTP request of an admin to change the plan of a user
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c QuotaController) SetQuota(ctx *fiber.Ctx) error {
+	userId, err := primitive.ObjectIDFromHex(ctx.Params("userId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	var req SetQuotaRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	err = c.quotaService.SetQuota(ctx.UserContext(), userId, req.Quota)
+	if errors.Is(err, service.ErrInvalidQuota) {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// sendQuotaError answers a request that went over the limits of the user's plan
+// Parameters:
+// - ctx: the context of the HTTP request
+// - err: the error returned by the quota service
+// Returns:
+// - error: the error itself if it is not about a limit
+func sendQuotaError(ctx *fiber.Ctx, err error) error {
+	// The message tells clients which limit was reached
+	switch {
+	case errors.Is(err, service.ErrQuotaExceeded):
+		return ctx.Status(fiber.StatusPaymentRequired).JSON(fiber.Map{"error": err.Error()}) // Return 402 if the plan allows no more
+	case errors.Is(err, service.ErrDailyLimit):
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()}) // Return 429 until the day is over
+	}
+
+	return err
+}
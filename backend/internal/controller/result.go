@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/service"
+)
+
+// ResultController handles HTTP requests related to game results and high scores
+type ResultController struct {
+	resultService *service.ResultService
+	quizService   *service.QuizService
+	jobService    *service.JobService
+}
+
+// Result creates a new ResultController instance
+// Parameters:
+// - resultService: the service layer that handles result-related operations
+// - quizService: the service layer that reads the quizzes results are analysed against
+// - jobService: the service layer that runs reports asked for with ?async=true
+// Returns:
+// - A new instance of ResultController
+func Result(resultService *service.ResultService, quizService *service.QuizService, jobService *service.JobService) ResultController {
+	return ResultController{
+		resultService: resultService,
+		quizService:   quizService,
+		jobService:    jobService,
+	}
+}
+
+// GetItemAnalysis handles the HTTP request to analyse the questions of a quiz over all games played of it.
+// With ?async=true it is answered with a background job whose result is the analysis.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ResultController) GetItemAnalysis(ctx *fiber.Ctx) error {
+	quizId, err := primitive.ObjectIDFromHex(ctx.Params("quizId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	quiz, err := c.quizService.GetQuizById(ctx.UserContext(), quizId)
+	if err != nil {
+		return err
+	}
+
+	if quiz == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if runsAsJob(ctx) {
+		analysed := *quiz
+		return startJob(ctx, c.jobService, "item-analysis", func(jobCtx context.Context, progress func(done float64)) (service.JobOutput, error) {
+			analysis, err := c.resultService.GetItemAnalysis(jobCtx, analysed)
+			if err != nil {
+				return service.JobOutput{}, err
+			}
+
+			return service.JsonOutput(analysis)
+		})
+	}
+
+	analysis, err := c.resultService.GetItemAnalysis(ctx.UserContext(), *quiz)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(analysis)
+}
+
+// GetProgress handles the HTTP request to get the signed in student's attempts at every quiz they played
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ResultController) GetProgress(ctx *fiber.Ctx) error {
+	// The quizzes carry the attempt policies the attempts are counted under
+	quizzes, err := c.quizService.GetQuizzes(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	progress, err := c.resultService.GetProgress(ctx.UserContext(), getUserId(ctx), quizzes)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(progress)
+}
+
+// GetQuizHighScores handles the HTTP request to get the best scores of a quiz
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ResultController) GetQuizHighScores(ctx *fiber.Ctx) error {
+	// Retrieve the quiz ID from the URL parameters
+	quizIdStr := ctx.Params("quizId")
+	quizId, err := primitive.ObjectIDFromHex(quizIdStr)
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	// Fetch the high scores using the service layer
+	scores, err := c.resultService.GetQuizHighScores(ctx.UserContext(), quizId)
+	if err != nil {
+		return err
+	}
+
+	// Return the high scores in JSON format
+	return ctx.JSON(scores)
+}
+
+// GetSharedResult handles the HTTP request to view the public results page of a game.
+// It needs no authentication, the token in the link is the secret.
+// Browsers receive an HTML page, other clients JSON.
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ResultController) GetSharedResult(ctx *fiber.Ctx) error {
+	result, err := c.resultService.GetSharedResult(ctx.UserContext(), ctx.Params("token"))
+	if err != nil {
+		return err
+	}
+
+	// Unknown and expired links look the same
+	if result == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if ctx.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) == fiber.MIMETextHTML {
+		page, err := service.RenderSharedResult(*result)
+		if err != nil {
+			return err
+		}
+
+		ctx.Type("html")
+		return ctx.SendString(page)
+	}
+
+	return ctx.JSON(result)
+}
+
+// GetGlobalHighScores handles the HTTP request to get the best scores across all quizzes
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c ResultController) GetGlobalHighScores(ctx *fiber.Ctx) error {
+	// Fetch the high scores using the service layer
+	scores, err := c.resultService.GetGlobalHighScores(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	// Return the high scores in JSON format
+	return ctx.JSON(scores)
+}
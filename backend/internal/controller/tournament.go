@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/service"
+)
+
+// TournamentController handles HTTP requests related to tournaments
+type TournamentController struct {
+	tournamentService *service.TournamentService
+	invitationService *service.InvitationService
+}
+
+// Tournament creates a new TournamentController instance
+// Parameters:
+// - tournamentService: the service layer that handles tournament-related operations
+// - invitationService: the service layer that emails invitations to tournaments
+// Returns:
+// - A new instance of TournamentController
+func Tournament(tournamentService *service.TournamentService, invitationService *service.InvitationService) TournamentController {
+	return TournamentController{
+		tournamentService: tournamentService,
+		invitationService: invitationService,
+	}
+}
+
+// CreateTournamentRequest represents the structure of the request body for creating a tournament
+type CreateTournamentRequest struct {
+	Name    string               `json:"name"`
+	Format  string               `json:"format"`
+	QuizIds []primitive.ObjectID `json:"quizIds"`
+}
+
+// CreateTournament handles the HTTP request to schedule a new tournament
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c TournamentController) CreateTournament(ctx *fiber.Ctx) error {
+	// Parse the request body into the CreateTournamentRequest struct
+	var req CreateTournamentRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	// Create the tournament using the service layer, owned by the signed in user
+	tournament, err := c.tournamentService.CreateTournament(ctx.UserContext(), getUserId(ctx), req.Name, req.Format, req.QuizIds)
+	if errors.Is(err, service.ErrInvalidTournament) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the input is invalid
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(tournament)
+}
+
+// GetTournamentById handles the HTTP request of the owner of a tournament to get it by its ID
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c TournamentController) GetTournamentById(ctx *fiber.Ctx) error {
+	// Retrieve the tournament ID from the URL parameters
+	tournamentId, err := primitive.ObjectIDFromHex(ctx.Params("tournamentId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	tournament, err := c.tournamentService.GetOwnTournament(ctx.UserContext(), tournamentId, getUserId(ctx))
+	if errors.Is(err, service.ErrTournamentNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if errors.Is(err, service.ErrNotTournamentOwner) {
+		return ctx.SendStatus(fiber.StatusForbidden) // Return 403 if the user did not schedule the tournament
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(tournament)
+}
+
+// GetStandings handles the HTTP request to get the leaderboard of a tournament
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c TournamentController) GetStandings(ctx *fiber.Ctx) error {
+	// Retrieve the tournament ID from the URL parameters
+	tournamentId, err := primitive.ObjectIDFromHex(ctx.Params("tournamentId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	tournament, err := c.tournamentService.GetTournamentById(ctx.UserContext(), tournamentId)
+	if err != nil {
+		return err
+	}
+
+	// If the tournament is not found, return 404 status
+	if tournament == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	standings, err := c.tournamentService.GetStandings(ctx.UserContext(), *tournament)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(standings)
+}
+
+// InviteRequest represents the structure of the request body for sending invitations
+type InviteRequest struct {
+	Emails []string `json:"emails"`
+}
+
+// Invite handles the HTTP request to email invitations to a tournament
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c TournamentController) Invite(ctx *fiber.Ctx) error {
+	// Retrieve the tournament ID from the URL parameters
+	tournamentId, err := primitive.ObjectIDFromHex(ctx.Params("tournamentId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	// Parse the request body into the InviteRequest struct
+	var req InviteRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	// Only the owner may invite, so the server cannot be used to email anyone under any name
+	userId := getUserId(ctx)
+	tournament, err := c.tournamentService.GetOwnTournament(ctx.UserContext(), tournamentId, userId)
+	if errors.Is(err, service.ErrTournamentNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if errors.Is(err, service.ErrNotTournamentOwner) {
+		return ctx.SendStatus(fiber.StatusForbidden) // Return 403 if the user did not schedule the tournament
+	}
+
+	if err != nil {
+		return err
+	}
+
+	invitations, err := c.invitationService.InviteToTournament(ctx.UserContext(), "user:"+userId.Hex(), *tournament, req.Emails)
+	if errors.Is(err, service.ErrInvalidInvitation) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if an address is invalid
+	}
+
+	var limited service.RateLimitError
+	if errors.As(err, &limited) {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		return ctx.SendStatus(fiber.StatusTooManyRequests) // Return 429 if the user sent their emails for the day
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// The emails are sent in the background, so the invitations are still pending
+	return ctx.Status(fiber.StatusAccepted).JSON(invitations)
+}
+
+// GetInvitations handles the HTTP request to get the invitations to a tournament and their delivery state
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c TournamentController) GetInvitations(ctx *fiber.Ctx) error {
+	// Retrieve the tournament ID from the URL parameters
+	tournamentId, err := primitive.ObjectIDFromHex(ctx.Params("tournamentId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	_, err = c.tournamentService.GetOwnTournament(ctx.UserContext(), tournamentId, getUserId(ctx))
+	if errors.Is(err, service.ErrTournamentNotFound) {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	if errors.Is(err, service.ErrNotTournamentOwner) {
+		return ctx.SendStatus(fiber.StatusForbidden) // Return 403 if the user did not schedule the tournament
+	}
+
+	if err != nil {
+		return err
+	}
+
+	invitations, err := c.invitationService.GetTournamentInvitations(ctx.UserContext(), tournamentId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(invitations)
+}
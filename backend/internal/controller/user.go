@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+	"quiz.com/quiz/internal/service"
+)
+
+// UserController handles HTTP requests related to user accounts
+type UserController struct {
+	userService *service.UserService
+}
+
+// User creates a new UserController instance
+// Parameters:
+// - userService: the service layer that handles user-related operations
+// Returns:
+// - A new instance of UserController
+func User(userService *service.UserService) UserController {
+	return UserController{
+		userService: userService,
+	}
+}
+
+// RegisterRequest represents the structure of the request body for creating an account
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// LoginRequest represents the structure of the request body for signing in
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// TokenResponse represents the response returned after a successful registration or login
+type TokenResponse struct {
+	Token string      `json:"token"`
+	User  entity.User `json:"user"`
+}
+
+// Register handles the HTTP request to create an account
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c UserController) Register(ctx *fiber.Ctx) error {
+	// Parse the request body into the RegisterRequest struct
+	var req RegisterRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	// Create the user using the service layer
+	user, err := c.userService.Register(ctx.UserContext(), req.Email, req.Name, req.Password)
+	if errors.Is(err, service.ErrInvalidUser) {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the input is invalid
+	}
+
+	if errors.Is(err, service.ErrEmailTaken) || errors.Is(err, service.ErrNameTaken) {
+		return ctx.SendStatus(fiber.StatusConflict) // Return 409 if the email or name is already registered
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.sendToken(ctx, *user)
+}
+
+// Login handles the HTTP request to sign in
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c UserController) Login(ctx *fiber.Ctx) error {
+	// Parse the request body into the LoginRequest struct
+	var req LoginRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	// Check the credentials using the service layer
+	user, err := c.userService.Login(ctx.UserContext(), req.Email, req.Password)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		return ctx.SendStatus(fiber.StatusUnauthorized) // Return 401 if the credentials are wrong
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.sendToken(ctx, *user)
+}
+
+// VerifyEmail handles the HTTP request of a link verifying an email address
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c UserController) VerifyEmail(ctx *fiber.Ctx) error {
+	err := c.userService.VerifyEmail(ctx.UserContext(), ctx.Query("token"))
+	if errors.Is(err, service.ErrInvalidToken) {
+		return ctx.Status(fiber.StatusBadRequest).SendString("This link is invalid or expired, ask for a new one.") // Return 400 if the link cannot be trusted
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// The link is opened in a browser, so it is answered in words
+	return ctx.SendString("Your email address is verified.")
+}
+
+// SendVerification handles the HTTP request to email a new link verifying the signed in user's address
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c UserController) SendVerification(ctx *fiber.Ctx) error {
+	err := c.userService.SendVerification(ctx.UserContext(), getUserId(ctx))
+	var limited service.RateLimitError
+	if errors.As(err, &limited) {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		return ctx.SendStatus(fiber.StatusTooManyRequests) // Return 429 if the user asked for too many emails
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// The email is sent in the background
+	return ctx.SendStatus(fiber.StatusAccepted)
+}
+
+// GetMe handles the HTTP request to get the signed in user's profile
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c UserController) GetMe(ctx *fiber.Ctx) error {
+	return c.sendProfile(ctx, getUserId(ctx))
+}
+
+// GetProfile handles the HTTP request to get a user's public profile
+// Parameters:
+// - ctx: the context of the HTTP request
+// Returns:
+// - error: any error encountered during the process, or nil if successful
+func (c UserController) GetProfile(ctx *fiber.Ctx) error {
+	// Retrieve the user ID from the URL parameters
+	userId, err := primitive.ObjectIDFromHex(ctx.Params("userId"))
+	if err != nil {
+		return ctx.SendStatus(fiber.StatusBadRequest) // Return 400 if the ID is invalid
+	}
+
+	return c.sendProfile(ctx, userId)
+}
+
+// sendProfile responds with the public profile of a user
+func (c UserController) sendProfile(ctx *fiber.Ctx, userId primitive.ObjectID) error {
+	profile, err := c.userService.GetProfile(ctx.UserContext(), userId)
+	if err != nil {
+		return err
+	}
+
+	// If the user is not found, return 404 status
+	if profile == nil {
+		return ctx.SendStatus(fiber.StatusNotFound)
+	}
+
+	return ctx.JSON(profile)
+}
+
+// sendToken responds with a new access token for the user
+func (c UserController) sendToken(ctx *fiber.Ctx, user entity.User) error {
+	token, err := c.userService.IssueToken(user)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(TokenResponse{
+		Token: token,
+		User:  user,
+	})
+}
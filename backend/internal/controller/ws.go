@@ -1,23 +1,34 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+
 	"github.com/gofiber/contrib/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"quiz.com/quiz/internal/service"
 )
 
 // WebsocketController handles WebSocket connections and communication
 type WebsocketController struct {
-	netService *service.NetService
+	netService       *service.NetService
+	editorService    *service.EditorService
+	compressionLevel int // Flate level applied to connections that negotiated compression
 }
 
 // Ws creates a new WebsocketController instance
 // Parameters:
 // - netService: the service layer that handles network-related operations
+// - editorService: the service layer that tracks who is editing which quiz
+// - compressionLevel: flate level used when a connection negotiated compression
 // Returns:
 // - A new instance of WebsocketController
-func Ws(netService *service.NetService) WebsocketController {
+func Ws(netService *service.NetService, editorService *service.EditorService, compressionLevel int) WebsocketController {
 	return WebsocketController{
-		netService: netService,
+		netService:       netService,
+		editorService:    editorService,
+		compressionLevel: compressionLevel,
 	}
 }
 
@@ -30,6 +41,24 @@ func (c WebsocketController) Ws(con *websocket.Conn) {
 		msg []byte // message content
 		err error  // error handling
 	)
+
+	// Reject oversized frames before they are buffered in memory
+	con.SetReadLimit(service.MaxMessageSize)
+
+	// Has no effect unless compression was negotiated during the upgrade
+	if err := con.SetCompressionLevel(c.compressionLevel); err != nil {
+		fmt.Println(err)
+	}
+
+	// Cancel database work started for this connection once it closes
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Players reloading the page come back with the session token they were given when joining
+	if token := con.Query("session"); token != "" && !c.netService.ResumeSession(con, token) {
+		return
+	}
+
 	for {
 		// Read incoming WebSocket message
 		if mt, msg, err = con.ReadMessage(); err != nil {
@@ -39,6 +68,104 @@ func (c WebsocketController) Ws(con *websocket.Conn) {
 		}
 
 		// Handle the incoming message using the service layer
-		c.netService.OnIncomingMessage(con, mt, msg)
+		c.netService.OnIncomingMessage(ctx, con, mt, msg)
+	}
+}
+
+// widgetReadLimit is the largest frame accepted from a leaderboard widget, which has nothing to say
+const widgetReadLimit = 512
+
+// Leaderboard streams the live leaderboard of a game to an embedded widget.
+// The connection is read-only: anything the widget sends is ignored and never reaches the game.
+// Parameters:
+// - con: the WebSocket connection object
+func (c WebsocketController) Leaderboard(con *websocket.Conn) {
+	code := con.Params("code")
+	con.SetReadLimit(widgetReadLimit)
+
+	if err := c.netService.WatchLeaderboard(code, con); err != nil {
+		fmt.Println(err)
+		con.Close()
+		return
+	}
+	defer c.netService.StopWatchingLeaderboard(code, con)
+
+	// Keep reading so closes and pings are handled, until the widget goes away
+	for {
+		if _, _, err := con.ReadMessage(); err != nil {
+			break
+		}
 	}
 }
+
+// editorReadLimit is the largest frame accepted from a quiz editor, which only says which question it has open
+const editorReadLimit = 1024
+
+// EditorRequest is a message from a quiz editor
+type EditorRequest struct {
+	Type       string `json:"type"`       // Kind of message, only "focus" is known
+	QuestionId string `json:"questionId"` // ID of the question the editor opened, empty if none
+}
+
+// Editor lets the signed in editors of a quiz see each other and the changes they save.
+// The user is authenticated by RequireSocketUser before the upgrade.
+// Parameters:
+// - con: the WebSocket connection object
+func (c WebsocketController) Editor(con *websocket.Conn) {
+	quizId, err := primitive.ObjectIDFromHex(con.Params("quizId"))
+	if err != nil {
+		con.Close()
+		return
+	}
+
+	userId, _ := con.Locals(userIdLocal).(primitive.ObjectID)
+	claims, _ := con.Locals(claimsLocal).(*service.TokenClaims)
+	if claims == nil {
+		con.Close()
+		return
+	}
+
+	con.SetReadLimit(editorReadLimit)
+	if err := c.editorService.Join(quizId, userId, claims.Name, con); err != nil {
+		fmt.Println(err)
+		con.Close()
+		return
+	}
+	defer c.editorService.Leave(quizId, con)
+
+	for {
+		_, msg, err := con.ReadMessage()
+		if err != nil {
+			break
+		}
+
+		// Unknown and malformed messages are ignored, they never reach the other editors
+		var req EditorRequest
+		if json.Unmarshal(msg, &req) != nil || req.Type != "focus" {
+			continue
+		}
+
+		c.editorService.Focus(quizId, con, req.QuestionId)
+	}
+}
+
+// Dashboard streams the stats of the whole server to an ops dashboard at regular intervals.
+// The admin is authenticated by RequireSocketAdmin before the upgrade; anything the dashboard sends is ignored.
+// Parameters:
+// - con: the WebSocket connection object
+func (c WebsocketController) Dashboard(con *websocket.Conn) {
+	con.SetReadLimit(widgetReadLimit)
+
+	// Stop the feed once the dashboard goes away
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := con.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	c.netService.WatchPlatform(ctx, con)
+}
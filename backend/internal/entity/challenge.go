@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Intervals a challenge can be scheduled at
+const (
+	DailyChallenge  = "daily"  // A game is hosted every day
+	WeeklyChallenge = "weekly" // A game is hosted every week
+)
+
+// Challenge represents a game the server hosts on its own on a schedule, with a leaderboard over a season
+type Challenge struct {
+	Id           primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the challenge
+	Name         string             `json:"name"`          // Name of the challenge, used in announcements
+	OwnerId      primitive.ObjectID `json:"ownerId"`       // ID of the user who scheduled the challenge and hosts its games
+	QuizId       primitive.ObjectID `json:"quizId"`        // ID of the quiz played, zero to pick a random quiz of the open library each time
+	Interval     string             `json:"interval"`      // How often a game is hosted, see DailyChallenge and WeeklyChallenge
+	LobbySeconds int                `json:"lobbySeconds"`  // How long players can join a game before it starts
+	Webhooks     []Webhook          `json:"-"`             // Channels the join codes are posted to (excluded from JSON, the URLs are secret)
+
+	Season          int            `json:"season"`          // Number of the current season, starting at 1
+	SeasonStartedAt time.Time      `json:"seasonStartedAt"` // When the current season started, only games after it count towards the leaderboard
+	NextRunAt       time.Time      `json:"nextRunAt"`       // When the next game is hosted
+	Runs            []ChallengeRun `json:"runs"`            // Games hosted so far, oldest first
+	CreatedAt       time.Time      `json:"createdAt"`       // When the challenge was scheduled
+}
+
+// ChallengeRun represents a single game hosted for a challenge
+type ChallengeRun struct {
+	QuizId    primitive.ObjectID `json:"quizId"`    // ID of the quiz played
+	Code      string             `json:"code"`      // Join code of the game
+	StartedAt time.Time          `json:"startedAt"` // When the game was hosted
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Comment represents a reviewer's note on a question of a quiz
+type Comment struct {
+	Id         primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the comment
+	QuizId     primitive.ObjectID `json:"quizId"`        // ID of the quiz the question belongs to
+	QuestionId string             `json:"questionId"`    // ID of the commented question in the quiz
+	UserId     primitive.ObjectID `json:"userId"`        // ID of the user who wrote the comment
+	Author     string             `json:"author"`        // Name of the user when they wrote the comment
+	Text       string             `json:"text"`          // The comment
+	CreatedAt  time.Time          `json:"createdAt"`     // When the comment was written
+	Resolved   bool               `json:"resolved"`      // Whether the comment has been dealt with
+	ResolvedBy string             `json:"resolvedBy"`    // Name of the user who resolved the comment, empty until then
+	ResolvedAt time.Time          `json:"resolvedAt"`    // When the comment was resolved, zero until then
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// Embedding is the vector an embedding model computed for a text, cached so each text is only sent once
+type Embedding struct {
+	Id        string    `json:"id" bson:"_id"` // Hex encoded SHA-256 of the model and the text, see service.embeddingKey
+	Model     string    `json:"model"`         // Name of the model that computed the vector
+	Vector    []float32 `json:"vector"`        // The embedding
+	CreatedAt time.Time `json:"createdAt"`     // When the vector was computed
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HighScore represents a player's score in a finished game, kept for the high-score tables
+type HighScore struct {
+	Id         primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the entry
+	QuizId     primitive.ObjectID `json:"quizId"`        // ID of the quiz that was played
+	QuizName   string             `json:"quizName"`      // Name of the quiz at the time it was played
+	Name       string             `json:"name"`          // Name of the player
+	Points     int                `json:"points"`        // Points the player scored
+	AchievedAt time.Time          `json:"achievedAt"`    // When the game ended
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Delivery states of an invitation
+const (
+	InvitationPending = "pending" // Waiting to be sent
+	InvitationSent    = "sent"    // Accepted by the mail server
+	InvitationFailed  = "failed"  // Could not be sent, see Error
+)
+
+// Invitation represents an email inviting someone to a game or tournament
+type Invitation struct {
+	Id           primitive.ObjectID `json:"id" bson:"_id"`   // Unique identifier for the invitation
+	Email        string             `json:"email"`           // Address the invitation is sent to
+	Code         string             `json:"code,omitempty"`  // Join code of the invited game, empty for tournaments
+	TournamentId primitive.ObjectID `json:"tournamentId"`    // ID of the invited tournament, zero for games
+	Status       string             `json:"status"`          // Delivery state, see InvitationPending and friends
+	Error        string             `json:"error,omitempty"` // Why sending failed
+	CreatedAt    time.Time          `json:"createdAt"`       // When the invitation was created
+	SentAt       time.Time          `json:"sentAt"`          // When the invitation was sent, zero until then
+}
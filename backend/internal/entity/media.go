@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Media represents an uploaded image or sound that questions can show
+type Media struct {
+	Id          primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the media, also the ID of its stored file
+	OwnerId     primitive.ObjectID `json:"-"`             // ID of the user who uploaded the media
+	Name        string             `json:"name"`          // Original file name
+	Type        string             `json:"type"`          // Kind of media, see ImageMedia and AudioMedia
+	ContentType string             `json:"contentType"`   // MIME type detected from the content
+	Size        int64              `json:"size"`          // Size of the content in bytes
+	Hash        string             `json:"hash"`          // Hex encoded SHA-256 of the content
+	Variants    []MediaVariant     `json:"variants"`      // Smaller renditions of an image, empty for sounds
+	CreatedAt   time.Time          `json:"createdAt"`     // When the media was uploaded
+}
+
+// Sizes images are rendered in, so every client can download what it needs
+const (
+	ThumbnailSize = "thumbnail" // For lists and editors
+	MediumSize    = "medium"    // For phones
+	FullSize      = "full"      // For the big screen of the host
+)
+
+// MediaVariant represents a resized rendition of an uploaded image
+type MediaVariant struct {
+	Size        string `json:"size"`        // Name of the size, see ThumbnailSize and friends
+	Width       int    `json:"width"`       // Width in pixels
+	Height      int    `json:"height"`      // Height in pixels
+	ContentType string `json:"contentType"` // MIME type of the rendition
+	Bytes       int64  `json:"bytes"`       // Size of the rendition in bytes
+	Hash        string `json:"hash"`        // Hex encoded SHA-256 of the rendition
+}
@@ -1,22 +1,123 @@
 package entity
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Quiz represents a quiz entity with an ID, name, and a list of questions
 type Quiz struct {
-	Id        primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the quiz
-	Name      string             `json:"name"`          // Name of the quiz
-	Questions []QuizQuestion     `json:"questions"`     // List of questions in the quiz
+	Id           primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the quiz
+	Name         string             `json:"name"`          // Name of the quiz
+	QuizSettings `bson:",inline"`   // Defaults and scoring of the quiz's questions
+	Questions    []QuizQuestion     `json:"questions"` // List of questions in the quiz
+	Webhooks     []Webhook          `json:"-"`         // Channels games of the quiz are announced in (excluded from JSON, the URLs are secret)
+
+	OwnerId    primitive.ObjectID `json:"ownerId"`  // ID of the user who owns the quiz, zero for quizzes of the open library
+	Template   bool               `json:"template"` // Whether admins marked the quiz as a template every signed in user can duplicate
+	Shares     []QuizShare        `json:"-"`        // Users the owner granted rights on the quiz
+	ShareLinks []QuizShareLink    `json:"-"`        // Links granting rights to whoever has them (excluded from JSON, the tokens are secret)
+
+	Language      string             `json:"language"`      // Code of the language of the quiz's text, e.g. "de"; empty if unknown
+	TranslationOf primitive.ObjectID `json:"translationOf"` // ID of the quiz this one was machine translated from, zero for originals
+	Draft         bool               `json:"draft"`         // Whether the quiz was machine translated and its owner did not save it since
+
+	UpdatedAt time.Time `json:"updatedAt"` // When the name, settings or questions last changed, zero for quizzes not saved since this was tracked
+}
+
+// Rights on a quiz, each includes the ones before it
+const (
+	ViewPermission = "view" // See the quiz
+	HostPermission = "host" // Host games of the quiz
+	EditPermission = "edit" // Change the quiz
+)
+
+// QuizShare grants a user rights on a quiz
+type QuizShare struct {
+	UserId     primitive.ObjectID `json:"userId"`     // ID of the user
+	Permission string             `json:"permission"` // The granted right, see ViewPermission
+}
+
+// QuizShareLink grants rights on a quiz to whoever has its token
+type QuizShareLink struct {
+	Token      string    `json:"token"`      // Secret token of the link
+	Permission string    `json:"permission"` // The granted right, see ViewPermission
+	ExpiresAt  time.Time `json:"expiresAt"`  // When the link stops working
+}
+
+// QuizSettings are the choices of the author that apply to all questions of a quiz
+type QuizSettings struct {
+	DefaultTime int `json:"defaultTime"` // Time in seconds given to questions without a time of their own, 0 for none
+	TotalPoints int `json:"totalPoints"` // Points a player answering every question correctly and first can earn in all, 0 to keep the usual rewards
+
+	Grades   []GradeBoundary `json:"grades"`   // Boundaries players are graded by at the end of a game, none to not grade
+	Attempts AttemptPolicy   `json:"attempts"` // How retakes of the quiz by the same student count
+
+	TieBreaker *QuizQuestion `json:"tieBreaker"` // Question kept back from the quiz and asked only when players tie for first place, nil for none
+}
+
+// Which attempt of a student counts when a quiz is retaken
+const (
+	KeepBest   = "best"   // The attempt with the most points
+	KeepLatest = "latest" // The most recent attempt
+	KeepFirst  = "first"  // The first attempt, retakes are practice
+)
+
+// AttemptPolicy decides how the attempts of a student at a quiz count towards their progress
+type AttemptPolicy struct {
+	Max  int    `json:"max"`  // Most attempts that count, later ones are kept as practice; 0 for no limit
+	Keep string `json:"keep"` // Which of the counted attempts is kept, see KeepBest; empty keeps the best
+}
+
+// GradeBoundary is the lowest score that earns a grade
+type GradeBoundary struct {
+	Name       string  `json:"name"`       // The grade, e.g. "A" or "Pass"
+	MinPercent float64 `json:"minPercent"` // Share of the most points a player could earn needed for the grade, from 0 to 100
+	Pass       bool    `json:"pass"`       // Whether the grade passes the quiz
 }
 
 // QuizQuestion represents a single question in a quiz
 type QuizQuestion struct {
-	Id      string       `json:"id"`      // Unique identifier for the question
-	Name    string       `json:"name"`    // The text or title of the question
-	Time    int          `json:"time"`    // Time allotted to answer the question in seconds
-	Choices []QuizChoice `json:"choices"` // List of answer choices for the question
+	Id        string          `json:"id"`        // Unique identifier for the question
+	Name      string          `json:"name"`      // The text or title of the question
+	Type      string          `json:"type"`      // Kind of question, see ChoiceQuestion; empty for a multiple choice question
+	Time      int             `json:"time"`      // Time allotted to answer the question in seconds, 0 to take the quiz's default
+	MaxPoints int             `json:"maxPoints"` // Most points a correct answer can earn, the usual rewards are scaled down to it; 0 for no cap
+	Choices   []QuizChoice    `json:"choices"`   // List of answer choices for the question
+	HostNotes string          `json:"hostNotes"` // Private notes for the host, never shown to players
+	Hint      string          `json:"hint"`      // Clue players may buy for points while answering, empty for none
+	Media     []QuestionMedia `json:"media"`     // Images and sounds shown with the question
+	Stats     QuestionStats   `json:"stats"`     // How players did on the question across all games, kept by the server
+}
+
+// Kinds of questions, deciding which answers they take
+const (
+	ChoiceQuestion = "choice" // Players pick one of the choices
+)
+
+// QuestionStats aggregates the answers to a question across all games of its quiz
+type QuestionStats struct {
+	Answered   int     `json:"answered"`   // Number of answers given in all games
+	Correct    int     `json:"correct"`    // Number of correct answers given in all games
+	Difficulty float64 `json:"difficulty"` // Share of wrong answers, from 0 for too easy to 1 for too hard; 0 until answered
+}
+
+// Kinds of media a question can show
+const (
+	ImageMedia = "image"
+	AudioMedia = "audio"
+	VideoMedia = "video"
+)
+
+// QuestionMedia represents an image, sound or video shown with a quiz question
+type QuestionMedia struct {
+	Type    string             `json:"type"`    // Kind of media, see ImageMedia and AudioMedia
+	MediaId primitive.ObjectID `json:"mediaId"` // ID of uploaded media, zero for external URLs
+	Url     string             `json:"url"`     // Where the media is served from; signed when the game starts for uploaded media
+	Hash    string             `json:"hash"`    // Hash of the media content, empty if unknown
+
+	Sizes map[string]string `json:"sizes,omitempty"` // Signed URLs of resized renditions by size, filled when the game starts
 }
 
 // QuizChoice represents a possible answer choice for a quiz question
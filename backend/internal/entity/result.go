@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GameResult represents the stored outcome of a finished game
+type GameResult struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the result
+	QuizId    primitive.ObjectID `json:"quizId"`        // ID of the quiz that was played
+	QuizName  string             `json:"quizName"`      // Name of the quiz at the time it was played
+	Code      string             `json:"code"`          // Join code the game used
+	EndedAt   time.Time          `json:"endedAt"`       // When the game ended
+	Players   []PlayerResult     `json:"players"`       // Final results of every player, ordered by points
+	Awards    []GameAward        `json:"awards"`        // Awards handed out at the end of the game
+	Questions []QuestionResult   `json:"questions"`     // How players did on each question
+	MaxPoints int                `json:"maxPoints"`     // Most points a player could earn, to grade the points against
+
+	HostActions []HostAction `json:"hostActions"` // What the host did during the game, oldest first
+
+	TournamentId primitive.ObjectID `json:"tournamentId"` // ID of the tournament the game belonged to, zero if none
+	Round        int                `json:"round"`        // Index of the tournament round the game was played as
+	ChallengeId  primitive.ObjectID `json:"challengeId"`  // ID of the scheduled challenge the game was hosted for, zero if none
+	Anonymized   bool               `json:"anonymized"`   // Whether the players are labelled by rank instead of their names
+
+	ShareToken     string    `json:"-"` // Secret token of the public results link, empty if the result is not shared
+	ShareExpiresAt time.Time `json:"-"` // When the public results link stops working
+}
+
+// PlayerResult represents a single player's final result in a game
+type PlayerResult struct {
+	UserId      primitive.ObjectID `json:"userId"`      // ID of the authenticated user, zero for anonymous players
+	Name        string             `json:"name"`        // Player's name
+	Points      int                `json:"points"`      // Total points scored
+	Answered    int                `json:"answered"`    // Number of questions answered
+	Correct     int                `json:"correct"`     // Number of questions answered correctly
+	AverageTime float64            `json:"averageTime"` // Average seconds taken to answer
+	Answers     []AnswerResult     `json:"answers"`     // Final answer to each question the player answered, in question order
+	Grade       *PlayerGrade       `json:"grade"`       // The player's grade, nil if the quiz is not graded
+	Hints       int                `json:"hints"`       // Number of hints the player bought
+	HintPoints  int                `json:"hintPoints"`  // Points the player paid for hints
+}
+
+// PlayerGrade is the grade a player earned in a quiz
+type PlayerGrade struct {
+	Percent float64 `json:"percent"` // Points as a share of the most points the player could earn, from 0 to 100
+	Name    string  `json:"name"`    // The grade, empty if the player reached no boundary
+	Passed  bool    `json:"passed"`  // Whether the grade passes the quiz
+}
+
+// AnswerResult represents a player's final answer to a single question
+type AnswerResult struct {
+	QuestionId string `json:"questionId"`           // ID of the question in the quiz
+	Choice     int    `json:"choice"`               // Index of the chosen answer
+	Correct    bool   `json:"correct"`              // Whether the chosen answer was correct
+	Confidence string `json:"confidence,omitempty"` // How sure the player was in confidence mode, empty otherwise
+}
+
+// QuestionResult represents how players did on a single question in a game
+type QuestionResult struct {
+	QuestionId string `json:"questionId"` // ID of the question in the quiz
+	Answered   int    `json:"answered"`   // Number of players who answered
+	Correct    int    `json:"correct"`    // Number of players who answered correctly
+	MaxPoints  int    `json:"maxPoints"`  // Most points the question could earn
+	Hints      int    `json:"hints"`      // Number of players who bought the hint
+}
+
+// HostAction is something the host did during a game, kept for accountability in graded games
+type HostAction struct {
+	At       time.Time `json:"at"`               // When the host acted
+	Action   string    `json:"action"`           // What the host did, e.g. "kick"
+	Question int       `json:"question"`         // Index of the question the game was at, -1 before the first
+	Player   string    `json:"player,omitempty"` // Name of the player the action was aimed at, if any
+	Detail   string    `json:"detail,omitempty"` // More about the action, e.g. the settings that were changed
+}
+
+// GameAward represents a fun award given to a player at the end of a game
+type GameAward struct {
+	Type   string  `json:"type"`   // Kind of award, e.g. "fastestAnswer"
+	Player string  `json:"player"` // Name of the player receiving the award
+	Value  float64 `json:"value"`  // The achievement, e.g. seconds or number of questions
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Formats a tournament can be played in
+const (
+	PointsFormat  = "points"  // Points of every round are added up
+	BracketFormat = "bracket" // Only the top half of each round advances to the next
+)
+
+// Tournament represents a series of scheduled games with shared standings
+type Tournament struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the tournament
+	OwnerId   primitive.ObjectID `json:"ownerId"`       // ID of the user who scheduled the tournament and hosts its rounds
+	Name      string             `json:"name"`          // Name of the tournament
+	Format    string             `json:"format"`        // How standings are decided, see PointsFormat and BracketFormat
+	Rounds    []TournamentRound  `json:"rounds"`        // Scheduled games, played in order
+	CreatedAt time.Time          `json:"createdAt"`     // When the tournament was created
+}
+
+// TournamentRound represents a single scheduled game of a tournament
+type TournamentRound struct {
+	QuizId     primitive.ObjectID `json:"quizId"`   // ID of the quiz played in this round
+	ResultId   primitive.ObjectID `json:"resultId"` // ID of the stored game result, zero until the round is played
+	GameCode   string             `json:"-"`        // Join code of the game hosted for the round, empty while none is
+	ReservedAt time.Time          `json:"-"`        // When the game hosted for the round claimed it
+}
+
+// TournamentStanding represents a player's position in a tournament
+type TournamentStanding struct {
+	UserId       primitive.ObjectID `json:"userId"`       // ID of the player's account, zero for players who were not signed in
+	Name         string             `json:"name"`         // Player's name
+	Points       int                `json:"points"`       // Points scored across all rounds
+	RoundsPlayed int                `json:"roundsPlayed"` // Number of rounds the player took part in
+	Eliminated   bool               `json:"eliminated"`   // Whether the player is out of a bracket tournament
+}
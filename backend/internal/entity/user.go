@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultRating is the rating every user starts with
+const DefaultRating = 1000
+
+// User represents a registered account that can author quizzes and play rated games
+type User struct {
+	Id            primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the user
+	Email         string             `json:"email"`         // Email address used to sign in
+	EmailVerified bool               `json:"emailVerified"` // Whether the user proved they own the email address, or an admin created the account
+	Name          string             `json:"name"`          // Display name of the user, unique among accounts
+	NameKey       string             `json:"-"`             // Name folded by NameKey, under a unique index
+	PasswordHash  []byte             `json:"-"`             // Bcrypt hash of the user's password (excluded from JSON)
+	Role          string             `json:"role"`          // Role of the user, e.g. "user" or "admin"
+	Rating        int                `json:"rating"`        // Elo-style rating updated after each rated game
+	GamesPlayed   int                `json:"gamesPlayed"`   // Number of rated games the user finished
+	CreatedAt     time.Time          `json:"createdAt"`     // When the account was created
+	Quota         *Quota             `json:"quota"`         // Limits of the user's plan, nil for the deployment's defaults
+}
+
+// NameKey folds a display name so names differing only in case or spacing count as the same,
+// keeping anyone from registering the name of someone else
+// Parameters:
+// - name: the display name
+// Returns:
+// - string: the key stored under the unique index
+func NameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Quota limits what a user can do on a hosted deployment, zero meaning no limit
+type Quota struct {
+	Quizzes     int `json:"quizzes"`     // Most quizzes the user can own
+	Players     int `json:"players"`     // Most players who can join a game the user hosts
+	GamesPerDay int `json:"gamesPerDay"` // Most games the user can host per day, counted in UTC
+}
+
+// DailyUsage counts what a user did on a single day
+type DailyUsage struct {
+	Id     primitive.ObjectID `json:"id" bson:"_id"` // Unique identifier for the count
+	UserId primitive.ObjectID `json:"userId"`        // ID of the user
+	Day    string             `json:"day"`           // The day in UTC, formatted as 2006-01-02
+	Games  int                `json:"games"`         // Number of games the user hosted on the day
+}
+
+// Roles a user can have
+const (
+	UserRole  = "user"
+	AdminRole = "admin"
+)
@@ -0,0 +1,13 @@
+package entity
+
+// Kinds of chat services a webhook can post to
+const (
+	SlackWebhook   = "slack"
+	DiscordWebhook = "discord"
+)
+
+// Webhook represents an incoming webhook of a Slack or Discord channel that game announcements are posted to
+type Webhook struct {
+	Kind string `json:"kind"` // Chat service of the webhook, see SlackWebhook and DiscordWebhook
+	Url  string `json:"url"`  // URL of the webhook
+}
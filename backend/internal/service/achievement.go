@@ -0,0 +1,146 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/entity"
+)
+
+// RoundEvent identifies a notable outcome of a question that the host screen can celebrate
+type RoundEvent string
+
+const (
+	NoCorrectEvent  RoundEvent = "noCorrect"  // Nobody answered the question correctly
+	AllCorrectEvent RoundEvent = "allCorrect" // Every player answered the question correctly
+	ComebackEvent   RoundEvent = "comeback"   // A player climbed into the top 3
+)
+
+// Award types handed out at the end of a game
+const (
+	FastestAnswerAward   = "fastestAnswer"   // Quickest correct answer, value in seconds
+	LongestStreakAward   = "longestStreak"   // Most consecutive correct answers
+	BiggestComebackAward = "biggestComeback" // Most leaderboard places climbed from the lowest position
+)
+
+// topPlayerCount is the number of players shown on the leaderboard
+const topPlayerCount = 3
+
+// getTopPlayerIds returns the IDs of the highest scoring players, without reordering g.Players
+// Returns:
+// - []uuid.UUID: up to three player IDs ordered by points
+func (g *Game) getTopPlayerIds() []uuid.UUID {
+	players := slices.Clone(g.Players)
+	g.sortPlayers(players)
+
+	ids := []uuid.UUID{}
+	for i := 0; i < min(topPlayerCount, len(players)); i++ {
+		ids = append(ids, players[i].Id)
+	}
+
+	return ids
+}
+
+// getRoundEvents detects notable outcomes of the current question from the recorded answers
+// Returns:
+// - []AchievementPacket: the events to announce, possibly empty
+func (g *Game) getRoundEvents() []AchievementPacket {
+	events := []AchievementPacket{}
+	if len(g.Players) == 0 {
+		return events
+	}
+
+	correct := []string{}
+	for _, player := range g.Players {
+		if answer, ok := player.Answers[g.CurrentQuestion]; ok && answer.Correct {
+			correct = append(correct, player.Name)
+		}
+	}
+
+	switch len(correct) {
+	case 0:
+		events = append(events, AchievementPacket{Event: NoCorrectEvent, Players: []string{}})
+	case len(g.Players):
+		events = append(events, AchievementPacket{Event: AllCorrectEvent, Players: correct})
+	}
+
+	// A comeback needs a previous ranking to climb from
+	top := g.getTopPlayerIds()
+	if g.previousTop != nil {
+		climbers := []string{}
+		for _, id := range top {
+			if slices.Contains(g.previousTop, id) {
+				continue
+			}
+
+			if player := g.getPlayerById(id); player != nil && player.Points > 0 {
+				climbers = append(climbers, player.Name)
+			}
+		}
+
+		if len(climbers) > 0 {
+			events = append(events, AchievementPacket{Event: ComebackEvent, Players: climbers})
+		}
+	}
+
+	g.previousTop = top
+	return events
+}
+
+// getRanks returns every player's leaderboard position, without reordering g.Players
+// Returns:
+// - map[uuid.UUID]int: the 1-based rank of each player by ID
+func (g *Game) getRanks() map[uuid.UUID]int {
+	players := slices.Clone(g.Players)
+	g.sortPlayers(players)
+
+	ranks := map[uuid.UUID]int{}
+	for i, player := range players {
+		ranks[player.Id] = i + 1
+	}
+
+	return ranks
+}
+
+// updateLowestRanks records the worst position each player has held so far
+func (g *Game) updateLowestRanks() {
+	ranks := g.getRanks()
+	for _, player := range g.Players {
+		player.LowestRank = max(player.LowestRank, ranks[player.Id])
+	}
+}
+
+// getAwards determines the players who earned each award over the whole game
+// Returns:
+// - []entity.GameAward: the awards, omitting those nobody qualified for
+func (g *Game) getAwards() []entity.GameAward {
+	awards := []entity.GameAward{}
+	ranks := g.getRanks()
+
+	var fastest, streak, comeback *entity.GameAward
+	for _, player := range g.Players {
+		for _, answer := range player.Answers {
+			seconds := roundSeconds(answer.Elapsed)
+			if answer.Correct && (fastest == nil || seconds < fastest.Value) {
+				fastest = &entity.GameAward{Type: FastestAnswerAward, Player: player.Name, Value: seconds}
+			}
+		}
+
+		if player.LongestStreak > 1 && (streak == nil || float64(player.LongestStreak) > streak.Value) {
+			streak = &entity.GameAward{Type: LongestStreakAward, Player: player.Name, Value: float64(player.LongestStreak)}
+		}
+
+		climbed := player.LowestRank - ranks[player.Id]
+		if climbed > 0 && (comeback == nil || float64(climbed) > comeback.Value) {
+			comeback = &entity.GameAward{Type: BiggestComebackAward, Player: player.Name, Value: float64(climbed)}
+		}
+	}
+
+	for _, award := range []*entity.GameAward{fastest, streak, comeback} {
+		if award != nil {
+			awards = append(awards, *award)
+		}
+	}
+
+	return awards
+}
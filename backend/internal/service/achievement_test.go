@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestGetAwards(t *testing.T) {
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	alice := &Player{Id: uuid.New(), Name: "alice", Points: 9000, LongestStreak: 3, LowestRank: 3}
+	bob := &Player{Id: uuid.New(), Name: "bob", Points: 4000, LongestStreak: 1, LowestRank: 2}
+	carol := &Player{Id: uuid.New(), Name: "carol", Points: 6000, LongestStreak: 2, LowestRank: 2}
+	game.Players = []*Player{alice, bob, carol}
+
+	alice.recordAnswer(PlayerAnswer{Question: 0, Correct: true, Elapsed: 2 * time.Second})
+	bob.recordAnswer(PlayerAnswer{Question: 0, Correct: false, Elapsed: 500 * time.Millisecond})
+	carol.recordAnswer(PlayerAnswer{Question: 0, Correct: true, Elapsed: 1200 * time.Millisecond})
+
+	want := []entity.GameAward{
+		{Type: FastestAnswerAward, Player: "carol", Value: 1.2},
+		{Type: LongestStreakAward, Player: "alice", Value: 3},
+		{Type: BiggestComebackAward, Player: "alice", Value: 2},
+	}
+
+	awards := game.getAwards()
+	if len(awards) != len(want) {
+		t.Fatalf("got %d awards, want %d: %+v", len(awards), len(want), awards)
+	}
+	for i := range want {
+		if awards[i] != want[i] {
+			t.Errorf("award %d = %+v, want %+v", i, awards[i], want[i])
+		}
+	}
+}
+
+func TestGetRoundEvents(t *testing.T) {
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	alice := &Player{Name: "alice"}
+	bob := &Player{Name: "bob"}
+	game.Players = []*Player{alice, bob}
+	game.CurrentQuestion = 0
+
+	events := game.getRoundEvents()
+	if len(events) != 1 || events[0].Event != NoCorrectEvent {
+		t.Fatalf("expected only a no-correct event, got %+v", events)
+	}
+
+	game.CurrentQuestion = 1
+	alice.recordAnswer(PlayerAnswer{Question: 1, Correct: true})
+	bob.recordAnswer(PlayerAnswer{Question: 1, Correct: true})
+	events = game.getRoundEvents()
+	if len(events) != 1 || events[0].Event != AllCorrectEvent || len(events[0].Players) != 2 {
+		t.Fatalf("expected only an all-correct event, got %+v", events)
+	}
+}
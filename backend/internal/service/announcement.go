@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// Limits of the outgoing webhook integrations
+const (
+	maxWebhooks     = 5                // Highest number of webhooks per quiz
+	webhookTimeout  = 10 * time.Second // Time allowed for a single webhook call
+	webhookMaxBytes = 2048             // Longest URL accepted for a webhook
+)
+
+// ErrInvalidWebhook is returned when a webhook is not a Slack or Discord incoming webhook URL
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// webhookHosts are the hosts webhooks may point to, so they cannot be used to make requests to arbitrary servers
+var webhookHosts = map[string][]string{
+	entity.SlackWebhook:   {"hooks.slack.com"},
+	entity.DiscordWebhook: {"discord.com", "discordapp.com"},
+}
+
+// AnnouncementService posts game announcements to Slack and Discord webhooks.
+type AnnouncementService struct {
+	client   *http.Client     // Client used to call the webhooks
+	webhooks []entity.Webhook // Webhooks every game is announced in, in addition to those of its quiz
+}
+
+// Announcement initializes and returns a new AnnouncementService instance.
+// Parameters:
+// - webhooks: the webhooks every game is announced in.
+func Announcement(webhooks []entity.Webhook) *AnnouncementService {
+	return &AnnouncementService{
+		client:   &http.Client{Timeout: webhookTimeout},
+		webhooks: webhooks,
+	}
+}
+
+// AnnounceGame posts that a game of a quiz is open for players, in the background.
+// Parameters:
+// - quiz: the quiz being played.
+// - code: the join code of the game.
+func (s AnnouncementService) AnnounceGame(quiz entity.Quiz, code string) {
+	s.announce(quiz, fmt.Sprintf("Game of \"%s\" starting, join with code %s", quiz.Name, code))
+}
+
+// AnnouncePodium posts the final podium of a game, in the background.
+// Parameters:
+// - quiz: the quiz that was played.
+// - podium: the top players of the game.
+func (s AnnouncementService) AnnouncePodium(quiz entity.Quiz, podium []LeaderboardEntry) {
+	if len(podium) == 0 {
+		return
+	}
+
+	lines := []string{fmt.Sprintf("Results of \"%s\":", quiz.Name)}
+	for i, entry := range podium {
+		lines = append(lines, fmt.Sprintf("%d. %s - %d points", i+1, entry.Name, entry.Points))
+	}
+
+	s.announce(quiz, strings.Join(lines, "\n"))
+}
+
+// AnnounceChallenge posts the join code of a game hosted for a scheduled challenge, in the background.
+// Parameters:
+// - challenge: the challenge, whose webhooks are posted to as well.
+// - quiz: the quiz being played.
+// - code: the join code of the game.
+func (s AnnouncementService) AnnounceChallenge(challenge entity.Challenge, quiz entity.Quiz, code string) {
+	interval := "Weekly"
+	if challenge.Interval == entity.DailyChallenge {
+		interval = "Daily"
+	}
+
+	text := fmt.Sprintf("%s challenge \"%s\" is open: play \"%s\" with code %s", interval, challenge.Name, quiz.Name, code)
+	s.announceTo(append(append([]entity.Webhook{}, quiz.Webhooks...), challenge.Webhooks...), text)
+}
+
+// announce posts a message to the global webhooks and those of the quiz.
+func (s AnnouncementService) announce(quiz entity.Quiz, text string) {
+	s.announceTo(quiz.Webhooks, text)
+}
+
+// announceTo posts a message to the global webhooks and the given ones.
+func (s AnnouncementService) announceTo(extra []entity.Webhook, text string) {
+	webhooks := append(append([]entity.Webhook{}, s.webhooks...), extra...)
+	if len(webhooks) == 0 {
+		return
+	}
+
+	go func() {
+		for _, webhook := range webhooks {
+			if err := s.post(webhook, text); err != nil {
+				fmt.Println("failed to post to", webhook.Kind, "webhook:", err)
+			}
+		}
+	}()
+}
+
+// post sends a message to a single webhook.
+func (s AnnouncementService) post(webhook entity.Webhook, text string) error {
+	// Slack and Discord name the message field differently
+	payload := map[string]string{"text": text}
+	if webhook.Kind == entity.DiscordWebhook {
+		payload = map[string]string{"content": text}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return nil
+}
+
+// validateWebhooks checks that every webhook is an HTTPS URL of the chat service it claims to be.
+// Parameters:
+// - webhooks: the webhooks to check.
+// Returns:
+// - ErrInvalidWebhook if there are too many webhooks or any of them is invalid.
+func validateWebhooks(webhooks []entity.Webhook) error {
+	if len(webhooks) > maxWebhooks {
+		return ErrInvalidWebhook
+	}
+
+	for _, webhook := range webhooks {
+		if len(webhook.Url) > webhookMaxBytes {
+			return ErrInvalidWebhook
+		}
+
+		hosts, ok := webhookHosts[webhook.Kind]
+		if !ok {
+			return ErrInvalidWebhook
+		}
+
+		parsed, err := url.Parse(webhook.Url)
+		if err != nil || parsed.Scheme != "https" || parsed.User != nil || parsed.Port() != "" {
+			return ErrInvalidWebhook
+		}
+
+		allowed := false
+		for _, host := range hosts {
+			allowed = allowed || parsed.Hostname() == host
+		}
+
+		if !allowed {
+			return ErrInvalidWebhook
+		}
+	}
+
+	return nil
+}
+
+// ParseWebhooks reads webhooks from a comma separated list of kind=url pairs, as used in the configuration.
+// Parameters:
+// - value: the list, e.g. "slack=https://hooks.slack.com/services/...,discord=https://discord.com/api/webhooks/..."
+// Returns:
+// - The webhooks and ErrInvalidWebhook if any of them is malformed.
+func ParseWebhooks(value string) ([]entity.Webhook, error) {
+	webhooks := []entity.Webhook{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		kind, webhookUrl, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, ErrInvalidWebhook
+		}
+
+		webhooks = append(webhooks, entity.Webhook{Kind: kind, Url: webhookUrl})
+	}
+
+	if err := validateWebhooks(webhooks); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestValidateWebhooks(t *testing.T) {
+	valid := [][]entity.Webhook{
+		{},
+		{{Kind: entity.SlackWebhook, Url: "https://hooks.slack.com/services/T000/B000/XXXX"}},
+		{{Kind: entity.DiscordWebhook, Url: "https://discord.com/api/webhooks/1/abc"}},
+	}
+	for _, webhooks := range valid {
+		if err := validateWebhooks(webhooks); err != nil {
+			t.Errorf("expected %v to be valid, got %v", webhooks, err)
+		}
+	}
+
+	invalid := []entity.Webhook{
+		{Kind: entity.SlackWebhook, Url: "http://hooks.slack.com/services/T000"},
+		{Kind: entity.SlackWebhook, Url: "https://discord.com/api/webhooks/1/abc"},
+		{Kind: entity.DiscordWebhook, Url: "https://discord.com.evil.example/api/webhooks"},
+		{Kind: entity.DiscordWebhook, Url: "https://discord.com:8443/api/webhooks/1/abc"},
+		{Kind: "teams", Url: "https://hooks.slack.com/services/T000"},
+	}
+	for _, webhook := range invalid {
+		if err := validateWebhooks([]entity.Webhook{webhook}); err != ErrInvalidWebhook {
+			t.Errorf("expected %v to be rejected, got %v", webhook, err)
+		}
+	}
+}
+
+func TestParseWebhooks(t *testing.T) {
+	webhooks, err := ParseWebhooks(" slack=https://hooks.slack.com/services/T000 ,discord=https://discord.com/api/webhooks/1/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(webhooks) != 2 || webhooks[0].Kind != entity.SlackWebhook || webhooks[1].Kind != entity.DiscordWebhook {
+		t.Errorf("unexpected webhooks %v", webhooks)
+	}
+
+	if webhooks, err := ParseWebhooks(""); err != nil || len(webhooks) != 0 {
+		t.Errorf("expected no webhooks, got %v, %v", webhooks, err)
+	}
+
+	if _, err := ParseWebhooks("https://hooks.slack.com/services/T000"); err != ErrInvalidWebhook {
+		t.Errorf("expected a missing kind to be rejected, got %v", err)
+	}
+}
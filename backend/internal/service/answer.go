@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// Kinds of answers a player can send in the answer packet
+const (
+	IndexAnswer       = "index"       // A single choice, by its index
+	IndicesAnswer     = "indices"     // Several choices, by their indexes
+	TextAnswer        = "text"        // A typed answer
+	NumberAnswer      = "number"      // A number, e.g. an estimate
+	CoordinatesAnswer = "coordinates" // A point on the image of the question
+	OrderingAnswer    = "ordering"    // The choices put in order, by their indexes
+)
+
+// maxTextAnswerLength is the longest typed answer in bytes
+const maxTextAnswerLength = 200
+
+// ErrAnswerMismatch is returned when an answer does not fit the kind of question it answers
+var ErrAnswerMismatch = errors.New("answer does not fit the question")
+
+// questionAnswers lists the kinds of answers each kind of question takes
+var questionAnswers = map[string][]string{
+	entity.ChoiceQuestion: {IndexAnswer},
+}
+
+// AnswerPoint is a point on the image of a question, as fractions of its width and height from the top left
+type AnswerPoint struct {
+	X float64 `json:"x"` // Distance from the left edge, from 0 to 1
+	Y float64 `json:"y"` // Distance from the top edge, from 0 to 1
+}
+
+// AnswerPayload carries the answer of a player; only the field of its kind is set
+type AnswerPayload struct {
+	Index       *int         `json:"index,omitempty"`       // Index of the chosen choice, for index answers
+	Indices     []int        `json:"indices,omitempty"`     // Indexes of the chosen choices, for indices answers
+	Text        string       `json:"text,omitempty"`        // The typed answer, for text answers
+	Number      *float64     `json:"number,omitempty"`      // The number, for number answers
+	Coordinates *AnswerPoint `json:"coordinates,omitempty"` // The point, for coordinates answers
+	Ordering    []int        `json:"ordering,omitempty"`    // Indexes of the choices in the order given, for ordering answers
+}
+
+// checkAnswerPayload checks that a payload holds a well formed answer of its kind, before the question is known.
+// Parameters:
+// - kind: the kind of answer, e.g. IndexAnswer.
+// - payload: the answer.
+// Returns:
+// - ErrInvalidPacket if the kind is unknown or the answer malformed.
+func checkAnswerPayload(kind string, payload *AnswerPayload) error {
+	switch kind {
+	case IndexAnswer:
+		if payload.Index == nil || *payload.Index < 0 || *payload.Index > maxChoiceIndex {
+			return ErrInvalidPacket
+		}
+	case IndicesAnswer:
+		if len(payload.Indices) == 0 || !distinctChoices(payload.Indices) {
+			return ErrInvalidPacket
+		}
+	case TextAnswer:
+		payload.Text = strings.TrimSpace(payload.Text)
+		if payload.Text == "" || len(payload.Text) > maxTextAnswerLength {
+			return ErrInvalidPacket
+		}
+	case NumberAnswer:
+		if payload.Number == nil || math.IsNaN(*payload.Number) || math.IsInf(*payload.Number, 0) {
+			return ErrInvalidPacket
+		}
+	case CoordinatesAnswer:
+		point := payload.Coordinates
+		if point == nil || point.X < 0 || point.X > 1 || point.Y < 0 || point.Y > 1 {
+			return ErrInvalidPacket
+		}
+	case OrderingAnswer:
+		if len(payload.Ordering) < 2 || !distinctChoices(payload.Ordering) {
+			return ErrInvalidPacket
+		}
+	default:
+		return ErrInvalidPacket
+	}
+
+	return nil
+}
+
+// distinctChoices reports whether choice indexes are all in range and none is given twice
+func distinctChoices(indexes []int) bool {
+	if len(indexes) > maxChoiceIndex+1 {
+		return false
+	}
+
+	seen := map[int]bool{}
+	for _, index := range indexes {
+		if index < 0 || index > maxChoiceIndex || seen[index] {
+			return false
+		}
+		seen[index] = true
+	}
+
+	return true
+}
+
+// checkAnswerFor checks that an answer is one the question takes and that the choices it names exist.
+// Parameters:
+// - question: the question answered.
+// - packet: the validated answer packet.
+// Returns:
+// - ErrAnswerMismatch if the question does not take the answer.
+func checkAnswerFor(question entity.QuizQuestion, packet QuestionAnswerPacket) error {
+	kind := question.Type
+	if kind == "" {
+		kind = entity.ChoiceQuestion
+	}
+
+	accepted := false
+	for _, answer := range questionAnswers[kind] {
+		accepted = accepted || answer == packet.Type
+	}
+	if !accepted {
+		return fmt.Errorf("%w: %s answer to %s question", ErrAnswerMismatch, packet.Type, kind)
+	}
+
+	choices := len(question.Choices)
+	switch packet.Type {
+	case IndexAnswer:
+		if *packet.Payload.Index >= choices {
+			return fmt.Errorf("%w: no choice %d", ErrAnswerMismatch, *packet.Payload.Index)
+		}
+	case IndicesAnswer:
+		for _, index := range packet.Payload.Indices {
+			if index >= choices {
+				return fmt.Errorf("%w: no choice %d", ErrAnswerMismatch, index)
+			}
+		}
+	case OrderingAnswer:
+		// Every choice is put in order exactly once
+		if len(packet.Payload.Ordering) != choices {
+			return fmt.Errorf("%w: %d of %d choices ordered", ErrAnswerMismatch, len(packet.Payload.Ordering), choices)
+		}
+	}
+
+	return nil
+}
+
+// answeredQuestion finds the question an answer of a player is for
+// Parameters:
+// - player: the player who answered
+// Returns:
+// - entity.QuizQuestion: the question
+// - bool: false if the player has no question to answer right now
+func (g *Game) answeredQuestion(player *Player) (entity.QuizQuestion, bool) {
+	switch {
+	case g.State == TieBreakerState && g.tieBreak != nil:
+		return g.tieBreak.question, true
+	case g.State != PlayState:
+		return entity.QuizQuestion{}, false
+	case g.Settings.PlayerPaced:
+		if player.paced == nil || player.paced.done() {
+			return entity.QuizQuestion{}, false
+		}
+		return g.Quiz.Questions[player.paced.order[player.paced.position]], true
+	}
+
+	return g.getCurrentQuestion(), true
+}
+
+// OnAnswer handles an answer packet of a player, turning down answers that do not fit the question
+// Parameters:
+// - packet: the validated answer packet
+// - player: the player who answered
+func (g *Game) OnAnswer(packet QuestionAnswerPacket, player *Player) {
+	var err error
+	if question, ok := g.answeredQuestion(player); ok {
+		err = checkAnswerFor(question, packet)
+	}
+
+	// Questions only take single choices so far, other answers are turned down even between questions
+	if err == nil && packet.Type != IndexAnswer {
+		err = fmt.Errorf("%w: %s answer", ErrAnswerMismatch, packet.Type)
+	}
+
+	if err != nil {
+		fmt.Println("answer of", player.Name, "turned down:", err)
+		if g.Settings.PlayerPaced {
+			g.sendPacedAnswerAck(player, packet.Question, false)
+		} else {
+			g.sendAnswerAck(player, packet.Question, false)
+		}
+		return
+	}
+
+	g.OnConfidentAnswer(*packet.Payload.Index, packet.Confidence, player)
+}
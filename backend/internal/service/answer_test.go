@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestAnswerPacketValidation(t *testing.T) {
+	index := func(i int) *int { return &i }
+	number := func(n float64) *float64 { return &n }
+
+	valid := []QuestionAnswerPacket{
+		{Question: 1},
+		{Type: IndexAnswer, Payload: AnswerPayload{Index: index(2)}},
+		{Type: IndicesAnswer, Payload: AnswerPayload{Indices: []int{0, 3}}},
+		{Type: TextAnswer, Payload: AnswerPayload{Text: " Paris "}},
+		{Type: NumberAnswer, Payload: AnswerPayload{Number: number(-3.5)}},
+		{Type: CoordinatesAnswer, Payload: AnswerPayload{Coordinates: &AnswerPoint{X: 0, Y: 1}}},
+		{Type: OrderingAnswer, Payload: AnswerPayload{Ordering: []int{2, 0, 1}}},
+	}
+	for _, packet := range valid {
+		if err := packet.validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", packet, err)
+		}
+	}
+
+	invalid := []QuestionAnswerPacket{
+		{Question: -1},
+		{Type: "essay"},
+		{Type: IndexAnswer},
+		{Type: IndicesAnswer, Payload: AnswerPayload{Indices: []int{1, 1}}},
+		{Type: TextAnswer, Payload: AnswerPayload{Text: "   "}},
+		{Type: NumberAnswer},
+		{Type: CoordinatesAnswer, Payload: AnswerPayload{Coordinates: &AnswerPoint{X: 0.5, Y: 1.5}}},
+		{Type: OrderingAnswer, Payload: AnswerPayload{Ordering: []int{0}}},
+	}
+	for _, packet := range invalid {
+		if err := packet.validate(); err == nil {
+			t.Errorf("expected %+v to be turned down", packet)
+		}
+	}
+
+	// Clients sending only the choice get an index answer
+	legacy := QuestionAnswerPacket{Question: 1}
+	legacy.validate()
+	if legacy.Type != IndexAnswer || *legacy.Payload.Index != 1 {
+		t.Errorf("expected a legacy answer to become an index answer, got %+v", legacy)
+	}
+}
+
+func TestAnswerFitsQuestion(t *testing.T) {
+	quiz := fuzzQuiz()
+	two := 2
+	if err := checkAnswerFor(quiz.Questions[1], QuestionAnswerPacket{Type: IndexAnswer, Payload: AnswerPayload{Index: &two}}); err != nil {
+		t.Errorf("expected the last choice to be taken, got %v", err)
+	}
+	if err := checkAnswerFor(quiz.Questions[0], QuestionAnswerPacket{Type: IndexAnswer, Payload: AnswerPayload{Index: &two}}); !errors.Is(err, ErrAnswerMismatch) {
+		t.Errorf("expected a missing choice to be turned down, got %v", err)
+	}
+	if err := checkAnswerFor(quiz.Questions[0], QuestionAnswerPacket{Type: TextAnswer, Payload: AnswerPayload{Text: "a"}}); !errors.Is(err, ErrAnswerMismatch) {
+		t.Errorf("expected a text answer to a choice question to be turned down, got %v", err)
+	}
+}
+
+func TestOnAnswerTurnsDownMismatch(t *testing.T) {
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	connection := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", connection)
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	player := game.Players[0]
+	game.Start()
+
+	text := QuestionAnswerPacket{Type: TextAnswer, Payload: AnswerPayload{Text: "a"}}
+	text.validate()
+	game.OnAnswer(text, player)
+	if player.Answered {
+		t.Fatal("expected the text answer to be turned down")
+	}
+
+	choice := QuestionAnswerPacket{Question: 0}
+	choice.validate()
+	game.OnAnswer(choice, player)
+	acks := answerAcks(t, connection)
+	if !player.Answered || len(acks) != 2 || acks[0].Received || !acks[1].Received {
+		t.Errorf("expected only the choice to be taken, got %+v", acks)
+	}
+}
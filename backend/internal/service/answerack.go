@@ -0,0 +1,31 @@
+package service
+
+// AnswerAckPacket confirms to a player that their answer arrived, so they know it counts before the reveal.
+// Players who receive no acknowledgement can send the same answer again, repeated answers are acknowledged again.
+type AnswerAckPacket struct {
+	Question   int   `json:"question"`   // Index of the question the answer was given to
+	Choice     int   `json:"choice"`     // The choice the player sent
+	Received   bool  `json:"received"`   // Whether the choice is the player's answer now, false if it came too late or could not replace the earlier one
+	Locked     bool  `json:"locked"`     // Whether the answer is final and can no longer be changed
+	ServerTime int64 `json:"serverTime"` // Server time in milliseconds when the answer was handled
+}
+
+// sendAnswerAck acknowledges an answer to the player who sent it
+// Parameters:
+// - player: the player who answered
+// - choice: the choice the player sent
+// - received: whether the choice is the player's answer now
+func (g *Game) sendAnswerAck(player *Player, choice int, received bool) {
+	locked := true
+	if answer, ok := player.Answers[g.CurrentQuestion]; ok && g.State == PlayState {
+		locked = !g.canChangeAnswer(answer)
+	}
+
+	g.netService.SendPacket(player.Connection, AnswerAckPacket{
+		Question:   g.CurrentQuestion,
+		Choice:     choice,
+		Received:   received,
+		Locked:     locked,
+		ServerTime: g.clock.Now().UnixMilli(),
+	})
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// answerAckPacketId is the packet ID of AnswerAckPacket
+const answerAckPacketId = 32
+
+// answerAcks decodes the answer acknowledgements written to a connection
+func answerAcks(t *testing.T, connection *fakeConnection) []AnswerAckPacket {
+	acks := []AnswerAckPacket{}
+	for _, message := range connection.messages {
+		if message[0] != answerAckPacketId {
+			continue
+		}
+
+		var ack AnswerAckPacket
+		if err := json.Unmarshal(packetData(message), &ack); err != nil {
+			t.Fatal(err)
+		}
+		acks = append(acks, ack)
+	}
+
+	return acks
+}
+
+func TestAnswerAcks(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnSettings(GameSettings{AnswerChange: OneAnswerChange})
+	connection := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", connection)
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	alice := game.Players[0]
+	game.Start()
+
+	game.OnPlayerAnswer(1, alice) // First answer, may still change
+	game.OnPlayerAnswer(1, alice) // Resent answer
+	game.OnPlayerAnswer(0, alice) // The one change
+	game.OnPlayerAnswer(2, alice) // Turned down
+
+	acks := answerAcks(t, connection)
+	want := []struct {
+		choice   int
+		received bool
+		locked   bool
+	}{{1, true, false}, {1, true, false}, {0, true, true}, {2, false, true}}
+	if len(acks) != len(want) {
+		t.Fatalf("expected %d acknowledgements, got %+v", len(want), acks)
+	}
+	for i, ack := range acks {
+		if ack.Choice != want[i].choice || ack.Received != want[i].received || ack.Locked != want[i].locked || ack.ServerTime == 0 {
+			t.Errorf("acknowledgement %d: got %+v, want %+v", i, ack, want[i])
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// maxAttempts is the largest attempt limit a quiz can set
+const maxAttempts = 100
+
+// ErrInvalidAttemptPolicy is returned when the attempt policy of a quiz is malformed
+var ErrInvalidAttemptPolicy = errors.New("invalid attempt policy")
+
+// Progress is a student's attempts at every quiz they played while signed in
+type Progress struct {
+	Quizzes []QuizProgress `json:"quizzes"` // Progress per quiz, most recently played first
+}
+
+// QuizProgress is a student's attempts at a single quiz
+type QuizProgress struct {
+	QuizId   primitive.ObjectID   `json:"quizId"`   // ID of the quiz
+	QuizName string               `json:"quizName"` // Name of the quiz when it was last played
+	Policy   entity.AttemptPolicy `json:"policy"`   // The policy the attempts were counted under
+	Attempts []Attempt            `json:"attempts"` // Every attempt, oldest first
+	Kept     *Attempt             `json:"kept"`     // The attempt that counts, nil if none does
+}
+
+// Attempt is a single game of a quiz played by a student
+type Attempt struct {
+	Number    int                 `json:"number"`    // Position among the student's attempts at the quiz, from 1
+	ResultId  primitive.ObjectID  `json:"resultId"`  // ID of the stored game result
+	EndedAt   time.Time           `json:"endedAt"`   // When the game ended
+	Points    int                 `json:"points"`    // Points the student scored
+	MaxPoints int                 `json:"maxPoints"` // Most points the student could have scored
+	Correct   int                 `json:"correct"`   // Number of questions answered correctly
+	Grade     *entity.PlayerGrade `json:"grade"`     // The grade earned, nil if the quiz was not graded
+	Counted   bool                `json:"counted"`   // Whether the attempt is within the limit of the policy
+}
+
+// validateAttemptPolicy checks that the attempt limit is within range and the kept attempt is known
+// Parameters:
+// - policy: the policy to check
+// Returns:
+// - error: ErrInvalidAttemptPolicy if the policy is malformed, nil otherwise
+func validateAttemptPolicy(policy entity.AttemptPolicy) error {
+	if policy.Max < 0 || policy.Max > maxAttempts {
+		return ErrInvalidAttemptPolicy
+	}
+
+	switch policy.Keep {
+	case "", entity.KeepBest, entity.KeepLatest, entity.KeepFirst:
+		return nil
+	}
+
+	return ErrInvalidAttemptPolicy
+}
+
+// GetProgress links the games a student played of the same quiz into attempts and applies each
+// quiz's attempt policy to them.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - userId: the ObjectID of the student.
+// - quizzes: the quizzes whose policies apply; attempts at deleted quizzes count under the default policy.
+// Returns:
+// - The progress and an error if the results cannot be read.
+func (s ResultService) GetProgress(ctx context.Context, userId primitive.ObjectID, quizzes []entity.Quiz) (Progress, error) {
+	results, err := s.resultCollection.GetResultsByUser(ctx, userId)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	policies := map[primitive.ObjectID]entity.AttemptPolicy{}
+	for _, quiz := range quizzes {
+		policies[quiz.Id] = quiz.Attempts
+	}
+
+	return buildProgress(userId, results, policies), nil
+}
+
+// buildProgress groups a student's results by quiz and numbers the attempts in the order they were played
+// Parameters:
+// - userId: the ObjectID of the student
+// - results: the results of the games the student played, in any order
+// - policies: the attempt policy of each quiz
+// Returns:
+// - Progress: the attempts at every quiz, most recently played first
+func buildProgress(userId primitive.ObjectID, results []entity.GameResult, policies map[primitive.ObjectID]entity.AttemptPolicy) Progress {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].EndedAt.Before(results[j].EndedAt)
+	})
+
+	progress := Progress{Quizzes: []QuizProgress{}}
+	index := map[primitive.ObjectID]int{}
+	for _, result := range results {
+		player := findUserResult(result, userId)
+		if player == nil {
+			continue
+		}
+
+		i, ok := index[result.QuizId]
+		if !ok {
+			i = len(progress.Quizzes)
+			index[result.QuizId] = i
+			progress.Quizzes = append(progress.Quizzes, QuizProgress{
+				QuizId: result.QuizId,
+				Policy: policies[result.QuizId],
+			})
+		}
+
+		quiz := &progress.Quizzes[i]
+		quiz.QuizName = result.QuizName
+		quiz.Attempts = append(quiz.Attempts, Attempt{
+			Number:    len(quiz.Attempts) + 1,
+			ResultId:  result.Id,
+			EndedAt:   result.EndedAt,
+			Points:    player.Points,
+			MaxPoints: result.MaxPoints,
+			Correct:   player.Correct,
+			Grade:     player.Grade,
+			Counted:   quiz.Policy.Max == 0 || len(quiz.Attempts) < quiz.Policy.Max,
+		})
+	}
+
+	for i := range progress.Quizzes {
+		progress.Quizzes[i].Kept = keptAttempt(progress.Quizzes[i])
+	}
+
+	// Most recently played first, the attempts are oldest first so the last one is the latest
+	sort.SliceStable(progress.Quizzes, func(i, j int) bool {
+		a, b := progress.Quizzes[i].Attempts, progress.Quizzes[j].Attempts
+		return a[len(a)-1].EndedAt.After(b[len(b)-1].EndedAt)
+	})
+
+	return progress
+}
+
+// keptAttempt picks the counted attempt the policy keeps
+// Parameters:
+// - quiz: the attempts at a quiz, oldest first
+// Returns:
+// - *Attempt: the kept attempt, nil if no attempt is counted
+func keptAttempt(quiz QuizProgress) *Attempt {
+	var kept *Attempt
+	for i := range quiz.Attempts {
+		attempt := &quiz.Attempts[i]
+		if !attempt.Counted {
+			continue
+		}
+
+		switch {
+		case kept == nil:
+			kept = attempt
+		case quiz.Policy.Keep == entity.KeepLatest:
+			kept = attempt
+		case quiz.Policy.Keep == entity.KeepFirst:
+		case attempt.Points > kept.Points:
+			kept = attempt
+		}
+	}
+
+	if kept == nil {
+		return nil
+	}
+
+	copied := *kept
+	return &copied
+}
+
+// findUserResult finds the result of a signed in player in a game
+// Parameters:
+// - result: the result of the game
+// - userId: the ObjectID of the player's user
+// Returns:
+// - *entity.PlayerResult: the player's result, nil if the user did not play the game
+func findUserResult(result entity.GameResult, userId primitive.ObjectID) *entity.PlayerResult {
+	for i := range result.Players {
+		if result.Players[i].UserId == userId {
+			return &result.Players[i]
+		}
+	}
+
+	return nil
+}
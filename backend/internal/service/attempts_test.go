@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestBuildProgress(t *testing.T) {
+	student := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+	quizId := primitive.NewObjectID()
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	game := func(minutes int, points int) entity.GameResult {
+		return entity.GameResult{
+			Id:      primitive.NewObjectID(),
+			QuizId:  quizId,
+			EndedAt: start.Add(time.Duration(minutes) * time.Minute),
+			Players: []entity.PlayerResult{
+				{UserId: other, Points: 9000},
+				{UserId: student, Points: points},
+			},
+		}
+	}
+
+	// Stored out of order, the attempts are numbered by when they were played
+	results := []entity.GameResult{game(20, 700), game(0, 500), game(10, 900), game(30, 1000)}
+	results = append(results, entity.GameResult{QuizId: quizId, Players: []entity.PlayerResult{{UserId: other}}})
+
+	tests := []struct {
+		policy entity.AttemptPolicy
+		want   int
+	}{
+		{entity.AttemptPolicy{}, 1000},
+		{entity.AttemptPolicy{Max: 3}, 900},
+		{entity.AttemptPolicy{Max: 3, Keep: entity.KeepLatest}, 700},
+		{entity.AttemptPolicy{Keep: entity.KeepFirst}, 500},
+	}
+	for _, test := range tests {
+		progress := buildProgress(student, results, map[primitive.ObjectID]entity.AttemptPolicy{quizId: test.policy})
+		if len(progress.Quizzes) != 1 || len(progress.Quizzes[0].Attempts) != 4 {
+			t.Fatalf("expected 4 linked attempts at one quiz, got %+v", progress.Quizzes)
+		}
+
+		quiz := progress.Quizzes[0]
+		if quiz.Kept == nil || quiz.Kept.Points != test.want {
+			t.Errorf("policy %+v: expected %d points kept, got %+v", test.policy, test.want, quiz.Kept)
+		}
+		if quiz.Attempts[0].Points != 500 || quiz.Attempts[3].Number != 4 {
+			t.Errorf("expected attempts numbered oldest first, got %+v", quiz.Attempts)
+		}
+		if quiz.Attempts[3].Counted != (test.policy.Max == 0) {
+			t.Errorf("policy %+v: fourth attempt counted is %v", test.policy, quiz.Attempts[3].Counted)
+		}
+	}
+
+	if validateAttemptPolicy(entity.AttemptPolicy{Keep: "worst"}) == nil {
+		t.Errorf("expected an unknown kept attempt to be rejected")
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// Actions of the host recorded in the event log of a game
+const (
+	StartHostAction    = "start"    // The host started the game
+	SkipHostAction     = "skip"     // The host moved on before the timer ran out
+	KickHostAction     = "kick"     // The host removed a player
+	BanHostAction      = "ban"      // The host removed a player and kept them from joining again
+	SettingsHostAction = "settings" // The host changed the settings in the lobby
+	InviteHostAction   = "invite"   // The host emailed invitations to the game
+	AwardHostAction    = "award"    // The host judged the answer of a player who buzzed correct
+	DenyHostAction     = "deny"     // The host judged the answer of a player who buzzed wrong
+)
+
+// HostActionEvent records something the host did. It leaves the phase of the game as it is,
+// but is logged with the other events so the host's actions can be audited.
+type HostActionEvent struct {
+	Action   string // What the host did, e.g. KickHostAction
+	Question int    // Index of the question the game was at, -1 before the first
+	Player   string // Name of the player the action was aimed at, empty if none
+	Detail   string // More about the action, empty if there is nothing to add
+}
+
+// recordHostAction logs an action of the host
+// Parameters:
+// - action: what the host did
+// - player: the player the action was aimed at, nil if none
+// - detail: more about the action, empty if there is nothing to add
+func (g *Game) recordHostAction(action string, player *Player, detail string) {
+	event := HostActionEvent{
+		Action:   action,
+		Question: g.CurrentQuestion,
+		Detail:   detail,
+	}
+	if player != nil {
+		event.Player = player.Name
+	}
+
+	g.apply(event)
+}
+
+// getHostActions lists the actions of the host from the event log, for the report
+// Returns:
+// - []entity.HostAction: the actions, oldest first
+func (g *Game) getHostActions() []entity.HostAction {
+	actions := []entity.HostAction{}
+	for _, logged := range g.events {
+		if e, ok := logged.Event.(HostActionEvent); ok {
+			actions = append(actions, entity.HostAction{
+				At:       logged.At,
+				Action:   e.Action,
+				Question: e.Question,
+				Player:   e.Player,
+				Detail:   e.Detail,
+			})
+		}
+	}
+
+	return actions
+}
+
+// changedSettings names the settings that differ between two versions, for the audit log
+// Parameters:
+// - before: the settings before the change
+// - after: the settings after the change
+// Returns:
+// - string: the JSON names of the changed settings, comma separated
+func changedSettings(before GameSettings, after GameSettings) string {
+	var was, now map[string]any
+	beforeJson, _ := json.Marshal(before)
+	afterJson, _ := json.Marshal(after)
+	json.Unmarshal(beforeJson, &was)
+	json.Unmarshal(afterJson, &now)
+
+	changed := []string{}
+	for name, value := range now {
+		if fmt.Sprint(was[name]) != fmt.Sprint(value) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+
+	return strings.Join(changed, ", ")
+}
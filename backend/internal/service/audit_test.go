@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestHostActionsInReport(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+
+	game.OnSettings(GameSettings{ExamMode: true})
+	game.OnSettings(GameSettings{ExamMode: true})
+	game.OnKickPlayer(&KickPlayerPacket{PlayerId: game.Players[1].Id, Ban: true})
+	game.StartOrSkip()
+	game.StartOrSkip()
+
+	actions := game.buildReport().HostActions
+	want := []string{SettingsHostAction, BanHostAction, StartHostAction, SkipHostAction}
+	if len(actions) != len(want) {
+		t.Fatalf("expected %d host actions, got %+v", len(want), actions)
+	}
+	for i, action := range actions {
+		if action.Action != want[i] {
+			t.Errorf("expected action %d to be %s, got %s", i, want[i], action.Action)
+		}
+	}
+	if actions[0].Detail != "examMode" || actions[1].Player != "bob" || actions[3].Question != 0 {
+		t.Errorf("expected the details of the actions to be recorded, got %+v", actions)
+	}
+
+	// The log of a long game is folded, but the actions of the host stay
+	for range maxLoggedEvents {
+		game.apply(TimerTickedEvent{})
+	}
+	if len(game.getHostActions()) != len(want) {
+		t.Errorf("expected folding the log to keep the host actions, got %+v", game.getHostActions())
+	}
+
+	game.Settings.AnonymizeResults = true
+	result := game.buildResult(game.buildReport(), nil)
+	if result.HostActions[1].Player != "Removed player" {
+		t.Errorf("expected the names in host actions to be anonymized, got %q", result.HostActions[1].Player)
+	}
+}
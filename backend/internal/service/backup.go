@@ -0,0 +1,260 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+)
+
+// backupVersion is the version of the backup format, raised when restoring older backups needs conversions
+const backupVersion = 1
+
+// backupManifestFile is the name of the manifest in a backup archive
+const backupManifestFile = "manifest.json"
+
+// backupBatchSize is the number of documents restored with a single write
+const backupBatchSize = 500
+
+// maxBackupLine is the longest line of a backup, MongoDB's document limit with room for Extended JSON
+const maxBackupLine = 32 << 20
+
+// backupCollections are the collections a backup holds, in the order they are restored.
+// Media holds only the manifest of uploaded files; their content stays in the media storage.
+var backupCollections = []string{"users", "quizzes", "media", "results", "highscores", "tournaments", "challenges", "comments"}
+
+// ErrInvalidBackup is returned when a restored archive is not a complete backup of a supported version
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// BackupManifest describes a backup archive
+type BackupManifest struct {
+	Version     int            `json:"version"`     // Version of the backup format
+	CreatedAt   time.Time      `json:"createdAt"`   // When the backup was taken
+	Collections map[string]int `json:"collections"` // Number of documents in the backup, by collection
+}
+
+// BackupService takes and restores backups of the whole database, for migrations and disaster recovery
+type BackupService struct {
+	backups     *collection.BackupCollection
+	quizService *QuizService
+}
+
+// Backup creates a new BackupService instance
+// Parameters:
+// - backups: the collection reading and writing whole collections
+// - quizService: the service layer whose caches are dropped after a restore
+// Returns:
+// - A pointer to a new BackupService
+func Backup(backups *collection.BackupCollection, quizService *QuizService) *BackupService {
+	return &BackupService{
+		backups:     backups,
+		quizService: quizService,
+	}
+}
+
+// ExportBackup writes a backup of the database as a zip archive, holding a manifest and a file of
+// newline delimited canonical Extended JSON documents per collection. Password hashes are left out.
+// Parameters:
+// - ctx: the context bounding the export
+// - w: where the archive is written
+// Returns:
+// - error: any error encountered during the export, or nil if successful
+func (s BackupService) ExportBackup(ctx context.Context, w io.Writer) error {
+	return writeBackup(w, time.Now().UTC(), func(name string, write func(document bson.Raw) error) (int, error) {
+		return s.backups.ExportDocuments(ctx, name, write)
+	})
+}
+
+// RestoreBackup inserts the documents of a backup the database does not have yet.
+// Documents that already exist are kept as they are, so restoring the same backup twice is harmless.
+// Parameters:
+// - ctx: the context bounding the restore
+// - r: the backup archive
+// - size: the size of the archive in bytes
+// - progress: called with the share of the collections restored so far as it goes, nil if nobody is watching
+// Returns:
+// - map[string]int: the number of documents inserted, by collection
+// - error: ErrInvalidBackup if the archive is not a backup, or any error encountered during the restore
+func (s BackupService) RestoreBackup(ctx context.Context, r io.ReaderAt, size int64, progress func(done float64)) (map[string]int, error) {
+	restored, err := readBackup(r, size, progress, func(name string, documents []bson.D) (int, error) {
+		return s.backups.RestoreDocuments(ctx, name, documents)
+	})
+	if len(restored) > 0 {
+		s.quizService.invalidateQuiz(primitive.NilObjectID)
+	}
+
+	return restored, err
+}
+
+// writeBackup writes a backup archive, with the manifest last so an interrupted backup has none
+// Parameters:
+// - w: where the archive is written
+// - createdAt: when the backup is taken
+// - export: passes every document of a collection to a function, returning how many there were
+// Returns:
+// - error: any error encountered during the export, or nil if successful
+func writeBackup(w io.Writer, createdAt time.Time, export func(name string, write func(document bson.Raw) error) (int, error)) error {
+	archive := zip.NewWriter(w)
+	manifest := BackupManifest{
+		Version:     backupVersion,
+		CreatedAt:   createdAt,
+		Collections: map[string]int{},
+	}
+
+	for _, name := range backupCollections {
+		file, err := archive.Create(name + ".ndjson")
+		if err != nil {
+			return err
+		}
+
+		count, err := export(name, func(document bson.Raw) error {
+			line, err := bson.MarshalExtJSON(document, true, false)
+			if err != nil {
+				return err
+			}
+
+			_, err = file.Write(append(line, '\n'))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("backing up %s: %w", name, err)
+		}
+
+		manifest.Collections[name] = count
+	}
+
+	file, err := archive.Create(backupManifestFile)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(file).Encode(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// readBackup reads a backup archive and hands its documents to a function in batches
+// Parameters:
+// - r: the backup archive
+// - size: the size of the archive in bytes
+// - progress: called with the share of the collections restored so far before each, or nil
+// - restore: inserts a batch of documents into a collection, returning how many were inserted
+// Returns:
+// - map[string]int: the number of documents inserted, by collection
+// - error: ErrInvalidBackup if the archive is not a backup, or the first error of restore
+func readBackup(r io.ReaderAt, size int64, progress func(done float64), restore func(name string, documents []bson.D) (int, error)) (map[string]int, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	if _, err := readBackupManifest(archive); err != nil {
+		return nil, err
+	}
+
+	restored := map[string]int{}
+	for i, name := range backupCollections {
+		if progress != nil {
+			progress(float64(i) / float64(len(backupCollections)))
+		}
+
+		file, err := archive.Open(name + ".ndjson")
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return restored, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+
+		err = readDocuments(file, func(documents []bson.D) error {
+			count, err := restore(name, documents)
+			restored[name] += count
+			return err
+		})
+		file.Close()
+		if err != nil {
+			return restored, fmt.Errorf("restoring %s: %w", name, err)
+		}
+	}
+
+	return restored, nil
+}
+
+// readBackupManifest reads and checks the manifest of a backup archive
+// Parameters:
+// - archive: the backup archive
+// Returns:
+// - *BackupManifest: the manifest
+// - error: ErrInvalidBackup if the manifest is missing or of an unsupported version
+func readBackupManifest(archive *zip.Reader) (*BackupManifest, error) {
+	file, err := archive.Open(backupManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no manifest", ErrInvalidBackup)
+	}
+	defer file.Close()
+
+	var manifest BackupManifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	if manifest.Version < 1 || manifest.Version > backupVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBackup, manifest.Version)
+	}
+
+	return &manifest, nil
+}
+
+// readDocuments reads newline delimited Extended JSON documents in batches of backupBatchSize
+// Parameters:
+// - r: the documents
+// - batch: called with each batch in turn; an error stops reading
+// Returns:
+// - error: ErrInvalidBackup if a line is not a document, or the first error of batch
+func readDocuments(r io.Reader, batch func(documents []bson.D) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxBackupLine)
+
+	documents := []bson.D{}
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var document bson.D
+		if err := bson.UnmarshalExtJSON(line, true, &document); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+
+		documents = append(documents, document)
+		if len(documents) == backupBatchSize {
+			if err := batch(documents); err != nil {
+				return err
+			}
+			documents = []bson.D{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	if len(documents) == 0 {
+		return nil
+	}
+
+	return batch(documents)
+}
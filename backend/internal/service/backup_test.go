@@ -0,0 +1,115 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBackupRoundTrip(t *testing.T) {
+	quizId := primitive.NewObjectID()
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stored := map[string][]bson.D{
+		"quizzes": {{{Key: "_id", Value: quizId}, {Key: "name", Value: "Capitals"}, {Key: "plays", Value: int32(3)}}},
+		"users":   {{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "email", Value: "a@example.com"}, {Key: "createdat", Value: primitive.NewDateTimeFromTime(createdAt)}}},
+	}
+
+	var archive bytes.Buffer
+	err := writeBackup(&archive, createdAt, func(name string, write func(document bson.Raw) error) (int, error) {
+		for _, document := range stored[name] {
+			raw, err := bson.Marshal(document)
+			if err != nil {
+				return 0, err
+			}
+			if err := write(raw); err != nil {
+				return 0, err
+			}
+		}
+		return len(stored[name]), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restoredDocuments := map[string][]bson.D{}
+	restored, err := readBackup(bytes.NewReader(archive.Bytes()), int64(archive.Len()), nil, func(name string, documents []bson.D) (int, error) {
+		restoredDocuments[name] = append(restoredDocuments[name], documents...)
+		return len(documents), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored["quizzes"] != 1 || restored["users"] != 1 || restored["results"] != 0 {
+		t.Errorf("expected a quiz and a user to be restored, got %v", restored)
+	}
+
+	quiz := restoredDocuments["quizzes"][0]
+	if quiz[0].Value != quizId || quiz[2].Value != int32(3) {
+		t.Errorf("expected the quiz to keep its ID and types, got %v", quiz)
+	}
+}
+
+func TestRestoreBatches(t *testing.T) {
+	var archive bytes.Buffer
+	err := writeBackup(&archive, time.Now(), func(name string, write func(document bson.Raw) error) (int, error) {
+		if name != "results" {
+			return 0, nil
+		}
+		for i := 0; i < backupBatchSize+1; i++ {
+			raw, _ := bson.Marshal(bson.D{{Key: "_id", Value: primitive.NewObjectID()}})
+			if err := write(raw); err != nil {
+				return 0, err
+			}
+		}
+		return backupBatchSize + 1, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batches := []int{}
+	_, err = readBackup(bytes.NewReader(archive.Bytes()), int64(archive.Len()), nil, func(name string, documents []bson.D) (int, error) {
+		batches = append(batches, len(documents))
+		return len(documents), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batches) != 2 || batches[0] != backupBatchSize || batches[1] != 1 {
+		t.Errorf("expected two batches, got %v", batches)
+	}
+}
+
+func TestRestoreTurnsDownInvalidBackups(t *testing.T) {
+	noop := func(name string, documents []bson.D) (int, error) { return len(documents), nil }
+
+	if _, err := readBackup(bytes.NewReader([]byte("not a zip")), 9, nil, noop); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("expected a file that is not a zip to be turned down, got %v", err)
+	}
+
+	// An interrupted backup has no manifest
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	file, _ := writer.Create("quizzes.ndjson")
+	file.Write([]byte("{}\n"))
+	writer.Close()
+	if _, err := readBackup(bytes.NewReader(archive.Bytes()), int64(archive.Len()), nil, noop); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("expected a backup without manifest to be turned down, got %v", err)
+	}
+
+	archive.Reset()
+	writer = zip.NewWriter(&archive)
+	file, _ = writer.Create(backupManifestFile)
+	file.Write([]byte(`{"version":99}`))
+	writer.Close()
+	if _, err := readBackup(bytes.NewReader(archive.Bytes()), int64(archive.Len()), nil, noop); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("expected a backup of a newer version to be turned down, got %v", err)
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the available message buses, selected with the QUIZ_BUS setting
+const (
+	NatsMessageBus = "nats"
+)
+
+// Timing of the connection of a message bus to its server
+const (
+	busHealthInterval = 15 * time.Second       // How often the connection is checked with a ping
+	busPongTimeout    = 5 * time.Second        // How long the server has to answer a ping before the connection is dropped
+	busMinRetry       = 100 * time.Millisecond // Wait before reconnecting the first time, doubled after each failure
+	busMaxRetry       = 30 * time.Second       // Longest wait before reconnecting
+)
+
+// Errors returned by message buses
+var (
+	ErrInvalidBus      = errors.New("invalid message bus")
+	ErrBusDisconnected = errors.New("message bus disconnected")
+)
+
+// MessageBus carries messages between the instances of a horizontally scaled deployment.
+// Every instance subscribed to a subject receives the messages published to it, including its own.
+type MessageBus interface {
+	// Publish sends a message to the subscribers of a subject.
+	Publish(subject string, data []byte) error
+	// Subscribe calls a handler with every message of a subject, from now on and after reconnecting.
+	Subscribe(subject string, handler func(data []byte)) error
+	// Healthy reports whether the bus is connected, nil if it is.
+	Healthy() error
+	// Close disconnects from the bus for good.
+	Close()
+}
+
+// NewMessageBus connects to the message bus selected by the configuration.
+// Parameters:
+// - kind: the bus, NatsMessageBus.
+// - address: nats://[user:password@]host:port of the NATS server.
+// Returns:
+// - The bus, and ErrInvalidBus if the kind is unknown or the address invalid.
+func NewMessageBus(kind string, address string) (MessageBus, error) {
+	switch kind {
+	case NatsMessageBus:
+		server, ok := parseNatsUrl(address)
+		if !ok {
+			return nil, ErrInvalidBus
+		}
+
+		return NatsBus(server), nil
+	}
+
+	return nil, ErrInvalidBus
+}
+
+// busSubscription is a subject an instance listens to
+type busSubscription struct {
+	subject string            // Subject of the messages
+	handler func(data []byte) // Called with each message
+}
+
+// NatsMessages is a MessageBus over a NATS server, spoken with the core NATS text protocol.
+// It reconnects with backoff when the connection breaks or stops answering pings, and subscribes again.
+type NatsMessages struct {
+	server natsServer // Server the bus connects to
+
+	mu            sync.Mutex              // Guards the fields below
+	conn          net.Conn                // Connection to the server, nil while disconnected
+	err           error                   // Why the bus is disconnected, nil while connected
+	subscriptions map[int]busSubscription // Subscriptions by subscription ID
+	pongs         chan struct{}           // Answers of the server to the health check pings
+	closed        bool                    // Whether the bus was closed
+}
+
+// NatsBus initializes a new NatsMessages instance and starts connecting in the background.
+// Parameters:
+// - server: the NATS server.
+func NatsBus(server natsServer) *NatsMessages {
+	b := &NatsMessages{
+		server:        server,
+		err:           ErrBusDisconnected,
+		subscriptions: map[int]busSubscription{},
+		pongs:         make(chan struct{}, 1),
+	}
+	go b.run()
+
+	return b
+}
+
+// Publish sends a message to the subscribers of a subject.
+// Parameters:
+// - subject: the subject.
+// - data: the message.
+// Returns:
+// - ErrBusDisconnected while the bus is reconnecting, or an error if the message cannot be sent.
+func (b *NatsMessages) Publish(subject string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn == nil {
+		return ErrBusDisconnected
+	}
+
+	return b.write(natsPub(subject, data))
+}
+
+// Subscribe calls a handler with every message of a subject. Handlers are called one at a time and should be quick.
+// Parameters:
+// - subject: the subject.
+// - handler: the function called with each message.
+// Returns:
+// - An error if the subscription cannot be sent; it is still sent again after reconnecting.
+func (b *NatsMessages) Subscribe(subject string, handler func(data []byte)) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return ErrInvalidBus
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sid := len(b.subscriptions) + 1
+	b.subscriptions[sid] = busSubscription{subject: subject, handler: handler}
+	if b.conn == nil {
+		return nil
+	}
+
+	return b.write(fmt.Appendf(nil, "SUB %s %d\r\n", subject, sid))
+}
+
+// Healthy reports whether the bus is connected.
+// Returns:
+// - nil if it is, otherwise why it is not.
+func (b *NatsMessages) Healthy() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.err
+}
+
+// Close disconnects from the server for good.
+func (b *NatsMessages) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.err = ErrBusDisconnected
+	if b.conn != nil {
+		b.conn.Close()
+	}
+}
+
+// write sends a command on the connection, dropping the connection if it fails; the caller holds the lock
+// Parameters:
+// - command: the encoded command
+// Returns:
+// - error: an error if the command cannot be sent
+func (b *NatsMessages) write(command []byte) error {
+	b.conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+	if _, err := b.conn.Write(command); err != nil {
+		b.conn.Close()
+		return err
+	}
+
+	return nil
+}
+
+// run keeps the bus connected until it is closed, waiting longer after each failed attempt
+func (b *NatsMessages) run() {
+	retry := busMinRetry
+	for {
+		conn, reader, err := b.server.dial()
+		if err == nil {
+			err = b.attach(conn)
+		}
+
+		if err == nil {
+			retry = busMinRetry
+			go b.checkHealth(conn)
+			err = b.read(reader)
+			conn.Close()
+		}
+
+		b.mu.Lock()
+		b.conn = nil
+		b.err = fmt.Errorf("%w: %v", ErrBusDisconnected, err)
+		closed := b.closed
+		b.mu.Unlock()
+		if closed {
+			return
+		}
+
+		fmt.Println("message bus disconnected, retrying in", retry, "-", err)
+		time.Sleep(retry)
+		retry = min(retry*2, busMaxRetry)
+	}
+}
+
+// attach makes a new connection the current one and subscribes again to every subject
+// Parameters:
+// - conn: the connection
+// Returns:
+// - error: an error if the bus was closed or the subscriptions cannot be sent
+func (b *NatsMessages) attach(conn net.Conn) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		conn.Close()
+		return ErrBusDisconnected
+	}
+
+	b.conn = conn
+	for sid, subscription := range b.subscriptions {
+		if err := b.write(fmt.Appendf(nil, "SUB %s %d\r\n", subscription.subject, sid)); err != nil {
+			b.conn = nil
+			return err
+		}
+	}
+	b.err = nil
+
+	return nil
+}
+
+// read handles what the server sends until the connection breaks
+// Parameters:
+// - reader: the reader of the connection
+// Returns:
+// - error: why the connection broke
+func (b *NatsMessages) read(reader *bufio.Reader) error {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "MSG":
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			if len(fields) < 4 {
+				return errors.New("malformed message from the server")
+			}
+			sid, _ := strconv.Atoi(fields[2])
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || size < 0 {
+				return errors.New("malformed message from the server")
+			}
+
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return err
+			}
+
+			b.mu.Lock()
+			subscription, ok := b.subscriptions[sid]
+			b.mu.Unlock()
+			if ok {
+				subscription.handler(data[:size])
+			}
+		case "PING":
+			b.mu.Lock()
+			if b.conn != nil {
+				err = b.write([]byte("PONG\r\n"))
+			}
+			b.mu.Unlock()
+			if err != nil {
+				return err
+			}
+		case "PONG":
+			select {
+			case b.pongs <- struct{}{}:
+			default:
+			}
+		case "-ERR":
+			fmt.Println("NATS server error:", strings.TrimSpace(line))
+		}
+	}
+}
+
+// checkHealth pings the server regularly and drops the connection if it stops answering, so the bus reconnects
+// Parameters:
+// - conn: the connection to check, the check stops once it is not the current one
+func (b *NatsMessages) checkHealth(conn net.Conn) {
+	for {
+		time.Sleep(busHealthInterval)
+
+		b.mu.Lock()
+		if b.conn != conn {
+			b.mu.Unlock()
+			return
+		}
+		// A pong left over from an earlier ping must not answer this one
+		select {
+		case <-b.pongs:
+		default:
+		}
+		err := b.write([]byte("PING\r\n"))
+		b.mu.Unlock()
+		if err != nil {
+			return
+		}
+
+		select {
+		case <-b.pongs:
+		case <-time.After(busPongTimeout):
+			fmt.Println("message bus did not answer a ping, reconnecting")
+			conn.Close()
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// fakeBus delivers published messages to the subscribers right away, like a bus shared by instances in one process
+type fakeBus struct {
+	handlers map[string][]func(data []byte)
+}
+
+func (b *fakeBus) Publish(subject string, data []byte) error {
+	for _, handler := range b.handlers[subject] {
+		handler(data)
+	}
+	return nil
+}
+
+func (b *fakeBus) Subscribe(subject string, handler func(data []byte)) error {
+	if b.handlers == nil {
+		b.handlers = map[string][]func(data []byte){}
+	}
+	b.handlers[subject] = append(b.handlers[subject], handler)
+	return nil
+}
+
+func (b *fakeBus) Healthy() error { return nil }
+
+func (b *fakeBus) Close() {}
+
+func TestQuizInvalidationAcrossInstances(t *testing.T) {
+	bus := &fakeBus{}
+	first := Quiz(nil, time.Minute, bus)
+	second := Quiz(nil, time.Minute, bus)
+
+	id := primitive.NewObjectID()
+	second.quizCache.set(id, entity.Quiz{Id: id, Name: "stale"})
+	second.quizListCache.set(struct{}{}, []entity.Quiz{{Id: id}})
+
+	first.invalidateQuiz(id)
+	if _, ok := second.quizCache.get(id); ok {
+		t.Errorf("expected the other instance to drop the updated quiz")
+	}
+	if _, ok := second.quizListCache.get(struct{}{}); ok {
+		t.Errorf("expected the other instance to drop the quiz list")
+	}
+}
+
+// acceptNats accepts a connection on a fake NATS server, greets it and returns its reader
+func acceptNats(t *testing.T, listener net.Listener) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write([]byte("INFO {}\r\n"))
+	reader := bufio.NewReader(conn)
+	if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, "CONNECT ") {
+		t.Fatalf("expected the client to connect, got %q", line)
+	}
+
+	return conn, reader
+}
+
+func TestNatsBusResubscribes(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	bus, err := NewMessageBus(NatsMessageBus, "nats://"+listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bus.Close()
+
+	received := make(chan string, 1)
+	if err := bus.Subscribe("quiz.cache.invalidate", func(data []byte) { received <- string(data) }); err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		conn, reader := acceptNats(t, listener)
+		if line, _ := reader.ReadString('\n'); line != "SUB quiz.cache.invalidate 1\r\n" {
+			t.Fatalf("attempt %d: expected the subscription to be sent, got %q", attempt, line)
+		}
+
+		conn.Write([]byte("MSG quiz.cache.invalidate 1 5\r\nhello\r\n"))
+		select {
+		case data := <-received:
+			if data != "hello" {
+				t.Errorf("expected the message to be handled, got %q", data)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("attempt %d: expected the message to be handled", attempt)
+		}
+
+		if err := bus.Healthy(); err != nil {
+			t.Errorf("expected the bus to be healthy, got %v", err)
+		}
+
+		// Dropping the connection makes the bus reconnect and subscribe again
+		conn.Close()
+	}
+}
+
+func TestUnknownMessageBus(t *testing.T) {
+	if _, err := NewMessageBus("amqp", "amqp://localhost"); err != ErrInvalidBus {
+		t.Errorf("expected unsupported buses to be refused, got %v", err)
+	}
+	if _, err := NewMessageBus(NatsMessageBus, "http://localhost"); err != ErrInvalidBus {
+		t.Errorf("expected non NATS addresses to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// buzzChoice is the choice recorded for an answer given out loud in a buzzer game
+const buzzChoice = -1
+
+// BuzzPacket is sent by a player of a buzzer game who presses the buzzer
+type BuzzPacket struct{}
+
+// Buzz is a player who pressed the buzzer for the current question
+type Buzz struct {
+	PlayerId uuid.UUID `json:"playerId"` // ID of the player
+	Name     string    `json:"name"`     // Name of the player
+	Elapsed  int64     `json:"elapsed"`  // Milliseconds from showing the question to the buzz, compensated for latency
+	Judged   bool      `json:"judged"`   // Whether the host awarded or denied the answer
+	Awarded  bool      `json:"awarded"`  // Whether the host judged the answer correct
+}
+
+// BuzzOrderPacket tells the host in which order the players buzzed for the current question, fastest first
+type BuzzOrderPacket struct {
+	Question int    `json:"question"` // Index of the question
+	Buzzes   []Buzz `json:"buzzes"`   // The players who buzzed, fastest first
+}
+
+// JudgeBuzzPacket is sent by the host to award or deny points to a player who buzzed and answered out loud
+type JudgeBuzzPacket struct {
+	PlayerId uuid.UUID `json:"playerId"` // ID of the player
+	Award    bool      `json:"award"`    // Whether the answer was correct
+}
+
+// OnPlayerBuzz registers a player pressing the buzzer while the question is shown.
+// Buzzes are ordered by when they were pressed, not when they arrived, so a slow connection costs no place.
+// Parameters:
+// - player: the player who buzzed
+func (g *Game) OnPlayerBuzz(player *Player) {
+	if !g.Settings.BuzzerMode || g.State != PlayState || player.Eliminated || g.hasBuzzed(player) {
+		return
+	}
+
+	buzz := Buzz{
+		PlayerId: player.Id,
+		Name:     player.Name,
+		Elapsed:  g.getAnswerElapsed(player).Milliseconds(),
+	}
+	i := sort.Search(len(g.buzzes), func(i int) bool {
+		return g.buzzes[i].Elapsed > buzz.Elapsed
+	})
+	g.buzzes = slices.Insert(g.buzzes, i, buzz)
+
+	g.markActive(player)
+	g.sendBuzzOrder()
+}
+
+// OnJudgeBuzz records the host's verdict on the answer of a player who buzzed.
+// A correct answer ends the question; after a wrong one the next player in line may answer.
+// Parameters:
+// - packet: the player and whether their answer was correct
+func (g *Game) OnJudgeBuzz(packet *JudgeBuzzPacket) {
+	if !g.Settings.BuzzerMode || g.State != PlayState {
+		return
+	}
+
+	i := slices.IndexFunc(g.buzzes, func(buzz Buzz) bool {
+		return buzz.PlayerId == packet.PlayerId
+	})
+	player := g.getPlayerById(packet.PlayerId)
+	if i < 0 || g.buzzes[i].Judged || player == nil {
+		return
+	}
+
+	buzz := &g.buzzes[i]
+	buzz.Judged = true
+	buzz.Awarded = packet.Award
+
+	action := DenyHostAction
+	if packet.Award {
+		action = AwardHostAction
+	}
+	g.recordHostAction(action, player, "")
+
+	elapsed := time.Duration(buzz.Elapsed) * time.Millisecond
+	player.Answered = true
+	g.applyToPlayer(player, AnswerRecordedEvent{PlayerId: player.Id, Answer: PlayerAnswer{
+		Question: g.CurrentQuestion,
+		Choice:   buzzChoice,
+		Correct:  packet.Award,
+		Elapsed:  elapsed,
+		Points:   g.scoring().Points(packet.Award, g.getPointsReward(elapsed)),
+	}})
+	g.streamAnswer(player, *player.Answers[g.CurrentQuestion])
+	g.sendBuzzOrder()
+
+	if packet.Award || g.allAnswersFinal() {
+		g.Reveal()
+	}
+}
+
+// hasBuzzed reports whether a player pressed the buzzer for the current question
+// Parameters:
+// - player: the player
+// Returns:
+// - bool: true if the player buzzed
+func (g *Game) hasBuzzed(player *Player) bool {
+	return slices.ContainsFunc(g.buzzes, func(buzz Buzz) bool {
+		return buzz.PlayerId == player.Id
+	})
+}
+
+// sendBuzzOrder tells the host who buzzed for the current question so far
+func (g *Game) sendBuzzOrder() {
+	g.sendToHost(BuzzOrderPacket{
+		Question: g.CurrentQuestion,
+		Buzzes:   append([]Buzz{}, g.buzzes...),
+	})
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// buzzOrderPacketId is the ID of BuzzOrderPacket on the wire
+const buzzOrderPacketId = 45
+
+// lastBuzzOrder decodes the last buzz order sent on a connection
+func lastBuzzOrder(t *testing.T, connection *fakeConnection) BuzzOrderPacket {
+	t.Helper()
+
+	var order BuzzOrderPacket
+	for _, message := range connection.messages {
+		if message[0] != buzzOrderPacketId {
+			continue
+		}
+		if err := json.Unmarshal(packetData(message), &order); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return order
+}
+
+func TestBuzzerMode(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	clock := newFakeClock()
+	c.clock = clock
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("carol", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	alice, bob := game.Players[0], game.Players[1]
+	bob.Rtt = 600 * time.Millisecond
+	game.OnSettings(GameSettings{BuzzerMode: true})
+
+	// Started by hand, without the timer goroutine of Start
+	game.stopLobby()
+	game.ChangeState(PlayState)
+	game.NextQuestion()
+
+	game.OnPlayerAnswer(0, alice)
+	if alice.Answered {
+		t.Fatal("expected choices to be turned down in buzzer mode")
+	}
+
+	// Bob's buzz arrives later, but he pressed first on his slower connection
+	clock.Advance(time.Second)
+	game.OnPlayerBuzz(alice)
+	clock.Advance(100 * time.Millisecond)
+	game.OnPlayerBuzz(bob)
+	game.OnPlayerBuzz(alice)
+
+	order := lastBuzzOrder(t, host)
+	if len(order.Buzzes) != 2 || order.Buzzes[0].Name != "bob" || order.Buzzes[0].Elapsed != 800 || order.Buzzes[1].Elapsed != 1000 {
+		t.Fatalf("expected bob to be first by the time he pressed, got %+v", order)
+	}
+
+	game.OnJudgeBuzz(&JudgeBuzzPacket{PlayerId: bob.Id, Award: false})
+	if game.State != PlayState || !bob.Answered || bob.Answers[0].Correct || bob.Answers[0].Choice != buzzChoice {
+		t.Fatalf("expected a denied answer to leave the question open, got state %d and %+v", game.State, bob.Answers[0])
+	}
+
+	game.OnJudgeBuzz(&JudgeBuzzPacket{PlayerId: bob.Id, Award: true})
+	game.OnJudgeBuzz(&JudgeBuzzPacket{PlayerId: alice.Id, Award: true})
+	if game.State != RevealState || alice.Points == 0 || bob.Points != 0 {
+		t.Fatalf("expected an awarded answer to end the question, got state %d, alice %d and bob %d points", game.State, alice.Points, bob.Points)
+	}
+	if order := lastBuzzOrder(t, host); !order.Buzzes[1].Judged || !order.Buzzes[1].Awarded {
+		t.Errorf("expected the host to see the verdicts, got %+v", order)
+	}
+
+	actions := game.getHostActions()
+	if len(actions) != 3 || actions[1].Action != DenyHostAction || actions[2].Action != AwardHostAction || actions[2].Player != "alice" {
+		t.Errorf("expected the verdicts in the host actions, got %+v", actions)
+	}
+
+	// Carol never buzzed and is counted as missing the question, the others took part
+	if game.Players[2].missedQuestions != 1 || alice.missedQuestions != 0 {
+		t.Errorf("expected only carol to have missed the question")
+	}
+}
+
+func TestBuzzerModeSettings(t *testing.T) {
+	for _, settings := range []GameSettings{
+		{BuzzerMode: true, ExamMode: true},
+		{BuzzerMode: true, PlayerPaced: true},
+		{BuzzerMode: true, AnswerChange: OneAnswerChange},
+	} {
+		packet := GameSettingsPacket{Settings: settings}
+		if packet.validate() == nil {
+			t.Errorf("expected %+v to be rejected", settings)
+		}
+	}
+}
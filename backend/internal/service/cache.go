@@ -0,0 +1,107 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStats holds the counters of a cache, used to judge how well it relieves the database
+type CacheStats struct {
+	Hits    int64   `json:"hits"`    // Number of reads answered from the cache
+	Misses  int64   `json:"misses"`  // Number of reads that had to go to the database
+	Entries int     `json:"entries"` // Number of values currently cached
+	HitRate float64 `json:"hitRate"` // Share of reads answered from the cache, between 0 and 1
+}
+
+// cacheEntry is a cached value and when it stops being valid
+type cacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// cache is a small in-memory key-value cache whose entries expire after a fixed time
+type cache[K comparable, V any] struct {
+	ttl     time.Duration       // How long entries stay valid; zero disables the cache
+	now     func() time.Time    // Clock used for expiry, replaceable in tests
+	mu      sync.Mutex          // Guards entries
+	entries map[K]cacheEntry[V] // Cached values by key
+
+	hits   atomic.Int64 // Number of reads answered from the cache
+	misses atomic.Int64 // Number of reads that missed the cache
+}
+
+// newCache creates an empty cache
+// Parameters:
+// - ttl: how long entries stay valid, or zero to disable caching
+// Returns:
+// - A pointer to the new cache
+func newCache[K comparable, V any](ttl time.Duration) *cache[K, V] {
+	return &cache[K, V]{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[K]cacheEntry[V]{},
+	}
+}
+
+// get returns the cached value of a key, if it is present and has not expired
+func (c *cache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && c.now().Before(entry.expiresAt) {
+		c.hits.Add(1)
+		return entry.value, true
+	}
+
+	if ok {
+		delete(c.entries, key)
+	}
+
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+// set caches a value under a key
+func (c *cache[K, V]) set(key K, value V) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry[V]{
+		value:     value,
+		expiresAt: c.now().Add(c.ttl),
+	}
+}
+
+// invalidate removes a key from the cache
+func (c *cache[K, V]) invalidate(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// stats returns the current counters of the cache
+func (c *cache[K, V]) stats() CacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	stats := CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+
+	return stats
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheExpiry(t *testing.T) {
+	now := time.Now()
+	c := newCache[string, int](time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.set("a", 1)
+	if value, ok := c.get("a"); !ok || value != 1 {
+		t.Fatalf("expected a cached value of 1, got %d, %v", value, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.get("a"); ok {
+		t.Error("expected the entry to have expired")
+	}
+
+	stats := c.stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 0 || stats.HitRate != 0.5 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c := newCache[string, int](time.Minute)
+	c.set("a", 1)
+	c.invalidate("a")
+
+	if _, ok := c.get("a"); ok {
+		t.Error("expected the entry to be invalidated")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c := newCache[string, int](0)
+	c.set("a", 1)
+
+	if _, ok := c.get("a"); ok {
+		t.Error("expected nothing to be cached with a zero TTL")
+	}
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Limits and timing of scheduled challenges
+const (
+	maxChallengeNameLength = 100              // Longest name of a challenge
+	challengeCheckInterval = time.Minute      // How often the scheduler looks for due challenges
+	challengeRunTimeout    = 30 * time.Second // Time allowed for hosting the games of one check
+)
+
+// Errors returned when a challenge cannot be scheduled or changed.
+var (
+	ErrInvalidChallenge   = errors.New("invalid challenge")
+	ErrChallengeNotFound  = errors.New("challenge not found")
+	ErrNotChallengeOwner  = errors.New("not the owner of the challenge")
+	ErrNoChallengeQuizzes = errors.New("no quiz to pick for the challenge")
+)
+
+// ChallengeService schedules challenges, hosts their games on autopilot when they are due and
+// keeps the leaderboard of their seasons.
+type ChallengeService struct {
+	challengeCollection *collection.ChallengeCollection // Reference to the challenge collection for database operations
+	resultCollection    *collection.ResultCollection    // Reference to the result collection for reading the games of a season
+	quizService         *QuizService                    // Reference to the quiz service for picking the quizzes played
+	netService          *NetService                     // Reference to the network service hosting the games
+	announcementService *AnnouncementService            // Reference to the announcement service for posting join codes to chat webhooks
+	clock               Clock                           // Source of time of the scheduler, the wall clock outside of tests
+	rand                *rand.Rand                      // Random source for picking quizzes, only used by the scheduler
+}
+
+// Challenge initializes and returns a new ChallengeService instance.
+// Parameters:
+// - challengeCollection: the collection that interacts with the challenges in the database.
+// - resultCollection: the collection that interacts with the game results in the database.
+// - quizService: the quiz service used to pick the quizzes played.
+// - netService: the network service hosting the games.
+// - announcementService: the service posting the join codes to chat webhooks.
+func Challenge(challengeCollection *collection.ChallengeCollection, resultCollection *collection.ResultCollection, quizService *QuizService, netService *NetService, announcementService *AnnouncementService) *ChallengeService {
+	return &ChallengeService{
+		challengeCollection: challengeCollection,
+		resultCollection:    resultCollection,
+		quizService:         quizService,
+		netService:          netService,
+		announcementService: announcementService,
+		clock:               realClock{},
+		rand:                newRand(0),
+	}
+}
+
+// CreateChallenge schedules a challenge hosting a game every day or week, starting at a given time.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - ownerId: the ID of the user scheduling the challenge, who hosts its games.
+// - name: the name of the challenge.
+// - quizId: the quiz played, zero to pick a random quiz of the open library for every game.
+// - interval: how often a game is hosted, entity.DailyChallenge or entity.WeeklyChallenge.
+// - lobby: how long players can join a game before it starts, clamped to between 10 seconds and 10 minutes.
+// - startAt: when the first game is hosted, zero or a past time for right away.
+// - webhooks: the channels the join codes are posted to, in addition to the global ones.
+// Returns:
+// - The created challenge, and ErrInvalidChallenge or ErrInvalidWebhook if the input is invalid,
+// ErrQuizNotFound if the quiz does not exist or ErrQuizForbidden if it is not shared with the owner for hosting.
+func (s ChallengeService) CreateChallenge(ctx context.Context, ownerId primitive.ObjectID, name string, quizId primitive.ObjectID, interval string, lobby time.Duration, startAt time.Time, webhooks []entity.Webhook) (*entity.Challenge, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxChallengeNameLength {
+		return nil, ErrInvalidChallenge
+	}
+
+	if interval != entity.DailyChallenge && interval != entity.WeeklyChallenge {
+		return nil, ErrInvalidChallenge
+	}
+
+	if err := validateWebhooks(webhooks); err != nil {
+		return nil, err
+	}
+
+	if !quizId.IsZero() {
+		quiz, err := s.quizService.GetQuizById(ctx, quizId)
+		if err != nil {
+			return nil, err
+		}
+
+		if quiz == nil {
+			return nil, ErrQuizNotFound
+		}
+
+		if !CanAccessQuiz(*quiz, ownerId, "", entity.HostPermission) {
+			return nil, ErrQuizForbidden
+		}
+	}
+
+	now := s.clock.Now()
+	if startAt.Before(now) {
+		startAt = now
+	}
+
+	challenge := entity.Challenge{
+		Id:              primitive.NewObjectID(),
+		Name:            name,
+		OwnerId:         ownerId,
+		QuizId:          quizId,
+		Interval:        interval,
+		LobbySeconds:    int(min(max(lobby, minHeadlessLobby), maxHeadlessLobby) / time.Second),
+		Webhooks:        webhooks,
+		Season:          1,
+		SeasonStartedAt: now,
+		NextRunAt:       startAt,
+		Runs:            []entity.ChallengeRun{},
+		CreatedAt:       now,
+	}
+	if err := s.challengeCollection.InsertChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// GetChallengeById retrieves a challenge by its unique identifier.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the challenge to retrieve.
+// Returns:
+// - A pointer to the Challenge entity, or nil if it does not exist, and an error if something goes wrong.
+func (s ChallengeService) GetChallengeById(ctx context.Context, id primitive.ObjectID) (*entity.Challenge, error) {
+	return s.challengeCollection.GetChallengeById(ctx, id)
+}
+
+// getOwnChallenge retrieves a challenge a user wants to change.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the challenge.
+// - userId: the ObjectID of the user.
+// Returns:
+// - The challenge, and ErrChallengeNotFound if it does not exist or ErrNotChallengeOwner if the user did not schedule it.
+func (s ChallengeService) getOwnChallenge(ctx context.Context, id primitive.ObjectID, userId primitive.ObjectID) (*entity.Challenge, error) {
+	challenge, err := s.challengeCollection.GetChallengeById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge == nil {
+		return nil, ErrChallengeNotFound
+	}
+
+	if challenge.OwnerId != userId {
+		return nil, ErrNotChallengeOwner
+	}
+
+	return challenge, nil
+}
+
+// DeleteChallenge stops a challenge, so no more games are hosted for it. Its played games stay in the results.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the challenge.
+// - userId: the ObjectID of the user stopping the challenge, who must own it.
+// Returns:
+// - ErrChallengeNotFound if the challenge does not exist, ErrNotChallengeOwner if the user does not own it.
+func (s ChallengeService) DeleteChallenge(ctx context.Context, id primitive.ObjectID, userId primitive.ObjectID) error {
+	if _, err := s.getOwnChallenge(ctx, id, userId); err != nil {
+		return err
+	}
+
+	return s.challengeCollection.DeleteChallenge(ctx, id)
+}
+
+// StartSeason starts a new season of a challenge, so its leaderboard counts from zero again.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the challenge.
+// - userId: the ObjectID of the user starting the season, who must own the challenge.
+// Returns:
+// - The challenge in its new season, and ErrChallengeNotFound if it does not exist or ErrNotChallengeOwner if the user does not own it.
+func (s ChallengeService) StartSeason(ctx context.Context, id primitive.ObjectID, userId primitive.ObjectID) (*entity.Challenge, error) {
+	challenge, err := s.getOwnChallenge(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if err := s.challengeCollection.StartChallengeSeason(ctx, id, now); err != nil {
+		return nil, err
+	}
+
+	challenge.Season++
+	challenge.SeasonStartedAt = now
+	return challenge, nil
+}
+
+// GetLeaderboard adds up the points of every game of the current season of a challenge.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - challenge: the challenge.
+// Returns:
+// - The standings from first to last place and an error if something goes wrong.
+func (s ChallengeService) GetLeaderboard(ctx context.Context, challenge entity.Challenge) ([]entity.TournamentStanding, error) {
+	results, err := s.resultCollection.GetResultsByChallenge(ctx, challenge.Id, challenge.SeasonStartedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return calculateStandings(entity.PointsFormat, results), nil
+}
+
+// Run hosts the games of due challenges until the process exits. Every instance of a scaled out
+// deployment may run the scheduler, each game is only hosted by the instance that claims it.
+func (s *ChallengeService) Run() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), challengeRunTimeout)
+		s.runDue(ctx)
+		cancel()
+
+		s.clock.Sleep(challengeCheckInterval)
+	}
+}
+
+// runDue hosts a game for every challenge that is due and schedules its next one.
+// Parameters:
+// - ctx: the context bounding the database operations.
+func (s *ChallengeService) runDue(ctx context.Context) {
+	now := s.clock.Now()
+	challenges, err := s.challengeCollection.GetDueChallenges(ctx, now)
+	if err != nil {
+		fmt.Println("failed to look for due challenges:", err)
+		return
+	}
+
+	for _, challenge := range challenges {
+		next := nextChallengeRun(challenge.Interval, challenge.NextRunAt, now)
+		claimed, err := s.challengeCollection.ClaimChallengeRun(ctx, challenge.Id, challenge.NextRunAt, next)
+		if err != nil {
+			fmt.Println("failed to claim challenge", challenge.Name, ":", err)
+			continue
+		}
+
+		if !claimed {
+			continue
+		}
+
+		if err := s.host(ctx, challenge); err != nil {
+			fmt.Println("failed to host challenge", challenge.Name, ":", err)
+		}
+	}
+}
+
+// host starts the game of a challenge and posts its join code.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - challenge: the due challenge.
+// Returns:
+// - ErrQuizNotFound if the quiz of the challenge was deleted, ErrNoChallengeQuizzes if there is no quiz to pick
+// or ErrDailyLimit if the owner hosted as many games today as their plan allows.
+func (s *ChallengeService) host(ctx context.Context, challenge entity.Challenge) error {
+	quiz, err := s.pickQuiz(ctx, challenge)
+	if err != nil {
+		return err
+	}
+
+	game, err := s.netService.hostHeadlessGame(ctx, *quiz, challenge.OwnerId)
+	if err != nil {
+		return err
+	}
+	game.challengeId = challenge.Id
+	go game.runAutopilot(time.Duration(challenge.LobbySeconds) * time.Second)
+
+	err = s.challengeCollection.AddChallengeRun(ctx, challenge.Id, entity.ChallengeRun{
+		QuizId:    quiz.Id,
+		Code:      game.Code,
+		StartedAt: s.clock.Now(),
+	})
+	if err != nil {
+		fmt.Println("failed to record game", game.Code, "of challenge", challenge.Name, ":", err)
+	}
+
+	if s.announcementService != nil {
+		s.announcementService.AnnounceChallenge(challenge, *quiz, game.Code)
+	}
+
+	fmt.Println("challenge game", game.Code, "hosted for", challenge.Name)
+	return nil
+}
+
+// pickQuiz finds the quiz the next game of a challenge plays.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - challenge: the challenge.
+// Returns:
+// - The quiz, and ErrQuizNotFound if the quiz of the challenge was deleted or ErrNoChallengeQuizzes if there is no quiz to pick.
+func (s *ChallengeService) pickQuiz(ctx context.Context, challenge entity.Challenge) (*entity.Quiz, error) {
+	if !challenge.QuizId.IsZero() {
+		quiz, err := s.quizService.GetQuizById(ctx, challenge.QuizId)
+		if err != nil {
+			return nil, err
+		}
+
+		if quiz == nil {
+			return nil, ErrQuizNotFound
+		}
+
+		return quiz, nil
+	}
+
+	quizzes, err := s.quizService.GetQuizzes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	last := primitive.NilObjectID
+	if len(challenge.Runs) > 0 {
+		last = challenge.Runs[len(challenge.Runs)-1].QuizId
+	}
+
+	return pickChallengeQuiz(quizzes, last, s.rand)
+}
+
+// pickChallengeQuiz picks a random quiz of the open library with questions, avoiding the one played last if there is a choice
+// Parameters:
+// - quizzes: every quiz
+// - last: the ID of the quiz played in the previous game, zero if none
+// - random: the random source
+// Returns:
+// - *entity.Quiz: the picked quiz
+// - error: ErrNoChallengeQuizzes if the open library has no quiz with questions
+func pickChallengeQuiz(quizzes []entity.Quiz, last primitive.ObjectID, random *rand.Rand) (*entity.Quiz, error) {
+	candidates := []entity.Quiz{}
+	for _, quiz := range quizzes {
+		if quiz.OwnerId.IsZero() && len(quiz.Questions) > 0 {
+			candidates = append(candidates, quiz)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrNoChallengeQuizzes
+	}
+
+	if len(candidates) > 1 {
+		fresh := []entity.Quiz{}
+		for _, quiz := range candidates {
+			if quiz.Id != last {
+				fresh = append(fresh, quiz)
+			}
+		}
+		candidates = fresh
+	}
+
+	quiz := candidates[random.Intn(len(candidates))]
+	return &quiz, nil
+}
+
+// nextChallengeRun finds when the game after a due one is hosted. Runs missed while no instance was up
+// are skipped rather than hosted all at once.
+// Parameters:
+// - interval: how often a game is hosted, entity.DailyChallenge or entity.WeeklyChallenge
+// - due: when the due game was scheduled
+// - now: the current time
+// Returns:
+// - time.Time: the first time after now on the schedule
+func nextChallengeRun(interval string, due time.Time, now time.Time) time.Time {
+	days := 7
+	if interval == entity.DailyChallenge {
+		days = 1
+	}
+
+	next := due.AddDate(0, 0, days)
+	for !next.After(now) {
+		next = next.AddDate(0, 0, days)
+	}
+
+	return next
+}
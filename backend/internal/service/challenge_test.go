@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestNextChallengeRun(t *testing.T) {
+	due := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	if next := nextChallengeRun(entity.DailyChallenge, due, due.Add(time.Minute)); !next.Equal(due.AddDate(0, 0, 1)) {
+		t.Errorf("expected the next daily game a day later, got %v", next)
+	}
+	if next := nextChallengeRun(entity.WeeklyChallenge, due, due.Add(time.Minute)); !next.Equal(due.AddDate(0, 0, 7)) {
+		t.Errorf("expected the next weekly game a week later, got %v", next)
+	}
+
+	// Games missed while the server was down are skipped, the schedule keeps its time of day
+	if next := nextChallengeRun(entity.DailyChallenge, due, due.AddDate(0, 0, 3).Add(time.Hour)); !next.Equal(due.AddDate(0, 0, 4)) {
+		t.Errorf("expected the missed games to be skipped, got %v", next)
+	}
+}
+
+func TestPickChallengeQuiz(t *testing.T) {
+	library, other := fuzzQuiz(), fuzzQuiz()
+	library.Id, other.Id = primitive.NewObjectID(), primitive.NewObjectID()
+	owned := fuzzQuiz()
+	owned.OwnerId = primitive.NewObjectID()
+	empty := entity.Quiz{Id: primitive.NewObjectID()}
+
+	// Quizzes of users and quizzes without questions are never picked, nor the last one if there is a choice
+	for range 20 {
+		quiz, err := pickChallengeQuiz([]entity.Quiz{owned, empty, library, other}, library.Id, newRand(0))
+		if err != nil || quiz.Id != other.Id {
+			t.Fatalf("expected the other quiz of the open library, got %v", quiz)
+		}
+	}
+
+	if quiz, err := pickChallengeQuiz([]entity.Quiz{library}, library.Id, newRand(0)); err != nil || quiz.Id != library.Id {
+		t.Errorf("expected the only quiz to be played again, got %v", quiz)
+	}
+	if _, err := pickChallengeQuiz([]entity.Quiz{owned, empty}, primitive.NilObjectID, newRand(0)); err != ErrNoChallengeQuizzes {
+		t.Errorf("expected no quiz to pick, got %v", err)
+	}
+}
+
+func TestChallengeGameResult(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game, err := c.hostHeadlessGame(context.Background(), fuzzQuiz(), primitive.NewObjectID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.challengeId = primitive.NewObjectID()
+
+	// Results of challenge games count towards the season leaderboard
+	if result := game.buildResult(GameReportPacket{}, nil); result.ChallengeId != game.challengeId {
+		t.Errorf("expected the result to belong to the challenge, got %v", result.ChallengeId)
+	}
+}
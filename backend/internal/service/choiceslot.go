@@ -0,0 +1,31 @@
+package service
+
+// ChoiceSlot is how a choice is drawn on every screen, by its position in the question
+type ChoiceSlot struct {
+	Color string `json:"color"` // Name of the color of the answer button
+	Shape string `json:"shape"` // Name of the shape on the answer button, for players who cannot tell the colors apart
+}
+
+// choiceSlots are the slots of the choices in order; questions with fewer choices use the first ones
+var choiceSlots = []ChoiceSlot{
+	{Color: "pink", Shape: "triangle"},
+	{Color: "blue", Shape: "diamond"},
+	{Color: "yellow", Shape: "circle"},
+	{Color: "purple", Shape: "square"},
+	{Color: "green", Shape: "star"},
+	{Color: "orange", Shape: "heart"},
+}
+
+// getChoiceSlots returns the color and shape of each choice of a question
+// Parameters:
+// - choices: the number of choices of the question
+// Returns:
+// - []ChoiceSlot: a slot per choice, repeating from the start past the sixth choice
+func getChoiceSlots(choices int) []ChoiceSlot {
+	slots := []ChoiceSlot{}
+	for i := 0; i < choices; i++ {
+		slots = append(slots, choiceSlots[i%len(choiceSlots)])
+	}
+
+	return slots
+}
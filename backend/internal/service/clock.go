@@ -0,0 +1,23 @@
+package service
+
+import "time"
+
+// Clock is the source of time of games. Games never read the wall clock or sleep directly,
+// so tests can substitute a clock that advances instantly.
+type Clock interface {
+	Now() time.Time        // Returns the current time
+	Sleep(d time.Duration) // Blocks for the given duration
+}
+
+// realClock is the wall clock
+type realClock struct{}
+
+// Now returns the current wall clock time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep blocks the calling goroutine for the given duration
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock is a Clock that only advances when told to. Sleeping goroutines wake once the clock
+// has been advanced past the end of their sleep.
+type fakeClock struct {
+	mu       sync.Mutex
+	now      time.Time
+	sleepers []fakeSleeper
+}
+
+// fakeSleeper is a goroutine sleeping on a fakeClock
+type fakeSleeper struct {
+	until time.Time     // When the sleep ends
+	wake  chan struct{} // Closed to wake the goroutine
+}
+
+// newFakeClock creates a fake clock standing at a fixed time
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+// Now returns the time the clock stands at
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Sleep blocks until the clock is advanced by at least d
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	sleeper := fakeSleeper{until: c.now.Add(d), wake: make(chan struct{})}
+	c.sleepers = append(c.sleepers, sleeper)
+	c.mu.Unlock()
+
+	<-sleeper.wake
+}
+
+// Advance moves the clock forward and wakes the goroutines whose sleep is over
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	sleeping := []fakeSleeper{}
+	for _, sleeper := range c.sleepers {
+		if sleeper.until.After(c.now) {
+			sleeping = append(sleeping, sleeper)
+		} else {
+			close(sleeper.wake)
+		}
+	}
+	c.sleepers = sleeping
+}
+
+// sleeping returns the number of goroutines sleeping on the clock
+func (c *fakeClock) sleeping() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.sleepers)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+// Close codes sent to clients when the server closes their connection, so they can tell the player why.
+// Codes below 4000 are the standard ones of RFC 6455, the others are specific to the quiz.
+const (
+	CloseNormal          = 1000 // The connection is no longer needed
+	CloseShutdown        = 1001 // The server is shutting down
+	CloseMalformed       = 1008 // The client sent too many malformed messages
+	CloseKicked          = 4000 // The host removed the player from the game
+	CloseBanned          = 4001 // The host banned the player from the game
+	CloseIdle            = 4002 // The player was removed for missing too many questions
+	CloseNotAllowed      = 4003 // The player may not join, e.g. not in the tournament round or from a blocked address
+	CloseExamStarted     = 4004 // The exam started before the player joined
+	CloseGameFull        = 4005 // The game has as many players as it allows
+	CloseGameEnded       = 4006 // The game was ended by an administrator
+	CloseSessionExpired  = 4007 // The session the player tried to resume expired or never existed
+	CloseHibernated      = 4008 // The lobby went to sleep after being idle, joining again by its code wakes it
+	CloseSignInRequired  = 4009 // The game only lets in players signed in, with an account of an allowed domain
+	CloseTooManyAttempts = 4010 // The address of the player tried too many unknown join codes and is locked out for a while
+	CloseEmailUnverified = 4011 // The game lets in accounts of certain domains only, and the player did not verify the address of theirs
+)
+
+// closeReasons are the reasons sent along with each close code
+var closeReasons = map[int]string{
+	CloseNormal:          "Goodbye",
+	CloseShutdown:        "The server is restarting, please join again in a moment",
+	CloseMalformed:       "Too many invalid messages",
+	CloseKicked:          "You were removed from the game by the host",
+	CloseBanned:          "You were banned from the game by the host",
+	CloseIdle:            "You were removed from the game for being inactive",
+	CloseNotAllowed:      "You are not allowed to join this game",
+	CloseExamStarted:     "The exam already started",
+	CloseGameFull:        "The game is full",
+	CloseGameEnded:       "The game was ended",
+	CloseSessionExpired:  "Your session expired, please join again",
+	CloseHibernated:      "The game went to sleep after being idle, join again with its code to wake it",
+	CloseSignInRequired:  "Sign in with your school or work account to join this game",
+	CloseTooManyAttempts: "Too many wrong game codes, please wait a few minutes before trying again",
+	CloseEmailUnverified: "Verify your email address with the link we sent you to join this game",
+}
+
+// Limits of the close frame
+const (
+	maxCloseReason = 123             // Longest reason in bytes that fits a control frame next to the code
+	closeTimeout   = 1 * time.Second // Time allowed to send the close frame before the connection is dropped anyway
+)
+
+// closeFrame encodes the payload of a close frame
+// Parameters:
+// - code: the close code
+// - reason: the reason, cut to fit the frame
+// Returns:
+// - []byte: the code in network byte order followed by the reason
+func closeFrame(code int, reason string) []byte {
+	if len(reason) > maxCloseReason {
+		reason = reason[:maxCloseReason]
+	}
+
+	frame := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return append(frame, reason...)
+}
+
+// CloseConnection tells a client why it is being disconnected with a close frame, then closes the connection.
+// Parameters:
+// - connection: the connection to close.
+// - code: the close code, e.g. CloseKicked; its documented reason is sent along.
+func (c *NetService) CloseConnection(connection Connection, code int) {
+	if con, ok := connection.(closeWriter); ok {
+		err := con.WriteControl(websocket.CloseMessage, closeFrame(code, closeReasons[code]), time.Now().Add(closeTimeout))
+		if err != nil {
+			fmt.Println("failed to send close frame:", err)
+		}
+	}
+
+	connection.Close()
+}
+
+// Shutdown disconnects the hosts and players of every game with CloseShutdown, so their clients
+// know to come back instead of seeing the connection drop.
+func (c *NetService) Shutdown() {
+	c.gamesMu.Lock()
+	games := append([]*Game{}, c.games...)
+	c.gamesMu.Unlock()
+
+	for _, game := range games {
+		game.run("shutdown", func() {
+			game.closeConnections(CloseShutdown)
+		})
+	}
+}
+
+// closeConnections disconnects the host and every player of the game
+// Parameters:
+// - code: the close code sent to each of them
+func (g *Game) closeConnections(code int) {
+	for _, player := range g.Players {
+		g.netService.CloseConnection(player.Connection, code)
+	}
+
+	if g.Host != nil {
+		g.netService.CloseConnection(g.Host, code)
+	}
+}
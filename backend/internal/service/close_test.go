@@ -0,0 +1,88 @@
+package service
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// closingConnection records the close frames sent before it is closed
+type closingConnection struct {
+	fakeConnection
+	frames [][]byte
+}
+
+func (c *closingConnection) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	c.frames = append(c.frames, data)
+	return nil
+}
+
+// closeCode returns the code of the only close frame sent on a connection, or 0 if there is not exactly one
+func (c *closingConnection) closeCode() int {
+	if len(c.frames) != 1 || len(c.frames[0]) < 2 {
+		return 0
+	}
+
+	return int(binary.BigEndian.Uint16(c.frames[0]))
+}
+
+func TestCloseFrame(t *testing.T) {
+	frame := closeFrame(CloseKicked, closeReasons[CloseKicked])
+	if binary.BigEndian.Uint16(frame) != CloseKicked || string(frame[2:]) != closeReasons[CloseKicked] {
+		t.Errorf("expected the code followed by the reason, got %v", frame)
+	}
+
+	long := make([]byte, 200)
+	if frame := closeFrame(CloseNormal, string(long)); len(frame) != 125 {
+		t.Errorf("expected the reason to be cut to fit a control frame, got %d bytes", len(frame))
+	}
+
+	for code := range closeReasons {
+		if len(closeReasons[code]) > maxCloseReason {
+			t.Errorf("reason of close code %d does not fit a control frame", code)
+		}
+	}
+}
+
+func TestKickAndBan(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+
+	kicked := &closingConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", kicked)
+	game.OnKickPlayer(&KickPlayerPacket{PlayerId: game.Players[0].Id})
+	if len(game.Players) != 0 || !kicked.closed || kicked.closeCode() != CloseKicked {
+		t.Fatalf("expected the player to be removed with CloseKicked, got code %d", kicked.closeCode())
+	}
+
+	// A kicked player may come back, a banned one may not
+	banned := &closingConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", banned)
+	game.OnKickPlayer(&KickPlayerPacket{PlayerId: game.Players[0].Id, Ban: true})
+	if banned.closeCode() != CloseBanned {
+		t.Fatalf("expected the player to be removed with CloseBanned, got code %d", banned.closeCode())
+	}
+
+	rejoined := &closingConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", rejoined)
+	if len(game.Players) != 0 || rejoined.closeCode() != CloseBanned {
+		t.Errorf("expected the banned player to be refused, got code %d", rejoined.closeCode())
+	}
+}
+
+func TestShutdownClosesConnections(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	host := &closingConnection{}
+	player := &closingConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	c.addGame(game)
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", player)
+
+	c.Shutdown()
+	if host.closeCode() != CloseShutdown || player.closeCode() != CloseShutdown {
+		t.Errorf("expected the host and player to be told the server is shutting down, got %d and %d", host.closeCode(), player.closeCode())
+	}
+}
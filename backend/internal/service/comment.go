@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/entity"
+)
+
+// maxCommentLength is the longest comment in characters
+const maxCommentLength = 2000
+
+// Errors returned when a comment cannot be written or resolved.
+var (
+	ErrInvalidComment  = errors.New("invalid comment")
+	ErrCommentNotFound = errors.New("comment not found")
+)
+
+// CommentService provides methods for reviewers to comment on the questions of a quiz before it is published.
+type CommentService struct {
+	commentCollection *collection.CommentCollection // Reference to the comment collection for database operations
+	quizService       *QuizService                  // Service used to check that commented questions exist
+}
+
+// Comment initializes and returns a new CommentService instance.
+// Parameters:
+// - commentCollection: the collection that interacts with the comments in the database.
+// - quizService: the service used to read the commented quizzes.
+func Comment(commentCollection *collection.CommentCollection, quizService *QuizService) *CommentService {
+	return &CommentService{
+		commentCollection: commentCollection,
+		quizService:       quizService,
+	}
+}
+
+// AddComment leaves a comment on a question of a quiz.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quizId: the ObjectID of the quiz.
+// - questionId: the ID of the question in the quiz.
+// - userId: the ObjectID of the user writing the comment.
+// - author: the name of the user writing the comment.
+// - text: the comment.
+// Returns:
+// - The stored comment, or nil if the quiz does not exist, and ErrInvalidComment if the text is empty or too long
+// or the quiz has no such question, or an error if the comment cannot be stored.
+func (s CommentService) AddComment(ctx context.Context, quizId primitive.ObjectID, questionId string, userId primitive.ObjectID, author string, text string) (*entity.Comment, error) {
+	quiz, err := s.quizService.GetQuizById(ctx, quizId)
+	if err != nil || quiz == nil {
+		return nil, err
+	}
+
+	text, err = validateComment(*quiz, questionId, text)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := entity.Comment{
+		Id:         primitive.NewObjectID(),
+		QuizId:     quizId,
+		QuestionId: questionId,
+		UserId:     userId,
+		Author:     author,
+		Text:       text,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.commentCollection.InsertComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// GetComments lists the comments on the questions of a quiz.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quizId: the ObjectID of the quiz.
+// - resolved: whether to include resolved comments.
+// Returns:
+// - The comments, oldest first, and an error if they cannot be read.
+func (s CommentService) GetComments(ctx context.Context, quizId primitive.ObjectID, resolved bool) ([]entity.Comment, error) {
+	return s.commentCollection.GetCommentsByQuiz(ctx, quizId, resolved)
+}
+
+// ResolveComment marks a comment on a quiz as dealt with.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quizId: the ObjectID of the quiz.
+// - commentId: the ObjectID of the comment.
+// - by: the name of the user resolving the comment.
+// Returns:
+// - ErrCommentNotFound if the quiz has no such open comment, or an error if the update fails.
+func (s CommentService) ResolveComment(ctx context.Context, quizId primitive.ObjectID, commentId primitive.ObjectID, by string) error {
+	found, err := s.commentCollection.ResolveComment(ctx, quizId, commentId, by, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// validateComment checks that a comment is about a question of the quiz and has something to say
+// Parameters:
+// - quiz: the commented quiz
+// - questionId: the ID of the commented question
+// - text: the comment
+// Returns:
+// - string: the comment without surrounding whitespace
+// - error: ErrInvalidComment if the comment is empty or too long or the quiz has no such question
+func validateComment(quiz entity.Quiz, questionId string, text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > maxCommentLength {
+		return "", ErrInvalidComment
+	}
+
+	for _, question := range quiz.Questions {
+		if question.Id == questionId {
+			return text, nil
+		}
+	}
+
+	return "", ErrInvalidComment
+}
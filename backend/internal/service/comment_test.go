@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestValidateComment(t *testing.T) {
+	quiz := entity.Quiz{Questions: []entity.QuizQuestion{{Id: "q1"}, {Id: "q2"}}}
+
+	text, err := validateComment(quiz, "q2", "  The second choice is also correct.\n")
+	if err != nil || text != "The second choice is also correct." {
+		t.Errorf("expected the trimmed comment, got %q, %v", text, err)
+	}
+
+	tests := []struct {
+		name       string
+		questionId string
+		text       string
+	}{
+		{"empty", "q1", "   "},
+		{"too long", "q1", strings.Repeat("é", maxCommentLength+1)},
+		{"unknown question", "q3", "Typo in the question"},
+	}
+	for _, test := range tests {
+		if _, err := validateComment(quiz, test.questionId, test.text); !errors.Is(err, ErrInvalidComment) {
+			t.Errorf("%s: expected ErrInvalidComment, got %v", test.name, err)
+		}
+	}
+
+	if _, err := validateComment(quiz, "q1", strings.Repeat("é", maxCommentLength)); err != nil {
+		t.Errorf("expected a comment of the longest length in characters to pass, got %v", err)
+	}
+}
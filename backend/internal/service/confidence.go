@@ -0,0 +1,86 @@
+package service
+
+import "math"
+
+// Confidence is how sure a player is of their answer, weighing its points in confidence mode
+type Confidence string
+
+// Confidence levels a player can send with their answer
+const (
+	NoConfidence     Confidence = ""       // No level given, counted as medium in confidence mode
+	LowConfidence    Confidence = "low"    // A guess: half the points when right, nothing lost when wrong
+	MediumConfidence Confidence = "medium" // Fairly sure: three quarters of the points when right, a quarter lost when wrong
+	HighConfidence   Confidence = "high"   // Certain: all the points when right, three quarters lost when wrong
+)
+
+// confidenceWeight is what a confidence level makes of the points of an answer
+type confidenceWeight struct {
+	reward float64 // Share of the points a correct answer earns
+	stake  float64 // Share of the points of a correct answer a wrong answer costs
+}
+
+// confidenceWeights are the weights of each level. High confidence pays best for answers more likely right
+// than two in three, low confidence for those less likely right than one in two, so bluffing does not pay.
+var confidenceWeights = map[Confidence]confidenceWeight{
+	LowConfidence:    {reward: 0.5, stake: 0},
+	MediumConfidence: {reward: 0.75, stake: 0.25},
+	HighConfidence:   {reward: 1, stake: 0.75},
+}
+
+// valid reports whether the confidence is a known level or none
+func (c Confidence) valid() bool {
+	_, ok := confidenceWeights[c]
+	return ok || c == NoConfidence
+}
+
+// confidenceScoring weighs the points of another strategy by the confidence of the answer: a correct answer
+// earns a share of its points, a wrong one costs a share of what a correct one would have earned
+type confidenceScoring struct {
+	ScoringStrategy
+	weight        confidenceWeight // Weight of the confidence of the answer
+	allowNegative bool             // Whether totals may drop below zero
+}
+
+// Points returns the weighted reward of a correct answer, and the points of a wrong one less the stake
+func (s confidenceScoring) Points(correct bool, reward int) int {
+	points := s.ScoringStrategy.Points(correct, reward)
+	if correct {
+		return int(math.Round(float64(points) * s.weight.reward))
+	}
+
+	stake := float64(s.ScoringStrategy.Points(true, reward)) * s.weight.stake
+	return points - int(math.Round(stake))
+}
+
+// Apply adds the points to the total, which stays at or above zero unless negatives are allowed
+func (s confidenceScoring) Apply(total int, points int) int {
+	if s.allowNegative {
+		return total + points
+	}
+
+	return max(0, total+points)
+}
+
+// answerScoring returns the scoring strategy of an answer to a question, weighed by its confidence in confidence mode
+// Parameters:
+// - index: the index of the question
+// - confidence: the confidence the player answered with
+// Returns:
+// - ScoringStrategy: the strategy
+func (g *Game) answerScoring(index int, confidence Confidence) ScoringStrategy {
+	scoring := g.questionScoring(index)
+	if !g.Settings.ConfidenceMode {
+		return scoring
+	}
+
+	weight, ok := confidenceWeights[confidence]
+	if !ok {
+		weight = confidenceWeights[MediumConfidence]
+	}
+
+	return confidenceScoring{
+		ScoringStrategy: scoring,
+		weight:          weight,
+		allowNegative:   g.Settings.AllowNegative,
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestConfidenceScoring(t *testing.T) {
+	tests := []struct {
+		base       ScoringStrategy
+		confidence Confidence
+		correct    int
+		wrong      int
+	}{
+		{standardScoring{}, LowConfidence, 500, 0},
+		{standardScoring{}, MediumConfidence, 750, -250},
+		{standardScoring{}, HighConfidence, 1000, -750},
+		{penaltyScoring{penalty: 300}, HighConfidence, 1000, -1050},
+		{accuracyScoring{}, MediumConfidence, 750, -250},
+	}
+
+	for _, test := range tests {
+		scoring := confidenceScoring{ScoringStrategy: test.base, weight: confidenceWeights[test.confidence]}
+		if points := scoring.Points(true, 1000); points != test.correct {
+			t.Errorf("%T %s: expected %d points for a correct answer, got %d", test.base, test.confidence, test.correct, points)
+		}
+		if points := scoring.Points(false, 1000); points != test.wrong {
+			t.Errorf("%T %s: expected %d points for a wrong answer, got %d", test.base, test.confidence, test.wrong, points)
+		}
+	}
+
+	if total := (confidenceScoring{ScoringStrategy: standardScoring{}}).Apply(500, -750); total != 0 {
+		t.Errorf("expected the total to stay at zero, got %d", total)
+	}
+	if total := (confidenceScoring{ScoringStrategy: standardScoring{}, allowNegative: true}).Apply(500, -750); total != -250 {
+		t.Errorf("expected a negative total to be allowed, got %d", total)
+	}
+}
+
+func TestConfidenceMode(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnSettings(GameSettings{ConfidenceMode: true, AllowNegative: true})
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	alice, bob := game.Players[0], game.Players[1]
+	game.Start()
+
+	game.OnConfidentAnswer(0, LowConfidence, alice)
+	game.OnConfidentAnswer(1, HighConfidence, bob)
+	if alice.LastAwardedPoints <= 0 || bob.LastAwardedPoints >= 0 {
+		t.Fatalf("expected alice to earn points and bob to lose some, got %d and %d", alice.LastAwardedPoints, bob.LastAwardedPoints)
+	}
+	if alice.Answers[0].Confidence != LowConfidence || bob.Answers[0].Confidence != HighConfidence || bob.Points != bob.Answers[0].Points {
+		t.Errorf("expected the weighted answers to be recorded, got %+v and %+v", alice.Answers[0], bob.Answers[0])
+	}
+}
+
+func TestConfidenceValidation(t *testing.T) {
+	packet := QuestionAnswerPacket{Question: 1, Confidence: "sure"}
+	if packet.validate() == nil {
+		t.Error("expected an unknown confidence to be turned down")
+	}
+
+	packet = QuestionAnswerPacket{Question: 1, Confidence: HighConfidence}
+	if err := packet.validate(); err != nil {
+		t.Errorf("expected a known confidence to be valid, got %v", err)
+	}
+
+	settings := GameSettingsPacket{Settings: GameSettings{BuzzerMode: true, ConfidenceMode: true}}
+	if settings.validate() == nil {
+		t.Error("expected confidence mode to be turned down in buzzer games")
+	}
+}
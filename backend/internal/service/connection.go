@@ -0,0 +1,30 @@
+package service
+
+import "time"
+
+// Connection is the subset of a WebSocket connection used by the game services.
+// *websocket.Conn satisfies it; tests substitute an in-memory implementation.
+type Connection interface {
+	WriteMessage(messageType int, data []byte) error // Sends a single message to the client, not keeping data once it returns
+	Close() error                                    // Closes the underlying connection
+}
+
+// compressible is implemented by connections that negotiated per-message compression
+type compressible interface {
+	EnableWriteCompression(enable bool) // Toggles compression for subsequent messages
+}
+
+// addressable is implemented by connections that know the IP address of their client
+type addressable interface {
+	IP() string // Address of the client, as seen through the configured proxy header
+}
+
+// deadlined is implemented by connections whose writes can be bounded in time
+type deadlined interface {
+	SetWriteDeadline(t time.Time) error // Makes writes fail once the time has passed
+}
+
+// closeWriter is implemented by connections that can send control frames
+type closeWriter interface {
+	WriteControl(messageType int, data []byte, deadline time.Time) error // Sends a control frame, safe to call during other writes
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// fakeConnection is an in-memory Connection that records every message written to it
+type fakeConnection struct {
+	mu       sync.Mutex
+	messages [][]byte // Raw messages written to the connection
+	closed   bool     // Whether Close has been called
+}
+
+// WriteMessage records a copy of the message
+func (c *fakeConnection) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = append(c.messages, append([]byte(nil), data...))
+	return nil
+}
+
+// Close marks the connection as closed
+func (c *fakeConnection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+// packetIds returns the packet ID of every message written so far
+func (c *fakeConnection) packetIds() []uint8 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := []uint8{}
+	for _, msg := range c.messages {
+		ids = append(ids, msg[0])
+	}
+
+	return ids
+}
+
+// encodePacket builds a client message with the given packet ID and JSON body
+func encodePacket(packetId uint8, packet any) []byte {
+	bytes, err := json.Marshal(packet)
+	if err != nil {
+		panic(err)
+	}
+
+	return append([]byte{packetId}, bytes...)
+}
+
+// packetData returns the JSON body of a packet written to a connection, taken out of its envelope
+func packetData(message []byte) []byte {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(message[1:], &envelope); err != nil {
+		panic(err)
+	}
+
+	return envelope.Data
+}
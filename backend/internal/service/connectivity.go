@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// Connectivity warnings sent to the host while a question is shown
+const (
+	connectionWarningWindow = 10 * time.Second // Span over which lost connections are counted together
+	connectionWarningMin    = 3                // Lost connections within the window before the host is warned
+)
+
+// Kinds of warnings sent to the host
+const (
+	ThrottledWarning    = "throttled"    // The game is too large for the server to keep up every update
+	ConnectivityWarning = "connectivity" // Several players lost their connection in a short time
+)
+
+// connectionLosses counts the players who lost their connection during a question, to warn the host of a
+// network problem once instead of with every single disconnect
+type connectionLosses struct {
+	at     []time.Time // When players lost their connection, within the last window
+	warned time.Time   // When the host was last warned, zero if never
+}
+
+// isReconnectable reports whether a player who is no longer in the game lost their connection and may still resume
+// Parameters:
+// - player: the player
+// Returns:
+// - bool: false for players who left, were removed or whose session expired
+func (g *Game) isReconnectable(player *Player) bool {
+	departed, ok := g.departed[player.session]
+	return ok && departed.player == player && g.clock.Now().Sub(departed.at) <= g.netService.config.SessionTtl
+}
+
+// recordConnectionLoss counts a player who lost their connection during a question and warns the host once enough
+// players did within the window, at most once per window
+func (g *Game) recordConnectionLoss() {
+	if g.State != PlayState {
+		return
+	}
+
+	now := g.clock.Now()
+	recent := g.losses.at[:0]
+	for _, at := range g.losses.at {
+		if now.Sub(at) < connectionWarningWindow {
+			recent = append(recent, at)
+		}
+	}
+	g.losses.at = append(recent, now)
+
+	count := len(g.losses.at)
+	if count < connectionWarningMin || now.Sub(g.losses.warned) < connectionWarningWindow {
+		return
+	}
+
+	g.losses.warned = now
+	g.sendToHost(GameWarningPacket{
+		Kind:    ConnectivityWarning,
+		Message: fmt.Sprintf("%d players lost connection in the last %ds", count, int(connectionWarningWindow.Seconds())),
+		Count:   count,
+	})
+}
+
+// getDisconnectedStates lists the players who lost their connection and may still resume, for the host
+// Returns:
+// - []HostPlayerState: the players, best first
+func (g *Game) getDisconnectedStates() []HostPlayerState {
+	players := []*Player{}
+	for _, departed := range g.departed {
+		if g.isReconnectable(departed.player) {
+			players = append(players, departed.player)
+		}
+	}
+	g.sortPlayers(players)
+
+	states := []HostPlayerState{}
+	for _, player := range players {
+		states = append(states, newHostPlayerState(player))
+	}
+
+	return states
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// hostPackets decodes the packets of a type the host received
+func hostPackets[T any](t *testing.T, host *fakeConnection, packetId uint8) []T {
+	t.Helper()
+
+	packets := []T{}
+	for _, message := range host.messages {
+		if message[0] != packetId {
+			continue
+		}
+
+		var packet T
+		if err := json.Unmarshal(packetData(message), &packet); err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, packet)
+	}
+
+	return packets
+}
+
+func TestConnectivityWarning(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{SessionTtl: time.Minute})
+	clock := newFakeClock()
+	c.clock = clock
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	c.addGame(game)
+
+	connections := []*fakeConnection{}
+	for i := 0; i < 5; i++ {
+		connection := &fakeConnection{}
+		connections = append(connections, connection)
+		game.OnPlayerJoin(fmt.Sprint("player", i), primitive.NilObjectID, 0, 0, "", connection)
+	}
+	game.Start()
+	host.messages = nil
+
+	// Two lost connections are not worth a warning, the third within the window is, the fourth is counted in it
+	for _, connection := range connections[:4] {
+		c.OnDisconnect(connection)
+		clock.Advance(2 * time.Second)
+	}
+
+	warnings := hostPackets[GameWarningPacket](t, host, 40)
+	if len(warnings) != 1 || warnings[0].Kind != ConnectivityWarning || warnings[0].Count != 3 {
+		t.Fatalf("expected one warning about 3 players, got %+v", warnings)
+	}
+
+	disconnects := hostPackets[PlayerDisconnectPacket](t, host, 10)
+	if len(disconnects) != 4 || !disconnects[0].Reconnectable {
+		t.Errorf("expected the players marked as reconnectable, got %+v", disconnects)
+	}
+	if state := game.getHostState(); len(state.Players) != 1 || len(state.Disconnected) != 4 {
+		t.Errorf("expected 1 connected and 4 reconnectable players, got %d and %d", len(state.Players), len(state.Disconnected))
+	}
+
+	// Once the window passed, the count starts over
+	clock.Advance(connectionWarningWindow)
+	c.OnDisconnect(connections[4])
+	if warnings := hostPackets[GameWarningPacket](t, host, 40); len(warnings) != 1 {
+		t.Errorf("expected no warning for a single lost connection, got %+v", warnings)
+	}
+}
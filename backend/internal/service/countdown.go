@@ -0,0 +1,28 @@
+package service
+
+import "time"
+
+// tickInterval is the number of seconds between tick packets to the host. Clients count down to the
+// endsAt timestamps of question, reveal and state packets on their own, so ticks only correct drift.
+const tickInterval = 5
+
+// setTimer sets the timer of the current state, along with the time it runs out at
+// Parameters:
+// - seconds: the time the state lasts
+func (g *Game) setTimer(seconds int) {
+	g.apply(TimerSetEvent{
+		Time:   seconds,
+		EndsAt: g.clock.Now().Add(time.Duration(seconds) * time.Second),
+	})
+}
+
+// endsAt returns when the timer of the current state runs out, for clients to count down to
+// Returns:
+// - int64: the server time in milliseconds, or zero when no timer runs
+func (g *Game) endsAt() int64 {
+	if g.EndsAt.IsZero() {
+		return 0
+	}
+
+	return g.EndsAt.UnixMilli()
+}
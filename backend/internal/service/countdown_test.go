@@ -0,0 +1,68 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// tickPacketId is the packet ID of tick packets
+const tickPacketId = 6
+
+func TestCountdownEndsAt(t *testing.T) {
+	s := newSimulation(t)
+	s.join("alice")
+	started := s.clock.Now()
+	s.start()
+
+	var question PlayerQuestionPacket
+	for _, message := range s.players["alice"].messages {
+		if message[0] == playerQuestionPacketId {
+			if err := json.Unmarshal(packetData(message), &question); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if want := started.Add(3 * time.Second).UnixMilli(); question.EndsAt != want {
+		t.Errorf("expected the question to end at %d, got %d", want, question.EndsAt)
+	}
+
+	// The reveal follows the answer and lasts five seconds
+	s.advance(1)
+	s.answer("alice", 0)
+	var reveal ChangeGameStatePacket
+	for _, message := range s.players["alice"].messages {
+		if message[0] == changeGameStatePacketId {
+			if err := json.Unmarshal(packetData(message), &reveal); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if want := started.Add(6 * time.Second).UnixMilli(); reveal.State != RevealState || reveal.EndsAt != want {
+		t.Errorf("expected the reveal to end at %d, got %+v", want, reveal)
+	}
+
+	// Clients count down on their own, the host is only sent a tick every few seconds
+	ticks := s.count(s.host, tickPacketId)
+	s.advance(5 + 30)
+	if got := s.count(s.host, tickPacketId) - ticks; got != (5+30)/tickInterval {
+		t.Errorf("expected a tick every %d seconds, got %d ticks in 35 seconds", tickInterval, got)
+	}
+}
+
+func TestCountdownResumesAfterPause(t *testing.T) {
+	s := newSimulation(t)
+	s.join("alice")
+	s.start()
+
+	// The timer stands still while nobody plays, so it runs out later than first announced
+	s.disconnect("alice")
+	s.advance(10)
+	s.join("alice")
+	s.advance(1)
+	s.game.run("check", func() {
+		if want := s.clock.Now().Add(time.Duration(s.game.Time) * time.Second).UnixMilli(); s.game.endsAt() != want {
+			t.Errorf("expected the timer to run out at %d after the pause, got %d", want, s.game.endsAt())
+		}
+	})
+}
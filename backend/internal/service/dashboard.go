@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+// PlatformStats is the health of the whole server, streamed to ops dashboards as a plain JSON text message
+type PlatformStats struct {
+	At       time.Time `json:"at"`       // When the stats were taken
+	Interval float64   `json:"interval"` // Seconds since the previous stats on the feed, 0 for the first
+
+	Games   int `json:"games"`   // Games open right now
+	Players int `json:"players"` // Players connected to those games
+
+	GamesCreated     int64 `json:"gamesCreated"`     // Games hosted since the server started
+	GamesEnded       int64 `json:"gamesEnded"`       // Games that ended since the server started
+	WriteErrors      int64 `json:"writeErrors"`      // Failed writes to clients since the server started
+	RejectedMessages int64 `json:"rejectedMessages"` // Malformed messages from clients since the server started
+	FailedJoins      int64 `json:"failedJoins"`      // Attempts to join with unknown codes since the server started
+	JoinLockouts     int64 `json:"joinLockouts"`     // Addresses locked out for guessing join codes since the server started
+
+	NewGames        int64   `json:"newGames"`        // Games hosted since the previous stats
+	NewEndedGames   int64   `json:"newEndedGames"`   // Games that ended since the previous stats
+	NewJoinLockouts int64   `json:"newJoinLockouts"` // Addresses locked out for guessing join codes since the previous stats, to alert on
+	ErrorsPerMinute float64 `json:"errorsPerMinute"` // Failed writes and malformed messages per minute since the previous stats
+}
+
+// newPlatformStats takes the current stats of the server
+// Parameters:
+// - previous: the stats sent before on the same feed, nil for the first
+// Returns:
+// - PlatformStats: the stats, with the rates since the previous ones
+func (c *NetService) newPlatformStats(previous *PlatformStats) PlatformStats {
+	c.gamesMu.Lock()
+	games := append([]*Game{}, c.games...)
+	c.gamesMu.Unlock()
+
+	c.writeErrorsMu.Lock()
+	writeErrors := c.totalWriteErrors
+	c.writeErrorsMu.Unlock()
+
+	stats := PlatformStats{
+		At:               c.clock.Now(),
+		Games:            len(games),
+		GamesCreated:     c.gamesCreated.Load(),
+		GamesEnded:       c.gamesEnded.Load(),
+		WriteErrors:      writeErrors,
+		RejectedMessages: c.rejectedMessages.Load(),
+		FailedJoins:      c.failedJoins.Load(),
+		JoinLockouts:     c.joinLockouts.Load(),
+	}
+	for _, game := range games {
+		game.run("stats", func() {
+			stats.Players += len(game.Players)
+		})
+	}
+
+	if previous == nil {
+		return stats
+	}
+
+	stats.Interval = stats.At.Sub(previous.At).Seconds()
+	stats.NewGames = stats.GamesCreated - previous.GamesCreated
+	stats.NewEndedGames = stats.GamesEnded - previous.GamesEnded
+	stats.NewJoinLockouts = stats.JoinLockouts - previous.JoinLockouts
+	if stats.Interval > 0 {
+		failures := stats.WriteErrors + stats.RejectedMessages - previous.WriteErrors - previous.RejectedMessages
+		stats.ErrorsPerMinute = float64(failures) / stats.Interval * 60
+	}
+
+	return stats
+}
+
+// WatchPlatform sends the stats of the server to an admin dashboard at the configured interval, at least a second,
+// until the connection fails or the context is cancelled.
+// Parameters:
+// - ctx: the context of the connection, cancelled when it closes.
+// - connection: the connection of the dashboard.
+func (c *NetService) WatchPlatform(ctx context.Context, connection Connection) {
+	interval := max(c.config.AdminFeedInterval, time.Second)
+
+	var previous *PlatformStats
+	for ctx.Err() == nil {
+		stats := c.newPlatformStats(previous)
+		bytes, err := json.Marshal(stats)
+		if err != nil {
+			return
+		}
+
+		c.setWriteDeadline(connection)
+		if err := connection.WriteMessage(websocket.TextMessage, bytes); err != nil {
+			return
+		}
+
+		previous = &stats
+		c.clock.Sleep(interval)
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// lastPlatformStats decodes the latest stats sent on a dashboard connection
+func lastPlatformStats(t *testing.T, connection *fakeConnection) PlatformStats {
+	t.Helper()
+	connection.mu.Lock()
+	defer connection.mu.Unlock()
+
+	var stats PlatformStats
+	if err := json.Unmarshal(connection.messages[len(connection.messages)-1], &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	return stats
+}
+
+func TestPlatformFeed(t *testing.T) {
+	clock := newFakeClock()
+	c := Net(NetOptions{}, config.Config{AdminFeedInterval: 10 * time.Second})
+	c.clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dashboard := &fakeConnection{}
+	done := make(chan struct{})
+	go func() {
+		c.WatchPlatform(ctx, dashboard)
+		close(done)
+	}()
+	for clock.sleeping() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if stats := lastPlatformStats(t, dashboard); stats.Games != 0 || stats.Interval != 0 {
+		t.Fatalf("expected an empty server in the first stats, got %+v", stats)
+	}
+
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	c.addGame(game)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	c.strike(&fakeConnection{}, ErrInvalidPacket)
+	c.strike(&fakeConnection{}, ErrInvalidPacket)
+
+	clock.Advance(10 * time.Second)
+	for clock.sleeping() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := lastPlatformStats(t, dashboard)
+	if stats.Games != 1 || stats.Players != 1 || stats.NewGames != 1 || stats.Interval != 10 {
+		t.Errorf("expected the new game and its player, got %+v", stats)
+	}
+	if stats.RejectedMessages != 2 || stats.ErrorsPerMinute != 12 {
+		t.Errorf("expected two malformed messages in ten seconds, got %+v", stats)
+	}
+
+	cancel()
+	clock.Advance(10 * time.Second)
+	<-done
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// RecordQuestionStats adds the answers of a finished game to the statistics of the quiz's questions
+// and recalibrates their difficulty. Questions removed from the quiz since the game are skipped.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quizId: the ObjectID of the quiz that was played.
+// - results: how players did on each question of the game.
+// Returns:
+// - An error if the quiz cannot be read or updated.
+func (s QuizService) RecordQuestionStats(ctx context.Context, quizId primitive.ObjectID, results []entity.QuestionResult) error {
+	quiz, err := s.quizCollection.GetQuizById(ctx, quizId)
+	if err != nil || quiz == nil {
+		return err
+	}
+
+	addQuestionStats(quiz.Questions, results)
+	if err := s.quizCollection.UpdateQuiz(ctx, *quiz); err != nil {
+		return err
+	}
+
+	s.invalidateQuiz(quizId)
+	return nil
+}
+
+// addQuestionStats counts the answers of a game into the statistics of the questions they belong to
+// Parameters:
+// - questions: the questions of the quiz, updated in place
+// - results: how players did on each question of the game
+func addQuestionStats(questions []entity.QuizQuestion, results []entity.QuestionResult) {
+	for _, result := range results {
+		for i := range questions {
+			if questions[i].Id != result.QuestionId || result.QuestionId == "" {
+				continue
+			}
+
+			stats := &questions[i].Stats
+			stats.Answered += result.Answered
+			stats.Correct += result.Correct
+			if stats.Answered > 0 {
+				stats.Difficulty = 1 - float64(stats.Correct)/float64(stats.Answered)
+			}
+		}
+	}
+}
+
+// keepQuestionStats carries the statistics of stored questions over to their edited versions,
+// so authors cannot overwrite them and editing a question does not reset its calibration
+// Parameters:
+// - stored: the questions before the edit
+// - edited: the questions after the edit, updated in place
+func keepQuestionStats(stored []entity.QuizQuestion, edited []entity.QuizQuestion) {
+	stats := map[string]entity.QuestionStats{}
+	for _, question := range stored {
+		if question.Id != "" {
+			stats[question.Id] = question.Stats
+		}
+	}
+
+	for i := range edited {
+		edited[i].Stats = stats[edited[i].Id]
+	}
+}
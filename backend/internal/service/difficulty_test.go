@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestAddQuestionStats(t *testing.T) {
+	questions := []entity.QuizQuestion{{Id: "q1"}, {Id: "q2"}}
+
+	addQuestionStats(questions, []entity.QuestionResult{
+		{QuestionId: "q1", Answered: 4, Correct: 4},
+		{QuestionId: "q2", Answered: 4, Correct: 1},
+		{QuestionId: "removed", Answered: 4, Correct: 2},
+	})
+	addQuestionStats(questions, []entity.QuestionResult{
+		{QuestionId: "q2", Answered: 4, Correct: 1},
+	})
+
+	if got := questions[0].Stats; got.Answered != 4 || got.Difficulty != 0 {
+		t.Errorf("expected an easy first question, got %+v", got)
+	}
+	if got := questions[1].Stats; got.Answered != 8 || got.Correct != 2 || got.Difficulty != 0.75 {
+		t.Errorf("expected a hard second question, got %+v", got)
+	}
+}
+
+func TestKeepQuestionStats(t *testing.T) {
+	stored := []entity.QuizQuestion{{Id: "q1", Stats: entity.QuestionStats{Answered: 10, Correct: 5, Difficulty: 0.5}}}
+	edited := []entity.QuizQuestion{
+		{Id: "q1", Name: "Edited"},
+		{Id: "q2", Stats: entity.QuestionStats{Answered: 99, Correct: 99}},
+	}
+
+	keepQuestionStats(stored, edited)
+
+	if edited[0].Stats.Answered != 10 || edited[0].Stats.Difficulty != 0.5 {
+		t.Errorf("expected the stats of the edited question to be kept, got %+v", edited[0].Stats)
+	}
+	if edited[1].Stats.Answered != 0 {
+		t.Errorf("expected stats sent with a new question to be dropped, got %+v", edited[1].Stats)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+// digestInterval is the number of ticks between state digests
+const digestInterval = 3
+
+// StateDigestPacket is a lightweight summary of the game sent every few ticks, so clients notice when
+// the screen they show no longer matches the game and can ask for a snapshot
+type StateDigestPacket struct {
+	State    GameState `json:"state"`    // Current state of the game
+	Question int       `json:"question"` // Index of the current question, -1 before the first
+	Time     int       `json:"time"`     // Seconds left in the current state
+	EndsAt   int64     `json:"endsAt"`   // Server time in milliseconds when the current state's timer runs out
+}
+
+// ResyncRequestPacket asks the server for a snapshot of the game after a client noticed it is out of sync
+type ResyncRequestPacket struct{}
+
+// StateSnapshotPacket is everything a client needs to show the current screen of the game again
+type StateSnapshotPacket struct {
+	State    GameState `json:"state"`    // Current state of the game
+	Question int       `json:"question"` // Index of the current question, -1 before the first
+	Time     int       `json:"time"`     // Seconds left in the current state
+	EndsAt   int64     `json:"endsAt"`   // Server time in milliseconds when the current state's timer runs out
+	Points   int       `json:"points"`   // Points of the player, 0 for the host
+	Choice   int       `json:"choice"`   // The player's answer to the current question, -1 if none or for the host
+
+	PlayerQuestion *PlayerQuestionPacket `json:"playerQuestion,omitempty"` // The open question, for players while it is played
+	Host           *HostGameState        `json:"host,omitempty"`           // The full state of the game, for the host
+}
+
+// newStateDigest summarizes the current state of the game
+// Returns:
+// - StateDigestPacket: the digest
+func (g *Game) newStateDigest() StateDigestPacket {
+	return StateDigestPacket{
+		State:    g.State,
+		Question: g.CurrentQuestion,
+		Time:     g.Time,
+		EndsAt:   g.endsAt(),
+	}
+}
+
+// newStateSnapshot starts a snapshot of the game from its digest
+// Returns:
+// - StateSnapshotPacket: the snapshot without anything specific to the host or a player
+func (g *Game) newStateSnapshot() StateSnapshotPacket {
+	return StateSnapshotPacket{
+		State:    g.State,
+		Question: g.CurrentQuestion,
+		Time:     g.Time,
+		EndsAt:   g.endsAt(),
+		Choice:   -1,
+	}
+}
+
+// OnHostResync answers a host that is out of sync with a snapshot of the game
+func (g *Game) OnHostResync() {
+	snapshot := g.newStateSnapshot()
+	host := g.getHostState()
+	snapshot.Host = &host
+
+	g.sendToHost(snapshot)
+}
+
+// OnPlayerResync answers a player who is out of sync with a snapshot of the game
+// Parameters:
+// - player: the player who asked
+func (g *Game) OnPlayerResync(player *Player) {
+	snapshot := g.newStateSnapshot()
+	if !g.Settings.ExamMode || g.State == EndState {
+		snapshot.Points = player.Points
+	}
+	if answer, ok := player.Answers[g.CurrentQuestion]; ok && player.Answered {
+		snapshot.Choice = answer.Choice
+	}
+	if g.State == PlayState && player.paced != nil {
+		g.pacedSnapshot(player, &snapshot)
+	} else if g.State == PlayState {
+		question := g.newPlayerQuestionPacket(player)
+		snapshot.PlayerQuestion = &question
+	}
+
+	g.netService.SendPacket(player.Connection, snapshot)
+	g.sendMediaCue(player)
+}
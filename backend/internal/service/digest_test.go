@@ -0,0 +1,53 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// stateSnapshotPacketId is the packet ID of StateSnapshotPacket
+const stateSnapshotPacketId = 36
+
+// lastSnapshot decodes the last StateSnapshotPacket written to a connection
+func lastSnapshot(t *testing.T, connection *fakeConnection) StateSnapshotPacket {
+	message := connection.messages[len(connection.messages)-1]
+	if message[0] != stateSnapshotPacketId {
+		t.Fatalf("expected a snapshot last, got packets %v", connection.packetIds())
+	}
+
+	var snapshot StateSnapshotPacket
+	if err := json.Unmarshal(packetData(message), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+func TestResyncSnapshots(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	connection := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", connection)
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	alice := game.Players[0]
+	game.Start()
+	game.OnPlayerAnswer(1, alice)
+
+	game.OnPlayerResync(alice)
+	snapshot := lastSnapshot(t, connection)
+	if snapshot.State != PlayState || snapshot.Question != 0 || snapshot.Choice != 1 || snapshot.Host != nil {
+		t.Errorf("unexpected player snapshot: %+v", snapshot)
+	}
+	if snapshot.PlayerQuestion == nil || snapshot.PlayerQuestion.Choices != 2 {
+		t.Errorf("expected the open question in the snapshot, got %+v", snapshot.PlayerQuestion)
+	}
+
+	game.OnHostResync()
+	snapshot = lastSnapshot(t, host)
+	if snapshot.Host == nil || len(snapshot.Host.Players) != 2 || snapshot.PlayerQuestion != nil || snapshot.Choice != -1 {
+		t.Errorf("unexpected host snapshot: %+v", snapshot)
+	}
+}
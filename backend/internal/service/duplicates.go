@@ -0,0 +1,119 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// duplicateSimilarity is the share of words two questions must have in common to be reported as duplicates
+const duplicateSimilarity = 0.8
+
+// DuplicateWarning reports a question that is nearly the same as another one
+type DuplicateWarning struct {
+	QuestionId  string             `json:"questionId"`  // ID of the question in the saved quiz
+	Question    string             `json:"question"`    // The text of the question
+	QuizId      primitive.ObjectID `json:"quizId"`      // ID of the quiz the other question belongs to, the saved quiz itself for duplicates within it
+	QuizName    string             `json:"quizName"`    // Name of the quiz the other question belongs to
+	DuplicateId string             `json:"duplicateId"` // ID of the other question
+	Duplicate   string             `json:"duplicate"`   // The text of the other question
+	Similarity  float64            `json:"similarity"`  // Share of words both questions have in common, from 0.8 to 1
+}
+
+// findDuplicates looks for near-duplicate questions within a quiz and across the other quizzes the user may view.
+// Within the quiz, a question is reported against the first question it repeats.
+// Parameters:
+// - quiz: the quiz being saved
+// - others: the quizzes of the library, the saved quiz among them and those the user may not view are skipped
+// - userId: the ObjectID of the user saving the quiz, zero for anonymous requests
+// Returns:
+// - []DuplicateWarning: the duplicates found, in question order
+func findDuplicates(quiz entity.Quiz, others []entity.Quiz, userId primitive.ObjectID) []DuplicateWarning {
+	warnings := []DuplicateWarning{}
+	for i, question := range quiz.Questions {
+		words := questionWords(question.Name)
+		if len(words) == 0 {
+			continue
+		}
+
+		for _, earlier := range quiz.Questions[:i] {
+			if similarity := wordSimilarity(words, questionWords(earlier.Name)); similarity >= duplicateSimilarity {
+				warnings = append(warnings, newDuplicateWarning(question, quiz, earlier, similarity))
+				break
+			}
+		}
+
+		for _, other := range others {
+			if other.Id == quiz.Id || !CanAccessQuiz(other, userId, "", entity.ViewPermission) {
+				continue
+			}
+
+			for _, candidate := range other.Questions {
+				if similarity := wordSimilarity(words, questionWords(candidate.Name)); similarity >= duplicateSimilarity {
+					warnings = append(warnings, newDuplicateWarning(question, other, candidate, similarity))
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+// newDuplicateWarning creates a warning about a question repeating another one
+// Parameters:
+// - question: the question of the saved quiz
+// - quiz: the quiz the other question belongs to
+// - duplicate: the other question
+// - similarity: how similar both questions are
+// Returns:
+// - DuplicateWarning: the warning
+func newDuplicateWarning(question entity.QuizQuestion, quiz entity.Quiz, duplicate entity.QuizQuestion, similarity float64) DuplicateWarning {
+	return DuplicateWarning{
+		QuestionId:  question.Id,
+		Question:    question.Name,
+		QuizId:      quiz.Id,
+		QuizName:    quiz.Name,
+		DuplicateId: duplicate.Id,
+		Duplicate:   duplicate.Name,
+		Similarity:  similarity,
+	}
+}
+
+// questionWords normalizes the text of a question into its set of words, ignoring case and punctuation
+// Parameters:
+// - text: the text of the question
+// Returns:
+// - map[string]bool: the distinct words
+func questionWords(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		words[word] = true
+	}
+
+	return words
+}
+
+// wordSimilarity compares two sets of words by the share they have in common (Jaccard index)
+// Parameters:
+// - a, b: the sets of words
+// Returns:
+// - float64: 1 for the same words, 0 for nothing in common or empty sets
+func wordSimilarity(a, b map[string]bool) float64 {
+	common := 0
+	for word := range a {
+		if b[word] {
+			common++
+		}
+	}
+
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 0
+	}
+
+	return float64(common) / float64(union)
+}
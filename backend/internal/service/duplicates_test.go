@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	quiz := entity.Quiz{Id: primitive.NewObjectID(), Name: "Capitals", Questions: []entity.QuizQuestion{
+		{Id: "a", Name: "What is the capital of France?"},
+		{Id: "b", Name: "what is the CAPITAL of france"},
+		{Id: "c", Name: "What is 2 + 2?"},
+		{Id: "d", Name: "What is 3 + 3?"},
+	}}
+	other := entity.Quiz{Id: primitive.NewObjectID(), Name: "Maths", Questions: []entity.QuizQuestion{
+		{Id: "x", Name: "What is 2+2"},
+	}}
+	private := entity.Quiz{Id: primitive.NewObjectID(), Name: "Secret", OwnerId: primitive.NewObjectID(), Questions: []entity.QuizQuestion{
+		{Id: "y", Name: "What is 3+3"},
+	}}
+
+	// The saved quiz is part of the library as well and must not match itself, nor may private quizzes of others show up
+	warnings := findDuplicates(quiz, []entity.Quiz{quiz, other, private}, primitive.NewObjectID())
+	if len(warnings) != 2 {
+		t.Fatalf("expected two duplicates, got %+v", warnings)
+	}
+	if got := warnings[0]; got.QuestionId != "b" || got.DuplicateId != "a" || got.QuizId != quiz.Id || got.Similarity != 1 {
+		t.Errorf("expected the repeated question within the quiz, got %+v", got)
+	}
+	if got := warnings[1]; got.QuestionId != "c" || got.DuplicateId != "x" || got.QuizName != "Maths" {
+		t.Errorf("expected the question repeated from another quiz, got %+v", got)
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gofiber/contrib/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// maxQuizEditors is the highest number of editors connected to a single quiz
+const maxQuizEditors = 20
+
+// ErrTooManyEditors is returned when a quiz has no room for another editor
+var ErrTooManyEditors = errors.New("too many editors")
+
+// Types of the messages sent to editors
+const (
+	EditorPresenceMessage = "presence" // The editors of the quiz changed or moved to another question
+	EditorChangesMessage  = "changes"  // Someone saved changes to the quiz
+)
+
+// Kinds of changes to a question
+const (
+	QuestionAdded   = "added"
+	QuestionEdited  = "edited"
+	QuestionRemoved = "removed"
+)
+
+// EditorService tracks who is editing which quiz and tells the editors of a quiz about each other.
+// Messages are sent as plain JSON text, like the leaderboard widget's.
+type EditorService struct {
+	mu    sync.Mutex                              // Guards the rooms and serializes writes to the connections
+	rooms map[primitive.ObjectID][]*editorSession // Connected editors by quiz
+}
+
+// editorSession is a single editor connected to a quiz
+type editorSession struct {
+	connection Connection     // Connection of the editor
+	presence   EditorPresence // What the other editors see of the editor
+}
+
+// EditorPresence is an editor of a quiz as seen by the others
+type EditorPresence struct {
+	UserId     primitive.ObjectID `json:"userId"`     // ID of the editor's user
+	Name       string             `json:"name"`       // Display name of the editor
+	QuestionId string             `json:"questionId"` // ID of the question the editor has open, empty if none
+}
+
+// QuestionChange is a question that was added, edited or removed by a save
+type QuestionChange struct {
+	QuestionId string `json:"questionId"` // ID of the question in the quiz
+	Kind       string `json:"kind"`       // What happened to the question, see QuestionAdded
+	Name       string `json:"name"`       // The text of the question, as saved or before it was removed
+}
+
+// EditorMessage is sent to the editors of a quiz
+type EditorMessage struct {
+	Type    string           `json:"type"`              // Kind of message, see EditorPresenceMessage
+	Editors []EditorPresence `json:"editors,omitempty"` // Everyone editing the quiz, for presence messages
+	By      string           `json:"by,omitempty"`      // Name of the editor who saved, empty if unknown, for change messages
+	Changes []QuestionChange `json:"changes,omitempty"` // The changed questions, for change messages
+}
+
+// Editor creates a new EditorService instance
+// Returns:
+// - A pointer to a new EditorService without editors
+func Editor() *EditorService {
+	return &EditorService{
+		rooms: map[primitive.ObjectID][]*editorSession{},
+	}
+}
+
+// Join registers a connection as an editor of a quiz and tells every editor of the quiz who is there.
+// Parameters:
+// - quizId: the ObjectID of the quiz being edited.
+// - userId: the ObjectID of the editor's user.
+// - name: the display name of the editor.
+// - connection: the connection of the editor.
+// Returns:
+// - ErrTooManyEditors if the quiz has no room for another editor.
+func (s *EditorService) Join(quizId primitive.ObjectID, userId primitive.ObjectID, name string, connection Connection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rooms[quizId]) >= maxQuizEditors {
+		return ErrTooManyEditors
+	}
+
+	s.rooms[quizId] = append(s.rooms[quizId], &editorSession{
+		connection: connection,
+		presence:   EditorPresence{UserId: userId, Name: name},
+	})
+	s.sendPresence(quizId)
+	return nil
+}
+
+// Leave unregisters an editor connection and tells the remaining editors.
+// Parameters:
+// - quizId: the ObjectID of the quiz being edited.
+// - connection: the connection of the editor.
+func (s *EditorService) Leave(quizId primitive.ObjectID, connection Connection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeSession(quizId, connection)
+	s.sendPresence(quizId)
+}
+
+// Focus records which question an editor has open and tells every editor of the quiz.
+// Parameters:
+// - quizId: the ObjectID of the quiz being edited.
+// - connection: the connection of the editor.
+// - questionId: the ID of the open question, empty if none.
+func (s *EditorService) Focus(quizId primitive.ObjectID, connection Connection, questionId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.rooms[quizId] {
+		if session.connection == connection {
+			session.presence.QuestionId = questionId
+		}
+	}
+	s.sendPresence(quizId)
+}
+
+// AnnounceChanges tells the editors of a quiz which questions a save changed.
+// Nothing is sent if the save changed no question.
+// Parameters:
+// - quizId: the ObjectID of the saved quiz.
+// - by: the display name of the editor who saved, empty if unknown.
+// - before: the questions of the quiz before the save.
+// - after: the questions of the quiz after the save.
+func (s *EditorService) AnnounceChanges(quizId primitive.ObjectID, by string, before []entity.QuizQuestion, after []entity.QuizQuestion) {
+	changes := diffQuestions(before, after)
+	if len(changes) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.broadcast(quizId, EditorMessage{Type: EditorChangesMessage, By: by, Changes: changes})
+}
+
+// diffQuestions lists the questions added, edited and removed between two versions of a quiz.
+// Questions are matched by ID; statistics kept by the server are not edits.
+// Parameters:
+// - before: the questions before the change
+// - after: the questions after the change
+// Returns:
+// - []QuestionChange: the changes, added and edited questions in their new order, then the removed ones
+func diffQuestions(before []entity.QuizQuestion, after []entity.QuizQuestion) []QuestionChange {
+	old := map[string]entity.QuizQuestion{}
+	for _, question := range before {
+		old[question.Id] = question
+	}
+
+	changes := []QuestionChange{}
+	kept := map[string]bool{}
+	for _, question := range after {
+		kept[question.Id] = true
+
+		previous, ok := old[question.Id]
+		if !ok {
+			changes = append(changes, QuestionChange{QuestionId: question.Id, Kind: QuestionAdded, Name: question.Name})
+			continue
+		}
+
+		if !sameQuestion(previous, question) {
+			changes = append(changes, QuestionChange{QuestionId: question.Id, Kind: QuestionEdited, Name: question.Name})
+		}
+	}
+
+	for _, question := range before {
+		if !kept[question.Id] {
+			changes = append(changes, QuestionChange{QuestionId: question.Id, Kind: QuestionRemoved, Name: question.Name})
+		}
+	}
+
+	return changes
+}
+
+// sameQuestion reports whether an editor changed nothing about a question
+// Parameters:
+// - a: the question before
+// - b: the question after
+// Returns:
+// - bool: true if the questions only differ in their statistics
+func sameQuestion(a entity.QuizQuestion, b entity.QuizQuestion) bool {
+	return a.Name == b.Name && a.Time == b.Time && a.MaxPoints == b.MaxPoints && a.HostNotes == b.HostNotes && a.Hint == b.Hint &&
+		slices.Equal(a.Choices, b.Choices) &&
+		slices.EqualFunc(a.Media, b.Media, func(x entity.QuestionMedia, y entity.QuestionMedia) bool {
+			return x.Type == y.Type && x.MediaId == y.MediaId && x.Url == y.Url
+		})
+}
+
+// sendPresence tells every editor of a quiz who is editing it; the caller holds the lock
+// Parameters:
+// - quizId: the ObjectID of the quiz
+func (s *EditorService) sendPresence(quizId primitive.ObjectID) {
+	editors := []EditorPresence{}
+	for _, session := range s.rooms[quizId] {
+		editors = append(editors, session.presence)
+	}
+
+	s.broadcast(quizId, EditorMessage{Type: EditorPresenceMessage, Editors: editors})
+}
+
+// broadcast sends a message to every editor of a quiz, dropping editors that cannot be reached; the caller holds the lock
+// Parameters:
+// - quizId: the ObjectID of the quiz
+// - message: the message to send
+func (s *EditorService) broadcast(quizId primitive.ObjectID, message EditorMessage) {
+	bytes, err := json.Marshal(message)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, session := range slices.Clone(s.rooms[quizId]) {
+		if err := session.connection.WriteMessage(websocket.TextMessage, bytes); err != nil {
+			s.removeSession(quizId, session.connection)
+		}
+	}
+}
+
+// removeSession forgets an editor connection; the caller holds the lock
+// Parameters:
+// - quizId: the ObjectID of the quiz
+// - connection: the connection of the editor
+func (s *EditorService) removeSession(quizId primitive.ObjectID, connection Connection) {
+	sessions := slices.DeleteFunc(s.rooms[quizId], func(session *editorSession) bool {
+		return session.connection == connection
+	})
+
+	if len(sessions) == 0 {
+		delete(s.rooms, quizId)
+		return
+	}
+
+	s.rooms[quizId] = sessions
+}
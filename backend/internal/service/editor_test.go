@@ -0,0 +1,92 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// lastEditorMessage decodes the last message sent to an editor
+func lastEditorMessage(t *testing.T, connection *fakeConnection) EditorMessage {
+	t.Helper()
+	connection.mu.Lock()
+	defer connection.mu.Unlock()
+
+	var message EditorMessage
+	if len(connection.messages) == 0 {
+		t.Fatalf("expected a message to the editor")
+	}
+	if err := json.Unmarshal(connection.messages[len(connection.messages)-1], &message); err != nil {
+		t.Fatal(err)
+	}
+
+	return message
+}
+
+func TestEditorPresence(t *testing.T) {
+	editors := Editor()
+	quizId := primitive.NewObjectID()
+	alice, bob := &fakeConnection{}, &fakeConnection{}
+
+	if err := editors.Join(quizId, primitive.NewObjectID(), "Alice", alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := editors.Join(quizId, primitive.NewObjectID(), "Bob", bob); err != nil {
+		t.Fatal(err)
+	}
+	editors.Focus(quizId, bob, "q2")
+
+	message := lastEditorMessage(t, alice)
+	if message.Type != EditorPresenceMessage || len(message.Editors) != 2 || message.Editors[1].QuestionId != "q2" {
+		t.Errorf("expected Alice to see Bob on q2, got %+v", message)
+	}
+
+	editors.Leave(quizId, bob)
+	if message := lastEditorMessage(t, alice); len(message.Editors) != 1 || message.Editors[0].Name != "Alice" {
+		t.Errorf("expected Alice alone after Bob left, got %+v", message)
+	}
+}
+
+func TestEditorAnnounceChanges(t *testing.T) {
+	editors := Editor()
+	quizId := primitive.NewObjectID()
+	alice := &fakeConnection{}
+	if err := editors.Join(quizId, primitive.NewObjectID(), "Alice", alice); err != nil {
+		t.Fatal(err)
+	}
+
+	before := []entity.QuizQuestion{
+		{Id: "q1", Name: "Capital of France?"},
+		{Id: "q2", Name: "2 + 2?"},
+		{Id: "q3", Name: "Largest ocean?"},
+	}
+	after := []entity.QuizQuestion{
+		{Id: "q1", Name: "Capital of France?", Stats: entity.QuestionStats{Answered: 10}},
+		{Id: "q2", Name: "2 + 3?"},
+		{Id: "q4", Name: "Smallest planet?"},
+	}
+	editors.AnnounceChanges(quizId, "Bob", before, after)
+
+	message := lastEditorMessage(t, alice)
+	want := []QuestionChange{
+		{QuestionId: "q2", Kind: QuestionEdited, Name: "2 + 3?"},
+		{QuestionId: "q4", Kind: QuestionAdded, Name: "Smallest planet?"},
+		{QuestionId: "q3", Kind: QuestionRemoved, Name: "Largest ocean?"},
+	}
+	if message.Type != EditorChangesMessage || message.By != "Bob" || len(message.Changes) != len(want) {
+		t.Fatalf("expected the changes by Bob, got %+v", message)
+	}
+	for i := range want {
+		if message.Changes[i] != want[i] {
+			t.Errorf("change %d: expected %+v, got %+v", i, want[i], message.Changes[i])
+		}
+	}
+
+	count := len(alice.messages)
+	editors.AnnounceChanges(quizId, "Bob", after, after)
+	if len(alice.messages) != count {
+		t.Errorf("expected no message for a save without changes")
+	}
+}
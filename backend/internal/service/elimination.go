@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// maxEliminationPercent is the largest share of the players still in that can be eliminated after a question
+const maxEliminationPercent = 50
+
+// PlayerEliminatedPacket tells a player they were knocked out and can only watch from now on
+type PlayerEliminatedPacket struct {
+	Question int `json:"question"` // Index of the question after which the player was eliminated
+	Active   int `json:"active"`   // Number of players still in
+}
+
+// EliminationPacket tells the host which players were knocked out after a question
+type EliminationPacket struct {
+	Eliminated []uuid.UUID `json:"eliminated"` // IDs of the players eliminated after the question
+	Active     int         `json:"active"`     // Number of players still in
+	Total      int         `json:"total"`      // Number of players in the game, eliminated or not
+}
+
+// eliminatePlayers knocks out the bottom share of the players still in after a reveal, as set in the settings,
+// and at least one of them. Players tied with the last one to stay are spared, and one player always stays in.
+func (g *Game) eliminatePlayers() {
+	if g.Settings.EliminationPercent == 0 {
+		return
+	}
+
+	active := g.getActivePlayers()
+	g.sortPlayers(active)
+
+	count := max(1, len(active)*g.Settings.EliminationPercent/100)
+	if count >= len(active) {
+		return
+	}
+
+	scoring := g.scoring()
+	lastStaying := active[len(active)-count-1]
+	eliminated := []*Player{}
+	for _, player := range active[len(active)-count:] {
+		if g.ranks(lastStaying, player, scoring) {
+			eliminated = append(eliminated, player)
+		}
+	}
+
+	if len(eliminated) == 0 {
+		return
+	}
+
+	g.eliminations++
+	ids := []uuid.UUID{}
+	for _, player := range eliminated {
+		player.Eliminated = true
+		player.eliminatedIn = g.eliminations
+		ids = append(ids, player.Id)
+	}
+
+	remaining := len(active) - len(eliminated)
+	fmt.Println(len(eliminated), "players eliminated in game", g.Code+",", remaining, "left")
+	for _, player := range eliminated {
+		g.netService.SendPacket(player.Connection, PlayerEliminatedPacket{
+			Question: g.CurrentQuestion,
+			Active:   remaining,
+		})
+	}
+	g.sendToHost(EliminationPacket{
+		Eliminated: ids,
+		Active:     remaining,
+		Total:      len(g.Players),
+	})
+}
+
+// getActivePlayers returns the players who were not eliminated
+func (g *Game) getActivePlayers() []*Player {
+	players := []*Player{}
+	for _, player := range g.Players {
+		if !player.Eliminated {
+			players = append(players, player)
+		}
+	}
+
+	return players
+}
+
+// lastOneStanding reports whether an elimination game is down to a single player, who wins it
+func (g *Game) lastOneStanding() bool {
+	return g.Settings.EliminationPercent > 0 && len(g.Players) > 1 && len(g.getActivePlayers()) <= 1
+}
+
+// ranksByElimination orders players by how long they stayed in an elimination game
+// Parameters:
+// - a: the first player
+// - b: the second player
+// Returns:
+// - bool: true if a stayed in longer than b
+// - bool: true if the players differ in how long they stayed in, false if their rank depends on their points
+func ranksByElimination(a *Player, b *Player) (bool, bool) {
+	switch {
+	case a.Eliminated != b.Eliminated:
+		return !a.Eliminated, true
+	case a.Eliminated && a.eliminatedIn != b.eliminatedIn:
+		return a.eliminatedIn > b.eliminatedIn, true
+	}
+
+	return false, false
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// playerEliminatedPacketId is the ID of PlayerEliminatedPacket on the wire
+const playerEliminatedPacketId = 49
+
+func TestEliminatePlayers(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		game.OnPlayerJoin(name, primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	}
+	daveConnection := &fakeConnection{}
+	game.OnPlayerJoin("dave", primitive.NilObjectID, 0, 0, "", daveConnection)
+	alice, bob, carol, dave := game.Players[0], game.Players[1], game.Players[2], game.Players[3]
+	game.OnSettings(GameSettings{EliminationPercent: 25})
+
+	// Started by hand, without the timer goroutine of Start
+	game.stopLobby()
+	game.ChangeState(PlayState)
+
+	// Dave is the only one to answer wrong
+	game.NextQuestion()
+	game.OnPlayerAnswer(0, alice)
+	game.OnPlayerAnswer(0, bob)
+	game.OnPlayerAnswer(0, carol)
+	game.OnPlayerAnswer(1, dave)
+	if !dave.Eliminated || alice.Eliminated || bob.Eliminated || carol.Eliminated {
+		t.Fatalf("expected only dave to be eliminated")
+	}
+	if !slices.Contains(daveConnection.packetIds(), playerEliminatedPacketId) {
+		t.Errorf("expected dave to be told he was eliminated")
+	}
+
+	// Eliminated players watch without answering, and the others are not waiting for them
+	game.NextQuestion()
+	game.OnPlayerAnswer(1, dave)
+	if dave.Answered {
+		t.Errorf("expected the answer of an eliminated player to be turned down")
+	}
+	dave.Points = 20000
+	game.OnPlayerAnswer(0, alice)
+	game.OnPlayerAnswer(1, bob)
+	game.OnPlayerAnswer(1, carol)
+	if game.State != RevealState {
+		t.Fatalf("expected the question to be revealed once the players still in answered, got state %d", game.State)
+	}
+	if !alice.Eliminated || bob.Eliminated {
+		t.Errorf("expected alice to be eliminated next")
+	}
+
+	players := append([]*Player{}, game.Players...)
+	game.sortPlayers(players)
+	if players[2] != alice || players[3] != dave {
+		t.Errorf("expected the eliminated players to rank last, the later one first, got %s and %s", players[2].Name, players[3].Name)
+	}
+}
+
+func TestLastOneStanding(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnSettings(GameSettings{EliminationPercent: 10})
+
+	game.stopLobby()
+	game.ChangeState(PlayState)
+	game.NextQuestion()
+	game.OnPlayerAnswer(0, game.Players[0])
+	game.OnPlayerAnswer(1, game.Players[1])
+	game.NextQuestion()
+
+	if game.State != EndState {
+		t.Errorf("expected the game to end with a single player left, got state %d", game.State)
+	}
+	if podium := game.getLeaderboard(); podium[0].Name != "alice" {
+		t.Errorf("expected the last one standing to win, got %+v", podium)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Every packet the server sends is its ID byte followed by an envelope in JSON:
+//
+//	{"type": "QuestionShow", "at": 1718000000000, "game": "<game id>", "seq": 17, "data": {...}}
+//
+// type names the packet, at is the server time in milliseconds it was encoded at, game is the ID of the game
+// the connection takes part in (left out outside of games), seq numbers the packet among all packets sent on the
+// connection from 1 (0 for packets encoded outside of a connection) and data is the packet itself. Resent packets
+// keep the envelope they were first sent in.
+
+// encodedPacket is a packet encoded once, to be sealed in an envelope for each connection it is sent to
+type encodedPacket struct {
+	id   uint8         // ID of the packet
+	name string        // Name of the packet, its type without the Packet suffix
+	at   int64         // Server time in milliseconds the packet was encoded at
+	body *bytes.Buffer // JSON body of the packet, from the pool
+}
+
+// encodePacket encodes the body of a packet, to be sealed with seal.
+// Parameters:
+// - packet: the packet structure to encode.
+// Returns:
+// - *encodedPacket: the encoded packet, to hand back with release once sent.
+// - error: an error if the packet type is unknown or cannot be encoded.
+func (c *NetService) encodePacket(packet any) (*encodedPacket, error) {
+	packetId, err := c.packetToPacketId(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.marshalPacket(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encodedPacket{
+		id:   packetId,
+		name: strings.TrimSuffix(reflect.TypeOf(packet).Name(), "Packet"),
+		at:   c.clock.Now().UnixMilli(),
+		body: body,
+	}, nil
+}
+
+// release hands the body of the packet back to the pool
+func (p *encodedPacket) release() {
+	releasePacket(p.body)
+}
+
+// envelope writes the packet in its envelope into a pooled buffer, to hand back with releasePacket once written.
+// All fields but the body are names, IDs and numbers, so they need no escaping.
+// Parameters:
+// - game: the ID of the game of the connection, uuid.Nil outside of games
+// - seq: the number of the packet on the connection
+func (p *encodedPacket) envelope(game uuid.UUID, seq uint64) *bytes.Buffer {
+	buf := packetBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	buf.WriteByte(p.id)
+	buf.WriteString(`{"type":"`)
+	buf.WriteString(p.name)
+	buf.WriteString(`","at":`)
+	buf.WriteString(strconv.FormatInt(p.at, 10))
+	if game != uuid.Nil {
+		buf.WriteString(`,"game":"`)
+		buf.WriteString(game.String())
+		buf.WriteByte('"')
+	}
+	buf.WriteString(`,"seq":`)
+	buf.WriteString(strconv.FormatUint(seq, 10))
+	buf.WriteString(`,"data":`)
+	buf.Write(p.body.Bytes())
+	buf.WriteByte('}')
+
+	return buf
+}
+
+// seal puts an encoded packet in the envelope of a connection, numbering it among the packets sent on it.
+// Parameters:
+// - connection: the WebSocket connection the packet is sent to.
+// - packet: the encoded packet.
+// Returns:
+// - *bytes.Buffer: the packet ready to write, to hand back with releasePacket once written.
+func (c *NetService) seal(connection Connection, packet *encodedPacket) *bytes.Buffer {
+	c.outboxesMu.Lock()
+	box := c.outboxOf(connection)
+	box.packets++
+	game, seq := box.game, box.packets
+	c.outboxesMu.Unlock()
+
+	return packet.envelope(game, seq)
+}
+
+// bindConnection records the game a connection takes part in, named in the envelopes of its packets.
+// Parameters:
+// - connection: the WebSocket connection of a host or player.
+// - gameId: the ID of the game.
+func (c *NetService) bindConnection(connection Connection, gameId uuid.UUID) {
+	c.outboxesMu.Lock()
+	defer c.outboxesMu.Unlock()
+
+	c.outboxOf(connection).game = gameId
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// packetEnvelope is the envelope of a packet as clients read it
+type packetEnvelope struct {
+	Type string          `json:"type"`
+	At   int64           `json:"at"`
+	Game string          `json:"game"`
+	Seq  uint64          `json:"seq"`
+	Data json.RawMessage `json:"data"`
+}
+
+// readEnvelope decodes the envelope of a message written to a connection
+func readEnvelope(t *testing.T, message []byte) packetEnvelope {
+	t.Helper()
+
+	var envelope packetEnvelope
+	if err := json.Unmarshal(message[1:], &envelope); err != nil {
+		t.Fatalf("message is not an envelope: %v", err)
+	}
+
+	return envelope
+}
+
+func TestPacketEnvelope(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	clock := newFakeClock()
+	c.clock = clock
+	connection := &fakeConnection{}
+
+	c.SendPacket(connection, TickPacket{Tick: 5})
+	c.bindConnection(connection, uuid.MustParse("9b2f1c3e-8f6a-4d1e-9c57-2a0b1f3d4e5f"))
+	c.SendPacket(connection, ChangeGameStatePacket{State: PlayState})
+
+	first := readEnvelope(t, connection.messages[0])
+	if first.Type != "Tick" || first.At != clock.Now().UnixMilli() || first.Game != "" || first.Seq != 1 {
+		t.Errorf("unexpected envelope %+v", first)
+	}
+
+	var tick TickPacket
+	if err := json.Unmarshal(first.Data, &tick); err != nil || tick.Tick != 5 {
+		t.Errorf("expected the tick in the envelope, got %s", first.Data)
+	}
+
+	// Sequenced packets keep their own sequence number next to the one of the envelope
+	second := readEnvelope(t, connection.messages[1])
+	if second.Type != "ChangeGameState" || second.Game != "9b2f1c3e-8f6a-4d1e-9c57-2a0b1f3d4e5f" || second.Seq != 2 {
+		t.Errorf("unexpected envelope %+v", second)
+	}
+
+	var state ChangeGameStatePacket
+	if err := json.Unmarshal(second.Data, &state); err != nil || state.Seq != 1 {
+		t.Errorf("expected the first sequenced packet, got %s", second.Data)
+	}
+}
+
+func TestBroadcastEnvelopePerPlayer(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	c.clock = newFakeClock()
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+
+	early, late := &fakeConnection{}, &fakeConnection{}
+	game.OnPlayerJoin("Early", primitive.NilObjectID, 0, 0, "", early)
+	game.OnPlayerJoin("Late", primitive.NilObjectID, 0, 0, "", late)
+	c.SendPacket(early, TickPacket{Tick: 1})
+
+	game.broadcast(TickPacket{Tick: 2}, false)
+
+	// Both players get the same packet, each numbered among the packets of their own connection
+	a, b := readEnvelope(t, early.messages[len(early.messages)-1]), readEnvelope(t, late.messages[len(late.messages)-1])
+	if a.Game != game.Id.String() || b.Game != game.Id.String() {
+		t.Errorf("expected envelopes naming game %s, got %q and %q", game.Id, a.Game, b.Game)
+	}
+	if a.Seq != uint64(len(early.messages)) || b.Seq != uint64(len(late.messages)) {
+		t.Errorf("expected packets %d and %d, got %d and %d", len(early.messages), len(late.messages), a.Seq, b.Seq)
+	}
+	if string(a.Data) != string(b.Data) {
+		t.Errorf("expected the same packet, got %s and %s", a.Data, b.Data)
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GamePhase is the core state of a game: where it is in the quiz and how long the current step lasts.
+// It only changes by applying events, as do the answers and points of players, so the log of events replays
+// the phase and scores of a game. Who joined, left or was eliminated, streaks and hints are not logged.
+type GamePhase struct {
+	State           GameState // Current state of the game
+	CurrentQuestion int       // Index of the current question, -1 before the first
+	Time            int       // Time remaining for the current state
+	EndsAt          time.Time // When the timer of the current state runs out, zero when no timer runs
+	Ended           bool      // Indicates if the game has ended
+}
+
+// GameEvent is a change to the phase of a game, see GamePhase.apply, or to the scores of its players, see Player.apply
+type GameEvent interface{}
+
+// Events that change the phase of a game
+type (
+	StateChangedEvent     struct{ State GameState } // The game moved to another state
+	TimerTickedEvent      struct{}                  // A second of the timer passed
+	QuestionAdvancedEvent struct{}                  // The game moved on to the next question
+	QuizChangedEvent      struct{}                  // The next quiz of the playlist starts, before its first question
+	GameEndedEvent        struct{}                  // The game is over
+	PhaseRestoredEvent    struct{ Phase GamePhase } // The phase older events led to, replacing them in a folded log
+)
+
+// TimerSetEvent sets the timer for the current state
+type TimerSetEvent struct {
+	Time   int       // Seconds the state lasts
+	EndsAt time.Time // When the timer runs out
+}
+
+// Events that change the answers and points of players
+type (
+	AnswerRecordedEvent struct { // A player answered the current question, changed their answer or had its points halved
+		PlayerId uuid.UUID    // ID of the player
+		Answer   PlayerAnswer // The answer as it now stands
+	}
+	PointsAwardedEvent struct { // A player won points, or lost them to a penalty or a hint
+		PlayerId uuid.UUID // ID of the player
+		Points   int       // Points won, negative if lost
+	}
+	PointsResetEvent    struct{}                                   // Every player starts from zero points, e.g. in a new round of a match
+	AnswersClearedEvent struct{}                                   // Every player's answers are forgotten as the next quiz of a playlist starts
+	ScoresRestoredEvent struct{ Scores map[uuid.UUID]PlayerScore } // The scores older events led to, replacing them in a folded log
+)
+
+// PlayerScore is what a player scored in a game, as far as events record it
+type PlayerScore struct {
+	Points  int                  // Total points
+	Answers map[int]PlayerAnswer // Answers keyed by question index
+}
+
+// LoggedEvent is an event in the log of a game
+type LoggedEvent struct {
+	At    time.Time // When the event happened
+	Event GameEvent // The event
+}
+
+// newGamePhase returns the phase a game starts in, waiting in the lobby
+func newGamePhase() GamePhase {
+	return GamePhase{
+		State:           LobbyState,
+		CurrentQuestion: -1,
+		Time:            60,
+	}
+}
+
+// apply returns the phase after an event, without side effects
+// Parameters:
+// - event: the event to apply
+// Returns:
+// - GamePhase: the new phase
+func (p GamePhase) apply(event GameEvent) GamePhase {
+	switch e := event.(type) {
+	case StateChangedEvent:
+		p.State = e.State
+	case TimerSetEvent:
+		p.Time = e.Time
+		p.EndsAt = e.EndsAt
+	case TimerTickedEvent:
+		p.Time--
+	case QuestionAdvancedEvent:
+		p.CurrentQuestion++
+	case QuizChangedEvent:
+		p.CurrentQuestion = -1
+	case GameEndedEvent:
+		p.Ended = true
+		p.EndsAt = time.Time{}
+	case PhaseRestoredEvent:
+		p = e.Phase
+	}
+
+	return p
+}
+
+// replayPhase rebuilds the phase of a game from its log
+// Parameters:
+// - events: the log of the game, oldest first
+// Returns:
+// - GamePhase: the phase after all events
+func replayPhase(events []LoggedEvent) GamePhase {
+	phase := newGamePhase()
+	for _, logged := range events {
+		phase = phase.apply(logged.Event)
+	}
+
+	return phase
+}
+
+// apply changes a player's score by an event. Players only score through events, live and when replaying a log.
+// Parameters:
+// - event: the event to apply
+func (p *Player) apply(event GameEvent) {
+	switch e := event.(type) {
+	case AnswerRecordedEvent:
+		p.recordAnswer(e.Answer)
+	case PointsAwardedEvent:
+		p.Points += e.Points
+	case PointsResetEvent:
+		p.Points = 0
+	case AnswersClearedEvent:
+		p.Answers = map[int]*PlayerAnswer{}
+	case ScoresRestoredEvent:
+		score := e.Scores[p.Id]
+		p.Points = score.Points
+		p.Answers = map[int]*PlayerAnswer{}
+		for _, answer := range score.Answers {
+			p.recordAnswer(answer)
+		}
+	}
+}
+
+// score returns what the player scored so far
+// Returns:
+// - PlayerScore: the points and a copy of the answers
+func (p *Player) score() PlayerScore {
+	score := PlayerScore{Points: p.Points, Answers: map[int]PlayerAnswer{}}
+	for index, answer := range p.Answers {
+		score.Answers[index] = *answer
+	}
+
+	return score
+}
+
+// replayScores rebuilds the scores of the players of a game from its log
+// Parameters:
+// - events: the log of the game, oldest first
+// Returns:
+// - map[uuid.UUID]PlayerScore: the score of every player the log mentions, by ID
+func replayScores(events []LoggedEvent) map[uuid.UUID]PlayerScore {
+	players := map[uuid.UUID]*Player{}
+	playerOf := func(id uuid.UUID) *Player {
+		if players[id] == nil {
+			players[id] = &Player{Id: id}
+		}
+		return players[id]
+	}
+
+	for _, logged := range events {
+		switch e := logged.Event.(type) {
+		case AnswerRecordedEvent:
+			playerOf(e.PlayerId).apply(e)
+		case PointsAwardedEvent:
+			playerOf(e.PlayerId).apply(e)
+		case PointsResetEvent, AnswersClearedEvent:
+			for _, player := range players {
+				player.apply(e)
+			}
+		case ScoresRestoredEvent:
+			players = map[uuid.UUID]*Player{}
+			for id := range e.Scores {
+				playerOf(id).apply(e)
+			}
+		}
+	}
+
+	scores := map[uuid.UUID]PlayerScore{}
+	for id, player := range players {
+		scores[id] = player.score()
+	}
+
+	return scores
+}
+
+// apply changes the phase of the game, or the scores of all players, by an event, logs it and tells the clients
+// what they need to know
+// Parameters:
+// - event: the event to apply
+func (g *Game) apply(event GameEvent) {
+	g.GamePhase = g.GamePhase.apply(event)
+	switch event.(type) {
+	case PointsResetEvent, AnswersClearedEvent:
+		for _, player := range g.Players {
+			player.apply(event)
+		}
+	}
+	g.log(event)
+
+	g.publish(event)
+}
+
+// applyToPlayer changes the score of a player by an event and logs it
+// Parameters:
+// - player: the player the event is about
+// - event: the event to apply
+func (g *Game) applyToPlayer(player *Player, event GameEvent) {
+	player.apply(event)
+	g.log(event)
+}
+
+// log appends an applied event to the log of the game
+// Parameters:
+// - event: the event
+func (g *Game) log(event GameEvent) {
+	g.events = append(g.events, LoggedEvent{
+		At:    g.clock.Now(),
+		Event: event,
+	})
+	g.foldEvents()
+}
+
+// publish sends the packets that follow from an event
+// Parameters:
+// - event: the applied event
+func (g *Game) publish(event GameEvent) {
+	switch e := event.(type) {
+	case StateChangedEvent:
+		g.BroadcastPacket(ChangeGameStatePacket{
+			State:  e.State,
+			EndsAt: g.endsAt(),
+		}, true)
+		g.updateViewers()
+	case GameEndedEvent:
+		g.netService.gamesEnded.Add(1)
+	}
+}
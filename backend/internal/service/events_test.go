@@ -0,0 +1,84 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestReplayPhase(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.run("start", game.Start)
+	game.run("answer", func() {
+		game.OnPlayerAnswer(0, game.Players[0])
+		game.Tick()
+		game.NextQuestion()
+	})
+
+	game.run("replay", func() {
+		if replayed := replayPhase(game.events); replayed != game.GamePhase {
+			t.Errorf("replaying the log gave %+v, want %+v", replayed, game.GamePhase)
+		}
+		if game.State != PlayState || game.CurrentQuestion != 1 {
+			t.Errorf("unexpected phase %+v", game.GamePhase)
+		}
+	})
+}
+
+func TestApplyIsPure(t *testing.T) {
+	phase := newGamePhase()
+	next := phase.apply(QuestionAdvancedEvent{}).apply(TimerSetEvent{Time: 20}).apply(TimerTickedEvent{})
+
+	if phase != newGamePhase() {
+		t.Errorf("applying an event changed the original phase: %+v", phase)
+	}
+	if next.CurrentQuestion != 0 || next.Time != 19 {
+		t.Errorf("unexpected phase after events: %+v", next)
+	}
+}
+
+func TestReplayScores(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.run("start", game.Start)
+	game.run("answer", func() {
+		game.OnPlayerAnswer(0, game.Players[0])
+		game.OnPlayerAnswer(1, game.Players[1])
+	})
+
+	game.run("replay", func() {
+		scores := replayScores(game.events)
+		for _, player := range game.Players {
+			if player.Points == 0 && len(player.Answers) == 0 {
+				t.Fatalf("expected %s to have scored", player.Name)
+			}
+			if !reflect.DeepEqual(scores[player.Id], player.score()) {
+				t.Errorf("replaying the log gave %s %+v, want %+v", player.Name, scores[player.Id], player.score())
+			}
+		}
+	})
+}
+
+func TestFoldedScores(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	alice := game.Players[0]
+	game.applyToPlayer(alice, AnswerRecordedEvent{PlayerId: alice.Id, Answer: PlayerAnswer{Question: 0, Correct: true, Points: 900}})
+	for range maxLoggedEvents + 10 {
+		game.applyToPlayer(alice, PointsAwardedEvent{PlayerId: alice.Id, Points: 1})
+	}
+
+	if game.limits.FoldedEvents == 0 {
+		t.Fatal("expected the log to be folded")
+	}
+	if replayed := replayScores(game.events)[alice.Id]; !reflect.DeepEqual(replayed, alice.score()) {
+		t.Errorf("expected the folded log to replay to %+v, got %+v", alice.score(), replayed)
+	}
+}
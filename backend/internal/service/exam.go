@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/google/uuid"
+)
+
+// Limits of exam mode
+const (
+	examIntermission = 5   // Seconds between the questions of an exam
+	maxFocusFlags    = 100 // Most times a player is flagged to the host, later losses are only counted
+)
+
+// FocusLostPacket is sent by a player whose game window lost focus, e.g. to switch to another tab
+type FocusLostPacket struct{}
+
+// PlayerFlagPacket tells the host of an exam that a player left the game window
+type PlayerFlagPacket struct {
+	PlayerId uuid.UUID `json:"playerId"` // ID of the player
+	Question int       `json:"question"` // Index of the question shown when the player left, -1 before the first
+	Count    int       `json:"count"`    // Number of times the player left the window so far
+}
+
+// revealExam scores the answers to the current question of an exam without telling anyone,
+// and moves on to the break before the next question. Points are first shown at the end.
+func (g *Game) revealExam() {
+	for _, player := range g.Players {
+		g.scoreAnswer(player)
+		g.updateStreak(player)
+	}
+	g.streamReveal()
+
+	g.updateLowestRanks()
+	g.checkIdlePlayers()
+	g.Intermission()
+}
+
+// OnPlayerFocusLost flags a player of an exam who left the game window once the exam started.
+// Outside of exams the packet is ignored.
+// Parameters:
+// - player: the player who left the window
+func (g *Game) OnPlayerFocusLost(player *Player) {
+	if !g.Settings.ExamMode || g.State == LobbyState || g.State == EndState {
+		return
+	}
+
+	player.focusLosses++
+	if player.focusLosses > maxFocusFlags {
+		return
+	}
+
+	g.sendToHost(PlayerFlagPacket{
+		PlayerId: player.Id,
+		Question: g.CurrentQuestion,
+		Count:    player.focusLosses,
+	})
+}
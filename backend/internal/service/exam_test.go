@@ -0,0 +1,68 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// Packet IDs checked by the exam tests
+const (
+	leaderboardPacketId = 9
+	playerFlagPacketId  = 38
+)
+
+func TestExamMode(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	game.OnSettings(GameSettings{ExamMode: true})
+
+	player := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", player)
+	alice := game.Players[0]
+	game.Start()
+
+	late := &fakeConnection{}
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", late)
+	if len(game.Players) != 1 || !late.closed {
+		t.Errorf("expected late joins to be turned away, got %d players", len(game.Players))
+	}
+
+	game.OnPlayerAnswer(0, alice)
+	if game.State != IntermissionState {
+		t.Errorf("expected the exam to skip the reveal, got state %d", game.State)
+	}
+	if slices.Contains(player.packetIds(), playerRevealPacketId) {
+		t.Errorf("expected no points to be revealed during the exam")
+	}
+	if slices.Contains(host.packetIds(), leaderboardPacketId) {
+		t.Errorf("expected no leaderboard between questions")
+	}
+	if update := game.newWidgetUpdate(); len(update.Top) != 0 {
+		t.Errorf("expected widgets to show no standings, got %+v", update.Top)
+	}
+
+	game.OnPlayerFocusLost(alice)
+	if !slices.Contains(host.packetIds(), playerFlagPacketId) || alice.focusLosses != 1 {
+		t.Errorf("expected the host to be told alice left the window, got packets %v", host.packetIds())
+	}
+	if report := game.buildReport(); report.Players[0].Flags != 1 {
+		t.Errorf("expected the report to count the flag, got %d", report.Players[0].Flags)
+	}
+}
+
+func TestFocusLostOutsideExam(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.Start()
+
+	game.OnPlayerFocusLost(game.Players[0])
+	if slices.Contains(host.packetIds(), playerFlagPacketId) {
+		t.Errorf("expected players to be flagged only in exams")
+	}
+}
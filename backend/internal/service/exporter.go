@@ -0,0 +1,296 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// Formats quizzes can be exported to
+const (
+	QtiFormat = "qti" // IMS QTI 2.1 content package
+)
+
+// ErrUnknownExportFormat is returned when a quiz is exported to an unsupported format
+var ErrUnknownExportFormat = errors.New("unknown export format")
+
+// Namespaces and templates of QTI 2.1 packages
+const (
+	qtiNamespace         = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiSchemaLocation    = "http://www.imsglobal.org/xsd/imsqti_v2p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"
+	qtiMatchCorrect      = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	imsNamespace         = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	xsiNamespace         = "http://www.w3.org/2001/XMLSchema-instance"
+	qtiTestHref          = "assessment.xml"
+	qtiManifestHref      = "imsmanifest.xml"
+	qtiResponseId        = "RESPONSE"
+	qtiItemResourceType  = "imsqti_item_xmlv2p1"
+	qtiTestResourceType  = "imsqti_test_xmlv2p1"
+	qtiPackageSchemaName = "QTIv2.1 Package"
+)
+
+// ExportQuiz writes a quiz in a format other assessment platforms can import.
+// Parameters:
+// - format: the format to export to, QtiFormat
+// - quiz: the quiz to export
+// Returns:
+// - []byte: the exported file
+// - error: ErrUnknownExportFormat if the format is not supported
+func ExportQuiz(format string, quiz entity.Quiz) ([]byte, error) {
+	switch format {
+	case QtiFormat:
+		return exportQti(quiz)
+	}
+
+	return nil, ErrUnknownExportFormat
+}
+
+type qtiChoice struct {
+	Identifier string `xml:"identifier,attr"`
+	Text       string `xml:",chardata"`
+}
+
+type qtiItem struct {
+	XMLName        xml.Name `xml:"assessmentItem"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	Xsi            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Identifier     string   `xml:"identifier,attr"`
+	Title          string   `xml:"title,attr"`
+	Adaptive       bool     `xml:"adaptive,attr"`
+	TimeDependent  bool     `xml:"timeDependent,attr"`
+
+	Response struct {
+		Identifier  string   `xml:"identifier,attr"`
+		Cardinality string   `xml:"cardinality,attr"`
+		BaseType    string   `xml:"baseType,attr"`
+		Correct     []string `xml:"correctResponse>value,omitempty"`
+	} `xml:"responseDeclaration"`
+
+	Outcome struct {
+		Identifier  string `xml:"identifier,attr"`
+		Cardinality string `xml:"cardinality,attr"`
+		BaseType    string `xml:"baseType,attr"`
+	} `xml:"outcomeDeclaration"`
+
+	Interaction struct {
+		ResponseIdentifier string      `xml:"responseIdentifier,attr"`
+		Shuffle            bool        `xml:"shuffle,attr"`
+		MaxChoices         int         `xml:"maxChoices,attr"`
+		Prompt             string      `xml:"prompt"`
+		Choices            []qtiChoice `xml:"simpleChoice"`
+	} `xml:"itemBody>choiceInteraction"`
+
+	Processing struct {
+		Template string `xml:"template,attr"`
+	} `xml:"responseProcessing"`
+}
+
+type qtiItemRef struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+	TimeLimits struct {
+		MaxTime int `xml:"maxTime,attr"`
+	} `xml:"timeLimits"`
+}
+
+type qtiTest struct {
+	XMLName        xml.Name `xml:"assessmentTest"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	Xsi            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Identifier     string   `xml:"identifier,attr"`
+	Title          string   `xml:"title,attr"`
+
+	Part struct {
+		Identifier     string `xml:"identifier,attr"`
+		NavigationMode string `xml:"navigationMode,attr"`
+		SubmissionMode string `xml:"submissionMode,attr"`
+		Section        struct {
+			Identifier string       `xml:"identifier,attr"`
+			Title      string       `xml:"title,attr"`
+			Visible    bool         `xml:"visible,attr"`
+			Items      []qtiItemRef `xml:"assessmentItemRef"`
+		} `xml:"assessmentSection"`
+	} `xml:"testPart"`
+}
+
+type qtiFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type qtiDependency struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type qtiResource struct {
+	Identifier   string          `xml:"identifier,attr"`
+	Type         string          `xml:"type,attr"`
+	Href         string          `xml:"href,attr"`
+	Files        []qtiFile       `xml:"file"`
+	Dependencies []qtiDependency `xml:"dependency"`
+}
+
+type qtiManifest struct {
+	XMLName       xml.Name      `xml:"manifest"`
+	Xmlns         string        `xml:"xmlns,attr"`
+	Identifier    string        `xml:"identifier,attr"`
+	Schema        string        `xml:"metadata>schema"`
+	SchemaVersion string        `xml:"metadata>schemaversion"`
+	Organizations struct{}      `xml:"organizations"`
+	Resources     []qtiResource `xml:"resources>resource"`
+}
+
+// newQtiItem converts a quiz question into a QTI choice item.
+// Questions with several correct choices become multiple response items.
+// Parameters:
+// - identifier: the identifier of the item within the package
+// - question: the question
+// Returns:
+// - qtiItem: the item
+func newQtiItem(identifier string, question entity.QuizQuestion) qtiItem {
+	item := qtiItem{
+		Xmlns:          qtiNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     identifier,
+		Title:          question.Name,
+	}
+
+	correct := []string{}
+	for i, choice := range question.Choices {
+		choiceId := fmt.Sprintf("choice-%d", i+1)
+		item.Interaction.Choices = append(item.Interaction.Choices, qtiChoice{
+			Identifier: choiceId,
+			Text:       choice.Name,
+		})
+
+		if choice.Correct {
+			correct = append(correct, choiceId)
+		}
+	}
+
+	item.Response.Identifier = qtiResponseId
+	item.Response.Cardinality = "single"
+	item.Response.BaseType = "identifier"
+	item.Response.Correct = correct
+	item.Interaction.MaxChoices = 1
+	if len(correct) > 1 {
+		item.Response.Cardinality = "multiple"
+		item.Interaction.MaxChoices = 0 // No limit
+	}
+
+	item.Outcome.Identifier = "SCORE"
+	item.Outcome.Cardinality = "single"
+	item.Outcome.BaseType = "float"
+	item.Interaction.ResponseIdentifier = qtiResponseId
+	item.Interaction.Prompt = question.Name
+	item.Processing.Template = qtiMatchCorrect
+
+	return item
+}
+
+// exportQti packages a quiz as a QTI 2.1 content package: a manifest, an assessment test and one choice item per question.
+// Question time limits carry over to the test; host notes and media do not.
+// Parameters:
+// - quiz: the quiz to export
+// Returns:
+// - []byte: the zip archive
+// - error: any error encountered while writing the archive
+func exportQti(quiz entity.Quiz) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	archive := zip.NewWriter(&buffer)
+
+	test := qtiTest{
+		Xmlns:          qtiNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     "test-" + quiz.Id.Hex(),
+		Title:          quiz.Name,
+	}
+	test.Part.Identifier = "part-1"
+	test.Part.NavigationMode = "linear"
+	test.Part.SubmissionMode = "individual"
+	test.Part.Section.Identifier = "section-1"
+	test.Part.Section.Title = quiz.Name
+	test.Part.Section.Visible = true
+
+	manifest := qtiManifest{
+		Xmlns:         imsNamespace,
+		Identifier:    "manifest-" + quiz.Id.Hex(),
+		Schema:        qtiPackageSchemaName,
+		SchemaVersion: "1.0.0",
+	}
+	testResource := qtiResource{
+		Identifier: test.Identifier,
+		Type:       qtiTestResourceType,
+		Href:       qtiTestHref,
+		Files:      []qtiFile{{Href: qtiTestHref}},
+	}
+	itemResources := []qtiResource{}
+
+	// Time limits are per item in QTI, so questions without a time of their own carry the quiz's default
+	for i, question := range withDefaultTime(quiz).Questions {
+		identifier := fmt.Sprintf("item-%d", i+1)
+		href := "items/" + identifier + ".xml"
+		if err := writeXmlFile(archive, href, newQtiItem(identifier, question)); err != nil {
+			return nil, err
+		}
+
+		ref := qtiItemRef{
+			Identifier: identifier,
+			Href:       href,
+		}
+		ref.TimeLimits.MaxTime = question.Time
+		test.Part.Section.Items = append(test.Part.Section.Items, ref)
+
+		testResource.Dependencies = append(testResource.Dependencies, qtiDependency{IdentifierRef: identifier})
+		itemResources = append(itemResources, qtiResource{
+			Identifier: identifier,
+			Type:       qtiItemResourceType,
+			Href:       href,
+			Files:      []qtiFile{{Href: href}},
+		})
+	}
+
+	if err := writeXmlFile(archive, qtiTestHref, test); err != nil {
+		return nil, err
+	}
+
+	manifest.Resources = append([]qtiResource{testResource}, itemResources...)
+	if err := writeXmlFile(archive, qtiManifestHref, manifest); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// writeXmlFile adds an XML document to a zip archive
+// Parameters:
+// - archive: the archive to write to
+// - name: the path of the file within the archive
+// - document: the value to encode
+// Returns:
+// - error: any error encountered while encoding or writing the document
+func writeXmlFile(archive *zip.Writer, name string, document any) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(file)
+	encoder.Indent("", "  ")
+	return encoder.Encode(document)
+}
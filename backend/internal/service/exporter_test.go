@@ -0,0 +1,79 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestExportQti(t *testing.T) {
+	quiz := entity.Quiz{
+		Id:   primitive.NewObjectID(),
+		Name: "Capitals & rivers",
+		Questions: []entity.QuizQuestion{
+			{Name: "Capital of France?", Time: 20, HostNotes: "secret", Choices: []entity.QuizChoice{
+				{Name: "Paris", Correct: true},
+				{Name: "<London>"},
+			}},
+			{Name: "Rivers in Germany?", Time: 30, Choices: []entity.QuizChoice{
+				{Name: "Rhine", Correct: true},
+				{Name: "Elbe", Correct: true},
+				{Name: "Thames"},
+			}},
+		},
+	}
+
+	data, err := ExportQuiz(QtiFormat, quiz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		files[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"imsmanifest.xml", "assessment.xml", "items/item-1.xml", "items/item-2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expected %s in the package, got %v", name, files)
+		}
+	}
+
+	var item qtiItem
+	if err := xml.Unmarshal([]byte(files["items/item-2.xml"]), &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.Response.Cardinality != "multiple" || len(item.Response.Correct) != 2 || item.Interaction.MaxChoices != 0 {
+		t.Errorf("expected a multiple response item, got %+v", item.Response)
+	}
+
+	if first := files["items/item-1.xml"]; !strings.Contains(first, "&lt;London&gt;") || strings.Contains(first, "secret") {
+		t.Errorf("expected escaped choices and no host notes, got %s", first)
+	}
+
+	if test := files["assessment.xml"]; !strings.Contains(test, `maxTime="30"`) || !strings.Contains(test, "Capitals &amp; rivers") {
+		t.Errorf("expected time limits and the quiz name in the test, got %s", test)
+	}
+
+	if _, err := ExportQuiz("scorm", quiz); !errors.Is(err, ErrUnknownExportFormat) {
+		t.Errorf("expected ErrUnknownExportFormat, got %v", err)
+	}
+}
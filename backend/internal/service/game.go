@@ -4,23 +4,162 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"sort"
-	"strconv"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/gofiber/contrib/websocket"
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"quiz.com/quiz/internal/entity"
 )
 
 // Player represents a player in the quiz game
 type Player struct {
-	Id                uuid.UUID       `json:"id"`   // Unique identifier for the player
-	Name              string          `json:"name"` // Player's name
-	Connection        *websocket.Conn `json:"-"`    // WebSocket connection for the player (excluded from JSON)
-	Points            int             `json:"-"`    // Player's total points (excluded from JSON)
-	LastAwardedPoints int             `json:"-"`    // Points awarded for the last question (excluded from JSON)
-	Answered          bool            `json:"-"`    // Indicates whether the player has answered the current question (excluded from JSON)
+	Id                uuid.UUID  `json:"id"`     // Unique identifier for the player
+	Name              string     `json:"name"`   // Player's name
+	Avatar            int        `json:"avatar"` // Index of the player's avatar
+	Color             int        `json:"color"`  // Index of the player's color
+	Device            string     `json:"-"`      // Device type reported by the player, empty if unknown
+	Connection        Connection `json:"-"`      // WebSocket connection for the player (excluded from JSON)
+	Points            int        `json:"-"`      // Player's total points (excluded from JSON)
+	LastAwardedPoints int        `json:"-"`      // Points awarded for the last question (excluded from JSON)
+	Answered          bool       `json:"-"`      // Indicates whether the player has answered the current question (excluded from JSON)
+
+	UserId      primitive.ObjectID    `json:"-"` // ID of the authenticated user, zero for anonymous players
+	Rtt         time.Duration         `json:"-"` // Smoothed round-trip time measured with ping packets
+	ClockOffset int64                 `json:"-"` // Difference between the player's clock and the server clock in milliseconds
+	Answers     map[int]*PlayerAnswer `json:"-"` // Answers given by the player, keyed by question index
+	Hints       map[int]int           `json:"-"` // Points paid for the hints the player bought, keyed by question index
+	rttSamples  int                   // Number of round-trip measurements taken
+	pings       []int64               // Server times in milliseconds of the pings sent to the player and not answered yet
+
+	Streak        int `json:"-"` // Number of consecutive correct answers
+	LongestStreak int `json:"-"` // Longest run of consecutive correct answers in the game
+	LowestRank    int `json:"-"` // Worst leaderboard position held after any reveal (1 is first)
+	RoundWins     int `json:"-"` // Rounds of a match the player won
+
+	Idle            bool `json:"-"` // Whether the player missed as many questions in a row as the idle limit allows
+	Eliminated      bool `json:"-"` // Whether the player was knocked out of an elimination game and only watches
+	eliminatedIn    int  // Number of the elimination the player was knocked out in, counted from 1
+	missedQuestions int  // Number of consecutive questions the player did not answer
+	focusLosses     int  // Number of times the player left the game window during an exam
+
+	paced *pacedProgress // Where the player is in a player-paced game, nil in live games and before the start
+
+	session string // Token the player resumes their place in the game with, empty if resuming is disabled
+
+	lastLobbyVote   time.Time // When the player last voted in the lobby emoji vote
+	quizStartPoints int       // Points the player had when the current quiz of the playlist started
+}
+
+// PlayerAnswer records a player's answer to a single question
+type PlayerAnswer struct {
+	Question int           // Index of the answered question
+	Choice   int           // Index of the chosen answer
+	Correct  bool          // Whether the chosen answer was correct
+	Elapsed  time.Duration // Time the player took to answer, compensated for latency
+	Points   int           // Points awarded for the answer, counted once the question is revealed
+	History  []int         // Choices the player changed away from, oldest first
+
+	Confidence Confidence // How sure the player was of the answer, weighing its points in confidence mode
+}
+
+// recordAnswer stores the player's answer to a question
+// Parameters:
+// - answer: the answer to record
+func (p *Player) recordAnswer(answer PlayerAnswer) {
+	if p.Answers == nil {
+		p.Answers = map[int]*PlayerAnswer{}
+	}
+
+	p.Answers[answer.Question] = &answer
+}
+
+// leaderboardEntry returns the player's line on a leaderboard
+// Returns:
+// - LeaderboardEntry: the player's name, points, avatar, color and round wins
+func (p *Player) leaderboardEntry() LeaderboardEntry {
+	return LeaderboardEntry{
+		Name:   p.Name,
+		Points: p.Points,
+		Avatar: p.Avatar,
+		Color:  p.Color,
+		Wins:   p.RoundWins,
+	}
+}
+
+// AverageAnswerTime returns the average number of seconds the player took to answer
+// Returns:
+// - float64: the average in seconds rounded to one decimal, or 0 if nothing was answered
+func (p *Player) AverageAnswerTime() float64 {
+	if len(p.Answers) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, answer := range p.Answers {
+		total += answer.Elapsed
+	}
+
+	return roundSeconds(total / time.Duration(len(p.Answers)))
+}
+
+// roundSeconds converts a duration to seconds rounded to one decimal
+func roundSeconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*10) / 10
+}
+
+// Limits of measuring and compensating the latency of players
+const (
+	pingInterval    = 5                      // Number of ticks between latency measurements of each player
+	maxPendingPings = 4                      // Most unanswered pings remembered per player, older ones are forgotten
+	maxLatency      = 300 * time.Millisecond // Most time taken off an answer to make up for the player's latency
+)
+
+// recordPong updates the player's latency estimate from an answered ping
+// Parameters:
+// - sentAt: server time in milliseconds at which the ping was sent
+// - clientTime: the player's clock in milliseconds when it answered the ping
+// - now: server time in milliseconds at which the pong was received
+func (p *Player) recordPong(sentAt int64, clientTime int64, now int64) {
+	rtt := time.Duration(now-sentAt) * time.Millisecond
+	offset := clientTime - (sentAt + (now-sentAt)/2)
+
+	// Smooth out jitter with an exponentially weighted moving average
+	if p.rttSamples == 0 {
+		p.Rtt = rtt
+		p.ClockOffset = offset
+	} else {
+		p.Rtt = (p.Rtt*4 + rtt) / 5
+		p.ClockOffset = (p.ClockOffset*4 + offset) / 5
+	}
+
+	p.rttSamples++
+}
+
+// latency returns the time to take off the player's answers for the answer travelling to the server.
+// Half the round trip is taken, capped so a player faking a slow connection gains little.
+// Returns:
+// - time.Duration: the time to take off, at most maxLatency
+func (p *Player) latency() time.Duration {
+	return min(p.Rtt/2, maxLatency)
+}
+
+// sendPing sends a ping to a player, remembering it so only pongs to pings the server sent are measured
+// Parameters:
+// - player: the player to ping
+func (g *Game) sendPing(player *Player) {
+	now := g.clock.Now().UnixMilli()
+	player.pings = append(player.pings, now)
+	if len(player.pings) > maxPendingPings {
+		player.pings = player.pings[len(player.pings)-maxPendingPings:]
+	}
+
+	g.netService.SendPacket(player.Connection, PingPacket{
+		ServerTime: now,
+	})
 }
 
 // GameState represents the different states a game can be in
@@ -32,32 +171,79 @@ const (
 	IntermissionState                  // A break between questions
 	RevealState                        // Revealing the correct answer
 	EndState                           // Game has ended
+	TieBreakerState                    // The players tied for first place answer the tie-breaker question
 )
 
 // LeaderboardEntry represents a player's position on the leaderboard
 type LeaderboardEntry struct {
 	Name   string `json:"name"`   // Player's name
 	Points int    `json:"points"` // Player's points
+	Avatar int    `json:"avatar"` // Index of the player's avatar
+	Color  int    `json:"color"`  // Index of the player's color
+	Wins   int    `json:"wins"`   // Rounds of a match the player won, 0 outside matches
 }
 
+// emptyGameGracePeriod is the number of seconds a started game may run without
+// any players before it is ended automatically
+const emptyGameGracePeriod = 30
+
 // Game represents the state of an active quiz game
 type Game struct {
-	Id              uuid.UUID   // Unique identifier for the game
-	Quiz            entity.Quiz // The quiz being played
-	CurrentQuestion int         // Index of the current question
-	Code            string      // Code for players to join the game
-	State           GameState   // Current state of the game
-	Ended           bool        // Indicates if the game has ended
-	Time            int         // Time remaining for the current question
-	Players         []*Player   // List of players in the game
+	Id        uuid.UUID    // Unique identifier for the game
+	Quiz      entity.Quiz  // The quiz being played
+	GamePhase              // State, question and timer of the game, changed only through apply
+	Code      string       // Code for players to join the game
+	Players   []*Player    // List of players in the game
+	Paused    bool         // Indicates if the timer is paused because no players are connected
+	Settings  GameSettings // Rules the host chose for the game
 
-	Host       *websocket.Conn // WebSocket connection for the host
-	netService *NetService     // Network service for handling WebSocket communication
-}
+	events            []LoggedEvent // Every change to the phase of the game, oldest first
+	questionStartedAt time.Time     // When the current question was shown
+	previousTop       []uuid.UUID   // Top players after the previous reveal, used to detect comebacks
+	record            int           // Best score ever reached on the quiz before this game, -1 if unknown
+	recordHolder      uuid.UUID     // Player who most recently beat the record in this game
 
-// generateCode generates a random 6-digit code for players to join the game
-func generateCode() string {
-	return strconv.Itoa(100000 + rand.Intn(900000))
+	Playlist        []entity.Quiz // Quizzes played one after another, the first is the hosted quiz; empty for a single quiz
+	PlaylistIndex   int           // Index of the current quiz in the playlist
+	resetPoints     bool          // Whether points start from zero with each quiz of the playlist
+	playlistRecords []int         // Best score ever reached on each quiz of the playlist, -1 if unknown
+
+	tournament   *entity.Tournament          // Tournament the game is played for, nil for a standalone game
+	round        int                         // Index of the tournament round the game is played as
+	allowedUsers map[primitive.ObjectID]bool // Accounts of the players still in a bracket tournament, nil if anyone may join
+	challengeId  primitive.ObjectID          // Scheduled challenge the game was hosted for, zero if none
+	maxPlayers   int                         // Most players who may join, set by the host's plan; zero for no limit
+	bans         gameBans                    // Players the host banned from the game
+
+	departed map[string]departedPlayer // Players who lost their connection and may still resume, by session token
+	losses   connectionLosses          // Players who lost their connection during recent questions, see recordConnectionLoss
+	mediaCue *MediaCuePacket           // Last playback cue of the host for the media of the current question, nil if none
+	buzzes   []Buzz                    // Players who buzzed for the current question in a buzzer game, fastest first
+	tieBreak *tieBreak                 // Tie-breaker question played for first place at the end, nil if none was needed
+
+	roundOpen    bool // Whether a question of the current round of a match was shown and the round was not closed yet
+	roundsPlayed int  // Rounds of a match finished so far
+	eliminations int  // Times players were knocked out of an elimination game so far
+
+	lobbyVotes        map[uuid.UUID]int // Emoji each player voted for while waiting in the lobby
+	lobbyVotesPending bool              // Whether a change of the lobby votes was not broadcast because the game was over its budget
+	lobbyStats        lobbyStats        // Join rate of the players, reported to the host while waiting in the lobby
+	viewers           []Connection      // Embedded leaderboard widgets watching the game
+
+	Host       Connection         // WebSocket connection for the host, nil for headless games the server runs on its own
+	hostUserId primitive.ObjectID // ID of the user who hosted the game, zero if they were not signed in
+	headless   bool               // Whether the server runs the game on autopilot, without a host
+	netService *NetService        // Network service for handling WebSocket communication
+	clock      Clock              // Source of time of the game, taken from the network service
+	rand       *rand.Rand         // Random source of the game, seeded from the network service
+	emptyTicks int                // Number of consecutive ticks without any players
+
+	lastActivity atomic.Int64 // When a client last sent a packet for the game, in Unix nanoseconds, see hibernateIdleGames
+
+	fanOut         fanOutBudget // Packets broadcast in the current second, see GameFanOutLimit
+	limits         GameLimits   // What the game gave up to stay within the limits of the server
+	sampledAnswers int          // Answers considered for the analytics stream since the game was throttled
+	mu             sync.Mutex   // Serializes event processing for the game
 }
 
 // newGame creates a new game instance
@@ -66,48 +252,116 @@ func generateCode() string {
 // - host: WebSocket connection for the host
 // - netService: network service for WebSocket communication
 // Returns:
-// - A new Game instance
-func newGame(quiz entity.Quiz, host *websocket.Conn, netService *NetService) Game {
-	return Game{
-		Id:              uuid.New(),
-		Quiz:            quiz,
-		Code:            generateCode(),
-		Players:         []*Player{},
-		State:           LobbyState,
-		CurrentQuestion: -1,
-		Time:            60,
-		Host:            host,
-		netService:      netService,
+// - A pointer to a new Game instance
+func newGame(quiz entity.Quiz, host Connection, netService *NetService) *Game {
+	game := &Game{
+		Id:         uuid.New(),
+		Quiz:       withDefaultTime(quiz),
+		Code:       netService.generateCode(),
+		Players:    []*Player{},
+		GamePhase:  newGamePhase(),
+		record:     -1,
+		lobbyVotes: map[uuid.UUID]int{},
+		Host:       host,
+		netService: netService,
+		clock:      netService.clock,
+		rand:       netService.newGameRand(),
+	}
+	game.touch()
+	if host != nil {
+		netService.bindConnection(host, game.Id)
 	}
+
+	return game
+}
+
+// run processes a single game event, serialized with all other events of the game.
+// A panic while processing the event is logged with the game context and ends
+// only the affected game, keeping the rest of the server running.
+// Parameters:
+// - event: name of the event, used in the log
+// - fn: the function processing the event
+func (g *Game) run(event string, fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("panic in game %s (id %s, state %d, question %d) during %s: %v\n%s",
+				g.Code, g.Id, g.State, g.CurrentQuestion, event, r, debug.Stack())
+			g.abort()
+		}
+	}()
+
+	fn()
+}
+
+// abort ends the game after an unrecoverable error and frees its join code
+func (g *Game) abort() {
+	g.apply(GameEndedEvent{})
+	g.netService.removeGame(g)
+
+	// Notifying clients may fail again in the same way, which must not escape the recovery
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println("failed to notify clients of aborted game", g.Code, ":", r)
+		}
+	}()
+
+	g.ChangeState(EndState)
 }
 
 // StartOrSkip starts the game if in the lobby state, or skips to the next question
 func (g *Game) StartOrSkip() {
 	if g.State == LobbyState {
+		g.recordHostAction(StartHostAction, nil, "")
 		g.Start()
+	} else if g.State == TieBreakerState {
+		g.recordHostAction(SkipHostAction, nil, "")
+		g.endTieBreaker()
 	} else {
+		g.recordHostAction(SkipHostAction, nil, "")
 		g.NextQuestion()
 	}
 }
 
 // Start begins the game and starts the question timer
 func (g *Game) Start() {
+	g.stopLobby()
 	g.ChangeState(PlayState)
-	g.NextQuestion()
+	if g.Settings.PlayerPaced {
+		g.startPaced()
+	} else {
+		g.NextQuestion()
+	}
 
-	// Start the game timer
+	// Start the game timer, a second after the first question was shown
 	go func() {
 		for {
-			if g.Ended {
+			g.clock.Sleep(time.Second)
+
+			ended := false
+			g.run("tick", func() {
+				ended = g.Ended
+				if !ended {
+					g.Tick()
+				}
+			})
+
+			if ended {
 				return
 			}
-
-			g.Tick()
-			time.Sleep(time.Second)
 		}
 	}()
 }
 
+// closeLobby ends a game that never started, without a report or result
+func (g *Game) closeLobby() {
+	g.apply(GameEndedEvent{})
+	g.ChangeState(EndState)
+	g.netService.releaseTournamentRound(g)
+}
+
 // ResetPlayerAnswerStates resets the answered state for all players
 func (g *Game) ResetPlayerAnswerStates() {
 	for _, player := range g.Players {
@@ -117,95 +371,354 @@ func (g *Game) ResetPlayerAnswerStates() {
 
 // End ends the game and changes the state to EndState
 func (g *Game) End() {
-	g.Ended = true
+	g.apply(GameEndedEvent{})
 	g.ChangeState(EndState)
+
+	// Give the host a summary of how everyone performed
+	report := g.buildReport()
+	g.sendToHost(report)
+
+	// Show the podium and awards on every screen, and every player their own grade
+	awards := g.getAwards()
+	podium := g.getLeaderboard()
+	for _, player := range g.Players {
+		g.netService.SendPacket(player.Connection, GameEndPacket{
+			Podium: podium,
+			Awards: awards,
+			Grade:  gradePoints(g.Quiz.Grades, player.quizPoints(), report.MaxPoints),
+		})
+	}
+	g.sendToHost(GameEndPacket{
+		Podium: podium,
+		Awards: awards,
+	})
+	g.netService.announcePodium(g, g.anonymizePodium(podium))
+
+	g.netService.saveResult(g, g.buildResult(report, awards))
 }
 
 // NextQuestion advances to the next question in the quiz
 func (g *Game) NextQuestion() {
-	g.CurrentQuestion++
+	g.closeRound()
+	g.mediaCue = nil
+	g.buzzes = nil
 
-	// If there are no more questions, end the game
-	if g.CurrentQuestion >= len(g.Quiz.Questions) {
+	// Elimination games are over once a single player is left standing
+	if g.lastOneStanding() {
 		g.End()
 		return
 	}
 
-	// Reset player answer states and change to PlayState
+	g.apply(QuestionAdvancedEvent{})
+
+	// If there are no more questions, move on to the next quiz of the playlist, break a tie for first place or end the game
+	if g.CurrentQuestion >= len(g.Quiz.Questions) {
+		if g.hasNextQuiz() {
+			g.NextQuiz()
+		} else if !g.startTieBreaker() {
+			g.End()
+		}
+		return
+	}
+
+	// Reset player answer states and change to PlayState, with the timer set so clients can count down
+	currentQuestion := g.getCurrentQuestion()
 	g.ResetPlayerAnswerStates()
+	g.setTimer(currentQuestion.Time)
 	g.ChangeState(PlayState)
+	g.roundOpen = true
 
-	currentQuestion := g.getCurrentQuestion()
-	g.Time = currentQuestion.Time
+	g.questionStartedAt = g.clock.Now()
+	g.BroadcastPacket(g.newGameInfoPacket(), false)
 
 	// Notify the host to show the current question
-	g.netService.SendPacket(g.Host, QuestionShowPacket{
-		Question: currentQuestion,
+	g.sendToHost(QuestionShowPacket{
+		Question:   currentQuestion,
+		ServerTime: g.questionStartedAt.UnixMilli(),
+		EndsAt:     g.endsAt(),
+		Index:      g.CurrentQuestion,
+		Total:      len(g.Quiz.Questions),
+		Slots:      getChoiceSlots(len(currentQuestion.Choices)),
 	})
+
+	// Tell each player how long they have
+	for _, player := range g.Players {
+		g.netService.SendPacket(player.Connection, g.newPlayerQuestionPacket(player))
+	}
+}
+
+// newPlayerQuestionPacket describes the current question to a player, with their measured clock offset so
+// their countdown can be aligned to the server
+// Parameters:
+// - player: the player to send the question to
+// Returns:
+// - PlayerQuestionPacket: the packet
+func (g *Game) newPlayerQuestionPacket(player *Player) PlayerQuestionPacket {
+	currentQuestion := g.getCurrentQuestion()
+	return PlayerQuestionPacket{
+		Choices:     len(currentQuestion.Choices),
+		Time:        currentQuestion.Time,
+		ServerTime:  g.questionStartedAt.UnixMilli(),
+		EndsAt:      g.endsAt(),
+		ClockOffset: player.ClockOffset,
+		Media:       sizeMedia(currentQuestion.Media, entity.MediumSize),
+		Index:       g.CurrentQuestion,
+		Total:       len(g.Quiz.Questions),
+		Slots:       getChoiceSlots(len(currentQuestion.Choices)),
+		Hint:        currentQuestion.Hint != "",
+	}
 }
 
 // Reveal reveals the correct answer and awards points to players
 func (g *Game) Reveal() {
-	g.Time = 5
+	if g.Settings.ExamMode {
+		g.revealExam()
+		return
+	}
+
+	g.setTimer(5)
 
 	for _, player := range g.Players {
-		if !player.Answered {
-			player.LastAwardedPoints = 0
-		}
+		g.scoreAnswer(player)
+		g.updateStreak(player)
 
 		// Notify each player of their awarded points
 		g.netService.SendPacket(player.Connection, PlayerRevealPacket{
-			Points: player.LastAwardedPoints,
+			Points:      player.LastAwardedPoints,
+			AverageTime: player.AverageAnswerTime(),
+			EndsAt:      g.endsAt(),
 		})
 	}
+	g.streamReveal()
 
 	// Change the state to RevealState
 	g.ChangeState(RevealState)
+
+	// Let the host celebrate notable outcomes of the round
+	for _, event := range g.getRoundEvents() {
+		g.sendToHost(event)
+	}
+
+	g.updateLowestRanks()
+	g.checkRecord()
+	g.checkIdlePlayers()
+	g.eliminatePlayers()
+}
+
+// updateStreak counts the consecutive correct answers of a player after a question
+// Parameters:
+// - player: the player whose answer was scored
+func (g *Game) updateStreak(player *Player) {
+	if answer, ok := player.Answers[g.CurrentQuestion]; ok && answer.Correct {
+		player.Streak++
+		player.LongestStreak = max(player.LongestStreak, player.Streak)
+	} else {
+		player.Streak = 0
+	}
+}
+
+// checkRecord announces when the leading player beats the quiz's all-time record
+func (g *Game) checkRecord() {
+	if g.record < 0 || len(g.Players) == 0 {
+		return
+	}
+
+	// Records are per quiz, so only the points of the current quiz of a playlist count
+	leader := g.getPlayerById(g.getTopPlayerIds()[0])
+	for _, player := range g.Players {
+		if player.quizPoints() > leader.quizPoints() {
+			leader = player
+		}
+	}
+
+	if leader.quizPoints() <= g.record || leader.Id == g.recordHolder {
+		return
+	}
+
+	g.broadcastOptional(RecordPacket{
+		Name:           leader.Name,
+		Points:         leader.quizPoints(),
+		PreviousRecord: g.record,
+	}, true)
+
+	g.record = leader.quizPoints()
+	g.recordHolder = leader.Id
 }
 
 // Tick handles the game timer, updating the time and advancing the game state as needed
 func (g *Game) Tick() {
-	g.Time--
-	g.netService.SendPacket(g.Host, TickPacket{
-		Tick: g.Time,
-	})
+	resumed := g.Paused
+	if g.checkEmpty() {
+		return
+	}
+	g.flushLobbyVotes()
+
+	// Players of player-paced games each have their own timer
+	if g.Settings.PlayerPaced {
+		g.tickPaced()
+		return
+	}
+
+	// The timer rests at zero until the grace window closes
+	if g.inGrace() {
+		return
+	}
+
+	// Pings and digests only help clients recover, so they are the first to thin out in a throttled game
+	if g.Time%g.interval(pingInterval) == 0 && g.canFanOut(len(g.Players)) {
+		g.pingPlayers()
+	}
+	if g.Time%g.interval(digestInterval) == 0 {
+		g.broadcastOptional(g.newStateDigest(), true)
+	}
+
+	g.apply(TimerTickedEvent{})
+	if resumed {
+		// The timer stood still while the game was paused, so it runs out later than clients were told
+		g.setTimer(g.Time)
+	}
+	if g.Time%tickInterval == 0 {
+		g.sendToHost(TickPacket{
+			Tick:   g.Time,
+			EndsAt: g.endsAt(),
+		})
+	}
 
 	// When time runs out, change the game state accordingly
 	if g.Time == 0 {
 		switch g.State {
 		case PlayState:
-			g.Reveal()
+			g.timeUp()
 		case RevealState:
 			g.Intermission()
 		case IntermissionState:
 			g.NextQuestion()
+		case TieBreakerState:
+			g.endTieBreaker()
 		}
 	}
 }
 
+// pingPlayers sends a ping to every player to measure their latency
+func (g *Game) pingPlayers() {
+	for _, player := range g.Players {
+		g.sendPing(player)
+	}
+}
+
+// OnPlayerPong handles a player's answer to a ping
+// Parameters:
+// - player: the player who answered
+// - packet: the pong packet echoing the ping's server time
+func (g *Game) OnPlayerPong(player *Player, packet *PongPacket) {
+	now := g.clock.Now().UnixMilli()
+
+	// Ignore pongs for pings that were never sent, were answered already or are too old to be meaningful
+	index := slices.Index(player.pings, packet.ServerTime)
+	if index < 0 {
+		return
+	}
+
+	player.pings = slices.Delete(player.pings, index, index+1)
+	if packet.ServerTime > now || now-packet.ServerTime > maxPingAge {
+		return
+	}
+
+	player.recordPong(packet.ServerTime, packet.ClientTime, now)
+}
+
+// checkEmpty pauses the game while it has no players and ends it once the grace period is over
+// Returns:
+// - bool: true if the game is paused or was ended and the tick should be skipped
+func (g *Game) checkEmpty() bool {
+	if len(g.Players) > 0 {
+		g.emptyTicks = 0
+		if g.Paused {
+			g.setPaused(false, "")
+		}
+
+		return false
+	}
+
+	if !g.Paused {
+		g.setPaused(true, "All players have left the game")
+	}
+
+	g.emptyTicks++
+	if g.emptyTicks >= emptyGameGracePeriod {
+		fmt.Println("game", g.Code, "ended after", g.emptyTicks, "seconds without players")
+		g.End()
+		g.netService.removeGame(g)
+	}
+
+	return true
+}
+
+// setPaused changes whether the game timer is paused and notifies the host
+// Parameters:
+// - paused: whether the timer should be paused
+// - reason: the reason shown to the host
+func (g *Game) setPaused(paused bool, reason string) {
+	g.Paused = paused
+	g.sendToHost(GamePausePacket{
+		Paused: paused,
+		Reason: reason,
+	})
+}
+
 // Intermission starts a break between questions and shows the leaderboard
 func (g *Game) Intermission() {
-	g.Time = 30
-	g.ChangeState(IntermissionState)
-	g.netService.SendPacket(g.Host, LeaderboardPacket{
-		Points: g.getLeaderboard(),
+	// Exams show no standings until the end, so the break is only a short pause
+	if g.Settings.ExamMode {
+		g.setTimer(examIntermission)
+		g.ChangeState(IntermissionState)
+	} else {
+		g.setTimer(30)
+		g.ChangeState(IntermissionState)
+
+		// The match standings take the place of the leaderboard after the last question of a round
+		if !g.closeRound() {
+			g.sendToHost(LeaderboardPacket{
+				Points: g.getLeaderboard(),
+			})
+		}
+	}
+
+	g.sendNextQuestionPreview()
+	g.broadcastPreload(g.CurrentQuestion + 1)
+}
+
+// sendNextQuestionPreview shows the host the upcoming question and its answers so
+// they can prepare commentary. It contains the answers, so it must never reach players.
+func (g *Game) sendNextQuestionPreview() {
+	next := g.CurrentQuestion + 1
+	if next >= len(g.Quiz.Questions) {
+		return
+	}
+
+	question := g.Quiz.Questions[next]
+	correct := []int{}
+	for i, choice := range question.Choices {
+		if choice.Correct {
+			correct = append(correct, i)
+		}
+	}
+
+	g.sendToHost(NextQuestionPreviewPacket{
+		Index:          next,
+		Question:       question,
+		CorrectChoices: correct,
 	})
 }
 
 // getLeaderboard returns the top 3 players sorted by points
 func (g *Game) getLeaderboard() []LeaderboardEntry {
-	// Sort players by points in descending order
-	sort.Slice(g.Players, func(i, j int) bool {
-		return g.Players[i].Points > g.Players[j].Points
-	})
+	// Sort players by their rank, best first
+	g.sortPlayers(g.Players)
 
 	leaderboard := []LeaderboardEntry{}
 	for i := 0; i < int(math.Min(3, float64(len(g.Players)))); i++ {
-		player := g.Players[i]
-		leaderboard = append(leaderboard, LeaderboardEntry{
-			Name:   player.Name,
-			Points: player.Points,
-		})
+		leaderboard = append(leaderboard, g.Players[i].leaderboardEntry())
 	}
 
 	return leaderboard
@@ -215,30 +728,57 @@ func (g *Game) getLeaderboard() []LeaderboardEntry {
 // Parameters:
 // - state: the new state to change to
 func (g *Game) ChangeState(state GameState) {
-	g.State = state
-	g.BroadcastPacket(ChangeGameStatePacket{
-		State: state,
-	}, true)
+	g.apply(StateChangedEvent{State: state})
 }
 
-// BroadcastPacket sends a packet to all players, optionally including the host
+// BroadcastPacket sends a packet to all players, optionally including the host, counting it against the budget of the game
 // Parameters:
 // - packet: the packet to send
 // - includeHost: whether to include the host in the broadcast
 // Returns:
 // - error: any error encountered during the broadcast, or nil if successful
 func (g *Game) BroadcastPacket(packet any, includeHost bool) error {
-	// Send the packet to each player
-	for _, player := range g.Players {
-		err := g.netService.SendPacket(player.Connection, packet)
+	g.spendFanOut(len(g.Players))
+	return g.broadcast(packet, includeHost)
+}
+
+// broadcast sends a packet to all players without counting it against the budget of the game.
+// A player whose connection fails does not keep the packet from the others.
+// Parameters:
+// - packet: the packet to send
+// - includeHost: whether to include the host in the broadcast
+// Returns:
+// - error: the first error encountered while sending, or nil if successful
+func (g *Game) broadcast(packet any, includeHost bool) error {
+	var errs []error
+
+	// Sequenced packets and pings carry numbers of their own per connection, every other packet is encoded
+	// once and only sealed in the envelope of each connection
+	switch packet.(type) {
+	case sequenced, PingPacket:
+		for _, player := range g.Players {
+			errs = append(errs, g.netService.SendPacket(player.Connection, packet))
+		}
+	default:
+		encoded, err := g.netService.encodePacket(packet)
 		if err != nil {
 			return err
 		}
+		defer encoded.release()
+
+		for _, player := range g.Players {
+			buf := g.netService.seal(player.Connection, encoded)
+			errs = append(errs, g.netService.writePacket(player.Connection, buf.Bytes()))
+			releasePacket(buf)
+		}
 	}
 
 	// Optionally include the host
 	if includeHost {
-		err := g.netService.SendPacket(g.Host, packet)
+		errs = append(errs, g.sendToHost(packet))
+	}
+
+	for _, err := range errs {
 		if err != nil {
 			return err
 		}
@@ -247,29 +787,110 @@ func (g *Game) BroadcastPacket(packet any, includeHost bool) error {
 	return nil
 }
 
+// sendToHost sends a packet to the host, if the game has one
+// Parameters:
+// - packet: the packet to send
+// Returns:
+// - error: any error encountered while sending, or nil if successful or the game is headless
+func (g *Game) sendToHost(packet any) error {
+	if g.Host == nil {
+		return nil
+	}
+
+	return g.netService.SendPacket(g.Host, packet)
+}
+
 // OnPlayerJoin handles a new player joining the game
 // Parameters:
 // - name: the name of the player
+// - userId: the ID of the authenticated user, or primitive.NilObjectID for anonymous players
+// - avatar: the index of the avatar the player picked
+// - color: the index of the color the player picked
+// - device: the device type reported by the player, empty if unknown
 // - connection: WebSocket connection for the player
-func (g *Game) OnPlayerJoin(name string, connection *websocket.Conn) {
+func (g *Game) OnPlayerJoin(name string, userId primitive.ObjectID, avatar int, color int, device string, connection Connection) {
+	if g.isBanned(name, userId, connection) {
+		fmt.Println(name, "is banned from the game")
+		g.netService.CloseConnection(connection, CloseBanned)
+		return
+	}
+
+	// Players knocked out of a bracket tournament cannot enter later rounds, whatever name they pick
+	if g.allowedUsers != nil && !g.allowedUsers[userId] {
+		fmt.Println(name, "is not in the tournament round")
+		g.netService.CloseConnection(connection, CloseNotAllowed)
+		return
+	}
+
+	if g.Settings.ExamMode && g.State != LobbyState {
+		fmt.Println(name, "cannot join the exam after it started")
+		g.netService.CloseConnection(connection, CloseExamStarted)
+		return
+	}
+
+	// Exam-like games only let in devices on the allowed networks or in the allowed countries
+	if !g.canJoinFrom(connection) {
+		fmt.Println(name, "is not allowed to join from their address")
+		g.netService.CloseConnection(connection, CloseNotAllowed)
+		return
+	}
+
+	if overLimit(g.maxPlayers, len(g.Players)) {
+		fmt.Println(name, "cannot join, the game is full")
+		g.netService.CloseConnection(connection, CloseGameFull)
+		return
+	}
+
 	fmt.Println(name, "joined the game")
 
 	player := Player{
 		Id:         uuid.New(),
 		Name:       name,
+		Avatar:     avatar,
+		Color:      color,
+		Device:     device,
+		UserId:     userId,
 		Connection: connection,
 	}
 	g.Players = append(g.Players, &player)
+	g.netService.bindConnection(connection, g.Id)
+	g.lobbyStats.recordJoin(g.clock.Now())
 
 	// Notify the player of the current game state
 	g.netService.SendPacket(connection, ChangeGameStatePacket{
-		State: g.State,
+		State:  g.State,
+		EndsAt: g.endsAt(),
+	})
+
+	// Tell the player the quiz and the rules of the game, e.g. whether they may change their answer
+	g.netService.SendPacket(connection, GameSettingsPacket{
+		Settings: g.Settings,
 	})
+	g.netService.SendPacket(connection, g.newGameInfoPacket())
+	g.sendSession(&player)
+
+	// Players joining during a question can still answer it, those joining a player-paced game start from the beginning
+	if g.State == PlayState && g.Settings.PlayerPaced {
+		g.startPlayerPaced(&player)
+	} else if g.State == PlayState {
+		g.netService.SendPacket(connection, g.newPlayerQuestionPacket(&player))
+		g.sendMediaCue(&player)
+	}
+
+	// Let the player join in on the lobby vote, and fetch the media of the first question while waiting
+	if g.State == LobbyState {
+		g.netService.SendPacket(connection, g.getLobbyVotes())
+		g.sendPreload(connection, 0, entity.MediumSize)
+	}
+
+	// Take a first latency measurement right away
+	g.sendPing(&player)
 
 	// Notify the host of the new player
-	g.netService.SendPacket(g.Host, PlayerJoinPacket{
+	g.sendToHost(PlayerJoinPacket{
 		Player: player,
 	})
+	g.updateViewers()
 }
 
 // OnPlayerDisconnect handles a player disconnecting from the game
@@ -287,11 +908,33 @@ func (g *Game) OnPlayerDisconnect(player *Player) {
 
 	fmt.Println(player.Name, "left the game")
 	g.Players = filter
+	g.removeLobbyVote(player.Id)
 
-	// Notify the host that the player disconnected
-	g.netService.SendPacket(g.Host, PlayerDisconnectPacket{
-		PlayerId: player.Id,
+	// Notify the host that the player disconnected, and whether they may come back
+	reconnectable := g.isReconnectable(player)
+	g.sendToHost(PlayerDisconnectPacket{
+		PlayerId:      player.Id,
+		Reconnectable: reconnectable,
 	})
+	if reconnectable {
+		g.recordConnectionLoss()
+	}
+	g.updateViewers()
+}
+
+// getPlayerById returns the player with the given ID
+// Parameters:
+// - id: the ID of the player
+// Returns:
+// - *Player: the player, or nil if they are not in the game
+func (g *Game) getPlayerById(id uuid.UUID) *Player {
+	for _, player := range g.Players {
+		if player.Id == id {
+			return player
+		}
+	}
+
+	return nil
 }
 
 // getAnsweredPlayers returns a list of players who have answered the current question
@@ -318,41 +961,146 @@ func (g *Game) getCurrentQuestion() entity.QuizQuestion {
 // Returns:
 // - bool: true if the choice is correct, false otherwise
 func (g *Game) isCorrectChoice(choiceIndex int) bool {
-	choices := g.getCurrentQuestion().Choices
-	if choiceIndex < 0 || choiceIndex >= len(choices) {
+	return isCorrectChoiceOf(g.getCurrentQuestion(), choiceIndex)
+}
+
+// isCorrectChoiceOf checks if a given choice is a correct answer to a question
+// Parameters:
+// - question: the question the choice belongs to
+// - choiceIndex: the index of the choice to check
+// Returns:
+// - bool: true if the choice is correct, false otherwise
+func isCorrectChoiceOf(question entity.QuizQuestion, choiceIndex int) bool {
+	if choiceIndex < 0 || choiceIndex >= len(question.Choices) {
 		return false
 	}
 
-	return choices[choiceIndex].Correct
+	return question.Choices[choiceIndex].Correct
+}
+
+// getAnswerElapsed returns how long a player took to answer the current question, compensating
+// for the time the answer spent travelling over the network
+// Parameters:
+// - player: the player who answered
+// Returns:
+// - time.Duration: the elapsed time, between zero and the question's time limit, which answers in the grace window take
+func (g *Game) getAnswerElapsed(player *Player) time.Duration {
+	elapsed := g.clock.Now().Sub(g.questionStartedAt) - player.latency()
+	limit := time.Duration(g.getCurrentQuestion().Time) * time.Second
+	if g.inGrace() {
+		return limit
+	}
+
+	return max(0, min(elapsed, limit))
 }
 
 // getPointsReward calculates the points to award for answering a question
+// Parameters:
+// - elapsed: the time the player took to answer
 // Returns:
 // - int: the number of points awarded
-func (g *Game) getPointsReward() int {
+func (g *Game) getPointsReward(elapsed time.Duration) int {
 	answered := len(g.getAnsweredPlayers())
 	orderReward := 5000 - (1000 * math.Min(4, float64(answered)))
-	timeReward := g.Time * (1000 / 60)
+	remaining := g.getCurrentQuestion().Time - int(elapsed.Seconds())
+	timeReward := remaining * (1000 / 60)
 
 	return int(orderReward) + timeReward
 }
 
-// OnPlayerAnswer handles a player answering a question
+// bestReward returns the reward getPointsReward gives the first answer to a question, given at once
+// Parameters:
+// - question: the question
+// Returns:
+// - int: the number of points
+func bestReward(question entity.QuizQuestion) int {
+	return 5000 + question.Time*(1000/60)
+}
+
+// OnPlayerAnswer handles a player answering a question without saying how sure they are
 // Parameters:
 // - choice: the index of the chosen answer
 // - player: the player who answered
 func (g *Game) OnPlayerAnswer(choice int, player *Player) {
-	if g.isCorrectChoice(choice) {
-		player.LastAwardedPoints = g.getPointsReward()
-		player.Points += player.LastAwardedPoints
-	} else {
-		player.LastAwardedPoints = 0
+	g.OnConfidentAnswer(choice, NoConfidence, player)
+}
+
+// OnConfidentAnswer handles a player answering a question
+// Parameters:
+// - choice: the index of the chosen answer
+// - confidence: how sure the player is, weighing the points in confidence mode
+// - player: the player who answered
+func (g *Game) OnConfidentAnswer(choice int, confidence Confidence, player *Player) {
+	// Confidence only counts in confidence mode
+	if !g.Settings.ConfidenceMode {
+		confidence = NoConfidence
 	}
 
-	player.Answered = true
+	if g.Settings.PlayerPaced {
+		g.onPacedAnswer(choice, confidence, player)
+		return
+	}
 
-	// If all players have answered, reveal the correct answer
-	if len(g.getAnsweredPlayers()) == len(g.Players) {
+	if g.State == TieBreakerState {
+		g.onTieBreakerAnswer(choice, player)
+		return
+	}
+
+	// Answers are only accepted while the question is being played, given out loud in buzzer games and
+	// not at all from eliminated players
+	if g.State != PlayState || g.Settings.BuzzerMode || player.Eliminated {
+		g.sendAnswerAck(player, choice, false)
+		return
+	}
+
+	// The reward is fixed when answering and counted at the reveal, a changed answer is rewarded as of its change
+	elapsed := g.getAnswerElapsed(player)
+	correct := g.isCorrectChoice(choice)
+	points := g.answerScoring(g.CurrentQuestion, confidence).Points(correct, g.getPointsReward(elapsed))
+
+	recorded := PlayerAnswer{
+		Question:   g.CurrentQuestion,
+		Choice:     choice,
+		Correct:    correct,
+		Elapsed:    elapsed,
+		Points:     points,
+		Confidence: confidence,
+	}
+	if player.Answered {
+		answer, ok := player.Answers[g.CurrentQuestion]
+		if !ok || answer.Choice == choice || !g.canChangeAnswer(answer) {
+			// A resent answer arrived already, only a different one is turned down
+			g.sendAnswerAck(player, choice, ok && answer.Choice == choice)
+			return
+		}
+
+		recorded.History = append(slices.Clone(answer.History), answer.Choice)
+	} else {
+		player.Answered = true
+		g.markActive(player)
+	}
+	g.applyToPlayer(player, AnswerRecordedEvent{PlayerId: player.Id, Answer: recorded})
+	g.streamAnswer(player, *player.Answers[g.CurrentQuestion])
+	g.sendAnswerAck(player, choice, true)
+
+	// Once no player can answer or change their answer anymore, reveal the correct answer
+	if g.allAnswersFinal() {
 		g.Reveal()
 	}
 }
+
+// allAnswersFinal reports whether every active player has answered the current question and cannot change their answer
+func (g *Game) allAnswersFinal() bool {
+	for _, player := range g.Players {
+		if player.Idle || player.Eliminated {
+			continue
+		}
+
+		answer, ok := player.Answers[g.CurrentQuestion]
+		if !player.Answered || !ok || g.canChangeAnswer(answer) {
+			return false
+		}
+	}
+
+	return true
+}
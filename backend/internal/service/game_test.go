@@ -0,0 +1,102 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/config"
+)
+
+const nextQuestionPreviewPacketId = 22
+
+func TestNextQuestionPreviewIsHostOnly(t *testing.T) {
+	host := &fakeConnection{}
+	player := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, Net(NetOptions{}, config.Config{}))
+	game.Players = []*Player{{Id: uuid.New(), Name: "alice", Connection: player}}
+	game.CurrentQuestion = 0
+
+	game.Intermission()
+
+	if !slices.Contains(host.packetIds(), nextQuestionPreviewPacketId) {
+		t.Errorf("host did not receive the preview, got packets %v", host.packetIds())
+	}
+	if slices.Contains(player.packetIds(), nextQuestionPreviewPacketId) {
+		t.Errorf("player received the preview, got packets %v", player.packetIds())
+	}
+}
+
+func TestNoPreviewAfterLastQuestion(t *testing.T) {
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, Net(NetOptions{}, config.Config{}))
+	game.CurrentQuestion = len(game.Quiz.Questions) - 1
+
+	game.Intermission()
+
+	if slices.Contains(host.packetIds(), nextQuestionPreviewPacketId) {
+		t.Errorf("host received a preview after the last question, got packets %v", host.packetIds())
+	}
+}
+
+func TestAdminEndGame(t *testing.T) {
+	net := Net(NetOptions{}, config.Config{})
+	lobby := newGame(fuzzQuiz(), &fakeConnection{}, net)
+	playing := newGame(fuzzQuiz(), &fakeConnection{}, net)
+	playing.Players = []*Player{{Id: uuid.New(), Name: "alice", Connection: &fakeConnection{}}}
+	playing.State = PlayState
+	playing.CurrentQuestion = 0
+	net.addGame(lobby)
+	net.addGame(playing)
+
+	games := net.GetGames()
+	if len(games) != 2 || games[1].Code != playing.Code || games[1].Players != 1 || games[1].State != PlayState {
+		t.Fatalf("unexpected games %+v", games)
+	}
+
+	if !net.EndGame(lobby.Code) || !lobby.Ended || lobby.State != EndState {
+		t.Errorf("expected the lobby to be closed, got state %d", lobby.State)
+	}
+	if !net.EndGame(playing.Code) || !playing.Ended {
+		t.Error("expected the running game to end")
+	}
+
+	if games := net.GetGames(); len(games) != 0 {
+		t.Errorf("expected ended games to be removed, got %+v", games)
+	}
+	if net.EndGame(lobby.Code) {
+		t.Error("expected ending an unknown game to fail")
+	}
+}
+
+func TestPongsOfSentPings(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	clock := newFakeClock()
+	c.clock = clock
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	alice := &Player{Id: uuid.New(), Name: "alice", Connection: &fakeConnection{}}
+	game.Players = []*Player{alice}
+
+	sent := clock.Now().UnixMilli()
+	game.sendPing(alice)
+	clock.Advance(100 * time.Millisecond)
+
+	// A made up server time would claim a slow connection
+	game.OnPlayerPong(alice, &PongPacket{ServerTime: sent - 20000})
+	if alice.rttSamples != 0 {
+		t.Fatalf("expected the pong of a ping never sent to be ignored, got a round trip of %v", alice.Rtt)
+	}
+
+	game.OnPlayerPong(alice, &PongPacket{ServerTime: sent})
+	game.OnPlayerPong(alice, &PongPacket{ServerTime: sent})
+	if alice.rttSamples != 1 || alice.Rtt != 100*time.Millisecond {
+		t.Errorf("expected the ping to be measured once, got %d samples and a round trip of %v", alice.rttSamples, alice.Rtt)
+	}
+
+	// Slow connections only earn back a limited time
+	alice.Rtt = 5 * time.Second
+	if alice.latency() != maxLatency {
+		t.Errorf("expected the compensation to be capped, got %v", alice.latency())
+	}
+}
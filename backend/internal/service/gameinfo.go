@@ -0,0 +1,24 @@
+package service
+
+// GameInfoPacket tells a player which quiz they are playing and under which rules.
+// It is sent when the player joins, with every question and when a playlist moves on to its next quiz.
+type GameInfoPacket struct {
+	Quiz         string           `json:"quiz"`         // Name of the quiz being played
+	Questions    int              `json:"questions"`    // Number of questions in the quiz
+	Question     int              `json:"question"`     // Number of the current question starting at 1, 0 before the first
+	Scoring      ScoringMode      `json:"scoring"`      // How answers are scored
+	AnswerChange AnswerChangeMode `json:"answerChange"` // Whether and how players may change their answer
+}
+
+// newGameInfoPacket describes the quiz and the rules of the game for players
+// Returns:
+// - GameInfoPacket: the packet
+func (g *Game) newGameInfoPacket() GameInfoPacket {
+	return GameInfoPacket{
+		Quiz:         g.Quiz.Name,
+		Questions:    len(g.Quiz.Questions),
+		Question:     g.CurrentQuestion + 1,
+		Scoring:      g.Settings.Scoring,
+		AnswerChange: g.Settings.AnswerChange,
+	}
+}
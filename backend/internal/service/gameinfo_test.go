@@ -0,0 +1,82 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// Packet IDs of the packets the tests decode
+const (
+	playerQuestionPacketId = 14
+	gameInfoPacketId       = 30
+)
+
+// lastGameInfo decodes the last GameInfoPacket written to a connection
+func lastGameInfo(t *testing.T, connection *fakeConnection) GameInfoPacket {
+	var info GameInfoPacket
+	for i := len(connection.messages) - 1; i >= 0; i-- {
+		if message := connection.messages[i]; message[0] == gameInfoPacketId {
+			if err := json.Unmarshal(packetData(message), &info); err != nil {
+				t.Fatal(err)
+			}
+			return info
+		}
+	}
+
+	t.Fatalf("no game info among packets %v", connection.packetIds())
+	return info
+}
+
+func TestGameInfoOnJoinAndQuestion(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnSettings(GameSettings{Scoring: AccuracyScoring})
+
+	player := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", player)
+	if info := lastGameInfo(t, player); info.Questions != 3 || info.Question != 0 || info.Scoring != AccuracyScoring {
+		t.Errorf("unexpected game info on join: %+v", info)
+	}
+
+	game.Start()
+	game.NextQuestion()
+	if info := lastGameInfo(t, player); info.Question != 2 {
+		t.Errorf("expected the second question, got %+v", info)
+	}
+}
+
+func TestLateJoinerReceivesQuestion(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.Start()
+	game.NextQuestion()
+
+	late := &fakeConnection{}
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", late)
+
+	var question PlayerQuestionPacket
+	for _, message := range late.messages {
+		if message[0] == playerQuestionPacketId {
+			if err := json.Unmarshal(packetData(message), &question); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if question.Index != 1 || question.Total != 3 || question.Choices == 0 {
+		t.Errorf("expected the second of three questions, got %+v", question)
+	}
+	if len(question.Slots) != question.Choices || question.Slots[0] != choiceSlots[0] {
+		t.Errorf("expected a slot per choice, got %+v", question.Slots)
+	}
+}
+
+func TestChoiceSlotsRepeat(t *testing.T) {
+	slots := getChoiceSlots(len(choiceSlots) + 1)
+	if slots[len(choiceSlots)] != choiceSlots[0] || slots[5] == slots[0] {
+		t.Errorf("expected six distinct slots, then repeating, got %+v", slots)
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Limits of the join restrictions of a game
+const (
+	maxAllowedNetworks  = 50 // Most IP addresses and CIDR ranges a game can be restricted to
+	maxAllowedCountries = 50 // Most countries a game can be restricted to
+)
+
+// ErrInvalidGeoDatabase is returned when a GeoIP database cannot be read
+var ErrInvalidGeoDatabase = errors.New("invalid geoip database")
+
+// GeoResolver finds the country of an IP address, for games restricted to countries.
+// Any database can be plugged in; CsvGeoResolver reads the free CSV range databases.
+type GeoResolver interface {
+	Country(addr netip.Addr) (string, error) // ISO 3166-1 alpha-2 code of the country, empty if unknown
+}
+
+// geoRange is a range of IP addresses located in a country
+type geoRange struct {
+	first   netip.Addr // First address of the range
+	last    netip.Addr // Last address of the range, inclusive
+	country string     // ISO 3166-1 alpha-2 code of the country
+}
+
+// CsvGeoResolver locates IP addresses in a database of address ranges held in memory
+type CsvGeoResolver struct {
+	ranges []geoRange // Ranges ordered by their first address, not overlapping
+}
+
+// GeoCsv loads a GeoIP database from a CSV file of first address, last address and country code rows,
+// the format of the free DB-IP and IP2Location country databases.
+// Parameters:
+// - path: the path of the CSV file.
+// Returns:
+// - The resolver and ErrInvalidGeoDatabase if a row cannot be read, or an error if the file cannot be opened.
+func GeoCsv(path string) (*CsvGeoResolver, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readGeoCsv(file)
+}
+
+// readGeoCsv reads the ranges of a GeoIP database
+// Parameters:
+// - reader: the CSV content
+// Returns:
+// - *CsvGeoResolver: the resolver
+// - error: ErrInvalidGeoDatabase if a row cannot be read
+func readGeoCsv(reader io.Reader) (*CsvGeoResolver, error) {
+	rows := csv.NewReader(reader)
+	rows.FieldsPerRecord = -1
+
+	ranges := []geoRange{}
+	for line := 1; ; line++ {
+		row, err := rows.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(row) < 3 {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidGeoDatabase, line)
+		}
+
+		first, firstErr := netip.ParseAddr(strings.TrimSpace(row[0]))
+		last, lastErr := netip.ParseAddr(strings.TrimSpace(row[1]))
+		first, last = first.Unmap(), last.Unmap()
+		if firstErr != nil || lastErr != nil || first.Is4() != last.Is4() || last.Less(first) {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidGeoDatabase, line)
+		}
+
+		ranges = append(ranges, geoRange{first: first, last: last, country: strings.ToUpper(strings.TrimSpace(row[2]))})
+	}
+
+	slices.SortFunc(ranges, func(a, b geoRange) int {
+		return a.first.Compare(b.first)
+	})
+
+	return &CsvGeoResolver{ranges: ranges}, nil
+}
+
+// Country finds the country of an IP address.
+// Parameters:
+// - addr: the address.
+// Returns:
+// - The ISO 3166-1 alpha-2 code of the country, empty if the address is in no range.
+func (r *CsvGeoResolver) Country(addr netip.Addr) (string, error) {
+	addr = addr.Unmap()
+
+	// The last range starting at or before the address is the only one that can contain it
+	i, found := slices.BinarySearchFunc(r.ranges, addr, func(candidate geoRange, target netip.Addr) int {
+		return candidate.first.Compare(target)
+	})
+	if !found {
+		i--
+	}
+
+	if i < 0 || r.ranges[i].last.Less(addr) {
+		return "", nil
+	}
+
+	return r.ranges[i].country, nil
+}
+
+// parseNetwork reads an entry of a game's network allowlist
+// Parameters:
+// - network: an IP address or a CIDR range, e.g. 10.0.0.0/8
+// Returns:
+// - netip.Prefix: the range, a single address being a range of its own
+// - error: an error if the entry is neither
+func parseNetwork(network string) (netip.Prefix, error) {
+	if strings.Contains(network, "/") {
+		prefix, err := netip.ParsePrefix(network)
+		return prefix.Masked(), err
+	}
+
+	addr, err := netip.ParseAddr(network)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
+
+// validateJoinRestriction checks the networks and countries a game is restricted to
+// Parameters:
+// - networks: IP addresses and CIDR ranges
+// - countries: ISO 3166-1 alpha-2 codes in upper case
+// Returns:
+// - error: ErrInvalidPacket if there are too many or one cannot be read
+func validateJoinRestriction(networks []string, countries []string) error {
+	if len(networks) > maxAllowedNetworks || len(countries) > maxAllowedCountries {
+		return ErrInvalidPacket
+	}
+
+	for _, network := range networks {
+		if _, err := parseNetwork(network); err != nil {
+			return ErrInvalidPacket
+		}
+	}
+
+	for _, country := range countries {
+		if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return ErrInvalidPacket
+		}
+	}
+
+	return nil
+}
+
+// canJoinFrom reports whether a client may join a game from its address.
+// Games without restrictions are open to everyone; restricted games let in clients in one of the
+// allowed networks or located in one of the allowed countries, and nobody whose address is unknown.
+// Parameters:
+// - connection: the connection of the client
+// Returns:
+// - bool: true if the client may join
+func (g *Game) canJoinFrom(connection Connection) bool {
+	if len(g.Settings.AllowedNetworks) == 0 && len(g.Settings.AllowedCountries) == 0 {
+		return true
+	}
+
+	client, ok := connection.(addressable)
+	if !ok {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(client.IP())
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, network := range g.Settings.AllowedNetworks {
+		if prefix, err := parseNetwork(network); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	// Without a GeoIP database no address can be located, so country restrictions keep everyone else out
+	resolver := g.netService.geoResolver
+	if len(g.Settings.AllowedCountries) == 0 || resolver == nil {
+		return false
+	}
+
+	country, err := resolver.Country(addr)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+
+	return country != "" && slices.Contains(g.Settings.AllowedCountries, country)
+}
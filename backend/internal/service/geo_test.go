@@ -0,0 +1,94 @@
+package service
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// addressedConnection is a fake connection from a client at a known address
+type addressedConnection struct {
+	fakeConnection
+	ip string // Address of the client
+}
+
+// IP returns the address of the client
+func (c *addressedConnection) IP() string {
+	return c.ip
+}
+
+const testGeoCsv = `1.0.0.0,1.0.0.255,AU
+2.16.0.0,2.16.255.255,DE
+2001:db8::,2001:db8::ffff,NL
+`
+
+func TestGeoCsvCountry(t *testing.T) {
+	resolver, err := readGeoCsv(strings.NewReader(testGeoCsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"1.0.0.7":         "AU",
+		"2.16.200.1":      "DE",
+		"::ffff:2.16.0.0": "DE",
+		"2001:db8::1":     "NL",
+		"1.0.1.0":         "",
+		"0.0.0.1":         "",
+		"2001:db8::1:0:0": "",
+	}
+	for address, want := range tests {
+		if got, _ := resolver.Country(netip.MustParseAddr(address)); got != want {
+			t.Errorf("%s: expected %q, got %q", address, want, got)
+		}
+	}
+
+	if _, err := readGeoCsv(strings.NewReader("1.0.0.255,1.0.0.0,AU\n")); err == nil {
+		t.Errorf("expected a reversed range to be rejected")
+	}
+}
+
+func TestJoinRestriction(t *testing.T) {
+	resolver, err := readGeoCsv(strings.NewReader(testGeoCsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Net(NetOptions{GeoResolver: resolver}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnSettings(GameSettings{AllowedNetworks: []string{"10.20.0.0/16", "192.0.2.7"}, AllowedCountries: []string{"DE"}})
+
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"10.20.3.4", true},
+		{"192.0.2.7", true},
+		{"2.16.1.1", true},
+		{"10.21.0.1", false},
+		{"1.0.0.7", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		connection := &addressedConnection{ip: test.ip}
+		game.OnPlayerJoin("player", primitive.NilObjectID, 0, 0, "", connection)
+		if connection.closed == test.allowed {
+			t.Errorf("%q: expected allowed %v", test.ip, test.allowed)
+		}
+	}
+}
+
+func TestValidateJoinRestriction(t *testing.T) {
+	if err := validateJoinRestriction([]string{"10.0.0.0/8", "::1"}, []string{"US"}); err != nil {
+		t.Errorf("expected a valid restriction, got %v", err)
+	}
+	if err := validateJoinRestriction([]string{"campus"}, nil); err == nil {
+		t.Errorf("expected an unreadable network to be rejected")
+	}
+	if err := validateJoinRestriction(nil, []string{"usa"}); err == nil {
+		t.Errorf("expected a country that is not an ISO code to be rejected")
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import "time"
+
+// maxAnswerGrace bounds the grace window, so a misconfiguration cannot hold a question open for long
+const maxAnswerGrace = 5 * time.Second
+
+// timeUp handles the timer of a question running out. Answers are still accepted for the configured
+// grace window, as they may have been sent in time and delayed by the network; the timer shown stays
+// at zero and late answers earn no time bonus, see getAnswerElapsed.
+func (g *Game) timeUp() {
+	grace := min(g.netService.config.AnswerGrace, maxAnswerGrace)
+	if grace <= 0 {
+		g.Reveal()
+		return
+	}
+
+	question := g.CurrentQuestion
+	go func() {
+		g.clock.Sleep(grace)
+		g.run("grace", func() {
+			// Everyone may have answered during the grace window already
+			if g.Ended || g.State != PlayState || g.CurrentQuestion != question {
+				return
+			}
+
+			g.Reveal()
+		})
+	}()
+}
+
+// inGrace reports whether the timer of the current question ran out and the grace window is open
+func (g *Game) inGrace() bool {
+	return g.State == PlayState && g.Time == 0
+}
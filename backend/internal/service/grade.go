@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"math"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// maxGrades is the most grade boundaries a quiz can have
+const maxGrades = 10
+
+// ErrInvalidGrades is returned when the grade boundaries of a quiz are malformed
+var ErrInvalidGrades = errors.New("invalid grade boundaries")
+
+// validateGrades checks that every grade boundary is named, within 0 to 100 percent and unique
+// Parameters:
+// - grades: the boundaries to check
+// Returns:
+// - error: ErrInvalidGrades if a boundary is malformed, nil otherwise
+func validateGrades(grades []entity.GradeBoundary) error {
+	if len(grades) > maxGrades {
+		return ErrInvalidGrades
+	}
+
+	seen := map[float64]bool{}
+	for _, grade := range grades {
+		if grade.Name == "" || grade.MinPercent < 0 || grade.MinPercent > 100 || seen[grade.MinPercent] {
+			return ErrInvalidGrades
+		}
+		seen[grade.MinPercent] = true
+	}
+
+	return nil
+}
+
+// gradePoints grades points against the most points a player could have earned
+// Parameters:
+// - grades: the grade boundaries of the quiz, in any order
+// - points: the points the player earned
+// - maxPoints: the most points the player could have earned
+// Returns:
+// - *entity.PlayerGrade: the grade of the highest boundary reached, nil if the quiz is not graded
+func gradePoints(grades []entity.GradeBoundary, points int, maxPoints int) *entity.PlayerGrade {
+	if len(grades) == 0 || maxPoints <= 0 {
+		return nil
+	}
+
+	percent := float64(points) / float64(maxPoints) * 100
+	grade := entity.PlayerGrade{Percent: math.Round(percent*10) / 10}
+	reached := -1.0
+	for _, boundary := range grades {
+		if percent >= boundary.MinPercent && boundary.MinPercent > reached {
+			reached = boundary.MinPercent
+			grade.Name = boundary.Name
+			grade.Passed = boundary.Pass
+		}
+	}
+
+	return &grade
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestGradePoints(t *testing.T) {
+	grades := []entity.GradeBoundary{
+		{Name: "Fail", MinPercent: 0},
+		{Name: "A", MinPercent: 90, Pass: true},
+		{Name: "B", MinPercent: 80, Pass: true},
+	}
+
+	tests := []struct {
+		points int
+		want   entity.PlayerGrade
+	}{
+		{95, entity.PlayerGrade{Percent: 95, Name: "A", Passed: true}},
+		{80, entity.PlayerGrade{Percent: 80, Name: "B", Passed: true}},
+		{79, entity.PlayerGrade{Percent: 79, Name: "Fail"}},
+	}
+	for _, test := range tests {
+		if got := gradePoints(grades, test.points, 100); got == nil || *got != test.want {
+			t.Errorf("%d points: got %+v, want %+v", test.points, got, test.want)
+		}
+	}
+
+	if got := gradePoints(nil, 50, 100); got != nil {
+		t.Errorf("expected no grade without boundaries, got %+v", got)
+	}
+	if validateGrades([]entity.GradeBoundary{{Name: "A", MinPercent: 80}, {Name: "B", MinPercent: 80}}) == nil {
+		t.Errorf("expected boundaries at the same percentage to be rejected")
+	}
+}
+
+func TestGameEndGrades(t *testing.T) {
+	quiz := fuzzQuiz()
+	quiz.TotalPoints = 100
+	quiz.Grades = []entity.GradeBoundary{{Name: "Pass", MinPercent: 30, Pass: true}}
+
+	c := Net(NetOptions{}, config.Config{})
+	host := &fakeConnection{}
+	game := newGame(quiz, host, c)
+	player := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", player)
+	game.Start()
+	game.OnPlayerAnswer(0, game.Players[0])
+	game.End()
+
+	var end GameEndPacket
+	for _, message := range player.messages {
+		if message[0] == gameEndPacketId {
+			if err := json.Unmarshal(packetData(message), &end); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if end.Grade == nil || end.Grade.Percent != 33.3 || !end.Grade.Passed {
+		t.Errorf("expected a passing grade for a third of the points, got %+v", end.Grade)
+	}
+
+	if result := game.buildResult(game.buildReport(), nil); result.Players[0].Grade == nil || result.Players[0].Grade.Name != "Pass" {
+		t.Errorf("expected the grade in the stored result, got %+v", result.Players[0])
+	}
+}
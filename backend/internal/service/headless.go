@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Lobby times of headless games, which start on their own once the lobby time is over
+const (
+	DefaultHeadlessLobby = time.Minute
+	minHeadlessLobby     = 10 * time.Second
+	maxHeadlessLobby     = 10 * time.Minute
+)
+
+// ErrQuizNotFound is returned when a game is started for a quiz that does not exist
+var ErrQuizNotFound = errors.New("quiz not found")
+
+// HostHeadless starts a game without a host connection. The server runs it on autopilot: it starts once
+// the lobby time is over and players have joined, then advances through the questions on their timers.
+// Screens follow the game through the leaderboard widget stream.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - userId: the ID of the user starting the game, who may fetch its state.
+// - quizId: the ID of the quiz to play.
+// - lobby: how long players can join before the quiz starts, clamped to between 10 seconds and 10 minutes.
+// Returns:
+// - The join code of the game, and ErrQuizNotFound if the quiz does not exist, ErrQuizForbidden if it is not shared with the user for hosting
+// or ErrDailyLimit if the user hosted as many games today as their plan allows.
+func (c *NetService) HostHeadless(ctx context.Context, userId primitive.ObjectID, quizId primitive.ObjectID, lobby time.Duration) (string, error) {
+	quiz, err := c.quizService.GetQuizById(ctx, quizId)
+	if err != nil {
+		return "", err
+	}
+
+	if quiz == nil {
+		return "", ErrQuizNotFound
+	}
+
+	if !CanAccessQuiz(*quiz, userId, "", entity.HostPermission) {
+		return "", ErrQuizForbidden
+	}
+
+	game, err := c.hostHeadlessGame(ctx, *quiz, userId)
+	if err != nil {
+		return "", err
+	}
+
+	go game.runAutopilot(min(max(lobby, minHeadlessLobby), maxHeadlessLobby))
+	c.announceGame(game)
+	fmt.Println("headless game", game.Code, "hosted for quiz", quiz.Name)
+	return game.Code, nil
+}
+
+// hostHeadlessGame registers a game without a host connection, for the caller to start its autopilot.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quiz: the quiz to play.
+// - userId: the ID of the user the game is hosted for, whose plan limits it.
+// Returns:
+// - The game and ErrDailyLimit if the user hosted as many games today as their plan allows.
+func (c *NetService) hostHeadlessGame(ctx context.Context, quiz entity.Quiz, userId primitive.ObjectID) (*Game, error) {
+	// Uploaded media is only reachable through signed URLs
+	if c.mediaService != nil {
+		quiz = c.mediaService.SignQuiz(ctx, quiz)
+	}
+
+	game := newGame(quiz, nil, c)
+	game.record = c.getRecord(ctx, quiz.Id)
+	game.hostUserId = userId
+	game.headless = true
+	if err := c.applyQuota(ctx, game); err != nil {
+		return nil, err
+	}
+
+	c.addGame(game)
+	return game, nil
+}
+
+// runAutopilot starts a headless game once the lobby time is over and a player has joined.
+// A game nobody joins is closed after the empty game grace period.
+// Parameters:
+// - lobby: how long to wait before starting
+func (g *Game) runAutopilot(lobby time.Duration) {
+	g.clock.Sleep(lobby)
+
+	for waited := 0; ; waited++ {
+		done := true
+		g.run("autopilot", func() {
+			switch {
+			case g.Ended || g.State != LobbyState:
+			case len(g.Players) > 0:
+				g.Start()
+			case waited >= emptyGameGracePeriod:
+				fmt.Println("headless game", g.Code, "closed without players")
+				g.closeLobby()
+				g.netService.removeGame(g)
+			default:
+				done = false
+			}
+		})
+
+		if done {
+			return
+		}
+
+		g.clock.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestHeadlessGameWithoutHost(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), nil, c)
+	c.addGame(game)
+
+	screen := &fakeConnection{}
+	if err := c.WatchLeaderboard(game.Code, screen); err != nil {
+		t.Fatal(err)
+	}
+
+	player := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", player)
+	game.NextQuestion()
+	game.OnPlayerAnswer(0, game.Players[0])
+	game.Reveal()
+
+	// The screen shows the revealed question in place of the host
+	var update LeaderboardWidgetUpdate
+	if err := json.Unmarshal(screen.messages[len(screen.messages)-1], &update); err != nil {
+		t.Fatal(err)
+	}
+	if update.Prompt == nil || update.Prompt.Name != "One" || len(update.Prompt.Correct) != 1 || update.Prompt.Correct[0] != 0 {
+		t.Errorf("expected the revealed question on the screen, got %+v", update.Prompt)
+	}
+
+	game.Intermission()
+	game.End()
+	if !game.Ended || len(player.messages) == 0 {
+		t.Errorf("expected the game to run to its end, got %d player messages", len(player.messages))
+	}
+}
+
+func TestAutopilotStartsQuiz(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), nil, c)
+	c.addGame(game)
+
+	// Players joining before the lobby time is over start the game
+	game.Players = []*Player{{Id: uuid.New(), Name: "alice", Connection: &fakeConnection{}}}
+	game.runAutopilot(0)
+	game.run("stop", func() {
+		game.Ended = true
+		if game.State != PlayState || game.CurrentQuestion != 0 {
+			t.Errorf("expected the autopilot to start the quiz, got state %d", game.State)
+		}
+	})
+}
+
+func TestHostStateOnlyForHost(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	host := primitive.NewObjectID()
+	game := newGame(fuzzQuiz(), nil, c)
+	game.hostUserId = host
+	c.addGame(game)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+
+	if _, err := c.GetHostState(game.Code, primitive.NewObjectID()); err != ErrNotHost {
+		t.Errorf("expected another user to be refused, got %v", err)
+	}
+	if _, err := c.GetHostState("000000", host); err != ErrGameNotFound {
+		t.Errorf("expected an unknown code to be reported, got %v", err)
+	}
+
+	state, err := c.GetHostState(game.Code, host)
+	if err != nil || state.Code != game.Code || len(state.Players) != 1 || state.Players[0].Name != "alice" {
+		t.Errorf("expected the state of the game, got %+v, %v", state, err)
+	}
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Timing of hibernation
+const (
+	hibernateCheckInterval = time.Minute      // How often games are checked for being idle
+	hibernatedGameTtl      = 24 * time.Hour   // How long a hibernated lobby can be woken before it is thrown away
+	hibernateWakeTimeout   = 10 * time.Second // Time allowed for signing the media of a woken game
+)
+
+// ErrNotResumable is returned when a host asks to take over a game that is not waiting in the lobby
+var ErrNotResumable = errors.New("game cannot be resumed")
+
+// hibernatedGame is what is kept of a lobby while it hibernates, everything needed to open it again.
+// Players are disconnected when the lobby hibernates and join again by its code.
+type hibernatedGame struct {
+	Id              string               `bson:"id"`              // ID of the game
+	Code            string               `bson:"code"`            // Join code of the game
+	Quiz            entity.Quiz          `bson:"quiz"`            // The quiz being played
+	Settings        GameSettings         `bson:"settings"`        // Rules the host chose for the game
+	Playlist        []entity.Quiz        `bson:"playlist"`        // Quizzes played one after another, empty for a single quiz
+	ResetPoints     bool                 `bson:"resetPoints"`     // Whether points start from zero with each quiz of the playlist
+	PlaylistRecords []int                `bson:"playlistRecords"` // Best score ever reached on each quiz of the playlist
+	Record          int                  `bson:"record"`          // Best score ever reached on the quiz, -1 if unknown
+	Tournament      *entity.Tournament   `bson:"tournament"`      // Tournament the game is played for, nil for a standalone game
+	Round           int                  `bson:"round"`           // Index of the tournament round the game is played as
+	AllowedUsers    []primitive.ObjectID `bson:"allowedUsers"`    // Accounts of the players still in a bracket tournament, nil if anyone may join
+	MaxPlayers      int                  `bson:"maxPlayers"`      // Most players who may join, zero for no limit
+	BannedNames     []string             `bson:"bannedNames"`     // Names of players the host banned
+	BannedUsers     []primitive.ObjectID `bson:"bannedUsers"`     // Accounts of players the host banned
+	BannedAddresses []string             `bson:"bannedAddresses"` // Addresses of players the host banned
+	HostUserId      primitive.ObjectID   `bson:"hostUserId"`      // ID of the user who hosted the game, who may take it over again
+	HibernatedAt    time.Time            `bson:"hibernatedAt"`    // When the lobby went to sleep
+}
+
+// touch records that a client sent a packet for the game, keeping it from hibernating
+func (g *Game) touch() {
+	g.lastActivity.Store(g.clock.Now().UnixNano())
+}
+
+// idleFor returns how long no client sent a packet for the game
+// Parameters:
+// - now: the current time
+func (g *Game) idleFor(now time.Time) time.Duration {
+	return now.Sub(time.Unix(0, g.lastActivity.Load()))
+}
+
+// hibernate captures what is needed to open the lobby again
+// Parameters:
+// - now: when the lobby goes to sleep
+// Returns:
+// - hibernatedGame: the snapshot of the lobby
+func (g *Game) hibernate(now time.Time) hibernatedGame {
+	snapshot := hibernatedGame{
+		Id:              g.Id.String(),
+		Code:            g.Code,
+		Quiz:            g.Quiz,
+		Settings:        g.Settings,
+		Playlist:        g.Playlist,
+		ResetPoints:     g.resetPoints,
+		PlaylistRecords: g.playlistRecords,
+		Record:          g.record,
+		Tournament:      g.tournament,
+		Round:           g.round,
+		MaxPlayers:      g.maxPlayers,
+		HostUserId:      g.hostUserId,
+		HibernatedAt:    now,
+	}
+
+	if g.allowedUsers != nil {
+		snapshot.AllowedUsers = []primitive.ObjectID{}
+	}
+	for userId := range g.allowedUsers {
+		snapshot.AllowedUsers = append(snapshot.AllowedUsers, userId)
+	}
+	for name := range g.bans.names {
+		snapshot.BannedNames = append(snapshot.BannedNames, name)
+	}
+	for userId := range g.bans.users {
+		snapshot.BannedUsers = append(snapshot.BannedUsers, userId)
+	}
+	for address := range g.bans.addresses {
+		snapshot.BannedAddresses = append(snapshot.BannedAddresses, address)
+	}
+
+	return snapshot
+}
+
+// restoreGame opens a hibernated lobby again, without a host until they take it over.
+// Parameters:
+// - ctx: the context bounding the signing of the media.
+// - snapshot: the hibernated lobby.
+// Returns:
+// - The game, waiting in the lobby.
+func (c *NetService) restoreGame(ctx context.Context, snapshot hibernatedGame) *Game {
+	// The signed URLs of the media may have expired while the lobby slept
+	if c.mediaService != nil {
+		snapshot.Quiz = c.mediaService.SignQuiz(ctx, snapshot.Quiz)
+		for i, quiz := range snapshot.Playlist {
+			snapshot.Playlist[i] = c.mediaService.SignQuiz(ctx, quiz)
+		}
+	}
+
+	game := newGame(snapshot.Quiz, nil, c)
+	if id, err := uuid.Parse(snapshot.Id); err == nil {
+		game.Id = id
+	}
+	game.Code = snapshot.Code
+	game.Settings = snapshot.Settings
+	game.Playlist = snapshot.Playlist
+	game.resetPoints = snapshot.ResetPoints
+	game.playlistRecords = snapshot.PlaylistRecords
+	game.record = snapshot.Record
+	game.tournament = snapshot.Tournament
+	game.round = snapshot.Round
+	game.maxPlayers = snapshot.MaxPlayers
+	game.hostUserId = snapshot.HostUserId
+
+	if len(snapshot.BannedNames) > 0 {
+		game.bans = gameBans{
+			names:     map[string]bool{},
+			users:     map[primitive.ObjectID]bool{},
+			addresses: map[string]bool{},
+		}
+	}
+	if snapshot.AllowedUsers != nil {
+		game.allowedUsers = map[primitive.ObjectID]bool{}
+	}
+	for _, userId := range snapshot.AllowedUsers {
+		game.allowedUsers[userId] = true
+	}
+	for _, name := range snapshot.BannedNames {
+		game.bans.names[name] = true
+	}
+	for _, userId := range snapshot.BannedUsers {
+		game.bans.users[userId] = true
+	}
+	for _, address := range snapshot.BannedAddresses {
+		game.bans.addresses[address] = true
+	}
+
+	return game
+}
+
+// hibernatedGamePath returns the file a hibernated lobby is kept in. Join codes may use any characters,
+// so they are hex encoded to make a safe file name.
+// Parameters:
+// - code: the join code of the game
+func (c *NetService) hibernatedGamePath(code string) string {
+	return filepath.Join(c.config.HibernateDir, hex.EncodeToString([]byte(code))+".bson")
+}
+
+// saveHibernatedGame writes a hibernated lobby to disk, replacing the file at once so a crash cannot
+// leave half of it behind.
+// Parameters:
+// - snapshot: the hibernated lobby.
+// Returns:
+// - An error if the file cannot be written.
+func (c *NetService) saveHibernatedGame(snapshot hibernatedGame) error {
+	data, err := bson.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.config.HibernateDir, 0o700); err != nil {
+		return err
+	}
+
+	path := c.hibernatedGamePath(snapshot.Code)
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// loadHibernatedGame reads a hibernated lobby from disk.
+// Parameters:
+// - code: the join code of the game.
+// Returns:
+// - The hibernated lobby, and an error wrapping fs.ErrNotExist if no lobby with the code hibernates.
+func (c *NetService) loadHibernatedGame(code string) (*hibernatedGame, error) {
+	data, err := os.ReadFile(c.hibernatedGamePath(code))
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot hibernatedGame
+	if err := bson.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// RunHibernation checks for idle lobbies every minute, putting them to sleep, and throws away lobbies
+// that hibernated for too long. Nothing is done if hibernation is disabled.
+func (c *NetService) RunHibernation() {
+	if c.config.HibernateAfter <= 0 || c.config.HibernateDir == "" {
+		return
+	}
+
+	for {
+		c.clock.Sleep(hibernateCheckInterval)
+
+		now := c.clock.Now()
+		c.hibernateIdleGames(now)
+		c.pruneHibernatedGames(now)
+	}
+}
+
+// hibernateIdleGames puts lobbies no client sent a packet for in the configured time to sleep: they are
+// written to disk, dropped from memory and their host and players are disconnected with CloseHibernated.
+// Lobbies of hosts who were not signed in could never be taken over again, so they are closed instead.
+// Headless games close their lobbies on their own.
+// Parameters:
+// - now: the current time.
+func (c *NetService) hibernateIdleGames(now time.Time) {
+	c.gamesMu.Lock()
+	games := append([]*Game{}, c.games...)
+	c.gamesMu.Unlock()
+
+	for _, game := range games {
+		game.run("hibernate", func() {
+			if game.Ended || game.State != LobbyState || game.headless || game.idleFor(now) < c.config.HibernateAfter {
+				return
+			}
+
+			if game.hostUserId.IsZero() {
+				fmt.Println("idle lobby", game.Code, "closed")
+				game.closeLobby()
+				c.removeGame(game)
+				game.closeConnections(CloseGameEnded)
+				return
+			}
+
+			if err := c.saveHibernatedGame(game.hibernate(now)); err != nil {
+				fmt.Println("failed to hibernate game", game.Code, ":", err)
+				return
+			}
+
+			fmt.Println("idle lobby", game.Code, "hibernated")
+			game.apply(GameEndedEvent{})
+			c.removeGame(game)
+			game.closeConnections(CloseHibernated)
+		})
+	}
+}
+
+// pruneHibernatedGames deletes the lobbies that hibernated for longer than they can be woken.
+// Parameters:
+// - now: the current time.
+func (c *NetService) pruneHibernatedGames(now time.Time) {
+	entries, err := os.ReadDir(c.config.HibernateDir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Println("failed to list hibernated games:", err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < hibernatedGameTtl {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(c.config.HibernateDir, entry.Name())); err != nil {
+			fmt.Println("failed to delete hibernated game", entry.Name(), ":", err)
+		}
+	}
+}
+
+// wakeGame opens a hibernated lobby again when its code is used, so hosts and players find it as they left it.
+// Parameters:
+// - code: the join code of the game.
+// Returns:
+// - The running game with the code, nil if there is none and no lobby with the code hibernates.
+func (c *NetService) wakeGame(code string) *Game {
+	c.wakeMu.Lock()
+	defer c.wakeMu.Unlock()
+
+	// Another packet may have woken the lobby while this one waited
+	if game := c.getGameByCode(code); game != nil {
+		return game
+	}
+
+	if c.config.HibernateDir == "" {
+		return nil
+	}
+
+	snapshot, err := c.loadHibernatedGame(code)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Println("failed to wake game", code, ":", err)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hibernateWakeTimeout)
+	defer cancel()
+
+	game := c.restoreGame(ctx, *snapshot)
+	c.addGame(game)
+	if err := os.Remove(c.hibernatedGamePath(code)); err != nil {
+		fmt.Println("failed to delete hibernated game", code, ":", err)
+	}
+
+	go game.runLobbyStats()
+	fmt.Println("hibernated lobby", code, "woken")
+	return game
+}
+
+// resumeHosting hands a lobby, running or hibernated, to a new connection of the user who hosted it,
+// e.g. after the lobby hibernated or the host reloaded the page. A connection still hosting the lobby is closed.
+// Parameters:
+// - con: the WebSocket connection of the host.
+// - code: the join code of the game.
+// - token: the access token of the host.
+// Returns:
+// - ErrGameNotFound if there is no game with the code, ErrNotHost if the user did not host it
+// or ErrNotResumable if it is not waiting in the lobby.
+func (c *NetService) resumeHosting(con Connection, code string, token string) error {
+	hostUserId := c.authenticate(token)
+	if hostUserId.IsZero() {
+		return ErrNotHost
+	}
+
+	game := c.wakeGame(code)
+	if game == nil {
+		return ErrGameNotFound
+	}
+
+	var err error
+	game.run("resume host", func() {
+		if game.hostUserId != hostUserId {
+			err = ErrNotHost
+			return
+		}
+
+		if game.Ended || game.State != LobbyState || game.headless {
+			err = ErrNotResumable
+			return
+		}
+
+		previous := game.Host
+		c.gamesMu.Lock()
+		game.Host = con
+		c.gamesMu.Unlock()
+		c.bindConnection(con, game.Id)
+		game.touch()
+
+		if previous != nil && previous != con {
+			c.CloseConnection(previous, CloseNormal)
+		}
+
+		c.SendPacket(con, HostGamePacket{
+			QuizId: game.Code,
+		})
+		c.SendPacket(con, ChangeGameStatePacket{
+			State: game.State,
+		})
+		game.sendPreload(con, 0, entity.FullSize)
+		game.OnHostResync()
+	})
+
+	return err
+}
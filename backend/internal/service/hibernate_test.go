@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func newHibernatingNet(t *testing.T) (*NetService, *fakeClock) {
+	clock := newFakeClock()
+	cfg := config.Config{HibernateAfter: 30 * time.Minute, HibernateDir: t.TempDir(), AuthSecret: "secret", AuthTokenTtl: time.Hour}
+	c := Net(NetOptions{UserService: User(nil, nil, nil, cfg)}, cfg)
+	c.clock = clock
+	return c, clock
+}
+
+func TestHibernateIdleLobby(t *testing.T) {
+	c, clock := newHibernatingNet(t)
+	host := &fakeConnection{}
+	player := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	game.hostUserId = primitive.NewObjectID()
+	game.Settings.IdleLimit = 3
+	game.ban(&Player{Name: "mallory"})
+	game.Players = []*Player{{Id: uuid.New(), Name: "alice", Connection: player}}
+	c.addGame(game)
+
+	// A packet of a player keeps the lobby awake
+	clock.Advance(20 * time.Minute)
+	c.getGameByPlayer(player)
+	clock.Advance(20 * time.Minute)
+	c.hibernateIdleGames(clock.Now())
+	if c.getGameByCode(game.Code) == nil {
+		t.Fatal("expected a lobby with recent packets to stay awake")
+	}
+
+	clock.Advance(20 * time.Minute)
+	c.hibernateIdleGames(clock.Now())
+	if c.getGameByCode(game.Code) != nil || !game.Ended || !host.closed || !player.closed {
+		t.Fatal("expected the idle lobby to be dropped and its clients disconnected")
+	}
+
+	// Joining by the code wakes the lobby as it was left, without its players
+	woken := c.wakeGame(game.Code)
+	if woken == nil || woken.Id != game.Id || woken.Settings.IdleLimit != 3 || woken.Host != nil || len(woken.Players) != 0 {
+		t.Fatalf("expected the lobby to wake, got %+v", woken)
+	}
+	if !woken.isBanned("mallory", primitive.NilObjectID, &fakeConnection{}) {
+		t.Errorf("expected the bans to survive hibernation")
+	}
+	if c.wakeGame(game.Code) != woken {
+		t.Errorf("expected a woken lobby to be opened only once")
+	}
+}
+
+func TestHibernateClosesAnonymousLobby(t *testing.T) {
+	c, clock := newHibernatingNet(t)
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	c.addGame(game)
+
+	clock.Advance(time.Hour)
+	c.hibernateIdleGames(clock.Now())
+	if c.getGameByCode(game.Code) != nil || c.wakeGame(game.Code) != nil {
+		t.Errorf("expected the lobby of a host who was not signed in to be closed")
+	}
+}
+
+func TestResumeHosting(t *testing.T) {
+	c, clock := newHibernatingNet(t)
+	hostUser := entity.User{Id: primitive.NewObjectID(), Name: "host"}
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.hostUserId = hostUser.Id
+	c.addGame(game)
+
+	clock.Advance(time.Hour)
+	c.hibernateIdleGames(clock.Now())
+
+	token, err := c.userService.IssueToken(hostUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := c.userService.IssueToken(entity.User{Id: primitive.NewObjectID()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.resumeHosting(&fakeConnection{}, "nope", token); err != ErrGameNotFound {
+		t.Errorf("expected an unknown code to be rejected, got %v", err)
+	}
+	if err := c.resumeHosting(&fakeConnection{}, game.Code, stranger); err != ErrNotHost {
+		t.Errorf("expected another user to be rejected, got %v", err)
+	}
+
+	host := &fakeConnection{}
+	if err := c.resumeHosting(host, game.Code, token); err != nil {
+		t.Fatal(err)
+	}
+	if woken := c.getGameByHost(host); woken == nil || woken.Code != game.Code {
+		t.Errorf("expected the host to take the lobby back")
+	}
+	if len(host.packetIds()) == 0 {
+		t.Errorf("expected the host to be sent the lobby")
+	}
+}
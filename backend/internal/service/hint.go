@@ -0,0 +1,96 @@
+package service
+
+// maxHintCost is the most points a game may charge for a hint
+const maxHintCost = 5000
+
+// HintRequestPacket is sent by a player who buys the hint of the question they are answering
+type HintRequestPacket struct{}
+
+// HintPacket gives a player the hint they bought; it is sent to that player alone
+type HintPacket struct {
+	Question int    `json:"question"` // Index of the question, as in the player's question packet
+	Hint     string `json:"hint"`     // The hint the author attached to the question
+	Cost     int    `json:"cost"`     // Points the hint cost the player
+}
+
+// OnHintRequest sells a player the hint of the question they are answering, once per question.
+// The cost is taken from their points right away; players who cannot afford it are turned down unless negatives are allowed.
+// Parameters:
+// - player: the player asking for the hint
+func (g *Game) OnHintRequest(player *Player) {
+	index, position, ok := g.hintQuestion(player)
+	if !ok {
+		return
+	}
+
+	hint := g.Quiz.Questions[index].Hint
+	if hint == "" {
+		return
+	}
+	if _, bought := player.Hints[index]; bought {
+		return
+	}
+
+	cost := g.Settings.HintCost
+	if player.Points < cost && !g.Settings.AllowNegative {
+		return
+	}
+	if cost > 0 {
+		g.applyToPlayer(player, PointsAwardedEvent{PlayerId: player.Id, Points: -cost})
+	}
+
+	if player.Hints == nil {
+		player.Hints = map[int]int{}
+	}
+	player.Hints[index] = cost
+
+	g.netService.SendPacket(player.Connection, HintPacket{
+		Question: position,
+		Hint:     hint,
+		Cost:     cost,
+	})
+}
+
+// hintQuestion finds the question a player may buy the hint of: the one they are answering, before their
+// answer is final
+// Parameters:
+// - player: the player
+// Returns:
+// - int: the index of the question in the quiz
+// - int: the index of the question as the player sees it, which differs in player-paced games
+// - bool: false if the player is not answering a question of the quiz
+func (g *Game) hintQuestion(player *Player) (int, int, bool) {
+	if g.State != PlayState || player.Eliminated {
+		return 0, 0, false
+	}
+
+	if g.Settings.PlayerPaced {
+		progress := player.paced
+		if progress == nil || progress.done() {
+			return 0, 0, false
+		}
+
+		return progress.order[progress.position], progress.position, true
+	}
+
+	if g.CurrentQuestion < 0 || g.CurrentQuestion >= len(g.Quiz.Questions) {
+		return 0, 0, false
+	}
+	if answer, ok := player.Answers[g.CurrentQuestion]; player.Answered && (!ok || !g.canChangeAnswer(answer)) {
+		return 0, 0, false
+	}
+
+	return g.CurrentQuestion, g.CurrentQuestion, true
+}
+
+// hintPoints returns the points the player spent on hints in the current quiz
+// Returns:
+// - int: the points
+func (p *Player) hintPoints() int {
+	points := 0
+	for _, paid := range p.Hints {
+		points += paid
+	}
+
+	return points
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// hintPacketId is the ID of HintPacket on the wire
+const hintPacketId = 53
+
+// hintPackets decodes the hints sent on a connection
+func hintPackets(t *testing.T, connection *fakeConnection) []HintPacket {
+	t.Helper()
+
+	hints := []HintPacket{}
+	for _, message := range connection.messages {
+		if message[0] != hintPacketId {
+			continue
+		}
+
+		var hint HintPacket
+		if err := json.Unmarshal(packetData(message), &hint); err != nil {
+			t.Fatal(err)
+		}
+		hints = append(hints, hint)
+	}
+
+	return hints
+}
+
+func TestHints(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	c.clock = newFakeClock()
+	quiz := fuzzQuiz()
+	quiz.Questions[0].Hint = "It is the first letter"
+	game := newGame(quiz, &fakeConnection{}, c)
+	aliceCon, bobCon, carolCon := &fakeConnection{}, &fakeConnection{}, &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", aliceCon)
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", bobCon)
+	game.OnPlayerJoin("carol", primitive.NilObjectID, 0, 0, "", carolCon)
+	alice, bob, carol := game.Players[0], game.Players[1], game.Players[2]
+	alice.Points = 500
+	game.OnSettings(GameSettings{HintCost: 200})
+
+	game.OnHintRequest(alice)
+	if alice.Points != 500 || len(hintPackets(t, aliceCon)) != 0 {
+		t.Fatal("expected no hint before the question is shown")
+	}
+
+	// Started by hand, without the timer goroutine of Start
+	game.stopLobby()
+	game.ChangeState(PlayState)
+	game.NextQuestion()
+
+	game.OnHintRequest(alice)
+	game.OnHintRequest(alice)
+	hints := hintPackets(t, aliceCon)
+	if alice.Points != 300 || len(hints) != 1 || hints[0].Hint != "It is the first letter" || hints[0].Cost != 200 {
+		t.Errorf("expected alice to pay for the hint once, got %d points and %+v", alice.Points, hints)
+	}
+	if len(hintPackets(t, bobCon)) != 0 {
+		t.Error("expected the hint to be sent to alice alone")
+	}
+
+	// The hint is not sold after the final answer
+	game.OnPlayerAnswer(1, bob)
+	game.OnHintRequest(bob)
+	if len(hintPackets(t, bobCon)) != 0 {
+		t.Error("expected no hint after answering")
+	}
+
+	// Players who cannot afford the hint only get it when negatives are allowed
+	game.OnHintRequest(carol)
+	if carol.Points != 0 || len(hintPackets(t, carolCon)) != 0 {
+		t.Errorf("expected carol to be turned down without the points, got %d points", carol.Points)
+	}
+	game.Settings.AllowNegative = true
+	game.OnHintRequest(carol)
+	if hints := hintPackets(t, carolCon); carol.Points != -200 || len(hints) != 1 || hints[0].Cost != 200 {
+		t.Errorf("expected carol to pay for the hint into negative points, got %d points and %+v", carol.Points, hints)
+	}
+
+	report := game.buildReport()
+	for _, player := range report.Players {
+		if player.Name == "alice" && (player.Hints != 1 || player.HintPoints != 200) {
+			t.Errorf("expected alice's hint in the report, got %+v", player)
+		}
+	}
+	if report.Questions[0].Hints != 2 || report.Questions[1].Hints != 0 {
+		t.Errorf("expected both hints of the first question in the report, got %+v", report.Questions)
+	}
+
+	game.NextQuestion()
+	game.OnHintRequest(alice)
+	if len(hintPackets(t, aliceCon)) != 1 {
+		t.Error("expected no hint for a question without one")
+	}
+}
+
+func TestHintCostSetting(t *testing.T) {
+	for _, cost := range []int{-1, maxHintCost + 1} {
+		packet := GameSettingsPacket{Settings: GameSettings{HintCost: cost}}
+		if packet.validate() == nil {
+			t.Errorf("expected a hint cost of %d to be turned down", cost)
+		}
+	}
+}
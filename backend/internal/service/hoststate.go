@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// ErrNotHost is returned when a user asks for the state of a game they did not host
+var ErrNotHost = errors.New("not the host of the game")
+
+// HostPlayerState is a player of a game as seen by its host
+type HostPlayerState struct {
+	Id       string `json:"id"`       // Unique identifier for the player
+	Name     string `json:"name"`     // Player's name
+	Avatar   int    `json:"avatar"`   // Index of the player's avatar
+	Color    int    `json:"color"`    // Index of the player's color
+	Points   int    `json:"points"`   // Player's total points
+	Answered bool   `json:"answered"` // Whether the player answered the current question
+	Streak   int    `json:"streak"`   // Number of consecutive correct answers
+}
+
+// HostGameState is a snapshot of a game for its host, so a dashboard can recover without the WebSocket stream
+type HostGameState struct {
+	Code            string            `json:"code"`            // Code for players to join the game
+	Quiz            entity.Quiz       `json:"quiz"`            // The quiz being played
+	State           GameState         `json:"state"`           // Current state of the game
+	CurrentQuestion int               `json:"currentQuestion"` // Index of the current question, -1 before the first
+	Time            int               `json:"time"`            // Seconds left in the current state
+	Paused          bool              `json:"paused"`          // Whether the timer is paused because no players are connected
+	Ended           bool              `json:"ended"`           // Whether the game has finished
+	Settings        GameSettings      `json:"settings"`        // Rules the host chose for the game
+	PlaylistIndex   int               `json:"playlistIndex"`   // Index of the current quiz in the playlist
+	PlaylistTotal   int               `json:"playlistTotal"`   // Number of quizzes in the playlist, 0 for a single quiz
+	Players         []HostPlayerState `json:"players"`         // Players in the game, best first
+	Disconnected    []HostPlayerState `json:"disconnected"`    // Players who lost their connection and may still resume, best first
+}
+
+// GetHostState returns a snapshot of a game for the user who hosted it.
+// Parameters:
+// - code: the join code of the game.
+// - userId: the ID of the authenticated user asking for the state.
+// Returns:
+// - The state of the game, ErrGameNotFound if there is no game with the code,
+// or ErrNotHost if the user did not host it while signed in.
+func (c *NetService) GetHostState(code string, userId primitive.ObjectID) (HostGameState, error) {
+	game := c.getGameByCode(code)
+	if game == nil {
+		return HostGameState{}, ErrGameNotFound
+	}
+
+	var state HostGameState
+	err := ErrNotHost
+	game.run("host state", func() {
+		if game.hostUserId.IsZero() || game.hostUserId != userId {
+			return
+		}
+
+		state = game.getHostState()
+		err = nil
+	})
+
+	return state, err
+}
+
+// getHostState takes a snapshot of the game for its host
+// Returns:
+// - HostGameState: the state of the game
+func (g *Game) getHostState() HostGameState {
+	players := append([]*Player{}, g.Players...)
+	g.sortPlayers(players)
+
+	playerStates := []HostPlayerState{}
+	for _, player := range players {
+		playerStates = append(playerStates, newHostPlayerState(player))
+	}
+
+	return HostGameState{
+		Code:            g.Code,
+		Quiz:            g.Quiz,
+		State:           g.State,
+		CurrentQuestion: g.CurrentQuestion,
+		Time:            g.Time,
+		Paused:          g.Paused,
+		Ended:           g.Ended,
+		Settings:        g.Settings,
+		PlaylistIndex:   g.PlaylistIndex,
+		PlaylistTotal:   len(g.Playlist),
+		Players:         playerStates,
+		Disconnected:    g.getDisconnectedStates(),
+	}
+}
+
+// newHostPlayerState describes a player for the host
+// Parameters:
+// - player: the player
+// Returns:
+// - HostPlayerState: the player as seen by the host
+func newHostPlayerState(player *Player) HostPlayerState {
+	return HostPlayerState{
+		Id:       player.Id.String(),
+		Name:     player.Name,
+		Avatar:   player.Avatar,
+		Color:    player.Color,
+		Points:   player.Points,
+		Answered: player.Answered,
+		Streak:   player.Streak,
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// maxIdleLimit is the largest number of consecutive missed questions a host may allow
+const maxIdleLimit = 10
+
+// PlayerIdlePacket tells the host that a player went idle, came back or was removed for being idle
+type PlayerIdlePacket struct {
+	PlayerId uuid.UUID `json:"playerId"` // ID of the player
+	Missed   int       `json:"missed"`   // Number of consecutive questions the player did not answer
+	Idle     bool      `json:"idle"`     // Whether the player is idle, false once they answer again
+	Removed  bool      `json:"removed"`  // Whether the player was removed from the game
+}
+
+// checkIdlePlayers counts the questions players missed in a row after a reveal and, once a player
+// reaches the idle limit of the settings, marks them idle or removes them from the game
+func (g *Game) checkIdlePlayers() {
+	for _, player := range append([]*Player{}, g.Players...) {
+		if player.Answered || g.hasBuzzed(player) || player.Eliminated {
+			player.missedQuestions = 0
+			continue
+		}
+
+		player.missedQuestions++
+		if g.Settings.IdleLimit == 0 || player.missedQuestions < g.Settings.IdleLimit || player.Idle {
+			continue
+		}
+
+		player.Idle = true
+		g.sendToHost(PlayerIdlePacket{
+			PlayerId: player.Id,
+			Missed:   player.missedQuestions,
+			Idle:     true,
+			Removed:  g.Settings.RemoveIdle,
+		})
+
+		if g.Settings.RemoveIdle {
+			fmt.Println(player.Name, "removed for missing", player.missedQuestions, "questions")
+			g.OnPlayerDisconnect(player)
+			g.netService.CloseConnection(player.Connection, CloseIdle)
+		}
+	}
+}
+
+// markActive clears the idle mark of a player who answered again
+// Parameters:
+// - player: the player who answered
+func (g *Game) markActive(player *Player) {
+	player.missedQuestions = 0
+	if !player.Idle {
+		return
+	}
+
+	player.Idle = false
+	g.sendToHost(PlayerIdlePacket{
+		PlayerId: player.Id,
+	})
+}
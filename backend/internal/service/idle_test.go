@@ -0,0 +1,52 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+const playerIdlePacketId = 31
+
+func TestIdlePlayers(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		c := Net(NetOptions{}, config.Config{})
+		host := &fakeConnection{}
+		game := newGame(fuzzQuiz(), host, c)
+		game.OnSettings(GameSettings{IdleLimit: 2, RemoveIdle: remove})
+
+		idle := &fakeConnection{}
+		game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+		game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", idle)
+		alice, bob := game.Players[0], game.Players[1]
+		game.Start()
+
+		// Bob misses two questions in a row
+		for range 2 {
+			game.OnPlayerAnswer(0, alice)
+			if game.State != PlayState {
+				t.Fatalf("remove %v: expected the question to wait for bob until he is idle", remove)
+			}
+			game.Reveal()
+			game.NextQuestion()
+		}
+
+		if !slices.Contains(host.packetIds(), playerIdlePacketId) {
+			t.Errorf("remove %v: expected the host to be told, got packets %v", remove, host.packetIds())
+		}
+		if remove && (len(game.Players) != 1 || !idle.closed) {
+			t.Errorf("expected bob to be removed, got %d players", len(game.Players))
+		}
+		if !remove && !bob.Idle {
+			t.Errorf("expected bob to be marked idle")
+		}
+
+		// Idle players do not hold up the reveal
+		game.OnPlayerAnswer(1, alice)
+		if game.State != RevealState {
+			t.Errorf("remove %v: expected the reveal once alice answered, got state %d", remove, game.State)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// Limits of the image resizing pipeline. An image takes up to 4 bytes per pixel decoded and again as the RGBA copy
+// it is resized from, so a resize holds up to 128 MB and at most maxImageResizes of them run at once.
+const (
+	maxImagePixels  = 16_000_000 // Larger images are stored but not resized, decoding them would take too much memory
+	maxImageResizes = 2          // Most images resized at the same time, later uploads wait for a turn
+	jpegQuality     = 82         // Quality of renditions of opaque images
+)
+
+// imageResizes holds a slot for each image being resized
+var imageResizes = make(chan struct{}, maxImageResizes)
+
+// imageSizes are the widths images are rendered in, smallest first. Images are never scaled up.
+var imageSizes = []struct {
+	name  string
+	width int
+}{
+	{entity.ThumbnailSize, 160},
+	{entity.MediumSize, 640},
+	{entity.FullSize, 1600},
+}
+
+// resizableTypes are the image formats that can be decoded for resizing. GIFs are left alone to keep their animation,
+// and WebP images are stored and served as uploaded without renditions, as the standard library cannot decode them.
+// Renditions keep the format of the original: there are no WebP renditions, as Go has no WebP encoder without cgo.
+var resizableTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+}
+
+// rendition is a resized and encoded image ready to be stored
+type rendition struct {
+	variant entity.MediaVariant // Description of the rendition
+	data    []byte              // Encoded content
+}
+
+// renderImage renders an image in every size smaller than the original.
+// Images with transparency are encoded as PNG, others as JPEG.
+// Parameters:
+// - ctx: the context bounding the wait for a turn to resize.
+// - data: the encoded original image.
+// - contentType: the detected content type of the original.
+// Returns:
+//   - The renditions, none if the image cannot or need not be resized, and an error if decoding or encoding fails
+//     or the context ends before it is the image's turn.
+func renderImage(ctx context.Context, data []byte, contentType string) ([]rendition, error) {
+	if !resizableTypes[contentType] {
+		return nil, nil
+	}
+
+	// Check the dimensions before decoding, so a tiny file cannot claim a huge canvas
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Width*config.Height > maxImagePixels || config.Width <= imageSizes[0].width {
+		return nil, nil
+	}
+
+	select {
+	case imageResizes <- struct{}{}:
+		defer func() { <-imageResizes }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	original, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Every size is resized from the same copy, the decoded original is not needed past this point
+	opaque := isOpaque(original)
+	pixels := toRGBA(original)
+
+	renditions := []rendition{}
+	for _, size := range imageSizes {
+		if size.width >= config.Width {
+			continue
+		}
+
+		resized := resizeImage(pixels, size.width)
+
+		var encoded bytes.Buffer
+		contentType := "image/jpeg"
+		if opaque {
+			err = jpeg.Encode(&encoded, resized, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			contentType = "image/png"
+			err = png.Encode(&encoded, resized)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		bounds := resized.Bounds()
+		renditions = append(renditions, rendition{
+			variant: entity.MediaVariant{
+				Size:        size.name,
+				Width:       bounds.Dx(),
+				Height:      bounds.Dy(),
+				ContentType: contentType,
+				Bytes:       int64(encoded.Len()),
+				Hash:        hashBytes(encoded.Bytes()),
+			},
+			data: encoded.Bytes(),
+		})
+	}
+
+	return renditions, nil
+}
+
+// toRGBA converts a decoded image to premultiplied pixels, so transparent areas do not bleed their color when
+// resized. Images decoded as RGBA are used as they are.
+// Parameters:
+// - src: the decoded image.
+// Returns:
+// - The pixels, with the origin at zero.
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	if rgba, ok := src.(*image.RGBA); ok && bounds.Min == (image.Point{}) {
+		return rgba
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	return rgba
+}
+
+// resizeImage scales an image down to a width, keeping its aspect ratio. Every target pixel
+// is the average of the source pixels it covers, which keeps downscaled images smooth.
+// Parameters:
+// - rgba: the premultiplied pixels to scale, see toRGBA.
+// - width: the target width, smaller than the width of the image.
+// Returns:
+// - The scaled image.
+func resizeImage(rgba *image.RGBA, width int) *image.NRGBA {
+	bounds := rgba.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	height := max(1, srcHeight*width/srcWidth)
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += uint64(pixel[0])
+					g += uint64(pixel[1])
+					b += uint64(pixel[2])
+					a += uint64(pixel[3])
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.RGBA{
+				R: uint8(r / n),
+				G: uint8(g / n),
+				B: uint8(b / n),
+				A: uint8(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+// isOpaque reports whether an image has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// encodeTestImage encodes a solid image of the given size
+func encodeTestImage(t *testing.T, width int, height int, transparent bool) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	fill := color.NRGBA{R: 200, G: 40, B: 90, A: 255}
+	if transparent {
+		fill.A = 100
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, fill)
+		}
+	}
+
+	var encoded bytes.Buffer
+	var err error
+	if transparent {
+		err = png.Encode(&encoded, img)
+	} else {
+		err = jpeg.Encode(&encoded, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encoded.Bytes()
+}
+
+func TestRenderImage(t *testing.T) {
+	data := encodeTestImage(t, 2000, 1000, false)
+
+	renditions, err := renderImage(context.Background(), data, http.DetectContentType(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][2]int{
+		entity.ThumbnailSize: {160, 80},
+		entity.MediumSize:    {640, 320},
+		entity.FullSize:      {1600, 800},
+	}
+	if len(renditions) != len(expected) {
+		t.Fatalf("expected %d renditions, got %d", len(expected), len(renditions))
+	}
+
+	for _, rendition := range renditions {
+		variant := rendition.variant
+		size := expected[variant.Size]
+		if variant.Width != size[0] || variant.Height != size[1] || variant.ContentType != "image/jpeg" {
+			t.Errorf("unexpected %s rendition %+v", variant.Size, variant)
+		}
+
+		if variant.Bytes != int64(len(rendition.data)) || variant.Hash != hashBytes(rendition.data) {
+			t.Errorf("expected the %s rendition to describe its content", variant.Size)
+		}
+	}
+}
+
+func TestRenderImageKeepsTransparency(t *testing.T) {
+	data := encodeTestImage(t, 800, 800, true)
+
+	renditions, err := renderImage(context.Background(), data, "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the thumbnail and the medium size are smaller than the original
+	if len(renditions) != 2 {
+		t.Fatalf("expected 2 renditions, got %d", len(renditions))
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(renditions[0].data))
+	if err != nil {
+		t.Fatalf("expected a PNG rendition, got %v", err)
+	}
+
+	if _, _, _, a := decoded.At(0, 0).RGBA(); a>>8 != 100 {
+		t.Errorf("expected the alpha channel to be kept, got %d", a>>8)
+	}
+}
+
+func TestRenderImageSkipsUnsupported(t *testing.T) {
+	for _, contentType := range []string{"image/gif", "image/webp", "audio/mpeg"} {
+		renditions, err := renderImage(context.Background(), []byte("not decoded"), contentType)
+		if err != nil || len(renditions) != 0 {
+			t.Errorf("expected %s to be left alone, got %v, %v", contentType, renditions, err)
+		}
+	}
+}
+
+func TestRenderImageWaitsForTurn(t *testing.T) {
+	for i := 0; i < maxImageResizes; i++ {
+		imageResizes <- struct{}{}
+	}
+	defer func() {
+		for i := 0; i < maxImageResizes; i++ {
+			<-imageResizes
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	data := encodeTestImage(t, 800, 400, false)
+	if _, err := renderImage(ctx, data, "image/jpeg"); err != context.Canceled {
+		t.Errorf("expected the upload to give up waiting for a turn, got %v", err)
+	}
+}
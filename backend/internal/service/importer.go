@@ -0,0 +1,471 @@
+package service
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Formats quizzes can be imported from
+const (
+	GiftFormat   = "gift"   // Moodle GIFT text format
+	MoodleFormat = "moodle" // Moodle XML format
+)
+
+// Defaults and limits of imported questions
+const (
+	importQuestionTime = 60 // Time in seconds given to imported questions, the importers carry no timing
+	maxImportChoices   = 4  // Most choices a question can show, one per answer color
+	giftBlankMarker    = "_____"
+)
+
+// Errors returned when a file cannot be imported at all
+var (
+	ErrUnknownImportFormat = errors.New("unknown import format")
+	ErrInvalidImport       = errors.New("invalid import file")
+)
+
+// ImportIssue describes an item of an imported file that could not be converted into a question
+type ImportIssue struct {
+	Item   int    `json:"item"`   // Position of the item in the file, starting at 1
+	Name   string `json:"name"`   // Title or text of the item, as far as it could be read
+	Reason string `json:"reason"` // Why the item was skipped
+}
+
+// ParseQuestions converts a GIFT or Moodle XML file into quiz questions.
+// Items that have no equivalent in a quiz, such as essays or matching questions, are skipped and reported.
+// Parameters:
+// - format: the format of the file, GiftFormat or MoodleFormat
+// - data: the content of the file
+// Returns:
+// - []entity.QuizQuestion: the converted questions
+// - []ImportIssue: the skipped items
+// - error: ErrUnknownImportFormat or ErrInvalidImport if the file cannot be read at all
+func ParseQuestions(format string, data []byte) ([]entity.QuizQuestion, []ImportIssue, error) {
+	switch format {
+	case GiftFormat:
+		questions, issues := parseGift(string(data))
+		return questions, issues, nil
+	case MoodleFormat:
+		return parseMoodleXml(data)
+	}
+
+	return nil, nil, ErrUnknownImportFormat
+}
+
+// importChoice is an answer read from an imported file
+type importChoice struct {
+	text    string
+	correct bool
+}
+
+// newImportQuestion builds a quiz question from an imported item
+// Parameters:
+// - text: the question text
+// - choices: the answers of the item
+// Returns:
+// - entity.QuizQuestion: the question
+// - string: why the item cannot be played as a choice question, empty if it can
+func newImportQuestion(text string, choices []importChoice) (entity.QuizQuestion, string) {
+	question := entity.QuizQuestion{
+		Id:      uuid.NewString(),
+		Name:    text,
+		Time:    importQuestionTime,
+		Choices: []entity.QuizChoice{},
+		Media:   []entity.QuestionMedia{},
+	}
+
+	if text == "" {
+		return question, "the question has no text"
+	}
+
+	if len(choices) < 2 {
+		return question, "choice questions need at least two answers"
+	}
+
+	if len(choices) > maxImportChoices {
+		return question, fmt.Sprintf("choice questions can have at most %d answers", maxImportChoices)
+	}
+
+	anyCorrect := false
+	for _, choice := range choices {
+		anyCorrect = anyCorrect || choice.correct
+		question.Choices = append(question.Choices, entity.QuizChoice{
+			Id:      uuid.NewString(),
+			Name:    choice.text,
+			Correct: choice.correct,
+		})
+	}
+
+	if !anyCorrect {
+		return question, "no answer is marked as correct"
+	}
+
+	return question, ""
+}
+
+// giftFormatMarker matches the text format marker GIFT allows in front of a question, such as [html]
+var giftFormatMarker = regexp.MustCompile(`^\[(html|moodle|plain|markdown)\]`)
+
+// parseGift converts a GIFT file into quiz questions
+// Parameters:
+// - data: the content of the file
+// Returns:
+// - []entity.QuizQuestion: the converted questions
+// - []ImportIssue: the skipped items
+func parseGift(data string) ([]entity.QuizQuestion, []ImportIssue) {
+	questions := []entity.QuizQuestion{}
+	issues := []ImportIssue{}
+
+	for i, item := range splitGiftItems(data) {
+		question, reason := parseGiftItem(item)
+		if reason != "" {
+			issues = append(issues, ImportIssue{
+				Item:   i + 1,
+				Name:   question.Name,
+				Reason: reason,
+			})
+			continue
+		}
+
+		questions = append(questions, question)
+	}
+
+	return questions, issues
+}
+
+// splitGiftItems splits a GIFT file into its items, which are separated by blank lines.
+// Comments and category declarations are dropped.
+// Parameters:
+// - data: the content of the file
+// Returns:
+// - []string: the items
+func splitGiftItems(data string) []string {
+	items := []string{}
+	current := []string{}
+	flush := func() {
+		if len(current) > 0 {
+			items = append(items, strings.Join(current, "\n"))
+			current = []string{}
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "//"):
+		case strings.HasPrefix(trimmed, "$CATEGORY:"):
+			flush()
+		default:
+			current = append(current, trimmed)
+		}
+	}
+	flush()
+
+	return items
+}
+
+// parseGiftItem converts a single GIFT item into a quiz question
+// Parameters:
+// - item: the item
+// Returns:
+// - entity.QuizQuestion: the question, whose name is set even if the item cannot be converted
+// - string: why the item cannot be converted, empty if it can
+func parseGiftItem(item string) (entity.QuizQuestion, string) {
+	// An optional title is written as ::title::
+	title := ""
+	if strings.HasPrefix(item, "::") {
+		if end := indexUnescaped(item[2:], "::"); end >= 0 {
+			title = unescapeGift(strings.TrimSpace(item[2 : 2+end]))
+			item = strings.TrimSpace(item[4+end:])
+		}
+	}
+
+	open := indexUnescaped(item, "{")
+	closing := -1
+	if open >= 0 {
+		if end := indexUnescaped(item[open:], "}"); end >= 0 {
+			closing = open + end
+		}
+	}
+
+	if open < 0 || closing < 0 {
+		return entity.QuizQuestion{Name: title}, "descriptions without answers cannot be played"
+	}
+
+	// Text after the answers turns the question into a fill in the blank
+	before := strings.TrimSpace(item[:open])
+	after := strings.TrimSpace(item[closing+1:])
+	text := before
+	if after != "" {
+		text = strings.TrimSpace(before + " " + giftBlankMarker + " " + after)
+	}
+	text = cleanImportText(unescapeGift(giftFormatMarker.ReplaceAllString(text, "")))
+
+	name := text
+	if name == "" {
+		name = title
+	}
+
+	body := strings.TrimSpace(item[open+1 : closing])
+	if body == "" {
+		return entity.QuizQuestion{Name: name}, "essay questions cannot be played"
+	}
+
+	if strings.HasPrefix(body, "#") {
+		return entity.QuizQuestion{Name: name}, "numerical questions cannot be played"
+	}
+
+	// True/false answers may be followed by feedback
+	verdict := body
+	if feedback := indexUnescaped(body, "#"); feedback >= 0 {
+		verdict = strings.TrimSpace(body[:feedback])
+	}
+
+	switch strings.ToUpper(verdict) {
+	case "T", "TRUE":
+		return newImportQuestion(text, trueFalseChoices(true))
+	case "F", "FALSE":
+		return newImportQuestion(text, trueFalseChoices(false))
+	}
+
+	choices := []importChoice{}
+	wrong := 0
+	for _, answer := range splitGiftAnswers(body) {
+		if indexUnescaped(answer, "->") >= 0 {
+			return entity.QuizQuestion{Name: name}, "matching questions cannot be played"
+		}
+
+		correct := answer[0] == '='
+		answer = strings.TrimSpace(answer[1:])
+
+		// Weighted answers such as ~%50% count as correct when they earn points
+		if strings.HasPrefix(answer, "%") {
+			if end := strings.Index(answer[1:], "%"); end >= 0 {
+				weight, err := strconv.ParseFloat(answer[1:1+end], 64)
+				if err == nil {
+					correct = weight > 0
+				}
+				answer = strings.TrimSpace(answer[2+end:])
+			}
+		}
+
+		if feedback := indexUnescaped(answer, "#"); feedback >= 0 {
+			answer = strings.TrimSpace(answer[:feedback])
+		}
+
+		if !correct {
+			wrong++
+		}
+
+		choices = append(choices, importChoice{
+			text:    cleanImportText(unescapeGift(answer)),
+			correct: correct,
+		})
+	}
+
+	// Answers without any wrong alternative are short answers that players would have to type
+	if len(choices) > 0 && wrong == 0 {
+		return entity.QuizQuestion{Name: name}, "short answer questions without wrong answers cannot be played as a choice question"
+	}
+
+	return newImportQuestion(text, choices)
+}
+
+// splitGiftAnswers splits the answer block of a GIFT item at every unescaped = or ~
+// Parameters:
+// - body: the text between the braces
+// Returns:
+// - []string: the answers, each starting with = or ~
+func splitGiftAnswers(body string) []string {
+	answers := []string{}
+	start := -1
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if start >= 0 {
+				answers = append(answers, strings.TrimSpace(body[start:i]))
+			}
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		answers = append(answers, strings.TrimSpace(body[start:]))
+	}
+
+	return answers
+}
+
+// indexUnescaped returns the index of the first occurrence of sub in s that is not escaped with a backslash
+// Parameters:
+// - s: the text to search
+// - sub: the text to find
+// Returns:
+// - int: the index of sub, or -1 if it does not occur
+func indexUnescaped(s string, sub string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// giftEscapes undoes the escaping of GIFT special characters
+var giftEscapes = strings.NewReplacer(`\~`, "~", `\=`, "=", `\#`, "#", `\{`, "{", `\}`, "}", `\:`, ":", `\n`, "\n", `\\`, `\`)
+
+// unescapeGift removes the backslashes in front of GIFT special characters
+func unescapeGift(s string) string {
+	return giftEscapes.Replace(s)
+}
+
+// trueFalseChoices returns the two answers of a true/false question
+// Parameters:
+// - answer: the correct answer
+// Returns:
+// - []importChoice: the True and False choices
+func trueFalseChoices(answer bool) []importChoice {
+	return []importChoice{
+		{text: "True", correct: answer},
+		{text: "False", correct: !answer},
+	}
+}
+
+// HTML tags, which quizzes cannot display. Inline tags sit within words, the others separate them.
+var (
+	inlineHtmlTag = regexp.MustCompile(`(?i)</?(a|b|em|i|span|strong|sub|sup|u)\b[^>]*>`)
+	htmlTag       = regexp.MustCompile(`<[^>]*>`)
+)
+
+// cleanImportText turns imported rich text into the plain text quizzes show
+// Parameters:
+// - s: the imported text
+// Returns:
+// - string: the text without HTML tags and entities, on a single line
+func cleanImportText(s string) string {
+	s = inlineHtmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// moodleQuiz is the root element of a Moodle XML file
+type moodleQuiz struct {
+	Questions []moodleQuestion `xml:"question"`
+}
+
+// moodleQuestion is a question of a Moodle XML file
+type moodleQuestion struct {
+	Type         string         `xml:"type,attr"`
+	Name         string         `xml:"name>text"`
+	QuestionText string         `xml:"questiontext>text"`
+	Answers      []moodleAnswer `xml:"answer"`
+}
+
+// moodleAnswer is an answer of a Moodle XML question
+type moodleAnswer struct {
+	Fraction string `xml:"fraction,attr"`
+	Text     string `xml:"text"`
+}
+
+// parseMoodleXml converts a Moodle XML file into quiz questions
+// Parameters:
+// - data: the content of the file
+// Returns:
+// - []entity.QuizQuestion: the converted questions
+// - []ImportIssue: the skipped items
+// - error: ErrInvalidImport if the file is not a Moodle XML quiz
+func parseMoodleXml(data []byte) ([]entity.QuizQuestion, []ImportIssue, error) {
+	var quiz moodleQuiz
+	if err := xml.Unmarshal(data, &quiz); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	questions := []entity.QuizQuestion{}
+	issues := []ImportIssue{}
+	for i, item := range quiz.Questions {
+		// Categories only group the questions in Moodle's question bank
+		if item.Type == "category" {
+			continue
+		}
+
+		question, reason := parseMoodleQuestion(item)
+		if reason != "" {
+			issues = append(issues, ImportIssue{
+				Item:   i + 1,
+				Name:   question.Name,
+				Reason: reason,
+			})
+			continue
+		}
+
+		questions = append(questions, question)
+	}
+
+	return questions, issues, nil
+}
+
+// parseMoodleQuestion converts a single Moodle XML question into a quiz question
+// Parameters:
+// - item: the question
+// Returns:
+// - entity.QuizQuestion: the question, whose name is set even if the item cannot be converted
+// - string: why the item cannot be converted, empty if it can
+func parseMoodleQuestion(item moodleQuestion) (entity.QuizQuestion, string) {
+	text := cleanImportText(item.QuestionText)
+	name := text
+	if name == "" {
+		name = cleanImportText(item.Name)
+	}
+
+	choices := []importChoice{}
+	wrong := 0
+	for _, answer := range item.Answers {
+		fraction, _ := strconv.ParseFloat(answer.Fraction, 64)
+		if fraction <= 0 {
+			wrong++
+		}
+
+		choices = append(choices, importChoice{
+			text:    cleanImportText(answer.Text),
+			correct: fraction > 0,
+		})
+	}
+
+	switch item.Type {
+	case "multichoice":
+		return newImportQuestion(text, choices)
+	case "truefalse":
+		// Moodle writes the answers in lower case
+		for i, choice := range choices {
+			if choice.text != "" {
+				choices[i].text = strings.ToUpper(choice.text[:1]) + choice.text[1:]
+			}
+		}
+		return newImportQuestion(text, choices)
+	case "shortanswer":
+		if wrong == 0 {
+			return entity.QuizQuestion{Name: name}, "short answer questions without wrong answers cannot be played as a choice question"
+		}
+		return newImportQuestion(text, choices)
+	}
+
+	return entity.QuizQuestion{Name: name}, fmt.Sprintf("%s questions cannot be played", item.Type)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseGift(t *testing.T) {
+	data := `// Geography
+$CATEGORY: $course$/Europe
+
+::Capital::What is the capital of France? {=Paris ~London ~Berlin#Not quite}
+
+The sun is a star.{T}
+
+Two plus two equals {~3 =4 ~%50%four ~5} in decimal.
+
+Write an essay about rivers.{}
+
+Which planet is red? {=Mars =mars}
+
+How many legs does a spider have? {#8}
+
+Match the capitals. {=France -> Paris =Italy -> Rome}
+
+Escaped \{braces\} are text. {=yes ~no}`
+
+	questions, issues := parseGift(data)
+	if len(questions) != 4 {
+		t.Fatalf("expected 4 questions, got %d: %+v", len(questions), questions)
+	}
+
+	capital := questions[0]
+	if capital.Name != "What is the capital of France?" || len(capital.Choices) != 3 {
+		t.Errorf("unexpected multiple choice question %+v", capital)
+	}
+	if !capital.Choices[0].Correct || capital.Choices[1].Correct || capital.Choices[2].Name != "Berlin" {
+		t.Errorf("unexpected choices %+v", capital.Choices)
+	}
+
+	if sun := questions[1]; sun.Choices[0].Name != "True" || !sun.Choices[0].Correct || sun.Choices[1].Correct {
+		t.Errorf("unexpected true/false question %+v", sun)
+	}
+
+	// Weighted answers earning points are correct, and the blank marks where the answer goes
+	sum := questions[2]
+	if sum.Name != "Two plus two equals _____ in decimal." || !sum.Choices[1].Correct || !sum.Choices[2].Correct || sum.Choices[3].Correct {
+		t.Errorf("unexpected fill in the blank question %+v", sum)
+	}
+
+	if escaped := questions[3]; escaped.Name != "Escaped {braces} are text." {
+		t.Errorf("expected escapes to be removed, got %q", escaped.Name)
+	}
+
+	// The essay, short answer, numerical and matching items are reported in file order
+	items := []int{}
+	for _, issue := range issues {
+		items = append(items, issue.Item)
+	}
+	if len(items) != 4 || items[0] != 4 || items[1] != 5 || items[2] != 6 || items[3] != 7 {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+}
+
+func TestParseMoodleXml(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<quiz>
+  <question type="category"><category><text>$course$/Europe</text></category></question>
+  <question type="multichoice">
+    <name><text>Capital</text></name>
+    <questiontext format="html"><text><![CDATA[<p>What is the capital of <b>France</b>?</p>]]></text></questiontext>
+    <answer fraction="100"><text>Paris</text></answer>
+    <answer fraction="0"><text>London</text></answer>
+  </question>
+  <question type="truefalse">
+    <name><text>Sun</text></name>
+    <questiontext format="html"><text>The sun is a star.</text></questiontext>
+    <answer fraction="100"><text>true</text></answer>
+    <answer fraction="0"><text>false</text></answer>
+  </question>
+  <question type="shortanswer">
+    <name><text>Planet</text></name>
+    <questiontext format="html"><text>Which planet is red?</text></questiontext>
+    <answer fraction="100"><text>Mars</text></answer>
+  </question>
+  <question type="essay">
+    <name><text>Rivers</text></name>
+    <questiontext format="html"><text>Write about rivers.</text></questiontext>
+  </question>
+</quiz>`
+
+	questions, issues, err := ParseQuestions(MoodleFormat, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(questions) != 2 {
+		t.Fatalf("expected 2 questions, got %d: %+v", len(questions), questions)
+	}
+	if questions[0].Name != "What is the capital of France?" {
+		t.Errorf("expected the HTML to be stripped, got %q", questions[0].Name)
+	}
+	if sun := questions[1]; sun.Choices[0].Name != "True" || !sun.Choices[0].Correct {
+		t.Errorf("unexpected true/false question %+v", sun)
+	}
+
+	if len(issues) != 2 || issues[0].Item != 4 || issues[1].Item != 5 || issues[1].Name != "Write about rivers." {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+
+	if _, _, err := ParseQuestions(MoodleFormat, []byte("not xml")); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("expected ErrInvalidImport, got %v", err)
+	}
+	if _, _, err := ParseQuestions("qti", nil); !errors.Is(err, ErrUnknownImportFormat) {
+		t.Errorf("expected ErrUnknownImportFormat, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Limits of invitations
+const (
+	maxInvitations          = 50              // Highest number of addresses invited at once
+	maxEmailLength          = 254             // Longest valid email address
+	maxInvitationNameLength = 60              // Most characters of the quiz or tournament name put into an email
+	invitationSendTime      = 2 * time.Minute // Time allowed for sending a whole batch
+	invitationWindow        = 24 * time.Hour  // Window the emails of each sender are counted in
+)
+
+// ErrInvalidInvitation is returned when a batch of invitations has no or too many valid addresses
+var ErrInvalidInvitation = errors.New("invalid invitation")
+
+// invitationMessage holds the values filled into an invitation email
+type invitationMessage struct {
+	Name string // Name of the quiz or tournament
+	Code string // Join code, empty for tournaments
+	Link string // Link opening the game or tournament
+}
+
+// Templates of the invitation emails
+var (
+	gameInvitationTemplate = template.Must(template.New("game").Parse(`You have been invited to play "{{.Name}}".
+
+Join with the code {{.Code}} or open this link:
+{{.Link}}
+`))
+
+	tournamentInvitationTemplate = template.Must(template.New("tournament").Parse(`You have been invited to the tournament "{{.Name}}".
+
+When a round starts, join it here with the code your host shares:
+{{.Link}}
+`))
+)
+
+// InvitationService provides methods for inviting people to games and tournaments by email and tracking delivery.
+type InvitationService struct {
+	invitationCollection *collection.InvitationCollection // Reference to the invitation collection for database operations
+	mailService          *MailService                     // Service used to send the emails
+	publicUrl            string                           // Base URL of the frontend, used in links
+	senders              *rateLimiter                     // Emails sent per sender, so the server cannot be used to send mail in bulk
+}
+
+// Invitation initializes and returns a new InvitationService instance.
+// Parameters:
+// - invitationCollection: the collection that interacts with the invitations in the database.
+// - mailService: the service used to send the emails.
+// - config: the runtime configuration, providing the public URL of the frontend and the daily limit of each sender.
+func Invitation(invitationCollection *collection.InvitationCollection, mailService *MailService, config config.Config) *InvitationService {
+	return &InvitationService{
+		invitationCollection: invitationCollection,
+		mailService:          mailService,
+		publicUrl:            strings.TrimSuffix(config.PublicUrl, "/"),
+		senders:              newRateLimiter(config.InvitationLimit, invitationWindow),
+	}
+}
+
+// InviteToGame emails the join code of a game waiting in the lobby. The emails are
+// sent in the background; onUpdate is called with every invitation once they are
+// created and again after each delivery attempt.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - sender: who sends the invitations, the key of their daily limit, e.g. the host's account or address.
+// - code: the join code of the game.
+// - quizName: the name of the quiz being played.
+// - emails: the addresses to invite.
+// - onUpdate: called with the current state of the invitations as they are sent.
+// Returns:
+// - The pending invitations, ErrInvalidInvitation if the addresses are invalid and a RateLimitError if the sender used up their daily emails.
+func (s InvitationService) InviteToGame(ctx context.Context, sender string, code string, quizName string, emails []string, onUpdate func([]entity.Invitation)) ([]entity.Invitation, error) {
+	invitations, err := s.createInvitations(ctx, sender, emails, code, primitive.NilObjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := invitationName(quizName)
+	body, err := renderInvitation(gameInvitationTemplate, invitationMessage{
+		Name: name,
+		Code: code,
+		Link: s.publicUrl + "/?code=" + url.QueryEscape(code),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	onUpdate(append([]entity.Invitation(nil), invitations...))
+	go s.deliver(invitations, "Join "+name+" with code "+code, body, onUpdate)
+	return invitations, nil
+}
+
+// InviteToTournament emails a link to a tournament. The emails are sent in the background.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - sender: who sends the invitations, the key of their daily limit, e.g. the owner's account.
+// - tournament: the tournament to invite to.
+// - emails: the addresses to invite.
+// Returns:
+// - The pending invitations, ErrInvalidInvitation if the addresses are invalid and a RateLimitError if the sender used up their daily emails.
+func (s InvitationService) InviteToTournament(ctx context.Context, sender string, tournament entity.Tournament, emails []string) ([]entity.Invitation, error) {
+	invitations, err := s.createInvitations(ctx, sender, emails, "", tournament.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := invitationName(tournament.Name)
+	body, err := renderInvitation(tournamentInvitationTemplate, invitationMessage{
+		Name: name,
+		Link: s.publicUrl + "/",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go s.deliver(invitations, "You are invited to "+name, body, nil)
+	return invitations, nil
+}
+
+// GetTournamentInvitations retrieves the invitations to a tournament and their delivery state.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - tournamentId: the ObjectID of the tournament.
+// Returns:
+// - The invitations, oldest first, and an error if something goes wrong.
+func (s InvitationService) GetTournamentInvitations(ctx context.Context, tournamentId primitive.ObjectID) ([]entity.Invitation, error) {
+	return s.invitationCollection.GetInvitationsByTournament(ctx, tournamentId)
+}
+
+// createInvitations validates the addresses, counts them against the sender's daily limit and stores a pending invitation for each.
+func (s InvitationService) createInvitations(ctx context.Context, sender string, emails []string, code string, tournamentId primitive.ObjectID) ([]entity.Invitation, error) {
+	addresses, err := parseEmails(emails)
+	if err != nil {
+		return nil, err
+	}
+
+	if wait := s.senders.allowN(sender, len(addresses), time.Now()); wait > 0 {
+		return nil, RateLimitError{RetryAfter: wait}
+	}
+
+	now := time.Now()
+	invitations := []entity.Invitation{}
+	for _, address := range addresses {
+		invitations = append(invitations, entity.Invitation{
+			Id:           primitive.NewObjectID(),
+			Email:        address,
+			Code:         code,
+			TournamentId: tournamentId,
+			Status:       entity.InvitationPending,
+			CreatedAt:    now,
+		})
+	}
+
+	if err := s.invitationCollection.InsertInvitations(ctx, invitations); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// deliver sends the invitation emails one after another, recording the outcome of each.
+func (s InvitationService) deliver(invitations []entity.Invitation, subject string, body string, onUpdate func([]entity.Invitation)) {
+	ctx, cancel := context.WithTimeout(context.Background(), invitationSendTime)
+	defer cancel()
+
+	for i := range invitations {
+		invitation := &invitations[i]
+		if err := s.mailService.Send(invitation.Email, subject, body); err != nil {
+			invitation.Status = entity.InvitationFailed
+			invitation.Error = err.Error()
+		} else {
+			invitation.Status = entity.InvitationSent
+			invitation.SentAt = time.Now()
+		}
+
+		if err := s.invitationCollection.UpdateInvitation(ctx, *invitation); err != nil {
+			fmt.Println("failed to update invitation", invitation.Id.Hex(), ":", err)
+		}
+
+		if onUpdate != nil {
+			onUpdate(append([]entity.Invitation(nil), invitations...))
+		}
+	}
+}
+
+// parseEmails validates and normalizes a list of addresses, dropping duplicates.
+// Parameters:
+// - emails: the addresses entered by the host.
+// Returns:
+// - The normalized addresses and ErrInvalidInvitation if any is invalid or there are none or too many.
+func parseEmails(emails []string) ([]string, error) {
+	if len(emails) == 0 || len(emails) > maxInvitations {
+		return nil, ErrInvalidInvitation
+	}
+
+	seen := map[string]bool{}
+	addresses := []string{}
+	for _, email := range emails {
+		if len(email) > maxEmailLength {
+			return nil, ErrInvalidInvitation
+		}
+
+		address, err := mail.ParseAddress(strings.TrimSpace(email))
+		if err != nil {
+			return nil, ErrInvalidInvitation
+		}
+
+		normalized := strings.ToLower(address.Address)
+		if seen[normalized] {
+			continue
+		}
+
+		seen[normalized] = true
+		addresses = append(addresses, normalized)
+	}
+
+	return addresses, nil
+}
+
+// invitationName makes the name of a quiz or tournament, chosen by whoever sends the invitations, fit for an email:
+// control characters and runs of spaces are collapsed and long names are cut short.
+// Parameters:
+// - name: the name.
+// Returns:
+// - The name to put into the subject and body.
+func invitationName(name string) string {
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}), " ")
+
+	if runes := []rune(name); len(runes) > maxInvitationNameLength {
+		name = string(runes[:maxInvitationNameLength-1]) + "…"
+	}
+
+	return name
+}
+
+// renderInvitation fills an invitation template.
+func renderInvitation(tmpl *template.Template, message invitationMessage) (string, error) {
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, message); err != nil {
+		return "", err
+	}
+
+	return body.String(), nil
+}
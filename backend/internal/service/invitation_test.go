@@ -0,0 +1,77 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEmails(t *testing.T) {
+	addresses, err := parseEmails([]string{"Alice@Example.com", " bob@example.com ", "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"alice@example.com", "bob@example.com"}; !slices.Equal(addresses, want) {
+		t.Errorf("got %v, want %v", addresses, want)
+	}
+
+	for _, emails := range [][]string{nil, {"not an address"}, {strings.Repeat("a", maxEmailLength) + "@example.com"}} {
+		if _, err := parseEmails(emails); err != ErrInvalidInvitation {
+			t.Errorf("expected %v to be rejected, got %v", emails, err)
+		}
+	}
+}
+
+func TestRenderGameInvitation(t *testing.T) {
+	body, err := renderInvitation(gameInvitationTemplate, invitationMessage{
+		Name: "Capitals",
+		Code: "123456",
+		Link: "http://localhost:5173/?code=123456",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{`"Capitals"`, "code 123456", "http://localhost:5173/?code=123456"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the email to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestInvitationName(t *testing.T) {
+	if got := invitationName("Capitals\r\nBcc: everyone@example.com\t quiz"); got != "Capitals Bcc: everyone@example.com quiz" {
+		t.Errorf("expected line breaks to be collapsed, got %q", got)
+	}
+
+	if got := []rune(invitationName(strings.Repeat("é", 200))); len(got) != maxInvitationNameLength {
+		t.Errorf("expected the name to be cut to %d characters, got %d", maxInvitationNameLength, len(got))
+	}
+}
+
+func TestInvitationsPerSender(t *testing.T) {
+	limiter := newRateLimiter(5, invitationWindow)
+	now := time.Now()
+
+	if wait := limiter.allowN("user:alice", 4, now); wait != 0 {
+		t.Fatalf("expected the first batch to be allowed, got %v", wait)
+	}
+
+	if wait := limiter.allowN("user:alice", 2, now); wait <= 0 {
+		t.Error("expected a batch over the daily limit to be refused")
+	}
+
+	if wait := limiter.allowN("user:alice", 1, now); wait != 0 {
+		t.Errorf("expected a refused batch not to count against the limit, got %v", wait)
+	}
+
+	if wait := limiter.allowN("user:bob", 5, now); wait != 0 {
+		t.Errorf("expected other senders to have their own limit, got %v", wait)
+	}
+
+	if wait := limiter.allowN("user:alice", 5, now.Add(invitationWindow)); wait != 0 {
+		t.Errorf("expected the limit to reset after a day, got %v", wait)
+	}
+}
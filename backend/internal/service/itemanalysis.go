@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// minDiscriminationPlayers is the number of players needed to compare a top and a bottom group
+const minDiscriminationPlayers = 4
+
+// ItemAnalysis is the classical test analysis of a quiz over all games played of it
+type ItemAnalysis struct {
+	Games   int             `json:"games"`   // Number of games the analysis covers
+	Players int             `json:"players"` // Number of players who answered at least one question
+	Items   []ItemStatistic `json:"items"`   // Analysis of each question, in quiz order
+}
+
+// ItemStatistic is the analysis of a single question
+type ItemStatistic struct {
+	QuestionId     string            `json:"questionId"`     // ID of the question in the quiz
+	Name           string            `json:"name"`           // The text of the question
+	Answered       int               `json:"answered"`       // Number of answers given
+	Difficulty     float64           `json:"difficulty"`     // Difficulty index, the share of correct answers; higher is easier
+	Discrimination float64           `json:"discrimination"` // Share correct in the top quartile minus share correct in the bottom quartile
+	Choices        []ChoiceStatistic `json:"choices"`        // How often each choice was picked
+}
+
+// ChoiceStatistic is the distractor analysis of a single choice
+type ChoiceStatistic struct {
+	Name        string  `json:"name"`        // The text of the choice
+	Correct     bool    `json:"correct"`     // Whether the choice is a correct answer
+	Picks       int     `json:"picks"`       // Number of players who picked the choice
+	Share       float64 `json:"share"`       // Share of the answers that picked the choice
+	TopPicks    int     `json:"topPicks"`    // Picks by players in the top quartile
+	BottomPicks int     `json:"bottomPicks"` // Picks by players in the bottom quartile
+}
+
+// GetItemAnalysis analyses the questions of a quiz over all games played of it.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quiz: the quiz to analyse.
+// Returns:
+// - The analysis and an error if the results cannot be read.
+func (s ResultService) GetItemAnalysis(ctx context.Context, quiz entity.Quiz) (ItemAnalysis, error) {
+	results, err := s.resultCollection.GetResultsByQuiz(ctx, quiz.Id)
+	if err != nil {
+		return ItemAnalysis{}, err
+	}
+
+	return analyzeItems(quiz, results), nil
+}
+
+// analyzeItems computes the difficulty, discrimination and distractor analysis of every question.
+// Players of all games are pooled and ranked by their number of correct answers; the top and bottom
+// quartiles of that ranking are compared for the discrimination index.
+// Parameters:
+// - quiz: the quiz the results belong to
+// - results: the results of the games played of the quiz
+// Returns:
+// - ItemAnalysis: the analysis
+func analyzeItems(quiz entity.Quiz, results []entity.GameResult) ItemAnalysis {
+	players := []entity.PlayerResult{}
+	for _, result := range results {
+		for _, player := range result.Players {
+			if len(player.Answers) > 0 {
+				players = append(players, player)
+			}
+		}
+	}
+
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].Correct > players[j].Correct
+	})
+
+	quartile := 0
+	if len(players) >= minDiscriminationPlayers {
+		quartile = len(players) / 4
+	}
+	top := players[:quartile]
+	bottom := players[len(players)-quartile:]
+
+	items := []ItemStatistic{}
+	for _, question := range quiz.Questions {
+		item := ItemStatistic{
+			QuestionId: question.Id,
+			Name:       question.Name,
+			Choices:    []ChoiceStatistic{},
+		}
+		for _, choice := range question.Choices {
+			item.Choices = append(item.Choices, ChoiceStatistic{
+				Name:    choice.Name,
+				Correct: choice.Correct,
+			})
+		}
+
+		correct := 0
+		for _, player := range players {
+			if answer, ok := findAnswer(player, question.Id); ok {
+				item.Answered++
+				if answer.Correct {
+					correct++
+				}
+				if answer.Choice >= 0 && answer.Choice < len(item.Choices) {
+					item.Choices[answer.Choice].Picks++
+				}
+			}
+		}
+
+		for _, player := range top {
+			if answer, ok := findAnswer(player, question.Id); ok && answer.Choice >= 0 && answer.Choice < len(item.Choices) {
+				item.Choices[answer.Choice].TopPicks++
+			}
+		}
+		for _, player := range bottom {
+			if answer, ok := findAnswer(player, question.Id); ok && answer.Choice >= 0 && answer.Choice < len(item.Choices) {
+				item.Choices[answer.Choice].BottomPicks++
+			}
+		}
+
+		if item.Answered > 0 {
+			item.Difficulty = float64(correct) / float64(item.Answered)
+			for i := range item.Choices {
+				item.Choices[i].Share = float64(item.Choices[i].Picks) / float64(item.Answered)
+			}
+		}
+
+		if quartile > 0 {
+			item.Discrimination = shareCorrect(top, question.Id) - shareCorrect(bottom, question.Id)
+		}
+
+		items = append(items, item)
+	}
+
+	return ItemAnalysis{
+		Games:   len(results),
+		Players: len(players),
+		Items:   items,
+	}
+}
+
+// findAnswer looks up a player's answer to a question
+// Parameters:
+// - player: the player's result
+// - questionId: the ID of the question
+// Returns:
+// - entity.AnswerResult: the answer
+// - bool: false if the player did not answer the question
+func findAnswer(player entity.PlayerResult, questionId string) (entity.AnswerResult, bool) {
+	for _, answer := range player.Answers {
+		if answer.QuestionId == questionId {
+			return answer, true
+		}
+	}
+
+	return entity.AnswerResult{}, false
+}
+
+// shareCorrect returns the share of a group of players who answered a question correctly, counting
+// players who did not answer it as wrong
+// Parameters:
+// - players: the group of players, not empty
+// - questionId: the ID of the question
+// Returns:
+// - float64: the share between 0 and 1
+func shareCorrect(players []entity.PlayerResult, questionId string) float64 {
+	correct := 0
+	for _, player := range players {
+		if answer, ok := findAnswer(player, questionId); ok && answer.Correct {
+			correct++
+		}
+	}
+
+	return float64(correct) / float64(len(players))
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestAnalyzeItems(t *testing.T) {
+	quiz := entity.Quiz{Questions: []entity.QuizQuestion{{
+		Id:      "q1",
+		Name:    "One",
+		Choices: []entity.QuizChoice{{Name: "Right", Correct: true}, {Name: "Wrong"}},
+	}}}
+
+	answer := func(choice int) []entity.AnswerResult {
+		return []entity.AnswerResult{{QuestionId: "q1", Choice: choice, Correct: choice == 0}}
+	}
+	results := []entity.GameResult{
+		{Players: []entity.PlayerResult{
+			{Name: "a", Correct: 5, Answers: answer(0)},
+			{Name: "b", Correct: 4, Answers: answer(0)},
+			{Name: "c", Correct: 1, Answers: answer(1)},
+		}},
+		{Players: []entity.PlayerResult{
+			{Name: "d", Correct: 0, Answers: answer(1)},
+			{Name: "e"},
+		}},
+	}
+
+	analysis := analyzeItems(quiz, results)
+	if analysis.Games != 2 || analysis.Players != 4 {
+		t.Fatalf("expected 2 games and 4 answering players, got %+v", analysis)
+	}
+
+	item := analysis.Items[0]
+	if item.Answered != 4 || item.Difficulty != 0.5 {
+		t.Errorf("unexpected difficulty: %+v", item)
+	}
+	if item.Discrimination != 1 {
+		t.Errorf("expected the best player right and the worst wrong, got %v", item.Discrimination)
+	}
+	if wrong := item.Choices[1]; wrong.Picks != 2 || wrong.Share != 0.5 || wrong.TopPicks != 0 || wrong.BottomPicks != 1 {
+		t.Errorf("unexpected distractor analysis: %+v", wrong)
+	}
+}
+
+func TestAnalyzeItemsTooFewPlayers(t *testing.T) {
+	quiz := entity.Quiz{Questions: []entity.QuizQuestion{{Id: "q1"}}}
+	results := []entity.GameResult{{Players: []entity.PlayerResult{
+		{Correct: 1, Answers: []entity.AnswerResult{{QuestionId: "q1", Correct: true}}},
+	}}}
+
+	if item := analyzeItems(quiz, results).Items[0]; item.Discrimination != 0 || item.Difficulty != 1 {
+		t.Errorf("expected no discrimination without quartiles, got %+v", item)
+	}
+}
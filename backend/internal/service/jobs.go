@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// States of a background job
+const (
+	JobQueued  = "queued"  // Waiting for a free worker
+	JobRunning = "running" // Being worked on
+	JobDone    = "done"    // Finished, its result can be downloaded
+	JobFailed  = "failed"  // Gave up with an error
+)
+
+// Limits of background jobs
+const (
+	jobTimeout          = 10 * time.Minute       // Longest a single job may run
+	jobProgressInterval = 500 * time.Millisecond // Progress is stored at most this often, so reporting it stays cheap
+)
+
+// Errors returned by the job queue
+var (
+	ErrJobQueueFull = errors.New("job queue full")
+	ErrJobNotFound  = errors.New("job not found")
+)
+
+// Job is a long-running task, such as an import or a report, that runs on a worker instead of in an HTTP handler
+type Job struct {
+	Id         string             `json:"id"`                   // Secret random ID of the job
+	Kind       string             `json:"kind"`                 // What the job does, e.g. "import"
+	OwnerId    primitive.ObjectID `json:"-"`                    // ID of the user who started the job, zero if they were not signed in
+	State      string             `json:"state"`                // JobQueued, JobRunning, JobDone or JobFailed
+	Progress   float64            `json:"progress"`             // Share of the work done, from 0 to 1
+	Error      string             `json:"error,omitempty"`      // Why the job failed
+	ResultType string             `json:"resultType,omitempty"` // Content type of the result of a finished job
+	ResultName string             `json:"resultName,omitempty"` // File name the result is downloaded as, empty if it is not a file
+	CreatedAt  time.Time          `json:"createdAt"`            // When the job was queued
+	FinishedAt *time.Time         `json:"finishedAt,omitempty"` // When the job finished or failed, nil until then
+}
+
+// JobOutput is the result of a finished job
+type JobOutput struct {
+	Data        []byte // The result
+	ContentType string // Content type of the result
+	FileName    string // File name the result is downloaded as, empty if it is not a file
+}
+
+// JobFunc does the work of a job, calling progress with the share of the work done as it goes
+type JobFunc func(ctx context.Context, progress func(done float64)) (JobOutput, error)
+
+// JsonOutput encodes the result of a job as JSON
+// Parameters:
+// - value: the result
+// Returns:
+// - JobOutput: the encoded result
+// - error: an error if the value cannot be encoded
+func JsonOutput(value any) (JobOutput, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return JobOutput{}, err
+	}
+
+	return JobOutput{Data: data, ContentType: "application/json"}, nil
+}
+
+// JobStore keeps the state and results of jobs, so they can be looked up while and after they run
+type JobStore interface {
+	// SaveJob stores the state of a job and its result, if it has one, for the given time.
+	SaveJob(ctx context.Context, job Job, result []byte, ttl time.Duration) error
+	// GetJob returns a job and its result, nil if it does not exist or expired.
+	GetJob(ctx context.Context, id string) (*Job, []byte, error)
+}
+
+// queuedJob is a job waiting for a worker
+type queuedJob struct {
+	job Job     // The job
+	fn  JobFunc // The work of the job
+}
+
+// JobService runs heavy tasks on a pool of in-process workers, keeping their progress and results in a JobStore
+type JobService struct {
+	store JobStore        // Where the state of the jobs is kept
+	queue chan queuedJob  // Jobs waiting for a worker
+	ttl   time.Duration   // How long jobs are kept after they were last updated
+	clock Clock           // Source of time of the jobs
+	ctx   context.Context // Cancelled when the service is closed, bounding every job
+	stop  func()          // Cancels ctx
+	wg    sync.WaitGroup  // Running workers
+}
+
+// Jobs creates a new JobService instance and starts its workers
+// Parameters:
+// - store: where the state and results of jobs are kept
+// - workers: the number of jobs run at once
+// - queueSize: the most jobs that may wait for a worker
+// - ttl: how long jobs are kept after they were last updated
+// Returns:
+// - A pointer to a new JobService
+func Jobs(store JobStore, workers int, queueSize int, ttl time.Duration) *JobService {
+	ctx, stop := context.WithCancel(context.Background())
+	s := &JobService{
+		store: store,
+		queue: make(chan queuedJob, max(queueSize, 0)),
+		ttl:   ttl,
+		clock: realClock{},
+		ctx:   ctx,
+		stop:  stop,
+	}
+
+	for range max(workers, 1) {
+		s.wg.Add(1)
+		go s.work()
+	}
+
+	return s
+}
+
+// Enqueue queues a job for the next free worker
+// Parameters:
+// - ctx: the context bounding the storing of the job, not the job itself
+// - ownerId: the ObjectID of the user starting the job, zero if they are not signed in
+// - kind: what the job does, e.g. "import"
+// - fn: the work of the job
+// Returns:
+// - *Job: the queued job
+// - error: ErrJobQueueFull if too many jobs are waiting, or an error if the job cannot be stored
+func (s *JobService) Enqueue(ctx context.Context, ownerId primitive.ObjectID, kind string, fn JobFunc) (*Job, error) {
+	id, err := newSessionToken()
+	if err != nil {
+		return nil, err
+	}
+
+	job := Job{
+		Id:        id,
+		Kind:      kind,
+		OwnerId:   ownerId,
+		State:     JobQueued,
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.store.SaveJob(ctx, job, nil, s.ttl); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.queue <- queuedJob{job: job, fn: fn}:
+		return &job, nil
+	default:
+		job.State = JobFailed
+		job.Error = ErrJobQueueFull.Error()
+		s.save(job, nil)
+		return nil, ErrJobQueueFull
+	}
+}
+
+// GetJob returns the state of a job and its result once it is done
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ID of the job
+// - userId: the ObjectID of the signed in user, zero if there is none
+// Returns:
+// - *Job: the job
+// - []byte: the result, nil unless the job is done
+// - error: ErrJobNotFound if the job does not exist, expired or was started by another user
+func (s *JobService) GetJob(ctx context.Context, id string, userId primitive.ObjectID) (*Job, []byte, error) {
+	job, result, err := s.store.GetJob(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Jobs started by signed in users are theirs alone; the secret ID is enough for the others
+	if job == nil || (!job.OwnerId.IsZero() && job.OwnerId != userId) {
+		return nil, nil, ErrJobNotFound
+	}
+
+	return job, result, nil
+}
+
+// Close stops the workers, cancelling the jobs that are running and dropping those still queued
+func (s *JobService) Close() {
+	s.stop()
+	s.wg.Wait()
+}
+
+// work runs queued jobs one after the other until the service is closed
+func (s *JobService) work() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case queued := <-s.queue:
+			s.run(queued)
+		}
+	}
+}
+
+// run does the work of a job, storing its progress and its result or failure
+// Parameters:
+// - queued: the job
+func (s *JobService) run(queued queuedJob) {
+	job := queued.job
+	job.State = JobRunning
+	s.save(job, nil)
+
+	ctx, cancel := context.WithTimeout(s.ctx, jobTimeout)
+	defer cancel()
+
+	reported := s.clock.Now()
+	progress := func(done float64) {
+		job.Progress = min(max(done, 0), 1)
+		if s.clock.Now().Sub(reported) >= jobProgressInterval {
+			reported = s.clock.Now()
+			s.save(job, nil)
+		}
+	}
+
+	output, err := s.do(ctx, queued.fn, progress)
+	finishedAt := s.clock.Now()
+	job.FinishedAt = &finishedAt
+	if err != nil {
+		job.State = JobFailed
+		job.Error = err.Error()
+		s.save(job, nil)
+		return
+	}
+
+	job.State = JobDone
+	job.Progress = 1
+	job.ResultType = output.ContentType
+	job.ResultName = output.FileName
+	s.save(job, output.Data)
+}
+
+// do calls the work of a job, turning a panic into a failure so a broken job cannot take the server down
+// Parameters:
+// - ctx: the context bounding the job
+// - fn: the work of the job
+// - progress: reports the share of the work done
+// Returns:
+// - JobOutput: the result of the job
+// - error: why the job failed, or nil if it succeeded
+func (s *JobService) do(ctx context.Context, fn JobFunc, progress func(done float64)) (output JobOutput, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return fn(ctx, progress)
+}
+
+// save stores the state of a job, logging failures since the job carries on regardless
+// Parameters:
+// - job: the job
+// - result: the result of the job, nil until it is done
+func (s *JobService) save(job Job, result []byte) {
+	if err := s.store.SaveJob(s.ctx, job, result, s.ttl); err != nil {
+		fmt.Println("failed to save job", job.Id+":", err)
+	}
+}
+
+// memoryJob is a job kept in memory
+type memoryJob struct {
+	job     Job       // The job
+	result  []byte    // Its result, nil until it is done
+	expires time.Time // When the job is forgotten
+}
+
+// MemoryJobStore keeps jobs in memory, so they are only known to the instance running them
+type MemoryJobStore struct {
+	mu    sync.Mutex           // Guards jobs
+	jobs  map[string]memoryJob // Jobs by ID
+	clock Clock                // Source of time of the expiry
+}
+
+// MemoryJobs creates a new MemoryJobStore instance
+// Returns:
+// - A pointer to a new MemoryJobStore
+func MemoryJobs() *MemoryJobStore {
+	return &MemoryJobStore{
+		jobs:  map[string]memoryJob{},
+		clock: realClock{},
+	}
+}
+
+// SaveJob stores the state of a job and its result, forgetting expired jobs
+// Parameters:
+// - ctx: unused, memory does not block
+// - job: the job
+// - result: the result of the job, nil until it is done
+// - ttl: how long the job is kept
+// Returns:
+// - error: always nil
+func (m *MemoryJobStore) SaveJob(ctx context.Context, job Job, result []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	for id, stored := range m.jobs {
+		if now.After(stored.expires) {
+			delete(m.jobs, id)
+		}
+	}
+
+	m.jobs[job.Id] = memoryJob{job: job, result: result, expires: now.Add(ttl)}
+	return nil
+}
+
+// GetJob returns a job and its result
+// Parameters:
+// - ctx: unused, memory does not block
+// - id: the ID of the job
+// Returns:
+// - *Job: the job, nil if it does not exist or expired
+// - []byte: its result, nil until it is done
+// - error: always nil
+func (m *MemoryJobStore) GetJob(ctx context.Context, id string) (*Job, []byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.jobs[id]
+	if !ok || m.clock.Now().After(stored.expires) {
+		return nil, nil, nil
+	}
+
+	return &stored.job, stored.result, nil
+}
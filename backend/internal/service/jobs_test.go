@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// waitForJob polls a job until it finished or failed
+func waitForJob(t *testing.T, s *JobService, id string, userId primitive.ObjectID) (*Job, []byte) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, result, err := s.GetJob(context.Background(), id, userId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.State == JobDone || job.State == JobFailed {
+			return job, result
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not finish", id)
+	return nil, nil
+}
+
+func TestJobRunsInBackground(t *testing.T) {
+	s := Jobs(MemoryJobs(), 1, 10, time.Hour)
+	defer s.Close()
+
+	ownerId := primitive.NewObjectID()
+	job, err := s.Enqueue(context.Background(), ownerId, "report", func(ctx context.Context, progress func(done float64)) (JobOutput, error) {
+		progress(0.5)
+		return JsonOutput(map[string]int{"answers": 3})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != JobQueued || job.Kind != "report" {
+		t.Errorf("expected a queued report job, got %+v", job)
+	}
+
+	done, result := waitForJob(t, s, job.Id, ownerId)
+	if done.State != JobDone || done.Progress != 1 || done.ResultType != "application/json" || done.FinishedAt == nil {
+		t.Errorf("expected the job to be done, got %+v", done)
+	}
+	if string(result) != `{"answers":3}` {
+		t.Errorf("expected the result of the job, got %s", result)
+	}
+
+	// Jobs of signed in users are theirs alone
+	if _, _, err := s.GetJob(context.Background(), job.Id, primitive.NewObjectID()); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected the job to be hidden from other users, got %v", err)
+	}
+	if _, _, err := s.GetJob(context.Background(), "unknown", ownerId); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected an unknown job to be not found, got %v", err)
+	}
+}
+
+func TestJobFailures(t *testing.T) {
+	s := Jobs(MemoryJobs(), 2, 10, time.Hour)
+	defer s.Close()
+
+	failing, _ := s.Enqueue(context.Background(), primitive.NilObjectID, "import", func(ctx context.Context, progress func(done float64)) (JobOutput, error) {
+		return JobOutput{}, ErrInvalidImport
+	})
+	panicking, _ := s.Enqueue(context.Background(), primitive.NilObjectID, "import", func(ctx context.Context, progress func(done float64)) (JobOutput, error) {
+		panic("broken")
+	})
+
+	job, _ := waitForJob(t, s, failing.Id, primitive.NilObjectID)
+	if job.State != JobFailed || job.Error != ErrInvalidImport.Error() {
+		t.Errorf("expected the job to fail with its error, got %+v", job)
+	}
+
+	job, _ = waitForJob(t, s, panicking.Id, primitive.NilObjectID)
+	if job.State != JobFailed || !strings.Contains(job.Error, "broken") {
+		t.Errorf("expected a panic to fail the job, got %+v", job)
+	}
+}
+
+func TestJobQueueFull(t *testing.T) {
+	s := Jobs(MemoryJobs(), 1, 1, time.Hour)
+	release := make(chan struct{})
+	defer func() {
+		close(release)
+		s.Close()
+	}()
+
+	started := make(chan struct{})
+	block := func(ctx context.Context, progress func(done float64)) (JobOutput, error) {
+		started <- struct{}{}
+		<-release
+		return JobOutput{}, nil
+	}
+
+	// The first job keeps the only worker busy, the second fills the queue
+	if _, err := s.Enqueue(context.Background(), primitive.NilObjectID, "export", block); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if _, err := s.Enqueue(context.Background(), primitive.NilObjectID, "export", block); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Enqueue(context.Background(), primitive.NilObjectID, "export", block); !errors.Is(err, ErrJobQueueFull) {
+		t.Errorf("expected a full queue to turn the job away, got %v", err)
+	}
+
+	go func() { <-started }()
+}
+
+func TestMemoryJobsExpire(t *testing.T) {
+	clock := newFakeClock()
+	store := MemoryJobs()
+	store.clock = clock
+
+	store.SaveJob(context.Background(), Job{Id: "a", State: JobDone}, []byte("x"), time.Minute)
+	if job, result, _ := store.GetJob(context.Background(), "a"); job == nil || string(result) != "x" {
+		t.Fatalf("expected the job to be kept, got %+v", job)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if job, _, _ := store.GetJob(context.Background(), "a"); job != nil {
+		t.Errorf("expected the job to expire, got %+v", job)
+	}
+}
+
+func TestRedisProtocol(t *testing.T) {
+	if got := string(redisCommand("GET", "quiz:job:a")); got != "*2\r\n$3\r\nGET\r\n$10\r\nquiz:job:a\r\n" {
+		t.Errorf("unexpected command encoding %q", got)
+	}
+
+	replies := bufio.NewReader(strings.NewReader("+OK\r\n$5\r\nhello\r\n$-1\r\n-ERR wrong\r\n"))
+	if reply, err := readRedisReply(replies); err != nil || string(reply) != "OK" {
+		t.Errorf("expected a simple reply, got %q %v", reply, err)
+	}
+	if reply, err := readRedisReply(replies); err != nil || string(reply) != "hello" {
+		t.Errorf("expected a bulk reply, got %q %v", reply, err)
+	}
+	if reply, err := readRedisReply(replies); err != nil || reply != nil {
+		t.Errorf("expected a null reply, got %q %v", reply, err)
+	}
+	var replyErr redisError
+	if _, err := readRedisReply(replies); !errors.As(err, &replyErr) {
+		t.Errorf("expected an error reply, got %v", err)
+	}
+
+	server, ok := parseRedisUrl("redis://:secret@cache:6380/2")
+	if !ok || server.address != "cache:6380" || server.password != "secret" || server.database != 2 {
+		t.Errorf("unexpected server %+v", server)
+	}
+	if server, ok := parseRedisUrl("redis://cache"); !ok || server.address != "cache:6379" {
+		t.Errorf("expected the default port, got %+v", server)
+	}
+	if _, ok := parseRedisUrl("http://cache"); ok {
+		t.Error("expected a URL of another scheme to be turned down")
+	}
+}
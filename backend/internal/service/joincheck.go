@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"time"
+)
+
+// joinCheckWindow is the window lookups of join codes are counted in
+const joinCheckWindow = time.Minute
+
+// ErrRateLimited is returned when a client made too many attempts; see RateLimitError for when to try again
+var ErrRateLimited = errors.New("too many attempts")
+
+// RateLimitError is returned when a client made too many attempts
+type RateLimitError struct {
+	RetryAfter time.Duration // How long until the client may try again
+}
+
+// Error describes the error
+func (e RateLimitError) Error() string {
+	return ErrRateLimited.Error()
+}
+
+// Unwrap makes the error match ErrRateLimited
+func (e RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// JoinCheck tells a join page about a game before it opens a WebSocket to join it
+type JoinCheck struct {
+	Code          string `json:"code"`          // Join code of the game
+	QuizName      string `json:"quizName"`      // Title of the quiz being played
+	Players       int    `json:"players"`       // Number of players in the game
+	InProgress    bool   `json:"inProgress"`    // Whether the quiz started, so players join mid-game
+	Full          bool   `json:"full"`          // Whether the game reached the most players the host's plan allows
+	RequireSignIn bool   `json:"requireSignIn"` // Whether players must sign in to join
+}
+
+// CheckJoinCode looks up a game by its join code for a join page. Every lookup counts against the
+// client's limit, found or not, so active games cannot be discovered by trying codes one after the other.
+// Parameters:
+// - code: the join code
+// - address: the IP address of the client, the key of its limit
+// Returns:
+// - *JoinCheck: the game
+// - error: a RateLimitError if the client looked up too many codes or is locked out for guessing them, or ErrGameNotFound if there is no game with the code
+func (c *NetService) CheckJoinCode(code string, address string) (*JoinCheck, error) {
+	if lockout := c.joinLockedOut(address); lockout > 0 {
+		return nil, RateLimitError{RetryAfter: lockout}
+	}
+	if wait := c.joinChecks.allow(address, c.clock.Now()); wait > 0 {
+		return nil, RateLimitError{RetryAfter: wait}
+	}
+
+	if code == "" || len(code) > maxCodeLength {
+		return nil, ErrGameNotFound
+	}
+
+	// Joining a hibernated lobby wakes it, so checking it does too
+	game := c.getGameByCode(code)
+	if game == nil {
+		game = c.wakeGame(code)
+	}
+	if game == nil {
+		return nil, ErrGameNotFound
+	}
+
+	var check *JoinCheck
+	game.run("check", func() {
+		if game.Ended {
+			return
+		}
+
+		check = &JoinCheck{
+			Code:          game.Code,
+			QuizName:      game.Quiz.Name,
+			Players:       len(game.Players),
+			InProgress:    game.State != LobbyState,
+			Full:          overLimit(game.maxPlayers, len(game.Players)),
+			RequireSignIn: game.Settings.RequireSignIn,
+		}
+	})
+	if check == nil {
+		return nil, ErrGameNotFound
+	}
+
+	return check, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestRateLimiterWindows(t *testing.T) {
+	limiter := newRateLimiter(2, time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if limiter.allow("a", now) != 0 || limiter.allow("a", now.Add(time.Second)) != 0 {
+		t.Fatal("expected the first attempts to be allowed")
+	}
+	if wait := limiter.allow("a", now.Add(20*time.Second)); wait != 40*time.Second {
+		t.Errorf("expected to wait for the end of the window, got %v", wait)
+	}
+	if limiter.allow("b", now.Add(20*time.Second)) != 0 {
+		t.Error("expected other clients to have limits of their own")
+	}
+	if limiter.allow("a", now.Add(time.Minute)) != 0 {
+		t.Error("expected a new window to allow attempts again")
+	}
+
+	unlimited := newRateLimiter(0, time.Minute)
+	for range 100 {
+		if unlimited.allow("a", now) != 0 {
+			t.Fatal("expected no limit")
+		}
+	}
+}
+
+func TestCheckJoinCode(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{JoinCheckLimit: 3})
+	c.clock = newFakeClock()
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.Settings.RequireSignIn = true
+	game.maxPlayers = 1
+	c.addGame(game)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+
+	check, err := c.CheckJoinCode(game.Code, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.QuizName != "Fuzz" || check.Players != 1 || check.InProgress || !check.Full || !check.RequireSignIn {
+		t.Errorf("unexpected check %+v", check)
+	}
+
+	if _, err := c.CheckJoinCode("000000", "192.0.2.1"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("expected an unknown code to be not found, got %v", err)
+	}
+
+	// Misses count against the limit just as hits do
+	c.CheckJoinCode("000001", "192.0.2.1")
+	var limited RateLimitError
+	if _, err := c.CheckJoinCode(game.Code, "192.0.2.1"); !errors.As(err, &limited) || limited.RetryAfter != time.Minute {
+		t.Errorf("expected the address to be limited for a minute, got %v", err)
+	}
+	if _, err := c.CheckJoinCode(game.Code, "192.0.2.2"); err != nil {
+		t.Errorf("expected other addresses to look up codes, got %v", err)
+	}
+
+	game.Ended = true
+	if _, err := c.CheckJoinCode(game.Code, "192.0.2.3"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("expected an ended game to be not found, got %v", err)
+	}
+}
+
+func TestJoinLockout(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{JoinAttemptLimit: 2, JoinLockout: 10 * time.Minute})
+	clock := newFakeClock()
+	c.clock = clock
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	c.addGame(game)
+
+	join := func(ip string, code string) *addressedConnection {
+		con := &addressedConnection{ip: ip}
+		c.OnIncomingMessage(context.Background(), con, websocket.BinaryMessage, encodePacket(0, ConnectPacket{Code: code, Name: ip}))
+		return con
+	}
+
+	if con := join("192.0.2.1", "000000"); con.closed {
+		t.Error("expected a single wrong code to be let go")
+	}
+	if con := join("192.0.2.1", "000001"); !con.closed {
+		t.Error("expected the connection to be closed once the address is locked out")
+	}
+
+	// A locked out address cannot join even with the right code, nor look codes up
+	if con := join("192.0.2.1", game.Code); !con.closed || len(game.Players) != 0 {
+		t.Error("expected the locked out address to be turned away")
+	}
+	var limited RateLimitError
+	if _, err := c.CheckJoinCode(game.Code, "192.0.2.1"); !errors.As(err, &limited) || limited.RetryAfter != 10*time.Minute {
+		t.Errorf("expected the lookup to be refused for the lockout, got %v", err)
+	}
+	if join("192.0.2.2", game.Code); len(game.Players) != 1 {
+		t.Error("expected other addresses to join")
+	}
+
+	stats := c.newPlatformStats(nil)
+	if stats.FailedJoins != 2 || stats.JoinLockouts != 1 {
+		t.Errorf("expected the failed joins and the lockout to be counted, got %+v", stats)
+	}
+
+	clock.Advance(10 * time.Minute)
+	if join("192.0.2.1", game.Code); len(game.Players) != 2 {
+		t.Error("expected the address to join again after the lockout")
+	}
+}
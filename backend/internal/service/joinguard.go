@@ -0,0 +1,62 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// joinAttemptWindow is the window failed joins are counted in
+const joinAttemptWindow = time.Minute
+
+// joinAddress tells the address failed joins of a connection are counted against
+// Parameters:
+// - connection: the connection of the client
+// Returns:
+// - string: the IP address of the client, empty if it is unknown
+func joinAddress(connection Connection) string {
+	if con, ok := connection.(addressable); ok {
+		return con.IP()
+	}
+
+	return ""
+}
+
+// joinLockedOut reports whether the address of a client is locked out for trying too many unknown join codes
+// Parameters:
+// - address: the IP address of the client, empty if it is unknown
+// Returns:
+// - time.Duration: zero if the client may try to join, otherwise how long until the lockout ends
+func (c *NetService) joinLockedOut(address string) time.Duration {
+	if address == "" {
+		return 0
+	}
+
+	return c.joinFailures.retryAfter(address, c.clock.Now())
+}
+
+// failJoin records an attempt to join with a code no game has. Once an address tried as many unknown codes
+// as allowed within a minute it is locked out, as codes are being guessed, and the attempt is logged for alerting.
+// Parameters:
+// - connection: the connection of the client
+// - code: the code the client tried
+// Returns:
+// - bool: true if the address got locked out, so the connection should be closed
+func (c *NetService) failJoin(connection Connection, code string) bool {
+	c.failedJoins.Add(1)
+
+	address := joinAddress(connection)
+	if address == "" {
+		return false
+	}
+
+	now := c.clock.Now()
+	c.joinFailures.allow(address, now)
+	lockout := c.joinFailures.retryAfter(address, now)
+	if lockout == 0 {
+		return false
+	}
+
+	c.joinLockouts.Add(1)
+	fmt.Printf("suspected join code enumeration: %s locked out for %v after trying code %q\n", address, lockout, code)
+	return true
+}
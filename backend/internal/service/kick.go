@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// KickPlayerPacket is sent by the host to remove a player from the game
+type KickPlayerPacket struct {
+	PlayerId uuid.UUID `json:"playerId"` // ID of the player to remove
+	Ban      bool      `json:"ban"`      // Whether the player may not join the game again
+}
+
+// gameBans are the players the host banned from a game, matched by what they cannot easily change
+type gameBans struct {
+	names     map[string]bool             // Names of banned players
+	users     map[primitive.ObjectID]bool // Accounts of banned players who were signed in
+	addresses map[string]bool             // Addresses banned players joined from, when known
+}
+
+// OnKickPlayer removes a player on behalf of the host and, if asked, keeps them from joining again
+// Parameters:
+// - packet: the player to remove and whether to ban them
+func (g *Game) OnKickPlayer(packet *KickPlayerPacket) {
+	player := g.getPlayerById(packet.PlayerId)
+	if player == nil {
+		return
+	}
+
+	code, action := CloseKicked, KickHostAction
+	if packet.Ban {
+		code, action = CloseBanned, BanHostAction
+		g.ban(player)
+	}
+	g.recordHostAction(action, player, "")
+
+	fmt.Println(player.Name, "was removed by the host")
+	g.OnPlayerDisconnect(player)
+	g.netService.CloseConnection(player.Connection, code)
+}
+
+// ban remembers a player so they cannot join the game again
+// Parameters:
+// - player: the player to ban
+func (g *Game) ban(player *Player) {
+	if g.bans.names == nil {
+		g.bans = gameBans{
+			names:     map[string]bool{},
+			users:     map[primitive.ObjectID]bool{},
+			addresses: map[string]bool{},
+		}
+	}
+
+	g.bans.names[player.Name] = true
+	if !player.UserId.IsZero() {
+		g.bans.users[player.UserId] = true
+	}
+	if con, ok := player.Connection.(addressable); ok && con.IP() != "" {
+		g.bans.addresses[con.IP()] = true
+	}
+}
+
+// isBanned reports whether a joining player was banned by the host
+// Parameters:
+// - name: the name the player picked
+// - userId: the ID of the authenticated user, or primitive.NilObjectID for anonymous players
+// - connection: the connection of the player
+// Returns:
+// - bool: true if the name, account or address of the player was banned
+func (g *Game) isBanned(name string, userId primitive.ObjectID, connection Connection) bool {
+	if g.bans.names == nil {
+		return false
+	}
+
+	if g.bans.names[name] || (!userId.IsZero() && g.bans.users[userId]) {
+		return true
+	}
+
+	con, ok := connection.(addressable)
+	return ok && g.bans.addresses[con.IP()]
+}
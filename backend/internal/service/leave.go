@@ -0,0 +1,17 @@
+package service
+
+import "fmt"
+
+// LeaveGamePacket is sent by a player who leaves the game on purpose, e.g. by navigating away, so they are
+// removed at once instead of when the server notices the connection dropped
+type LeaveGamePacket struct{}
+
+// OnPlayerLeave removes a player who left the game on purpose and closes their connection. Their session is
+// not kept, so they cannot resume; joining again makes them a new player.
+// Parameters:
+// - player: the player who left
+func (g *Game) OnPlayerLeave(player *Player) {
+	fmt.Println(player.Name, "left the game on purpose")
+	g.OnPlayerDisconnect(player)
+	g.netService.CloseConnection(player.Connection, CloseNormal)
+}
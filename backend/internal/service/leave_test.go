@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestPlayerLeave(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{SessionTtl: time.Minute})
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	c.addGame(game)
+
+	leaving := &closingConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", leaving)
+	token := game.Players[0].session
+	host.messages = nil
+
+	c.OnIncomingMessage(context.Background(), leaving, websocket.BinaryMessage, encodePacket(51, LeaveGamePacket{}))
+	if len(game.Players) != 0 || !leaving.closed || leaving.closeCode() != CloseNormal {
+		t.Fatalf("expected the player removed and closed with CloseNormal, got code %d", leaving.closeCode())
+	}
+	if ids := host.packetIds(); len(ids) == 0 || ids[0] != 10 {
+		t.Errorf("expected the host told the player left, got %v", ids)
+	}
+
+	// The connection dropping afterwards changes nothing, and the player cannot resume
+	c.OnDisconnect(leaving)
+	if c.ResumeSession(&closingConnection{}, token) {
+		t.Errorf("expected a player who left not to resume")
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import "fmt"
+
+// Limits protecting the server from one enormous game
+const (
+	maxLoggedEvents  = 5000 // Events kept in the log of a game before the oldest are folded into a snapshot
+	coarseTickFactor = 3    // How many times less often pings and digests are sent once a game is throttled
+	answerSampleRate = 10   // One in this many answers is streamed for analytics once a game is throttled
+)
+
+// GameLimits counts what a game gave up to stay within the limits of the server
+type GameLimits struct {
+	Throttled      bool `json:"throttled"`      // Whether the game hit its broadcast limit, making optional updates coarser
+	SkippedPackets int  `json:"skippedPackets"` // Packets of optional broadcasts not sent to stay within the limit
+	FoldedEvents   int  `json:"foldedEvents"`   // Logged events folded into a snapshot to bound the memory of the game
+	SkippedAnswers int  `json:"skippedAnswers"` // Answers left out of the analytics stream by sampling
+}
+
+// fanOutBudget counts the packets a game broadcast within one second
+type fanOutBudget struct {
+	second int64 // Unix time of the second the packets were sent in
+	sent   int   // Packets sent in that second
+}
+
+// GameWarningPacket warns the host of a problem with their game, e.g. that it is too large for the server to keep
+// up every update or that players are losing their connection
+type GameWarningPacket struct {
+	Kind    string `json:"kind"`            // Kind of warning, e.g. ConnectivityWarning
+	Message string `json:"message"`         // Explanation shown to the host
+	Count   int    `json:"count,omitempty"` // Players the warning is about, for connectivity warnings
+}
+
+// spendFanOut counts the packets of a broadcast against the budget of the current second
+// Parameters:
+// - count: the number of recipients
+func (g *Game) spendFanOut(count int) {
+	second := g.clock.Now().Unix()
+	if g.fanOut.second != second {
+		g.fanOut = fanOutBudget{second: second}
+	}
+
+	g.fanOut.sent += count
+}
+
+// canFanOut reports whether an optional broadcast fits the budget of the current second and counts it if so.
+// A broadcast that does not fit throttles the game.
+// Parameters:
+// - count: the number of recipients
+// Returns:
+// - bool: false if the broadcast should be skipped
+func (g *Game) canFanOut(count int) bool {
+	limit := g.netService.config.GameFanOutLimit
+	if limit <= 0 {
+		return true
+	}
+
+	g.spendFanOut(0)
+	if g.fanOut.sent+count > limit {
+		g.limits.SkippedPackets += count
+		g.throttle()
+		return false
+	}
+
+	g.fanOut.sent += count
+	return true
+}
+
+// broadcastOptional sends a packet every client can do without to all players, unless the game is over its budget
+// Parameters:
+// - packet: the packet to send
+// - includeHost: whether the host receives it too
+// Returns:
+// - bool: false if the packet was skipped
+func (g *Game) broadcastOptional(packet any, includeHost bool) bool {
+	if !g.canFanOut(len(g.Players)) {
+		return false
+	}
+
+	g.broadcast(packet, includeHost)
+	return true
+}
+
+// throttle makes the optional updates of the game coarser for the rest of the game and warns the host once
+func (g *Game) throttle() {
+	if g.limits.Throttled {
+		return
+	}
+
+	g.limits.Throttled = true
+	fmt.Println("game", g.Code, "throttled with", len(g.Players), "players")
+	g.sendToHost(GameWarningPacket{
+		Kind:    ThrottledWarning,
+		Message: "So many players joined that live updates like votes and standings are slowed down to keep the game running",
+	})
+}
+
+// interval stretches the interval of a periodic update once the game is throttled
+// Parameters:
+// - seconds: the interval of the update in seconds
+// Returns:
+// - int: the interval to use
+func (g *Game) interval(seconds int) int {
+	if g.limits.Throttled {
+		return seconds * coarseTickFactor
+	}
+
+	return seconds
+}
+
+// flushLobbyVotes sends the lobby votes a throttled broadcast skipped, once the budget allows
+func (g *Game) flushLobbyVotes() {
+	if !g.lobbyVotesPending {
+		return
+	}
+
+	if g.broadcastOptional(g.getLobbyVotes(), true) {
+		g.lobbyVotesPending = false
+	}
+}
+
+// foldEvents bounds the memory of the event log by replacing its older half with the phase and scores they lead to,
+// so replaying the log still gives the same phase and scores. Actions of the host are never folded.
+func (g *Game) foldEvents() {
+	if len(g.events) <= maxLoggedEvents {
+		return
+	}
+
+	cut := len(g.events) - maxLoggedEvents/2
+	folded := []LoggedEvent{{
+		At:    g.events[cut-1].At,
+		Event: PhaseRestoredEvent{Phase: replayPhase(g.events[:cut])},
+	}, {
+		At:    g.events[cut-1].At,
+		Event: ScoresRestoredEvent{Scores: replayScores(g.events[:cut])},
+	}}
+
+	// Actions of the host are kept for the report; they do not change the phase, so replaying is unaffected
+	kept := []LoggedEvent{}
+	for _, logged := range g.events[:cut] {
+		if _, ok := logged.Event.(HostActionEvent); ok {
+			kept = append(kept, logged)
+		}
+	}
+
+	g.limits.FoldedEvents += cut - len(kept)
+	g.events = append(append(kept, folded...), g.events[cut:]...)
+}
+
+// sampleAnswer decides whether an answer is streamed for analytics, keeping one in answerSampleRate once the game is throttled
+// Returns:
+// - int: the sample rate to stream the answer with, 1 for every answer; 0 if the answer is skipped
+func (g *Game) sampleAnswer() int {
+	if !g.limits.Throttled {
+		return 1
+	}
+
+	g.sampledAnswers++
+	if g.sampledAnswers%answerSampleRate != 0 {
+		g.limits.SkippedAnswers++
+		return 0
+	}
+
+	return answerSampleRate
+}
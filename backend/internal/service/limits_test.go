@@ -0,0 +1,56 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// gameWarningPacketId is the ID of the packet warning the host of a throttled game
+const gameWarningPacketId = 40
+
+func TestFanOutLimit(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{GameFanOutLimit: 3})
+	clock := newFakeClock()
+	c.clock = clock
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+
+	game.BroadcastPacket(game.newGameInfoPacket(), false)
+	if game.broadcastOptional(game.newStateDigest(), true) {
+		t.Fatalf("expected the digest to be skipped once the budget of the second is spent")
+	}
+	if !game.limits.Throttled || game.limits.SkippedPackets != 2 || !slices.Contains(host.packetIds(), gameWarningPacketId) {
+		t.Errorf("expected the game to be throttled and the host warned, got %+v", game.limits)
+	}
+	if game.interval(digestInterval) != digestInterval*coarseTickFactor {
+		t.Errorf("expected coarser digests once throttled")
+	}
+
+	clock.Advance(time.Second)
+	if !game.broadcastOptional(game.newStateDigest(), true) {
+		t.Errorf("expected the budget to renew the next second")
+	}
+}
+
+func TestFoldEvents(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.apply(StateChangedEvent{State: PlayState})
+	game.apply(QuestionAdvancedEvent{})
+	for range maxLoggedEvents + 10 {
+		game.apply(TimerTickedEvent{})
+	}
+
+	if len(game.events) > maxLoggedEvents || game.limits.FoldedEvents == 0 {
+		t.Errorf("expected the log to be folded, got %d events", len(game.events))
+	}
+	if replayed := replayPhase(game.events); replayed != game.GamePhase {
+		t.Errorf("expected the folded log to replay to %+v, got %+v", game.GamePhase, replayed)
+	}
+}
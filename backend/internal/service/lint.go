@@ -0,0 +1,98 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+const (
+	maxLintQuestionLength = 120 // Longest question text that fits the display of a player in one piece
+	maxLintChoiceLength   = 75  // Longest choice text that fits its answer button
+)
+
+// Kinds of issues the quiz lint reports
+const (
+	LintNoCorrectChoice  = "noCorrectChoice"  // None of the choices is marked correct
+	LintIdenticalChoices = "identicalChoices" // All choices read the same
+	LintLongQuestion     = "longQuestion"     // The question text is too long for the display
+	LintLongChoice       = "longChoice"       // A choice text is too long for its button
+	LintNoTime           = "noTime"           // The question has no time limit
+)
+
+// LintWarning describes an issue with a question of a quiz
+type LintWarning struct {
+	QuestionId string `json:"questionId"` // ID of the question
+	Question   int    `json:"question"`   // Position of the question in the quiz, starting at 0
+	Choice     int    `json:"choice"`     // Position of the choice the issue is about, -1 for the whole question
+	Kind       string `json:"kind"`       // Kind of issue, see LintNoCorrectChoice and the other Lint constants
+	Message    string `json:"message"`    // Explanation for the author
+}
+
+// LintQuestions checks the questions of a quiz for issues that make them unplayable or hard to read.
+// Parameters:
+// - questions: the questions to check
+// - defaultTime: the quiz's default time, which questions without a time of their own take
+// Returns:
+// - []LintWarning: the issues found, in question order
+func LintQuestions(questions []entity.QuizQuestion, defaultTime int) []LintWarning {
+	warnings := []LintWarning{}
+	for i, question := range questions {
+		warn := func(choice int, kind string, message string) {
+			warnings = append(warnings, LintWarning{
+				QuestionId: question.Id,
+				Question:   i,
+				Choice:     choice,
+				Kind:       kind,
+				Message:    message,
+			})
+		}
+
+		if question.Time <= 0 && defaultTime <= 0 {
+			warn(-1, LintNoTime, "The question has no time limit")
+		}
+
+		if utf8.RuneCountInString(question.Name) > maxLintQuestionLength {
+			warn(-1, LintLongQuestion, "The question is too long to be shown in one piece")
+		}
+
+		correct := false
+		for j, choice := range question.Choices {
+			correct = correct || choice.Correct
+			if utf8.RuneCountInString(choice.Name) > maxLintChoiceLength {
+				warn(j, LintLongChoice, "The choice is too long for its answer button")
+			}
+		}
+
+		if !correct {
+			warn(-1, LintNoCorrectChoice, "None of the choices is marked correct")
+		}
+
+		if identicalChoices(question.Choices) {
+			warn(-1, LintIdenticalChoices, "All choices read the same")
+		}
+	}
+
+	return warnings
+}
+
+// identicalChoices checks whether a question offers nothing to choose between, ignoring case and surrounding space
+// Parameters:
+// - choices: the choices of the question
+// Returns:
+// - bool: true if there are at least two choices and they all read the same
+func identicalChoices(choices []entity.QuizChoice) bool {
+	if len(choices) < 2 {
+		return false
+	}
+
+	first := strings.TrimSpace(choices[0].Name)
+	for _, choice := range choices[1:] {
+		if !strings.EqualFold(strings.TrimSpace(choice.Name), first) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestLintQuestions(t *testing.T) {
+	questions := []entity.QuizQuestion{
+		{Id: "fine", Name: "Fine", Time: 20, Choices: []entity.QuizChoice{{Name: "A", Correct: true}, {Name: "B"}}},
+		{Id: "broken", Name: strings.Repeat("x", maxLintQuestionLength+1), Choices: []entity.QuizChoice{{Name: "Same"}, {Name: " same "}}},
+		{Id: "long", Name: "Long", Time: 20, Choices: []entity.QuizChoice{{Name: "A", Correct: true}, {Name: strings.Repeat("y", maxLintChoiceLength+1)}}},
+	}
+
+	kinds := []string{}
+	for _, warning := range LintQuestions(questions, 0) {
+		if warning.QuestionId == "fine" {
+			t.Errorf("unexpected warning for a fine question: %+v", warning)
+		}
+		if warning.Kind == LintLongChoice && (warning.Question != 2 || warning.Choice != 1) {
+			t.Errorf("long choice reported at the wrong place: %+v", warning)
+		}
+		kinds = append(kinds, warning.Kind)
+	}
+
+	want := []string{LintNoTime, LintLongQuestion, LintNoCorrectChoice, LintIdenticalChoices, LintLongChoice}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Errorf("got warnings %v, want %v", kinds, want)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Limits of the emoji vote played in the lobby while waiting for the host to start
+const (
+	lobbyEmojiCount    = 6                      // Number of emojis players can vote for
+	lobbyVoteCooldown  = 250 * time.Millisecond // Minimum time between two votes of the same player
+	lobbyVoteNoneIndex = -1                     // Emoji index used to withdraw a vote
+)
+
+type LobbyVotePacket struct {
+	Emoji int `json:"emoji"` // Index of the emoji voted for, or -1 to withdraw the vote
+}
+
+type LobbyVotesPacket struct {
+	Votes []int `json:"votes"` // Number of votes for each emoji
+}
+
+// validate checks that the emoji index is within range.
+func (p *LobbyVotePacket) validate() error {
+	if p.Emoji < lobbyVoteNoneIndex || p.Emoji >= lobbyEmojiCount {
+		return ErrInvalidPacket
+	}
+
+	return nil
+}
+
+// OnLobbyVote handles a player's vote in the lobby emoji vote
+// Parameters:
+// - player: the player who voted
+// - packet: the vote
+func (g *Game) OnLobbyVote(player *Player, packet *LobbyVotePacket) {
+	// The vote only runs until the host starts the quiz
+	if g.State != LobbyState {
+		return
+	}
+
+	now := g.clock.Now()
+	if now.Sub(player.lastLobbyVote) < lobbyVoteCooldown {
+		return
+	}
+	player.lastLobbyVote = now
+
+	previous, voted := g.lobbyVotes[player.Id]
+	if packet.Emoji == lobbyVoteNoneIndex {
+		if !voted {
+			return
+		}
+
+		delete(g.lobbyVotes, player.Id)
+	} else {
+		if voted && previous == packet.Emoji {
+			return
+		}
+
+		g.lobbyVotes[player.Id] = packet.Emoji
+	}
+
+	g.broadcastLobbyVotes()
+}
+
+// removeLobbyVote withdraws the vote of a player leaving the lobby
+// Parameters:
+// - id: the ID of the player
+func (g *Game) removeLobbyVote(id uuid.UUID) {
+	if _, voted := g.lobbyVotes[id]; !voted {
+		return
+	}
+
+	delete(g.lobbyVotes, id)
+	g.broadcastLobbyVotes()
+}
+
+// broadcastLobbyVotes sends the tally of the lobby votes to everyone, or leaves it for a later tick if the game is over its budget
+func (g *Game) broadcastLobbyVotes() {
+	g.lobbyVotesPending = !g.broadcastOptional(g.getLobbyVotes(), true)
+}
+
+// stopLobby ends the lobby vote when the quiz starts
+func (g *Game) stopLobby() {
+	g.lobbyVotes = map[uuid.UUID]int{}
+}
+
+// getLobbyVotes tallies the current lobby votes
+// Returns:
+// - LobbyVotesPacket: the number of votes for each emoji
+func (g *Game) getLobbyVotes() LobbyVotesPacket {
+	votes := make([]int, lobbyEmojiCount)
+	for _, emoji := range g.lobbyVotes {
+		votes[emoji]++
+	}
+
+	return LobbyVotesPacket{
+		Votes: votes,
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestLobbyVote(t *testing.T) {
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	alice := &Player{Id: uuid.New(), Name: "alice", Connection: &fakeConnection{}}
+	bob := &Player{Id: uuid.New(), Name: "bob", Connection: &fakeConnection{}}
+	game.Players = []*Player{alice, bob}
+
+	game.OnLobbyVote(alice, &LobbyVotePacket{Emoji: 2})
+	game.OnLobbyVote(bob, &LobbyVotePacket{Emoji: 2})
+	if votes := game.getLobbyVotes().Votes; votes[2] != 2 {
+		t.Fatalf("expected two votes for emoji 2, got %v", votes)
+	}
+
+	// Votes arriving faster than the cooldown are ignored
+	game.OnLobbyVote(alice, &LobbyVotePacket{Emoji: 4})
+	if votes := game.getLobbyVotes().Votes; votes[4] != 0 {
+		t.Errorf("expected the vote within the cooldown to be ignored, got %v", votes)
+	}
+
+	game.OnPlayerDisconnect(bob)
+	if votes := game.getLobbyVotes().Votes; votes[2] != 1 {
+		t.Errorf("expected bob's vote to be withdrawn, got %v", votes)
+	}
+
+	game.stopLobby()
+	game.State = PlayState
+	alice.lastLobbyVote = alice.lastLobbyVote.Add(-lobbyVoteCooldown)
+	game.OnLobbyVote(alice, &LobbyVotePacket{Emoji: 1})
+	if votes := game.getLobbyVotes().Votes; votes[1] != 0 || votes[2] != 0 {
+		t.Errorf("expected no votes after the quiz started, got %v", votes)
+	}
+}
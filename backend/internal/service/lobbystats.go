@@ -0,0 +1,101 @@
+package service
+
+import (
+	"time"
+)
+
+// Timing of the join statistics sent to the host while waiting in the lobby
+const (
+	lobbyStatsWindow   = 10 * time.Second // Joins within this window count as recent
+	lobbyStatsInterval = 2 * time.Second  // Time between two statistics updates
+)
+
+// Device types players may report when joining
+var lobbyDevices = map[string]bool{
+	"phone":   true,
+	"tablet":  true,
+	"desktop": true,
+}
+
+type LobbyStatsPacket struct {
+	RecentJoins int            `json:"recentJoins"` // Number of players who joined within the last lobbyStatsWindow
+	Total       int            `json:"total"`       // Number of players in the lobby
+	Devices     map[string]int `json:"devices"`     // Number of players per reported device type
+}
+
+// lobbyStats aggregates the join times of the players of a game
+type lobbyStats struct {
+	joins []time.Time // Join times within the window, oldest first
+}
+
+// recordJoin counts a player joining the game
+// Parameters:
+// - now: the time the player joined
+func (s *lobbyStats) recordJoin(now time.Time) {
+	s.joins = append(s.joins, now)
+	s.prune(now)
+}
+
+// recentJoins returns the number of players who joined within the window
+// Parameters:
+// - now: the current time
+// Returns:
+// - int: the number of recent joins
+func (s *lobbyStats) recentJoins(now time.Time) int {
+	s.prune(now)
+	return len(s.joins)
+}
+
+// prune forgets the joins that fell out of the window
+// Parameters:
+// - now: the current time
+func (s *lobbyStats) prune(now time.Time) {
+	cutoff := now.Add(-lobbyStatsWindow)
+	i := 0
+	for i < len(s.joins) && !s.joins[i].After(cutoff) {
+		i++
+	}
+
+	s.joins = s.joins[i:]
+}
+
+// getLobbyStats summarizes the players waiting in the lobby
+// Parameters:
+// - now: the current time
+// Returns:
+// - LobbyStatsPacket: the join rate, the total and the device breakdown
+func (g *Game) getLobbyStats(now time.Time) LobbyStatsPacket {
+	devices := map[string]int{}
+	for _, player := range g.Players {
+		if player.Device != "" {
+			devices[player.Device]++
+		}
+	}
+
+	return LobbyStatsPacket{
+		RecentJoins: g.lobbyStats.recentJoins(now),
+		Total:       len(g.Players),
+		Devices:     devices,
+	}
+}
+
+// runLobbyStats periodically sends the lobby statistics to the host until the quiz starts
+func (g *Game) runLobbyStats() {
+	for {
+		g.clock.Sleep(lobbyStatsInterval)
+
+		done := false
+		g.run("lobby stats", func() {
+			if g.Ended || g.State != LobbyState {
+				done = true
+				return
+			}
+
+			g.sendToHost(g.getLobbyStats(g.clock.Now()))
+		})
+
+		if done {
+			return
+		}
+	}
+}
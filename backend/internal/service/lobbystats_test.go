@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestLobbyStats(t *testing.T) {
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	game.Players = []*Player{
+		{Id: uuid.New(), Name: "alice", Device: "phone", Connection: &fakeConnection{}},
+		{Id: uuid.New(), Name: "bob", Device: "phone", Connection: &fakeConnection{}},
+		{Id: uuid.New(), Name: "carol", Device: "desktop", Connection: &fakeConnection{}},
+		{Id: uuid.New(), Name: "dave", Connection: &fakeConnection{}},
+	}
+
+	start := time.Now()
+	game.lobbyStats.recordJoin(start)
+	game.lobbyStats.recordJoin(start.Add(4 * time.Second))
+	game.lobbyStats.recordJoin(start.Add(8 * time.Second))
+
+	stats := game.getLobbyStats(start.Add(9 * time.Second))
+	if stats.RecentJoins != 3 || stats.Total != 4 {
+		t.Errorf("expected 3 recent joins of 4 players, got %d of %d", stats.RecentJoins, stats.Total)
+	}
+	if stats.Devices["phone"] != 2 || stats.Devices["desktop"] != 1 || len(stats.Devices) != 2 {
+		t.Errorf("unexpected device breakdown %v", stats.Devices)
+	}
+
+	// Joins older than the window no longer count as recent
+	if recent := game.lobbyStats.recentJoins(start.Add(15 * time.Second)); recent != 1 {
+		t.Errorf("expected 1 recent join after the window moved, got %d", recent)
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"quiz.com/quiz/internal/config"
+)
+
+// ErrMailNotConfigured is returned when an email is sent without an SMTP server configured
+var ErrMailNotConfigured = errors.New("email is not configured")
+
+// MailService sends plain text emails through the configured SMTP server
+type MailService struct {
+	host     string // Host name of the SMTP server, empty if email is disabled
+	port     int    // Port of the SMTP server
+	user     string // User name for SMTP authentication, empty to send without authentication
+	password string // Password for SMTP authentication
+	from     string // Sender address
+}
+
+// Mail initializes and returns a new MailService instance.
+// Parameters:
+// - config: the runtime configuration, providing the SMTP server and sender.
+func Mail(config config.Config) *MailService {
+	return &MailService{
+		host:     config.SmtpHost,
+		port:     config.SmtpPort,
+		user:     config.SmtpUser,
+		password: config.SmtpPassword,
+		from:     config.SmtpFrom,
+	}
+}
+
+// Send delivers an email to a single recipient.
+// Parameters:
+// - to: the address of the recipient, which must already be validated.
+// - subject: the subject line.
+// - body: the plain text body.
+// Returns:
+// - ErrMailNotConfigured if no SMTP server is set, or the error reported by the server.
+func (s MailService) Send(to string, subject string, body string) error {
+	if s.host == "" {
+		return ErrMailNotConfigured
+	}
+
+	var auth smtp.Auth
+	if s.user != "" {
+		auth = smtp.PlainAuth("", s.user, s.password, s.host)
+	}
+
+	// Line breaks in the subject would let it inject headers
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		s.from, to, subject, strings.ReplaceAll(body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	return smtp.SendMail(addr, auth, s.from, []string{to}, []byte(message))
+}
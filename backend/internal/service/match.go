@@ -0,0 +1,131 @@
+package service
+
+import "sort"
+
+// maxMatchRounds is the most rounds a match can be split into
+const maxMatchRounds = 9
+
+// MatchStanding is a player's line on the leaderboard of a match
+type MatchStanding struct {
+	Name   string `json:"name"`   // Player's name
+	Avatar int    `json:"avatar"` // Index of the player's avatar
+	Color  int    `json:"color"`  // Index of the player's color
+	Wins   int    `json:"wins"`   // Rounds the player won
+	Points int    `json:"points"` // Points the player scored in the quiz so far, over all of its rounds
+}
+
+// MatchLeaderboardPacket tells everyone who won a round of a match and how the match stands.
+// It is sent whenever a round ends, the points of the next round start from zero.
+type MatchLeaderboardPacket struct {
+	Round     int             `json:"round"`     // Number of rounds finished, starting at 1
+	Rounds    int             `json:"rounds"`    // Number of rounds in the match
+	Winners   []string        `json:"winners"`   // Names of the players who won the round, empty if nobody scored
+	Standings []MatchStanding `json:"standings"` // Every player, most round wins first
+}
+
+// isMatch reports whether the game is played as a match of several rounds
+func (g *Game) isMatch() bool {
+	return g.Settings.MatchRounds > 1
+}
+
+// getMatchRounds returns the number of rounds the questions of a quiz are split into
+// Parameters:
+// - questions: the number of questions of the quiz
+// Returns:
+// - int: the rounds, at most one per question
+func (g *Game) getMatchRounds(questions int) int {
+	return max(1, min(g.Settings.MatchRounds, questions))
+}
+
+// getTotalRounds returns the number of rounds in the match, over every quiz of the playlist
+func (g *Game) getTotalRounds() int {
+	if len(g.Playlist) == 0 {
+		return g.getMatchRounds(len(g.Quiz.Questions))
+	}
+
+	total := 0
+	for _, quiz := range g.Playlist {
+		total += g.getMatchRounds(len(quiz.Questions))
+	}
+
+	return total
+}
+
+// isRoundEnd reports whether a question is the last of its round. The questions of a quiz are split
+// into rounds of the same length, give or take one.
+// Parameters:
+// - index: the index of the question
+// Returns:
+// - bool: true if the round ends with the question
+func (g *Game) isRoundEnd(index int) bool {
+	questions := len(g.Quiz.Questions)
+	if index < 0 || index >= questions {
+		return false
+	}
+
+	rounds := g.getMatchRounds(questions)
+	return index == questions-1 || index*rounds/questions != (index+1)*rounds/questions
+}
+
+// closeRound ends the round of a match if the current question was its last, gives a win to the
+// players with the most points in the round and starts the points of the next round from zero.
+// A round is closed once, either when the leaderboard is shown after it or when the game moves on.
+// Returns:
+// - bool: true if a round was closed
+func (g *Game) closeRound() bool {
+	if !g.isMatch() || !g.roundOpen || !g.isRoundEnd(g.CurrentQuestion) {
+		return false
+	}
+	g.roundOpen = false
+	g.roundsPlayed++
+
+	players := append([]*Player{}, g.Players...)
+	scoring := g.scoring()
+	sort.SliceStable(players, func(i, j int) bool {
+		return scoring.Ranks(players[i], players[j])
+	})
+
+	winners := []string{}
+	for _, player := range players {
+		if player.Points <= 0 || scoring.Ranks(players[0], player) {
+			break
+		}
+
+		player.RoundWins++
+		winners = append(winners, player.Name)
+	}
+
+	// The points of the quiz keep adding up for reports and results, only the round's start from zero
+	for _, player := range g.Players {
+		player.quizStartPoints -= player.Points
+	}
+	g.apply(PointsResetEvent{})
+
+	g.BroadcastPacket(MatchLeaderboardPacket{
+		Round:     g.roundsPlayed,
+		Rounds:    g.getTotalRounds(),
+		Winners:   winners,
+		Standings: g.getMatchStandings(),
+	}, true)
+
+	return true
+}
+
+// getMatchStandings returns every player of the match, most round wins first
+func (g *Game) getMatchStandings() []MatchStanding {
+	players := append([]*Player{}, g.Players...)
+	g.sortPlayers(players)
+
+	standings := []MatchStanding{}
+	for _, player := range players {
+		standings = append(standings, MatchStanding{
+			Name:   player.Name,
+			Avatar: player.Avatar,
+			Color:  player.Color,
+			Wins:   player.RoundWins,
+			Points: player.quizPoints(),
+		})
+	}
+
+	return standings
+}
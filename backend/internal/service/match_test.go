@@ -0,0 +1,100 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// matchLeaderboardPacketId is the ID of MatchLeaderboardPacket on the wire
+const matchLeaderboardPacketId = 48
+
+// matchLeaderboards decodes every match leaderboard sent on a connection
+func matchLeaderboards(t *testing.T, connection *fakeConnection) []MatchLeaderboardPacket {
+	t.Helper()
+
+	packets := []MatchLeaderboardPacket{}
+	for _, message := range connection.messages {
+		if message[0] != matchLeaderboardPacketId {
+			continue
+		}
+
+		var packet MatchLeaderboardPacket
+		if err := json.Unmarshal(packetData(message), &packet); err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, packet)
+	}
+
+	return packets
+}
+
+func TestMatchRounds(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	alice, bob := game.Players[0], game.Players[1]
+	game.OnSettings(GameSettings{MatchRounds: 3})
+
+	// Started by hand, without the timer goroutine of Start
+	game.stopLobby()
+	game.ChangeState(PlayState)
+
+	// Alice wins the first round, which closes when the leaderboard is shown
+	game.NextQuestion()
+	game.OnPlayerAnswer(0, alice)
+	game.OnPlayerAnswer(1, bob)
+	game.Intermission()
+	if alice.RoundWins != 1 || alice.Points != 0 || alice.quizPoints() == 0 {
+		t.Fatalf("expected alice to win the round and start the next from zero, got %d wins and %d points", alice.RoundWins, alice.Points)
+	}
+
+	// Bob wins the second round, which the host skips past the leaderboard of
+	game.NextQuestion()
+	game.OnPlayerAnswer(0, alice)
+	game.OnPlayerAnswer(1, bob)
+	game.NextQuestion()
+	if bob.RoundWins != 1 {
+		t.Fatalf("expected bob to win the skipped round, got %d wins", bob.RoundWins)
+	}
+
+	// Nobody can answer the last question correctly, so nobody wins it
+	game.OnPlayerAnswer(0, alice)
+	game.OnPlayerAnswer(0, bob)
+	game.Intermission()
+	game.NextQuestion()
+
+	leaderboards := matchLeaderboards(t, host)
+	if len(leaderboards) != 3 {
+		t.Fatalf("expected a match leaderboard per round, got %d", len(leaderboards))
+	}
+	if first := leaderboards[0]; first.Round != 1 || first.Rounds != 3 || len(first.Winners) != 1 || first.Winners[0] != "alice" {
+		t.Errorf("expected alice to win the first of three rounds, got %+v", first)
+	}
+
+	last := leaderboards[2]
+	if len(last.Winners) != 0 || last.Standings[0].Wins != 1 || last.Standings[1].Wins != 1 || last.Standings[0].Points == 0 {
+		t.Errorf("expected a round without winners and a tied match, got %+v", last)
+	}
+	if game.State != EndState {
+		t.Errorf("expected the match to end after the last round, got state %d", game.State)
+	}
+}
+
+func TestMatchRoundsSettings(t *testing.T) {
+	for _, settings := range []GameSettings{
+		{MatchRounds: -1},
+		{MatchRounds: maxMatchRounds + 1},
+		{MatchRounds: 3, ExamMode: true},
+		{MatchRounds: 3, PlayerPaced: true},
+	} {
+		packet := GameSettingsPacket{Settings: settings}
+		if packet.validate() == nil {
+			t.Errorf("expected %+v to be rejected", settings)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// maxMediaSize is the largest accepted upload, the default request body limit of the HTTP server
+const maxMediaSize = 4 << 20
+
+// mediaTypes maps the content types accepted for upload, as detected from the content, to the kind of media
+var mediaTypes = map[string]string{
+	"image/png":       entity.ImageMedia,
+	"image/jpeg":      entity.ImageMedia,
+	"image/gif":       entity.ImageMedia,
+	"image/webp":      entity.ImageMedia,
+	"audio/mpeg":      entity.AudioMedia,
+	"audio/wave":      entity.AudioMedia,
+	"application/ogg": entity.AudioMedia,
+	"video/mp4":       entity.VideoMedia,
+	"video/webm":      entity.VideoMedia,
+}
+
+// Errors returned when media cannot be uploaded or served.
+var (
+	ErrInvalidMedia     = errors.New("unsupported or too large media")
+	ErrMediaNotFound    = errors.New("media not found")
+	ErrInvalidSignature = errors.New("invalid or expired media signature")
+)
+
+// MediaService provides methods for uploading media and serving it through time-limited signed URLs,
+// so the storage is never exposed directly and responses can be cached by a CDN in front of the server.
+type MediaService struct {
+	mediaCollection *collection.MediaCollection // Reference to the media collection for database operations
+	storage         collection.MediaStorage     // Storage holding the content of the media
+	secret          []byte                      // Key used to sign media URLs
+	baseUrl         string                      // Base URL media is served from, e.g. a CDN in front of the server
+	urlTtl          time.Duration               // Minimum time a signed URL stays valid
+}
+
+// Media initializes and returns a new MediaService instance.
+// Parameters:
+// - mediaCollection: the collection that interacts with the media in the database.
+// - storage: the storage holding the content of the media.
+// - config: the runtime configuration, providing the signing secret, base URL and lifetime of media URLs.
+func Media(mediaCollection *collection.MediaCollection, storage collection.MediaStorage, config config.Config) *MediaService {
+	return &MediaService{
+		mediaCollection: mediaCollection,
+		storage:         storage,
+		secret:          []byte(config.AuthSecret),
+		baseUrl:         strings.TrimSuffix(config.MediaUrl, "/"),
+		urlTtl:          config.MediaUrlTtl,
+	}
+}
+
+// Upload stores new media. The content type is detected from the content rather than trusted from the client.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - ownerId: the ID of the uploading user.
+// - name: the original file name.
+// - content: the content of the file.
+// Returns:
+// - The stored media and ErrInvalidMedia if the content is not a supported image or sound or too large.
+func (s MediaService) Upload(ctx context.Context, ownerId primitive.ObjectID, name string, content io.Reader) (*entity.Media, error) {
+	// Uploads are small, so read them whole; one byte extra detects oversized files
+	data, err := io.ReadAll(io.LimitReader(content, maxMediaSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxMediaSize {
+		return nil, ErrInvalidMedia
+	}
+
+	contentType := http.DetectContentType(data)
+	kind, ok := mediaTypes[contentType]
+	if !ok {
+		return nil, ErrInvalidMedia
+	}
+
+	media := entity.Media{
+		Id:          primitive.NewObjectID(),
+		OwnerId:     ownerId,
+		Name:        name,
+		Type:        kind,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Hash:        hashBytes(data),
+		Variants:    []entity.MediaVariant{},
+		CreatedAt:   time.Now(),
+	}
+
+	key := mediaKey(media.Id, "")
+	if err := s.storage.Put(ctx, key, bytes.NewReader(data), media.Size, contentType); err != nil {
+		return nil, err
+	}
+
+	// Smaller renditions save bandwidth, but the original works without them
+	renditions, err := renderImage(ctx, data, contentType)
+	if err != nil {
+		fmt.Println("failed to resize media", media.Id.Hex(), ":", err)
+	}
+
+	for _, rendition := range renditions {
+		variant := rendition.variant
+		err := s.storage.Put(ctx, mediaKey(media.Id, variant.Size), bytes.NewReader(rendition.data), variant.Bytes, variant.ContentType)
+		if err != nil {
+			fmt.Println("failed to store", variant.Size, "rendition of media", media.Id.Hex(), ":", err)
+			continue
+		}
+
+		media.Variants = append(media.Variants, variant)
+	}
+
+	// Do not leave content behind that nothing refers to
+	if err := s.mediaCollection.InsertMedia(ctx, media); err != nil {
+		s.storage.Delete(ctx, key)
+		for _, variant := range media.Variants {
+			s.storage.Delete(ctx, mediaKey(media.Id, variant.Size))
+		}
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+// Open verifies the signature of a media URL and opens the media, or one of its renditions, for reading.
+// Parameters:
+// - ctx: the context bounding the database operations and reading the content.
+// - id: the ObjectID of the media.
+// - size: the size of the rendition, or empty for the original.
+// - expires: the expiry of the URL as a Unix timestamp.
+// - signature: the signature of the URL.
+// Returns:
+//   - The content type, hash and length of the content, the content itself, which the caller must close,
+//     ErrInvalidSignature if the URL is forged or expired, or ErrMediaNotFound if the media or rendition does not exist.
+func (s MediaService) Open(ctx context.Context, id primitive.ObjectID, size string, expires string, signature string) (*entity.MediaVariant, io.ReadCloser, error) {
+	if err := s.verify(id, size, expires, signature, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	media, err := s.mediaCollection.GetMediaById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if media == nil {
+		return nil, nil, ErrMediaNotFound
+	}
+
+	// Describe the original like a rendition, so both are served the same way
+	variant := &entity.MediaVariant{ContentType: media.ContentType, Bytes: media.Size, Hash: media.Hash}
+	if size != "" {
+		variant = findVariant(*media, size)
+		if variant == nil {
+			return nil, nil, ErrMediaNotFound
+		}
+	}
+
+	content, err := s.storage.Get(ctx, mediaKey(id, size))
+	if errors.Is(err, collection.ErrContentNotFound) {
+		return nil, nil, ErrMediaNotFound
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return variant, content, nil
+}
+
+// SignUrl returns a signed URL for media that stays valid for at least the configured lifetime.
+// Storages that can sign URLs serve the media themselves, otherwise the URL points to the app.
+// The expiry is rounded to a window of that lifetime, so everyone receives the same URL for a while
+// and a CDN can serve it from its cache.
+// Parameters:
+// - id: the ObjectID of the media.
+// - size: the size of the rendition, or empty for the original.
+// Returns:
+// - The signed URL.
+func (s MediaService) SignUrl(id primitive.ObjectID, size string) string {
+	return s.signUrl(id, size, time.Now())
+}
+
+// signUrl returns a signed URL for media as of the given time.
+func (s MediaService) signUrl(id primitive.ObjectID, size string, now time.Time) string {
+	window := int64(max(s.urlTtl, time.Second) / time.Second)
+	start := now.Unix() / window * window
+	expires := start + 2*window
+
+	if s.storage != nil {
+		signed, err := s.storage.SignURL(mediaKey(id, size), time.Unix(start, 0), time.Duration(expires-start)*time.Second)
+		if err == nil {
+			return signed
+		}
+	}
+
+	query := url.Values{}
+	if size != "" {
+		query.Set("size", size)
+	}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sig", s.signature(id, size, expires))
+
+	return fmt.Sprintf("%s/api/media/%s?%s", s.baseUrl, id.Hex(), query.Encode())
+}
+
+// verify checks the signature and expiry of a media URL.
+func (s MediaService) verify(id primitive.ObjectID, size string, expires string, signature string, now time.Time) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.signature(id, size, expiresAt))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// signature computes the hex encoded HMAC of a media ID, rendition size and expiry.
+func (s MediaService) signature(id primitive.ObjectID, size string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s:%s:%d", id.Hex(), size, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignQuiz returns a copy of a quiz whose uploaded media carries signed URLs of the original and of every
+// rendition, leaving the original untouched. Media that cannot be looked up is signed without renditions.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quiz: the quiz about to be played.
+// Returns:
+// - The quiz with signed media URLs.
+func (s MediaService) SignQuiz(ctx context.Context, quiz entity.Quiz) entity.Quiz {
+	ids := []primitive.ObjectID{}
+	for _, question := range quiz.Questions {
+		for _, item := range question.Media {
+			if !item.MediaId.IsZero() {
+				ids = append(ids, item.MediaId)
+			}
+		}
+	}
+
+	variants := map[primitive.ObjectID][]entity.MediaVariant{}
+	if len(ids) > 0 {
+		media, err := s.mediaCollection.GetMediaByIds(ctx, ids)
+		if err != nil {
+			fmt.Println("failed to look up media of quiz", quiz.Id.Hex(), ":", err)
+		}
+
+		for _, m := range media {
+			variants[m.Id] = m.Variants
+		}
+	}
+
+	return s.signQuiz(quiz, variants, time.Now())
+}
+
+// signQuiz signs the media of a quiz given the renditions of every uploaded media.
+func (s MediaService) signQuiz(quiz entity.Quiz, variants map[primitive.ObjectID][]entity.MediaVariant, now time.Time) entity.Quiz {
+	questions := []entity.QuizQuestion{}
+	for _, question := range quiz.Questions {
+		media := []entity.QuestionMedia{}
+		for _, item := range question.Media {
+			if !item.MediaId.IsZero() {
+				item.Url = s.signUrl(item.MediaId, "", now)
+				item.Sizes = map[string]string{}
+				for _, variant := range variants[item.MediaId] {
+					item.Sizes[variant.Size] = s.signUrl(item.MediaId, variant.Size, now)
+				}
+			}
+
+			media = append(media, item)
+		}
+
+		question.Media = media
+		questions = append(questions, question)
+	}
+
+	quiz.Questions = questions
+	return quiz
+}
+
+// mediaKey returns the storage key of media or one of its renditions
+// Parameters:
+// - id: the ObjectID of the media.
+// - size: the size of the rendition, or empty for the original.
+// Returns:
+// - The key the content is stored under.
+func mediaKey(id primitive.ObjectID, size string) string {
+	if size == "" {
+		return id.Hex()
+	}
+
+	return id.Hex() + "-" + size
+}
+
+// findVariant returns the rendition of media in a size, or nil if there is none
+func findVariant(media entity.Media, size string) *entity.MediaVariant {
+	for _, variant := range media.Variants {
+		if variant.Size == size {
+			return &variant
+		}
+	}
+
+	return nil
+}
+
+// hashBytes returns the hex encoded SHA-256 of data
+func hashBytes(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
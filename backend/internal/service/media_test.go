@@ -0,0 +1,144 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func testMediaService() *MediaService {
+	return Media(nil, nil, config.Config{AuthSecret: "secret", MediaUrl: "https://cdn.example.com/", MediaUrlTtl: time.Hour})
+}
+
+func TestSignedMediaUrl(t *testing.T) {
+	s := testMediaService()
+	id := primitive.NewObjectID()
+	now := time.Now()
+
+	signed, err := url.Parse(s.signUrl(id, "", now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signed.Host != "cdn.example.com" || signed.Path != "/api/media/"+id.Hex() {
+		t.Errorf("unexpected URL %s", signed)
+	}
+
+	expires, sig := signed.Query().Get("expires"), signed.Query().Get("sig")
+	if err := s.verify(id, "", expires, sig, now); err != nil {
+		t.Errorf("expected the URL to be valid, got %v", err)
+	}
+
+	if err := s.verify(id, "", expires, sig, now.Add(time.Hour)); err != nil {
+		t.Errorf("expected the URL to stay valid for the configured lifetime, got %v", err)
+	}
+
+	if err := s.verify(id, "", expires, sig, now.Add(3*time.Hour)); err != ErrInvalidSignature {
+		t.Errorf("expected the URL to expire, got %v", err)
+	}
+
+	if err := s.verify(primitive.NewObjectID(), "", expires, sig, now); err != ErrInvalidSignature {
+		t.Errorf("expected the signature not to work for other media, got %v", err)
+	}
+
+	if err := s.verify(id, "", expires+"0", sig, now); err != ErrInvalidSignature {
+		t.Errorf("expected a changed expiry to be rejected, got %v", err)
+	}
+}
+
+func TestSignedMediaUrlIsShared(t *testing.T) {
+	s := testMediaService()
+	id := primitive.NewObjectID()
+	window := time.Unix(time.Now().Unix()/3600*3600, 0)
+
+	// Everyone in the same window receives the same URL, so a CDN can cache it
+	if s.signUrl(id, "", window) != s.signUrl(id, "", window.Add(59*time.Minute)) {
+		t.Error("expected the URL to be stable within a window")
+	}
+}
+
+func TestSignQuiz(t *testing.T) {
+	s := testMediaService()
+	quiz := fuzzQuiz()
+	quiz.Questions[0].Media = []entity.QuestionMedia{
+		{Type: entity.ImageMedia, MediaId: primitive.NewObjectID()},
+		{Type: entity.ImageMedia, Url: "https://example.com/flag.png"},
+	}
+
+	signed := s.signQuiz(quiz, nil, time.Now())
+
+	if !strings.HasPrefix(signed.Questions[0].Media[0].Url, "https://cdn.example.com/api/media/") {
+		t.Errorf("expected uploaded media to be signed, got %q", signed.Questions[0].Media[0].Url)
+	}
+
+	if signed.Questions[0].Media[1].Url != "https://example.com/flag.png" {
+		t.Errorf("expected external media to be left alone, got %q", signed.Questions[0].Media[1].Url)
+	}
+
+	if quiz.Questions[0].Media[0].Url != "" {
+		t.Error("expected the original quiz not to change")
+	}
+}
+
+// signingStorage is a MediaStorage that only signs URLs
+type signingStorage struct {
+	collection.MediaStorage
+}
+
+// SignURL returns a URL recording what it was asked to sign
+func (signingStorage) SignURL(key string, signedAt time.Time, ttl time.Duration) (string, error) {
+	return "https://bucket.example.com/" + key + "?ttl=" + ttl.String(), nil
+}
+
+func TestSignedMediaUrlFromStorage(t *testing.T) {
+	s := Media(nil, signingStorage{}, config.Config{AuthSecret: "secret", MediaUrlTtl: time.Hour})
+	id := primitive.NewObjectID()
+
+	if signed := s.SignUrl(id, ""); signed != "https://bucket.example.com/"+id.Hex()+"?ttl=2h0m0s" {
+		t.Errorf("expected the storage to sign the URL, got %s", signed)
+	}
+}
+
+func TestSignQuizSizes(t *testing.T) {
+	s := testMediaService()
+	id := primitive.NewObjectID()
+	quiz := fuzzQuiz()
+	quiz.Questions[0].Media = []entity.QuestionMedia{{Type: entity.ImageMedia, MediaId: id}}
+
+	now := time.Now()
+	signed := s.signQuiz(quiz, map[primitive.ObjectID][]entity.MediaVariant{
+		id: {{Size: entity.MediumSize}},
+	}, now)
+
+	media := signed.Questions[0].Media[0]
+	medium, err := url.Parse(media.Sizes[entity.MediumSize])
+	if err != nil || medium.Query().Get("size") != entity.MediumSize {
+		t.Fatalf("expected a signed URL of the medium rendition, got %v", media.Sizes)
+	}
+
+	query := medium.Query()
+	if err := s.verify(id, entity.MediumSize, query.Get("expires"), query.Get("sig"), now); err != nil {
+		t.Errorf("expected the rendition URL to be valid, got %v", err)
+	}
+
+	// A signature for one size must not unlock another
+	if err := s.verify(id, entity.FullSize, query.Get("expires"), query.Get("sig"), now); err != ErrInvalidSignature {
+		t.Errorf("expected the signature not to work for another size, got %v", err)
+	}
+
+	phone := sizeMedia(signed.Questions[0].Media, entity.MediumSize)[0]
+	if phone.Url != media.Sizes[entity.MediumSize] || phone.Sizes != nil {
+		t.Errorf("expected phones to get the medium rendition, got %+v", phone)
+	}
+
+	// Without a rendition in the size, the original is used
+	if full := sizeMedia(signed.Questions[0].Media, entity.FullSize)[0]; full.Url != media.Url {
+		t.Errorf("expected the original without a full rendition, got %s", full.Url)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// QuizMetadata is what join pages and link previews show about a quiz, without its questions
+type QuizMetadata struct {
+	Id        primitive.ObjectID `json:"id"`                  // ID of the quiz
+	Name      string             `json:"name"`                // Title of the quiz
+	Questions int                `json:"questions"`           // Number of questions in the quiz
+	Author    string             `json:"author"`              // Name of the owner, empty for quizzes of the open library
+	Cover     string             `json:"cover"`               // URL of the first image of the quiz, empty if it has none
+	Language  string             `json:"language,omitempty"`  // Code of the language of the quiz, if known
+	UpdatedAt *time.Time         `json:"updatedAt,omitempty"` // When the quiz last changed, if known
+}
+
+// IsPublicQuiz reports whether the metadata of a quiz may be shown to anyone, without signing in or
+// holding a share link, so it can be cached by CDNs
+// Parameters:
+// - quiz: the quiz
+// Returns:
+// - bool: true for quizzes of the open library and templates
+func IsPublicQuiz(quiz entity.Quiz) bool {
+	return quiz.Template || CanAccessQuiz(quiz, primitive.NilObjectID, "", entity.ViewPermission)
+}
+
+// GetQuizCover finds the image a quiz is presented with, the first image of its questions
+// Parameters:
+// - quiz: the quiz
+// Returns:
+// - *entity.QuestionMedia: the image, nil if the quiz has none
+func GetQuizCover(quiz entity.Quiz) *entity.QuestionMedia {
+	for _, question := range quiz.Questions {
+		for _, item := range question.Media {
+			if item.Type == entity.ImageMedia {
+				return &item
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewQuizMetadata describes a quiz for join pages and link previews. Uploaded cover images are only reachable
+// through signed URLs that keep changing, so they are pointed to through the stable cover endpoint instead.
+// Parameters:
+// - quiz: the quiz
+// - author: the name of the owner, empty if it has none
+// - baseUrl: the base URL the cover endpoint is served from
+// Returns:
+// - QuizMetadata: the metadata
+func NewQuizMetadata(quiz entity.Quiz, author string, baseUrl string) QuizMetadata {
+	metadata := QuizMetadata{
+		Id:        quiz.Id,
+		Name:      quiz.Name,
+		Questions: len(quiz.Questions),
+		Author:    author,
+		Language:  quiz.Language,
+	}
+
+	if cover := GetQuizCover(quiz); cover != nil {
+		metadata.Cover = cover.Url
+		if !cover.MediaId.IsZero() {
+			metadata.Cover = strings.TrimSuffix(baseUrl, "/") + "/api/public/quizzes/" + quiz.Id.Hex() + "/cover"
+		}
+	}
+
+	if !quiz.UpdatedAt.IsZero() {
+		updatedAt := quiz.UpdatedAt.UTC().Truncate(time.Second)
+		metadata.UpdatedAt = &updatedAt
+	}
+
+	return metadata
+}
+
+// ETag returns a strong entity tag of the metadata, which changes whenever anything in it does
+// Returns:
+// - string: the quoted tag
+func (m QuizMetadata) ETag() string {
+	body, _ := json.Marshal(m)
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestQuizMetadata(t *testing.T) {
+	quiz := fuzzQuiz()
+	quiz.Id = primitive.NewObjectID()
+	quiz.UpdatedAt = time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	quiz.Questions[1].Media = []entity.QuestionMedia{
+		{Type: entity.AudioMedia, Url: "https://cdn.example.com/a.mp3"},
+		{Type: entity.ImageMedia, MediaId: primitive.NewObjectID(), Url: "signed"},
+	}
+
+	metadata := NewQuizMetadata(quiz, "alice", "https://quiz.example.com/")
+	if metadata.Questions != 3 || metadata.Author != "alice" || metadata.UpdatedAt.Nanosecond() != 0 {
+		t.Errorf("expected the quiz to be described, got %+v", metadata)
+	}
+	if metadata.Cover != "https://quiz.example.com/api/public/quizzes/"+quiz.Id.Hex()+"/cover" {
+		t.Errorf("expected uploaded covers to go through the stable endpoint, got %q", metadata.Cover)
+	}
+
+	// The tag stays the same until something shown changes
+	etag := metadata.ETag()
+	if NewQuizMetadata(quiz, "alice", "https://quiz.example.com/").ETag() != etag {
+		t.Errorf("expected the same metadata to keep its tag")
+	}
+	quiz.Name = "Renamed"
+	if NewQuizMetadata(quiz, "alice", "https://quiz.example.com/").ETag() == etag {
+		t.Errorf("expected a renamed quiz to get a new tag")
+	}
+
+	quiz.Questions[1].Media[1].MediaId = primitive.NilObjectID
+	quiz.Questions[1].Media[1].Url = "https://images.example.com/cover.png"
+	quiz.UpdatedAt = time.Time{}
+	if metadata := NewQuizMetadata(quiz, "", ""); metadata.Cover != "https://images.example.com/cover.png" || metadata.UpdatedAt != nil {
+		t.Errorf("expected external covers to be linked directly and no date for untracked quizzes, got %+v", metadata)
+	}
+}
+
+func TestIsPublicQuiz(t *testing.T) {
+	owned := entity.Quiz{OwnerId: primitive.NewObjectID()}
+	if IsPublicQuiz(owned) {
+		t.Errorf("expected quizzes of users to stay private")
+	}
+
+	owned.Template = true
+	if !IsPublicQuiz(owned) || !IsPublicQuiz(entity.Quiz{}) {
+		t.Errorf("expected templates and the open library to be public")
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// natsTimeout is the time allowed to connect to a NATS server and be greeted
+const natsTimeout = 10 * time.Second
+
+// natsServer is where a NATS server listens and who to authenticate as
+type natsServer struct {
+	address  string // Host and port of the server
+	user     string // User to authenticate as, empty for none
+	password string // Password of the user
+}
+
+// parseNatsUrl reads the address of a NATS server
+// Parameters:
+// - address: nats://[user:password@]host[:port] of the server, the port defaulting to 4222
+// Returns:
+// - natsServer: the server
+// - bool: false if the address is not a NATS URL
+func parseNatsUrl(address string) (natsServer, bool) {
+	u, err := url.Parse(address)
+	if err != nil || u.Scheme != "nats" || u.Host == "" {
+		return natsServer{}, false
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	password, _ := u.User.Password()
+	return natsServer{
+		address:  host,
+		user:     u.User.Username(),
+		password: password,
+	}, true
+}
+
+// dial opens a connection to the server and introduces the client with the core NATS text protocol
+// Returns:
+// - net.Conn: the connection
+// - *bufio.Reader: the reader of the connection, past the greeting of the server
+// - error: an error if the server cannot be reached or does not speak NATS
+func (n natsServer) dial() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", n.address, natsTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(natsTimeout))
+	reader := bufio.NewReader(conn)
+	info, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return nil, nil, errors.New("not a NATS server")
+	}
+
+	options, _ := json.Marshal(map[string]any{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "quiz",
+		"user":     n.user,
+		"pass":     n.password,
+	})
+	if _, err := conn.Write(fmt.Appendf(nil, "CONNECT %s\r\n", options)); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	return conn, reader, nil
+}
+
+// natsPub encodes a message published to a subject
+// Parameters:
+// - subject: the subject
+// - data: the payload
+// Returns:
+// - []byte: the PUB command
+func natsPub(subject string, data []byte) []byte {
+	message := fmt.Appendf(nil, "PUB %s %d\r\n", subject, len(data))
+	return append(append(message, data...), "\r\n"...)
+}
@@ -1,48 +1,171 @@
 package service
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
 	"quiz.com/quiz/internal/entity"
 )
 
+// Limits applied to incoming WebSocket messages.
+const (
+	MaxMessageSize  = 4096  // Maximum size in bytes of a single incoming message
+	maxNameLength   = 32    // Maximum length of a player name
+	maxCodeLength   = 16    // Maximum length of a game join code
+	maxQuizIdLength = 24    // Length of a hex encoded ObjectID
+	maxTokenLength  = 1024  // Maximum length of an access token
+	maxChoiceIndex  = 63    // Highest choice index a player may submit
+	avatarCount     = 12    // Number of avatars players can pick from, see AVATARS in the frontend
+	colorCount      = 8     // Number of colors players can pick from, see PLAYER_COLORS in the frontend
+	maxStrikes      = 5     // Number of malformed messages tolerated before the connection is closed
+	maxPingAge      = 30000 // Age in milliseconds after which a pong is ignored
+)
+
+// saveResultTimeout bounds storing a finished game and everything that follows from it
+const saveResultTimeout = 30 * time.Second
+
+// Errors returned when an incoming message is rejected.
+var (
+	ErrUnexpectedMessageType = errors.New("unexpected message type")
+	ErrMessageTooLarge       = errors.New("message too large")
+	ErrMessageTooShort       = errors.New("message too short")
+	ErrUnknownPacket         = errors.New("unknown packet id")
+	ErrInvalidPacket         = errors.New("invalid packet data")
+)
+
 // NetService manages the networking aspect of the quiz game, handling game sessions and WebSocket communication.
 type NetService struct {
-	quizService *QuizService // Reference to the quiz service for quiz-related operations
-	games       []*Game      // List of active games
+	config        config.Config  // Runtime configuration, including WebSocket compression settings
+	quizService   *QuizService   // Reference to the quiz service for quiz-related operations
+	resultService *ResultService // Reference to the result service for storing finished games
+	userService   *UserService   // Reference to the user service for authenticating players and updating ratings
+
+	tournamentService   *TournamentService   // Reference to the tournament service for playing tournament rounds
+	invitationService   *InvitationService   // Reference to the invitation service for emailing join codes
+	announcementService *AnnouncementService // Reference to the announcement service for posting to chat webhooks
+	mediaService        *MediaService        // Reference to the media service for signing media URLs
+	quotaService        *QuotaService        // Reference to the quota service for limiting the games of users
+	geoResolver         GeoResolver          // Locates players for games restricted to countries, nil without a GeoIP database
+	streamService       *StreamService       // Publishes answers and reveals to an analytics stack, nil without a configured stream
+	games               []*Game              // List of active games
+	gamesMu             sync.Mutex           // Guards games
+	wakeMu              sync.Mutex           // Serializes waking hibernated lobbies, so each is opened once
+
+	strikesMu sync.Mutex         // Guards strikes
+	strikes   map[Connection]int // Number of malformed messages received per connection
+
+	joinChecks   *rateLimiter // Lookups of join codes per client address
+	joinFailures *rateLimiter // Attempts to join with unknown codes per client address, locking out those guessing codes
+
+	clock Clock // Source of time of the games, the wall clock outside of tests
+
+	randMu sync.Mutex // Guards rand
+	rand   *rand.Rand // Random source of join codes and the games, seeded by the configuration
+
+	outboxesMu sync.Mutex             // Guards outboxes
+	outboxes   map[Connection]*outbox // Sequenced packets recently sent per connection, for resending
+
+	writeErrorsMu    sync.Mutex                       // Guards the write error counters
+	writeErrors      map[Connection]*ConnectionErrors // Failed writes per open connection
+	totalWriteErrors int64                            // Failed writes since the server started
+	totalTimeouts    int64                            // Timed out writes since the server started
+
+	gamesCreated     atomic.Int64 // Games hosted since the server started
+	gamesEnded       atomic.Int64 // Games that ended since the server started
+	rejectedMessages atomic.Int64 // Malformed messages received since the server started
+	failedJoins      atomic.Int64 // Attempts to join with unknown codes since the server started
+	joinLockouts     atomic.Int64 // Addresses locked out for guessing join codes since the server started
+}
+
+// NetOptions are the services a NetService works with. Any of them may be left nil,
+// as in tests, switching off the features that need it.
+type NetOptions struct {
+	QuizService         *QuizService         // Loads the quizzes to host
+	ResultService       *ResultService       // Stores finished games
+	UserService         *UserService         // Authenticates players and updates their ratings
+	TournamentService   *TournamentService   // Plays tournament rounds
+	InvitationService   *InvitationService   // Emails join codes
+	AnnouncementService *AnnouncementService // Posts games to chat webhooks
+	MediaService        *MediaService        // Signs the media URLs of questions
+	QuotaService        *QuotaService        // Limits the games and players of hosts
+	GeoResolver         GeoResolver          // GeoIP database used to locate players
+	StreamService       *StreamService       // Publishes game events to an analytics stack
 }
 
 // Net initializes and returns a new NetService instance.
 // Parameters:
-// - quizService: the quiz service to associate with this network service.
-func Net(quizService *QuizService) *NetService {
+// - options: the services the network service works with.
+// - config: the runtime configuration.
+func Net(options NetOptions, config config.Config) *NetService {
 	return &NetService{
-		quizService: quizService,
-		games:       []*Game{},
+		config:              config,
+		quizService:         options.QuizService,
+		resultService:       options.ResultService,
+		userService:         options.UserService,
+		tournamentService:   options.TournamentService,
+		invitationService:   options.InvitationService,
+		announcementService: options.AnnouncementService,
+		mediaService:        options.MediaService,
+		quotaService:        options.QuotaService,
+		geoResolver:         options.GeoResolver,
+		streamService:       options.StreamService,
+		games:               []*Game{},
+		strikes:             map[Connection]int{},
+		outboxes:            map[Connection]*outbox{},
+		writeErrors:         map[Connection]*ConnectionErrors{},
+		joinChecks:          newRateLimiter(config.JoinCheckLimit, joinCheckWindow),
+		joinFailures:        newLockoutLimiter(config.JoinAttemptLimit, joinAttemptWindow, config.JoinLockout),
+		clock:               realClock{},
+		rand:                newRand(config.RandomSeed),
 	}
 }
 
 // Packet structures representing different types of messages exchanged between the server and clients.
 type ConnectPacket struct {
-	Code string `json:"code"` // Game code to connect to
-	Name string `json:"name"` // Name of the player
+	Code   string `json:"code"`   // Game code to connect to
+	Name   string `json:"name"`   // Name of the player
+	Token  string `json:"token"`  // Optional access token of a registered user, for rated games
+	Avatar int    `json:"avatar"` // Index of the avatar the player picked
+	Color  int    `json:"color"`  // Index of the color the player picked
+	Device string `json:"device"` // Optional device type of the player: phone, tablet or desktop
 }
 
 type HostGamePacket struct {
-	QuizId string `json:"quizId"` // ID of the quiz to host
+	QuizId       string   `json:"quizId"`       // ID of the quiz to host
+	TournamentId string   `json:"tournamentId"` // Optional ID of the tournament the game is a round of
+	Playlist     []string `json:"playlist"`     // Optional IDs of quizzes to play after the first one, in order
+	ResetPoints  bool     `json:"resetPoints"`  // Whether points start from zero with each quiz of the playlist
+	Token        string   `json:"token"`        // Optional access token of the host, to fetch the game state over HTTP and host shared quizzes
+	ShareToken   string   `json:"shareToken"`   // Optional token of a link the quiz was shared through
+	Code         string   `json:"code"`         // Optional join code of a lobby of the host to take over, e.g. after it hibernated, instead of hosting a new game
 }
 
 type QuestionShowPacket struct {
-	Question entity.QuizQuestion `json:"question"` // The current quiz question
+	Question   entity.QuizQuestion `json:"question"`   // The current quiz question
+	ServerTime int64               `json:"serverTime"` // Server time in milliseconds when the question started
+	EndsAt     int64               `json:"endsAt"`     // Server time in milliseconds when the time to answer runs out
+	Index      int                 `json:"index"`      // Index of the question in the quiz, starting at 0
+	Total      int                 `json:"total"`      // Number of questions in the quiz
+	Slots      []ChoiceSlot        `json:"slots"`      // Color and shape of each choice
+	Seq        uint32              `json:"seq"`        // Sequence number on the connection, see sendSequenced
 }
 
 type ChangeGameStatePacket struct {
-	State GameState `json:"state"` // The current state of the game
+	State  GameState `json:"state"`  // The current state of the game
+	EndsAt int64     `json:"endsAt"` // Server time in milliseconds when the state's timer runs out, zero without a timer
+	Seq    uint32    `json:"seq"`    // Sequence number on the connection, see sendSequenced
 }
 
 type PlayerJoinPacket struct {
@@ -50,27 +173,229 @@ type PlayerJoinPacket struct {
 }
 
 type PlayerDisconnectPacket struct {
-	PlayerId uuid.UUID `json:"playerId"` // ID of the player who disconnected
+	PlayerId      uuid.UUID `json:"playerId"`      // ID of the player who disconnected
+	Reconnectable bool      `json:"reconnectable"` // Whether the player lost their connection and may still resume, false if they are gone for good
 }
 
 type StartGamePacket struct{}
 
 type TickPacket struct {
-	Tick int `json:"tick"` // Time remaining for the current question
+	Tick   int   `json:"tick"`   // Time remaining for the current question
+	EndsAt int64 `json:"endsAt"` // Server time in milliseconds when the timer runs out
 }
 
 type QuestionAnswerPacket struct {
-	Question int `json:"question"` // Index of the answered question
+	Question int           `json:"question"` // Index of the chosen choice; the whole answer of clients that send no type
+	Type     string        `json:"type"`     // Kind of answer, e.g. IndexAnswer; empty for a choice given in Question
+	Payload  AnswerPayload `json:"payload"`  // The answer, in the field of its kind
+
+	Confidence Confidence `json:"confidence"` // How sure the player is, weighing the points in confidence mode; empty for none
 }
 
 type PlayerRevealPacket struct {
-	Points int `json:"points"` // Points awarded to the player
+	Points      int     `json:"points"`      // Points awarded to the player
+	AverageTime float64 `json:"averageTime"` // Average seconds the player took to answer so far
+	EndsAt      int64   `json:"endsAt"`      // Server time in milliseconds when the reveal ends
+	Seq         uint32  `json:"seq"`         // Sequence number on the connection, see sendSequenced
 }
 
 type LeaderboardPacket struct {
 	Points []LeaderboardEntry `json:"points"` // Leaderboard entries
 }
 
+type PingPacket struct {
+	ServerTime int64  `json:"serverTime"` // Server time in milliseconds when the ping was sent
+	Seq        uint32 `json:"seq"`        // Last sequence number sent on the connection, so clients notice a lost last packet
+}
+
+type PongPacket struct {
+	ServerTime int64 `json:"serverTime"` // Server time echoed from the ping
+	ClientTime int64 `json:"clientTime"` // Client time in milliseconds when the ping was received
+}
+
+type PlayerQuestionPacket struct {
+	Choices     int                    `json:"choices"`               // Number of choices the player can pick from
+	Time        int                    `json:"time"`                  // Seconds available to answer
+	ServerTime  int64                  `json:"serverTime"`            // Server time in milliseconds when the question started
+	EndsAt      int64                  `json:"endsAt"`                // Server time in milliseconds when the time to answer runs out
+	ClockOffset int64                  `json:"clockOffset"`           // Measured offset of the player's clock from the server clock in milliseconds
+	Media       []entity.QuestionMedia `json:"media"`                 // Media of the question, sized for phones
+	Index       int                    `json:"index"`                 // Index of the question in the quiz, starting at 0
+	Total       int                    `json:"total"`                 // Number of questions in the quiz
+	Slots       []ChoiceSlot           `json:"slots"`                 // Color and shape of each choice
+	Name        string                 `json:"name,omitempty"`        // Text of the question, only in player-paced games
+	ChoiceNames []string               `json:"choiceNames,omitempty"` // Text of each choice, only in player-paced games
+	Hint        bool                   `json:"hint,omitempty"`        // Whether the question has a hint the player may buy with a HintRequestPacket
+	Seq         uint32                 `json:"seq"`                   // Sequence number on the connection, see sendSequenced
+}
+
+type GameReportPacket struct {
+	Players   []PlayerReport   `json:"players"`   // Per-player results
+	Questions []QuestionReport `json:"questions"` // Per-question results
+	MaxPoints int              `json:"maxPoints"` // Most points a player could earn in the quiz, to grade the points against
+
+	HostActions []entity.HostAction `json:"hostActions"` // What the host did during the game, oldest first
+}
+
+type AchievementPacket struct {
+	Event   RoundEvent `json:"event"`   // The notable outcome of the round
+	Players []string   `json:"players"` // Names of the players involved
+}
+
+type GameEndPacket struct {
+	Podium []LeaderboardEntry  `json:"podium"` // Top players of the game
+	Awards []entity.GameAward  `json:"awards"` // Fun awards beyond raw points
+	Grade  *entity.PlayerGrade `json:"grade"`  // The receiving player's grade, nil for the host or if the quiz is not graded
+	Seq    uint32              `json:"seq"`    // Sequence number on the connection, see sendSequenced
+}
+
+type RecordPacket struct {
+	Name           string `json:"name"`           // Name of the player who set the record
+	Points         int    `json:"points"`         // The new record score
+	PreviousRecord int    `json:"previousRecord"` // The record that was beaten
+}
+
+type TournamentStandingsPacket struct {
+	TournamentId primitive.ObjectID          `json:"tournamentId"` // ID of the tournament
+	Name         string                      `json:"name"`         // Name of the tournament
+	Format       string                      `json:"format"`       // Format of the tournament
+	Round        int                         `json:"round"`        // Index of the round being played
+	Rounds       int                         `json:"rounds"`       // Total number of rounds
+	Standings    []entity.TournamentStanding `json:"standings"`    // Current standings from first to last place
+}
+
+type NextQuestionPreviewPacket struct {
+	Index          int                 `json:"index"`          // Index of the upcoming question
+	Question       entity.QuizQuestion `json:"question"`       // The upcoming question, including which choices are correct
+	CorrectChoices []int               `json:"correctChoices"` // Indexes of the correct choices
+}
+
+type SendInvitationsPacket struct {
+	Emails []string `json:"emails"` // Addresses to email the join code to
+}
+
+type InvitationStatusPacket struct {
+	Invitations []entity.Invitation `json:"invitations"` // Invitations of the batch and their delivery state
+}
+
+type ResultLinkPacket struct {
+	Token     string    `json:"token"`     // Token of the public results page, served at /api/results/:token
+	ExpiresAt time.Time `json:"expiresAt"` // When the page stops working
+}
+
+type PreloadPacket struct {
+	Question int            `json:"question"` // Index of the question the media belongs to
+	Media    []PreloadMedia `json:"media"`    // Media to fetch before the question is shown
+}
+
+type GamePausePacket struct {
+	Paused bool   `json:"paused"` // Whether the game timer is paused
+	Reason string `json:"reason"` // Human readable reason shown to the host
+}
+
+// validatable is implemented by incoming packets that check their own fields after decoding.
+type validatable interface {
+	validate() error
+}
+
+// validate checks that the join code and player name are present and within length limits,
+// and that the avatar and color are from the allowed sets.
+func (p *ConnectPacket) validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" || len(p.Name) > maxNameLength {
+		return ErrInvalidPacket
+	}
+
+	if p.Code == "" || len(p.Code) > maxCodeLength {
+		return ErrInvalidPacket
+	}
+
+	if len(p.Token) > maxTokenLength {
+		return ErrInvalidPacket
+	}
+
+	if p.Avatar < 0 || p.Avatar >= avatarCount || p.Color < 0 || p.Color >= colorCount {
+		return ErrInvalidPacket
+	}
+
+	if p.Device != "" && !lobbyDevices[p.Device] {
+		return ErrInvalidPacket
+	}
+
+	return nil
+}
+
+// validate checks that the quiz ID has the length of a hex encoded ObjectID, unless a lobby is taken over by its code.
+func (p *HostGamePacket) validate() error {
+	if len(p.Code) > maxCodeLength {
+		return ErrInvalidPacket
+	}
+
+	if p.Code == "" && len(p.QuizId) != maxQuizIdLength {
+		return ErrInvalidPacket
+	}
+
+	if p.TournamentId != "" && len(p.TournamentId) != maxQuizIdLength {
+		return ErrInvalidPacket
+	}
+
+	if len(p.Token) > maxTokenLength {
+		return ErrInvalidPacket
+	}
+
+	// Tournament rounds are scored per quiz, so they cannot be playlists
+	if len(p.Playlist) > 0 && (p.TournamentId != "" || len(p.Playlist) >= maxPlaylistLength) {
+		return ErrInvalidPacket
+	}
+
+	for _, quizId := range p.Playlist {
+		if len(quizId) != maxQuizIdLength {
+			return ErrInvalidPacket
+		}
+	}
+
+	return nil
+}
+
+// validate checks the number of addresses; they are parsed when the invitations are created.
+func (p *SendInvitationsPacket) validate() error {
+	if len(p.Emails) == 0 || len(p.Emails) > maxInvitations {
+		return ErrInvalidPacket
+	}
+
+	return nil
+}
+
+// validate checks that the answer is well formed for its kind. Answers without a kind are choices given
+// in Question, they are turned into index answers; Question holds the chosen choice of index answers
+// and -1 for the other kinds.
+func (p *QuestionAnswerPacket) validate() error {
+	if !p.Confidence.valid() {
+		return ErrInvalidPacket
+	}
+
+	if p.Type == "" {
+		if p.Question < 0 || p.Question > maxChoiceIndex {
+			return ErrInvalidPacket
+		}
+
+		index := p.Question
+		p.Type = IndexAnswer
+		p.Payload = AnswerPayload{Index: &index}
+	}
+
+	if err := checkAnswerPayload(p.Type, &p.Payload); err != nil {
+		return err
+	}
+
+	p.Question = -1
+	if p.Type == IndexAnswer {
+		p.Question = *p.Payload.Index
+	}
+
+	return nil
+}
+
 // packetIdToPacket maps a packet ID to the corresponding packet structure.
 // Parameters:
 // - packetId: the ID of the packet type.
@@ -86,6 +411,32 @@ func (c *NetService) packetIdToPacket(packetId uint8) any {
 		return &StartGamePacket{}
 	case 7:
 		return &QuestionAnswerPacket{}
+	case 13:
+		return &PongPacket{}
+	case 33:
+		return &ResendPacket{}
+	case 35:
+		return &ResyncRequestPacket{}
+	case 37:
+		return &FocusLostPacket{}
+	case 41:
+		return &KickPlayerPacket{}
+	case 43:
+		return &MediaCuePacket{}
+	case 44:
+		return &BuzzPacket{}
+	case 46:
+		return &JudgeBuzzPacket{}
+	case 51:
+		return &LeaveGamePacket{}
+	case 52:
+		return &HintRequestPacket{}
+	case 20:
+		return &LobbyVotePacket{}
+	case 23:
+		return &SendInvitationsPacket{}
+	case 29:
+		return &GameSettingsPacket{}
 	}
 
 	return nil
@@ -114,6 +465,74 @@ func (c *NetService) packetToPacketId(packet any) (uint8, error) {
 		return 9, nil
 	case PlayerDisconnectPacket:
 		return 10, nil
+	case GamePausePacket:
+		return 11, nil
+	case PingPacket:
+		return 12, nil
+	case PlayerQuestionPacket:
+		return 14, nil
+	case GameReportPacket:
+		return 15, nil
+	case AchievementPacket:
+		return 16, nil
+	case GameEndPacket:
+		return 17, nil
+	case RecordPacket:
+		return 18, nil
+	case TournamentStandingsPacket:
+		return 19, nil
+	case LobbyVotesPacket:
+		return 21, nil
+	case NextQuestionPreviewPacket:
+		return 22, nil
+	case InvitationStatusPacket:
+		return 24, nil
+	case ResultLinkPacket:
+		return 25, nil
+	case PreloadPacket:
+		return 26, nil
+	case LobbyStatsPacket:
+		return 27, nil
+	case PlaylistPacket:
+		return 28, nil
+	case GameSettingsPacket:
+		return 29, nil
+	case GameInfoPacket:
+		return 30, nil
+	case PlayerIdlePacket:
+		return 31, nil
+	case AnswerAckPacket:
+		return 32, nil
+	case ResendPacket:
+		return 33, nil
+	case StateDigestPacket:
+		return 34, nil
+	case ResyncRequestPacket:
+		return 35, nil
+	case StateSnapshotPacket:
+		return 36, nil
+	case PlayerFlagPacket:
+		return 38, nil
+	case PacedProgressPacket:
+		return 39, nil
+	case GameWarningPacket:
+		return 40, nil
+	case SessionPacket:
+		return 42, nil
+	case MediaCuePacket:
+		return 43, nil
+	case BuzzOrderPacket:
+		return 45, nil
+	case TieBreakerPacket:
+		return 47, nil
+	case MatchLeaderboardPacket:
+		return 48, nil
+	case PlayerEliminatedPacket:
+		return 49, nil
+	case EliminationPacket:
+		return 50, nil
+	case HintPacket:
+		return 53, nil
 	}
 
 	return 0, errors.New("invalid packet type")
@@ -125,6 +544,9 @@ func (c *NetService) packetToPacketId(packet any) (uint8, error) {
 // Returns:
 // - The game instance or nil if not found.
 func (c *NetService) getGameByCode(code string) *Game {
+	c.gamesMu.Lock()
+	defer c.gamesMu.Unlock()
+
 	for _, game := range c.games {
 		if game.Code == code {
 			return game
@@ -134,14 +556,18 @@ func (c *NetService) getGameByCode(code string) *Game {
 	return nil
 }
 
-// getGameByHost retrieves a game by its host connection.
+// getGameByHost retrieves a game by its host connection. A packet of the host counts as activity of the game.
 // Parameters:
 // - host: the WebSocket connection of the host.
 // Returns:
 // - The game instance or nil if not found.
-func (c *NetService) getGameByHost(host *websocket.Conn) *Game {
+func (c *NetService) getGameByHost(host Connection) *Game {
+	c.gamesMu.Lock()
+	defer c.gamesMu.Unlock()
+
 	for _, game := range c.games {
 		if game.Host == host {
+			game.touch()
 			return game
 		}
 	}
@@ -149,15 +575,20 @@ func (c *NetService) getGameByHost(host *websocket.Conn) *Game {
 	return nil
 }
 
-// getGameByPlayer retrieves a game and the player by the player's connection.
+// getGameByPlayer retrieves a game and the player by the player's connection. A packet of a player counts as
+// activity of the game.
 // Parameters:
 // - con: the WebSocket connection of the player.
 // Returns:
 // - The game instance and player instance or nil if not found.
-func (c *NetService) getGameByPlayer(con *websocket.Conn) (*Game, *Player) {
+func (c *NetService) getGameByPlayer(con Connection) (*Game, *Player) {
+	c.gamesMu.Lock()
+	defer c.gamesMu.Unlock()
+
 	for _, game := range c.games {
 		for _, player := range game.Players {
 			if player.Connection == con {
+				game.touch()
 				return game, player
 			}
 		}
@@ -166,63 +597,501 @@ func (c *NetService) getGameByPlayer(con *websocket.Conn) (*Game, *Player) {
 	return nil, nil
 }
 
-// OnDisconnect handles a player's disconnection from the game.
+// addGame registers a game so players can join it by its code.
 // Parameters:
-// - con: the WebSocket connection of the player who disconnected.
-func (c *NetService) OnDisconnect(con *websocket.Conn) {
-	game, player := c.getGameByPlayer(con)
+// - game: the game to register.
+func (c *NetService) addGame(game *Game) {
+	c.gamesMu.Lock()
+	defer c.gamesMu.Unlock()
+
+	c.games = append(c.games, game)
+	c.gamesCreated.Add(1)
+}
+
+// removeGame unregisters a game, freeing its join code.
+// Parameters:
+// - game: the game to remove.
+func (c *NetService) removeGame(game *Game) {
+	c.gamesMu.Lock()
+	defer c.gamesMu.Unlock()
+
+	filter := []*Game{}
+	for _, g := range c.games {
+		if g == game {
+			continue
+		}
+
+		filter = append(filter, g)
+	}
+
+	c.games = filter
+}
+
+// GameSummary describes an active game for administrators
+type GameSummary struct {
+	Code     string             `json:"code"`     // Code for players to join the game
+	QuizId   primitive.ObjectID `json:"quizId"`   // ID of the quiz being played
+	QuizName string             `json:"quizName"` // Name of the quiz being played
+	State    GameState          `json:"state"`    // Current state of the game
+	Question int                `json:"question"` // Index of the current question, -1 before the first
+	Players  int                `json:"players"`  // Number of connected players
+	Ended    bool               `json:"ended"`    // Whether the game has finished
+	Limits   GameLimits         `json:"limits"`   // What the game gave up to stay within the limits of the server
+}
+
+// GetGames lists the games currently registered on the server.
+// Returns:
+// - A summary of every game.
+func (c *NetService) GetGames() []GameSummary {
+	c.gamesMu.Lock()
+	games := append([]*Game{}, c.games...)
+	c.gamesMu.Unlock()
+
+	summaries := []GameSummary{}
+	for _, game := range games {
+		game.run("summary", func() {
+			summaries = append(summaries, GameSummary{
+				Code:     game.Code,
+				QuizId:   game.Quiz.Id,
+				QuizName: game.Quiz.Name,
+				State:    game.State,
+				Question: game.CurrentQuestion,
+				Players:  len(game.Players),
+				Ended:    game.Ended,
+				Limits:   game.limits,
+			})
+		})
+	}
+
+	return summaries
+}
+
+// EndGame ends a game on behalf of an administrator and frees its join code.
+// Games that already started end normally and keep their results; games still in the lobby are closed.
+// The host and players are then disconnected with CloseGameEnded.
+// Parameters:
+// - code: the join code of the game.
+// Returns:
+// - false if there is no game with the code.
+func (c *NetService) EndGame(code string) bool {
+	game := c.getGameByCode(code)
 	if game == nil {
+		return false
+	}
+
+	game.run("admin end", func() {
+		switch {
+		case game.Ended:
+		case game.State == LobbyState:
+			game.closeLobby()
+		default:
+			game.End()
+		}
+
+		c.removeGame(game)
+		game.closeConnections(CloseGameEnded)
+	})
+
+	return true
+}
+
+// announceGame posts that a game is open for players to the chat webhooks, if any are configured.
+// Parameters:
+// - game: the game that was hosted.
+func (c *NetService) announceGame(game *Game) {
+	if c.announcementService == nil {
 		return
 	}
 
-	game.OnPlayerDisconnect(player)
+	c.announcementService.AnnounceGame(game.Quiz, game.Code)
 }
 
-// OnIncomingMessage handles an incoming WebSocket message.
+// announcePodium posts the final podium of a game to the chat webhooks, if any are configured.
 // Parameters:
-// - con: the WebSocket connection from which the message was received.
-// - mt: the message type (text/binary).
-// - msg: the raw message data.
-func (c *NetService) OnIncomingMessage(con *websocket.Conn, mt int, msg []byte) {
-	if len(msg) < 2 {
+// - game: the game that ended.
+// - podium: the top players of the game.
+func (c *NetService) announcePodium(game *Game, podium []LeaderboardEntry) {
+	if c.announcementService == nil {
 		return
 	}
 
-	packetId := msg[0]
-	data := msg[1:]
+	c.announcementService.AnnouncePodium(game.Quiz, podium)
+}
 
-	packet := c.packetIdToPacket(packetId)
-	if packet == nil {
+// saveResult stores the result of a finished game in the background so the game is not held up.
+// Parameters:
+// - game: the finished game.
+// - result: the result to store.
+func (c *NetService) saveResult(game *Game, result entity.GameResult) {
+	// Games nobody played are not worth keeping
+	if c.resultService == nil || len(result.Players) == 0 {
+		c.releaseTournamentRound(game)
 		return
 	}
 
-	err := json.Unmarshal(data, packet)
+	go func() {
+		// The game has ended, so nobody is waiting on this; bound it so it cannot pile up
+		ctx, cancel := context.WithTimeout(context.Background(), saveResultTimeout)
+		defer cancel()
+
+		c.shareResult(&result)
+
+		resultId, err := c.resultService.SaveResult(ctx, result)
+		if err != nil {
+			fmt.Println("failed to save result of game", result.Code, ":", err)
+			c.releaseTournamentRound(game)
+		}
+
+		// The link only works once the result is stored
+		if err == nil && result.ShareToken != "" {
+			game.run("result link", func() {
+				game.sendToHost(ResultLinkPacket{
+					Token:     result.ShareToken,
+					ExpiresAt: result.ShareExpiresAt,
+				})
+			})
+		}
+
+		if err == nil && game.tournament != nil {
+			c.completeTournamentRound(ctx, game, resultId)
+		}
+
+		if c.quizService != nil {
+			if err := c.quizService.RecordQuestionStats(ctx, result.QuizId, result.Questions); err != nil {
+				fmt.Println("failed to calibrate questions after game", result.Code, ":", err)
+			}
+		}
+
+		if c.userService == nil {
+			return
+		}
+
+		if err := c.userService.UpdateRatings(ctx, result); err != nil {
+			fmt.Println("failed to update ratings after game", result.Code, ":", err)
+		}
+	}()
+}
+
+// shareResult gives a result a public results link, unless those are disabled.
+// Parameters:
+// - result: the result to share, before it is stored.
+func (c *NetService) shareResult(result *entity.GameResult) {
+	if c.config.ResultShareTtl <= 0 {
+		return
+	}
+
+	token, err := newShareToken()
 	if err != nil {
+		fmt.Println("failed to create results link of game", result.Code, ":", err)
+		return
+	}
+
+	result.ShareToken = token
+	result.ShareExpiresAt = result.EndedAt.Add(c.config.ResultShareTtl)
+}
+
+// startTournamentRound prepares a game to be played as the next round of a tournament.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - game: the newly created game.
+// - tournamentId: the hex encoded ID of the tournament.
+// Returns:
+// - The standings before the round and an error if the game cannot be played for the tournament.
+func (c *NetService) startTournamentRound(ctx context.Context, game *Game, tournamentId string) ([]entity.TournamentStanding, error) {
+	if c.tournamentService == nil {
+		return nil, ErrTournamentNotFound
+	}
+
+	id, err := primitive.ObjectIDFromHex(tournamentId)
+	if err != nil {
+		return nil, err
+	}
+
+	tournament, round, standings, err := c.tournamentService.StartRound(ctx, id, game.hostUserId, game.Quiz.Id, game.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entrants are told apart by their accounts, so players must sign in
+	game.tournament = tournament
+	game.round = round
+	game.allowedUsers = advancingPlayers(tournament.Format, round, standings)
+	game.Settings.RequireSignIn = true
+	return standings, nil
+}
+
+// releaseTournamentRound gives up the round a tournament game claimed when the game ends without a stored result,
+// so the owner can host the round again.
+// Parameters:
+// - game: the game, which may not be played for a tournament.
+func (c *NetService) releaseTournamentRound(game *Game) {
+	if game.tournament == nil || c.tournamentService == nil {
+		return
+	}
+
+	id, round, code := game.tournament.Id, game.round, game.Code
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), saveResultTimeout)
+		defer cancel()
+
+		if err := c.tournamentService.ReleaseRound(ctx, id, round, code); err != nil {
+			fmt.Println("failed to release tournament round of game", code, ":", err)
+		}
+	}()
+}
+
+// newTournamentStandingsPacket builds the standings packet shown on the host screen of a tournament game.
+// Parameters:
+// - game: the tournament game.
+// - standings: the standings to show.
+// Returns:
+// - The packet to send to the host.
+func newTournamentStandingsPacket(game *Game, standings []entity.TournamentStanding) TournamentStandingsPacket {
+	return TournamentStandingsPacket{
+		TournamentId: game.tournament.Id,
+		Name:         game.tournament.Name,
+		Format:       game.tournament.Format,
+		Round:        game.round,
+		Rounds:       len(game.tournament.Rounds),
+		Standings:    standings,
+	}
+}
+
+// completeTournamentRound records a finished tournament game and shows the updated standings on the host screen.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - game: the finished game.
+// - resultId: the ID of the stored game result.
+func (c *NetService) completeTournamentRound(ctx context.Context, game *Game, resultId primitive.ObjectID) {
+	standings, err := c.tournamentService.CompleteRound(ctx, game.tournament.Id, game.round, resultId)
+	if err != nil {
+		fmt.Println("failed to complete tournament round of game", game.Code, ":", err)
+		return
+	}
+
+	game.run("standings", func() {
+		game.sendToHost(newTournamentStandingsPacket(game, standings))
+	})
+}
+
+// sendInvitations emails the join code of a game and keeps the host informed about delivery.
+// The host receives an InvitationStatusPacket when the invitations are created and after each email.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - game: the game to invite to.
+// - emails: the addresses to invite.
+func (c *NetService) sendInvitations(ctx context.Context, game *Game, emails []string) {
+	onUpdate := func(invitations []entity.Invitation) {
+		game.run("invitations", func() {
+			game.sendToHost(InvitationStatusPacket{
+				Invitations: invitations,
+			})
+		})
+	}
+
+	// Signed in hosts are limited by their account, anonymous ones by their address
+	sender := "address:" + joinAddress(game.Host)
+	if !game.hostUserId.IsZero() {
+		sender = "user:" + game.hostUserId.Hex()
+	}
+
+	if _, err := c.invitationService.InviteToGame(ctx, sender, game.Code, game.Quiz.Name, emails, onUpdate); err != nil {
 		fmt.Println(err)
+	}
+}
+
+// authenticate resolves the user behind an optional access token.
+// Parameters:
+// - token: the access token sent by the player, possibly empty.
+// Returns:
+// - The ID of the user, or primitive.NilObjectID if the player is anonymous or the token is invalid.
+func (c *NetService) authenticate(token string) primitive.ObjectID {
+	if token == "" || c.userService == nil {
+		return primitive.NilObjectID
+	}
+
+	claims, err := c.userService.VerifyToken(token)
+	if err != nil {
+		return primitive.NilObjectID
+	}
+
+	userId, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		return primitive.NilObjectID
+	}
+
+	return userId
+}
+
+// getRecord looks up the all-time best score of a quiz.
+// Parameters:
+// - ctx: the context bounding the database operation.
+// - quizId: the ObjectID of the quiz.
+// Returns:
+// - The record score, or -1 if the quiz has no stored scores or they could not be loaded.
+func (c *NetService) getRecord(ctx context.Context, quizId primitive.ObjectID) int {
+	if c.resultService == nil {
+		return -1
+	}
+
+	record, err := c.resultService.GetRecord(ctx, quizId)
+	if err != nil {
+		fmt.Println(err)
+		return -1
+	}
+
+	if record == nil {
+		return -1
+	}
+
+	return record.Points
+}
+
+// decodePacket validates a raw incoming message and decodes it into its packet structure.
+// Parameters:
+// - mt: the message type (text/binary).
+// - msg: the raw message data.
+// Returns:
+// - The decoded packet or an error describing why the message was rejected.
+func (c *NetService) decodePacket(mt int, msg []byte) (any, error) {
+	if mt != websocket.BinaryMessage {
+		return nil, ErrUnexpectedMessageType
+	}
+
+	if len(msg) > MaxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+
+	if len(msg) < 2 {
+		return nil, ErrMessageTooShort
+	}
+
+	packet := c.packetIdToPacket(msg[0])
+	if packet == nil {
+		return nil, ErrUnknownPacket
+	}
+
+	if err := json.Unmarshal(msg[1:], packet); err != nil {
+		return nil, err
+	}
+
+	if v, ok := packet.(validatable); ok {
+		if err := v.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return packet, nil
+}
+
+// strike records a malformed message from a connection and closes the connection
+// once it has sent too many.
+// Parameters:
+// - con: the WebSocket connection that sent the malformed message.
+// - reason: the error describing why the message was rejected.
+func (c *NetService) strike(con Connection, reason error) {
+	c.strikesMu.Lock()
+	c.strikes[con]++
+	strikes := c.strikes[con]
+	c.strikesMu.Unlock()
+
+	c.rejectedMessages.Add(1)
+	fmt.Println("rejected message:", reason)
+
+	if strikes >= maxStrikes {
+		fmt.Println("closing connection after", strikes, "malformed messages")
+		c.CloseConnection(con, CloseMalformed)
+	}
+}
+
+// OnDisconnect handles a player's disconnection from the game.
+// Parameters:
+// - con: the WebSocket connection of the player who disconnected.
+func (c *NetService) OnDisconnect(con Connection) {
+	c.strikesMu.Lock()
+	delete(c.strikes, con)
+	c.strikesMu.Unlock()
+
+	c.outboxesMu.Lock()
+	delete(c.outboxes, con)
+	c.outboxesMu.Unlock()
+	c.forgetWriteErrors(con)
+
+	game, player := c.getGameByPlayer(con)
+	if game == nil {
 		return
 	}
 
-	fmt.Println(packet)
+	game.run("disconnect", func() {
+		game.keepSession(player)
+		game.OnPlayerDisconnect(player)
+	})
+}
+
+// OnIncomingMessage handles an incoming WebSocket message.
+// Parameters:
+// - ctx: the context of the connection, cancelled when it closes.
+// - con: the WebSocket connection from which the message was received.
+// - mt: the message type (text/binary).
+// - msg: the raw message data.
+func (c *NetService) OnIncomingMessage(ctx context.Context, con Connection, mt int, msg []byte) {
+	packet, err := c.decodePacket(mt, msg)
+	if err != nil {
+		c.strike(con, err)
+		return
+	}
 
 	switch data := packet.(type) {
 	case *ConnectPacket:
 		{
+			// Addresses guessing codes are turned away before any lookup
+			if c.joinLockedOut(joinAddress(con)) > 0 {
+				c.CloseConnection(con, CloseTooManyAttempts)
+				return
+			}
+
+			// Joining a hibernated lobby wakes it
 			game := c.getGameByCode(data.Code)
 			if game == nil {
+				game = c.wakeGame(data.Code)
+			}
+			if game == nil {
+				if c.failJoin(con, data.Code) {
+					c.CloseConnection(con, CloseTooManyAttempts)
+				}
 				return
 			}
+			game.touch()
 
-			game.OnPlayerJoin(data.Name, con)
+			userId := c.authenticate(data.Token)
+			account := c.getSignInAccount(ctx, game, userId)
+			game.run("join", func() {
+				name, code := game.joinAs(data.Name, account)
+				if code != 0 {
+					fmt.Println(data.Name, "must sign in to join the game")
+					c.CloseConnection(con, code)
+					return
+				}
+
+				game.OnPlayerJoin(name, userId, data.Avatar, data.Color, data.Device, con)
+			})
 		}
 	case *HostGamePacket:
 		{
+			if data.Code != "" {
+				if err := c.resumeHosting(con, data.Code, data.Token); err != nil {
+					fmt.Println(err)
+				}
+				return
+			}
+
 			quizId, err := primitive.ObjectIDFromHex(data.QuizId)
 			if err != nil {
 				fmt.Println(err)
 				return
 			}
 
-			quiz, err := c.quizService.quizCollection.GetQuizById(quizId)
+			quiz, err := c.quizService.GetQuizById(ctx, quizId)
 			if err != nil {
 				fmt.Println(err)
 				return
@@ -232,9 +1101,47 @@ func (c *NetService) OnIncomingMessage(con *websocket.Conn, mt int, msg []byte)
 				return
 			}
 
+			// Quizzes with an owner are only hosted by the users and links they are shared with
+			hostUserId := c.authenticate(data.Token)
+			if !CanAccessQuiz(*quiz, hostUserId, data.ShareToken, entity.HostPermission) {
+				fmt.Println(ErrQuizForbidden)
+				return
+			}
+
+			// Uploaded media is only reachable through signed URLs
+			if c.mediaService != nil {
+				*quiz = c.mediaService.SignQuiz(ctx, *quiz)
+			}
+
 			// Create a new game and associate it with the host
 			game := newGame(*quiz, con, c)
-			c.games = append(c.games, &game)
+			game.record = c.getRecord(ctx, quiz.Id)
+			game.hostUserId = hostUserId
+
+			var standings []entity.TournamentStanding
+			if data.TournamentId != "" {
+				standings, err = c.startTournamentRound(ctx, game, data.TournamentId)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+			}
+
+			if len(data.Playlist) > 0 {
+				if err := c.loadPlaylist(ctx, game, data.Playlist, data.ResetPoints); err != nil {
+					fmt.Println(err)
+					return
+				}
+			}
+
+			if err := c.applyQuota(ctx, game); err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			c.addGame(game)
+			c.announceGame(game)
+			go game.runLobbyStats()
 
 			// Notify the host of the game state
 			c.SendPacket(con, HostGamePacket{
@@ -243,6 +1150,14 @@ func (c *NetService) OnIncomingMessage(con *websocket.Conn, mt int, msg []byte)
 			c.SendPacket(con, ChangeGameStatePacket{
 				State: game.State,
 			})
+			game.sendPreload(con, 0, entity.FullSize)
+
+			if game.tournament != nil {
+				c.SendPacket(con, newTournamentStandingsPacket(game, standings))
+			}
+			if len(game.Playlist) > 0 {
+				c.SendPacket(con, game.newPlaylistPacket([]LeaderboardEntry{}))
+			}
 		}
 	case *StartGamePacket:
 		{
@@ -251,7 +1166,7 @@ func (c *NetService) OnIncomingMessage(con *websocket.Conn, mt int, msg []byte)
 				return
 			}
 
-			game.StartOrSkip()
+			game.run("start", game.StartOrSkip)
 		}
 	case *QuestionAnswerPacket:
 		{
@@ -260,7 +1175,147 @@ func (c *NetService) OnIncomingMessage(con *websocket.Conn, mt int, msg []byte)
 				return
 			}
 
-			game.OnPlayerAnswer(data.Question, player)
+			game.run("answer", func() {
+				game.OnAnswer(*data, player)
+			})
+		}
+	case *PongPacket:
+		{
+			game, player := c.getGameByPlayer(con)
+			if game == nil {
+				return
+			}
+
+			game.run("pong", func() {
+				game.OnPlayerPong(player, data)
+			})
+		}
+	case *SendInvitationsPacket:
+		{
+			game := c.getGameByHost(con)
+			if game == nil || c.invitationService == nil {
+				return
+			}
+
+			game.run("invite", func() {
+				game.recordHostAction(InviteHostAction, nil, fmt.Sprintf("%d invitations", len(data.Emails)))
+			})
+			c.sendInvitations(ctx, game, data.Emails)
+		}
+	case *GameSettingsPacket:
+		{
+			game := c.getGameByHost(con)
+			if game == nil {
+				return
+			}
+
+			game.run("settings", func() {
+				game.OnSettings(data.Settings)
+			})
+		}
+	case *ResendPacket:
+		c.OnResend(con, data.From)
+	case *ResyncRequestPacket:
+		if game := c.getGameByHost(con); game != nil {
+			game.run("host resync", game.OnHostResync)
+			return
+		}
+
+		game, player := c.getGameByPlayer(con)
+		if game == nil {
+			return
+		}
+
+		game.run("resync", func() {
+			game.OnPlayerResync(player)
+		})
+	case *LobbyVotePacket:
+		{
+			game, player := c.getGameByPlayer(con)
+			if game == nil {
+				return
+			}
+
+			game.run("lobby vote", func() {
+				game.OnLobbyVote(player, data)
+			})
+		}
+	case *FocusLostPacket:
+		{
+			game, player := c.getGameByPlayer(con)
+			if game == nil {
+				return
+			}
+
+			game.run("focus lost", func() {
+				game.OnPlayerFocusLost(player)
+			})
+		}
+	case *KickPlayerPacket:
+		{
+			game := c.getGameByHost(con)
+			if game == nil {
+				return
+			}
+
+			game.run("kick", func() {
+				game.OnKickPlayer(data)
+			})
+		}
+	case *MediaCuePacket:
+		{
+			game := c.getGameByHost(con)
+			if game == nil {
+				return
+			}
+
+			game.run("media cue", func() {
+				game.OnMediaCue(data)
+			})
+		}
+	case *HintRequestPacket:
+		{
+			game, player := c.getGameByPlayer(con)
+			if game == nil {
+				return
+			}
+
+			game.run("hint", func() {
+				game.OnHintRequest(player)
+			})
+		}
+	case *BuzzPacket:
+		{
+			game, player := c.getGameByPlayer(con)
+			if game == nil {
+				return
+			}
+
+			game.run("buzz", func() {
+				game.OnPlayerBuzz(player)
+			})
+		}
+	case *JudgeBuzzPacket:
+		{
+			game := c.getGameByHost(con)
+			if game == nil {
+				return
+			}
+
+			game.run("judge buzz", func() {
+				game.OnJudgeBuzz(data)
+			})
+		}
+	case *LeaveGamePacket:
+		{
+			game, player := c.getGameByPlayer(con)
+			if game == nil {
+				return
+			}
+
+			game.run("leave", func() {
+				game.OnPlayerLeave(player)
+			})
 		}
 	}
 }
@@ -271,32 +1326,112 @@ func (c *NetService) OnIncomingMessage(con *websocket.Conn, mt int, msg []byte)
 // - packet: the packet structure to send.
 // Returns:
 // - error: any error encountered during sending, or nil if successful.
-func (c *NetService) SendPacket(connection *websocket.Conn, packet any) error {
-	bytes, err := c.PacketToBytes(packet)
+func (c *NetService) SendPacket(connection Connection, packet any) error {
+	switch p := packet.(type) {
+	case sequenced:
+		return c.sendSequenced(connection, p)
+	case PingPacket:
+		p.Seq = c.lastSequence(connection)
+		packet = p
+	}
+
+	encoded, err := c.encodePacket(packet)
 	if err != nil {
 		return err
 	}
+	defer encoded.release()
+
+	buf := c.seal(connection, encoded)
+	defer releasePacket(buf)
 
-	return connection.WriteMessage(websocket.BinaryMessage, bytes)
+	return c.writePacket(connection, buf.Bytes())
 }
 
-// PacketToBytes converts a packet structure into a byte slice for transmission.
+// writePacket writes an encoded packet to a client over the WebSocket connection.
+// A write running past the configured write timeout closes the connection.
+// Parameters:
+// - connection: the WebSocket connection to write to.
+// - data: the encoded packet.
+// Returns:
+// - error: any error encountered during writing, or nil if successful.
+func (c *NetService) writePacket(connection Connection, data []byte) error {
+	// Only spend CPU on compressing packets large enough to benefit from it
+	if con, ok := connection.(compressible); ok && c.config.WsCompression {
+		con.EnableWriteCompression(c.shouldCompress(len(data)))
+	}
+
+	c.setWriteDeadline(connection)
+	if err := connection.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		c.recordWriteError(connection, err)
+		return err
+	}
+
+	return nil
+}
+
+// shouldCompress decides whether a packet of the given size is worth compressing.
+// Parameters:
+// - size: the encoded size of the packet in bytes.
+// Returns:
+// - true if compression is enabled and the packet reaches the configured minimum size.
+func (c *NetService) shouldCompress(size int) bool {
+	return c.config.WsCompression && size >= c.config.WsCompressionMinSize
+}
+
+// PacketToBytes converts a packet structure into a byte slice for transmission, in an envelope
+// outside of any connection and game.
 // Parameters:
 // - packet: the packet structure to convert.
 // Returns:
-// - []byte: the byte representation of the packet.
+// - []byte: the byte representation of the packet, owned by the caller.
 // - error: any error encountered during conversion, or nil if successful.
 func (c *NetService) PacketToBytes(packet any) ([]byte, error) {
-	packetId, err := c.packetToPacketId(packet)
+	encoded, err := c.encodePacket(packet)
 	if err != nil {
 		return nil, err
 	}
+	defer encoded.release()
 
-	bytes, err := json.Marshal(packet)
-	if err != nil {
+	buf := encoded.envelope(uuid.Nil, 0)
+	defer releasePacket(buf)
+
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// maxPooledPacket is the capacity of the largest encoding buffer kept for reuse, so one huge packet does not pin its memory
+const maxPooledPacket = 64 * 1024
+
+// packetBuffers recycles the buffers packets are encoded into
+var packetBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// marshalPacket encodes the JSON body of a packet into a pooled buffer.
+// Parameters:
+// - packet: the packet structure to encode.
+// Returns:
+// - *bytes.Buffer: the encoded body, to hand back with releasePacket once sealed in its envelope.
+// - error: any error encountered during encoding, or nil if successful.
+func (c *NetService) marshalPacket(packet any) (*bytes.Buffer, error) {
+	buf := packetBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	if err := json.NewEncoder(buf).Encode(packet); err != nil {
+		releasePacket(buf)
 		return nil, err
 	}
 
-	final := append([]byte{packetId}, bytes...)
-	return final, nil
+	// Encode ends the body with a newline json.Marshal does not add
+	buf.Truncate(buf.Len() - 1)
+	return buf, nil
+}
+
+// releasePacket hands an encoding buffer back to the pool.
+// Parameters:
+// - buf: the buffer, not to be used afterwards.
+func releasePacket(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledPacket {
+		return
+	}
+
+	packetBuffers.Put(buf)
 }
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// benchPackets returns representative outgoing packets of different sizes
+func benchPackets() map[string]any {
+	choices := []entity.QuizChoice{}
+	for i := 0; i < 4; i++ {
+		choices = append(choices, entity.QuizChoice{
+			Id:   fmt.Sprint("choice-", i),
+			Name: strings.Repeat("Answer text ", 4),
+		})
+	}
+
+	leaderboard := []LeaderboardEntry{}
+	for i := 0; i < 3; i++ {
+		leaderboard = append(leaderboard, LeaderboardEntry{Name: fmt.Sprint("Player ", i), Points: 4000 - i*250})
+	}
+
+	return map[string]any{
+		"tick": TickPacket{Tick: 17},
+		"question": QuestionShowPacket{Question: entity.QuizQuestion{
+			Id:      "question",
+			Name:    strings.Repeat("What is the capital city of this country? ", 3),
+			Time:    20,
+			Choices: choices,
+		}},
+		"leaderboard": LeaderboardPacket{Points: leaderboard},
+	}
+}
+
+// BenchmarkPacketCompression reports the raw and deflated size of common packets
+// to help choose QUIZ_WS_COMPRESSION_MIN_SIZE
+func BenchmarkPacketCompression(b *testing.B) {
+	c := Net(NetOptions{}, config.Config{})
+
+	for name, packet := range benchPackets() {
+		raw, err := c.PacketToBytes(packet)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for _, level := range []int{flate.BestSpeed, flate.DefaultCompression} {
+			b.Run(fmt.Sprintf("%s/level=%d", name, level), func(b *testing.B) {
+				var buf bytes.Buffer
+				writer, _ := flate.NewWriter(&buf, level)
+
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					buf.Reset()
+					writer.Reset(&buf)
+					writer.Write(raw)
+					writer.Flush()
+				}
+
+				b.ReportMetric(float64(len(raw)), "raw-bytes")
+				b.ReportMetric(float64(buf.Len()), "deflated-bytes")
+			})
+		}
+	}
+}
+
+// discardConnection drops every message, so benchmarks measure encoding rather than a fake connection
+type discardConnection struct{}
+
+func (discardConnection) WriteMessage(messageType int, data []byte) error { return nil }
+
+func (discardConnection) Close() error { return nil }
+
+// BenchmarkBroadcast compares encoding a broadcast once for every player with encoding it once for the game,
+// at the size of a large classroom event
+func BenchmarkBroadcast(b *testing.B) {
+	const players = 500
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(entity.Quiz{}, nil, c)
+	for i := 0; i < players; i++ {
+		game.Players = append(game.Players, &Player{Id: uuid.New(), Connection: discardConnection{}})
+	}
+
+	for name, packet := range benchPackets() {
+		// Sequenced packets are numbered per connection and cannot share an encoding
+		if _, ok := packet.(sequenced); ok {
+			continue
+		}
+
+		b.Run(fmt.Sprintf("%s/players=%d/encode-each", name, players), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, player := range game.Players {
+					data, _ := c.PacketToBytes(packet)
+					c.writePacket(player.Connection, data)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("%s/players=%d/encode-once", name, players), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				game.broadcast(packet, false)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/gofiber/contrib/websocket"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// fuzzQuiz returns a small quiz used as the game state machine fixture
+func fuzzQuiz() entity.Quiz {
+	return entity.Quiz{
+		Name: "Fuzz",
+		Questions: []entity.QuizQuestion{
+			{Id: "q1", Name: "One", Time: 3, Choices: []entity.QuizChoice{{Name: "a", Correct: true}, {Name: "b"}}},
+			{Id: "q2", Name: "Two", Time: 2, Choices: []entity.QuizChoice{{Name: "a"}, {Name: "b", Correct: true}, {Name: "c"}}},
+			{Id: "q3", Name: "Three", Time: 1, Choices: []entity.QuizChoice{}},
+		},
+	}
+}
+
+func FuzzDecodePacket(f *testing.F) {
+	f.Add(uint8(websocket.BinaryMessage), encodePacket(0, ConnectPacket{Code: "123456", Name: "alice"}))
+	f.Add(uint8(websocket.BinaryMessage), encodePacket(1, HostGamePacket{QuizId: "66b1e6a5c2a6f0b4d1e2f3a4"}))
+	f.Add(uint8(websocket.BinaryMessage), encodePacket(5, StartGamePacket{}))
+	f.Add(uint8(websocket.BinaryMessage), encodePacket(7, QuestionAnswerPacket{Question: 2}))
+	f.Add(uint8(websocket.BinaryMessage), []byte("\x07{\"question\":-9223372036854775808}"))
+	f.Add(uint8(websocket.BinaryMessage), []byte("\x07{\"question\":1e309}"))
+	f.Add(uint8(websocket.BinaryMessage), []byte("\x07{\"type\":\"coordinates\",\"payload\":{\"coordinates\":{\"x\":0.5,\"y\":2}}}"))
+	f.Add(uint8(websocket.BinaryMessage), []byte("\x00{\"code\":\"1\",\"name\":\"a\",\"avatar\":12,\"color\":-1}"))
+	f.Add(uint8(websocket.TextMessage), []byte("\x00{}"))
+	f.Add(uint8(websocket.BinaryMessage), []byte{0xff})
+
+	c := Net(NetOptions{}, config.Config{})
+	f.Fuzz(func(t *testing.T, mt uint8, msg []byte) {
+		packet, err := c.decodePacket(int(mt), msg)
+		if err != nil {
+			return
+		}
+
+		switch p := packet.(type) {
+		case *ConnectPacket:
+			if p.Name == "" || len(p.Name) > maxNameLength || len(p.Code) > maxCodeLength ||
+				p.Avatar < 0 || p.Avatar >= avatarCount || p.Color < 0 || p.Color >= colorCount {
+				t.Fatalf("accepted invalid connect packet: %+v", p)
+			}
+		case *HostGamePacket:
+			if len(p.Code) > maxCodeLength || (p.Code == "" && len(p.QuizId) != maxQuizIdLength) {
+				t.Fatalf("accepted invalid host packet: %+v", p)
+			}
+		case *QuestionAnswerPacket:
+			if p.Type == IndexAnswer && (p.Question < 0 || p.Question > maxChoiceIndex || *p.Payload.Index != p.Question) {
+				t.Fatalf("accepted out of range answer: %+v", p)
+			}
+		}
+	})
+}
+
+// Fuzz operations interpreted by FuzzGameStateMachine
+const (
+	fuzzOpJoin = iota
+	fuzzOpAnswer
+	fuzzOpTick
+	fuzzOpSkip
+	fuzzOpDisconnect
+	fuzzOpRaw
+	fuzzOpCount
+)
+
+func FuzzGameStateMachine(f *testing.F) {
+	f.Add([]byte{fuzzOpJoin, fuzzOpJoin, fuzzOpSkip, fuzzOpAnswer, 0, 0, 0, 0, fuzzOpAnswer, 1, 1, 0, 0})
+	f.Add([]byte{fuzzOpAnswer, 0, 0xff, 0xff, 0xff, 0xff, fuzzOpTick, fuzzOpTick, fuzzOpTick})
+	f.Add([]byte{fuzzOpJoin, fuzzOpSkip, fuzzOpSkip, fuzzOpSkip, fuzzOpSkip, fuzzOpAnswer, 0, 1, 0, 0, 0})
+	f.Add([]byte{fuzzOpJoin, fuzzOpSkip, fuzzOpDisconnect, 0, fuzzOpTick, fuzzOpTick, fuzzOpTick, fuzzOpTick})
+	f.Add([]byte{fuzzOpJoin, fuzzOpRaw, 0, 7, '{', '}', fuzzOpRaw, 0, 0, 'x'})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		c := Net(NetOptions{}, config.Config{})
+		host := &fakeConnection{}
+		game := newGame(fuzzQuiz(), host, c)
+		c.addGame(game)
+
+		connections := []*fakeConnection{}
+		next := func() (byte, bool) {
+			if len(ops) == 0 {
+				return 0, false
+			}
+
+			b := ops[0]
+			ops = ops[1:]
+			return b, true
+		}
+		pick := func() *fakeConnection {
+			b, ok := next()
+			if !ok || len(connections) == 0 {
+				return nil
+			}
+
+			return connections[int(b)%len(connections)]
+		}
+
+		for len(ops) > 0 {
+			op, _ := next()
+			switch op % fuzzOpCount {
+			case fuzzOpJoin:
+				con := &fakeConnection{}
+				connections = append(connections, con)
+				c.OnIncomingMessage(context.Background(), con, websocket.BinaryMessage, encodePacket(0, ConnectPacket{Code: game.Code, Name: "player"}))
+			case fuzzOpAnswer:
+				con := pick()
+				if con == nil || len(ops) < 4 {
+					continue
+				}
+
+				choice := int32(binary.BigEndian.Uint32(ops[:4]))
+				ops = ops[4:]
+				c.OnIncomingMessage(context.Background(), con, websocket.BinaryMessage, encodePacket(7, QuestionAnswerPacket{Question: int(choice)}))
+			case fuzzOpTick:
+				game.Tick()
+			case fuzzOpSkip:
+				// Drive the state machine directly rather than through StartGamePacket,
+				// which would spawn the real-time ticker goroutine
+				if game.State == LobbyState {
+					game.ChangeState(PlayState)
+				}
+				game.NextQuestion()
+			case fuzzOpDisconnect:
+				if con := pick(); con != nil {
+					c.OnDisconnect(con)
+				}
+			case fuzzOpRaw:
+				con := pick()
+				size, ok := next()
+				if con == nil || !ok {
+					continue
+				}
+
+				n := min(int(size)%32, len(ops))
+				msg := ops[:n]
+				ops = ops[n:]
+
+				// Host packets need a database-backed quiz service
+				if len(msg) > 0 && msg[0] == 1 {
+					continue
+				}
+				c.OnIncomingMessage(context.Background(), con, websocket.BinaryMessage, msg)
+			}
+
+			if game.State == EndState && !game.Ended {
+				t.Fatalf("game in end state without being ended")
+			}
+			for _, player := range game.Players {
+				if player.Points < 0 {
+					t.Fatalf("player has negative points: %d", player.Points)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/entity"
+)
+
+// pacedProgress is where a player is in a player-paced game, which every player works through on their own
+type pacedProgress struct {
+	order     []int     // Indexes of the quiz questions in the order the player gets them
+	position  int       // Position of the current question in the order, len(order) once the player is done
+	choices   []int     // Index in the quiz of each choice of the current question, in the order the player sees them
+	startedAt time.Time // When the player got the current question
+	deadline  time.Time // When the time to answer the current question runs out, zero without a time limit
+}
+
+// done reports whether the player went through every question
+func (p *pacedProgress) done() bool {
+	return p.position >= len(p.order)
+}
+
+// PacedProgressPacket tells the host of a player-paced game how far a player got
+type PacedProgressPacket struct {
+	PlayerId uuid.UUID `json:"playerId"` // ID of the player
+	Done     int       `json:"done"`     // Number of questions the player answered or ran out of time on
+	Total    int       `json:"total"`    // Number of questions in the quiz
+}
+
+// startPaced hands every player their first question of a player-paced game
+func (g *Game) startPaced() {
+	for _, player := range g.Players {
+		g.startPlayerPaced(player)
+	}
+}
+
+// startPlayerPaced shuffles the questions for a player and sends them the first one
+// Parameters:
+// - player: the player starting the quiz
+func (g *Game) startPlayerPaced(player *Player) {
+	player.paced = &pacedProgress{order: g.rand.Perm(len(g.Quiz.Questions))}
+	g.sendPacedQuestion(player)
+}
+
+// sendPacedQuestion shuffles the choices of a player's current question and sends it, or tells
+// the player to wait for the others once they are done
+// Parameters:
+// - player: the player
+func (g *Game) sendPacedQuestion(player *Player) {
+	progress := player.paced
+	g.sendToHost(PacedProgressPacket{
+		PlayerId: player.Id,
+		Done:     progress.position,
+		Total:    len(progress.order),
+	})
+
+	if progress.done() {
+		g.netService.SendPacket(player.Connection, ChangeGameStatePacket{State: IntermissionState})
+		return
+	}
+
+	question := g.Quiz.Questions[progress.order[progress.position]]
+	progress.choices = g.rand.Perm(len(question.Choices))
+	progress.startedAt = g.clock.Now()
+	progress.deadline = time.Time{}
+	if question.Time > 0 {
+		progress.deadline = progress.startedAt.Add(time.Duration(question.Time) * time.Second)
+	}
+
+	g.netService.SendPacket(player.Connection, g.newPacedQuestionPacket(player))
+}
+
+// newPacedQuestionPacket describes a player's current question of a player-paced game. Without a shared
+// screen showing the question, the player is sent its text and the choices in their own order.
+// Parameters:
+// - player: the player
+// Returns:
+// - PlayerQuestionPacket: the packet
+func (g *Game) newPacedQuestionPacket(player *Player) PlayerQuestionPacket {
+	progress := player.paced
+	question := g.Quiz.Questions[progress.order[progress.position]]
+
+	choices := []string{}
+	for _, index := range progress.choices {
+		choices = append(choices, question.Choices[index].Name)
+	}
+
+	endsAt := int64(0)
+	if !progress.deadline.IsZero() {
+		endsAt = progress.deadline.UnixMilli()
+	}
+
+	return PlayerQuestionPacket{
+		Choices:     len(question.Choices),
+		Time:        question.Time,
+		ServerTime:  progress.startedAt.UnixMilli(),
+		EndsAt:      endsAt,
+		ClockOffset: player.ClockOffset,
+		Media:       sizeMedia(question.Media, entity.MediumSize),
+		Index:       progress.position,
+		Total:       len(progress.order),
+		Slots:       getChoiceSlots(len(question.Choices)),
+		Name:        question.Name,
+		ChoiceNames: choices,
+		Hint:        question.Hint != "",
+	}
+}
+
+// onPacedAnswer scores a player's answer to their current question of a player-paced game at once and
+// moves them on. The first answer is final and every answer gets the reward of a first answer in a live
+// game, so it does not matter who else answered before.
+// Parameters:
+// - choice: the index of the chosen answer, in the order the player sees the choices
+// - confidence: how sure the player is, weighing the points in confidence mode
+// - player: the player who answered
+func (g *Game) onPacedAnswer(choice int, confidence Confidence, player *Player) {
+	progress := player.paced
+	if g.State != PlayState || progress == nil || progress.done() || choice < 0 || choice >= len(progress.choices) {
+		g.sendPacedAnswerAck(player, choice, false)
+		return
+	}
+
+	index := progress.order[progress.position]
+	question := g.Quiz.Questions[index]
+	elapsed := max(0, g.clock.Now().Sub(progress.startedAt)-player.latency())
+	if question.Time > 0 {
+		elapsed = min(elapsed, time.Duration(question.Time)*time.Second)
+	}
+
+	original := progress.choices[choice]
+	correct := isCorrectChoiceOf(question, original)
+	scoring := g.answerScoring(index, confidence)
+	points := scoring.Points(correct, bestReward(question)-int(elapsed.Seconds())*(1000/60))
+
+	g.applyToPlayer(player, AnswerRecordedEvent{PlayerId: player.Id, Answer: PlayerAnswer{
+		Question:   index,
+		Choice:     original,
+		Correct:    correct,
+		Elapsed:    elapsed,
+		Points:     points,
+		Confidence: confidence,
+	}})
+	g.streamAnswer(player, *player.Answers[index])
+	player.LastAwardedPoints = scoring.Apply(player.Points, points) - player.Points
+	g.applyToPlayer(player, PointsAwardedEvent{PlayerId: player.Id, Points: player.LastAwardedPoints})
+	g.updatePacedStreak(player, correct)
+
+	g.sendPacedAnswerAck(player, choice, true)
+	g.advancePaced(player)
+}
+
+// updatePacedStreak counts the consecutive correct answers of a player of a player-paced game
+// Parameters:
+// - player: the player
+// - correct: whether the last answer was correct
+func (g *Game) updatePacedStreak(player *Player, correct bool) {
+	if correct {
+		player.Streak++
+		player.LongestStreak = max(player.LongestStreak, player.Streak)
+	} else {
+		player.Streak = 0
+	}
+}
+
+// sendPacedAnswerAck confirms an answer to a player of a player-paced game, numbered by its position in their order
+// Parameters:
+// - player: the player who answered
+// - choice: the index of the choice they sent
+// - received: whether the answer was taken
+func (g *Game) sendPacedAnswerAck(player *Player, choice int, received bool) {
+	question := -1
+	if player.paced != nil {
+		question = player.paced.position
+	}
+
+	g.netService.SendPacket(player.Connection, AnswerAckPacket{
+		Question:   question,
+		Choice:     choice,
+		Received:   received,
+		Locked:     true,
+		ServerTime: g.clock.Now().UnixMilli(),
+	})
+}
+
+// advancePaced moves a player on to their next question, ending the game once every player is done
+// Parameters:
+// - player: the player
+func (g *Game) advancePaced(player *Player) {
+	player.Answered = false
+	player.paced.position++
+	g.sendPacedQuestion(player)
+
+	if g.allPacedDone() {
+		g.End()
+	}
+}
+
+// pacedSnapshot fills in a snapshot of a player-paced game with where the player is
+// Parameters:
+// - player: the player who asked
+// - snapshot: the snapshot to complete
+func (g *Game) pacedSnapshot(player *Player, snapshot *StateSnapshotPacket) {
+	progress := player.paced
+	snapshot.Question = progress.position
+	snapshot.Choice = -1
+	if progress.done() {
+		snapshot.State = IntermissionState
+		return
+	}
+
+	question := g.newPacedQuestionPacket(player)
+	snapshot.PlayerQuestion = &question
+	snapshot.EndsAt = question.EndsAt
+}
+
+// tickPaced moves on the players of a player-paced game whose time to answer ran out
+func (g *Game) tickPaced() {
+	now := g.clock.Now()
+	for _, player := range append([]*Player{}, g.Players...) {
+		progress := player.paced
+		if progress == nil || progress.done() || progress.deadline.IsZero() || now.Before(progress.deadline) {
+			continue
+		}
+
+		player.Streak = 0
+		g.advancePaced(player)
+		if g.Ended {
+			return
+		}
+	}
+}
+
+// allPacedDone reports whether every player of a player-paced game went through every question
+func (g *Game) allPacedDone() bool {
+	if g.Ended || len(g.Players) == 0 {
+		return false
+	}
+
+	for _, player := range g.Players {
+		if player.paced == nil || !player.paced.done() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// lastPacedQuestion decodes the last question sent to a player of a player-paced game
+func lastPacedQuestion(t *testing.T, connection *fakeConnection) PlayerQuestionPacket {
+	t.Helper()
+
+	var question PlayerQuestionPacket
+	for _, message := range connection.messages {
+		if message[0] != playerQuestionPacketId {
+			continue
+		}
+		question = PlayerQuestionPacket{}
+		if err := json.Unmarshal(packetData(message), &question); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return question
+}
+
+// startPacedGame starts a player-paced game of the fuzz quiz with two players
+func startPacedGame(t *testing.T) (*Game, *fakeClock, []*fakeConnection) {
+	c := Net(NetOptions{}, config.Config{})
+	clock := newFakeClock()
+	c.clock = clock
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnSettings(GameSettings{PlayerPaced: true})
+
+	connections := []*fakeConnection{{}, {}}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", connections[0])
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", connections[1])
+
+	// Started by hand, without the timer goroutine of Start
+	game.stopLobby()
+	game.ChangeState(PlayState)
+	game.startPaced()
+
+	return game, clock, connections
+}
+
+func TestPacedAnswers(t *testing.T) {
+	game, clock, connections := startPacedGame(t)
+	alice, bob := game.Players[0], game.Players[1]
+
+	order := slices.Clone(alice.paced.order)
+	if sorted := slices.Sorted(slices.Values(order)); !slices.Equal(sorted, []int{0, 1, 2}) {
+		t.Fatalf("expected the questions in some order, got %v", order)
+	}
+
+	// Alice picks the correct choice of every question, wherever it was shuffled to
+	for range order {
+		packet := lastPacedQuestion(t, connections[0])
+		question := game.Quiz.Questions[alice.paced.order[packet.Index]]
+		if packet.Name != question.Name || len(packet.ChoiceNames) != len(question.Choices) {
+			t.Fatalf("expected question %q with its choices, got %+v", question.Name, packet)
+		}
+
+		correct := slices.IndexFunc(packet.ChoiceNames, func(name string) bool {
+			return slices.ContainsFunc(question.Choices, func(choice entity.QuizChoice) bool {
+				return choice.Correct && choice.Name == name
+			})
+		})
+		if correct < 0 {
+			// A question without choices can only run out of time
+			clock.Advance(time.Duration(question.Time) * time.Second)
+			game.tickPaced()
+			continue
+		}
+
+		game.OnPlayerAnswer(correct, alice)
+	}
+
+	if !alice.paced.done() || bob.paced.done() || game.Ended {
+		t.Fatalf("expected alice to be done and the game to wait for bob")
+	}
+	for _, index := range []int{0, 1} {
+		if answer, ok := alice.Answers[index]; !ok || !answer.Correct {
+			t.Errorf("expected alice's answer to question %d to be stored as correct, got %+v", index, answer)
+		}
+	}
+	if alice.Points <= 0 || len(alice.Answers) != 2 {
+		t.Errorf("expected alice to score two answers, got %d points for %d answers", alice.Points, len(alice.Answers))
+	}
+
+	// Bob always picks the first choice
+	for !bob.paced.done() {
+		if packet := lastPacedQuestion(t, connections[1]); len(packet.ChoiceNames) > 0 {
+			game.OnPlayerAnswer(0, bob)
+		} else {
+			clock.Advance(time.Duration(packet.Time) * time.Second)
+			game.tickPaced()
+		}
+	}
+	if !game.Ended {
+		t.Errorf("expected the game to end once every player is done")
+	}
+}
+
+func TestPacedDeadline(t *testing.T) {
+	game, clock, _ := startPacedGame(t)
+
+	// Nobody answers, the longest question of the fuzz quiz lasts 3 seconds
+	for range len(game.Quiz.Questions) {
+		clock.Advance(3 * time.Second)
+		game.tickPaced()
+	}
+
+	if !game.Ended {
+		t.Fatalf("expected the game to end once everyone ran out of time")
+	}
+	for _, player := range game.Players {
+		if !player.paced.done() || len(player.Answers) != 0 {
+			t.Errorf("expected %s to be done without answers", player.Name)
+		}
+	}
+}
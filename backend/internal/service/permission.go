@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Limits of sharing a quiz
+const (
+	maxQuizShares       = 50                  // Most users a quiz can be shared with
+	maxQuizShareLinks   = 10                  // Most links a quiz can have at once
+	maxShareLinkTtl     = 30 * 24 * time.Hour // Longest a share link can work
+	defaultShareLinkTtl = 7 * 24 * time.Hour  // How long a share link works if no expiry is asked for
+)
+
+// Errors returned when a quiz cannot be accessed or shared.
+var (
+	ErrQuizForbidden = errors.New("not allowed on quiz")
+	ErrInvalidShare  = errors.New("invalid share")
+)
+
+// QuizSharing is who the owner of a quiz shared it with
+type QuizSharing struct {
+	Shares []entity.QuizShare     `json:"shares"` // Users granted rights on the quiz
+	Links  []entity.QuizShareLink `json:"links"`  // Links granting rights, expired ones left out
+}
+
+// permissionRank orders the rights on a quiz
+// Parameters:
+// - permission: the right, see entity.ViewPermission
+// Returns:
+// - int: higher for more rights, 0 for none or an unknown right
+func permissionRank(permission string) int {
+	switch permission {
+	case entity.ViewPermission:
+		return 1
+	case entity.HostPermission:
+		return 2
+	case entity.EditPermission:
+		return 3
+	}
+
+	return 0
+}
+
+// CanAccessQuiz reports whether a user or the holder of a share link has a right on a quiz.
+// Quizzes without an owner belong to the open library and everyone has every right on them;
+// templates can be seen by every signed in user.
+// Parameters:
+// - quiz: the quiz.
+// - userId: the ObjectID of the user, zero for anonymous requests.
+// - linkToken: the token of a share link, empty if none.
+// - permission: the right needed, see entity.ViewPermission.
+// Returns:
+// - true if the user or the link grants the right or one that includes it.
+func CanAccessQuiz(quiz entity.Quiz, userId primitive.ObjectID, linkToken string, permission string) bool {
+	return permissionRank(quizPermission(quiz, userId, linkToken, time.Now())) >= permissionRank(permission)
+}
+
+// quizPermission finds the highest right a user or the holder of a share link has on a quiz
+// Parameters:
+// - quiz: the quiz
+// - userId: the ObjectID of the user, zero for anonymous requests
+// - linkToken: the token of a share link, empty if none
+// - now: the current time, to expire links
+// Returns:
+// - string: the right, empty for none
+func quizPermission(quiz entity.Quiz, userId primitive.ObjectID, linkToken string, now time.Time) string {
+	if quiz.OwnerId.IsZero() || (!userId.IsZero() && quiz.OwnerId == userId) {
+		return entity.EditPermission
+	}
+
+	best := ""
+	grant := func(permission string) {
+		if permissionRank(permission) > permissionRank(best) {
+			best = permission
+		}
+	}
+
+	if quiz.Template && !userId.IsZero() {
+		grant(entity.ViewPermission)
+	}
+
+	if !userId.IsZero() {
+		for _, share := range quiz.Shares {
+			if share.UserId == userId {
+				grant(share.Permission)
+			}
+		}
+	}
+
+	if linkToken != "" {
+		for _, link := range quiz.ShareLinks {
+			if now.Before(link.ExpiresAt) && subtle.ConstantTimeCompare([]byte(link.Token), []byte(linkToken)) == 1 {
+				grant(link.Permission)
+			}
+		}
+	}
+
+	return best
+}
+
+// GetSharing lists who the owner of a quiz shared it with.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the quiz.
+// - ownerId: the ObjectID of the user asking, who must own the quiz.
+// Returns:
+// - The sharing, and ErrQuizNotFound or ErrQuizForbidden if the quiz does not exist or is not the user's.
+func (s QuizService) GetSharing(ctx context.Context, id primitive.ObjectID, ownerId primitive.ObjectID) (QuizSharing, error) {
+	quiz, err := s.getOwnedQuiz(ctx, id, ownerId)
+	if err != nil {
+		return QuizSharing{}, err
+	}
+
+	return QuizSharing{
+		Shares: slices.Clone(quiz.Shares),
+		Links:  activeShareLinks(quiz.ShareLinks, time.Now()),
+	}, nil
+}
+
+// ShareQuiz grants a user a right on a quiz, replacing the right they had.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the quiz.
+// - ownerId: the ObjectID of the user sharing, who must own the quiz.
+// - userId: the ObjectID of the user to share with.
+// - permission: the right to grant, or an empty string to take all rights away.
+// Returns:
+// - ErrInvalidShare if the right is unknown, the user is the owner or the quiz is shared with too many users,
+// ErrQuizNotFound or ErrQuizForbidden if the quiz does not exist or is not the owner's, or an error if the update fails.
+func (s QuizService) ShareQuiz(ctx context.Context, id primitive.ObjectID, ownerId primitive.ObjectID, userId primitive.ObjectID, permission string) error {
+	if userId.IsZero() || userId == ownerId || (permission != "" && permissionRank(permission) == 0) {
+		return ErrInvalidShare
+	}
+
+	quiz, err := s.getOwnedQuiz(ctx, id, ownerId)
+	if err != nil {
+		return err
+	}
+
+	shares := slices.DeleteFunc(slices.Clone(quiz.Shares), func(share entity.QuizShare) bool {
+		return share.UserId == userId
+	})
+	if permission != "" {
+		shares = append(shares, entity.QuizShare{UserId: userId, Permission: permission})
+	}
+
+	if len(shares) > maxQuizShares {
+		return ErrInvalidShare
+	}
+
+	return s.updateSharing(ctx, id, shares, quiz.ShareLinks)
+}
+
+// CreateShareLink creates a link granting a right on a quiz to whoever has it.
+// Expired links are cleaned up on the way.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the quiz.
+// - ownerId: the ObjectID of the user sharing, who must own the quiz.
+// - permission: the right the link grants.
+// - ttl: how long the link works, zero for a week; at most 30 days.
+// Returns:
+// - The link, and ErrInvalidShare if the right or time is out of range or the quiz has too many links,
+// ErrQuizNotFound or ErrQuizForbidden if the quiz does not exist or is not the owner's, or an error if the update fails.
+func (s QuizService) CreateShareLink(ctx context.Context, id primitive.ObjectID, ownerId primitive.ObjectID, permission string, ttl time.Duration) (*entity.QuizShareLink, error) {
+	if permissionRank(permission) == 0 || ttl < 0 || ttl > maxShareLinkTtl {
+		return nil, ErrInvalidShare
+	}
+
+	if ttl == 0 {
+		ttl = defaultShareLinkTtl
+	}
+
+	quiz, err := s.getOwnedQuiz(ctx, id, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	links := activeShareLinks(quiz.ShareLinks, now)
+	if len(links) >= maxQuizShareLinks {
+		return nil, ErrInvalidShare
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link := entity.QuizShareLink{Token: token, Permission: permission, ExpiresAt: now.Add(ttl)}
+	if err := s.updateSharing(ctx, id, quiz.Shares, append(links, link)); err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// RevokeShareLink stops a share link of a quiz from working.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the quiz.
+// - ownerId: the ObjectID of the user revoking, who must own the quiz.
+// - token: the token of the link.
+// Returns:
+// - ErrQuizNotFound or ErrQuizForbidden if the quiz does not exist or is not the owner's, or an error if the update fails.
+func (s QuizService) RevokeShareLink(ctx context.Context, id primitive.ObjectID, ownerId primitive.ObjectID, token string) error {
+	quiz, err := s.getOwnedQuiz(ctx, id, ownerId)
+	if err != nil {
+		return err
+	}
+
+	links := slices.DeleteFunc(activeShareLinks(quiz.ShareLinks, time.Now()), func(link entity.QuizShareLink) bool {
+		return link.Token == token
+	})
+
+	return s.updateSharing(ctx, id, quiz.Shares, links)
+}
+
+// getOwnedQuiz reads a quiz only its owner may share
+// Parameters:
+// - ctx: the context bounding the database operations
+// - id: the ObjectID of the quiz
+// - ownerId: the ObjectID of the user asking
+// Returns:
+// - *entity.Quiz: the quiz
+// - error: ErrQuizNotFound if it does not exist, ErrQuizForbidden if it has no owner or another one
+func (s QuizService) getOwnedQuiz(ctx context.Context, id primitive.ObjectID, ownerId primitive.ObjectID) (*entity.Quiz, error) {
+	quiz, err := s.quizCollection.GetQuizById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if quiz == nil {
+		return nil, ErrQuizNotFound
+	}
+
+	// Quizzes of the open library cannot be shared, everyone has every right on them already
+	if quiz.OwnerId.IsZero() || quiz.OwnerId != ownerId {
+		return nil, ErrQuizForbidden
+	}
+
+	return quiz, nil
+}
+
+// updateSharing stores who a quiz is shared with and drops the quiz from the caches
+// Parameters:
+// - ctx: the context bounding the database operations
+// - id: the ObjectID of the quiz
+// - shares: the users granted rights
+// - links: the share links
+// Returns:
+// - error: an error if the update fails
+func (s QuizService) updateSharing(ctx context.Context, id primitive.ObjectID, shares []entity.QuizShare, links []entity.QuizShareLink) error {
+	if err := s.quizCollection.UpdateQuizSharing(ctx, id, shares, links); err != nil {
+		return err
+	}
+
+	s.invalidateQuiz(id)
+	return nil
+}
+
+// activeShareLinks leaves out the share links that stopped working
+// Parameters:
+// - links: the links of a quiz
+// - now: the current time
+// Returns:
+// - []entity.QuizShareLink: a copy of the links that still work
+func activeShareLinks(links []entity.QuizShareLink, now time.Time) []entity.QuizShareLink {
+	active := []entity.QuizShareLink{}
+	for _, link := range links {
+		if now.Before(link.ExpiresAt) {
+			active = append(active, link)
+		}
+	}
+
+	return active
+}
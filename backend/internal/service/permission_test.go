@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestQuizPermission(t *testing.T) {
+	owner, viewer, stranger := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	quiz := entity.Quiz{
+		OwnerId: owner,
+		Shares:  []entity.QuizShare{{UserId: viewer, Permission: entity.ViewPermission}},
+		ShareLinks: []entity.QuizShareLink{
+			{Token: "host-link", Permission: entity.HostPermission, ExpiresAt: now.Add(time.Hour)},
+			{Token: "old-link", Permission: entity.EditPermission, ExpiresAt: now.Add(-time.Hour)},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		userId primitive.ObjectID
+		token  string
+		want   string
+	}{
+		{"owner", owner, "", entity.EditPermission},
+		{"shared user", viewer, "", entity.ViewPermission},
+		{"stranger", stranger, "", ""},
+		{"anonymous", primitive.NilObjectID, "", ""},
+		{"link", primitive.NilObjectID, "host-link", entity.HostPermission},
+		{"link beats share", viewer, "host-link", entity.HostPermission},
+		{"expired link", stranger, "old-link", ""},
+		{"unknown link", stranger, "guess", ""},
+	}
+	for _, test := range tests {
+		if got := quizPermission(quiz, test.userId, test.token, now); got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.name, test.want, got)
+		}
+	}
+
+	if got := quizPermission(entity.Quiz{}, primitive.NilObjectID, "", now); got != entity.EditPermission {
+		t.Errorf("expected everyone to edit quizzes of the open library, got %q", got)
+	}
+	if permissionRank(entity.HostPermission) < permissionRank(entity.ViewPermission) || permissionRank("admin") != 0 {
+		t.Errorf("expected hosting to include viewing and unknown rights to grant nothing")
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// Actions of a media cue; seeking is a cue to play or pause at the new position
+const (
+	PlayMediaCue  = "play"  // Play from the position
+	PauseMediaCue = "pause" // Stop playing at the position
+)
+
+// Limits of media cues
+const (
+	mediaCueLead     = 500 * time.Millisecond // How far ahead cues are scheduled, so every device starts at the same moment
+	maxMediaPosition = 6 * 60 * 60            // Latest playback position accepted in seconds
+)
+
+// MediaCuePacket synchronizes the playback of the audio or video of the current question.
+// The host sends the action, the media and its position; the server relays it to every player,
+// scheduled a moment ahead at At and with the position the media will have reached by then.
+type MediaCuePacket struct {
+	Action      string  `json:"action"`      // What to do, see PlayMediaCue
+	Media       int     `json:"media"`       // Index of the media in the question
+	Position    float64 `json:"position"`    // Playback position in seconds at At
+	Question    int     `json:"question"`    // Index of the question the cue is for, set by the server
+	At          int64   `json:"at"`          // Server time in milliseconds when the cue takes effect, set by the server
+	ClockOffset int64   `json:"clockOffset"` // Measured offset of the player's clock from the server clock in milliseconds, set by the server
+}
+
+// validate checks the action and keeps the media index and position within range.
+func (p *MediaCuePacket) validate() error {
+	if p.Action != PlayMediaCue && p.Action != PauseMediaCue {
+		return ErrInvalidPacket
+	}
+
+	if p.Media < 0 || p.Media > maxChoiceIndex {
+		return ErrInvalidPacket
+	}
+
+	if math.IsNaN(p.Position) || p.Position < 0 || p.Position > maxMediaPosition {
+		return ErrInvalidPacket
+	}
+
+	return nil
+}
+
+// OnMediaCue relays a playback cue of the host to the players, while the question is shown or revealed
+// Parameters:
+// - packet: the cue of the host
+func (g *Game) OnMediaCue(packet *MediaCuePacket) {
+	if g.State != PlayState && g.State != RevealState {
+		return
+	}
+
+	media := g.getCurrentQuestion().Media
+	if packet.Media >= len(media) || (media[packet.Media].Type != entity.AudioMedia && media[packet.Media].Type != entity.VideoMedia) {
+		return
+	}
+
+	at := g.clock.Now().Add(mediaCueLead)
+	cue := MediaCuePacket{
+		Action:   packet.Action,
+		Media:    packet.Media,
+		Position: packet.Position,
+		Question: g.CurrentQuestion,
+		At:       at.UnixMilli(),
+	}
+
+	// The host's media plays on while the cue is on its way
+	if cue.Action == PlayMediaCue {
+		cue.Position += mediaCueLead.Seconds()
+	}
+
+	g.mediaCue = &cue
+	for _, player := range g.Players {
+		g.sendMediaCue(player)
+	}
+}
+
+// sendMediaCue brings the media of a player in line with the last cue of the current question, if there is one.
+// Players who join or resync after the cue took effect get it for the current moment.
+// Parameters:
+// - player: the player
+func (g *Game) sendMediaCue(player *Player) {
+	if g.mediaCue == nil || g.mediaCue.Question != g.CurrentQuestion {
+		return
+	}
+
+	cue := *g.mediaCue
+	now := g.clock.Now().UnixMilli()
+	if now > cue.At {
+		if cue.Action == PlayMediaCue {
+			cue.Position += float64(now-cue.At) / 1000
+		}
+		cue.At = now
+	}
+	cue.ClockOffset = player.ClockOffset
+
+	g.netService.SendPacket(player.Connection, cue)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// mediaCuePacketId is the ID of MediaCuePacket on the wire
+const mediaCuePacketId = 43
+
+// lastMediaCue decodes the last media cue sent on a connection
+func lastMediaCue(t *testing.T, connection *fakeConnection) *MediaCuePacket {
+	t.Helper()
+
+	var cue *MediaCuePacket
+	for _, message := range connection.messages {
+		if message[0] != mediaCuePacketId {
+			continue
+		}
+		cue = &MediaCuePacket{}
+		if err := json.Unmarshal(packetData(message), cue); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return cue
+}
+
+func TestMediaCues(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	clock := newFakeClock()
+	c.clock = clock
+	quiz := fuzzQuiz()
+	quiz.Questions[0].Media = []entity.QuestionMedia{{Type: entity.ImageMedia}, {Type: entity.AudioMedia}}
+	game := newGame(quiz, &fakeConnection{}, c)
+
+	early := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", early)
+	game.Players[0].ClockOffset = 250
+
+	// Started by hand, without the timer goroutine of Start
+	game.stopLobby()
+	game.ChangeState(PlayState)
+	game.NextQuestion()
+
+	game.OnMediaCue(&MediaCuePacket{Action: PlayMediaCue, Media: 0, Position: 3})
+	if lastMediaCue(t, early) != nil {
+		t.Fatal("expected cues for images to be ignored")
+	}
+
+	game.OnMediaCue(&MediaCuePacket{Action: PlayMediaCue, Media: 1, Position: 3})
+	cue := lastMediaCue(t, early)
+	if cue == nil || cue.At != clock.Now().Add(mediaCueLead).UnixMilli() || cue.Position != 3.5 || cue.ClockOffset != 250 {
+		t.Fatalf("expected the cue scheduled ahead with the position reached by then, got %+v", cue)
+	}
+
+	// Players joining later catch up with the media already playing
+	clock.Advance(2500 * time.Millisecond)
+	late := &fakeConnection{}
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", late)
+	if cue := lastMediaCue(t, late); cue == nil || cue.Position != 5.5 || cue.At != clock.Now().UnixMilli() {
+		t.Errorf("expected the late player to start where the media is now, got %+v", cue)
+	}
+
+	game.NextQuestion()
+	game.OnPlayerResync(game.Players[1])
+	if cue := lastMediaCue(t, late); cue.Question != 0 {
+		t.Errorf("expected no cue of the previous question after moving on, got %+v", cue)
+	}
+}
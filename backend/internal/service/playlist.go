@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Limits of quiz playlists
+const (
+	maxPlaylistLength = 10 // Most quizzes a single game can play one after another
+	playlistBreakTime = 15 // Seconds between the last question of a quiz and the first of the next
+)
+
+// PlaylistPacket tells everyone which quiz of a playlist is being played.
+// It is sent when a playlist game is hosted and whenever it moves on to the next quiz.
+type PlaylistPacket struct {
+	Index       int                `json:"index"`       // Index of the current quiz in the playlist
+	Total       int                `json:"total"`       // Number of quizzes in the playlist
+	Quiz        string             `json:"quiz"`        // Name of the current quiz
+	Questions   int                `json:"questions"`   // Number of questions in the current quiz
+	ResetPoints bool               `json:"resetPoints"` // Whether points start from zero with each quiz
+	Leaderboard []LeaderboardEntry `json:"leaderboard"` // Standings after the previous quiz, empty for the first
+}
+
+// loadPlaylist queues the quizzes a game plays after the one it was hosted with.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - game: the game to queue the quizzes on.
+// - quizIds: the hex encoded IDs of the quizzes, in playing order.
+// - resetPoints: whether points start from zero with each quiz rather than adding up.
+// Returns:
+// - An error if a quiz cannot be found or is not shared with the host for hosting.
+func (c *NetService) loadPlaylist(ctx context.Context, game *Game, quizIds []string, resetPoints bool) error {
+	game.Playlist = []entity.Quiz{game.Quiz}
+	game.playlistRecords = []int{game.record}
+	for _, hex := range quizIds {
+		quizId, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return err
+		}
+
+		quiz, err := c.quizService.GetQuizById(ctx, quizId)
+		if err != nil {
+			return err
+		}
+
+		if quiz == nil {
+			return fmt.Errorf("playlist quiz %s: %w", hex, ErrQuizNotFound)
+		}
+
+		if !CanAccessQuiz(*quiz, game.hostUserId, "", entity.HostPermission) {
+			return fmt.Errorf("playlist quiz %s: %w", hex, ErrQuizForbidden)
+		}
+
+		if c.mediaService != nil {
+			*quiz = c.mediaService.SignQuiz(ctx, *quiz)
+		}
+
+		game.Playlist = append(game.Playlist, withDefaultTime(*quiz))
+		game.playlistRecords = append(game.playlistRecords, c.getRecord(ctx, quiz.Id))
+	}
+
+	game.resetPoints = resetPoints
+	return nil
+}
+
+// hasNextQuiz reports whether the playlist has another quiz after the current one
+func (g *Game) hasNextQuiz() bool {
+	return g.PlaylistIndex+1 < len(g.Playlist)
+}
+
+// newPlaylistPacket describes the current quiz of the playlist
+// Parameters:
+// - leaderboard: the standings after the previous quiz
+// Returns:
+// - PlaylistPacket: the packet
+func (g *Game) newPlaylistPacket(leaderboard []LeaderboardEntry) PlaylistPacket {
+	return PlaylistPacket{
+		Index:       g.PlaylistIndex,
+		Total:       len(g.Playlist),
+		Quiz:        g.Quiz.Name,
+		Questions:   len(g.Quiz.Questions),
+		ResetPoints: g.resetPoints,
+		Leaderboard: leaderboard,
+	}
+}
+
+// NextQuiz finishes the current quiz of the playlist and moves on to the next one after a break.
+// Every quiz is stored as a result of its own, with the points earned in it.
+func (g *Game) NextQuiz() {
+	report := g.buildReport()
+	g.netService.saveResult(g, g.buildResult(report, g.getAwards()))
+
+	// Exams show no standings until the whole playlist is over
+	leaderboard := []LeaderboardEntry{}
+	if !g.Settings.ExamMode {
+		leaderboard = g.getLeaderboard()
+	}
+
+	g.PlaylistIndex++
+	g.Quiz = g.Playlist[g.PlaylistIndex]
+	g.record = g.playlistRecords[g.PlaylistIndex]
+	g.recordHolder = uuid.Nil
+	g.previousTop = nil
+	g.apply(QuizChangedEvent{})
+
+	// Answers, hints and streaks belong to a single quiz, points only if they are reset
+	if g.resetPoints {
+		g.apply(PointsResetEvent{})
+	}
+	g.apply(AnswersClearedEvent{})
+
+	for _, player := range g.Players {
+		player.quizStartPoints = player.Points
+		player.Hints = map[int]int{}
+		player.Streak = 0
+		player.LongestStreak = 0
+		player.LowestRank = 0
+	}
+
+	g.setTimer(playlistBreakTime)
+	g.ChangeState(IntermissionState)
+	g.BroadcastPacket(g.newPlaylistPacket(leaderboard), true)
+	g.BroadcastPacket(g.newGameInfoPacket(), false)
+	g.sendNextQuestionPreview()
+	g.broadcastPreload(0)
+}
+
+// quizPoints returns the points the player earned in the current quiz of the playlist
+func (p *Player) quizPoints() int {
+	return p.Points - p.quizStartPoints
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestPlaylistMovesToNextQuiz(t *testing.T) {
+	for _, resetPoints := range []bool{false, true} {
+		c := Net(NetOptions{}, config.Config{})
+		game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+		second := fuzzQuiz()
+		second.Name = "Second"
+		game.Playlist = []entity.Quiz{game.Quiz, second}
+		game.playlistRecords = []int{-1, -1}
+		game.resetPoints = resetPoints
+
+		game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+		player := game.Players[0]
+		game.Start()
+		game.OnPlayerAnswer(0, player)
+		game.Reveal()
+		earned := player.Points
+
+		for range game.Quiz.Questions {
+			game.NextQuestion()
+		}
+
+		if game.Ended || game.Quiz.Name != "Second" || game.State != IntermissionState || game.CurrentQuestion != -1 {
+			t.Fatalf("expected a break before the second quiz, got %q in state %d", game.Quiz.Name, game.State)
+		}
+		if len(player.Answers) != 0 || player.quizPoints() != 0 {
+			t.Errorf("expected the answers of the first quiz to be cleared, got %d", len(player.Answers))
+		}
+
+		want := earned
+		if earned == 0 {
+			t.Fatalf("expected points for the correct answer")
+		}
+		if resetPoints {
+			want = 0
+		}
+		if player.Points != want {
+			t.Errorf("resetPoints %v: expected %d points, got %d", resetPoints, want, player.Points)
+		}
+
+		for range game.Quiz.Questions {
+			game.NextQuestion()
+		}
+		game.NextQuestion()
+		if !game.Ended {
+			t.Errorf("expected the game to end after the last quiz")
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// urlHashLength is the number of hex characters of the URL hash used when the content hash of media is unknown
+const urlHashLength = 12
+
+// PreloadMedia is a single file clients should fetch ahead of a question
+type PreloadMedia struct {
+	Type string `json:"type"` // Kind of media, see entity.ImageMedia and entity.AudioMedia
+	Url  string `json:"url"`  // URL to fetch, versioned with the hash so a changed file is never served stale
+	Hash string `json:"hash"` // Hash identifying the version of the file
+}
+
+// preloadMedia lists the media of a question for clients to prefetch.
+// Media stored by the app carries a hash of its content; for other URLs the hash only identifies the URL.
+// Parameters:
+// - question: the upcoming question.
+// - size: the rendition the client shows, see entity.MediumSize and friends.
+// Returns:
+// - The media to prefetch, leaving out anything that is not an HTTP(S) or same-origin URL.
+func preloadMedia(question entity.QuizQuestion, size string) []PreloadMedia {
+	media := []PreloadMedia{}
+	for _, item := range sizeMedia(question.Media, size) {
+		parsed, err := url.Parse(item.Url)
+		if err != nil {
+			continue
+		}
+
+		// Only hand out URLs a browser can safely fetch
+		sameOrigin := parsed.Scheme == "" && parsed.Host == "" && len(parsed.Path) > 0 && parsed.Path[0] == '/'
+		if parsed.Scheme != "http" && parsed.Scheme != "https" && !sameOrigin {
+			continue
+		}
+
+		hash := item.Hash
+		if hash == "" {
+			sum := sha256.Sum256([]byte(item.Url))
+			hash = hex.EncodeToString(sum[:])[:urlHashLength]
+		}
+
+		query := parsed.Query()
+		query.Set("v", hash)
+		parsed.RawQuery = query.Encode()
+
+		media = append(media, PreloadMedia{
+			Type: item.Type,
+			Url:  parsed.String(),
+			Hash: hash,
+		})
+	}
+
+	return media
+}
+
+// sizeMedia picks the rendition of every uploaded image that suits a client, so phones do not download
+// images meant for the big screen.
+// Parameters:
+// - media: the media of a question.
+// - size: the rendition the client shows.
+// Returns:
+// - Copies of the media whose URL points to the rendition, or to the original if there is none.
+func sizeMedia(media []entity.QuestionMedia, size string) []entity.QuestionMedia {
+	sized := []entity.QuestionMedia{}
+	for _, item := range media {
+		if signed, ok := item.Sizes[size]; ok {
+			item.Url = signed
+		}
+
+		item.Sizes = nil
+		sized = append(sized, item)
+	}
+
+	return sized
+}
+
+// sendPreload tells a connection which media to fetch ahead of a question, if the question has any.
+// Parameters:
+// - connection: the connection to send the manifest to.
+// - index: the index of the upcoming question.
+// - size: the rendition the client shows.
+func (g *Game) sendPreload(connection Connection, index int, size string) {
+	packet, ok := g.newPreloadPacket(index, size)
+	if !ok {
+		return
+	}
+
+	g.netService.SendPacket(connection, packet)
+}
+
+// broadcastPreload tells every player and the host which media to fetch ahead of a question, if it has any.
+// Players fetch the renditions for phones, the host those for the big screen.
+// Parameters:
+// - index: the index of the upcoming question.
+func (g *Game) broadcastPreload(index int) {
+	if g.Host != nil {
+		g.sendPreload(g.Host, index, entity.FullSize)
+	}
+
+	packet, ok := g.newPreloadPacket(index, entity.MediumSize)
+	if !ok {
+		return
+	}
+
+	g.BroadcastPacket(packet, false)
+}
+
+// newPreloadPacket builds the preload manifest of a question.
+// Parameters:
+// - index: the index of the upcoming question.
+// - size: the rendition the client shows.
+// Returns:
+// - The manifest and false if there is no such question or it has nothing to preload.
+func (g *Game) newPreloadPacket(index int, size string) (PreloadPacket, bool) {
+	if index < 0 || index >= len(g.Quiz.Questions) {
+		return PreloadPacket{}, false
+	}
+
+	media := preloadMedia(g.Quiz.Questions[index], size)
+	if len(media) == 0 {
+		return PreloadPacket{}, false
+	}
+
+	return PreloadPacket{
+		Question: index,
+		Media:    media,
+	}, true
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+const preloadPacketId = 26
+
+func TestPreloadMedia(t *testing.T) {
+	media := preloadMedia(entity.QuizQuestion{
+		Media: []entity.QuestionMedia{
+			{Type: entity.ImageMedia, Url: "/api/media/flag.png", Hash: "abc123"},
+			{Type: entity.AudioMedia, Url: "https://cdn.example.com/anthem.mp3?lang=en"},
+			{Type: entity.ImageMedia, Url: "javascript:alert(1)"},
+			{Type: entity.ImageMedia, Url: "relative/path.png"},
+		},
+	}, entity.MediumSize)
+
+	if len(media) != 2 {
+		t.Fatalf("expected unsafe URLs to be left out, got %v", media)
+	}
+
+	if media[0].Url != "/api/media/flag.png?v=abc123" || media[0].Hash != "abc123" {
+		t.Errorf("expected the content hash to version the URL, got %+v", media[0])
+	}
+
+	if len(media[1].Hash) != urlHashLength || media[1].Url != "https://cdn.example.com/anthem.mp3?lang=en&v="+media[1].Hash {
+		t.Errorf("expected a URL hash to version the URL, got %+v", media[1])
+	}
+}
+
+func TestPreloadDuringIntermission(t *testing.T) {
+	host := &fakeConnection{}
+	player := &fakeConnection{}
+	quiz := fuzzQuiz()
+	quiz.Questions[1].Media = []entity.QuestionMedia{{Type: entity.ImageMedia, Url: "/api/media/flag.png"}}
+
+	game := newGame(quiz, host, Net(NetOptions{}, config.Config{}))
+	game.Players = []*Player{{Id: uuid.New(), Name: "alice", Connection: player}}
+	game.CurrentQuestion = 0
+
+	game.Intermission()
+
+	if !slices.Contains(host.packetIds(), preloadPacketId) || !slices.Contains(player.packetIds(), preloadPacketId) {
+		t.Errorf("expected everyone to receive the manifest, got %v and %v", host.packetIds(), player.packetIds())
+	}
+
+	// The question after has no media, so there is nothing to announce
+	game.CurrentQuestion = 1
+	before := len(player.packetIds())
+	game.Intermission()
+
+	if slices.Contains(player.packetIds()[before:], preloadPacketId) {
+		t.Errorf("expected no manifest for a question without media, got %v", player.packetIds()[before:])
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// LinkPreview is what chat apps show when a quiz or game link is shared, as Open Graph and Twitter card tags
+type LinkPreview struct {
+	Title       string // Title of the card
+	Description string // Line under the title
+	Image       string // URL of the image of the card, empty for a card without one
+	Url         string // Page of the frontend the link stands for, where people are sent on
+}
+
+// linkPreviewPage renders a LinkPreview as a page of meta tags that sends browsers on to the frontend
+var linkPreviewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.Url}}">
+{{if .Image}}<meta property="og:image" content="{{.Image}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{.Image}}">
+{{else}}<meta name="twitter:card" content="summary">
+{{end}}<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<meta http-equiv="refresh" content="0; url={{.Url}}">
+</head>
+<body>
+<p><a href="{{.Url}}">{{.Title}}</a></p>
+</body>
+</html>
+`))
+
+// RenderLinkPreview renders the page chat apps read the card of a link from.
+// Parameters:
+// - preview: the card.
+// Returns:
+// - The HTML page and an error if it cannot be rendered.
+func RenderLinkPreview(preview LinkPreview) (string, error) {
+	var page bytes.Buffer
+	if err := linkPreviewPage.Execute(&page, preview); err != nil {
+		return "", err
+	}
+
+	return page.String(), nil
+}
+
+// NewQuizPreview builds the card of a link to a public quiz.
+// Parameters:
+// - metadata: the public metadata of the quiz.
+// - publicUrl: the base URL of the frontend.
+// Returns:
+// - The card, sending people on to host the quiz.
+func NewQuizPreview(metadata QuizMetadata, publicUrl string) LinkPreview {
+	description := pluralize(metadata.Questions, "question")
+	if metadata.Author != "" {
+		description += " by " + metadata.Author
+	}
+
+	return LinkPreview{
+		Title:       metadata.Name,
+		Description: description,
+		Image:       metadata.Cover,
+		Url:         strings.TrimSuffix(publicUrl, "/") + "/#/host",
+	}
+}
+
+// GetGamePreview builds the card of a join link of a game, with the players in it so far.
+// Parameters:
+// - code: the join code of the game.
+// Returns:
+// - The card, sending people on to join the game, and ErrGameNotFound if there is no game with the code.
+func (c *NetService) GetGamePreview(code string) (LinkPreview, error) {
+	game := c.getGameByCode(code)
+	if game == nil {
+		return LinkPreview{}, ErrGameNotFound
+	}
+
+	var preview LinkPreview
+	game.run("preview", func() {
+		description := "Join with code " + game.Code + ", " + pluralize(len(game.Players), "player") + " in"
+		if game.State != LobbyState {
+			description = "Game in progress, " + pluralize(len(game.Players), "player") + " playing"
+		}
+
+		preview = LinkPreview{
+			Title:       "Play " + game.Quiz.Name,
+			Description: description,
+			Url:         strings.TrimSuffix(c.config.PublicUrl, "/") + "/?code=" + url.QueryEscape(game.Code),
+		}
+
+		// The media of a hosted quiz is already signed, for long enough for chat apps to fetch it
+		if cover := GetQuizCover(game.Quiz); cover != nil {
+			preview.Image = cover.Url
+		}
+	})
+
+	return preview, nil
+}
+
+// pluralize counts things in words
+// Parameters:
+// - count: the number of things
+// - noun: the name of one thing
+// Returns:
+// - string: e.g. "1 player" or "3 players"
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
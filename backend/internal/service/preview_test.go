@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestGamePreview(t *testing.T) {
+	net := Net(NetOptions{}, config.Config{PublicUrl: "https://quiz.example.com/"})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, net)
+	game.Quiz.Questions[2].Media = []entity.QuestionMedia{{Type: entity.ImageMedia, Url: "https://cdn.example.com/cover.png?sig=1&expires=2"}}
+	game.Players = []*Player{{Id: uuid.New(), Name: "alice", Connection: &fakeConnection{}}}
+	net.addGame(game)
+
+	if _, err := net.GetGamePreview("nope"); err != ErrGameNotFound {
+		t.Fatalf("expected unknown codes to be rejected, got %v", err)
+	}
+
+	preview, err := net.GetGamePreview(game.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Title != "Play Fuzz" || preview.Url != "https://quiz.example.com/?code="+game.Code || !strings.Contains(preview.Description, "1 player in") {
+		t.Errorf("expected the lobby to be described, got %+v", preview)
+	}
+
+	page, err := RenderLinkPreview(preview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{`property="og:title" content="Play Fuzz"`, `name="twitter:card" content="summary_large_image"`, `content="https://cdn.example.com/cover.png?sig=1&amp;expires=2"`} {
+		if !strings.Contains(page, tag) {
+			t.Errorf("expected %s in the page, got %s", tag, page)
+		}
+	}
+}
+
+func TestQuizPreviewEscapes(t *testing.T) {
+	preview := NewQuizPreview(QuizMetadata{Name: `<script>"x"</script>`, Questions: 1, Author: "bob"}, "https://quiz.example.com")
+	if preview.Description != "1 question by bob" || preview.Url != "https://quiz.example.com/#/host" {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+
+	page, err := RenderLinkPreview(preview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(page, "<script>") || !strings.Contains(page, `content="summary"`) {
+		t.Errorf("expected an escaped title and a card without an image, got %s", page)
+	}
+}
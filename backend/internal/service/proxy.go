@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+)
+
+// ErrUntrustedProxyHeader is returned when a proxy header is configured without the proxies it may be read from,
+// which would let any client choose the address it is seen under
+var ErrUntrustedProxyHeader = errors.New("a proxy header needs trusted proxies")
+
+// ProxyChain finds the address of a client behind the reverse proxies in front of the server
+type ProxyChain struct {
+	header  string         // Header the proxies append the address of their peer to, empty to use the peer address
+	trusted []netip.Prefix // Addresses and ranges of the proxies
+}
+
+// Proxies creates the chain of reverse proxies in front of the server
+// Parameters:
+// - header: the header the proxies append the address of their peer to, e.g. X-Forwarded-For; empty for no proxies
+// - trusted: the addresses and CIDR ranges of the proxies
+// Returns:
+// - *ProxyChain: the chain
+// - error: ErrUntrustedProxyHeader if there is a header but no proxies, or an error if an entry cannot be read
+func Proxies(header string, trusted []string) (*ProxyChain, error) {
+	if header != "" && len(trusted) == 0 {
+		return nil, ErrUntrustedProxyHeader
+	}
+
+	chain := &ProxyChain{header: header}
+	for _, proxy := range trusted {
+		prefix, err := parseNetwork(proxy)
+		if err != nil {
+			return nil, err
+		}
+		chain.trusted = append(chain.trusted, prefix)
+	}
+
+	return chain, nil
+}
+
+// Header tells which header the proxies put the client address in
+// Returns:
+// - string: the header, empty if there are no proxies
+func (p *ProxyChain) Header() string {
+	return p.header
+}
+
+// ClientAddress finds the address of a client. Each proxy appends the address it got the request from,
+// so the hops are read from the right, and the first one not added by a trusted proxy is the client.
+// Anything to the left of it was sent by the client itself and is ignored.
+// Parameters:
+// - peer: the address the request came from
+// - forwarded: the value of the proxy header, hops separated by commas
+// Returns:
+// - string: the address of the client, the peer if it is not a trusted proxy
+func (p *ProxyChain) ClientAddress(peer string, forwarded string) string {
+	if p.header == "" || !p.isTrusted(peer) {
+		return peer
+	}
+
+	client := peer
+	hops := strings.Split(forwarded, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break // Garbage can only come from the client, so the last proxy's peer is as far as is known
+		}
+
+		client = hop.Unmap().String()
+		if !p.isTrusted(client) {
+			break
+		}
+	}
+
+	return client
+}
+
+// isTrusted reports whether an address belongs to one of the proxies
+// Parameters:
+// - address: the address
+// Returns:
+// - bool: true if it is a trusted proxy
+func (p *ProxyChain) isTrusted(address string) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+
+	for _, prefix := range p.trusted {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package service
+
+import "testing"
+
+func TestProxyChainNeedsTrustedProxies(t *testing.T) {
+	if _, err := Proxies("X-Forwarded-For", nil); err != ErrUntrustedProxyHeader {
+		t.Errorf("expected a proxy header without trusted proxies to be refused, got %v", err)
+	}
+
+	if _, err := Proxies("", nil); err != nil {
+		t.Errorf("expected no proxies to be fine without a header, got %v", err)
+	}
+
+	if _, err := Proxies("X-Forwarded-For", []string{"not a proxy"}); err == nil {
+		t.Error("expected an unreadable proxy to be refused")
+	}
+}
+
+func TestClientAddressBehindProxies(t *testing.T) {
+	proxies, err := Proxies("X-Forwarded-For", []string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		peer      string
+		forwarded string
+		want      string
+	}{
+		{"10.0.0.1", "203.0.113.7", "203.0.113.7"},                          // A single proxy
+		{"10.0.0.1", "198.51.100.1, 203.0.113.7", "203.0.113.7"},            // The client made up the leftmost hop
+		{"10.0.0.1", "198.51.100.1, 203.0.113.7, 192.0.2.1", "203.0.113.7"}, // Two proxies
+		{"10.0.0.1", "junk, 203.0.113.7", "203.0.113.7"},                    // Garbage to the left of the client is ignored
+		{"10.0.0.1", "203.0.113.7, junk", "10.0.0.1"},                       // Garbage right after a proxy stops at that proxy
+		{"10.0.0.1", "192.0.2.1", "192.0.2.1"},                              // Only proxies, the farthest one is all there is
+		{"198.51.100.9", "203.0.113.7", "198.51.100.9"},                     // The header of a client reaching the server directly
+	}
+	for _, test := range tests {
+		if got := proxies.ClientAddress(test.peer, test.forwarded); got != test.want {
+			t.Errorf("ClientAddress(%q, %q) = %q, want %q", test.peer, test.forwarded, got, test.want)
+		}
+	}
+
+	direct, _ := Proxies("", nil)
+	if got := direct.ClientAddress("198.51.100.9", "203.0.113.7"); got != "198.51.100.9" {
+		t.Errorf("expected the header to be ignored without proxies, got %q", got)
+	}
+}
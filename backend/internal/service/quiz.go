@@ -1,66 +1,290 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"quiz.com/quiz/internal/collection"
 	"quiz.com/quiz/internal/entity"
 )
 
+// quizInvalidationSubject is the message bus subject instances announce updated quizzes on, so they drop them from their caches
+const quizInvalidationSubject = "quiz.cache.invalidate"
+
 // QuizService provides methods for managing quizzes, including retrieval, update, and listing.
+// Reads go through an in-memory cache that is invalidated whenever a quiz is updated, on every instance sharing a message bus.
 type QuizService struct {
 	quizCollection *collection.QuizCollection // Reference to the quiz collection for database operations
+	bus            MessageBus                 // Bus other instances are told of updated quizzes on, nil for a single instance
+
+	quizCache     *cache[primitive.ObjectID, entity.Quiz] // Cached quizzes by ID
+	quizListCache *cache[struct{}, []entity.Quiz]         // Cached list of all quizzes
 }
 
 // Quiz initializes and returns a new QuizService instance.
 // Parameters:
 // - quizCollection: the collection that interacts with the quiz data in the database.
-func Quiz(quizCollection *collection.QuizCollection) *QuizService {
-	return &QuizService{
+// - cacheTtl: how long quizzes are cached, or zero to always read from the database.
+// - bus: the message bus shared with the other instances, nil for a single instance.
+func Quiz(quizCollection *collection.QuizCollection, cacheTtl time.Duration, bus MessageBus) *QuizService {
+	s := &QuizService{
 		quizCollection: quizCollection,
+		bus:            bus,
+		quizCache:      newCache[primitive.ObjectID, entity.Quiz](cacheTtl),
+		quizListCache:  newCache[struct{}, []entity.Quiz](cacheTtl),
+	}
+
+	if bus != nil {
+		if err := bus.Subscribe(quizInvalidationSubject, s.onQuizInvalidated); err != nil {
+			fmt.Println("failed to subscribe to quiz updates:", err)
+		}
+	}
+
+	return s
+}
+
+// invalidateQuiz drops an updated quiz and the quiz list from the caches of this and every other instance.
+// Parameters:
+// - id: the ObjectID of the quiz, or NilObjectID if only the list changed.
+func (s QuizService) invalidateQuiz(id primitive.ObjectID) {
+	s.dropCachedQuiz(id)
+	if s.bus == nil {
+		return
+	}
+
+	// Other instances serve the stale quiz until their cache expires if the bus is down
+	if err := s.bus.Publish(quizInvalidationSubject, []byte(id.Hex())); err != nil {
+		fmt.Println("failed to announce quiz update:", err)
 	}
 }
 
+// onQuizInvalidated drops a quiz another instance updated from the caches.
+// Parameters:
+// - data: the hex ObjectID of the quiz.
+func (s QuizService) onQuizInvalidated(data []byte) {
+	id, err := primitive.ObjectIDFromHex(string(data))
+	if err != nil {
+		id = primitive.NilObjectID
+	}
+
+	s.dropCachedQuiz(id)
+}
+
+// dropCachedQuiz drops a quiz and the quiz list from the caches of this instance.
+// Parameters:
+// - id: the ObjectID of the quiz, or NilObjectID if only the list changed.
+func (s QuizService) dropCachedQuiz(id primitive.ObjectID) {
+	if !id.IsZero() {
+		s.quizCache.invalidate(id)
+	}
+	s.quizListCache.invalidate(struct{}{})
+}
+
 // GetQuizById retrieves a quiz by its unique identifier.
 // Parameters:
+// - ctx: the context bounding the database operations.
 // - id: the ObjectID of the quiz to retrieve.
 // Returns:
-// - A pointer to the Quiz entity and an error if something goes wrong.
-func (s QuizService) GetQuizById(id primitive.ObjectID) (*entity.Quiz, error) {
-	return s.quizCollection.GetQuizById(id)
+// - A pointer to a copy of the Quiz entity, or nil if it does not exist, and an error if something goes wrong.
+func (s QuizService) GetQuizById(ctx context.Context, id primitive.ObjectID) (*entity.Quiz, error) {
+	if quiz, ok := s.quizCache.get(id); ok {
+		return &quiz, nil
+	}
+
+	quiz, err := s.quizCollection.GetQuizById(ctx, id)
+	if err != nil || quiz == nil {
+		return quiz, err
+	}
+
+	s.quizCache.set(id, *quiz)
+	return quiz, nil
 }
 
-// UpdateQuiz updates the name and questions of an existing quiz.
+// UpdateQuiz updates the name, settings and questions of an existing quiz.
 // Parameters:
+// - ctx: the context bounding the database operations.
 // - id: the ObjectID of the quiz to update.
+// - userId: the ObjectID of the user saving the quiz, zero for anonymous requests.
 // - name: the new name for the quiz.
+// - settings: the new default time and scoring of the quiz.
 // - questions: the updated list of questions for the quiz.
 // Returns:
-// - An error if the update fails or the quiz is not found.
-func (s QuizService) UpdateQuiz(id primitive.ObjectID, name string, questions []entity.QuizQuestion) error {
+// - Warnings about near-duplicate questions within the quiz or across the quizzes the user may view, and ErrInvalidQuizSettings if the settings are out of range or an error if the update fails or the quiz is not found.
+func (s QuizService) UpdateQuiz(ctx context.Context, id primitive.ObjectID, userId primitive.ObjectID, name string, settings entity.QuizSettings, questions []entity.QuizQuestion) ([]DuplicateWarning, error) {
+	if err := validateQuizSettings(settings); err != nil {
+		return nil, err
+	}
+
 	// Retrieve the quiz by ID
-	quiz, err := s.quizCollection.GetQuizById(id)
+	quiz, err := s.quizCollection.GetQuizById(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check if the quiz exists
 	if quiz == nil {
-		return errors.New("quiz not found")
+		return nil, errors.New("quiz not found")
 	}
 
-	// Update the quiz's name and questions
+	// Questions are only compared against quizzes the user may see, so warnings cannot reveal private quizzes
+	library, err := s.quizCollection.GetVisibleQuestions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the quiz's name and questions, keeping the statistics of questions that stay
+	keepQuestionStats(quiz.Questions, questions)
+	quiz.Draft = false // Saving a machine translated draft means its author reviewed it
+	quiz.UpdatedAt = time.Now()
 	quiz.Name = name
+	quiz.QuizSettings = settings
 	quiz.Questions = questions
 
 	// Save the updated quiz back to the collection
-	return s.quizCollection.UpdateQuiz(*quiz)
+	if err := s.quizCollection.UpdateQuiz(ctx, *quiz); err != nil {
+		return nil, err
+	}
+
+	s.invalidateQuiz(id)
+	return findDuplicates(*quiz, library, userId), nil
+}
+
+// UpdateWebhooks replaces the Slack and Discord webhooks games of a quiz are announced in.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the quiz to update.
+// - webhooks: the new webhooks of the quiz.
+// Returns:
+// - ErrInvalidWebhook if any webhook is invalid, or an error if the update fails or the quiz is not found.
+func (s QuizService) UpdateWebhooks(ctx context.Context, id primitive.ObjectID, webhooks []entity.Webhook) error {
+	if err := validateWebhooks(webhooks); err != nil {
+		return err
+	}
+
+	// Check if the quiz exists
+	quiz, err := s.quizCollection.GetQuizById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if quiz == nil {
+		return errors.New("quiz not found")
+	}
+
+	if err := s.quizCollection.UpdateQuizWebhooks(ctx, id, webhooks); err != nil {
+		return err
+	}
+
+	s.invalidateQuiz(id)
+	return nil
+}
+
+// ImportQuiz creates a new quiz from a GIFT or Moodle XML file, owned by the importing user.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - ownerId: the ObjectID of the importing user.
+// - name: the name of the new quiz.
+// - format: the format of the file, GiftFormat or MoodleFormat.
+// - data: the content of the file.
+// Returns:
+// - The created quiz, the items of the file that could not be converted, and an error if the file cannot be read or the quiz cannot be stored.
+func (s QuizService) ImportQuiz(ctx context.Context, ownerId primitive.ObjectID, name string, format string, data []byte) (*entity.Quiz, []ImportIssue, error) {
+	questions, issues, err := ParseQuestions(format, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quiz, err := s.createQuiz(ctx, ownerId, name, entity.QuizSettings{}, questions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return quiz, issues, nil
+}
+
+// CreateQuiz stores a new quiz in the open library, without an owner.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - name: the name of the quiz.
+// - settings: the default time and scoring of the quiz.
+// - questions: the questions of the quiz.
+// Returns:
+// - The created quiz, and ErrInvalidQuizSettings if the settings are out of range or an error if it cannot be stored.
+func (s QuizService) CreateQuiz(ctx context.Context, name string, settings entity.QuizSettings, questions []entity.QuizQuestion) (*entity.Quiz, error) {
+	return s.createQuiz(ctx, primitive.NilObjectID, name, settings, questions)
+}
+
+// createQuiz stores a new quiz
+// Parameters:
+// - ctx: the context bounding the database operations
+// - ownerId: the ObjectID of the owner, zero for the open library
+// - name: the name of the quiz
+// - settings: the default time and scoring of the quiz
+// - questions: the questions of the quiz
+// Returns:
+// - *entity.Quiz: the created quiz
+// - error: ErrInvalidQuizSettings if the settings are out of range, or an error if it cannot be stored
+func (s QuizService) createQuiz(ctx context.Context, ownerId primitive.ObjectID, name string, settings entity.QuizSettings, questions []entity.QuizQuestion) (*entity.Quiz, error) {
+	return s.insertQuiz(ctx, entity.Quiz{
+		Id:           primitive.NewObjectID(),
+		OwnerId:      ownerId,
+		Name:         name,
+		QuizSettings: settings,
+		Questions:    questions,
+	})
+}
+
+// insertQuiz stores a new quiz built by the caller
+// Parameters:
+// - ctx: the context bounding the database operations
+// - quiz: the quiz, with its ID and owner set
+// Returns:
+// - *entity.Quiz: the created quiz
+// - error: ErrInvalidQuizSettings if the settings are out of range, or an error if it cannot be stored
+func (s QuizService) insertQuiz(ctx context.Context, quiz entity.Quiz) (*entity.Quiz, error) {
+	if err := validateQuizSettings(quiz.QuizSettings); err != nil {
+		return nil, err
+	}
+	quiz.UpdatedAt = time.Now()
+
+	if err := s.quizCollection.InsertQuiz(ctx, quiz); err != nil {
+		return nil, err
+	}
+
+	s.invalidateQuiz(primitive.NilObjectID)
+	return &quiz, nil
 }
 
 // GetQuizzes retrieves all available quizzes.
+// Parameters:
+// - ctx: the context bounding the database operations.
 // Returns:
 // - A slice of Quiz entities and an error if something goes wrong.
-func (s QuizService) GetQuizzes() ([]entity.Quiz, error) {
-	return s.quizCollection.GetQuizzes()
+func (s QuizService) GetQuizzes(ctx context.Context) ([]entity.Quiz, error) {
+	// Hand out a copy so callers cannot change the cached list
+	if quizzes, ok := s.quizListCache.get(struct{}{}); ok {
+		return slices.Clone(quizzes), nil
+	}
+
+	quizzes, err := s.quizCollection.GetQuizzes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.quizListCache.set(struct{}{}, slices.Clone(quizzes))
+	return quizzes, nil
+}
+
+// GetCacheStats reports how effective the quiz caches are.
+// Returns:
+// - The statistics of the cache of single quizzes and of the cache of the quiz list, by name.
+func (s QuizService) GetCacheStats() map[string]CacheStats {
+	return map[string]CacheStats{
+		"quiz":    s.quizCache.stats(),
+		"quizzes": s.quizListCache.stats(),
+	}
 }
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// maxQuestionTime is the longest time in seconds a question can be given
+const maxQuestionTime = 600
+
+// Errors returned for quiz settings and question times out of range
+var (
+	ErrInvalidQuestionTime = errors.New("question time out of range")
+	ErrInvalidQuizSettings = errors.New("invalid quiz settings")
+)
+
+// SetQuestionTimes gives every question of a quiz the same time to answer.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the quiz to update.
+// - seconds: the time to give every question.
+// Returns:
+// - The updated quiz, or nil if it does not exist, and ErrInvalidQuestionTime if the time is out of range or an error if the update fails.
+func (s QuizService) SetQuestionTimes(ctx context.Context, id primitive.ObjectID, seconds int) (*entity.Quiz, error) {
+	if seconds <= 0 || seconds > maxQuestionTime {
+		return nil, ErrInvalidQuestionTime
+	}
+
+	quiz, err := s.quizCollection.GetQuizById(ctx, id)
+	if err != nil || quiz == nil {
+		return quiz, err
+	}
+
+	for i := range quiz.Questions {
+		quiz.Questions[i].Time = seconds
+	}
+	quiz.UpdatedAt = time.Now()
+
+	if err := s.quizCollection.UpdateQuiz(ctx, *quiz); err != nil {
+		return nil, err
+	}
+
+	s.invalidateQuiz(id)
+	return quiz, nil
+}
+
+// validateQuizSettings checks that the default time and total points of a quiz are within range, zero meaning none,
+// and that its grade boundaries, attempt policy and tie-breaker question are well formed
+// Parameters:
+// - settings: the settings to check
+// Returns:
+// - error: ErrInvalidQuizSettings if a setting is out of range, nil otherwise
+func validateQuizSettings(settings entity.QuizSettings) error {
+	if settings.DefaultTime < 0 || settings.DefaultTime > maxQuestionTime {
+		return ErrInvalidQuizSettings
+	}
+
+	if settings.TotalPoints < 0 || settings.TotalPoints > maxTotalPoints {
+		return ErrInvalidQuizSettings
+	}
+
+	if validateGrades(settings.Grades) != nil {
+		return ErrInvalidQuizSettings
+	}
+
+	if validateAttemptPolicy(settings.Attempts) != nil {
+		return ErrInvalidQuizSettings
+	}
+
+	if settings.TieBreaker != nil && !validTieBreaker(*settings.TieBreaker) {
+		return ErrInvalidQuizSettings
+	}
+
+	return nil
+}
+
+// withDefaultTime returns a copy of a quiz in which questions without a time of their own take the
+// quiz's default time, so games never have to look it up
+// Parameters:
+// - quiz: the quiz about to be played
+// Returns:
+// - entity.Quiz: the quiz with a time for every question the default covers
+func withDefaultTime(quiz entity.Quiz) entity.Quiz {
+	if quiz.DefaultTime <= 0 {
+		return quiz
+	}
+
+	questions := make([]entity.QuizQuestion, len(quiz.Questions))
+	for i, question := range quiz.Questions {
+		if question.Time <= 0 {
+			question.Time = quiz.DefaultTime
+		}
+		questions[i] = question
+	}
+	quiz.Questions = questions
+
+	if quiz.TieBreaker != nil && quiz.TieBreaker.Time <= 0 {
+		tieBreaker := *quiz.TieBreaker
+		tieBreaker.Time = quiz.DefaultTime
+		quiz.TieBreaker = &tieBreaker
+	}
+
+	return quiz
+}
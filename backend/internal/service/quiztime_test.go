@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestGameTakesDefaultTime(t *testing.T) {
+	quiz := fuzzQuiz()
+	quiz.DefaultTime = 45
+	quiz.Questions[1].Time = 0
+
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(quiz, &fakeConnection{}, c)
+	if got := game.Quiz.Questions[1].Time; got != 45 {
+		t.Errorf("expected the question without a time to take the default, got %d", got)
+	}
+	if got := game.Quiz.Questions[0].Time; got != 3 {
+		t.Errorf("expected a question's own time to stay, got %d", got)
+	}
+	if quiz.Questions[1].Time != 0 {
+		t.Errorf("the default was written into the stored quiz")
+	}
+}
+
+func TestLintAcceptsDefaultTime(t *testing.T) {
+	questions := []entity.QuizQuestion{
+		{Id: "untimed", Name: "Untimed", Choices: []entity.QuizChoice{{Name: "A", Correct: true}, {Name: "B"}}},
+	}
+
+	if warnings := LintQuestions(questions, 30); len(warnings) != 0 {
+		t.Errorf("expected the default time to cover the question, got %+v", warnings)
+	}
+	if warnings := LintQuestions(questions, 0); len(warnings) != 1 || warnings[0].Kind != LintNoTime {
+		t.Errorf("expected a missing time without a default, got %+v", warnings)
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Errors returned when a user is over the limits of their plan.
+var (
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	ErrDailyLimit    = errors.New("daily limit reached")
+	ErrInvalidQuota  = errors.New("invalid quota")
+	ErrUserNotFound  = errors.New("user not found")
+)
+
+// Usage is what a user used of their plan
+type Usage struct {
+	Quota      entity.Quota `json:"quota"`      // Limits of the user's plan, zero meaning no limit
+	Quizzes    int          `json:"quizzes"`    // Number of quizzes the user owns
+	GamesToday int          `json:"gamesToday"` // Number of games the user hosted today, counted in UTC
+}
+
+// QuotaService enforces the limits of the users' plans on hosted deployments.
+type QuotaService struct {
+	usageCollection *collection.UsageCollection // Reference to the usage collection for counting games
+	userCollection  *collection.UserCollection  // Reference to the user collection for the plans of users
+	quizService     *QuizService                // Service used to count the quizzes a user owns
+	defaults        entity.Quota                // Limits of users without a plan of their own
+}
+
+// Quota initializes and returns a new QuotaService instance.
+// Parameters:
+// - usageCollection: the collection that counts the games of users per day.
+// - userCollection: the collection that interacts with the users in the database.
+// - quizService: the service used to count the quizzes of users.
+// - config: the runtime configuration, providing the default limits.
+func Quota(usageCollection *collection.UsageCollection, userCollection *collection.UserCollection, quizService *QuizService, config config.Config) *QuotaService {
+	return &QuotaService{
+		usageCollection: usageCollection,
+		userCollection:  userCollection,
+		quizService:     quizService,
+		defaults: entity.Quota{
+			Quizzes:     config.QuotaQuizzes,
+			Players:     config.QuotaPlayers,
+			GamesPerDay: config.QuotaGamesPerDay,
+		},
+	}
+}
+
+// GetQuota finds the limits of a user's plan.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - userId: the ObjectID of the user, zero for anonymous hosts.
+// Returns:
+// - The limits and an error if the user cannot be read.
+func (s QuotaService) GetQuota(ctx context.Context, userId primitive.ObjectID) (entity.Quota, error) {
+	if userId.IsZero() {
+		return s.defaults, nil
+	}
+
+	user, err := s.userCollection.GetUserById(ctx, userId)
+	if err != nil {
+		return entity.Quota{}, err
+	}
+
+	return userQuota(user, s.defaults), nil
+}
+
+// GetUsage reports what a user used of their plan.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - userId: the ObjectID of the user.
+// Returns:
+// - The usage and an error if it cannot be read.
+func (s QuotaService) GetUsage(ctx context.Context, userId primitive.ObjectID) (*Usage, error) {
+	quota, err := s.GetQuota(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	quizzes, err := s.countQuizzes(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := s.usageCollection.GetDailyUsage(ctx, userId, usageDay(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Usage{
+		Quota:      quota,
+		Quizzes:    quizzes,
+		GamesToday: daily.Games,
+	}, nil
+}
+
+// CheckQuizzes checks that a user may create another quiz.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - userId: the ObjectID of the user.
+// Returns:
+// - ErrQuotaExceeded if the user owns as many quizzes as their plan allows, or an error if the usage cannot be read.
+func (s QuotaService) CheckQuizzes(ctx context.Context, userId primitive.ObjectID) error {
+	quota, err := s.GetQuota(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if quota.Quizzes == 0 {
+		return nil
+	}
+
+	quizzes, err := s.countQuizzes(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if overLimit(quota.Quizzes, quizzes) {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// StartGame checks that a user may host another game today and counts the game.
+// Games of anonymous hosts are not counted, only limited in players.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - userId: the ObjectID of the host, zero if they are not signed in.
+// Returns:
+// - The most players who may join the game, zero for no limit, and ErrDailyLimit if the user hosted
+// as many games today as their plan allows, or an error if the usage cannot be read or counted.
+func (s QuotaService) StartGame(ctx context.Context, userId primitive.ObjectID) (int, error) {
+	quota, err := s.GetQuota(ctx, userId)
+	if err != nil || userId.IsZero() {
+		return quota.Players, err
+	}
+
+	day := usageDay(time.Now())
+	if quota.GamesPerDay > 0 {
+		daily, err := s.usageCollection.GetDailyUsage(ctx, userId, day)
+		if err != nil {
+			return 0, err
+		}
+
+		if overLimit(quota.GamesPerDay, daily.Games) {
+			return 0, ErrDailyLimit
+		}
+	}
+
+	if err := s.usageCollection.IncrementGames(ctx, userId, day); err != nil {
+		return 0, err
+	}
+
+	return quota.Players, nil
+}
+
+// SetQuota gives a user a plan of their own, or puts them back on the deployment's defaults.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - userId: the ObjectID of the user.
+// - quota: the limits, nil for the defaults.
+// Returns:
+// - ErrInvalidQuota if a limit is negative, ErrUserNotFound if the user does not exist, or an error if the update fails.
+func (s QuotaService) SetQuota(ctx context.Context, userId primitive.ObjectID, quota *entity.Quota) error {
+	if quota != nil && (quota.Quizzes < 0 || quota.Players < 0 || quota.GamesPerDay < 0) {
+		return ErrInvalidQuota
+	}
+
+	found, err := s.userCollection.UpdateQuota(ctx, userId, quota)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// countQuizzes counts the quizzes a user owns
+// Parameters:
+// - ctx: the context bounding the database operations
+// - userId: the ObjectID of the user
+// Returns:
+// - int: the number of quizzes
+// - error: an error if the quizzes cannot be read
+func (s QuotaService) countQuizzes(ctx context.Context, userId primitive.ObjectID) (int, error) {
+	quizzes, err := s.quizService.GetQuizzes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, quiz := range quizzes {
+		if quiz.OwnerId == userId {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// userQuota finds the limits of a user: admins have none, others their own plan or the defaults
+// Parameters:
+// - user: the user, nil if they do not exist
+// - defaults: the limits of users without a plan of their own
+// Returns:
+// - entity.Quota: the limits
+func userQuota(user *entity.User, defaults entity.Quota) entity.Quota {
+	switch {
+	case user == nil:
+		return defaults
+	case user.Role == entity.AdminRole:
+		return entity.Quota{}
+	case user.Quota != nil:
+		return *user.Quota
+	}
+
+	return defaults
+}
+
+// overLimit reports whether something used as often as counted reached its limit
+// Parameters:
+// - limit: the limit, zero for none
+// - used: how often it was used
+// Returns:
+// - bool: true if it cannot be used again
+func overLimit(limit int, used int) bool {
+	return limit > 0 && used >= limit
+}
+
+// usageDay formats the day usage is counted on
+// Parameters:
+// - now: the current time
+// Returns:
+// - string: the day in UTC, formatted as 2006-01-02
+func usageDay(now time.Time) string {
+	return now.UTC().Format(time.DateOnly)
+}
+
+// applyQuota counts a new game against the plan of its host and caps its players
+// Parameters:
+// - ctx: the context bounding the database operations
+// - game: the game about to start, its host already set
+// Returns:
+// - error: ErrDailyLimit if the host hosted as many games today as their plan allows, or an error if the usage cannot be counted
+func (c *NetService) applyQuota(ctx context.Context, game *Game) error {
+	if c.quotaService == nil {
+		return nil
+	}
+
+	maxPlayers, err := c.quotaService.StartGame(ctx, game.hostUserId)
+	if err != nil {
+		return err
+	}
+
+	game.maxPlayers = maxPlayers
+	return nil
+}
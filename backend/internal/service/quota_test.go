@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestUserQuota(t *testing.T) {
+	defaults := entity.Quota{Quizzes: 5, Players: 30, GamesPerDay: 3}
+	plan := entity.Quota{Quizzes: 50, Players: 200}
+
+	tests := []struct {
+		name string
+		user *entity.User
+		want entity.Quota
+	}{
+		{"unknown user", nil, defaults},
+		{"user without a plan", &entity.User{Role: entity.UserRole}, defaults},
+		{"user with a plan", &entity.User{Role: entity.UserRole, Quota: &plan}, plan},
+		{"admin", &entity.User{Role: entity.AdminRole, Quota: &plan}, entity.Quota{}},
+	}
+
+	for _, test := range tests {
+		if got := userQuota(test.user, defaults); got != test.want {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestOverLimit(t *testing.T) {
+	if overLimit(0, 1000) {
+		t.Errorf("expected no limit for zero")
+	}
+	if overLimit(3, 2) || !overLimit(3, 3) {
+		t.Errorf("expected the limit to be reached at 3")
+	}
+	if day := usageDay(time.Date(2024, 3, 1, 23, 30, 0, 0, time.FixedZone("", -2*60*60))); day != "2024-03-02" {
+		t.Errorf("expected usage to be counted in UTC, got %s", day)
+	}
+}
+
+func TestFullGame(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.maxPlayers = 1
+
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	bob := &fakeConnection{}
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", bob)
+
+	if len(game.Players) != 1 || !bob.closed {
+		t.Errorf("expected bob to be turned away from the full game, got %d players", len(game.Players))
+	}
+}
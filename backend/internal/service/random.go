@@ -0,0 +1,62 @@
+package service
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Join codes
+const (
+	defaultCodeLength   = 6            // Number of characters of a join code unless configured otherwise
+	defaultCodeAlphabet = "0123456789" // Characters join codes are drawn from unless configured otherwise
+)
+
+// newRand creates a random source
+// Parameters:
+// - seed: the seed, zero to seed from the current time
+// Returns:
+// - *rand.Rand: the random source
+func newRand(seed int) *rand.Rand {
+	if seed == 0 {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return rand.New(rand.NewSource(int64(seed)))
+}
+
+// generateCode draws a code for players to join a game from the configured alphabet, of the configured length
+// up to maxCodeLength
+// Returns:
+// - string: the code
+func (c *NetService) generateCode() string {
+	alphabet := []rune(c.config.GameCodeAlphabet)
+	if len(alphabet) == 0 {
+		alphabet = []rune(defaultCodeAlphabet)
+	}
+
+	length := c.config.GameCodeLength
+	if length <= 0 {
+		length = defaultCodeLength
+	}
+
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+
+	code := make([]rune, min(length, maxCodeLength))
+	for i := range code {
+		code[i] = alphabet[c.rand.Intn(len(alphabet))]
+	}
+
+	return string(code)
+}
+
+// newGameRand creates the random source of a new game, so games draw from their own source
+// without sharing state across goroutines
+// Returns:
+// - *rand.Rand: the random source, seeded from the one of the network service
+func (c *NetService) newGameRand() *rand.Rand {
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+
+	return rand.New(rand.NewSource(c.rand.Int63()))
+}
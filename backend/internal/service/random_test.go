@@ -0,0 +1,45 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"quiz.com/quiz/internal/config"
+)
+
+func TestSeededCodes(t *testing.T) {
+	codes := func() []string {
+		c := Net(NetOptions{}, config.Config{RandomSeed: 42, GameCodeAlphabet: "ABCDEFGHJKLMNPQRSTUVWXYZ"})
+		return []string{newGame(fuzzQuiz(), nil, c).Code, newGame(fuzzQuiz(), nil, c).Code}
+	}
+
+	first, second := codes(), codes()
+	if first[0] != second[0] || first[1] != second[1] {
+		t.Errorf("expected the same seed to draw the same codes, got %v and %v", first, second)
+	}
+
+	for _, code := range first {
+		if len(code) != defaultCodeLength || strings.Trim(code, "ABCDEFGHJKLMNPQRSTUVWXYZ") != "" {
+			t.Errorf("code %q is not drawn from the alphabet", code)
+		}
+	}
+}
+
+func TestDefaultCodeAlphabet(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	if code := c.generateCode(); len(code) != defaultCodeLength || strings.Trim(code, defaultCodeAlphabet) != "" {
+		t.Errorf("expected a numeric code, got %q", code)
+	}
+}
+
+func TestLongerCodes(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{GameCodeAlphabet: "ABCDEFGHJKLMNPQRSTUVWXYZ23456789", GameCodeLength: 10})
+	if code := c.generateCode(); len(code) != 10 || strings.Trim(code, "ABCDEFGHJKLMNPQRSTUVWXYZ23456789") != "" {
+		t.Errorf("expected a 10 character alphanumeric code, got %q", code)
+	}
+
+	c = Net(NetOptions{}, config.Config{GameCodeLength: 100})
+	if code := c.generateCode(); len(code) != maxCodeLength {
+		t.Errorf("expected the code to be cut to the longest players may send, got %q", code)
+	}
+}
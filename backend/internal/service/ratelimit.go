@@ -0,0 +1,163 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// rateWindow counts the attempts of a client within a window
+type rateWindow struct {
+	start       time.Time // When the window began
+	count       int       // Attempts within the window
+	lockedUntil time.Time // When the lockout of a client who used up its window ends, zero if not locked out
+}
+
+// rateLimiter allows each client a number of attempts per fixed window of time, such as lookups of join codes per address
+type rateLimiter struct {
+	limit   int           // Most attempts per window; zero for no limit
+	window  time.Duration // Length of a window
+	lockout time.Duration // How long a client who used up its window is locked out; zero to wait for the next window
+
+	mu      sync.Mutex             // Guards the fields below
+	clients map[string]*rateWindow // Current window of each client
+	swept   time.Time              // When windows that ended were last forgotten
+}
+
+// newRateLimiter creates a limiter of attempts per client
+// Parameters:
+// - limit: the most attempts per window, zero for no limit
+// - window: the length of a window
+// Returns:
+// - *rateLimiter: the limiter
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: map[string]*rateWindow{},
+	}
+}
+
+// newLockoutLimiter creates a limiter that locks clients out for a while once they used up their attempts,
+// such as failed joins per address
+// Parameters:
+// - limit: the most attempts per window, zero for no limit
+// - window: the length of a window
+// - lockout: how long a client who used up its attempts is locked out
+// Returns:
+// - *rateLimiter: the limiter
+func newLockoutLimiter(limit int, window time.Duration, lockout time.Duration) *rateLimiter {
+	limiter := newRateLimiter(limit, window)
+	limiter.lockout = lockout
+	return limiter
+}
+
+// allow counts an attempt of a client
+// Parameters:
+// - key: the client, e.g. their IP address
+// - now: the current time
+// Returns:
+// - time.Duration: zero if the attempt is allowed, otherwise how long until the client may try again
+func (l *rateLimiter) allow(key string, now time.Time) time.Duration {
+	return l.allowN(key, 1, now)
+}
+
+// allowN counts several attempts of a client at once, such as the emails of a batch of invitations.
+// Either all of them are allowed or none is counted.
+// Parameters:
+// - key: the client, e.g. their IP address
+// - n: the number of attempts
+// - now: the current time
+// Returns:
+// - time.Duration: zero if the attempts are allowed, otherwise how long until the client may try again
+func (l *rateLimiter) allowN(key string, n int, now time.Time) time.Duration {
+	if l.limit <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	client, ok := l.clients[key]
+	if !ok || l.expired(client, now) {
+		client = &rateWindow{start: now}
+		l.clients[key] = client
+	}
+
+	if client.count+n > l.limit {
+		if wait := l.wait(client, now); wait > 0 {
+			return wait
+		}
+		return client.start.Add(l.window).Sub(now)
+	}
+
+	client.count += n
+	if client.count >= l.limit && l.lockout > 0 {
+		client.lockedUntil = now.Add(l.lockout)
+	}
+	return 0
+}
+
+// retryAfter tells how long until a client may try again, without counting an attempt
+// Parameters:
+// - key: the client, e.g. their IP address
+// - now: the current time
+// Returns:
+// - time.Duration: zero if an attempt would be allowed, otherwise how long until the client may try again
+func (l *rateLimiter) retryAfter(key string, now time.Time) time.Duration {
+	if l.limit <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	client, ok := l.clients[key]
+	if !ok {
+		return 0
+	}
+	return l.wait(client, now)
+}
+
+// wait tells how long until a client may try again; the caller holds the lock
+// Parameters:
+// - client: the window of the client
+// - now: the current time
+// Returns:
+// - time.Duration: zero if an attempt would be allowed
+func (l *rateLimiter) wait(client *rateWindow, now time.Time) time.Duration {
+	if client.lockedUntil.After(now) {
+		return client.lockedUntil.Sub(now)
+	}
+	if client.count >= l.limit && now.Sub(client.start) < l.window {
+		return client.start.Add(l.window).Sub(now)
+	}
+
+	return 0
+}
+
+// sweep forgets the windows and lockouts that ended, at most once per window; the caller holds the lock
+// Parameters:
+// - now: the current time
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.window {
+		return
+	}
+
+	l.swept = now
+	for key, client := range l.clients {
+		if l.expired(client, now) {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// expired reports whether both the window and the lockout of a client ended
+// Parameters:
+// - client: the window of the client
+// - now: the current time
+// Returns:
+// - bool: true if the client may start a new window
+func (l *rateLimiter) expired(client *rateWindow, now time.Time) bool {
+	return now.Sub(client.start) >= l.window && !client.lockedUntil.After(now)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ratingK is the maximum rating change a single game can cause
+const ratingK = 32
+
+// ratedPlacement is a rated player's finishing position in a game
+type ratedPlacement struct {
+	UserId primitive.ObjectID // ID of the user
+	Rating int                // Rating before the game
+	Points int                // Points scored, used to order the players
+}
+
+// calculateRatings computes new Elo-style ratings after a game. Each player is
+// treated as having played a match against every other rated player, winning
+// against those who scored fewer points; the change is averaged over the
+// opponents so large games do not swing ratings more than small ones.
+// Parameters:
+// - placements: the rated players of the game
+// Returns:
+// - map[primitive.ObjectID]int: the new rating of each user
+func calculateRatings(placements []ratedPlacement) map[primitive.ObjectID]int {
+	ratings := map[primitive.ObjectID]int{}
+	if len(placements) < 2 {
+		return ratings
+	}
+
+	for i, player := range placements {
+		delta := 0.0
+		for j, opponent := range placements {
+			if i == j {
+				continue
+			}
+
+			expected := 1 / (1 + math.Pow(10, float64(opponent.Rating-player.Rating)/400))
+			actual := 0.5
+			if player.Points > opponent.Points {
+				actual = 1
+			} else if player.Points < opponent.Points {
+				actual = 0
+			}
+
+			delta += actual - expected
+		}
+
+		change := ratingK * delta / float64(len(placements)-1)
+		ratings[player.UserId] = player.Rating + int(math.Round(change))
+	}
+
+	return ratings
+}
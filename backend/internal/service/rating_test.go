@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCalculateRatings(t *testing.T) {
+	winner := primitive.NewObjectID()
+	loser := primitive.NewObjectID()
+
+	ratings := calculateRatings([]ratedPlacement{
+		{UserId: winner, Rating: 1000, Points: 3000},
+		{UserId: loser, Rating: 1000, Points: 1000},
+	})
+
+	if ratings[winner] != 1016 || ratings[loser] != 984 {
+		t.Errorf("expected 1016 and 984, got %d and %d", ratings[winner], ratings[loser])
+	}
+}
+
+func TestCalculateRatingsUpset(t *testing.T) {
+	favourite := primitive.NewObjectID()
+	underdog := primitive.NewObjectID()
+
+	ratings := calculateRatings([]ratedPlacement{
+		{UserId: favourite, Rating: 1400, Points: 0},
+		{UserId: underdog, Rating: 1000, Points: 500},
+	})
+
+	if gain := ratings[underdog] - 1000; gain <= ratingK/2 {
+		t.Errorf("expected an upset to gain more than %d, got %d", ratingK/2, gain)
+	}
+}
+
+func TestCalculateRatingsSinglePlayer(t *testing.T) {
+	ratings := calculateRatings([]ratedPlacement{
+		{UserId: primitive.NewObjectID(), Rating: 1000, Points: 1000},
+	})
+
+	if len(ratings) != 0 {
+		t.Errorf("expected no rating changes for a single player, got %v", ratings)
+	}
+}
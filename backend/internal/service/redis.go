@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Limits of the connection to a Redis server
+const (
+	redisTimeout   = 5 * time.Second // Time allowed to connect and for every command
+	redisMaxBulk   = 512 << 20       // Largest string a Redis server may hold
+	redisKeyPrefix = "quiz:job:"     // Prefix of the keys jobs are stored under
+)
+
+// ErrInvalidRedis is returned when the address of a Redis server is not a redis:// URL
+var ErrInvalidRedis = errors.New("invalid redis address")
+
+// redisError is an error a Redis server replied with, after which the connection is still usable
+type redisError string
+
+// Error returns the message of the server
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisServer is where a Redis server listens and who to authenticate as
+type redisServer struct {
+	address  string // Host and port of the server
+	user     string // User to authenticate as, empty for the default user
+	password string // Password of the user, empty for none
+	database int    // Number of the logical database
+}
+
+// parseRedisUrl reads the address of a Redis server
+// Parameters:
+// - address: redis://[[user]:password@]host[:port][/database] of the server, the port defaulting to 6379
+// Returns:
+// - redisServer: the server
+// - bool: false if the address is not a Redis URL
+func parseRedisUrl(address string) (redisServer, bool) {
+	u, err := url.Parse(address)
+	if err != nil || u.Scheme != "redis" || u.Host == "" {
+		return redisServer{}, false
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	database := 0
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		database, err = strconv.Atoi(path)
+		if err != nil || database < 0 {
+			return redisServer{}, false
+		}
+	}
+
+	password, _ := u.User.Password()
+	return redisServer{
+		address:  host,
+		user:     u.User.Username(),
+		password: password,
+		database: database,
+	}, true
+}
+
+// redisCommand encodes a command in the Redis serialization protocol
+// Parameters:
+// - args: the command and its arguments
+// Returns:
+// - []byte: the encoded command
+func redisCommand(args ...string) []byte {
+	command := fmt.Appendf(nil, "*%d\r\n", len(args))
+	for _, arg := range args {
+		command = fmt.Appendf(command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	return command
+}
+
+// readRedisReply reads the reply to a command
+// Parameters:
+// - reader: the reader of the connection
+// Returns:
+// - []byte: the string of a bulk or simple reply, nil for a null reply or integers
+// - error: the error the server replied with, or an error if the reply cannot be read
+func readRedisReply(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return []byte(line[1:]), nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return nil, nil
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size > redisMaxBulk {
+			return nil, fmt.Errorf("invalid redis reply %q", line)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+
+		return data[:size], nil
+	}
+
+	return nil, fmt.Errorf("unexpected redis reply %q", line)
+}
+
+// redisJob is a job as it is stored in Redis
+type redisJob struct {
+	Job     Job    `json:"job"`     // The job
+	OwnerId string `json:"ownerId"` // Hex ID of the user who started the job, which Job leaves out of JSON
+	Result  []byte `json:"result"`  // Its result, nil until it is done
+}
+
+// RedisJobStore keeps jobs in Redis, so they survive restarts and any instance of a scaled out deployment can report them.
+// Commands are sent one at a time over a single connection, which is opened again after it breaks.
+type RedisJobStore struct {
+	server redisServer // Server the jobs are kept on
+
+	mu     sync.Mutex    // Guards the connection, one command at a time
+	conn   net.Conn      // Connection to the server, nil until the next command opens it
+	reader *bufio.Reader // Reader of the connection
+}
+
+// RedisJobs creates a new RedisJobStore instance; it connects with the first command
+// Parameters:
+// - address: redis://[[user]:password@]host[:port][/database] of the Redis server
+// Returns:
+// - The store, and ErrInvalidRedis if the address is not a Redis URL
+func RedisJobs(address string) (*RedisJobStore, error) {
+	server, ok := parseRedisUrl(address)
+	if !ok {
+		return nil, ErrInvalidRedis
+	}
+
+	return &RedisJobStore{server: server}, nil
+}
+
+// SaveJob stores the state of a job and its result, expiring it after the given time
+// Parameters:
+// - ctx: the context bounding the operation
+// - job: the job
+// - result: the result of the job, nil until it is done
+// - ttl: how long the job is kept
+// Returns:
+// - error: an error if the server cannot be reached or refuses the job
+func (r *RedisJobStore) SaveJob(ctx context.Context, job Job, result []byte, ttl time.Duration) error {
+	data, err := json.Marshal(redisJob{Job: job, OwnerId: job.OwnerId.Hex(), Result: result})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.do(ctx, "SET", redisKeyPrefix+job.Id, string(data), "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	return err
+}
+
+// GetJob returns a job and its result
+// Parameters:
+// - ctx: the context bounding the operation
+// - id: the ID of the job
+// Returns:
+// - *Job: the job, nil if it does not exist or expired
+// - []byte: its result, nil until it is done
+// - error: an error if the server cannot be reached or the job cannot be read
+func (r *RedisJobStore) GetJob(ctx context.Context, id string) (*Job, []byte, error) {
+	data, err := r.do(ctx, "GET", redisKeyPrefix+id)
+	if err != nil || data == nil {
+		return nil, nil, err
+	}
+
+	var stored redisJob
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, nil, err
+	}
+
+	stored.Job.OwnerId, _ = primitive.ObjectIDFromHex(stored.OwnerId)
+	return &stored.Job, stored.Result, nil
+}
+
+// do sends a command and reads its reply, connecting first if there is no connection
+// Parameters:
+// - ctx: the context bounding the command
+// - args: the command and its arguments
+// Returns:
+// - []byte: the reply, see readRedisReply
+// - error: the error the server replied with, or an error if the server cannot be reached
+func (r *RedisJobStore) do(ctx context.Context, args ...string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		if err := r.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	deadline := time.Now().Add(redisTimeout)
+	if until, ok := ctx.Deadline(); ok && until.Before(deadline) {
+		deadline = until
+	}
+
+	r.conn.SetDeadline(deadline)
+	reply, err := r.roundTrip(args...)
+
+	// Errors the server replied with leave the connection in a known state, any other breaks it
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		r.conn.Close()
+		r.conn = nil
+	}
+
+	return reply, err
+}
+
+// roundTrip writes a command and reads its reply; the caller holds the lock
+// Parameters:
+// - args: the command and its arguments
+// Returns:
+// - []byte: the reply, see readRedisReply
+// - error: the error the server replied with, or an error if the command cannot be sent
+func (r *RedisJobStore) roundTrip(args ...string) ([]byte, error) {
+	if _, err := r.conn.Write(redisCommand(args...)); err != nil {
+		return nil, err
+	}
+
+	return readRedisReply(r.reader)
+}
+
+// connect opens the connection, authenticates and selects the database; the caller holds the lock
+// Parameters:
+// - ctx: the context bounding the connection
+// Returns:
+// - error: an error if the server cannot be reached or refuses the credentials
+func (r *RedisJobStore) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: redisTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.server.address)
+	if err != nil {
+		return err
+	}
+
+	r.conn = conn
+	r.reader = bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(redisTimeout))
+
+	setup := [][]string{}
+	if r.server.password != "" {
+		if r.server.user != "" {
+			setup = append(setup, []string{"AUTH", r.server.user, r.server.password})
+		} else {
+			setup = append(setup, []string{"AUTH", r.server.password})
+		}
+	}
+	if r.server.database != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.server.database)})
+	}
+
+	for _, command := range setup {
+		if _, err := r.roundTrip(command...); err != nil {
+			conn.Close()
+			r.conn = nil
+			return err
+		}
+	}
+
+	return nil
+}
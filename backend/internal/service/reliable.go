@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bytes"
+
+	"github.com/google/uuid"
+)
+
+// maxResendPackets is the number of sequenced packets kept per connection for resending
+const maxResendPackets = 32
+
+// ResendPacket asks the server to send the sequenced packets after the last one the client received again
+type ResendPacket struct {
+	From uint32 `json:"from"` // Sequence number of the last packet received in order
+}
+
+// sequenced is implemented by the packets that change the screen of a client. They carry a sequence number
+// so clients notice when one is missing and can ask for it with a ResendPacket.
+type sequenced interface {
+	withSeq(seq uint32) any
+}
+
+func (p QuestionShowPacket) withSeq(seq uint32) any    { p.Seq = seq; return p }
+func (p PlayerQuestionPacket) withSeq(seq uint32) any  { p.Seq = seq; return p }
+func (p ChangeGameStatePacket) withSeq(seq uint32) any { p.Seq = seq; return p }
+func (p PlayerRevealPacket) withSeq(seq uint32) any    { p.Seq = seq; return p }
+func (p GameEndPacket) withSeq(seq uint32) any         { p.Seq = seq; return p }
+
+// outbox keeps the sequenced packets recently sent on a connection, and numbers the envelopes of all its packets
+type outbox struct {
+	last uint32   // Sequence number of the last packet sent, 0 before the first
+	sent [][]byte // Encoded packets, the last one has sequence number last
+
+	game    uuid.UUID // ID of the game the connection takes part in, uuid.Nil until it joins or hosts one
+	packets uint64    // Packets sent on the connection so far, see encodedPacket
+}
+
+// outboxOf returns the outbox of a connection, creating it for the first packet; outboxesMu must be held
+// Parameters:
+// - connection: the WebSocket connection
+func (c *NetService) outboxOf(connection Connection) *outbox {
+	box, ok := c.outboxes[connection]
+	if !ok {
+		box = &outbox{}
+		c.outboxes[connection] = box
+	}
+
+	return box
+}
+
+// sendSequenced numbers a packet, keeps it for resending and sends it.
+// Parameters:
+// - connection: the WebSocket connection to send the packet to.
+// - packet: the packet to send.
+// Returns:
+// - error: any error encountered during sending, or nil if successful.
+func (c *NetService) sendSequenced(connection Connection, packet sequenced) error {
+	c.outboxesMu.Lock()
+	box := c.outboxOf(connection)
+	encoded, err := c.encodePacket(packet.withSeq(box.last + 1))
+	if err != nil {
+		c.outboxesMu.Unlock()
+		return err
+	}
+
+	box.last++
+	box.packets++
+	buf := encoded.envelope(box.game, box.packets)
+	data := bytes.Clone(buf.Bytes())
+	releasePacket(buf)
+	encoded.release()
+
+	box.sent = append(box.sent, data)
+	if len(box.sent) > maxResendPackets {
+		box.sent = box.sent[len(box.sent)-maxResendPackets:]
+	}
+	c.outboxesMu.Unlock()
+
+	return c.writePacket(connection, data)
+}
+
+// lastSequence returns the sequence number of the last sequenced packet sent on a connection
+// Parameters:
+// - connection: the WebSocket connection
+// Returns:
+// - uint32: the sequence number, 0 if none was sent
+func (c *NetService) lastSequence(connection Connection) uint32 {
+	c.outboxesMu.Lock()
+	defer c.outboxesMu.Unlock()
+
+	if box, ok := c.outboxes[connection]; ok {
+		return box.last
+	}
+
+	return 0
+}
+
+// OnResend sends the sequenced packets a client missed again, in order.
+// Packets too old to be kept are lost; the client catches up with the next one.
+// Parameters:
+// - connection: the WebSocket connection that asked for the packets.
+// - from: the sequence number of the last packet the client received in order.
+func (c *NetService) OnResend(connection Connection, from uint32) {
+	c.outboxesMu.Lock()
+	missing := [][]byte{}
+	if box, ok := c.outboxes[connection]; ok && from < box.last {
+		first := box.last - uint32(len(box.sent)) + 1
+		for i, bytes := range box.sent {
+			if first+uint32(i) > from {
+				missing = append(missing, bytes)
+			}
+		}
+	}
+	c.outboxesMu.Unlock()
+
+	for _, bytes := range missing {
+		c.writePacket(connection, bytes)
+	}
+}
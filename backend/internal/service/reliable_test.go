@@ -0,0 +1,51 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"quiz.com/quiz/internal/config"
+)
+
+// changeGameStatePacketId is the packet ID of ChangeGameStatePacket
+const changeGameStatePacketId = 3
+
+func TestResendMissedPackets(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	connection := &fakeConnection{}
+	for _, state := range []GameState{LobbyState, PlayState, RevealState} {
+		c.SendPacket(connection, ChangeGameStatePacket{State: state})
+	}
+	c.SendPacket(connection, PingPacket{ServerTime: 1})
+
+	var ping PingPacket
+	if err := json.Unmarshal(packetData(connection.messages[3]), &ping); err != nil || ping.Seq != 3 {
+		t.Fatalf("expected the ping to carry the last sequence number 3, got %+v", ping)
+	}
+
+	// The client got the first state change only
+	connection.messages = nil
+	c.OnResend(connection, 1)
+	if len(connection.messages) != 2 {
+		t.Fatalf("expected two packets resent, got %v", connection.packetIds())
+	}
+	for i, message := range connection.messages {
+		var packet ChangeGameStatePacket
+		if err := json.Unmarshal(packetData(message), &packet); err != nil || message[0] != changeGameStatePacketId {
+			t.Fatalf("unexpected packet %v", message)
+		}
+		if packet.Seq != uint32(i+2) || packet.State != []GameState{PlayState, RevealState}[i] {
+			t.Errorf("resent packet %d out of order: %+v", i, packet)
+		}
+	}
+
+	// Packets too old to be kept are not resent
+	for i := 0; i < maxResendPackets; i++ {
+		c.SendPacket(connection, ChangeGameStatePacket{State: PlayState})
+	}
+	connection.messages = nil
+	c.OnResend(connection, 0)
+	if len(connection.messages) != maxResendPackets {
+		t.Errorf("expected the last %d packets resent, got %d", maxResendPackets, len(connection.messages))
+	}
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// PlayerReport summarizes a single player's performance in a game
+type PlayerReport struct {
+	UserId      primitive.ObjectID  `json:"-"`           // ID of the authenticated user, zero for anonymous players
+	Name        string              `json:"name"`        // Player's name
+	Points      int                 `json:"points"`      // Total points scored
+	Answered    int                 `json:"answered"`    // Number of questions answered
+	Correct     int                 `json:"correct"`     // Number of questions answered correctly
+	AverageTime float64             `json:"averageTime"` // Average seconds taken to answer
+	Grade       *entity.PlayerGrade `json:"grade"`       // The player's grade, nil if the quiz is not graded
+	Flags       int                 `json:"flags"`       // Number of times the player left the game window during an exam
+	Hints       int                 `json:"hints"`       // Number of hints the player bought
+	HintPoints  int                 `json:"hintPoints"`  // Points the player paid for hints
+
+	answers []entity.AnswerResult // Final answers in question order, stored with the result for item analysis
+}
+
+// QuestionReport summarizes how players did on a single question
+type QuestionReport struct {
+	Id          string  `json:"id"`          // ID of the question in the quiz
+	Name        string  `json:"name"`        // The text of the question
+	MaxPoints   int     `json:"maxPoints"`   // Most points the question could earn, after caps and normalization
+	Answered    int     `json:"answered"`    // Number of players who answered
+	Correct     int     `json:"correct"`     // Number of players who answered correctly
+	AverageTime float64 `json:"averageTime"` // Average seconds taken to answer
+	Hints       int     `json:"hints"`       // Number of players who bought the hint
+
+	Changed        int `json:"changed"`        // Number of players who changed their answer
+	CorrectToWrong int `json:"correctToWrong"` // Number of players who switched from a correct answer to a wrong one
+	WrongToCorrect int `json:"wrongToCorrect"` // Number of players who switched from a wrong answer to a correct one
+}
+
+// buildReport summarizes the game for the host
+// Returns:
+// - GameReportPacket: the per-player and per-question results
+func (g *Game) buildReport() GameReportPacket {
+	players := []PlayerReport{}
+	for _, player := range g.Players {
+		report := PlayerReport{
+			UserId:      player.UserId,
+			Name:        player.Name,
+			Points:      player.quizPoints(),
+			Answered:    len(player.Answers),
+			AverageTime: player.AverageAnswerTime(),
+			Flags:       player.focusLosses,
+			Hints:       len(player.Hints),
+			HintPoints:  player.hintPoints(),
+		}
+
+		for _, answer := range player.Answers {
+			if answer.Correct {
+				report.Correct++
+			}
+		}
+
+		for i, question := range g.Quiz.Questions {
+			if answer, ok := player.Answers[i]; ok {
+				report.answers = append(report.answers, entity.AnswerResult{
+					QuestionId: question.Id,
+					Choice:     answer.Choice,
+					Correct:    answer.Correct,
+					Confidence: string(answer.Confidence),
+				})
+			}
+		}
+
+		players = append(players, report)
+	}
+
+	// Equal points go to the player with more correct answers, as with accuracy scoring
+	sort.SliceStable(players, func(i, j int) bool {
+		if players[i].Points != players[j].Points {
+			return players[i].Points > players[j].Points
+		}
+
+		return players[i].Correct > players[j].Correct
+	})
+
+	questions := []QuestionReport{}
+	maxPoints := 0
+	for i, question := range g.Quiz.Questions {
+		report := QuestionReport{
+			Id:        question.Id,
+			Name:      question.Name,
+			MaxPoints: g.getMaxPoints(i),
+		}
+		maxPoints += report.MaxPoints
+
+		var total time.Duration
+		for _, player := range g.Players {
+			if _, bought := player.Hints[i]; bought {
+				report.Hints++
+			}
+
+			answer, ok := player.Answers[i]
+			if !ok {
+				continue
+			}
+
+			report.Answered++
+			total += answer.Elapsed
+			if answer.Correct {
+				report.Correct++
+			}
+
+			// Compare the first answer with the final one, switching back and forth is no change
+			if len(answer.History) == 0 || answer.History[0] == answer.Choice {
+				continue
+			}
+
+			report.Changed++
+			switch firstCorrect := isCorrectChoiceOf(question, answer.History[0]); {
+			case firstCorrect && !answer.Correct:
+				report.CorrectToWrong++
+			case !firstCorrect && answer.Correct:
+				report.WrongToCorrect++
+			}
+		}
+
+		if report.Answered > 0 {
+			report.AverageTime = roundSeconds(total / time.Duration(report.Answered))
+		}
+
+		questions = append(questions, report)
+	}
+
+	// Grades need the most points a player could earn
+	for i := range players {
+		players[i].Grade = gradePoints(g.Quiz.Grades, players[i].Points, maxPoints)
+	}
+
+	return GameReportPacket{
+		Players:     players,
+		Questions:   questions,
+		MaxPoints:   maxPoints,
+		HostActions: g.getHostActions(),
+	}
+}
+
+// buildResult converts the final report and awards into a result to be stored
+// Parameters:
+// - report: the final report of the game
+// - awards: the awards handed out at the end of the game
+// Returns:
+// - entity.GameResult: the result entity, without an ID
+func (g *Game) buildResult(report GameReportPacket, awards []entity.GameAward) entity.GameResult {
+	players := []entity.PlayerResult{}
+	for _, player := range report.Players {
+		players = append(players, entity.PlayerResult{
+			UserId:      player.UserId,
+			Name:        player.Name,
+			Points:      player.Points,
+			Answered:    player.Answered,
+			Correct:     player.Correct,
+			AverageTime: player.AverageTime,
+			Answers:     player.answers,
+			Grade:       player.Grade,
+			Hints:       player.Hints,
+			HintPoints:  player.HintPoints,
+		})
+	}
+
+	questions := []entity.QuestionResult{}
+	for _, question := range report.Questions {
+		questions = append(questions, entity.QuestionResult{
+			QuestionId: question.Id,
+			Answered:   question.Answered,
+			Correct:    question.Correct,
+			MaxPoints:  question.MaxPoints,
+			Hints:      question.Hints,
+		})
+	}
+
+	result := entity.GameResult{
+		QuizId:    g.Quiz.Id,
+		QuizName:  g.Quiz.Name,
+		Code:      g.Code,
+		EndedAt:   g.clock.Now(),
+		Players:   players,
+		Awards:    awards,
+		Questions: questions,
+		MaxPoints: report.MaxPoints,
+
+		HostActions: report.HostActions,
+		ChallengeId: g.challengeId,
+	}
+
+	if g.tournament != nil {
+		result.TournamentId = g.tournament.Id
+		result.Round = g.round
+	}
+
+	if g.Settings.AnonymizeResults {
+		anonymizeResult(&result)
+	}
+
+	return result
+}
+
+// anonymousName returns the label that replaces a player's name in anonymized results
+// Parameters:
+// - rank: the player's position in the results, starting at 0
+// Returns:
+// - string: the label, e.g. "Player 1"
+func anonymousName(rank int) string {
+	return fmt.Sprintf("Player %d", rank+1)
+}
+
+// anonymizeResult replaces the names of the players in a result with labels by rank.
+// Their accounts are unlinked as well, so the game counts towards nobody's history or rating.
+// Parameters:
+// - result: the result to anonymize
+func anonymizeResult(result *entity.GameResult) {
+	result.Anonymized = true
+
+	labels := map[string]string{}
+	for i := range result.Players {
+		player := &result.Players[i]
+		if _, ok := labels[player.Name]; !ok {
+			labels[player.Name] = anonymousName(i)
+		}
+
+		player.Name = labels[player.Name]
+		player.UserId = primitive.NilObjectID
+	}
+
+	for i := range result.Awards {
+		result.Awards[i].Player = labels[result.Awards[i].Player]
+	}
+
+	// Players the host removed have no rank to be labelled by
+	for i := range result.HostActions {
+		action := &result.HostActions[i]
+		if action.Player == "" {
+			continue
+		}
+
+		if label, ok := labels[action.Player]; ok {
+			action.Player = label
+		} else {
+			action.Player = "Removed player"
+		}
+	}
+}
+
+// anonymizePodium replaces the names on a podium with labels by rank if the host chose anonymized results
+// Parameters:
+// - podium: the top players of the game, best first
+// Returns:
+// - []LeaderboardEntry: the podium to post outside the game
+func (g *Game) anonymizePodium(podium []LeaderboardEntry) []LeaderboardEntry {
+	if !g.Settings.AnonymizeResults {
+		return podium
+	}
+
+	anonymized := []LeaderboardEntry{}
+	for i, entry := range podium {
+		entry.Name = anonymousName(i)
+		anonymized = append(anonymized, entry)
+	}
+
+	return anonymized
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestBuildReport(t *testing.T) {
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	alice := &Player{Name: "alice", Points: 3000}
+	bob := &Player{Name: "bob", Points: 5000}
+	game.Players = []*Player{alice, bob}
+
+	alice.recordAnswer(PlayerAnswer{Question: 0, Correct: true, Elapsed: 2 * time.Second})
+	alice.recordAnswer(PlayerAnswer{Question: 1, Correct: false, Elapsed: 3 * time.Second})
+	bob.recordAnswer(PlayerAnswer{Question: 0, Correct: true, Elapsed: 4200 * time.Millisecond})
+
+	report := game.buildReport()
+
+	if report.Players[0].Name != "bob" || report.Players[1].Name != "alice" {
+		t.Fatalf("players not ordered by points: %+v", report.Players)
+	}
+	if got := report.Players[1]; got.Answered != 2 || got.Correct != 1 || got.AverageTime != 2.5 {
+		t.Errorf("unexpected report for alice: %+v", got)
+	}
+	if got := report.Players[0].AverageTime; got != 4.2 {
+		t.Errorf("bob averaged %v, want 4.2", got)
+	}
+
+	if got := report.Questions[0]; got.Answered != 2 || got.Correct != 2 || got.AverageTime != 3.1 {
+		t.Errorf("unexpected report for first question: %+v", got)
+	}
+	if got := report.Questions[2]; got.Answered != 0 || got.AverageTime != 0 {
+		t.Errorf("unanswered question should be empty: %+v", got)
+	}
+}
+
+func TestAnonymizedResult(t *testing.T) {
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	game.Settings.AnonymizeResults = true
+	alice := &Player{Name: "alice", Points: 3000, UserId: primitive.NewObjectID()}
+	bob := &Player{Name: "bob", Points: 5000}
+	game.Players = []*Player{alice, bob}
+
+	result := game.buildResult(game.buildReport(), []entity.GameAward{{Type: "fastestAnswer", Player: "alice"}})
+
+	if !result.Anonymized || result.Players[0].Name != "Player 1" || result.Players[1].Name != "Player 2" {
+		t.Errorf("expected players labelled by rank, got %+v", result.Players)
+	}
+	if !result.Players[1].UserId.IsZero() {
+		t.Errorf("expected the account of alice to be unlinked")
+	}
+	if result.Awards[0].Player != "Player 2" {
+		t.Errorf("expected the award to name the label of alice, got %q", result.Awards[0].Player)
+	}
+
+	// Live gameplay keeps the real names
+	if podium := game.getLeaderboard(); podium[0].Name != "bob" || game.anonymizePodium(podium)[0].Name != "Player 1" {
+		t.Errorf("expected only the posted podium to be anonymized, got %+v", podium)
+	}
+}
+
+func TestReportAnswerChanges(t *testing.T) {
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	alice := &Player{Name: "alice"}
+	bob := &Player{Name: "bob"}
+	carol := &Player{Name: "carol"}
+	game.Players = []*Player{alice, bob, carol}
+
+	// On the first question choice 0 is correct
+	alice.recordAnswer(PlayerAnswer{Question: 0, Choice: 1, Correct: false, History: []int{0}})
+	bob.recordAnswer(PlayerAnswer{Question: 0, Choice: 0, Correct: true, History: []int{1}})
+	carol.recordAnswer(PlayerAnswer{Question: 0, Choice: 0, Correct: true})
+
+	got := game.buildReport().Questions[0]
+	if got.Changed != 2 || got.CorrectToWrong != 1 || got.WrongToCorrect != 1 {
+		t.Errorf("unexpected answer changes: %+v", got)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/entity"
+)
+
+// highScoreLimit is the number of entries shown in a high-score table
+const highScoreLimit = 10
+
+// ResultService provides methods for storing and reading the results of finished games and the high-score tables.
+type ResultService struct {
+	resultCollection    *collection.ResultCollection    // Reference to the result collection for database operations
+	highScoreCollection *collection.HighScoreCollection // Reference to the high-score collection for database operations
+	transactor          *collection.Transactor          // Stores a result and its high scores atomically
+}
+
+// Result initializes and returns a new ResultService instance.
+// Parameters:
+// - resultCollection: the collection that interacts with the game results in the database.
+// - highScoreCollection: the collection that interacts with the high scores in the database.
+// - transactor: runs multi-document writes atomically.
+func Result(resultCollection *collection.ResultCollection, highScoreCollection *collection.HighScoreCollection, transactor *collection.Transactor) *ResultService {
+	return &ResultService{
+		resultCollection:    resultCollection,
+		highScoreCollection: highScoreCollection,
+		transactor:          transactor,
+	}
+}
+
+// SaveResult stores the result of a finished game, assigning it a new ID, and adds
+// every player's score to the high-score tables. Both are written in one transaction,
+// so a result is never stored without its scores or the other way round.
+// Anonymized results add no scores, as their "Player N" labels would fill the tables.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - result: the result to store.
+// Returns:
+// - The ID of the stored result and an error if the result or the scores could not be stored.
+func (s ResultService) SaveResult(ctx context.Context, result entity.GameResult) (primitive.ObjectID, error) {
+	result.Id = primitive.NewObjectID()
+
+	scores := []entity.HighScore{}
+	for _, player := range result.Players {
+		if result.Anonymized {
+			continue
+		}
+
+		scores = append(scores, entity.HighScore{
+			Id:         primitive.NewObjectID(),
+			QuizId:     result.QuizId,
+			QuizName:   result.QuizName,
+			Name:       player.Name,
+			Points:     player.Points,
+			AchievedAt: result.EndedAt,
+		})
+	}
+
+	err := s.transactor.Run(ctx, func(ctx context.Context) error {
+		if err := s.resultCollection.InsertResult(ctx, result); err != nil {
+			return err
+		}
+
+		return s.highScoreCollection.InsertHighScores(ctx, scores)
+	})
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	return result.Id, nil
+}
+
+// GetQuizHighScores retrieves the best scores ever reached on a quiz.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quizId: the ObjectID of the quiz.
+// Returns:
+// - The scores ordered from highest to lowest and an error if something goes wrong.
+func (s ResultService) GetQuizHighScores(ctx context.Context, quizId primitive.ObjectID) ([]entity.HighScore, error) {
+	if quizId.IsZero() {
+		return nil, errors.New("quiz id is required")
+	}
+
+	return s.highScoreCollection.GetHighScores(ctx, quizId, highScoreLimit)
+}
+
+// GetGlobalHighScores retrieves the best scores across all quizzes.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// Returns:
+// - The scores ordered from highest to lowest and an error if something goes wrong.
+func (s ResultService) GetGlobalHighScores(ctx context.Context) ([]entity.HighScore, error) {
+	return s.highScoreCollection.GetHighScores(ctx, primitive.NilObjectID, highScoreLimit)
+}
+
+// GetRecord retrieves the best score ever reached on a quiz.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - quizId: the ObjectID of the quiz.
+// Returns:
+// - The record score, or nil if the quiz has never been played, and an error if something goes wrong.
+func (s ResultService) GetRecord(ctx context.Context, quizId primitive.ObjectID) (*entity.HighScore, error) {
+	scores, err := s.highScoreCollection.GetHighScores(ctx, quizId, 1)
+	if err != nil || len(scores) == 0 {
+		return nil, err
+	}
+
+	return &scores[0], nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// ScoringMode selects the ScoringStrategy of a game
+type ScoringMode int
+
+const (
+	StandardScoring ScoringMode = iota // Correct answers earn points, wrong answers earn nothing
+	PenaltyScoring                     // Wrong answers cost a fixed number of points
+	AccuracyScoring                    // Every correct answer earns the same points, ties go to the most correct answers
+)
+
+// Points of the scoring strategies
+const (
+	maxPenalty     = 5000    // Largest number of points a wrong answer may cost
+	accuracyPoints = 1000    // Points of a correct answer with accuracy scoring
+	maxTotalPoints = 1000000 // Largest total a quiz can be normalized to
+)
+
+// ScoringStrategy decides how answers change a player's points
+type ScoringStrategy interface {
+	// Points returns the points an answer is worth, negative for a penalty
+	// Parameters:
+	// - correct: whether the answer was correct
+	// - reward: the points a correct answer earns for its speed and order
+	Points(correct bool, reward int) int
+
+	// Apply adds the points of an answer to a player's total
+	// Parameters:
+	// - total: the player's points before the answer
+	// - points: the points of the answer
+	// Returns:
+	// - int: the player's new total
+	Apply(total int, points int) int
+
+	// Ranks reports whether a player ranks above another on the leaderboard
+	// Parameters:
+	// - a: the first player
+	// - b: the second player
+	// Returns:
+	// - bool: true if a ranks above b
+	Ranks(a *Player, b *Player) bool
+}
+
+// standardScoring rewards correct answers and ignores wrong ones
+type standardScoring struct{}
+
+// Points returns the reward of a correct answer and nothing for a wrong one
+func (standardScoring) Points(correct bool, reward int) int {
+	if !correct {
+		return 0
+	}
+
+	return reward
+}
+
+// Apply adds the points to the total
+func (standardScoring) Apply(total int, points int) int {
+	return total + points
+}
+
+// Ranks orders players by points
+func (standardScoring) Ranks(a *Player, b *Player) bool {
+	return a.Points > b.Points
+}
+
+// penaltyScoring subtracts a fixed penalty for wrong answers
+type penaltyScoring struct {
+	penalty       int  // Points a wrong answer costs
+	allowNegative bool // Whether totals may drop below zero
+}
+
+// Points returns the reward of a correct answer and the penalty for a wrong one
+func (s penaltyScoring) Points(correct bool, reward int) int {
+	if !correct {
+		return -s.penalty
+	}
+
+	return reward
+}
+
+// Apply adds the points to the total, which stays at or above zero unless negatives are allowed
+func (s penaltyScoring) Apply(total int, points int) int {
+	if s.allowNegative {
+		return total + points
+	}
+
+	return max(0, total+points)
+}
+
+// Ranks orders players by points
+func (s penaltyScoring) Ranks(a *Player, b *Player) bool {
+	return a.Points > b.Points
+}
+
+// accuracyScoring ignores how fast players answer
+type accuracyScoring struct{}
+
+// Points returns the same points for every correct answer
+func (accuracyScoring) Points(correct bool, reward int) int {
+	if !correct {
+		return 0
+	}
+
+	return accuracyPoints
+}
+
+// Apply adds the points to the total
+func (accuracyScoring) Apply(total int, points int) int {
+	return total + points
+}
+
+// Ranks orders players by points, and players with equal points by their number of correct answers
+func (accuracyScoring) Ranks(a *Player, b *Player) bool {
+	if a.Points != b.Points {
+		return a.Points > b.Points
+	}
+
+	return a.correctAnswers() > b.correctAnswers()
+}
+
+// scaledScoring scales the points of another strategy, to cap a question or normalize a quiz to a total
+type scaledScoring struct {
+	ScoringStrategy
+	factor float64 // Factor the points of every answer are multiplied by
+}
+
+// Points returns the points of the scaled strategy multiplied by the factor, penalties included
+func (s scaledScoring) Points(correct bool, reward int) int {
+	return int(math.Round(float64(s.ScoringStrategy.Points(correct, reward)) * s.factor))
+}
+
+// pointsFactor returns what the points of a question are multiplied by. A question is worth its cap
+// if it has one below the usual maximum; with a total set for the quiz, every question's worth is
+// then scaled so that they add up to the total.
+// Parameters:
+// - scoring: the unscaled strategy
+// - quiz: the quiz being played
+// - index: the index of the question
+// Returns:
+// - float64: the factor, 1 to keep the usual points
+func pointsFactor(scoring ScoringStrategy, quiz entity.Quiz, index int) float64 {
+	usual := func(question entity.QuizQuestion) float64 {
+		return float64(scoring.Points(true, bestReward(question)))
+	}
+	worth := func(question entity.QuizQuestion) float64 {
+		if question.MaxPoints > 0 {
+			return min(usual(question), float64(question.MaxPoints))
+		}
+
+		return usual(question)
+	}
+
+	question := quiz.Questions[index]
+	if usual(question) <= 0 {
+		return 1
+	}
+
+	target := worth(question)
+	if quiz.TotalPoints > 0 {
+		total := 0.0
+		for _, other := range quiz.Questions {
+			total += worth(other)
+		}
+		target *= float64(quiz.TotalPoints) / total
+	}
+
+	return target / usual(question)
+}
+
+// correctAnswers returns the number of questions the player answered correctly
+func (p *Player) correctAnswers() int {
+	correct := 0
+	for _, answer := range p.Answers {
+		if answer.Correct {
+			correct++
+		}
+	}
+
+	return correct
+}
+
+// sortPlayers orders players by their rank under the game's scoring strategy, best first
+// Parameters:
+// - players: the players to sort in place
+func (g *Game) sortPlayers(players []*Player) {
+	scoring := g.scoring()
+	sort.SliceStable(players, func(i, j int) bool {
+		return g.ranks(players[i], players[j], scoring)
+	})
+}
+
+// ranks reports whether a player ranks above another: by how long they stayed in an elimination game,
+// by round wins in a match, then by the scoring strategy and finally by the tie-breaker
+// Parameters:
+// - a: the first player
+// - b: the second player
+// - scoring: the scoring strategy of the game
+// Returns:
+// - bool: true if a ranks above b
+func (g *Game) ranks(a *Player, b *Player, scoring ScoringStrategy) bool {
+	if above, differ := ranksByElimination(a, b); differ {
+		return above
+	}
+
+	if g.isMatch() && a.RoundWins != b.RoundWins {
+		return a.RoundWins > b.RoundWins
+	}
+
+	return scoring.Ranks(a, b) || g.wonTieBreak(a, b, scoring)
+}
+
+// scoring returns the scoring strategy chosen in the game settings, scaled for the current question
+func (g *Game) scoring() ScoringStrategy {
+	return g.questionScoring(g.CurrentQuestion)
+}
+
+// questionScoring returns the scoring strategy of a question, scaled by its cap and the quiz's total points
+// Parameters:
+// - index: the index of the question, out of range for the unscaled strategy
+// Returns:
+// - ScoringStrategy: the strategy
+func (g *Game) questionScoring(index int) ScoringStrategy {
+	scoring := g.unscaledScoring()
+	if index < 0 || index >= len(g.Quiz.Questions) {
+		return scoring
+	}
+
+	if factor := pointsFactor(scoring, g.Quiz, index); factor != 1 {
+		return scaledScoring{ScoringStrategy: scoring, factor: factor}
+	}
+
+	return scoring
+}
+
+// getMaxPoints returns the most points a question can earn, by a correct answer given first and at once
+// Parameters:
+// - index: the index of the question
+// Returns:
+// - int: the points
+func (g *Game) getMaxPoints(index int) int {
+	scoring := g.unscaledScoring()
+	points := float64(scoring.Points(true, bestReward(g.Quiz.Questions[index])))
+
+	return int(math.Round(points * pointsFactor(scoring, g.Quiz, index)))
+}
+
+// unscaledScoring returns the scoring strategy chosen in the game settings, without caps or normalization
+func (g *Game) unscaledScoring() ScoringStrategy {
+	switch g.Settings.Scoring {
+	case PenaltyScoring:
+		return penaltyScoring{
+			penalty:       g.Settings.Penalty,
+			allowNegative: g.Settings.AllowNegative,
+		}
+	case AccuracyScoring:
+		return accuracyScoring{}
+	}
+
+	return standardScoring{}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestPenaltyScoring(t *testing.T) {
+	tests := []struct {
+		allowNegative bool
+		want          int
+	}{
+		{false, 0},
+		{true, -300},
+	}
+
+	for _, test := range tests {
+		c := Net(NetOptions{}, config.Config{})
+		game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+		game.OnSettings(GameSettings{Scoring: PenaltyScoring, Penalty: 300, AllowNegative: test.allowNegative})
+		game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+		alice := game.Players[0]
+		game.Start()
+
+		game.OnPlayerAnswer(1, alice)
+		if alice.Points != test.want || alice.LastAwardedPoints != test.want {
+			t.Errorf("allowNegative %v: expected %d points, got %d", test.allowNegative, test.want, alice.Points)
+		}
+	}
+}
+
+func TestGameSettingsValidation(t *testing.T) {
+	valid := GameSettingsPacket{Settings: GameSettings{Scoring: PenaltyScoring, Penalty: 500}}
+	if err := valid.validate(); err != nil {
+		t.Errorf("expected a penalty of 500 to be valid, got %v", err)
+	}
+
+	for _, settings := range []GameSettings{
+		{Scoring: PenaltyScoring},
+		{Scoring: PenaltyScoring, Penalty: maxPenalty + 1},
+		{Scoring: 7},
+		{AnswerChange: -1},
+	} {
+		packet := GameSettingsPacket{Settings: settings}
+		if packet.validate() == nil {
+			t.Errorf("expected %+v to be rejected", settings)
+		}
+	}
+}
+
+func TestAccuracyScoring(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnSettings(GameSettings{Scoring: AccuracyScoring})
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	alice, bob := game.Players[0], game.Players[1]
+	game.Start()
+
+	// Bob answers first, but speed does not matter
+	game.OnPlayerAnswer(0, bob)
+	game.OnPlayerAnswer(0, alice)
+	if alice.Points != accuracyPoints || bob.Points != accuracyPoints {
+		t.Fatalf("expected %d points each, got %d and %d", accuracyPoints, alice.Points, bob.Points)
+	}
+
+	// With equal points, more correct answers rank higher
+	bob.Points = 0
+	alice.Points = 0
+	alice.Answers[0].Correct = false
+	if top := game.getTopPlayerIds(); top[0] != bob.Id {
+		t.Errorf("expected bob to rank first on correct answers")
+	}
+}
+
+func TestQuestionPointsCap(t *testing.T) {
+	quiz := fuzzQuiz()
+	quiz.Questions[0].MaxPoints = 1000
+
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(quiz, &fakeConnection{}, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	alice, bob := game.Players[0], game.Players[1]
+	game.Start()
+
+	// The first answer at once earns the cap, later answers keep their share of it
+	game.OnPlayerAnswer(0, alice)
+	game.OnPlayerAnswer(0, bob)
+	if alice.Points != 1000 || bob.Points != 802 {
+		t.Errorf("expected 1000 and 802 points below the cap, got %d and %d", alice.Points, bob.Points)
+	}
+	if report := game.buildReport(); report.Questions[0].MaxPoints != 1000 || report.Questions[1].MaxPoints != 5032 {
+		t.Errorf("expected the cap in the report, got %+v", report.Questions)
+	}
+}
+
+func TestQuizPointsNormalized(t *testing.T) {
+	quiz := fuzzQuiz()
+	quiz.TotalPoints = 100
+	quiz.Questions[2].MaxPoints = accuracyPoints / 2
+
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(quiz, &fakeConnection{}, c)
+	game.OnSettings(GameSettings{Scoring: AccuracyScoring})
+
+	// Every question is worth the same with accuracy scoring, except the one capped to half
+	report := game.buildReport()
+	maxPoints := []int{}
+	for _, question := range report.Questions {
+		maxPoints = append(maxPoints, question.MaxPoints)
+	}
+	if maxPoints[0] != 40 || maxPoints[1] != 40 || maxPoints[2] != 20 || report.MaxPoints != 100 {
+		t.Errorf("expected questions worth 40, 40 and 20 of 100 points, got %v of %d", maxPoints, report.MaxPoints)
+	}
+}
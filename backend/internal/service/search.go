@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Names of the available embedders, selected with the QUIZ_EMBEDDER setting
+const (
+	OpenAIEmbedder = "openai"
+)
+
+// Limits of question search
+const (
+	maxSearchQueryLength  = 200              // Longest query in characters
+	defaultSearchResults  = 20               // Questions returned when the request does not say
+	maxSearchResults      = 50               // Most questions returned for a query
+	embeddingTimeout      = 20 * time.Second // Time allowed for computing the embeddings of a search
+	embeddingBatchSize    = 64               // Texts sent to the embedder in one request
+	embeddingMaxBytes     = 32 << 20         // Largest response read from the embedder, a batch of large vectors
+	maxEmbeddedPerSearch  = 256              // Questions embedded for the first time in a single search, the rest follow in later ones
+	minSemanticSimilarity = 0.3              // Cosine similarity a question needs to the query to be returned
+)
+
+// Errors returned when questions cannot be searched.
+var (
+	ErrInvalidEmbedder = errors.New("invalid embedder")
+	ErrInvalidQuery    = errors.New("invalid search query")
+)
+
+// Embedder computes embeddings, vectors that are close for texts of similar meaning.
+type Embedder interface {
+	// Model names the model the embeddings come from, so vectors of different models are never compared.
+	Model() string
+
+	// Embed computes the embeddings of texts, in the order of the texts.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder creates the embedder selected by the configuration.
+// Parameters:
+// - kind: the embedder, OpenAIEmbedder.
+// - address: the base URL of the embeddings API, e.g. https://api.openai.com.
+// - key: the API key, empty for compatible servers that need none.
+// - model: the embedding model to ask for.
+// Returns:
+// - The embedder, and ErrInvalidEmbedder if the kind is unknown, the address invalid or the model missing.
+func NewEmbedder(kind string, address string, key string, model string) (Embedder, error) {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || model == "" {
+		return nil, ErrInvalidEmbedder
+	}
+
+	switch kind {
+	case OpenAIEmbedder:
+		return &OpenAIEmbeddings{
+			client: &http.Client{Timeout: embeddingTimeout},
+			url:    strings.TrimSuffix(u.String(), "/") + "/v1/embeddings",
+			key:    key,
+			model:  model,
+		}, nil
+	}
+
+	return nil, ErrInvalidEmbedder
+}
+
+// OpenAIEmbeddings computes embeddings with the OpenAI API, or any server offering the same endpoint.
+type OpenAIEmbeddings struct {
+	client *http.Client // Client used to call the API
+	url    string       // URL of the embeddings endpoint
+	key    string       // API key, empty if the server needs none
+	model  string       // Embedding model to ask for
+}
+
+// Model names the embedding model asked for.
+func (e *OpenAIEmbeddings) Model() string {
+	return e.model
+}
+
+// Embed computes the embeddings of texts in a single request.
+// Parameters:
+// - ctx: the context bounding the request.
+// - texts: the texts to embed.
+// Returns:
+// - The embeddings and an error if the API cannot be reached, refuses the request or leaves texts out.
+func (e *OpenAIEmbeddings) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authorization := ""
+	if e.key != "" {
+		authorization = "Bearer " + e.key
+	}
+
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := postApi(ctx, e.client, e.url, "application/json", authorization, body, embeddingMaxBytes, &response); err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedder returned text %d of %d", item.Index, len(texts))
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+	for i, embedding := range embeddings {
+		if len(embedding) == 0 {
+			return nil, fmt.Errorf("embedder left out text %d", i)
+		}
+	}
+
+	return embeddings, nil
+}
+
+// QuestionMatch is a question of the library found by a search
+type QuestionMatch struct {
+	QuizId   primitive.ObjectID  `json:"quizId"`   // ID of the quiz the question belongs to
+	QuizName string              `json:"quizName"` // Name of the quiz the question belongs to
+	Question entity.QuizQuestion `json:"question"` // The question, ready to be copied into another quiz
+	Score    float64             `json:"score"`    // How well the question matches the query, from 0 to 1
+}
+
+// SearchService finds questions across the quizzes of the library by what they mean, or by their words when no
+// embedder is configured.
+type SearchService struct {
+	quizService         *QuizService                    // Service the library is read from
+	embeddingCollection *collection.EmbeddingCollection // Cache of the embeddings of questions
+	embedder            Embedder                        // Embedder of questions and queries, nil to search by keywords
+}
+
+// Search initializes and returns a new SearchService instance.
+// Parameters:
+// - quizService: the service used to read the quizzes of the library.
+// - embeddingCollection: the collection the embeddings of questions are cached in.
+// - embedder: the embedder, nil to search by keywords only.
+func Search(quizService *QuizService, embeddingCollection *collection.EmbeddingCollection, embedder Embedder) *SearchService {
+	return &SearchService{
+		quizService:         quizService,
+		embeddingCollection: embeddingCollection,
+		embedder:            embedder,
+	}
+}
+
+// SearchQuestions finds the questions of the quizzes a user may view that best match a query. Questions are
+// ranked by the similarity of their embedding to the query's; if no embedder is configured or it fails, they are
+// ranked by the share of the query's words they contain instead.
+// Parameters:
+// - ctx: the context bounding the database operations and the embedder.
+// - userId: the ObjectID of the user searching.
+// - query: what the questions are about, e.g. "capital cities europe".
+// - limit: the most questions to return, 0 for the default.
+// Returns:
+// - The matching questions, best first, and ErrInvalidQuery if the query is empty or too long.
+func (s *SearchService) SearchQuestions(ctx context.Context, userId primitive.ObjectID, query string, limit int) ([]QuestionMatch, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, ErrInvalidQuery
+	}
+
+	if limit <= 0 {
+		limit = defaultSearchResults
+	}
+	limit = min(limit, maxSearchResults)
+
+	quizzes, err := s.quizService.GetQuizzes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candidates := searchCandidates(quizzes, userId)
+
+	if s.embedder != nil {
+		matches, err := s.semanticSearch(ctx, query, candidates)
+		if err == nil {
+			return rankMatches(matches, minSemanticSimilarity, limit), nil
+		}
+
+		fmt.Println("semantic search failed, searching by keywords:", err)
+	}
+
+	return rankMatches(keywordSearch(query, candidates), 0, limit), nil
+}
+
+// semanticSearch scores questions by how close their embeddings are to the query's. Embeddings missing from the
+// cache are computed and stored, at most maxEmbeddedPerSearch at a time; questions still without one are left out.
+// Parameters:
+// - ctx: the context bounding the database operations and the embedder
+// - query: the search query
+// - candidates: the questions to score
+// Returns:
+// - []QuestionMatch: the questions with an embedding and their scores
+// - error: an error if the cache cannot be read or the embedder fails
+func (s *SearchService) semanticSearch(ctx context.Context, query string, candidates []QuestionMatch) ([]QuestionMatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, embeddingTimeout)
+	defer cancel()
+
+	model := s.embedder.Model()
+	keys := []string{}
+	texts := map[string]string{}
+	for _, candidate := range candidates {
+		key := embeddingKey(model, candidate.Question.Name)
+		if _, ok := texts[key]; !ok {
+			keys = append(keys, key)
+			texts[key] = candidate.Question.Name
+		}
+	}
+
+	cached, err := s.embeddingCollection.GetEmbeddings(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := map[string][]float32{}
+	for _, embedding := range cached {
+		vectors[embedding.Id] = embedding.Vector
+	}
+
+	missing := []string{}
+	for _, key := range keys {
+		if _, ok := vectors[key]; !ok && len(missing) < maxEmbeddedPerSearch {
+			missing = append(missing, key)
+		}
+	}
+
+	// The query goes along with the first batch
+	pending := append([]string{""}, missing...)
+	queryVector := []float32(nil)
+	computed := []entity.Embedding{}
+	for start := 0; start < len(pending); start += embeddingBatchSize {
+		batch := pending[start:min(start+embeddingBatchSize, len(pending))]
+		inputs := []string{}
+		for _, key := range batch {
+			if key == "" {
+				inputs = append(inputs, query)
+			} else {
+				inputs = append(inputs, texts[key])
+			}
+		}
+
+		embeddings, err := s.embedder.Embed(ctx, inputs)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, key := range batch {
+			if key == "" {
+				queryVector = embeddings[i]
+				continue
+			}
+
+			vectors[key] = embeddings[i]
+			computed = append(computed, entity.Embedding{Id: key, Model: model, Vector: embeddings[i], CreatedAt: time.Now()})
+		}
+	}
+
+	// A failed write only means the questions are embedded again next time
+	if err := s.embeddingCollection.SaveEmbeddings(ctx, computed); err != nil {
+		fmt.Println("failed to cache embeddings:", err)
+	}
+
+	matches := []QuestionMatch{}
+	for _, candidate := range candidates {
+		if vector, ok := vectors[embeddingKey(model, candidate.Question.Name)]; ok {
+			candidate.Score = cosineSimilarity(queryVector, vector)
+			matches = append(matches, candidate)
+		}
+	}
+
+	return matches, nil
+}
+
+// searchCandidates lists the questions a user may find, those of the quizzes they may view
+// Parameters:
+// - quizzes: the quizzes of the library
+// - userId: the ObjectID of the user searching
+// Returns:
+// - []QuestionMatch: the questions with a text, not scored yet
+func searchCandidates(quizzes []entity.Quiz, userId primitive.ObjectID) []QuestionMatch {
+	candidates := []QuestionMatch{}
+	for _, quiz := range quizzes {
+		if !CanAccessQuiz(quiz, userId, "", entity.ViewPermission) {
+			continue
+		}
+
+		for _, question := range quiz.Questions {
+			if strings.TrimSpace(question.Name) == "" {
+				continue
+			}
+
+			candidates = append(candidates, QuestionMatch{
+				QuizId:   quiz.Id,
+				QuizName: quiz.Name,
+				Question: question,
+			})
+		}
+	}
+
+	return candidates
+}
+
+// keywordSearch scores questions by the share of the query's words they contain, ignoring case and punctuation
+// Parameters:
+// - query: the search query
+// - candidates: the questions to score
+// Returns:
+// - []QuestionMatch: the questions and their scores
+func keywordSearch(query string, candidates []QuestionMatch) []QuestionMatch {
+	words := questionWords(query)
+	matches := []QuestionMatch{}
+	for _, candidate := range candidates {
+		if len(words) == 0 {
+			break
+		}
+
+		found := 0
+		have := questionWords(candidate.Question.Name)
+		for word := range words {
+			if have[word] {
+				found++
+			}
+		}
+
+		candidate.Score = float64(found) / float64(len(words))
+		matches = append(matches, candidate)
+	}
+
+	return matches
+}
+
+// rankMatches keeps the best scored questions
+// Parameters:
+// - matches: the scored questions
+// - minScore: the score a question must reach, questions scoring 0 or less are always dropped
+// - limit: the most questions to keep
+// Returns:
+// - []QuestionMatch: at most limit questions, best first, ties in library order
+func rankMatches(matches []QuestionMatch, minScore float64, limit int) []QuestionMatch {
+	matches = slices.DeleteFunc(matches, func(match QuestionMatch) bool {
+		return match.Score <= 0 || match.Score < minScore
+	})
+	slices.SortStableFunc(matches, func(a, b QuestionMatch) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// embeddingKey is the key the embedding of a text is cached under
+// Parameters:
+// - model: the embedding model
+// - text: the text
+// Returns:
+// - string: the hex encoded SHA-256 of both
+func embeddingKey(model string, text string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + strings.TrimSpace(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// cosineSimilarity compares two embeddings by the angle between them
+// Parameters:
+// - a, b: the embeddings
+// Returns:
+// - float64: 1 for the same direction, 0 for unrelated or mismatched vectors
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
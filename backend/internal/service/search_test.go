@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestKeywordSearch(t *testing.T) {
+	library := fuzzQuiz()
+	library.Id = primitive.NewObjectID()
+	library.Questions = append(library.Questions, entity.QuizQuestion{Id: "q4", Name: "What is the capital of France?"}, entity.QuizQuestion{Id: "q5", Name: " "})
+	private := entity.Quiz{Id: primitive.NewObjectID(), OwnerId: primitive.NewObjectID(), Questions: []entity.QuizQuestion{{Id: "secret", Name: "Capital of Spain?"}}}
+
+	candidates := searchCandidates([]entity.Quiz{library, private}, primitive.NewObjectID())
+	if len(candidates) != 4 {
+		t.Fatalf("expected the questions of the open library with a text, got %d", len(candidates))
+	}
+
+	matches := rankMatches(keywordSearch("Capital cities of Europe", candidates), 0, 10)
+	if len(matches) != 1 || matches[0].Question.Id != "q4" || matches[0].QuizId != library.Id || matches[0].Score != 0.5 {
+		t.Errorf("expected the capital question with half the words, got %+v", matches)
+	}
+}
+
+func TestRankMatches(t *testing.T) {
+	matches := rankMatches([]QuestionMatch{
+		{Question: entity.QuizQuestion{Id: "low"}, Score: 0.2},
+		{Question: entity.QuizQuestion{Id: "high"}, Score: 0.9},
+		{Question: entity.QuizQuestion{Id: "first"}, Score: 0.5},
+		{Question: entity.QuizQuestion{Id: "second"}, Score: 0.5},
+	}, 0.3, 2)
+
+	if len(matches) != 2 || matches[0].Question.Id != "high" || matches[1].Question.Id != "first" {
+		t.Errorf("expected the best two above the minimum, ties in order, got %+v", matches)
+	}
+}
+
+func TestOpenAIEmbeddings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer secret" || request.Model != "small" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Answered out of order, as the API allows
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+			{"index": 1, "embedding": []float32{0, 1}},
+			{"index": 0, "embedding": []float32{1, 0}},
+		}})
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(OpenAIEmbedder, server.URL+"/", "secret", "small")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	embeddings, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	if err != nil || len(embeddings) != 2 || embeddings[0][0] != 1 || embeddings[1][1] != 1 {
+		t.Fatalf("expected the embeddings in the order of the texts, got %v, %v", embeddings, err)
+	}
+
+	if _, err := embedder.Embed(context.Background(), []string{"a", "b", "c"}); err == nil {
+		t.Errorf("expected an error for a text left out")
+	}
+
+	if _, err := NewEmbedder("word2vec", server.URL, "", "small"); err != ErrInvalidEmbedder {
+		t.Errorf("expected an unknown embedder to be refused, got %v", err)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if similarity := cosineSimilarity([]float32{1, 1}, []float32{2, 2}); similarity < 0.999 {
+		t.Errorf("expected parallel vectors to be similar, got %f", similarity)
+	}
+	if similarity := cosineSimilarity([]float32{1, 0}, []float32{0, 1}); similarity != 0 {
+		t.Errorf("expected orthogonal vectors to be unrelated, got %f", similarity)
+	}
+	if similarity := cosineSimilarity([]float32{1}, []float32{1, 0}); similarity != 0 {
+		t.Errorf("expected vectors of different models to be unrelated, got %f", similarity)
+	}
+}
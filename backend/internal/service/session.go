@@ -0,0 +1,149 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// sessionTokenBytes is the number of random bytes in a session token
+const sessionTokenBytes = 16
+
+// SessionPacket gives a player the token to resume their place in the game after losing the connection,
+// by connecting to /ws?session=<token>. A new token is sent after each resume.
+type SessionPacket struct {
+	Token string `json:"token"` // Secret token of the player's session
+}
+
+// departedPlayer is a player who lost their connection and may still resume
+type departedPlayer struct {
+	player *Player   // The player, with their points and answers
+	at     time.Time // When the player lost their connection
+}
+
+// newSessionToken generates the secret token of a player's session.
+// Returns:
+// - The hex encoded token and an error if no randomness is available.
+func newSessionToken() (string, error) {
+	bytes := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// sendSession gives a player a new session token, unless resuming is disabled
+// Parameters:
+// - player: the player
+func (g *Game) sendSession(player *Player) {
+	if g.netService.config.SessionTtl <= 0 {
+		return
+	}
+
+	token, err := newSessionToken()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	player.session = token
+	g.netService.SendPacket(player.Connection, SessionPacket{
+		Token: token,
+	})
+}
+
+// keepSession remembers a player who lost their connection so they can resume until the session expires
+// Parameters:
+// - player: the player who disconnected
+func (g *Game) keepSession(player *Player) {
+	if player.session == "" || g.Ended {
+		return
+	}
+
+	g.expireSessions()
+	if g.departed == nil {
+		g.departed = map[string]departedPlayer{}
+	}
+	g.departed[player.session] = departedPlayer{player: player, at: g.clock.Now()}
+}
+
+// expireSessions forgets the players whose sessions ran out, telling the host they are gone for good
+func (g *Game) expireSessions() {
+	for token, departed := range g.departed {
+		if g.clock.Now().Sub(departed.at) > g.netService.config.SessionTtl {
+			delete(g.departed, token)
+			g.sendToHost(PlayerDisconnectPacket{
+				PlayerId: departed.player.Id,
+			})
+		}
+	}
+}
+
+// OnPlayerResume puts a player who lost their connection back into the game on a new connection,
+// with their points and answers, and brings their screen up to date
+// Parameters:
+// - token: the session token the player connected with
+// - connection: the new connection of the player
+// Returns:
+// - bool: false if the game has no such session, or it expired or the game ended
+func (g *Game) OnPlayerResume(token string, connection Connection) bool {
+	g.expireSessions()
+	departed, ok := g.departed[token]
+	if !ok || g.Ended {
+		return false
+	}
+	delete(g.departed, token)
+
+	player := departed.player
+	fmt.Println(player.Name, "resumed their session")
+	player.Connection = connection
+	g.Players = append(g.Players, player)
+	g.netService.bindConnection(connection, g.Id)
+
+	g.netService.SendPacket(connection, GameSettingsPacket{
+		Settings: g.Settings,
+	})
+	g.netService.SendPacket(connection, g.newGameInfoPacket())
+	g.sendSession(player)
+	g.OnPlayerResync(player)
+
+	if g.State == LobbyState {
+		g.netService.SendPacket(connection, g.getLobbyVotes())
+	}
+	g.sendPing(player)
+
+	g.sendToHost(PlayerJoinPacket{
+		Player: *player,
+	})
+	g.updateViewers()
+
+	return true
+}
+
+// ResumeSession reattaches a player connecting with a session token to their game.
+// Connections whose session is unknown or expired are closed with CloseSessionExpired, so the client asks to join again.
+// Parameters:
+// - con: the new connection of the player.
+// - token: the session token from the /ws URL.
+// Returns:
+// - bool: true if the player was put back into their game.
+func (c *NetService) ResumeSession(con Connection, token string) bool {
+	c.gamesMu.Lock()
+	games := append([]*Game{}, c.games...)
+	c.gamesMu.Unlock()
+
+	for _, game := range games {
+		resumed := false
+		game.run("resume", func() {
+			resumed = game.OnPlayerResume(token, con)
+		})
+		if resumed {
+			return true
+		}
+	}
+
+	c.CloseConnection(con, CloseSessionExpired)
+	return false
+}
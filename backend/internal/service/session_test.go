@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestResumeSession(t *testing.T) {
+	clock := newFakeClock()
+	c := Net(NetOptions{}, config.Config{SessionTtl: time.Minute})
+	c.clock = clock
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	c.addGame(game)
+
+	first := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", first)
+	player := game.Players[0]
+	player.Points = 300
+	token := player.session
+	if token == "" {
+		t.Fatal("expected the player to be given a session token")
+	}
+
+	c.OnDisconnect(first)
+	if len(game.Players) != 0 {
+		t.Fatalf("expected the player to leave the game")
+	}
+
+	second := &closingConnection{}
+	if !c.ResumeSession(second, token) {
+		t.Fatal("expected the player to resume their session")
+	}
+	if len(game.Players) != 1 || game.Players[0].Points != 300 || game.Players[0].Connection != second {
+		t.Fatalf("expected the player back with their points on the new connection")
+	}
+	if player.session == token {
+		t.Errorf("expected a new session token after resuming")
+	}
+
+	// Tokens are used once
+	if c.ResumeSession(&closingConnection{}, token) {
+		t.Errorf("expected a used token to be refused")
+	}
+
+	c.OnDisconnect(second)
+	clock.Advance(2 * time.Minute)
+	expired := &closingConnection{}
+	if c.ResumeSession(expired, player.session) || expired.closeCode() != CloseSessionExpired {
+		t.Errorf("expected an expired session to be closed with CloseSessionExpired, got %d", expired.closeCode())
+	}
+}
+
+func TestKickedPlayerCannotResume(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{SessionTtl: time.Minute})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	c.addGame(game)
+
+	kicked := &closingConnection{}
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", kicked)
+	token := game.Players[0].session
+	game.OnKickPlayer(&KickPlayerPacket{PlayerId: game.Players[0].Id})
+	c.OnDisconnect(kicked)
+
+	if c.ResumeSession(&closingConnection{}, token) {
+		t.Errorf("expected a kicked player not to resume")
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+// AnswerChangeMode controls whether players may change their answer to a question
+type AnswerChangeMode int
+
+const (
+	NoAnswerChange  AnswerChangeMode = iota // The first answer is final
+	OneAnswerChange                         // One change per question, the final answer scores as usual
+	SecondGuess                             // One change per question, a changed answer scores half points
+)
+
+// maxAnswerChanges is the number of times a player may change their answer to a question
+const maxAnswerChanges = 1
+
+// GameSettings are the rules the host chose for a game
+type GameSettings struct {
+	AnswerChange   AnswerChangeMode `json:"answerChange"`   // Whether and how players may change their answer
+	Scoring        ScoringMode      `json:"scoring"`        // How answers are scored
+	Penalty        int              `json:"penalty"`        // Points a wrong answer costs with penalty scoring
+	ConfidenceMode bool             `json:"confidenceMode"` // Whether players say how sure they are with each answer, weighing its points
+	AllowNegative  bool             `json:"allowNegative"`  // Whether penalties, confident wrong answers and hints may take a player below zero points
+	HintCost       int              `json:"hintCost"`       // Points a player pays for the hint of a question, 0 for free hints
+
+	IdleLimit  int  `json:"idleLimit"`  // Consecutive missed questions after which a player is idle, 0 to never mark players
+	RemoveIdle bool `json:"removeIdle"` // Whether idle players are removed from the game rather than marked
+
+	AnonymizeResults bool `json:"anonymizeResults"` // Whether stored results and posted podiums label players "Player 1", "Player 2" instead of their names
+
+	PlayerPaced bool `json:"playerPaced"` // Whether every player works through the quiz on their own, in their own order of questions and choices
+	ExamMode    bool `json:"examMode"`    // Whether the game is an exam: no standings or points until the end, no late joins and players leaving the window are flagged
+	BuzzerMode  bool `json:"buzzerMode"`  // Whether players race to buzz and answer out loud, the host awarding or denying points
+	TieBreaker  bool `json:"tieBreaker"`  // Whether players tied for first place at the end play the quiz's tie-breaker question
+	MatchRounds int  `json:"matchRounds"` // Rounds the questions are split into for a match won by the most round wins, 0 or 1 for a single game
+
+	EliminationPercent int `json:"eliminationPercent"` // Share of the players still in who are knocked out at the bottom after each question, 0 for none
+
+	AllowedNetworks  []string `json:"allowedNetworks"`  // IP addresses and CIDR ranges players may join from, empty for anywhere
+	AllowedCountries []string `json:"allowedCountries"` // ISO 3166-1 alpha-2 codes of the countries players may join from, empty for anywhere
+
+	RequireSignIn bool     `json:"requireSignIn"` // Whether players must be signed in to join, and play under the name of their account
+	SignInDomains []string `json:"signInDomains"` // Email domains the accounts of players must belong to when sign in is required, empty for any account
+}
+
+// GameSettingsPacket is sent by the host to change the settings while in the lobby,
+// and to everyone in the game whenever the settings are set
+type GameSettingsPacket struct {
+	Settings GameSettings `json:"settings"` // The settings of the game
+}
+
+// validate checks that the settings use known modes that can be combined, that the penalty, hint cost, idle limit,
+// match rounds and elimination share are within range and that the join and sign in restrictions can be read.
+func (p *GameSettingsPacket) validate() error {
+	if p.Settings.AnswerChange < NoAnswerChange || p.Settings.AnswerChange > SecondGuess {
+		return ErrInvalidPacket
+	}
+
+	if p.Settings.Scoring < StandardScoring || p.Settings.Scoring > AccuracyScoring {
+		return ErrInvalidPacket
+	}
+
+	if p.Settings.Scoring == PenaltyScoring && (p.Settings.Penalty <= 0 || p.Settings.Penalty > maxPenalty) {
+		return ErrInvalidPacket
+	}
+
+	if p.Settings.HintCost < 0 || p.Settings.HintCost > maxHintCost {
+		return ErrInvalidPacket
+	}
+
+	if p.Settings.IdleLimit < 0 || p.Settings.IdleLimit > maxIdleLimit {
+		return ErrInvalidPacket
+	}
+
+	// Answers given out loud are judged once and only in live games, with no confidence to weigh them
+	if p.Settings.BuzzerMode && (p.Settings.PlayerPaced || p.Settings.ExamMode || p.Settings.AnswerChange != NoAnswerChange || p.Settings.ConfidenceMode) {
+		return ErrInvalidPacket
+	}
+
+	// Players of player-paced games finish at different times, so there is no moment to break a tie or end a round
+	if (p.Settings.TieBreaker || p.Settings.MatchRounds > 1) && p.Settings.PlayerPaced {
+		return ErrInvalidPacket
+	}
+
+	// Rounds are won by the standings, which exams keep hidden
+	if p.Settings.MatchRounds < 0 || p.Settings.MatchRounds > maxMatchRounds || (p.Settings.MatchRounds > 1 && p.Settings.ExamMode) {
+		return ErrInvalidPacket
+	}
+
+	// Eliminations follow the standings after every reveal, which exams and player-paced games do not have
+	if p.Settings.EliminationPercent < 0 || p.Settings.EliminationPercent > maxEliminationPercent ||
+		(p.Settings.EliminationPercent > 0 && (p.Settings.ExamMode || p.Settings.PlayerPaced)) {
+		return ErrInvalidPacket
+	}
+
+	if err := validateSignInDomains(p.Settings.RequireSignIn, p.Settings.SignInDomains); err != nil {
+		return err
+	}
+
+	return validateJoinRestriction(p.Settings.AllowedNetworks, p.Settings.AllowedCountries)
+}
+
+// OnSettings applies the settings the host chose and tells the players about them.
+// Settings can only be changed before the quiz starts.
+// Parameters:
+// - settings: the new settings
+func (g *Game) OnSettings(settings GameSettings) {
+	if g.State != LobbyState {
+		return
+	}
+
+	// Tournament rounds are matched to the next round by the players' accounts
+	if g.tournament != nil {
+		settings.AnonymizeResults = false
+		settings.RequireSignIn = true
+	}
+
+	if changed := changedSettings(g.Settings, settings); changed != "" {
+		g.recordHostAction(SettingsHostAction, nil, changed)
+	}
+
+	g.Settings = settings
+	g.BroadcastPacket(GameSettingsPacket{Settings: g.Settings}, true)
+	g.BroadcastPacket(g.newGameInfoPacket(), false)
+}
+
+// canChangeAnswer reports whether the player may still change their answer to the current question
+// Parameters:
+// - answer: the player's answer to the current question
+// Returns:
+// - bool: true if the settings allow another change
+func (g *Game) canChangeAnswer(answer *PlayerAnswer) bool {
+	return g.Settings.AnswerChange != NoAnswerChange && len(answer.History) < maxAnswerChanges
+}
+
+// scoreAnswer awards the player the points of their final answer to the current question
+// Parameters:
+// - player: the player to score
+func (g *Game) scoreAnswer(player *Player) {
+	player.LastAwardedPoints = 0
+
+	answer, ok := player.Answers[g.CurrentQuestion]
+	if !player.Answered || !ok {
+		return
+	}
+
+	// Only rewards are halved for a second guess, penalties stay whole
+	if g.Settings.AnswerChange == SecondGuess && len(answer.History) > 0 && answer.Points > 0 {
+		halved := *answer
+		halved.Points /= 2
+		g.applyToPlayer(player, AnswerRecordedEvent{PlayerId: player.Id, Answer: halved})
+		answer = player.Answers[g.CurrentQuestion]
+	}
+
+	total := g.answerScoring(g.CurrentQuestion, answer.Confidence).Apply(player.Points, answer.Points)
+	player.LastAwardedPoints = total - player.Points
+	g.applyToPlayer(player, PointsAwardedEvent{PlayerId: player.Id, Points: player.LastAwardedPoints})
+}
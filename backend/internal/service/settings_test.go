@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestAnswerChangeModes(t *testing.T) {
+	tests := []struct {
+		mode    AnswerChangeMode
+		changed bool // Whether the second answer replaces the first
+		halved  bool // Whether the changed answer scores half points
+	}{
+		{NoAnswerChange, false, false},
+		{OneAnswerChange, true, false},
+		{SecondGuess, true, true},
+	}
+
+	for _, test := range tests {
+		c := Net(NetOptions{}, config.Config{})
+		game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+		game.OnSettings(GameSettings{AnswerChange: test.mode})
+		game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+		game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+		alice := game.Players[0]
+		game.Start()
+
+		// Alice answers wrong first, then switches to the correct choice
+		game.OnPlayerAnswer(1, alice)
+		reward := game.getPointsReward(game.getAnswerElapsed(alice))
+		game.OnPlayerAnswer(0, alice)
+		game.OnPlayerAnswer(2, alice)
+
+		answer := alice.Answers[0]
+		if answer.Correct != test.changed || (len(answer.History) == 1) != test.changed {
+			t.Errorf("mode %d: expected changed %v, got choice %d and history %v", test.mode, test.changed, answer.Choice, answer.History)
+		}
+		if game.State != PlayState {
+			t.Fatalf("mode %d: expected the question to wait for bob", test.mode)
+		}
+
+		game.Reveal()
+		want := 0
+		if test.changed {
+			want = reward
+		}
+		if test.halved {
+			want /= 2
+		}
+		if alice.Points != want {
+			t.Errorf("mode %d: expected %d points, got %d", test.mode, want, alice.Points)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"html/template"
+	"time"
+
+	"quiz.com/quiz/internal/entity"
+)
+
+// shareTokenBytes is the number of random bytes in the token of a public results link
+const shareTokenBytes = 16
+
+// SharedResult is the read-only summary of a finished game shown on its public results link.
+// It leaves out anything identifying the players beyond the names they chose.
+type SharedResult struct {
+	QuizName  string               `json:"quizName"`  // Name of the quiz that was played
+	EndedAt   time.Time            `json:"endedAt"`   // When the game ended
+	ExpiresAt time.Time            `json:"expiresAt"` // When the link stops working
+	Players   []SharedPlayerResult `json:"players"`   // Final results of every player, ordered by points
+	Awards    []entity.GameAward   `json:"awards"`    // Awards handed out at the end of the game
+}
+
+// SharedPlayerResult is a single player's line on a public results page
+type SharedPlayerResult struct {
+	Name     string `json:"name"`     // Player's name
+	Points   int    `json:"points"`   // Total points scored
+	Correct  int    `json:"correct"`  // Number of questions answered correctly
+	Answered int    `json:"answered"` // Number of questions answered
+}
+
+// sharedResultPage renders a SharedResult as a standalone HTML page
+var sharedResultPage = template.Must(template.New("result").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Results: {{.QuizName}}</title>
+<style>
+body { font-family: sans-serif; background: #a855f7; color: #fff; margin: 0; padding: 2rem; }
+main { max-width: 40rem; margin: 0 auto; }
+table { width: 100%; border-collapse: collapse; background: #fff; color: #111; border-radius: 0.5rem; }
+th, td { padding: 0.5rem 1rem; text-align: left; }
+tr:nth-child(even) { background: #f3f4f6; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.QuizName}}</h1>
+<p>Played {{.EndedAt.Format "Jan 2, 2006 15:04 MST"}}</p>
+<table>
+<tr><th>#</th><th>Player</th><th>Points</th><th>Correct</th></tr>
+{{range $i, $player := .Players}}<tr><td>{{inc $i}}</td><td>{{$player.Name}}</td><td>{{$player.Points}}</td><td>{{$player.Correct}}/{{$player.Answered}}</td></tr>
+{{end}}</table>
+<p><small>This page is available until {{.ExpiresAt.Format "Jan 2, 2006 15:04 MST"}}.</small></p>
+</main>
+</body>
+</html>
+`))
+
+// newShareToken generates the secret token of a public results link.
+// Returns:
+// - The hex encoded token and an error if no randomness is available.
+func newShareToken() (string, error) {
+	bytes := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// GetSharedResult retrieves the summary of a game shared under a public results link.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - token: the secret token of the link.
+// Returns:
+// - The summary, or nil if the token is unknown or the link has expired, and an error if something goes wrong.
+func (s ResultService) GetSharedResult(ctx context.Context, token string) (*SharedResult, error) {
+	// Tokens are always the same length, so anything else is not worth a query
+	if len(token) != shareTokenBytes*2 {
+		return nil, nil
+	}
+
+	result, err := s.resultCollection.GetResultByShareToken(ctx, token)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	if time.Now().After(result.ShareExpiresAt) {
+		return nil, nil
+	}
+
+	shared := newSharedResult(*result)
+	return &shared, nil
+}
+
+// newSharedResult builds the public summary of a stored game result.
+// Parameters:
+// - result: the stored result.
+// Returns:
+// - The summary shown on the public results link.
+func newSharedResult(result entity.GameResult) SharedResult {
+	players := []SharedPlayerResult{}
+	for _, player := range result.Players {
+		players = append(players, SharedPlayerResult{
+			Name:     player.Name,
+			Points:   player.Points,
+			Correct:  player.Correct,
+			Answered: player.Answered,
+		})
+	}
+
+	awards := result.Awards
+	if awards == nil {
+		awards = []entity.GameAward{}
+	}
+
+	return SharedResult{
+		QuizName:  result.QuizName,
+		EndedAt:   result.EndedAt,
+		ExpiresAt: result.ShareExpiresAt,
+		Players:   players,
+		Awards:    awards,
+	}
+}
+
+// RenderSharedResult renders the summary of a game as a standalone HTML page.
+// Parameters:
+// - result: the summary to render.
+// Returns:
+// - The HTML page and an error if rendering fails.
+func RenderSharedResult(result SharedResult) (string, error) {
+	var page bytes.Buffer
+	if err := sharedResultPage.Execute(&page, result); err != nil {
+		return "", err
+	}
+
+	return page.String(), nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestShareResult(t *testing.T) {
+	endedAt := time.Now()
+
+	c := Net(NetOptions{}, config.Config{ResultShareTtl: time.Hour})
+	result := entity.GameResult{EndedAt: endedAt}
+	c.shareResult(&result)
+
+	if len(result.ShareToken) != shareTokenBytes*2 {
+		t.Errorf("expected a %d character token, got %q", shareTokenBytes*2, result.ShareToken)
+	}
+
+	if !result.ShareExpiresAt.Equal(endedAt.Add(time.Hour)) {
+		t.Errorf("expected the link to expire an hour after the game, got %v", result.ShareExpiresAt)
+	}
+
+	disabled := Net(NetOptions{}, config.Config{})
+	result = entity.GameResult{EndedAt: endedAt}
+	disabled.shareResult(&result)
+
+	if result.ShareToken != "" {
+		t.Errorf("expected no token when links are disabled, got %q", result.ShareToken)
+	}
+}
+
+func TestRenderSharedResult(t *testing.T) {
+	shared := newSharedResult(entity.GameResult{
+		QuizName: "Capitals",
+		EndedAt:  time.Now(),
+		Players: []entity.PlayerResult{
+			{UserId: primitive.NewObjectID(), Name: "<b>Alice</b>", Points: 3000, Answered: 3, Correct: 3},
+			{Name: "Bob", Points: 1000, Answered: 3, Correct: 1},
+		},
+	})
+
+	page, err := RenderSharedResult(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(page, "<b>Alice</b>") || !strings.Contains(page, "&lt;b&gt;Alice&lt;/b&gt;") {
+		t.Error("expected player names to be escaped")
+	}
+
+	if !strings.Contains(page, "<td>2</td><td>Bob</td><td>1000</td><td>1/3</td>") {
+		t.Errorf("expected Bob on second place, got %s", page)
+	}
+}
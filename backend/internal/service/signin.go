@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// maxSignInDomains is the most email domains a game can restrict its players to
+const maxSignInDomains = 20
+
+// validateSignInDomains checks the email domains players of a game must sign in from
+// Parameters:
+// - required: whether players must sign in
+// - domains: the domains, in lower case and without the @
+// Returns:
+// - error: ErrInvalidPacket if domains are given without requiring sign in, there are too many or one is malformed
+func validateSignInDomains(required bool, domains []string) error {
+	if len(domains) > maxSignInDomains || (len(domains) > 0 && !required) {
+		return ErrInvalidPacket
+	}
+
+	for _, domain := range domains {
+		if domain != strings.ToLower(domain) || !strings.Contains(domain, ".") || strings.ContainsAny(domain, "@ ") || len(domain) > 253 {
+			return ErrInvalidPacket
+		}
+	}
+
+	return nil
+}
+
+// getSignInAccount reads the account of a joining player, for games that require players to sign in.
+// Parameters:
+// - ctx: the context bounding the database operation.
+// - game: the game being joined.
+// - userId: the ObjectID of the user the access token was issued to, zero for anonymous players.
+// Returns:
+// - The account, nil for anonymous players, games open to everyone or if it cannot be read.
+func (c *NetService) getSignInAccount(ctx context.Context, game *Game, userId primitive.ObjectID) *entity.User {
+	if userId.IsZero() || c.userService == nil {
+		return nil
+	}
+
+	required := false
+	game.run("sign in check", func() {
+		required = game.Settings.RequireSignIn
+	})
+	if !required {
+		return nil
+	}
+
+	account, err := c.userService.GetUserById(ctx, userId)
+	if err != nil {
+		fmt.Println("failed to read the account of a joining player:", err)
+		return nil
+	}
+
+	return account
+}
+
+// joinAs decides the name a joining player plays under. Games that require sign in only let in players signed in
+// with an account of an allowed domain, under the name of their account, which no other account has, so nobody can
+// pass for someone else. Anyone can register with any address, so a domain only counts once the address is verified.
+// Parameters:
+// - name: the name the player picked
+// - account: the account the player signed in with, nil if anonymous or the game does not require sign in
+// Returns:
+// - string: the name to play under
+// - int: 0 if the player may join, otherwise the close code to turn them away with
+func (g *Game) joinAs(name string, account *entity.User) (string, int) {
+	if !g.Settings.RequireSignIn {
+		return name, 0
+	}
+
+	if account == nil || !inSignInDomains(account.Email, g.Settings.SignInDomains) {
+		return "", CloseSignInRequired
+	}
+
+	if len(g.Settings.SignInDomains) > 0 && !account.EmailVerified {
+		return "", CloseEmailUnverified
+	}
+
+	return account.Name, 0
+}
+
+// inSignInDomains reports whether an email address belongs to one of the allowed domains
+// Parameters:
+// - email: the email address of the account
+// - domains: the allowed domains, empty for any
+// Returns:
+// - bool: true if the address is in one of the domains or any domain is allowed
+func inSignInDomains(email string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range domains {
+		if domain == allowed {
+			return true
+		}
+	}
+
+	return false
+}
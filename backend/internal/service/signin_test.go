@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofiber/contrib/websocket"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestJoinAsSignedIn(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	c.addGame(game)
+
+	if name, code := game.joinAs("anyone", nil); name != "anyone" || code != 0 {
+		t.Errorf("expected games open to everyone to keep the picked name, got %q and %d", name, code)
+	}
+
+	game.Settings.RequireSignIn = true
+	game.Settings.SignInDomains = []string{"school.edu"}
+	student := &entity.User{Name: "Ada Lovelace", Email: "ada@School.edu", EmailVerified: true}
+	if name, code := game.joinAs("teacher", student); name != "Ada Lovelace" || code != 0 {
+		t.Errorf("expected the name of the account, got %q and %d", name, code)
+	}
+	if _, code := game.joinAs("ada", &entity.User{Name: "Ada", Email: "ada@gmail.com", EmailVerified: true}); code != CloseSignInRequired {
+		t.Errorf("expected an account of another domain to be turned away, got %d", code)
+	}
+	if _, code := game.joinAs("ada", &entity.User{Name: "Ada", Email: "ada@school.edu"}); code != CloseEmailUnverified {
+		t.Errorf("expected an account whose address is not verified to be turned away, got %d", code)
+	}
+
+	// Without domains the address does not matter
+	game.Settings.SignInDomains = nil
+	if _, code := game.joinAs("ada", &entity.User{Name: "Ada", Email: "ada@gmail.com"}); code != 0 {
+		t.Errorf("expected any account to join, got %d", code)
+	}
+
+	// Anonymous players are closed with CloseSignInRequired
+	anonymous := &closingConnection{}
+	c.OnIncomingMessage(context.Background(), anonymous, websocket.BinaryMessage, encodePacket(0, ConnectPacket{Code: game.Code, Name: "ada"}))
+	if len(game.Players) != 0 || anonymous.closeCode() != CloseSignInRequired {
+		t.Errorf("expected the anonymous player turned away, got code %d", anonymous.closeCode())
+	}
+}
+
+func TestValidateSignInDomains(t *testing.T) {
+	if err := validateSignInDomains(true, []string{"school.edu", "district.k12.us"}); err != nil {
+		t.Errorf("expected domains to be accepted, got %v", err)
+	}
+
+	for _, domains := range [][]string{{"School.edu"}, {"@school.edu"}, {"localhost"}} {
+		if validateSignInDomains(true, domains) == nil {
+			t.Errorf("expected %v to be refused", domains)
+		}
+	}
+
+	if validateSignInDomains(false, []string{"school.edu"}) == nil {
+		t.Errorf("expected domains without requiring sign in to be refused")
+	}
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"quiz.com/quiz/internal/config"
+)
+
+// Packet IDs of the packets the simulation decodes
+const (
+	playerRevealPacketId = 8
+	gameReportPacketId   = 15
+	gameEndPacketId      = 17
+)
+
+// simulation plays a whole game over in-memory connections. Packets from clients go through
+// NetService.OnIncomingMessage like real messages; time only passes when the simulation advances
+// its fake clock, which drives the game timer.
+type simulation struct {
+	t       *testing.T
+	net     *NetService
+	clock   *fakeClock
+	game    *Game
+	host    *fakeConnection
+	players map[string]*fakeConnection
+}
+
+// newSimulation creates a game of the fuzz quiz, as a host would
+func newSimulation(t *testing.T) *simulation {
+	c := Net(NetOptions{}, config.Config{})
+	clock := newFakeClock()
+	c.clock = clock
+	host := &fakeConnection{}
+	game := newGame(fuzzQuiz(), host, c)
+	c.addGame(game)
+
+	return &simulation{
+		t:       t,
+		net:     c,
+		clock:   clock,
+		game:    game,
+		host:    host,
+		players: map[string]*fakeConnection{},
+	}
+}
+
+// send delivers a packet from a client to the server
+func (s *simulation) send(connection *fakeConnection, packetId uint8, packet any) {
+	s.net.OnIncomingMessage(context.Background(), connection, websocket.BinaryMessage, encodePacket(packetId, packet))
+}
+
+// join connects a new player to the game
+func (s *simulation) join(name string) {
+	connection := &fakeConnection{}
+	s.players[name] = connection
+	s.send(connection, 0, ConnectPacket{Code: s.game.Code, Name: name})
+}
+
+// start has the host start the game, which starts its timer
+func (s *simulation) start() {
+	s.send(s.host, 5, StartGamePacket{})
+	s.settle()
+}
+
+// answer sends a player's answer to the current question
+func (s *simulation) answer(name string, choice int) {
+	s.send(s.players[name], 7, QuestionAnswerPacket{Question: choice})
+}
+
+// disconnect drops a player's connection
+func (s *simulation) disconnect(name string) {
+	s.net.OnDisconnect(s.players[name])
+}
+
+// advance lets the given number of seconds pass on the game timer
+func (s *simulation) advance(seconds int) {
+	for i := 0; i < seconds && !s.ended(); i++ {
+		s.clock.Advance(time.Second)
+		s.settle()
+	}
+}
+
+// settle waits until the game timer has processed its tick and sleeps again, or the game is over
+func (s *simulation) settle() {
+	for s.clock.sleeping() == 0 && !s.ended() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// revealed reports whether the current question is revealed
+func (s *simulation) revealed() bool {
+	revealed := false
+	s.game.run("check", func() {
+		revealed = s.game.State == RevealState
+	})
+
+	return revealed
+}
+
+// ended reports whether the game is over
+func (s *simulation) ended() bool {
+	ended := false
+	s.game.run("check", func() {
+		ended = s.game.Ended
+	})
+
+	return ended
+}
+
+// states returns the game states a connection was told about, in order
+func (s *simulation) states(connection *fakeConnection) []GameState {
+	states := []GameState{}
+	for _, message := range connection.messages {
+		if message[0] != changeGameStatePacketId {
+			continue
+		}
+
+		var packet ChangeGameStatePacket
+		if err := json.Unmarshal(packetData(message), &packet); err != nil {
+			s.t.Fatal(err)
+		}
+		states = append(states, packet.State)
+	}
+
+	return states
+}
+
+// count returns how many packets of a type a connection received
+func (s *simulation) count(connection *fakeConnection, packetId uint8) int {
+	count := 0
+	for _, id := range connection.packetIds() {
+		if id == packetId {
+			count++
+		}
+	}
+
+	return count
+}
+
+// report decodes the report the host received at the end of the game
+func (s *simulation) report() GameReportPacket {
+	var report GameReportPacket
+	for _, message := range s.host.messages {
+		if message[0] == gameReportPacketId {
+			if err := json.Unmarshal(packetData(message), &report); err != nil {
+				s.t.Fatal(err)
+			}
+			return report
+		}
+	}
+
+	s.t.Fatalf("the host received no report, packets %v", s.host.packetIds())
+	return report
+}
+
+func TestSimulatedGame(t *testing.T) {
+	s := newSimulation(t)
+	s.join("alice")
+	s.join("bob")
+	s.join("carol")
+	s.start()
+
+	// First question, choice 0 is correct; the reveal follows the last answer
+	s.answer("alice", 0)
+	s.answer("bob", 1)
+	s.answer("carol", 0)
+	if s.game.State != RevealState {
+		t.Fatalf("expected the reveal once everyone answered, got state %d", s.game.State)
+	}
+	s.advance(5 + 30)
+
+	// Second question, choice 1 is correct; alice leaves before answering
+	s.disconnect("alice")
+	s.answer("carol", 1)
+	s.answer("bob", 1)
+	if s.game.State != RevealState {
+		t.Fatalf("expected the reveal once the remaining players answered, got state %d", s.game.State)
+	}
+	s.advance(5 + 30)
+
+	// Third question has no choices, nobody answers before the time runs out
+	s.advance(1 + 5 + 30)
+	if !s.game.Ended {
+		t.Fatalf("expected the game to end after the last question, got state %d", s.game.State)
+	}
+
+	// Answer order earns 5000, 4000, 3000 points and every second left 16 more
+	report := s.report()
+	scores := map[string]int{}
+	for _, player := range report.Players {
+		scores[player.Name] = player.Points
+	}
+	if len(scores) != 2 || scores["carol"] != 3048+5032 || scores["bob"] != 4032 {
+		t.Errorf("unexpected final scores %v", scores)
+	}
+
+	want := []GameState{LobbyState, PlayState, PlayState, RevealState, IntermissionState, PlayState, RevealState, IntermissionState, PlayState, RevealState, IntermissionState, EndState}
+	if got := s.states(s.players["bob"]); !slices.Equal(got, want) {
+		t.Errorf("bob saw states %v, want %v", got, want)
+	}
+	if got := s.states(s.players["alice"]); !slices.Equal(got, want[:len(want)-6]) {
+		t.Errorf("alice saw states %v after leaving, want %v", got, want[:len(want)-6])
+	}
+
+	if got := s.count(s.players["bob"], playerQuestionPacketId); got != 3 {
+		t.Errorf("bob was shown %d questions, want 3", got)
+	}
+	if got := s.count(s.players["bob"], playerRevealPacketId); got != 3 {
+		t.Errorf("bob saw %d reveals, want 3", got)
+	}
+	if s.count(s.players["bob"], gameEndPacketId) != 1 || s.count(s.host, gameEndPacketId) != 1 {
+		t.Errorf("expected the podium on every remaining screen")
+	}
+}
+
+func TestSimulatedDeadline(t *testing.T) {
+	s := newSimulation(t)
+	s.join("alice")
+	s.join("bob")
+	s.start()
+
+	// The first question lasts three seconds, alice answers after one
+	s.advance(1)
+	s.answer("alice", 0)
+	s.advance(2)
+	if s.game.State != RevealState {
+		t.Fatalf("expected the reveal once the time ran out, got state %d", s.game.State)
+	}
+
+	s.answer("bob", 0)
+	acks := answerAcks(t, s.players["bob"])
+	if len(acks) != 1 || acks[0].Received {
+		t.Errorf("expected the late answer to be turned down, got %+v", acks)
+	}
+
+	// A second taken to answer costs 16 points of the time reward
+	if alice, bob := s.game.Players[0], s.game.Players[1]; alice.Points != 5000+2*16 || bob.Points != 0 {
+		t.Errorf("unexpected points after the deadline: alice %d, bob %d", alice.Points, bob.Points)
+	}
+}
+
+func TestSimulatedAnswerGrace(t *testing.T) {
+	s := newSimulation(t)
+	s.net.config.AnswerGrace = 500 * time.Millisecond
+	s.join("alice")
+	s.join("bob")
+	s.start()
+
+	// The timer runs out, the answer arrives within the grace window
+	s.advance(3)
+	if s.game.State != PlayState {
+		t.Fatalf("expected answers to be accepted after the timer ran out, got state %d", s.game.State)
+	}
+	s.answer("alice", 0)
+	if acks := answerAcks(t, s.players["alice"]); len(acks) != 1 || !acks[0].Received {
+		t.Errorf("expected the answer within the grace window to count, got %+v", acks)
+	}
+
+	// The timer shown stays at zero, the reveal follows once the window closes
+	s.clock.Advance(500 * time.Millisecond)
+	for !s.revealed() {
+		time.Sleep(time.Millisecond)
+	}
+	if s.game.Time != 5 {
+		t.Errorf("expected the reveal timer, got %d", s.game.Time)
+	}
+
+	// Without time left the answer earns no time bonus
+	if alice := s.game.Players[0]; alice.Points != 5000 {
+		t.Errorf("expected 5000 points without time bonus, got %d", alice.Points)
+	}
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the available stream sinks, selected with the QUIZ_STREAM setting
+const (
+	NatsStreamSink  = "nats"
+	KafkaStreamSink = "kafka"
+)
+
+// Limits of the live event stream
+const (
+	StreamSchemaVersion = 1                // Version of the StreamEvent schema, raised on incompatible changes
+	streamBuffer        = 1024             // Events waiting to be published before new ones are dropped
+	streamTimeout       = 10 * time.Second // Time allowed to connect to the sink or publish a single event
+)
+
+// Types of the events in the live stream
+const (
+	AnswerStreamEvent = "answer" // A player answered or changed their answer
+	RevealStreamEvent = "reveal" // The answers to a question were counted
+)
+
+// ErrInvalidStream is returned when the stream sink is unknown or misconfigured
+var ErrInvalidStream = errors.New("invalid stream")
+
+// StreamEvent is an event of a game published to the live stream, encoded as JSON.
+// Fields that do not apply to the type of the event are left out.
+type StreamEvent struct {
+	Schema     int       `json:"schema"`               // Version of the schema, see StreamSchemaVersion
+	Type       string    `json:"type"`                 // Type of the event, see AnswerStreamEvent
+	At         time.Time `json:"at"`                   // When the event happened
+	GameId     string    `json:"gameId"`               // ID of the game, also the key of the message
+	QuizId     string    `json:"quizId"`               // ID of the quiz being played
+	Question   int       `json:"question"`             // Index of the question in the quiz
+	QuestionId string    `json:"questionId,omitempty"` // ID of the question in the quiz
+
+	PlayerId   string `json:"playerId,omitempty"`   // ID of the player in the game (answer)
+	PlayerName string `json:"playerName,omitempty"` // Name of the player, left out for anonymized games (answer)
+	Choice     *int   `json:"choice,omitempty"`     // Index of the chosen choice in the quiz (answer)
+	Correct    *bool  `json:"correct,omitempty"`    // Whether the choice is correct (answer)
+	ElapsedMs  int64  `json:"elapsedMs,omitempty"`  // Time the player took to answer (answer)
+	Changed    bool   `json:"changed,omitempty"`    // Whether the player changed an earlier answer (answer)
+	Sampled    int    `json:"sampled,omitempty"`    // One in this many answers is published, 1 unless a large game is throttled (answer)
+
+	Players       int   `json:"players,omitempty"`       // Players in the game (reveal)
+	Answered      int   `json:"answered,omitempty"`      // Players who answered (reveal)
+	CorrectCount  int   `json:"correctCount,omitempty"`  // Players who answered correctly (reveal)
+	ChoiceCounts  []int `json:"choiceCounts,omitempty"`  // Answers per choice (reveal)
+	CorrectChoice []int `json:"correctChoice,omitempty"` // Indexes of the correct choices (reveal)
+}
+
+// StreamSink publishes encoded events to an external message system.
+type StreamSink interface {
+	// Publish sends an event, keyed so the events of a game stay in order where the system partitions messages.
+	Publish(key string, event []byte) error
+}
+
+// StreamService publishes the events of games to a StreamSink in the background, so slow or unreachable
+// analytics stacks never hold up a game. Events that cannot be published are dropped.
+type StreamService struct {
+	sink    StreamSink       // Where the events are published
+	events  chan StreamEvent // Events waiting to be published
+	dropped bool             // Whether an event was dropped since the last one was published
+}
+
+// Stream initializes and returns a new StreamService instance and starts publishing.
+// Parameters:
+// - sink: where the events are published.
+func Stream(sink StreamSink) *StreamService {
+	s := &StreamService{
+		sink:   sink,
+		events: make(chan StreamEvent, streamBuffer),
+	}
+	go s.run()
+
+	return s
+}
+
+// Publish queues an event to be published, dropping it if too many are waiting.
+// Parameters:
+// - event: the event; its schema version is set here.
+func (s *StreamService) Publish(event StreamEvent) {
+	event.Schema = StreamSchemaVersion
+	select {
+	case s.events <- event:
+	default:
+		// The sink fell behind, the event is lost rather than holding up the game
+	}
+}
+
+// run publishes the queued events one by one.
+func (s *StreamService) run() {
+	for event := range s.events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+
+		if err := s.sink.Publish(event.GameId, body); err != nil {
+			if !s.dropped {
+				fmt.Println("failed to publish game event:", err)
+			}
+			s.dropped = true
+			continue
+		}
+		s.dropped = false
+	}
+}
+
+// NewStreamSink creates the sink selected by the configuration.
+// Parameters:
+// - kind: the sink, NatsStreamSink or KafkaStreamSink.
+// - address: nats://[user:password@]host:port for NATS, the base URL of a Kafka REST Proxy for Kafka.
+// - topic: the NATS subject or Kafka topic events are published to.
+// Returns:
+// - The sink, and ErrInvalidStream if the kind is unknown or the address or topic invalid.
+func NewStreamSink(kind string, address string, topic string) (StreamSink, error) {
+	if topic == "" || strings.ContainsAny(topic, " \t\r\n/") {
+		return nil, ErrInvalidStream
+	}
+
+	switch kind {
+	case NatsStreamSink:
+		return NatsSink(address, topic)
+	case KafkaStreamSink:
+		return KafkaRestSink(address, topic)
+	}
+
+	return nil, ErrInvalidStream
+}
+
+// NatsStream publishes events to a NATS subject with the core NATS text protocol.
+// The connection is opened on the first event and opened again after it breaks.
+type NatsStream struct {
+	server  natsServer // Server events are published to
+	subject string     // Subject events are published to
+	mu      sync.Mutex // Guards the connection
+	conn    net.Conn   // Connection to the server, nil when closed
+}
+
+// NatsSink initializes and returns a new NatsStream instance.
+// Parameters:
+// - address: nats://[user:password@]host:port of the server.
+// - subject: the subject events are published to.
+// Returns:
+// - The sink, and ErrInvalidStream if the address is not a NATS URL.
+func NatsSink(address string, subject string) (*NatsStream, error) {
+	server, ok := parseNatsUrl(address)
+	if !ok {
+		return nil, ErrInvalidStream
+	}
+
+	return &NatsStream{
+		server:  server,
+		subject: subject,
+	}, nil
+}
+
+// Publish sends an event to the subject; NATS has no keys, so the key is not used.
+// Parameters:
+// - key: unused.
+// - event: the encoded event.
+// Returns:
+// - An error if the server cannot be reached.
+func (s *NatsStream) Publish(key string, event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, reader, err := s.server.dial()
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		go s.answerPings(conn, reader)
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(streamTimeout))
+	if _, err := s.conn.Write(natsPub(s.subject, event)); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// answerPings keeps a connection alive by answering the pings of the server, until the connection breaks
+// Parameters:
+// - conn: the connection
+// - reader: the reader of the connection, past the greeting of the server
+func (s *NatsStream) answerPings(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+
+		if strings.HasPrefix(line, "PING") {
+			s.mu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(streamTimeout))
+			_, err = conn.Write([]byte("PONG\r\n"))
+			s.mu.Unlock()
+		} else if strings.HasPrefix(line, "-ERR") {
+			fmt.Println("NATS server error:", strings.TrimSpace(line))
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	// Publishing on a connection the server closed would silently lose events, so the next event reconnects
+	s.mu.Lock()
+	if s.conn == conn {
+		s.conn = nil
+	}
+	s.mu.Unlock()
+	conn.Close()
+}
+
+// KafkaStream publishes events to a Kafka topic through a Kafka REST Proxy, which keeps a Kafka client out of the server.
+type KafkaStream struct {
+	client *http.Client // Client used to call the proxy
+	url    string       // URL records of the topic are posted to
+}
+
+// KafkaRestSink initializes and returns a new KafkaStream instance.
+// Parameters:
+// - address: the base URL of the Kafka REST Proxy, e.g. http://kafka-rest:8082.
+// - topic: the topic events are published to.
+// Returns:
+// - The sink, and ErrInvalidStream if the address is not an HTTP URL.
+func KafkaRestSink(address string, topic string) (*KafkaStream, error) {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidStream
+	}
+
+	return &KafkaStream{
+		client: &http.Client{Timeout: streamTimeout},
+		url:    strings.TrimSuffix(u.String(), "/") + "/topics/" + url.PathEscape(topic),
+	}, nil
+}
+
+// Publish posts an event as a JSON record keyed by the game.
+// Parameters:
+// - key: the key of the record.
+// - event: the encoded event.
+// Returns:
+// - An error if the proxy cannot be reached or refuses the record.
+func (s *KafkaStream) Publish(key string, event []byte) error {
+	body, err := json.Marshal(map[string]any{
+		"records": []map[string]any{{"key": key, "value": json.RawMessage(event)}},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), streamTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("kafka rest proxy responded %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// streamAnswer publishes a player's answer to the current question to the live stream, if one is configured.
+// Parameters:
+// - player: the player who answered
+// - answer: the answer as recorded
+func (g *Game) streamAnswer(player *Player, answer PlayerAnswer) {
+	if g.netService.streamService == nil {
+		return
+	}
+
+	sampled := g.sampleAnswer()
+	if sampled == 0 {
+		return
+	}
+
+	name := player.Name
+	if g.Settings.AnonymizeResults {
+		name = ""
+	}
+
+	g.netService.streamService.Publish(StreamEvent{
+		Type:       AnswerStreamEvent,
+		At:         g.netService.clock.Now(),
+		GameId:     g.Id.String(),
+		QuizId:     g.Quiz.Id.Hex(),
+		Question:   answer.Question,
+		QuestionId: g.Quiz.Questions[answer.Question].Id,
+		PlayerId:   player.Id.String(),
+		PlayerName: name,
+		Choice:     &answer.Choice,
+		Correct:    &answer.Correct,
+		ElapsedMs:  answer.Elapsed.Milliseconds(),
+		Changed:    len(answer.History) > 0,
+		Sampled:    sampled,
+	})
+}
+
+// streamReveal publishes how the players answered the current question to the live stream, if one is configured.
+func (g *Game) streamReveal() {
+	if g.netService.streamService == nil {
+		return
+	}
+
+	question := g.getCurrentQuestion()
+	event := StreamEvent{
+		Type:          RevealStreamEvent,
+		At:            g.netService.clock.Now(),
+		GameId:        g.Id.String(),
+		QuizId:        g.Quiz.Id.Hex(),
+		Question:      g.CurrentQuestion,
+		QuestionId:    question.Id,
+		Players:       len(g.Players),
+		ChoiceCounts:  make([]int, len(question.Choices)),
+		CorrectChoice: []int{},
+	}
+
+	for i, choice := range question.Choices {
+		if choice.Correct {
+			event.CorrectChoice = append(event.CorrectChoice, i)
+		}
+	}
+
+	for _, player := range g.Players {
+		answer, ok := player.Answers[g.CurrentQuestion]
+		if !player.Answered || !ok {
+			continue
+		}
+
+		event.Answered++
+		if answer.Correct {
+			event.CorrectCount++
+		}
+		if answer.Choice >= 0 && answer.Choice < len(event.ChoiceCounts) {
+			event.ChoiceCounts[answer.Choice]++
+		}
+	}
+
+	g.netService.streamService.Publish(event)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+)
+
+// fakeSink hands the published events to the test
+type fakeSink struct {
+	events chan StreamEvent
+}
+
+func (s fakeSink) Publish(key string, event []byte) error {
+	var decoded StreamEvent
+	if err := json.Unmarshal(event, &decoded); err != nil {
+		return err
+	}
+	if key != decoded.GameId {
+		decoded.GameId = "wrong key " + key
+	}
+
+	s.events <- decoded
+	return nil
+}
+
+// nextStreamEvent waits for the next event published to a fake sink
+func nextStreamEvent(t *testing.T, sink fakeSink) StreamEvent {
+	t.Helper()
+	select {
+	case event := <-sink.events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("expected an event to be published")
+	}
+
+	return StreamEvent{}
+}
+
+func TestStreamEvents(t *testing.T) {
+	sink := fakeSink{events: make(chan StreamEvent, 10)}
+	c := Net(NetOptions{StreamService: Stream(sink)}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	game.OnSettings(GameSettings{AnonymizeResults: true})
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.Start()
+
+	game.OnPlayerAnswer(1, game.Players[0])
+
+	answer := nextStreamEvent(t, sink)
+	if answer.Type != AnswerStreamEvent || answer.Schema != StreamSchemaVersion || answer.GameId != game.Id.String() {
+		t.Fatalf("expected an answer event of the game, got %+v", answer)
+	}
+	if answer.Choice == nil || *answer.Choice != 1 || answer.Correct == nil || answer.PlayerName != "" {
+		t.Errorf("expected the anonymized answer with its choice, got %+v", answer)
+	}
+
+	reveal := nextStreamEvent(t, sink)
+	if reveal.Type != RevealStreamEvent || reveal.Players != 1 || reveal.Answered != 1 || reveal.ChoiceCounts[1] != 1 {
+		t.Errorf("expected the reveal to count the answer, got %+v", reveal)
+	}
+}
+
+func TestNatsSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.Write([]byte("INFO {}\r\n"))
+		reader := bufio.NewReader(conn)
+		connect, _ := reader.ReadString('\n')
+		pub, _ := reader.ReadString('\n')
+		payload, _ := reader.ReadString('\n')
+		received <- connect + pub + payload
+	}()
+
+	sink, err := NewStreamSink(NatsStreamSink, "nats://"+listener.Addr().String(), "quiz.events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Publish("game", []byte(`{"type":"answer"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if !strings.HasPrefix(got, "CONNECT {") || !strings.Contains(got, "PUB quiz.events 17\r\n{\"type\":\"answer\"}\r\n") {
+		t.Errorf("expected the event to be published to the subject, got %q", got)
+	}
+}
+
+func TestKafkaRestSink(t *testing.T) {
+	var path, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		path, contentType, body = r.URL.Path, r.Header.Get("Content-Type"), string(data)
+	}))
+	defer server.Close()
+
+	sink, err := NewStreamSink(KafkaStreamSink, server.URL+"/", "quiz.events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Publish("game", []byte(`{"type":"reveal"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/topics/quiz.events" || contentType != "application/vnd.kafka.json.v2+json" || body != `{"records":[{"key":"game","value":{"type":"reveal"}}]}` {
+		t.Errorf("expected a keyed record posted to the topic, got %s %s %s", path, contentType, body)
+	}
+
+	if _, err := NewStreamSink("kinesis", server.URL, "quiz.events"); err != ErrInvalidStream {
+		t.Errorf("expected unknown sinks to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// SetTemplate marks a quiz as a template every signed in user can see and duplicate, or takes the mark away.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the quiz.
+// - template: whether the quiz is a template.
+// Returns:
+// - ErrQuizNotFound if the quiz does not exist, or an error if the update fails.
+func (s QuizService) SetTemplate(ctx context.Context, id primitive.ObjectID, template bool) error {
+	found, err := s.quizCollection.UpdateQuizTemplate(ctx, id, template)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrQuizNotFound
+	}
+
+	s.invalidateQuiz(id)
+	return nil
+}
+
+// GetTemplates lists the quizzes marked as templates.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// Returns:
+// - The templates and an error if the quizzes cannot be read.
+func (s QuizService) GetTemplates(ctx context.Context) ([]entity.Quiz, error) {
+	quizzes, err := s.GetQuizzes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(quizzes, func(quiz entity.Quiz) bool {
+		return !quiz.Template
+	}), nil
+}
+
+// DuplicateQuiz creates a copy of a quiz owned by a user, the only way to start a quiz from a template.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the quiz to copy.
+// - ownerId: the ObjectID of the user the copy belongs to.
+// Returns:
+// - The copy, and ErrQuizNotFound if the quiz does not exist or an error if the copy cannot be stored.
+func (s QuizService) DuplicateQuiz(ctx context.Context, id primitive.ObjectID, ownerId primitive.ObjectID) (*entity.Quiz, error) {
+	quiz, err := s.GetQuizById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if quiz == nil {
+		return nil, ErrQuizNotFound
+	}
+
+	return s.createQuiz(ctx, ownerId, quiz.Name+" (copy)", quiz.QuizSettings, duplicateQuestions(quiz.Questions))
+}
+
+// duplicateQuestions copies the questions of a quiz for a new quiz, which starts without statistics
+// Parameters:
+// - questions: the questions to copy
+// Returns:
+// - []entity.QuizQuestion: the copies
+func duplicateQuestions(questions []entity.QuizQuestion) []entity.QuizQuestion {
+	copies := []entity.QuizQuestion{}
+	for _, question := range questions {
+		question.Choices = slices.Clone(question.Choices)
+		question.Media = slices.Clone(question.Media)
+		question.Stats = entity.QuestionStats{}
+		copies = append(copies, question)
+	}
+
+	return copies
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestDuplicateQuestions(t *testing.T) {
+	questions := []entity.QuizQuestion{{
+		Id:      "q1",
+		Name:    "Capital of France?",
+		Choices: []entity.QuizChoice{{Id: "a", Name: "Paris", Correct: true}},
+		Stats:   entity.QuestionStats{Answered: 12, Correct: 9, Difficulty: 0.25},
+	}}
+
+	copies := duplicateQuestions(questions)
+	if len(copies) != 1 || copies[0].Name != "Capital of France?" || copies[0].Stats != (entity.QuestionStats{}) {
+		t.Fatalf("expected the question without statistics, got %+v", copies)
+	}
+
+	copies[0].Choices[0].Name = "Lyon"
+	if questions[0].Choices[0].Name != "Paris" {
+		t.Errorf("expected the copy to have its own choices")
+	}
+}
+
+func TestTemplatePermission(t *testing.T) {
+	template := entity.Quiz{OwnerId: primitive.NewObjectID(), Template: true}
+
+	if !CanAccessQuiz(template, primitive.NewObjectID(), "", entity.ViewPermission) {
+		t.Errorf("expected signed in users to see templates")
+	}
+	if CanAccessQuiz(template, primitive.NewObjectID(), "", entity.EditPermission) {
+		t.Errorf("expected signed in users not to edit templates they were not given")
+	}
+	if CanAccessQuiz(template, primitive.NilObjectID, "", entity.ViewPermission) {
+		t.Errorf("expected anonymous users not to see templates")
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/entity"
+)
+
+// defaultTieBreakerTime is the time in seconds given to a tie-breaker question without a time of its own
+const defaultTieBreakerTime = 20
+
+// TieBreakerPacket tells everyone in the game that the players tied for first place play the tie-breaker question
+type TieBreakerPacket struct {
+	Players []string `json:"players"` // Names of the tied players
+	Playing bool     `json:"playing"` // Whether the receiving player answers the question, false for the host and everyone else
+}
+
+// tieBreak is the tie-breaker question played for first place at the end of a game
+type tieBreak struct {
+	question entity.QuizQuestion        // The question reserved in the quiz settings
+	players  []uuid.UUID                // Players tied for first place, who may answer
+	answers  map[uuid.UUID]PlayerAnswer // First answer of each tied player who answered
+	winner   uuid.UUID                  // Player who answered correctly first, uuid.Nil if nobody did or while the question is played
+}
+
+// validTieBreaker checks that a tie-breaker question can be answered correctly
+// Parameters:
+// - question: the reserved question
+// Returns:
+// - bool: true if the question has a correct choice and a time within range
+func validTieBreaker(question entity.QuizQuestion) bool {
+	if question.Time < 0 || question.Time > maxQuestionTime {
+		return false
+	}
+
+	return slices.ContainsFunc(question.Choices, func(choice entity.QuizChoice) bool {
+		return choice.Correct
+	})
+}
+
+// startTieBreaker asks the players tied for first place the tie-breaker question of the quiz,
+// if the host turned tie-breakers on. The fastest correct answer wins first place.
+// Returns:
+// - bool: true if the tie-breaker started, false if the game may end
+func (g *Game) startTieBreaker() bool {
+	if !g.Settings.TieBreaker || g.Quiz.TieBreaker == nil || g.tieBreak != nil {
+		return false
+	}
+
+	tied := g.getTiedLeaders()
+	if len(tied) < 2 {
+		return false
+	}
+
+	question := *g.Quiz.TieBreaker
+	if question.Time <= 0 {
+		question.Time = defaultTieBreakerTime
+	}
+	g.tieBreak = &tieBreak{
+		question: question,
+		answers:  map[uuid.UUID]PlayerAnswer{},
+	}
+
+	names := []string{}
+	for _, player := range tied {
+		g.tieBreak.players = append(g.tieBreak.players, player.Id)
+		names = append(names, player.Name)
+	}
+
+	g.ResetPlayerAnswerStates()
+	g.setTimer(question.Time)
+	g.ChangeState(TieBreakerState)
+	g.questionStartedAt = g.clock.Now()
+
+	g.sendToHost(TieBreakerPacket{Players: names})
+	g.sendToHost(QuestionShowPacket{
+		Question:   question,
+		ServerTime: g.questionStartedAt.UnixMilli(),
+		EndsAt:     g.endsAt(),
+		Index:      len(g.Quiz.Questions),
+		Total:      len(g.Quiz.Questions),
+		Slots:      getChoiceSlots(len(question.Choices)),
+	})
+
+	// Everyone sees who plays for first place, only the tied players are asked the question
+	for _, player := range g.Players {
+		playing := g.isTied(player)
+		g.netService.SendPacket(player.Connection, TieBreakerPacket{
+			Players: names,
+			Playing: playing,
+		})
+		if playing {
+			g.netService.SendPacket(player.Connection, PlayerQuestionPacket{
+				Choices:     len(question.Choices),
+				Time:        question.Time,
+				ServerTime:  g.questionStartedAt.UnixMilli(),
+				EndsAt:      g.endsAt(),
+				ClockOffset: player.ClockOffset,
+				Media:       sizeMedia(question.Media, entity.MediumSize),
+				Index:       len(g.Quiz.Questions),
+				Total:       len(g.Quiz.Questions),
+				Slots:       getChoiceSlots(len(question.Choices)),
+			})
+		}
+	}
+
+	return true
+}
+
+// getTiedLeaders returns the players sharing first place
+// Returns:
+// - []*Player: the players ranked first, in the order of the leaderboard
+func (g *Game) getTiedLeaders() []*Player {
+	players := append([]*Player{}, g.Players...)
+	g.sortPlayers(players)
+
+	scoring := g.scoring()
+	tied := []*Player{}
+	for _, player := range players {
+		if len(tied) > 0 && g.ranks(tied[0], player, scoring) {
+			break
+		}
+		tied = append(tied, player)
+	}
+
+	return tied
+}
+
+// isTied reports whether a player plays the tie-breaker question
+// Parameters:
+// - player: the player
+// Returns:
+// - bool: true if the player is tied for first place
+func (g *Game) isTied(player *Player) bool {
+	return g.tieBreak != nil && slices.Contains(g.tieBreak.players, player.Id)
+}
+
+// onTieBreakerAnswer records the first answer of a tied player to the tie-breaker question.
+// Once every tied player still in the game answered, the tie-breaker is decided.
+// Parameters:
+// - choice: the index of the chosen answer
+// - player: the player who answered
+func (g *Game) onTieBreakerAnswer(choice int, player *Player) {
+	if !g.isTied(player) {
+		g.sendAnswerAck(player, choice, false)
+		return
+	}
+
+	if answer, ok := g.tieBreak.answers[player.Id]; ok {
+		g.sendAnswerAck(player, choice, answer.Choice == choice)
+		return
+	}
+
+	elapsed := g.clock.Now().Sub(g.questionStartedAt) - player.latency()
+	limit := time.Duration(g.tieBreak.question.Time) * time.Second
+	g.tieBreak.answers[player.Id] = PlayerAnswer{
+		Question: len(g.Quiz.Questions),
+		Choice:   choice,
+		Correct:  isCorrectChoiceOf(g.tieBreak.question, choice),
+		Elapsed:  max(0, min(elapsed, limit)),
+	}
+	player.Answered = true
+	g.markActive(player)
+	g.sendAnswerAck(player, choice, true)
+
+	for _, p := range g.Players {
+		if g.isTied(p) && !p.Answered {
+			return
+		}
+	}
+	g.endTieBreaker()
+}
+
+// endTieBreaker gives first place to the fastest correct answer to the tie-breaker question and ends the game.
+// If nobody answered correctly, the players stay tied.
+func (g *Game) endTieBreaker() {
+	var fastest *PlayerAnswer
+	for _, id := range g.tieBreak.players {
+		answer, ok := g.tieBreak.answers[id]
+		if !ok || !answer.Correct || (fastest != nil && answer.Elapsed >= fastest.Elapsed) {
+			continue
+		}
+
+		fastest = &answer
+		g.tieBreak.winner = id
+	}
+
+	g.End()
+}
+
+// wonTieBreak reports whether a player ranks above another they are tied with, by winning the tie-breaker
+// Parameters:
+// - player: the player who may have won
+// - other: the player they are compared to
+// - scoring: the scoring strategy ranking the players
+// Returns:
+// - bool: true if the player won the tie-breaker and the other does not rank above them
+func (g *Game) wonTieBreak(player *Player, other *Player, scoring ScoringStrategy) bool {
+	return g.tieBreak != nil && player.Id == g.tieBreak.winner && !scoring.Ranks(other, player)
+}
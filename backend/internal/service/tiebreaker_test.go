@@ -0,0 +1,86 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// tieBreakerPacketId is the ID of TieBreakerPacket on the wire
+const tieBreakerPacketId = 47
+
+func TestTieBreaker(t *testing.T) {
+	quiz := fuzzQuiz()
+	quiz.TieBreaker = &entity.QuizQuestion{
+		Id:      "tie",
+		Name:    "Tie",
+		Choices: []entity.QuizChoice{{Name: "a"}, {Name: "b", Correct: true}},
+	}
+
+	c := Net(NetOptions{}, config.Config{})
+	clock := newFakeClock()
+	c.clock = clock
+	game := newGame(quiz, &fakeConnection{}, c)
+	carolConnection := &fakeConnection{}
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("carol", primitive.NilObjectID, 0, 0, "", carolConnection)
+	alice, bob, carol := game.Players[0], game.Players[1], game.Players[2]
+	carol.Points = -1
+	game.OnSettings(GameSettings{TieBreaker: true})
+
+	// Started by hand, without the timer goroutine of Start; nobody answers, so alice and bob tie
+	game.stopLobby()
+	game.ChangeState(PlayState)
+	for range quiz.Questions {
+		game.NextQuestion()
+	}
+	carolConnection.messages = nil
+	game.NextQuestion()
+
+	if game.State != TieBreakerState || game.tieBreak.question.Time != defaultTieBreakerTime {
+		t.Fatalf("expected the tie-breaker to start, got state %d", game.State)
+	}
+	if !slices.Contains(carolConnection.packetIds(), tieBreakerPacketId) || slices.Contains(carolConnection.packetIds(), playerQuestionPacketId) {
+		t.Errorf("expected carol to watch the tie-breaker without being asked")
+	}
+
+	game.OnPlayerAnswer(1, carol)
+	clock.Advance(time.Second)
+	game.OnPlayerAnswer(0, alice)
+	clock.Advance(time.Second)
+	game.OnPlayerAnswer(1, bob)
+
+	if game.State != EndState {
+		t.Fatalf("expected the game to end once the tied players answered, got state %d", game.State)
+	}
+	if podium := game.getLeaderboard(); podium[0].Name != "bob" || podium[1].Name != "alice" {
+		t.Errorf("expected the correct answer to win first place, got %+v", podium)
+	}
+}
+
+func TestNoTieBreakerWithoutTie(t *testing.T) {
+	quiz := fuzzQuiz()
+	quiz.TieBreaker = &entity.QuizQuestion{Choices: []entity.QuizChoice{{Name: "a", Correct: true}}}
+
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(quiz, &fakeConnection{}, c)
+	game.OnPlayerJoin("alice", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("bob", primitive.NilObjectID, 0, 0, "", &fakeConnection{})
+	game.Players[0].Points = 100
+	game.OnSettings(GameSettings{TieBreaker: true})
+
+	game.stopLobby()
+	game.ChangeState(PlayState)
+	for range len(quiz.Questions) + 1 {
+		game.NextQuestion()
+	}
+
+	if game.State != EndState || game.tieBreak != nil {
+		t.Errorf("expected the game to end without a tie-breaker, got state %d", game.State)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+
+	token, err := signToken(TokenClaims{
+		Subject:   "user",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := parseToken(token, secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != "user" {
+		t.Errorf("expected subject user, got %q", claims.Subject)
+	}
+
+	if _, err := parseToken(token, []byte("other"), now); err != ErrInvalidToken {
+		t.Errorf("expected a token signed with another key to be rejected, got %v", err)
+	}
+
+	if _, err := parseToken(token, secret, now.Add(2*time.Hour)); err != ErrInvalidToken {
+		t.Errorf("expected an expired token to be rejected, got %v", err)
+	}
+}
+
+func TestVerificationTokenIsNoAccessToken(t *testing.T) {
+	users := User(nil, nil, nil, config.Config{AuthSecret: "secret", AuthTokenTtl: time.Hour})
+	user := entity.User{Id: primitive.NewObjectID(), Email: "ada@school.edu"}
+
+	token, err := users.verificationToken(user, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := users.VerifyToken(token); err != ErrInvalidToken {
+		t.Errorf("expected a verification link not to sign in, got %v", err)
+	}
+
+	if err := users.VerifyEmail(context.Background(), "not a token"); err != ErrInvalidToken {
+		t.Errorf("expected an invalid link to be refused, got %v", err)
+	}
+
+	access, err := users.IssueToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := users.VerifyEmail(context.Background(), access); err != ErrInvalidToken {
+		t.Errorf("expected an access token not to verify an address, got %v", err)
+	}
+}
+
+func TestVerificationLinkPointsAtTheApi(t *testing.T) {
+	users := User(nil, nil, nil, config.Config{
+		AuthSecret: "secret",
+		ApiUrl:     "https://api.quiz.example/",
+		MediaUrl:   "https://cdn.quiz.example",
+		PublicUrl:  "https://quiz.example",
+	})
+
+	link, err := url.Parse(users.verificationLink("a+b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if link.Host != "api.quiz.example" || link.Path != "/api/auth/verify" {
+		t.Errorf("expected the link to reach the API, got %s", link)
+	}
+
+	if token := link.Query().Get("token"); token != "a+b" {
+		t.Errorf("expected the token to survive the link, got %q", token)
+	}
+}
+
+func TestNameKey(t *testing.T) {
+	if entity.NameKey("  Ada   LOVELACE ") != entity.NameKey("ada lovelace") {
+		t.Error("expected names differing in case and spacing to share a key")
+	}
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Limits of tournaments
+const (
+	maxTournamentRounds = 32             // Highest number of rounds a tournament may schedule
+	roundReservationTtl = 12 * time.Hour // How long a game holds its round before it may be hosted again, for games lost in a crash
+)
+
+// Errors returned when a tournament cannot be created or played.
+var (
+	ErrInvalidTournament  = errors.New("invalid tournament")
+	ErrTournamentNotFound = errors.New("tournament not found")
+	ErrTournamentFinished = errors.New("tournament has no rounds left")
+	ErrWrongRoundQuiz     = errors.New("quiz does not match the next tournament round")
+	ErrNotTournamentOwner = errors.New("not the owner of the tournament")
+	ErrRoundTaken         = errors.New("another game is hosted for the tournament round")
+)
+
+// TournamentService provides methods for creating tournaments, scheduling their rounds and computing standings.
+type TournamentService struct {
+	tournamentCollection *collection.TournamentCollection // Reference to the tournament collection for database operations
+	resultCollection     *collection.ResultCollection     // Reference to the result collection for reading played rounds
+}
+
+// Tournament initializes and returns a new TournamentService instance.
+// Parameters:
+// - tournamentCollection: the collection that interacts with the tournaments in the database.
+// - resultCollection: the collection that interacts with the game results in the database.
+func Tournament(tournamentCollection *collection.TournamentCollection, resultCollection *collection.ResultCollection) *TournamentService {
+	return &TournamentService{
+		tournamentCollection: tournamentCollection,
+		resultCollection:     resultCollection,
+	}
+}
+
+// CreateTournament schedules a new tournament playing the given quizzes in order.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - ownerId: the ObjectID of the user scheduling the tournament, the only one who may host its rounds.
+// - name: the name of the tournament.
+// - format: how standings are decided, entity.PointsFormat or entity.BracketFormat.
+// - quizIds: the quizzes played in each round.
+// Returns:
+// - The created tournament and ErrInvalidTournament if the input is invalid.
+func (s TournamentService) CreateTournament(ctx context.Context, ownerId primitive.ObjectID, name string, format string, quizIds []primitive.ObjectID) (*entity.Tournament, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(quizIds) == 0 || len(quizIds) > maxTournamentRounds {
+		return nil, ErrInvalidTournament
+	}
+
+	if format != entity.PointsFormat && format != entity.BracketFormat {
+		return nil, ErrInvalidTournament
+	}
+
+	rounds := []entity.TournamentRound{}
+	for _, quizId := range quizIds {
+		if quizId.IsZero() {
+			return nil, ErrInvalidTournament
+		}
+
+		rounds = append(rounds, entity.TournamentRound{QuizId: quizId})
+	}
+
+	tournament := entity.Tournament{
+		Id:        primitive.NewObjectID(),
+		OwnerId:   ownerId,
+		Name:      name,
+		Format:    format,
+		Rounds:    rounds,
+		CreatedAt: time.Now(),
+	}
+	if err := s.tournamentCollection.InsertTournament(ctx, tournament); err != nil {
+		return nil, err
+	}
+
+	return &tournament, nil
+}
+
+// GetTournamentById retrieves a tournament by its unique identifier.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the tournament to retrieve.
+// Returns:
+// - A pointer to the Tournament entity, or nil if it does not exist, and an error if something goes wrong.
+func (s TournamentService) GetTournamentById(ctx context.Context, id primitive.ObjectID) (*entity.Tournament, error) {
+	return s.tournamentCollection.GetTournamentById(ctx, id)
+}
+
+// GetOwnTournament retrieves a tournament only its owner may see or change.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the tournament.
+// - userId: the ObjectID of the user.
+// Returns:
+// - The tournament, and ErrTournamentNotFound if it does not exist or ErrNotTournamentOwner if the user did not schedule it.
+func (s TournamentService) GetOwnTournament(ctx context.Context, id primitive.ObjectID, userId primitive.ObjectID) (*entity.Tournament, error) {
+	tournament, err := s.tournamentCollection.GetTournamentById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tournament == nil {
+		return nil, ErrTournamentNotFound
+	}
+
+	if userId.IsZero() || tournament.OwnerId != userId {
+		return nil, ErrNotTournamentOwner
+	}
+
+	return tournament, nil
+}
+
+// GetStandings computes the current standings of a tournament from its played rounds.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - tournament: the tournament.
+// Returns:
+// - The standings from first to last place and an error if something goes wrong.
+func (s TournamentService) GetStandings(ctx context.Context, tournament entity.Tournament) ([]entity.TournamentStanding, error) {
+	results, err := s.resultCollection.GetResultsByTournament(ctx, tournament.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return calculateStandings(tournament.Format, results), nil
+}
+
+// StartRound claims the next unplayed round of a tournament for a game its owner hosts.
+// The round stays claimed until the game's result is stored or the game is given up,
+// so no other game can be hosted for it meanwhile.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the tournament.
+// - hostUserId: the ObjectID of the signed in host, who must own the tournament.
+// - quizId: the quiz the host wants to play, which must be the one scheduled for the round.
+// - code: the join code of the game.
+// Returns:
+// - The tournament, the index of the round, the current standings and an error if the round cannot be played,
+// ErrNotTournamentOwner if the host does not own the tournament or ErrRoundTaken if another game holds the round.
+func (s TournamentService) StartRound(ctx context.Context, id primitive.ObjectID, hostUserId primitive.ObjectID, quizId primitive.ObjectID, code string) (*entity.Tournament, int, []entity.TournamentStanding, error) {
+	tournament, err := s.GetOwnTournament(ctx, id, hostUserId)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	round := nextRound(*tournament)
+	if round < 0 {
+		return nil, 0, nil, ErrTournamentFinished
+	}
+
+	if tournament.Rounds[round].QuizId != quizId {
+		return nil, 0, nil, ErrWrongRoundQuiz
+	}
+
+	now := time.Now()
+	reserved, err := s.tournamentCollection.ReserveRound(ctx, id, round, code, now, now.Add(-roundReservationTtl))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if !reserved {
+		return nil, 0, nil, ErrRoundTaken
+	}
+
+	standings, err := s.GetStandings(ctx, *tournament)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	return tournament, round, standings, nil
+}
+
+// ReleaseRound gives up the claim of a game on a round it did not finish, so the round can be hosted again.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the tournament.
+// - round: the index of the round.
+// - code: the join code of the game that claimed the round.
+// Returns:
+// - An error if the claim could not be given up.
+func (s TournamentService) ReleaseRound(ctx context.Context, id primitive.ObjectID, round int, code string) error {
+	return s.tournamentCollection.ReleaseRound(ctx, id, round, code)
+}
+
+// CompleteRound marks a round as played and returns the updated standings.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the tournament.
+// - round: the index of the round.
+// - resultId: the ObjectID of the stored game result.
+// Returns:
+// - The updated standings and an error if something goes wrong.
+func (s TournamentService) CompleteRound(ctx context.Context, id primitive.ObjectID, round int, resultId primitive.ObjectID) ([]entity.TournamentStanding, error) {
+	if err := s.tournamentCollection.SetRoundResult(ctx, id, round, resultId); err != nil {
+		return nil, err
+	}
+
+	tournament, err := s.tournamentCollection.GetTournamentById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tournament == nil {
+		return nil, ErrTournamentNotFound
+	}
+
+	return s.GetStandings(ctx, *tournament)
+}
+
+// nextRound finds the first round of a tournament that has not been played yet.
+// Parameters:
+// - tournament: the tournament.
+// Returns:
+// - The index of the round, or -1 if every round has been played.
+func nextRound(tournament entity.Tournament) int {
+	for i, round := range tournament.Rounds {
+		if round.ResultId.IsZero() {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// calculateStandings aggregates the results of a tournament's rounds into standings.
+// In the points format the points of every round are added up. In the bracket
+// format only the top half of each round advances; everyone else, including
+// players who skipped a round, is eliminated and ranked below those still in.
+// Signed in players are told apart by their account, so a new nickname does not make a new entrant.
+// Parameters:
+// - format: the format of the tournament.
+// - results: the results of the played rounds, ordered by round.
+// Returns:
+// - The standings from first to last place.
+func calculateStandings(format string, results []entity.GameResult) []entity.TournamentStanding {
+	standings := []*entity.TournamentStanding{}
+	byEntrant := map[string]*entity.TournamentStanding{}
+
+	for _, result := range results {
+		played := map[*entity.TournamentStanding]bool{}
+		for i, player := range result.Players {
+			key := entrantKey(player)
+			standing, ok := byEntrant[key]
+			if !ok {
+				standing = &entity.TournamentStanding{UserId: player.UserId}
+				byEntrant[key] = standing
+				standings = append(standings, standing)
+			}
+
+			// The latest name is shown, players may pick another one between rounds
+			standing.Name = player.Name
+			standing.Points += player.Points
+			standing.RoundsPlayed++
+			played[standing] = true
+
+			// Players are ordered by points, so the bottom half of a bracket round drops out
+			if format == entity.BracketFormat && i >= (len(result.Players)+1)/2 {
+				standing.Eliminated = true
+			}
+		}
+
+		if format != entity.BracketFormat {
+			continue
+		}
+
+		for _, standing := range standings {
+			if !played[standing] {
+				standing.Eliminated = true
+			}
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Eliminated != b.Eliminated {
+			return !a.Eliminated
+		}
+
+		if format == entity.BracketFormat && a.RoundsPlayed != b.RoundsPlayed {
+			return a.RoundsPlayed > b.RoundsPlayed
+		}
+
+		return a.Points > b.Points
+	})
+
+	sorted := []entity.TournamentStanding{}
+	for _, standing := range standings {
+		sorted = append(sorted, *standing)
+	}
+
+	return sorted
+}
+
+// entrantKey identifies a player across the rounds of a tournament: by account if they were signed in,
+// otherwise by name for results stored before tournament games required sign in.
+// Parameters:
+// - player: the player's result in a round.
+// Returns:
+// - The key of the entrant.
+func entrantKey(player entity.PlayerResult) string {
+	if !player.UserId.IsZero() {
+		return "user:" + player.UserId.Hex()
+	}
+
+	return "name:" + player.Name
+}
+
+// advancingPlayers returns the accounts of the players allowed into the next round of a bracket tournament.
+// Parameters:
+// - format: the format of the tournament.
+// - round: the index of the next round.
+// - standings: the current standings.
+// Returns:
+// - The accounts of the signed in players still in, or nil if anyone may join.
+func advancingPlayers(format string, round int, standings []entity.TournamentStanding) map[primitive.ObjectID]bool {
+	if format != entity.BracketFormat || round == 0 {
+		return nil
+	}
+
+	users := map[primitive.ObjectID]bool{}
+	for _, standing := range standings {
+		if !standing.Eliminated && !standing.UserId.IsZero() {
+			users[standing.UserId] = true
+		}
+	}
+
+	return users
+}
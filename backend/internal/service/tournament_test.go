@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+func tournamentResults() []entity.GameResult {
+	return []entity.GameResult{
+		{Round: 0, Players: []entity.PlayerResult{
+			{Name: "alice", Points: 3000},
+			{Name: "bob", Points: 2500},
+			{Name: "carol", Points: 2000},
+			{Name: "dave", Points: 500},
+		}},
+		{Round: 1, Players: []entity.PlayerResult{
+			{Name: "bob", Points: 4000},
+			{Name: "alice", Points: 1000},
+		}},
+	}
+}
+
+func TestCalculateStandingsPoints(t *testing.T) {
+	standings := calculateStandings(entity.PointsFormat, tournamentResults())
+
+	want := []string{"bob", "alice", "carol", "dave"}
+	for i, name := range want {
+		if standings[i].Name != name || standings[i].Eliminated {
+			t.Fatalf("unexpected standings: %+v", standings)
+		}
+	}
+
+	if standings[0].Points != 6500 || standings[0].RoundsPlayed != 2 {
+		t.Errorf("unexpected standing for bob: %+v", standings[0])
+	}
+}
+
+func TestCalculateStandingsBracket(t *testing.T) {
+	standings := calculateStandings(entity.BracketFormat, tournamentResults())
+
+	if standings[0].Name != "bob" || standings[0].Eliminated {
+		t.Fatalf("expected bob to lead the bracket, got %+v", standings)
+	}
+
+	// alice lost the second round, so she ranks above players knocked out in the first
+	if standings[1].Name != "alice" || !standings[1].Eliminated {
+		t.Errorf("expected alice in second place and eliminated, got %+v", standings[1])
+	}
+
+	// Only signed in players can be told apart between rounds, so only they advance
+	if advancing := advancingPlayers(entity.BracketFormat, 2, standings); len(advancing) != 0 {
+		t.Errorf("expected no anonymous player to advance, got %v", advancing)
+	}
+}
+
+func TestStandingsByAccount(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	results := []entity.GameResult{
+		{Round: 0, Players: []entity.PlayerResult{
+			{UserId: alice, Name: "alice", Points: 3000},
+			{UserId: bob, Name: "bob", Points: 500},
+		}},
+		// Knocked out, bob comes back under alice's old name
+		{Round: 1, Players: []entity.PlayerResult{
+			{UserId: alice, Name: "Alice", Points: 1000},
+			{UserId: bob, Name: "alice", Points: 200},
+		}},
+	}
+
+	standings := calculateStandings(entity.BracketFormat, results)
+	if len(standings) != 2 || standings[0].UserId != alice || standings[0].Name != "Alice" || standings[0].Points != 4000 || standings[0].Eliminated {
+		t.Fatalf("expected alice to lead under the latest name, got %+v", standings)
+	}
+	if standings[1].UserId != bob || !standings[1].Eliminated {
+		t.Errorf("expected bob to stay eliminated, got %+v", standings[1])
+	}
+
+	advancing := advancingPlayers(entity.BracketFormat, 2, standings)
+	if len(advancing) != 1 || !advancing[alice] {
+		t.Errorf("expected only alice's account to advance, got %v", advancing)
+	}
+
+	// Whatever name an eliminated player picks, their account is turned away
+	game := newGame(fuzzQuiz(), &fakeConnection{}, Net(NetOptions{}, config.Config{}))
+	game.allowedUsers = advancing
+	game.OnPlayerJoin("Alice", bob, 0, 0, "", &fakeConnection{})
+	game.OnPlayerJoin("Alice", alice, 0, 0, "", &fakeConnection{})
+	if len(game.Players) != 1 || game.Players[0].UserId != alice {
+		t.Errorf("expected only alice to join the round, got %+v", game.Players)
+	}
+}
+
+func TestAdvancingPlayersFirstRound(t *testing.T) {
+	if advancing := advancingPlayers(entity.BracketFormat, 0, nil); advancing != nil {
+		t.Errorf("expected anyone to be allowed into the first round, got %v", advancing)
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Names of the available translators, selected with the QUIZ_TRANSLATOR setting
+const (
+	LibreTranslator = "libretranslate"
+	DeeplTranslator = "deepl"
+)
+
+// Limits of machine translation
+const (
+	translationTimeout  = 30 * time.Second // Time allowed for translating a whole quiz
+	translationMaxBytes = 1 << 20          // Largest response read from a translator
+)
+
+// Errors returned when a quiz cannot be translated.
+var (
+	ErrInvalidTranslator   = errors.New("invalid translator")
+	ErrTranslationDisabled = errors.New("no translator configured")
+	ErrInvalidLanguage     = errors.New("invalid language")
+)
+
+// languagePattern matches the language codes quizzes are translated to, e.g. "de" or "pt-BR"
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{2,4})?$`)
+
+// Translator machine-translates text into another language.
+type Translator interface {
+	// Translate translates texts into a language, detecting the language they are written in.
+	// Returns the translations in the order of the texts.
+	Translate(ctx context.Context, texts []string, language string) ([]string, error)
+}
+
+// NewTranslator creates the translator selected by the configuration.
+// Parameters:
+// - kind: the translator, LibreTranslator or DeeplTranslator.
+// - address: the base URL of the translation API, e.g. https://api-free.deepl.com for DeepL.
+// - key: the API key, empty for LibreTranslate servers that need none.
+// Returns:
+// - The translator, and ErrInvalidTranslator if the kind is unknown or the address invalid.
+func NewTranslator(kind string, address string, key string) (Translator, error) {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidTranslator
+	}
+
+	base := strings.TrimSuffix(u.String(), "/")
+	client := &http.Client{Timeout: translationTimeout}
+	switch kind {
+	case LibreTranslator:
+		return &LibreTranslate{client: client, url: base + "/translate", key: key}, nil
+	case DeeplTranslator:
+		if key == "" {
+			return nil, ErrInvalidTranslator
+		}
+
+		return &Deepl{client: client, url: base + "/v2/translate", key: key}, nil
+	}
+
+	return nil, ErrInvalidTranslator
+}
+
+// LibreTranslate translates with a LibreTranslate server.
+type LibreTranslate struct {
+	client *http.Client // Client used to call the server
+	url    string       // URL of the translate endpoint
+	key    string       // API key, empty if the server needs none
+}
+
+// Translate translates texts in a single request.
+// Parameters:
+// - ctx: the context bounding the request.
+// - texts: the texts to translate.
+// - language: the code of the language to translate to.
+// Returns:
+// - The translations and an error if the server cannot be reached or refuses the request.
+func (t *LibreTranslate) Translate(ctx context.Context, texts []string, language string) ([]string, error) {
+	body, err := json.Marshal(map[string]any{
+		"q":       texts,
+		"source":  "auto",
+		"target":  language,
+		"format":  "text",
+		"api_key": t.key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		TranslatedText []string `json:"translatedText"`
+	}
+	if err := postApi(ctx, t.client, t.url, "application/json", "", body, translationMaxBytes, &response); err != nil {
+		return nil, err
+	}
+
+	return response.TranslatedText, nil
+}
+
+// Deepl translates with the DeepL API.
+type Deepl struct {
+	client *http.Client // Client used to call the API
+	url    string       // URL of the translate endpoint
+	key    string       // Authentication key of the API
+}
+
+// Translate translates texts in a single request.
+// Parameters:
+// - ctx: the context bounding the request.
+// - texts: the texts to translate.
+// - language: the code of the language to translate to.
+// Returns:
+// - The translations and an error if the API cannot be reached or refuses the request.
+func (t *Deepl) Translate(ctx context.Context, texts []string, language string) ([]string, error) {
+	form := url.Values{"target_lang": {strings.ToUpper(language)}}
+	for _, text := range texts {
+		form.Add("text", text)
+	}
+
+	var response struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	err := postApi(ctx, t.client, t.url, "application/x-www-form-urlencoded", "DeepL-Auth-Key "+t.key, []byte(form.Encode()), translationMaxBytes, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	translations := []string{}
+	for _, translation := range response.Translations {
+		translations = append(translations, translation.Text)
+	}
+
+	return translations, nil
+}
+
+// postApi sends a request to a translation or embeddings API and decodes its JSON response.
+// Parameters:
+// - ctx: the context bounding the request.
+// - client: the client to send it with.
+// - endpoint: the URL of the endpoint.
+// - contentType: the type of the body.
+// - authorization: the Authorization header, empty to send none.
+// - body: the body of the request.
+// - maxBytes: the largest response read.
+// - response: where the response is decoded into.
+// Returns:
+// - An error if the API cannot be reached, refuses the request or responds with malformed JSON.
+func postApi(ctx context.Context, client *http.Client, endpoint string, contentType string, authorization string, body []byte, maxBytes int64, response any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded %d", endpoint, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxBytes)).Decode(response)
+}
+
+// TranslateQuiz machine-translates the name, questions and choices of a quiz into a new draft quiz owned by
+// the user, for them to review. Host notes and media are copied as they are.
+// Parameters:
+// - ctx: the context bounding the database operations and the translation.
+// - translator: the translator to use, nil if none is configured.
+// - id: the ObjectID of the quiz to translate.
+// - ownerId: the ObjectID of the user the translation belongs to.
+// - language: the code of the language to translate to, e.g. "de".
+// Returns:
+// - The translated quiz, and ErrTranslationDisabled if no translator is configured, ErrInvalidLanguage if the
+// language code is malformed or ErrQuizNotFound if the quiz does not exist.
+func (s QuizService) TranslateQuiz(ctx context.Context, translator Translator, id primitive.ObjectID, ownerId primitive.ObjectID, language string) (*entity.Quiz, error) {
+	if translator == nil {
+		return nil, ErrTranslationDisabled
+	}
+
+	if !languagePattern.MatchString(language) {
+		return nil, ErrInvalidLanguage
+	}
+
+	quiz, err := s.GetQuizById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if quiz == nil {
+		return nil, ErrQuizNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, translationTimeout)
+	defer cancel()
+
+	variant, err := translateQuiz(ctx, translator, *quiz, language)
+	if err != nil {
+		return nil, err
+	}
+	variant.OwnerId = ownerId
+
+	return s.insertQuiz(ctx, variant)
+}
+
+// translateQuiz builds the translated copy of a quiz, without storing it
+// Parameters:
+// - ctx: the context bounding the translation
+// - translator: the translator to use
+// - quiz: the quiz to translate
+// - language: the code of the language to translate to
+// Returns:
+// - entity.Quiz: the draft copy without an owner, linked to the quiz it was translated from
+// - error: an error if the translator fails or does not translate every text
+func translateQuiz(ctx context.Context, translator Translator, quiz entity.Quiz, language string) (entity.Quiz, error) {
+	questions := duplicateQuestions(quiz.Questions)
+	var tieBreaker *entity.QuizQuestion
+	if quiz.TieBreaker != nil {
+		copies := duplicateQuestions([]entity.QuizQuestion{*quiz.TieBreaker})
+		tieBreaker = &copies[0]
+	}
+
+	// Every text is translated in one request, then put back where it came from
+	texts := []*string{&quiz.Name}
+	collect := func(question *entity.QuizQuestion) {
+		texts = append(texts, &question.Name, &question.Hint)
+		for i := range question.Choices {
+			texts = append(texts, &question.Choices[i].Name)
+		}
+	}
+	for i := range questions {
+		collect(&questions[i])
+	}
+	if tieBreaker != nil {
+		collect(tieBreaker)
+	}
+
+	// Empty texts, such as choices left blank, are not worth sending
+	sources := []string{}
+	targets := []*string{}
+	for _, text := range texts {
+		if strings.TrimSpace(*text) != "" {
+			sources = append(sources, *text)
+			targets = append(targets, text)
+		}
+	}
+
+	translations, err := translator.Translate(ctx, sources, language)
+	if err != nil {
+		return entity.Quiz{}, err
+	}
+
+	if len(translations) != len(sources) {
+		return entity.Quiz{}, fmt.Errorf("translator returned %d of %d texts", len(translations), len(sources))
+	}
+
+	for i, target := range targets {
+		*target = translations[i]
+	}
+
+	settings := quiz.QuizSettings
+	settings.TieBreaker = tieBreaker
+	return entity.Quiz{
+		Id:            primitive.NewObjectID(),
+		Name:          quiz.Name,
+		QuizSettings:  settings,
+		Questions:     questions,
+		Language:      language,
+		TranslationOf: quiz.Id,
+		Draft:         true,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quiz.com/quiz/internal/entity"
+)
+
+// upperTranslator "translates" by upper-casing, and remembers what it was asked
+type upperTranslator struct {
+	texts []string
+}
+
+func (t *upperTranslator) Translate(ctx context.Context, texts []string, language string) ([]string, error) {
+	t.texts = texts
+	translations := []string{}
+	for _, text := range texts {
+		translations = append(translations, strings.ToUpper(text))
+	}
+
+	return translations, nil
+}
+
+func TestTranslateQuiz(t *testing.T) {
+	quiz := fuzzQuiz()
+	quiz.Id = primitive.NewObjectID()
+	quiz.Questions[0].HostNotes = "keep me"
+	quiz.Questions[0].Choices = append(quiz.Questions[0].Choices, entity.QuizChoice{Name: " "})
+	quiz.TieBreaker = &entity.QuizQuestion{Name: "tie", Choices: []entity.QuizChoice{{Name: "yes", Correct: true}}}
+
+	translator := &upperTranslator{}
+	variant, err := translateQuiz(context.Background(), translator, quiz, "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if variant.Name != "FUZZ" || variant.Questions[1].Name != "TWO" || variant.Questions[1].Choices[2].Name != "C" || variant.TieBreaker.Choices[0].Name != "YES" {
+		t.Errorf("expected every text to be translated, got %+v", variant)
+	}
+	if variant.Questions[0].HostNotes != "keep me" || !variant.Questions[0].Choices[0].Correct {
+		t.Errorf("expected the rest of the questions to be copied, got %+v", variant.Questions[0])
+	}
+	if len(translator.texts) != 11 {
+		t.Errorf("expected blank choices to be left out, got %d texts", len(translator.texts))
+	}
+	if !variant.Draft || variant.Language != "de" || variant.TranslationOf != quiz.Id || variant.Id == quiz.Id {
+		t.Errorf("expected a new draft linked to the original, got %+v", variant)
+	}
+
+	// The original is left as it was
+	if quiz.Name != "Fuzz" || quiz.Questions[0].Choices[0].Name != "a" || quiz.TieBreaker.Name != "tie" {
+		t.Errorf("expected the original quiz to stay untouched, got %+v", quiz)
+	}
+}
+
+func TestLibreTranslate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Q      []string `json:"q"`
+			Target string   `json:"target"`
+		}
+		if r.URL.Path != "/translate" || json.NewDecoder(r.Body).Decode(&req) != nil || req.Target != "fr" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"translatedText": []string{"bonjour"}})
+	}))
+	defer server.Close()
+
+	translator, err := NewTranslator(LibreTranslator, server.URL+"/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translations, err := translator.Translate(context.Background(), []string{"hello"}, "fr")
+	if err != nil || len(translations) != 1 || translations[0] != "bonjour" {
+		t.Errorf("expected the server's translation, got %v, %v", translations, err)
+	}
+}
+
+func TestTranslateQuizRejectsLanguage(t *testing.T) {
+	s := Quiz(nil, 0, nil)
+	for _, language := range []string{"", "german", "d", "de_DE", "../x"} {
+		if _, err := s.TranslateQuiz(context.Background(), &upperTranslator{}, primitive.NewObjectID(), primitive.NilObjectID, language); err != ErrInvalidLanguage {
+			t.Errorf("expected %q to be rejected, got %v", language, err)
+		}
+	}
+
+	if _, err := NewTranslator(DeeplTranslator, "https://api-free.deepl.com", ""); err != ErrInvalidTranslator {
+		t.Errorf("expected DeepL without a key to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+	"quiz.com/quiz/internal/collection"
+	"quiz.com/quiz/internal/config"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Errors returned by the user service
+var (
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrNameTaken          = errors.New("name is already taken")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidUser        = errors.New("invalid email, name or password")
+)
+
+// Profile is the public view of a user
+type Profile struct {
+	Id          primitive.ObjectID `json:"id"`          // Unique identifier for the user
+	Name        string             `json:"name"`        // Display name of the user
+	Rating      int                `json:"rating"`      // Current rating
+	GamesPlayed int                `json:"gamesPlayed"` // Number of rated games finished
+}
+
+// Email verification
+const (
+	verifyEmailPurpose   = "verify-email" // Purpose of the tokens emailed to verify an address
+	verificationTokenTtl = 24 * time.Hour // How long a verification link works
+	verificationLimit    = 3              // Most verification emails a user may ask for per window
+	verificationWindow   = time.Hour      // Window the verification emails of a user are counted in
+)
+
+// UserService provides methods for registering and authenticating users and maintaining their ratings.
+type UserService struct {
+	userCollection *collection.UserCollection // Reference to the user collection for database operations
+	transactor     *collection.Transactor     // Runs the rating updates of a game atomically
+	mailService    *MailService               // Sends the links verifying email addresses
+	secret         []byte                     // Key used to sign access tokens
+	tokenTtl       time.Duration              // How long issued access tokens are valid
+	verifyUrl      string                     // URL of the endpoint verification links point to
+	verifications  *rateLimiter               // Verification emails sent per user
+}
+
+// User initializes and returns a new UserService instance.
+// Parameters:
+// - userCollection: the collection that interacts with the users in the database.
+// - transactor: runs multi-document writes atomically.
+// - mailService: sends the links verifying email addresses.
+// - config: the runtime configuration, providing the token secret and lifetime and the URL the server is reached at.
+func User(userCollection *collection.UserCollection, transactor *collection.Transactor, mailService *MailService, config config.Config) *UserService {
+	return &UserService{
+		userCollection: userCollection,
+		transactor:     transactor,
+		mailService:    mailService,
+		secret:         []byte(config.AuthSecret),
+		tokenTtl:       config.AuthTokenTtl,
+		verifyUrl:      strings.TrimSuffix(config.ApiUrl, "/") + "/api/auth/verify",
+		verifications:  newRateLimiter(verificationLimit, verificationWindow),
+	}
+}
+
+// Register creates a new regular user account and emails a link verifying its address.
+// Registering never makes an admin: admins are created by other admins or, for the first one,
+// with the create-admin command of the server.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - email: the email address used to sign in.
+// - name: the display name.
+// - password: the plain text password, at least 8 characters.
+// Returns:
+// - The created user and an error if the input is invalid or the email or name is taken.
+func (s UserService) Register(ctx context.Context, email string, name string, password string) (*entity.User, error) {
+	user, err := s.createUser(ctx, email, name, password, entity.UserRole, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.verifications.allow(user.Id.Hex(), time.Now())
+	go s.sendVerification(*user)
+	return user, nil
+}
+
+// CreateUser creates a new user account with the given role. The account is created by an admin,
+// who vouches for the address, so it counts as verified.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - email: the email address used to sign in.
+// - name: the display name.
+// - password: the plain text password, at least 8 characters.
+// - role: the role of the user, entity.UserRole or entity.AdminRole.
+// Returns:
+// - The created user and an error if the input is invalid or the email or name is taken.
+func (s UserService) CreateUser(ctx context.Context, email string, name string, password string, role string) (*entity.User, error) {
+	return s.createUser(ctx, email, name, password, role, true)
+}
+
+// createUser validates and stores a new user account.
+func (s UserService) createUser(ctx context.Context, email string, name string, password string, role string, verified bool) (*entity.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	name = strings.Join(strings.Fields(name), " ")
+	if _, err := mail.ParseAddress(email); err != nil || name == "" || len(name) > maxNameLength || len(password) < 8 {
+		return nil, ErrInvalidUser
+	}
+
+	if role != entity.UserRole && role != entity.AdminRole {
+		return nil, ErrInvalidUser
+	}
+
+	existing, err := s.userCollection.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	// Players of games requiring sign in play under the name of their account, so no two accounts share one
+	if taken, err := s.userCollection.GetUserByNameKey(ctx, entity.NameKey(name)); err != nil {
+		return nil, err
+	} else if taken != nil {
+		return nil, ErrNameTaken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, ErrInvalidUser
+	}
+
+	user := entity.User{
+		Id:            primitive.NewObjectID(),
+		Email:         email,
+		EmailVerified: verified,
+		Name:          name,
+		NameKey:       entity.NameKey(name),
+		PasswordHash:  hash,
+		Role:          role,
+		Rating:        entity.DefaultRating,
+		CreatedAt:     time.Now(),
+	}
+
+	// The unique email and name indexes catch registrations racing past the checks above
+	err = s.userCollection.InsertUser(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		if taken, _ := s.userCollection.GetUserByEmail(ctx, email); taken == nil {
+			return nil, ErrNameTaken
+		}
+		return nil, ErrEmailTaken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Login checks a user's credentials.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - email: the email address of the user.
+// - password: the plain text password.
+// Returns:
+// - The user and ErrInvalidCredentials if the email or password is wrong.
+func (s UserService) Login(ctx context.Context, email string, password string) (*entity.User, error) {
+	user, err := s.userCollection.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// IssueToken creates a signed access token for a user.
+// Parameters:
+// - user: the user to issue the token for.
+// Returns:
+// - The token and an error if it could not be signed.
+func (s UserService) IssueToken(user entity.User) (string, error) {
+	now := time.Now()
+	return signToken(TokenClaims{
+		Subject:   user.Id.Hex(),
+		Name:      user.Name,
+		Role:      user.Role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.tokenTtl).Unix(),
+	}, s.secret)
+}
+
+// VerifyToken checks an access token and returns its claims.
+// Parameters:
+// - token: the token to verify.
+// Returns:
+// - The claims and ErrInvalidToken if the token cannot be trusted or is not an access token.
+func (s UserService) VerifyToken(token string) (*TokenClaims, error) {
+	claims, err := parseToken(token, s.secret, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Verification links are not sign ins
+	if claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// SendVerification emails a user a new link verifying their address, e.g. when the first one expired
+// or the account was registered before addresses were verified.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the user.
+// Returns:
+// - A RateLimitError if the user asked for too many emails and an error if the user cannot be loaded.
+func (s UserService) SendVerification(ctx context.Context, id primitive.ObjectID) error {
+	user, err := s.userCollection.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if user == nil || user.EmailVerified {
+		return nil
+	}
+
+	if wait := s.verifications.allow(id.Hex(), time.Now()); wait > 0 {
+		return RateLimitError{RetryAfter: wait}
+	}
+
+	go s.sendVerification(*user)
+	return nil
+}
+
+// sendVerification emails a user a link verifying their address, logging failures.
+// Parameters:
+// - user: the user.
+func (s UserService) sendVerification(user entity.User) {
+	token, err := s.verificationToken(user, time.Now())
+	if err != nil {
+		fmt.Println("failed to sign a verification link:", err)
+		return
+	}
+
+	body := "Open this link within a day to verify your email address:\n\n" + s.verificationLink(token) + "\n"
+	if err := s.mailService.Send(user.Email, "Verify your email address", body); err != nil {
+		fmt.Println("failed to send a verification email:", err)
+	}
+}
+
+// verificationLink builds the link of a verification email, pointing at the API rather than the frontend or media host.
+// Parameters:
+// - token: the verification token.
+// Returns:
+// - The link.
+func (s UserService) verificationLink(token string) string {
+	return s.verifyUrl + "?token=" + url.QueryEscape(token)
+}
+
+// verificationToken signs the token of a link verifying the current address of a user.
+// Parameters:
+// - user: the user.
+// - now: the current time.
+// Returns:
+// - The token and an error if it could not be signed.
+func (s UserService) verificationToken(user entity.User, now time.Time) (string, error) {
+	return signToken(TokenClaims{
+		Subject:   user.Id.Hex(),
+		Purpose:   verifyEmailPurpose,
+		Email:     user.Email,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(verificationTokenTtl).Unix(),
+	}, s.secret)
+}
+
+// VerifyEmail marks the address of a user as verified with the token of a verification link.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - token: the token of the link.
+// Returns:
+// - ErrInvalidToken if the token cannot be trusted, is not a verification token or the address changed since.
+func (s UserService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := parseToken(token, s.secret, time.Now())
+	if err != nil {
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil || claims.Purpose != verifyEmailPurpose {
+		return ErrInvalidToken
+	}
+
+	verified, err := s.userCollection.VerifyEmail(ctx, id, claims.Email)
+	if err != nil {
+		return err
+	}
+
+	if !verified {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// GetUserById retrieves the account of a user.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the user.
+// Returns:
+// - The user, or nil if the user does not exist, and an error if something goes wrong.
+func (s UserService) GetUserById(ctx context.Context, id primitive.ObjectID) (*entity.User, error) {
+	return s.userCollection.GetUserById(ctx, id)
+}
+
+// GetProfile retrieves the public profile of a user.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - id: the ObjectID of the user.
+// Returns:
+// - The profile, or nil if the user does not exist, and an error if something goes wrong.
+func (s UserService) GetProfile(ctx context.Context, id primitive.ObjectID) (*Profile, error) {
+	user, err := s.userCollection.GetUserById(ctx, id)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	return &Profile{
+		Id:          user.Id,
+		Name:        user.Name,
+		Rating:      user.Rating,
+		GamesPlayed: user.GamesPlayed,
+	}, nil
+}
+
+// UpdateRatings adjusts the ratings of the authenticated players of a finished game.
+// Parameters:
+// - ctx: the context bounding the database operations.
+// - result: the result of the game.
+// Returns:
+// - An error if the users could not be loaded or updated.
+func (s UserService) UpdateRatings(ctx context.Context, result entity.GameResult) error {
+	points := map[primitive.ObjectID]int{}
+	ids := []primitive.ObjectID{}
+	for _, player := range result.Players {
+		if player.UserId.IsZero() {
+			continue
+		}
+
+		// A user joining twice only counts with their best score
+		if previous, ok := points[player.UserId]; !ok {
+			ids = append(ids, player.UserId)
+			points[player.UserId] = player.Points
+		} else {
+			points[player.UserId] = max(previous, player.Points)
+		}
+	}
+
+	if len(ids) < 2 {
+		return nil
+	}
+
+	// Either every player's rating changes or none does
+	return s.transactor.Run(ctx, func(ctx context.Context) error {
+		users, err := s.userCollection.GetUsersByIds(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		placements := []ratedPlacement{}
+		for _, user := range users {
+			placements = append(placements, ratedPlacement{
+				UserId: user.Id,
+				Rating: user.Rating,
+				Points: points[user.Id],
+			})
+		}
+
+		for id, rating := range calculateRatings(placements) {
+			if err := s.userCollection.UpdateRating(ctx, id, rating); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
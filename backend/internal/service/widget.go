@@ -0,0 +1,190 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/contrib/websocket"
+	"quiz.com/quiz/internal/entity"
+)
+
+// Limits of the embeddable leaderboard widget
+const (
+	maxWidgetViewers      = 20 // Highest number of widgets watching a single game
+	widgetLeaderboardSize = 10 // Number of players shown on the widget
+)
+
+// Errors returned when a widget cannot watch a game.
+var (
+	ErrGameNotFound   = errors.New("game not found")
+	ErrTooManyViewers = errors.New("too many viewers")
+)
+
+// LeaderboardWidgetUpdate is the read-only view of a game sent to embedded leaderboard widgets.
+// It is sent as a plain JSON text message, so overlays need no knowledge of the game protocol.
+type LeaderboardWidgetUpdate struct {
+	Code      string             `json:"code"`      // Join code of the game
+	Quiz      string             `json:"quiz"`      // Name of the quiz being played
+	State     GameState          `json:"state"`     // Current state of the game
+	Question  int                `json:"question"`  // Index of the current question
+	Questions int                `json:"questions"` // Number of questions in the quiz
+	Players   int                `json:"players"`   // Number of players in the game
+	Top       []LeaderboardEntry `json:"top"`       // Best players, ordered by points
+
+	Prompt *WidgetQuestion `json:"prompt,omitempty"` // Current question while it is shown or revealed, for screens of headless games
+}
+
+// WidgetQuestion is the current question as shown on a big screen
+type WidgetQuestion struct {
+	Name    string                 `json:"name"`              // The text of the question
+	Choices []string               `json:"choices"`           // The text of each choice
+	Time    int                    `json:"time"`              // Time allotted to answer the question in seconds
+	Media   []entity.QuestionMedia `json:"media"`             // Images and sounds shown with the question
+	Correct []int                  `json:"correct,omitempty"` // Indexes of the correct choices, only once revealed
+}
+
+// WatchLeaderboard registers a connection to receive live leaderboard updates of a game.
+// The connection immediately receives the current leaderboard.
+// Parameters:
+// - code: the join code of the game.
+// - connection: the connection of the widget.
+// Returns:
+// - ErrGameNotFound if no game uses the code, or ErrTooManyViewers if the game has no room for another widget.
+func (c *NetService) WatchLeaderboard(code string, connection Connection) error {
+	game := c.getGameByCode(code)
+	if game == nil {
+		return ErrGameNotFound
+	}
+
+	var err error
+	game.run("watch", func() {
+		if len(game.viewers) >= maxWidgetViewers {
+			err = ErrTooManyViewers
+			return
+		}
+
+		game.viewers = append(game.viewers, connection)
+		game.sendWidgetUpdate(connection, game.newWidgetUpdate())
+	})
+
+	return err
+}
+
+// StopWatchingLeaderboard unregisters a widget connection from a game, if the game still exists.
+// Parameters:
+// - code: the join code of the game.
+// - connection: the connection of the widget.
+func (c *NetService) StopWatchingLeaderboard(code string, connection Connection) {
+	game := c.getGameByCode(code)
+	if game == nil {
+		return
+	}
+
+	game.run("unwatch", func() {
+		game.removeViewer(connection)
+	})
+}
+
+// removeViewer forgets a widget connection
+// Parameters:
+// - connection: the connection of the widget
+func (g *Game) removeViewer(connection Connection) {
+	filter := []Connection{}
+	for _, viewer := range g.viewers {
+		if viewer != connection {
+			filter = append(filter, viewer)
+		}
+	}
+
+	g.viewers = filter
+}
+
+// updateViewers sends the current leaderboard to every watching widget
+func (g *Game) updateViewers() {
+	if len(g.viewers) == 0 {
+		return
+	}
+
+	update := g.newWidgetUpdate()
+	for _, viewer := range g.viewers {
+		g.sendWidgetUpdate(viewer, update)
+	}
+}
+
+// sendWidgetUpdate sends an update to a single widget, dropping it if it cannot be reached
+// Parameters:
+// - connection: the connection of the widget
+// - update: the update to send
+func (g *Game) sendWidgetUpdate(connection Connection, update LeaderboardWidgetUpdate) {
+	bytes, err := json.Marshal(update)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err := connection.WriteMessage(websocket.TextMessage, bytes); err != nil {
+		g.removeViewer(connection)
+	}
+}
+
+// newWidgetUpdate builds the widget view of the game
+// Returns:
+// - LeaderboardWidgetUpdate: the current leaderboard and progress of the game
+func (g *Game) newWidgetUpdate() LeaderboardWidgetUpdate {
+	// Sort a copy, the order of the players is not ours to change
+	players := append([]*Player{}, g.Players...)
+	g.sortPlayers(players)
+
+	// Exams keep the standings to themselves until the end
+	shown := min(widgetLeaderboardSize, len(players))
+	if g.Settings.ExamMode && g.State != EndState {
+		shown = 0
+	}
+
+	top := []LeaderboardEntry{}
+	for _, player := range players[:shown] {
+		top = append(top, player.leaderboardEntry())
+	}
+
+	return LeaderboardWidgetUpdate{
+		Code:      g.Code,
+		Quiz:      g.Quiz.Name,
+		State:     g.State,
+		Question:  g.CurrentQuestion,
+		Questions: len(g.Quiz.Questions),
+		Players:   len(g.Players),
+		Top:       top,
+		Prompt:    g.newWidgetQuestion(),
+	}
+}
+
+// newWidgetQuestion builds the big screen view of the current question
+// Returns:
+// - *WidgetQuestion: the question, or nil if no question is being shown or revealed
+func (g *Game) newWidgetQuestion() *WidgetQuestion {
+	if g.State != PlayState && g.State != RevealState {
+		return nil
+	}
+
+	if g.CurrentQuestion < 0 || g.CurrentQuestion >= len(g.Quiz.Questions) {
+		return nil
+	}
+
+	question := g.getCurrentQuestion()
+	prompt := WidgetQuestion{
+		Name:    question.Name,
+		Choices: []string{},
+		Time:    question.Time,
+		Media:   sizeMedia(question.Media, entity.FullSize),
+	}
+
+	for i, choice := range question.Choices {
+		prompt.Choices = append(prompt.Choices, choice.Name)
+		if g.State == RevealState && choice.Correct {
+			prompt.Correct = append(prompt.Correct, i)
+		}
+	}
+
+	return &prompt
+}
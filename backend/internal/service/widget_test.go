@@ -0,0 +1,54 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"quiz.com/quiz/internal/config"
+)
+
+func TestLeaderboardWidget(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{})
+	game := newGame(fuzzQuiz(), &fakeConnection{}, c)
+	c.addGame(game)
+
+	if err := c.WatchLeaderboard("000000", &fakeConnection{}); err != ErrGameNotFound {
+		t.Errorf("expected an unknown code to be rejected, got %v", err)
+	}
+
+	widget := &fakeConnection{}
+	if err := c.WatchLeaderboard(game.Code, widget); err != nil {
+		t.Fatal(err)
+	}
+
+	game.Players = []*Player{
+		{Id: uuid.New(), Name: "bob", Points: 500, Connection: &fakeConnection{}},
+		{Id: uuid.New(), Name: "alice", Points: 1500, Connection: &fakeConnection{}},
+	}
+	game.ChangeState(RevealState)
+
+	if len(widget.messages) != 2 {
+		t.Fatalf("expected a snapshot and an update, got %d messages", len(widget.messages))
+	}
+
+	var update LeaderboardWidgetUpdate
+	if err := json.Unmarshal(widget.messages[1], &update); err != nil {
+		t.Fatalf("expected a plain JSON update, got %v", err)
+	}
+
+	if update.State != RevealState || len(update.Top) != 2 || update.Top[0].Name != "alice" {
+		t.Errorf("unexpected update %+v", update)
+	}
+
+	if game.Players[0].Name != "bob" {
+		t.Error("expected the widget not to reorder the players")
+	}
+
+	c.StopWatchingLeaderboard(game.Code, widget)
+	game.ChangeState(EndState)
+
+	if len(widget.messages) != 2 {
+		t.Errorf("expected no updates after unwatching, got %d messages", len(widget.messages))
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// ConnectionErrors counts the failed writes to an open connection
+type ConnectionErrors struct {
+	Address     string `json:"address,omitempty"` // Address of the client, empty if unknown
+	WriteErrors int    `json:"writeErrors"`       // Writes that failed, timeouts included
+	Timeouts    int    `json:"timeouts"`          // Writes that ran past the write deadline
+}
+
+// ConnectionMetrics reports how reliably packets reach the clients
+type ConnectionMetrics struct {
+	WriteErrors int64              `json:"writeErrors"` // Failed writes since the server started
+	Timeouts    int64              `json:"timeouts"`    // Timed out writes since the server started, each closing its connection
+	Connections []ConnectionErrors `json:"connections"` // Open connections that had failed writes
+}
+
+// setWriteDeadline bounds the next write to a connection by the configured timeout, if the connection supports deadlines
+// Parameters:
+// - connection: the connection about to be written to
+func (c *NetService) setWriteDeadline(connection Connection) {
+	con, ok := connection.(deadlined)
+	if !ok || c.config.WsWriteTimeout <= 0 {
+		return
+	}
+
+	con.SetWriteDeadline(time.Now().Add(c.config.WsWriteTimeout))
+}
+
+// recordWriteError counts a failed write. A write that timed out means the client stopped reading,
+// so its connection is closed and the client is handled as disconnected.
+// Parameters:
+// - connection: the connection the write failed on
+// - err: the error of the write
+func (c *NetService) recordWriteError(connection Connection, err error) {
+	var netErr net.Error
+	timeout := errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+
+	c.writeErrorsMu.Lock()
+	counts, ok := c.writeErrors[connection]
+	if !ok {
+		counts = &ConnectionErrors{}
+		if con, ok := connection.(addressable); ok {
+			counts.Address = con.IP()
+		}
+		c.writeErrors[connection] = counts
+	}
+	counts.WriteErrors++
+	c.totalWriteErrors++
+	if timeout {
+		counts.Timeouts++
+		c.totalTimeouts++
+	}
+	c.writeErrorsMu.Unlock()
+
+	if timeout {
+		fmt.Println("closing connection after a write timed out")
+		connection.Close()
+	}
+}
+
+// forgetWriteErrors drops the counters of a closed connection
+// Parameters:
+// - connection: the closed connection
+func (c *NetService) forgetWriteErrors(connection Connection) {
+	c.writeErrorsMu.Lock()
+	delete(c.writeErrors, connection)
+	c.writeErrorsMu.Unlock()
+}
+
+// GetConnectionMetrics reports the failed writes to clients.
+// Returns:
+// - The totals since the server started and the counters of the open connections that had failed writes.
+func (c *NetService) GetConnectionMetrics() ConnectionMetrics {
+	c.writeErrorsMu.Lock()
+	defer c.writeErrorsMu.Unlock()
+
+	metrics := ConnectionMetrics{
+		WriteErrors: c.totalWriteErrors,
+		Timeouts:    c.totalTimeouts,
+		Connections: []ConnectionErrors{},
+	}
+	for _, counts := range c.writeErrors {
+		metrics.Connections = append(metrics.Connections, *counts)
+	}
+
+	return metrics
+}
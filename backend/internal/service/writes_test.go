@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"quiz.com/quiz/internal/config"
+)
+
+// stalledConnection fails every write with an error, recording the deadline it was given
+type stalledConnection struct {
+	fakeConnection
+	err      error
+	deadline time.Time
+}
+
+func (c *stalledConnection) WriteMessage(messageType int, data []byte) error {
+	return c.err
+}
+
+func (c *stalledConnection) SetWriteDeadline(t time.Time) error {
+	c.deadline = t
+	return nil
+}
+
+func TestWriteTimeout(t *testing.T) {
+	c := Net(NetOptions{}, config.Config{WsWriteTimeout: time.Second})
+	stalled := &stalledConnection{err: os.ErrDeadlineExceeded}
+
+	if err := c.SendPacket(stalled, TickPacket{Tick: 1}); err == nil {
+		t.Fatalf("expected the timed out write to fail")
+	}
+	if stalled.deadline.IsZero() || !stalled.closed {
+		t.Errorf("expected a write deadline and the connection to be closed after the timeout")
+	}
+
+	broken := &stalledConnection{err: errors.New("broken pipe")}
+	c.SendPacket(broken, TickPacket{Tick: 1})
+	if broken.closed {
+		t.Errorf("expected only timeouts to close the connection")
+	}
+
+	metrics := c.GetConnectionMetrics()
+	if metrics.WriteErrors != 2 || metrics.Timeouts != 1 || len(metrics.Connections) != 2 {
+		t.Errorf("expected two failed writes, one timed out, got %+v", metrics)
+	}
+
+	c.OnDisconnect(stalled)
+	if metrics := c.GetConnectionMetrics(); len(metrics.Connections) != 1 || metrics.WriteErrors != 2 {
+		t.Errorf("expected the counters of the closed connection to be dropped but the totals kept, got %+v", metrics)
+	}
+}
@@ -1,19 +1,130 @@
 export interface Quiz {
     id: string;
     name: string;
+    ownerId: string;
+    template: boolean;
+    defaultTime: number;
+    totalPoints: number;
+    grades: GradeBoundary[];
+    attempts: AttemptPolicy;
+    tieBreaker: QuizQuestion | null;
     questions: QuizQuestion[];
+    language: string;
+    translationOf: string;
+    draft: boolean;
+    updatedAt: string;
+}
+
+export interface AttemptPolicy {
+    max: number;
+    keep: "" | "best" | "latest" | "first";
+}
+
+export interface Quota {
+    quizzes: number;
+    players: number;
+    gamesPerDay: number;
+}
+
+export interface Usage {
+    quota: Quota;
+    quizzes: number;
+    gamesToday: number;
+}
+
+export interface Progress {
+    quizzes: QuizProgress[];
+}
+
+export interface QuizProgress {
+    quizId: string;
+    quizName: string;
+    policy: AttemptPolicy;
+    attempts: Attempt[];
+    kept: Attempt | null;
+}
+
+export interface Attempt {
+    number: number;
+    resultId: string;
+    endedAt: string;
+    points: number;
+    maxPoints: number;
+    correct: number;
+    grade: PlayerGrade | null;
+    counted: boolean;
+}
+
+export interface GradeBoundary {
+    name: string;
+    minPercent: number;
+    pass: boolean;
+}
+
+export interface PlayerGrade {
+    percent: number;
+    name: string;
+    passed: boolean;
 }
 
 export interface Player {
     id: string;
     name: string;
+    avatar: number;
+    color: number;
+    idle?: boolean;
+    disconnected?: boolean; // Lost the connection and may still resume
+    eliminated?: boolean;
+    flags?: number;
+    done?: number;
+    total?: number;
 }
 
 export interface QuizQuestion {
     id: string;
     name: string;
+    type?: string;
     time: number;
+    maxPoints?: number;
     choices: QuizChoice[];
+    hostNotes?: string;
+    hint?: string;
+    media?: QuestionMedia[];
+    stats?: QuestionStats;
+}
+
+export interface QuestionStats {
+    answered: number;
+    correct: number;
+    difficulty: number;
+}
+
+export interface QuestionMedia {
+    type: "image" | "audio" | "video";
+    mediaId?: string;
+    url: string;
+    hash?: string;
+    sizes?: Record<"thumbnail" | "medium" | "full", string>;
+}
+
+export interface Media {
+    id: string;
+    name: string;
+    type: "image" | "audio" | "video";
+    contentType: string;
+    size: number;
+    hash: string;
+    variants: MediaVariant[];
+    createdAt: string;
+}
+
+export interface MediaVariant {
+    size: "thumbnail" | "medium" | "full";
+    width: number;
+    height: number;
+    contentType: string;
+    bytes: number;
+    hash: string;
 }
 
 export interface QuizChoice {
@@ -22,4 +133,83 @@ export interface QuizChoice {
     correct: boolean;
 }
 
-export const COLORS = ["bg-pink-400", "bg-blue-400", "bg-yellow-400", "bg-purple-400"];
\ No newline at end of file
+export interface ImportIssue {
+    item: number;
+    name: string;
+    reason: string;
+}
+
+export const COLORS = ["bg-pink-400", "bg-blue-400", "bg-yellow-400", "bg-purple-400"];
+
+export interface ItemAnalysis {
+    games: number;
+    players: number;
+    items: ItemStatistic[];
+}
+
+export interface ItemStatistic {
+    questionId: string;
+    name: string;
+    answered: number;
+    difficulty: number;
+    discrimination: number;
+    choices: ChoiceStatistic[];
+}
+
+export interface ChoiceStatistic {
+    name: string;
+    correct: boolean;
+    picks: number;
+    share: number;
+    topPicks: number;
+    bottomPicks: number;
+}
+
+export interface DuplicateWarning {
+    questionId: string;
+    question: string;
+    quizId: string;
+    quizName: string;
+    duplicateId: string;
+    duplicate: string;
+    similarity: number;
+}
+
+export interface LintWarning {
+    questionId: string;
+    question: number;
+    choice: number;
+    kind: string;
+    message: string;
+}
+
+export interface Comment {
+    id: string;
+    quizId: string;
+    questionId: string;
+    userId: string;
+    author: string;
+    text: string;
+    createdAt: string;
+    resolved: boolean;
+    resolvedBy: string;
+    resolvedAt: string;
+}
+
+export type QuizPermission = "view" | "host" | "edit";
+
+export interface QuizShare {
+    userId: string;
+    permission: QuizPermission;
+}
+
+export interface QuizShareLink {
+    token: string;
+    permission: QuizPermission;
+    expiresAt: string;
+}
+
+export interface QuizSharing {
+    shares: QuizShare[];
+    links: QuizShareLink[];
+}
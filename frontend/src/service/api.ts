@@ -1,8 +1,25 @@
-import type { Quiz } from "../model/quiz";
+import type { Comment, DuplicateWarning, ImportIssue, ItemAnalysis, LintWarning, Media, Progress, Quiz, Usage } from "../model/quiz";
+import type { HostGameState, JoinCheck } from "./net";
+
+// Where the access token of the signed in user is kept
+const ACCESS_TOKEN_KEY = "accessToken";
+
+// Returns the access token of the signed in user, or null if nobody signed in
+export function getAccessToken(): string | null {
+    return localStorage.getItem(ACCESS_TOKEN_KEY);
+}
+
+// Returns the headers that authenticate a request, empty if nobody signed in
+function authHeaders(token: string | null): Record<string, string> {
+    return token ? { "Authorization": `Bearer ${token}` } : {};
+}
 
 export class ApiService {
-    async getQuizById(id: string): Promise<Quiz | null> {
-        let response = await fetch(`http://localhost:3000/api/quizzes/${id}`);
+    // Host notes are only included for users who may edit the quiz
+    async getQuizById(id: string, token: string | null = getAccessToken()): Promise<Quiz | null> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${id}`, {
+            headers: authHeaders(token)
+        });
         if (!response.ok) {
             return null;
         }
@@ -22,19 +39,257 @@ export class ApiService {
         return json;
     }
 
-    async saveQuiz(quizId: string, quiz: Quiz) {
+    async saveQuiz(quizId: string, quiz: Quiz, token: string | null = getAccessToken()): Promise<DuplicateWarning[]> {
         let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}`, {
             method: "PUT",
             body: JSON.stringify(quiz),
             headers: {
-                "Content-Type": "application/json"
+                "Content-Type": "application/json",
+                ...authHeaders(token)
             }
         });
 
         if (!response.ok) {
             alert("Failed to save quiz!");
-            return;
+            return [];
+        }
+
+        let json = await response.json();
+        return json.warnings;
+    }
+
+    async lintQuiz(quizId: string, quiz: Quiz): Promise<LintWarning[]> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}/lint`, {
+            method: "POST",
+            body: JSON.stringify(quiz),
+            headers: {
+                "Content-Type": "application/json"
+            }
+        });
+
+        if (!response.ok) {
+            return [];
+        }
+
+        return await response.json();
+    }
+
+    async setQuestionTimes(quizId: string, time: number): Promise<Quiz | null> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}/time`, {
+            method: "PUT",
+            body: JSON.stringify({ time: time }),
+            headers: {
+                "Content-Type": "application/json"
+            }
+        });
+
+        if (!response.ok) {
+            alert("Failed to set question times!");
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    async uploadMedia(file: File, token: string): Promise<{ media: Media, url: string } | null> {
+        let form = new FormData();
+        form.append("file", file);
+
+        let response = await fetch("http://localhost:3000/api/media", {
+            method: "POST",
+            body: form,
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            alert("Failed to upload media!");
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    async importQuiz(file: File, token: string, name?: string): Promise<{ quiz: Quiz, skipped: ImportIssue[] } | null> {
+        let form = new FormData();
+        form.append("file", file);
+        if (name) {
+            form.append("name", name);
+        }
+
+        let response = await fetch("http://localhost:3000/api/quizzes/import", {
+            method: "POST",
+            body: form,
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            alert("Failed to import quiz!");
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    async getHostState(code: string, token: string): Promise<HostGameState | null> {
+        let response = await fetch(`http://localhost:3000/api/host/games/${code}/state`, {
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    // Returns the game of a join code, or why it cannot be joined
+    async checkJoinCode(code: string): Promise<JoinCheck | string> {
+        let response = await fetch(`http://localhost:3000/api/games/${encodeURIComponent(code)}/exists`);
+        if (response.status == 404) {
+            return "There is no game with this code";
+        }
+        if (response.status == 429) {
+            return `Too many tries, wait ${response.headers.get("Retry-After") ?? 60} seconds`;
+        }
+        if (!response.ok) {
+            return "Failed to check the game code";
+        }
+
+        return await response.json();
+    }
+
+    async getItemAnalysis(quizId: string, token: string): Promise<ItemAnalysis | null> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}/item-analysis`, {
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    async getTemplates(token: string): Promise<Quiz[]> {
+        let response = await fetch(`http://localhost:3000/api/templates`, {
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            return [];
+        }
+
+        return await response.json();
+    }
+
+    async duplicateQuiz(quizId: string, token: string): Promise<Quiz | null> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}/duplicate`, {
+            method: "POST",
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    async translateQuiz(quizId: string, lang: string, token: string): Promise<Quiz | null> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}/translate?lang=${encodeURIComponent(lang)}`, {
+            method: "POST",
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    async getComments(quizId: string, token: string, resolved = false): Promise<Comment[]> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}/comments?resolved=${resolved}`, {
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            return [];
+        }
+
+        return await response.json();
+    }
+
+    async addComment(quizId: string, questionId: string, text: string, token: string): Promise<Comment | null> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}/questions/${questionId}/comments`, {
+            method: "POST",
+            headers: {
+                "Content-Type": "application/json",
+                "Authorization": `Bearer ${token}`
+            },
+            body: JSON.stringify({ text })
+        });
+
+        if (!response.ok) {
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    async resolveComment(quizId: string, commentId: string, token: string): Promise<boolean> {
+        let response = await fetch(`http://localhost:3000/api/quizzes/${quizId}/comments/${commentId}/resolve`, {
+            method: "PUT",
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        return response.ok;
+    }
+
+    async getProgress(token: string): Promise<Progress | null> {
+        let response = await fetch(`http://localhost:3000/api/me/progress`, {
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            return null;
+        }
+
+        return await response.json();
+    }
+
+    async getUsage(token: string): Promise<Usage | null> {
+        let response = await fetch(`http://localhost:3000/api/me/usage`, {
+            headers: {
+                "Authorization": `Bearer ${token}`
+            }
+        });
+
+        if (!response.ok) {
+            return null;
         }
+
+        return await response.json();
     }
 }
 
@@ -0,0 +1,31 @@
+import type { Writable } from "svelte/store";
+
+// Counts down the seconds to a server timestamp, so screens need no tick packets to show a smooth timer
+export class Countdown {
+    private endsAt = 0;
+    private offset = 0;
+    private interval: number | null = null;
+
+    constructor(private remaining: Writable<number>){}
+
+    // Counts down to endsAt, in server milliseconds; offset is how far the local clock is ahead of the server's
+    set(endsAt: number, offset: number = this.offset){
+        this.endsAt = endsAt;
+        this.offset = offset;
+        this.update();
+
+        if (this.interval == null && endsAt > 0) {
+            this.interval = setInterval(() => this.update(), 250);
+        }
+    }
+
+    private update(){
+        let left = Math.max(0, Math.ceil((this.endsAt - (Date.now() - this.offset)) / 1000));
+        this.remaining.set(left);
+
+        if (left == 0 && this.interval != null) {
+            clearInterval(this.interval);
+            this.interval = null;
+        }
+    }
+}
@@ -0,0 +1,51 @@
+import { writable, type Writable } from "svelte/store";
+
+export interface EditorPresence {
+    userId: string;
+    name: string;
+    questionId: string;
+}
+
+export interface QuestionChange {
+    questionId: string;
+    kind: "added" | "edited" | "removed";
+    name: string;
+}
+
+export interface EditorMessage {
+    type: "presence" | "changes";
+    editors?: EditorPresence[];
+    by?: string;
+    changes?: QuestionChange[];
+}
+
+// Shows who else is editing a quiz and what they saved, so nobody overwrites changes blindly
+export class EditorSession {
+    private webSocket: WebSocket;
+
+    editors: Writable<EditorPresence[]> = writable([]);
+    changes: Writable<{ by: string, changes: QuestionChange[] } | null> = writable(null);
+
+    constructor(quizId: string, token: string) {
+        this.webSocket = new WebSocket(`ws://localhost:3000/ws/editor/${quizId}?token=${encodeURIComponent(token)}`);
+        this.webSocket.onmessage = (event) => {
+            let message: EditorMessage = JSON.parse(event.data);
+            if (message.type == "presence") {
+                this.editors.set(message.editors ?? []);
+            } else if (message.type == "changes") {
+                this.changes.set({ by: message.by ?? "", changes: message.changes ?? [] });
+            }
+        };
+    }
+
+    // Tells the other editors which question is open, null for none
+    focus(questionId: string | null) {
+        if (this.webSocket.readyState != WebSocket.OPEN) return;
+
+        this.webSocket.send(JSON.stringify({ type: "focus", questionId: questionId ?? "" }));
+    }
+
+    close() {
+        this.webSocket.close();
+    }
+}
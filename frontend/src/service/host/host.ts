@@ -1,5 +1,7 @@
-import { writable, type Writable } from "svelte/store";
-import { NetService, PacketTypes, type Packet, type HostGamePacket, type PlayerJoinPacket, GameState, type ChangeGameStatePacket, type TickPacket, type QuestionShowPacket, type LeaderboardPacket, type LeaderboardEntry, type PlayerDisconnectPacket } from "../net";
+import { get, writable, type Writable } from "svelte/store";
+import { NetService, PacketTypes, type Packet, type HostGamePacket, type PlayerJoinPacket, GameState, type ChangeGameStatePacket, type TickPacket, type QuestionShowPacket, type LeaderboardPacket, type LeaderboardEntry, type PlayerDisconnectPacket, type GamePausePacket, type GameReportPacket, type AchievementPacket, type GameEndPacket, type TournamentStandingsPacket, type LobbyVotesPacket, type NextQuestionPreviewPacket, type SendInvitationsPacket, type InvitationStatusPacket, type Invitation, type ResultLinkPacket, type PreloadPacket, type LobbyStatsPacket, type PlaylistPacket, type GameSettings, type GameSettingsPacket, AnswerChangeMode, ScoringMode, type PlayerIdlePacket, type PlayerFlagPacket, type PacedProgressPacket, type GameWarningPacket, type KickPlayerPacket, type MediaCuePacket, type BuzzOrderPacket, type Buzz, type JudgeBuzzPacket, type TieBreakerPacket, type MatchLeaderboardPacket, type EliminationPacket, CloseCodes, type ChoiceSlot, type StateDigestPacket, type StateSnapshotPacket } from "../net";
+import { preload } from "../preload";
+import { Countdown } from "../countdown";
 import type { Player, QuizQuestion } from "../../model/quiz";
 
 export const state: Writable<GameState> = writable(GameState.Lobby);
@@ -8,25 +10,126 @@ export const gameCode: Writable<string | null> = writable(null);
 export const tick: Writable<number> = writable(0);
 export const leaderboard: Writable<LeaderboardEntry[]> = writable([]);
 export const currentQuestion: Writable<QuizQuestion | null> = writable(null);
+export const progress: Writable<{ index: number, total: number } | null> = writable(null);
+export const slots: Writable<ChoiceSlot[]> = writable([]);
+export const pauseReason: Writable<string | null> = writable(null);
+export const warning: Writable<string | null> = writable(null);
+export const report: Writable<GameReportPacket | null> = writable(null);
+export const achievements: Writable<AchievementPacket[]> = writable([]);
+export const gameEnd: Writable<GameEndPacket | null> = writable(null);
+export const tournament: Writable<TournamentStandingsPacket | null> = writable(null);
+export const lobbyVotes: Writable<number[]> = writable([]);
+export const nextQuestion: Writable<NextQuestionPreviewPacket | null> = writable(null);
+export const invitations: Writable<Invitation[]> = writable([]);
+export const resultLink: Writable<ResultLinkPacket | null> = writable(null);
+export const lobbyStats: Writable<LobbyStatsPacket | null> = writable(null);
+export const playlist: Writable<PlaylistPacket | null> = writable(null);
+export const buzzes: Writable<Buzz[]> = writable([]);
+export const tieBreaker: Writable<TieBreakerPacket | null> = writable(null);
+// Standings of a match after its last finished round, null until a round ended
+export const matchLeaderboard: Writable<MatchLeaderboardPacket | null> = writable(null);
+// Players still in an elimination game, null until the first elimination
+export const activePlayers: Writable<{ active: number, total: number } | null> = writable(null);
+export const settings: Writable<GameSettings> = writable({ answerChange: AnswerChangeMode.None, scoring: ScoringMode.Standard, penalty: 500, confidenceMode: false, allowNegative: false, hintCost: 0, idleLimit: 0, removeIdle: false, anonymizeResults: false, examMode: false, playerPaced: false, buzzerMode: false, tieBreaker: false, matchRounds: 0, eliminationPercent: 0, allowedNetworks: [], allowedCountries: [], requireSignIn: false, signInDomains: [] });
 
 export class HostGame {
     private net: NetService;
+    private countdown = new Countdown(tick);
 
     constructor(){
         this.net = new NetService();
         this.net.connect();
         this.net.onPacket(p => this.onPacket(p));
+        this.net.onClose((code, reason) => {
+            if (code != CloseCodes.Normal) warning.set(reason || "Lost the connection to the game");
+        });
     }
 
-    hostQuiz(quizId: string){
+    hostQuiz(quizId: string, tournamentId?: string, token?: string){
         let packet: HostGamePacket = {
             id: PacketTypes.HostGame,
             quizId: quizId,
+            tournamentId: tournamentId,
+            token: token,
         }
 
         this.net.sendPacket(packet);
     }
 
+    // Takes back a lobby the host left, e.g. after it hibernated, waking it if needed
+    resumeGame(code: string, token: string){
+        let packet: HostGamePacket = {
+            id: PacketTypes.HostGame,
+            quizId: "",
+            code: code,
+            token: token,
+        }
+
+        this.net.sendPacket(packet);
+    }
+
+    hostPlaylist(quizIds: string[], resetPoints: boolean){
+        let packet: HostGamePacket = {
+            id: PacketTypes.HostGame,
+            quizId: quizIds[0],
+            playlist: quizIds.slice(1),
+            resetPoints: resetPoints,
+        }
+
+        this.net.sendPacket(packet);
+    }
+
+    invite(emails: string[]){
+        let packet: SendInvitationsPacket = {
+            id: PacketTypes.SendInvitations,
+            emails: emails,
+        }
+
+        this.net.sendPacket(packet);
+    }
+
+    updateSettings(settings: GameSettings){
+        let packet: GameSettingsPacket = {
+            id: PacketTypes.GameSettings,
+            settings: settings,
+        }
+
+        this.net.sendPacket(packet);
+    }
+
+    kick(playerId: string, ban: boolean){
+        let packet: KickPlayerPacket = {
+            id: PacketTypes.KickPlayer,
+            playerId: playerId,
+            ban: ban,
+        };
+
+        this.net.sendPacket(packet);
+    }
+
+    // Tells the players to play or pause the media of the current question at the host's position
+    cueMedia(media: number, action: "play" | "pause", position: number){
+        let packet: MediaCuePacket = {
+            id: PacketTypes.MediaCue,
+            action: action,
+            media: media,
+            position: position,
+        };
+
+        this.net.sendPacket(packet);
+    }
+
+    // Awards or denies points to a player who buzzed and answered out loud
+    judgeBuzz(playerId: string, award: boolean){
+        let packet: JudgeBuzzPacket = {
+            id: PacketTypes.JudgeBuzz,
+            playerId: playerId,
+            award: award,
+        };
+
+        this.net.sendPacket(packet);
+    }
+
     start(){
         this.net.sendPacket({ id: PacketTypes.StartGame });
     }
@@ -41,24 +144,63 @@ export class HostGame {
             case PacketTypes.ChangeGameState:{
                 let data = packet as ChangeGameStatePacket;
                 state.set(data.state);
+                this.countdown.set(data.endsAt);
+                break;
+            }
+            case PacketTypes.StateDigest:{
+                let data = packet as StateDigestPacket;
+                let shown = get(progress);
+                if (data.state != get(state) || (data.question >= 0 && shown?.index != data.question)) {
+                    this.net.sendPacket({ id: PacketTypes.ResyncRequest });
+                }
+                this.countdown.set(data.endsAt);
+                break;
+            }
+            case PacketTypes.StateSnapshot:{
+                let data = packet as StateSnapshotPacket;
+                if (data.host && data.question >= 0) {
+                    currentQuestion.set(data.host.quiz.questions[data.question]);
+                    progress.set({ index: data.question, total: data.host.quiz.questions.length });
+                }
+                this.countdown.set(data.endsAt);
+                state.set(data.state);
                 break;
             }
             case PacketTypes.PlayerJoin:{
                 let data = packet as PlayerJoinPacket;
                 console.log(data)
-                players.update(p => [...p, data.player]);
+                // Players resuming their session take their place back
+                players.update(p => [...p.filter(v => v.id != data.player.id), data.player]);
                 break;
             }
 
             case PacketTypes.Tick:{
+                // Ticks are authoritative, they also tell how far the local clock is off
                 let data = packet as TickPacket;
-                tick.set(data.tick);
+                this.countdown.set(data.endsAt, Date.now() - (data.endsAt - data.tick * 1000));
                 break;
             }
 
             case PacketTypes.QuestionShow:{
                 let data = packet as QuestionShowPacket;
                 currentQuestion.set(data.question);
+                buzzes.set([]);
+                matchLeaderboard.set(null);
+                this.countdown.set(data.endsAt, Date.now() - data.serverTime);
+                progress.set({ index: data.index, total: data.total });
+                slots.set(data.slots);
+                break;
+            }
+            case PacketTypes.BuzzOrder:{
+                buzzes.set((packet as BuzzOrderPacket).buzzes);
+                break;
+            }
+            case PacketTypes.TieBreaker:{
+                tieBreaker.set(packet as TieBreakerPacket);
+                break;
+            }
+            case PacketTypes.MatchLeaderboard:{
+                matchLeaderboard.set(packet as MatchLeaderboardPacket);
                 break;
             }
             case PacketTypes.Leaderboard:{
@@ -68,7 +210,102 @@ export class HostGame {
             }
             case PacketTypes.PlayerDisconnect: {
                 let data = packet as PlayerDisconnectPacket;
-                players.update(v => v.filter(p => p.id != data.playerId));
+                if (data.reconnectable) {
+                    players.update(v => v.map(p => p.id == data.playerId ? { ...p, disconnected: true } : p));
+                } else {
+                    players.update(v => v.filter(p => p.id != data.playerId));
+                }
+                break;
+            }
+            case PacketTypes.PlayerIdle: {
+                let data = packet as PlayerIdlePacket;
+                if (data.removed) {
+                    players.update(v => v.filter(p => p.id != data.playerId));
+                } else {
+                    players.update(v => v.map(p => p.id == data.playerId ? { ...p, idle: data.idle } : p));
+                }
+                break;
+            }
+            case PacketTypes.Elimination: {
+                let data = packet as EliminationPacket;
+                players.update(v => v.map(p => data.eliminated.includes(p.id) ? { ...p, eliminated: true } : p));
+                activePlayers.set({ active: data.active, total: data.total });
+                break;
+            }
+            case PacketTypes.PlayerFlag: {
+                let data = packet as PlayerFlagPacket;
+                players.update(v => v.map(p => p.id == data.playerId ? { ...p, flags: data.count } : p));
+                break;
+            }
+            case PacketTypes.GameWarning: {
+                let data = packet as GameWarningPacket;
+                warning.set(data.message);
+                break;
+            }
+            case PacketTypes.PacedProgress: {
+                let data = packet as PacedProgressPacket;
+                players.update(v => v.map(p => p.id == data.playerId ? { ...p, done: data.done, total: data.total } : p));
+                break;
+            }
+            case PacketTypes.GamePause: {
+                let data = packet as GamePausePacket;
+                pauseReason.set(data.paused ? data.reason : null);
+                break;
+            }
+            case PacketTypes.GameReport: {
+                report.set(packet as GameReportPacket);
+                break;
+            }
+            case PacketTypes.Achievement: {
+                let data = packet as AchievementPacket;
+                achievements.update(a => [...a, data]);
+                break;
+            }
+            case PacketTypes.GameEnd: {
+                gameEnd.set(packet as GameEndPacket);
+                break;
+            }
+            case PacketTypes.TournamentStandings: {
+                tournament.set(packet as TournamentStandingsPacket);
+                break;
+            }
+            case PacketTypes.LobbyVotes: {
+                let data = packet as LobbyVotesPacket;
+                lobbyVotes.set(data.votes);
+                break;
+            }
+            case PacketTypes.NextQuestionPreview: {
+                nextQuestion.set(packet as NextQuestionPreviewPacket);
+                break;
+            }
+            case PacketTypes.InvitationStatus: {
+                let data = packet as InvitationStatusPacket;
+                let ids = new Set(data.invitations.map(i => i.id));
+                invitations.update(v => [...v.filter(i => !ids.has(i.id)), ...data.invitations]);
+                break;
+            }
+            case PacketTypes.ResultLink: {
+                resultLink.set(packet as ResultLinkPacket);
+                break;
+            }
+            case PacketTypes.Preload: {
+                preload((packet as PreloadPacket).media);
+                break;
+            }
+            case PacketTypes.LobbyStats: {
+                lobbyStats.set(packet as LobbyStatsPacket);
+                break;
+            }
+            case PacketTypes.GameSettings: {
+                settings.set((packet as GameSettingsPacket).settings);
+                break;
+            }
+            case PacketTypes.Playlist: {
+                let data = packet as PlaylistPacket;
+                playlist.set(data);
+                if (data.leaderboard.length > 0) {
+                    leaderboard.set(data.leaderboard);
+                }
                 break;
             }
         }
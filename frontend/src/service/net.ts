@@ -1,4 +1,4 @@
-import type { Player, QuizQuestion } from "../model/quiz";
+import type { Player, PlayerGrade, QuestionMedia, Quiz, QuizQuestion } from "../model/quiz";
 
 export enum PacketTypes {
     Connect,
@@ -11,7 +11,62 @@ export enum PacketTypes {
     Answer,
     PlayerReveal,
     Leaderboard,
-    PlayerDisconnect
+    PlayerDisconnect,
+    GamePause,
+    Ping,
+    Pong,
+    PlayerQuestion,
+    GameReport,
+    Achievement,
+    GameEnd,
+    Record,
+    TournamentStandings,
+    LobbyVote,
+    LobbyVotes,
+    NextQuestionPreview,
+    SendInvitations,
+    InvitationStatus,
+    ResultLink,
+    Preload,
+    LobbyStats,
+    Playlist,
+    GameSettings,
+    GameInfo,
+    PlayerIdle,
+    AnswerAck,
+    Resend,
+    StateDigest,
+    ResyncRequest,
+    StateSnapshot,
+    FocusLost,
+    PlayerFlag,
+    PacedProgress,
+    GameWarning,
+    KickPlayer,
+    Session,
+    MediaCue,
+    Buzz,
+    BuzzOrder,
+    JudgeBuzz,
+    TieBreaker,
+    MatchLeaderboard,
+    PlayerEliminated,
+    Elimination,
+    LeaveGame,
+    HintRequest,
+    Hint
+}
+
+export enum AnswerChangeMode {
+    None,
+    Once,
+    SecondGuess
+}
+
+export enum ScoringMode {
+    Standard,
+    Penalty,
+    Accuracy
 }
 
 export enum GameState {
@@ -19,19 +74,35 @@ export enum GameState {
     Play,
     Intermission,
     Reveal,
-    End
+    End,
+    TieBreaker
 }
 
 export interface Packet {
     id: PacketTypes;
 }
 
+export interface PacketEnvelope {
+    type: string; // Name of the packet, e.g. QuestionShow
+    at: number; // Server time in milliseconds the packet was sent at
+    game?: string; // ID of the game the connection takes part in
+    seq: number; // Number of the packet among all packets sent on the connection
+    data: any; // The packet itself
+}
+
 export interface HostGamePacket extends Packet {
     quizId: string;
+    tournamentId?: string;
+    playlist?: string[];
+    resetPoints?: boolean;
+    token?: string;
+    shareToken?: string;
+    code?: string; // Join code of a lobby of the host to take back, e.g. after it hibernated
 }
 
 export interface ChangeGameStatePacket extends Packet {
     state: GameState;
+    endsAt: number;
 }
 
 export interface PlayerJoinPacket extends Packet {
@@ -40,38 +111,519 @@ export interface PlayerJoinPacket extends Packet {
 
 export interface TickPacket extends Packet {
     tick: number;
+    endsAt: number;
 }
 
 export interface PlayerDisconnectPacket extends Packet {
     playerId: string;
+    reconnectable: boolean; // The player lost their connection and may still resume, false if they are gone for good
 }
 
 export interface ConnectPacket extends Packet {
     code: string;
     name: string;
+    avatar: number;
+    color: number;
+    device?: "phone" | "tablet" | "desktop";
+    token?: string;
 }
 
 export interface QuestionShowPacket extends Packet {
     question: QuizQuestion;
+    serverTime: number;
+    endsAt: number;
+    index: number;
+    total: number;
+    slots: ChoiceSlot[];
+    name?: string;
+    choiceNames?: string[];
+}
+
+export interface ChoiceSlot {
+    color: string;
+    shape: string;
+}
+
+// Tailwind classes and symbols of the choice slots the server assigns
+export const SLOT_COLORS: Record<string, string> = {
+    pink: "bg-pink-400",
+    blue: "bg-blue-400",
+    yellow: "bg-yellow-400",
+    purple: "bg-purple-400",
+    green: "bg-green-500",
+    orange: "bg-orange-400",
+};
+
+export const SLOT_SHAPES: Record<string, string> = {
+    triangle: "▲",
+    diamond: "◆",
+    circle: "●",
+    square: "■",
+    star: "★",
+    heart: "♥",
+};
+
+// Kinds of answers; multiple choice questions take index answers
+export type AnswerType = "index" | "indices" | "text" | "number" | "coordinates" | "ordering";
+
+export interface AnswerPayload {
+    index?: number;
+    indices?: number[];
+    text?: string;
+    number?: number;
+    coordinates?: { x: number, y: number };
+    ordering?: number[];
 }
 
+// How sure a player is of their answer, weighing its points in confidence mode
+export type Confidence = "low" | "medium" | "high";
+
 export interface QuestionAnswerPacket extends Packet {
     question: number;
+    type: AnswerType;
+    payload: AnswerPayload;
+    confidence?: Confidence;
 }
 
 export interface PlayerRevealPacket extends Packet {
     points: number;
+    averageTime: number;
+    endsAt: number;
 }
 
 export interface LeaderboardEntry {
     name: string;
     points: number;
+    avatar: number;
+    color: number;
+    wins: number;
 }
 
 export interface LeaderboardPacket extends Packet {
     points: LeaderboardEntry[];
 }
 
+export interface PingPacket extends Packet {
+    serverTime: number;
+    seq: number;
+}
+
+export interface ResendPacket extends Packet {
+    from: number;
+}
+
+export interface StateDigestPacket extends Packet {
+    state: GameState;
+    question: number;
+    time: number;
+    endsAt: number;
+}
+
+export interface StateSnapshotPacket extends Packet {
+    state: GameState;
+    question: number;
+    time: number;
+    endsAt: number;
+    points: number;
+    choice: number;
+    playerQuestion?: PlayerQuestionPacket;
+    host?: HostGameState;
+}
+
+export interface PongPacket extends Packet {
+    serverTime: number;
+    clientTime: number;
+}
+
+export interface PlayerQuestionPacket extends Packet {
+    choices: number;
+    time: number;
+    serverTime: number;
+    endsAt: number;
+    clockOffset: number;
+    media: QuestionMedia[];
+    index: number;
+    total: number;
+    slots: ChoiceSlot[];
+    hint?: boolean; // The question has a hint the player may buy
+}
+
+export interface PlayerReport {
+    name: string;
+    points: number;
+    answered: number;
+    correct: number;
+    averageTime: number;
+    grade: PlayerGrade | null;
+    changed: number;
+    correctToWrong: number;
+    wrongToCorrect: number;
+    hints: number;
+    hintPoints: number;
+}
+
+export interface QuestionReport {
+    name: string;
+    answered: number;
+    correct: number;
+    averageTime: number;
+    maxPoints: number;
+    hints: number;
+}
+
+export interface HostAction {
+    at: string;
+    action: "start" | "skip" | "kick" | "ban" | "settings" | "invite";
+    question: number;
+    player?: string;
+    detail?: string;
+}
+
+export interface GameReportPacket extends Packet {
+    players: PlayerReport[];
+    questions: QuestionReport[];
+    maxPoints: number;
+    hostActions: HostAction[];
+}
+
+export interface AchievementPacket extends Packet {
+    event: "noCorrect" | "allCorrect" | "comeback";
+    players: string[];
+}
+
+export interface GameAward {
+    type: "fastestAnswer" | "longestStreak" | "biggestComeback";
+    player: string;
+    value: number;
+}
+
+export interface GameEndPacket extends Packet {
+    podium: LeaderboardEntry[];
+    awards: GameAward[];
+    grade: PlayerGrade | null;
+}
+
+export interface RecordPacket extends Packet {
+    name: string;
+    points: number;
+    previousRecord: number;
+}
+
+export interface TournamentStanding {
+    userId: string;
+    name: string;
+    points: number;
+    roundsPlayed: number;
+    eliminated: boolean;
+}
+
+export interface TournamentStandingsPacket extends Packet {
+    tournamentId: string;
+    name: string;
+    format: "points" | "bracket";
+    round: number;
+    rounds: number;
+    standings: TournamentStanding[];
+}
+
+export const LOBBY_EMOJIS = ["😀", "🔥", "🎉", "🤔", "😴", "🚀"];
+
+// Indexed by the avatar and color numbers sent with Connect
+export const AVATARS = ["🐶", "🐱", "🦊", "🐻", "🐼", "🐨", "🐯", "🦁", "🐸", "🐵", "🐧", "🐙"];
+export const PLAYER_COLORS = [
+    "bg-red-500",
+    "bg-orange-500",
+    "bg-yellow-500",
+    "bg-green-500",
+    "bg-teal-500",
+    "bg-blue-500",
+    "bg-pink-500",
+    "bg-gray-700",
+];
+
+export interface LobbyVotePacket extends Packet {
+    emoji: number;
+}
+
+export interface LobbyVotesPacket extends Packet {
+    votes: number[];
+}
+
+export interface NextQuestionPreviewPacket extends Packet {
+    index: number;
+    question: QuizQuestion;
+    correctChoices: number[];
+}
+
+export interface SendInvitationsPacket extends Packet {
+    emails: string[];
+}
+
+export interface Invitation {
+    id: string;
+    email: string;
+    code?: string;
+    status: "pending" | "sent" | "failed";
+    error?: string;
+    createdAt: string;
+    sentAt: string;
+}
+
+export interface InvitationStatusPacket extends Packet {
+    invitations: Invitation[];
+}
+
+export interface ResultLinkPacket extends Packet {
+    token: string;
+    expiresAt: string;
+}
+
+export interface PreloadMedia {
+    type: "image" | "audio" | "video";
+    url: string;
+    hash: string;
+}
+
+export interface PreloadPacket extends Packet {
+    question: number;
+    media: PreloadMedia[];
+}
+
+export interface LobbyStatsPacket extends Packet {
+    recentJoins: number;
+    total: number;
+    devices: { [device: string]: number };
+}
+
+export interface PlaylistPacket extends Packet {
+    index: number;
+    total: number;
+    quiz: string;
+    questions: number;
+    resetPoints: boolean;
+    leaderboard: LeaderboardEntry[];
+}
+
+export interface GameSettings {
+    answerChange: AnswerChangeMode;
+    scoring: ScoringMode;
+    penalty: number;
+    confidenceMode: boolean; // Players say how sure they are with each answer, weighing its points
+    allowNegative: boolean;
+    hintCost: number; // Points a hint costs, 0 for free hints
+    idleLimit: number;
+    removeIdle: boolean;
+    anonymizeResults: boolean;
+    examMode: boolean;
+    playerPaced: boolean;
+    buzzerMode: boolean;
+    tieBreaker: boolean;
+    matchRounds: number;
+    eliminationPercent: number;
+    allowedNetworks: string[] | null;
+    allowedCountries: string[] | null;
+    requireSignIn: boolean; // Players must be signed in and play under the name of their account
+    signInDomains: string[] | null; // Email domains the accounts must belong to, empty for any
+}
+
+export interface GameSettingsPacket extends Packet {
+    settings: GameSettings;
+}
+
+export interface GameInfoPacket extends Packet {
+    quiz: string;
+    questions: number;
+    question: number;
+    scoring: ScoringMode;
+    answerChange: AnswerChangeMode;
+}
+
+export interface FocusLostPacket extends Packet {}
+
+export interface PlayerFlagPacket extends Packet {
+    playerId: string;
+    question: number;
+    count: number;
+}
+
+export interface PacedProgressPacket extends Packet {
+    playerId: string;
+    done: number;
+    total: number;
+}
+
+export enum WarningKind {
+    Throttled = "throttled",
+    Connectivity = "connectivity"
+}
+
+export interface GameWarningPacket extends Packet {
+    kind: WarningKind;
+    message: string;
+    count?: number; // Players the warning is about, for connectivity warnings
+}
+
+export interface KickPlayerPacket extends Packet {
+    playerId: string;
+    ban: boolean;
+}
+
+export interface SessionPacket extends Packet {
+    token: string;
+}
+
+// The host sends the action, media and position; the server adds the rest before relaying it to the players
+export interface MediaCuePacket extends Packet {
+    action: "play" | "pause";
+    media: number;
+    position: number;
+    question?: number;
+    at?: number;
+    clockOffset?: number;
+}
+
+// The player presses the buzzer in a buzzer game; the host is sent the buzz order and judges the answers
+export interface BuzzPacket extends Packet {}
+
+export interface Buzz {
+    playerId: string;
+    name: string;
+    elapsed: number;
+    judged: boolean;
+    awarded: boolean;
+}
+
+export interface BuzzOrderPacket extends Packet {
+    question: number;
+    buzzes: Buzz[];
+}
+
+export interface JudgeBuzzPacket extends Packet {
+    playerId: string;
+    award: boolean;
+}
+
+// The players tied for first place at the end answer the quiz's tie-breaker question, the fastest correct answer wins
+export interface TieBreakerPacket extends Packet {
+    players: string[];
+    playing: boolean;
+}
+
+export interface MatchStanding {
+    name: string;
+    avatar: number;
+    color: number;
+    wins: number;
+    points: number;
+}
+
+// Sent when a round of a match ends; the points of the next round start from zero
+export interface MatchLeaderboardPacket extends Packet {
+    round: number;
+    rounds: number;
+    winners: string[];
+    standings: MatchStanding[];
+}
+
+// Eliminated players only watch the rest of the game
+export interface PlayerEliminatedPacket extends Packet {
+    question: number;
+    active: number;
+}
+
+export interface EliminationPacket extends Packet {
+    eliminated: string[];
+    active: number;
+    total: number;
+}
+
+// Sent by a player who leaves on purpose, the server removes them at once and closes with CloseCodes.Normal
+export interface LeaveGamePacket extends Packet {}
+
+// Sent by a player to buy the hint of the question they are answering
+export interface HintRequestPacket extends Packet {}
+
+// The hint a player bought, sent to them alone
+export interface HintPacket extends Packet {
+    question: number;
+    hint: string;
+    cost: number;
+}
+
+// Close codes the server disconnects with; 1000-1999 are standard, 4000 and up are specific to the quiz
+export enum CloseCodes {
+    Normal = 1000,
+    Shutdown = 1001,
+    Abnormal = 1006, // Set by the browser when the connection dropped without a close frame
+    Malformed = 1008,
+    Kicked = 4000,
+    Banned = 4001,
+    Idle = 4002,
+    NotAllowed = 4003,
+    ExamStarted = 4004,
+    GameFull = 4005,
+    GameEnded = 4006,
+    SessionExpired = 4007,
+    Hibernated = 4008,
+    SignInRequired = 4009,
+    TooManyAttempts = 4010,
+    EmailUnverified = 4011
+}
+
+export interface PlayerIdlePacket extends Packet {
+    playerId: string;
+    missed: number;
+    idle: boolean;
+    removed: boolean;
+}
+
+export interface AnswerAckPacket extends Packet {
+    question: number;
+    choice: number;
+    received: boolean;
+    locked: boolean;
+    serverTime: number;
+}
+
+export interface HostPlayerState {
+    id: string;
+    name: string;
+    avatar: number;
+    color: number;
+    points: number;
+    answered: boolean;
+    streak: number;
+}
+
+export interface HostGameState {
+    code: string;
+    quiz: Quiz;
+    state: GameState;
+    currentQuestion: number;
+    time: number;
+    paused: boolean;
+    ended: boolean;
+    settings: GameSettings;
+    playlistIndex: number;
+    playlistTotal: number;
+    players: HostPlayerState[];
+    disconnected: HostPlayerState[]; // Players who lost their connection and may still resume
+}
+
+export interface JoinCheck {
+    code: string;
+    quizName: string;
+    players: number;
+    inProgress: boolean;
+    full: boolean; // The host's plan allows no more players
+    requireSignIn: boolean;
+}
+
+export interface GamePausePacket extends Packet {
+    paused: boolean;
+    reason: string;
+}
+
 export class NetService {
 
     private webSocket!: WebSocket;
@@ -79,34 +631,117 @@ export class NetService {
     private textEncoder: TextEncoder = new TextEncoder();
 
     private onPacketCallback?: (packet: any) => void;
+    private onCloseCallback?: (code: number, reason: string) => void;
 
-    connect(){
-        this.webSocket = new WebSocket("ws://localhost:3000/ws");
+    // Screen changing packets carry sequence numbers; ones arriving after a gap wait for the missing ones
+    private lastSeq = 0;
+    private held: Map<number, any> = new Map();
+    private resendTimeout: number | null = null;
+
+    // Connects to the server, resuming the player's place in a game if a session token is given
+    connect(session?: string){
+        this.lastSeq = 0;
+        this.held.clear();
+
+        let query = session ? "?session=" + encodeURIComponent(session) : "";
+        this.webSocket = new WebSocket("ws://localhost:3000/ws" + query);
         this.webSocket.onopen = () => {
             console.log("opened connection");
         };
 
+        // The server says why it disconnected in the close frame, e.g. a kick or a restart
+        this.webSocket.onclose = (event: CloseEvent) => {
+            console.log("closed connection", event.code, event.reason);
+            if(this.onCloseCallback)
+                this.onCloseCallback(event.code, event.reason);
+        };
+
         this.webSocket.onmessage = async (event: MessageEvent) => {
             const arrayBuffer = await event.data.arrayBuffer();
             const bytes = new Uint8Array(arrayBuffer);  
             const packetId = bytes[0];
 
-            const packet = JSON.parse(this.textDecoder.decode(bytes.subarray(1)));
+            // Every packet comes in an envelope naming it and the game it is from, see PacketEnvelope
+            const envelope: PacketEnvelope = JSON.parse(this.textDecoder.decode(bytes.subarray(1)));
+            const packet = envelope.data;
 
             packet.id = packetId;
 
             console.log(packetId);
             console.log(packet);
 
-            if(this.onPacketCallback)
-                this.onPacketCallback(packet);
+            this.receive(packet);
+        }
+    }
+
+    private receive(packet: any){
+        if (packet.id == PacketTypes.Ping) {
+            if (packet.seq > this.lastSeq) {
+                this.requestResend();
+            }
+            this.deliver(packet);
+            return;
+        }
+
+        if (packet.seq === undefined) {
+            this.deliver(packet);
+            return;
+        }
+
+        if (packet.seq <= this.lastSeq) {
+            return;
+        }
+
+        this.held.set(packet.seq, packet);
+        this.drain();
+        if (this.held.size > 0) {
+            this.requestResend();
         }
     }
 
+    // Delivers held packets for as long as they follow on from the last one delivered
+    private drain(){
+        while (this.held.has(this.lastSeq + 1)) {
+            this.lastSeq++;
+            this.deliver(this.held.get(this.lastSeq));
+            this.held.delete(this.lastSeq);
+        }
+    }
+
+    private requestResend(){
+        if (this.resendTimeout != null) {
+            return;
+        }
+
+        let packet: ResendPacket = {
+            id: PacketTypes.Resend,
+            from: this.lastSeq,
+        };
+        this.sendPacket(packet);
+
+        // The server only keeps recent packets, skip what cannot be recovered
+        this.resendTimeout = setTimeout(() => {
+            this.resendTimeout = null;
+            if (this.held.size > 0) {
+                this.lastSeq = Math.min(...this.held.keys()) - 1;
+                this.drain();
+            }
+        }, 2000);
+    }
+
+    private deliver(packet: any){
+        if(this.onPacketCallback)
+            this.onPacketCallback(packet);
+    }
+
     onPacket(callback: (packet: Packet) => void){
         this.onPacketCallback = callback;
     }
 
+    onClose(callback: (code: number, reason: string) => void){
+        this.onCloseCallback = callback;
+    }
+
     sendPacket(packet: Packet) {
 		const packetId = packet.id;
 		const packetData = JSON.stringify(packet, (key, value) =>
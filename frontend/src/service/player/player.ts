@@ -1,32 +1,173 @@
-import { writable, type Writable } from "svelte/store";
-import { NetService, PacketTypes, type Packet, type ConnectPacket, GameState, type ChangeGameStatePacket, type QuestionAnswerPacket, type PlayerRevealPacket } from "../net";
+import { get, writable, type Writable } from "svelte/store";
+import { NetService, PacketTypes, type Packet, type ConnectPacket, GameState, type ChangeGameStatePacket, type QuestionAnswerPacket, type PlayerRevealPacket, type PingPacket, type PongPacket, type PlayerQuestionPacket, type GameEndPacket, type LobbyVotePacket, type LobbyVotesPacket, type PreloadPacket, type PlaylistPacket, type GameSettings, type GameSettingsPacket, AnswerChangeMode, ScoringMode, type GameInfoPacket, type AnswerAckPacket, type StateDigestPacket, type StateSnapshotPacket, type FocusLostPacket, type SessionPacket, type MediaCuePacket, type BuzzPacket, type TieBreakerPacket, type MatchLeaderboardPacket, type PlayerEliminatedPacket, type LeaveGamePacket, type HintRequestPacket, type HintPacket, type Confidence, CloseCodes } from "../net";
+import { preload } from "../preload";
+import { Countdown } from "../countdown";
 
 export const state: Writable<GameState> = writable(GameState.Lobby);
 export const points: Writable<number> = writable(0);
+export const averageTime: Writable<number> = writable(0);
+export const question: Writable<PlayerQuestionPacket | null> = writable(null);
+export const gameEnd: Writable<GameEndPacket | null> = writable(null);
+export const lobbyVotes: Writable<number[]> = writable([]);
+export const gameInfo: Writable<GameInfoPacket | null> = writable(null);
+export const answerAck: Writable<AnswerAckPacket | null> = writable(null);
+export const remaining: Writable<number> = writable(0);
+export const disconnected: Writable<string | null> = writable(null);
+export const mediaCue: Writable<MediaCuePacket | null> = writable(null);
+export const tieBreaker: Writable<TieBreakerPacket | null> = writable(null);
+export const matchLeaderboard: Writable<MatchLeaderboardPacket | null> = writable(null);
+// The hint the player bought for the current question
+export const hint: Writable<HintPacket | null> = writable(null);
+// Set once the player was knocked out of an elimination game and only watches
+export const eliminated: Writable<PlayerEliminatedPacket | null> = writable(null);
+// Whether the player is coming back to a game they joined before reloading the page
+export const resuming: Writable<boolean> = writable(false);
+export const settings: Writable<GameSettings> = writable({ answerChange: AnswerChangeMode.None, scoring: ScoringMode.Standard, penalty: 500, confidenceMode: false, allowNegative: false, hintCost: 0, idleLimit: 0, removeIdle: false, anonymizeResults: false, examMode: false, playerPaced: false, buzzerMode: false, tieBreaker: false, matchRounds: 0, eliminationPercent: 0, allowedNetworks: [], allowedCountries: [], requireSignIn: false, signInDomains: [] });
+
+// Rough device type of the player, reported to the host's lobby stats
+function detectDevice(): "phone" | "tablet" | "desktop" {
+    const agent = navigator.userAgent;
+    if (/iPad|Tablet/i.test(agent) || (/Android/i.test(agent) && !/Mobile/i.test(agent))) {
+        return "tablet";
+    }
+
+    return /Mobi|iPhone|Android/i.test(agent) ? "phone" : "desktop";
+}
+
+// How long to wait for an answer to be acknowledged before sending it again, and how often to try
+const ANSWER_RETRY_MS = 1500;
+const ANSWER_ATTEMPTS = 3;
+
+// Where the session token is kept across page reloads, and how often to try resuming after losing the connection
+const SESSION_KEY = "quizSession";
+const RECONNECT_MS = 2000;
+const RECONNECT_ATTEMPTS = 5;
 
 export class PlayerGame {
     private net: NetService;
+    private answerRetry: number | null = null;
+    private countdown = new Countdown(remaining);
+    private reconnects = 0;
 
     constructor(){
+        let session = sessionStorage.getItem(SESSION_KEY);
+        resuming.set(session != null);
+
         this.net = new NetService();
-        this.net.connect();
+        this.net.connect(session ?? undefined);
         this.net.onPacket(p => this.onPacket(p));
+        this.net.onClose((code, reason) => this.onClose(code, reason));
+
+        // Exams flag players who leave the game window, e.g. to look up answers in another tab
+        window.addEventListener("blur", () => this.onFocusLost());
     }
 
-    join(code: string, name: string){
+    private onClose(code: number, reason: string){
+        let session = sessionStorage.getItem(SESSION_KEY);
+
+        // The game forgot the player, so they join again like anyone else
+        if (code == CloseCodes.SessionExpired) {
+            sessionStorage.removeItem(SESSION_KEY);
+            resuming.set(false);
+            this.net.connect();
+            return;
+        }
+
+        // Connections that dropped without a reason are resumed while the session lasts
+        if (session && (code == CloseCodes.Shutdown || code == CloseCodes.Abnormal) && this.reconnects < RECONNECT_ATTEMPTS) {
+            this.reconnects++;
+            setTimeout(() => this.net.connect(session!), RECONNECT_MS);
+            return;
+        }
+
+        sessionStorage.removeItem(SESSION_KEY);
+        if (code != CloseCodes.Normal) disconnected.set(reason || "Lost the connection to the game");
+    }
+
+    private onFocusLost(){
+        let current = get(state);
+        if (!get(settings).examMode || current == GameState.Lobby || current == GameState.End) return;
+
+        let packet: FocusLostPacket = {
+            id: PacketTypes.FocusLost
+        };
+
+        this.net.sendPacket(packet);
+    }
+
+    // Games requiring sign in take the access token of the player's account and show its name instead
+    join(code: string, name: string, avatar: number, color: number, token?: string){
         let packet: ConnectPacket = {
             id: PacketTypes.Connect,
             code: code,
             name: name,
+            avatar: avatar,
+            color: color,
+            device: detectDevice(),
+            token: token,
         }
 
         this.net.sendPacket(packet);
     }
 
-    answer(question: number){
+    answer(question: number, confidence?: Confidence){
         let packet: QuestionAnswerPacket = {
             id: PacketTypes.Answer,
-            question: question
+            question: question,
+            type: "index",
+            payload: { index: question },
+            confidence: confidence,
+        };
+
+        answerAck.set(null);
+        this.clearAnswerRetry();
+        this.sendAnswer(packet, ANSWER_ATTEMPTS);
+    }
+
+    // Sends an answer and sends it again if the server does not acknowledge it in time
+    private sendAnswer(packet: QuestionAnswerPacket, attempts: number){
+        this.net.sendPacket(packet);
+        if (attempts > 1) {
+            this.answerRetry = setTimeout(() => this.sendAnswer(packet, attempts - 1), ANSWER_RETRY_MS);
+        }
+    }
+
+    private clearAnswerRetry(){
+        if (this.answerRetry != null) {
+            clearTimeout(this.answerRetry);
+            this.answerRetry = null;
+        }
+    }
+
+    leave(){
+        let packet: LeaveGamePacket = {
+            id: PacketTypes.LeaveGame
+        };
+
+        sessionStorage.removeItem(SESSION_KEY);
+        this.net.sendPacket(packet);
+    }
+
+    buzz(){
+        let packet: BuzzPacket = {
+            id: PacketTypes.Buzz
+        };
+
+        this.net.sendPacket(packet);
+    }
+
+    requestHint(){
+        let packet: HintRequestPacket = {
+            id: PacketTypes.HintRequest
+        };
+
+        this.net.sendPacket(packet);
+    }
+
+    vote(emoji: number){
+        let packet: LobbyVotePacket = {
+            id: PacketTypes.LobbyVote,
+            emoji: emoji
         };
 
         this.net.sendPacket(packet);
@@ -36,12 +177,112 @@ export class PlayerGame {
         switch(packet.id){
             case PacketTypes.ChangeGameState:{
                 let data = packet as ChangeGameStatePacket;
+                this.clearAnswerRetry();
+                state.set(data.state);
+                this.countdown.set(data.endsAt);
+                break;
+            }
+            case PacketTypes.StateDigest:{
+                let data = packet as StateDigestPacket;
+                let shown = get(question);
+                if (data.state != get(state) || (data.state == GameState.Play && shown?.index != data.question)) {
+                    this.net.sendPacket({ id: PacketTypes.ResyncRequest });
+                }
+                this.countdown.set(data.endsAt);
+                break;
+            }
+            case PacketTypes.StateSnapshot:{
+                let data = packet as StateSnapshotPacket;
+                if (data.playerQuestion) {
+                    question.set(data.playerQuestion);
+                }
+                this.countdown.set(data.endsAt);
+                points.set(data.points);
                 state.set(data.state);
                 break;
             }
+            case PacketTypes.AnswerAck:{
+                this.clearAnswerRetry();
+                answerAck.set(packet as AnswerAckPacket);
+                break;
+            }
             case PacketTypes.PlayerReveal:{
                 let data = packet as PlayerRevealPacket;
                 points.set(data.points);
+                averageTime.set(data.averageTime);
+                this.countdown.set(data.endsAt);
+                break;
+            }
+            case PacketTypes.Ping:{
+                let data = packet as PingPacket;
+                let pong: PongPacket = {
+                    id: PacketTypes.Pong,
+                    serverTime: data.serverTime,
+                    clientTime: Date.now(),
+                };
+                this.net.sendPacket(pong);
+                break;
+            }
+            case PacketTypes.PlayerQuestion:{
+                let data = packet as PlayerQuestionPacket;
+                question.set(data);
+                hint.set(null);
+                matchLeaderboard.set(null);
+                this.countdown.set(data.endsAt, data.clockOffset);
+                break;
+            }
+            case PacketTypes.Hint: {
+                let data = packet as HintPacket;
+                hint.set(data);
+                points.update(p => p - data.cost);
+                break;
+            }
+            case PacketTypes.GameEnd: {
+                gameEnd.set(packet as GameEndPacket);
+                break;
+            }
+            case PacketTypes.LobbyVotes: {
+                let data = packet as LobbyVotesPacket;
+                lobbyVotes.set(data.votes);
+                break;
+            }
+            case PacketTypes.Preload: {
+                preload((packet as PreloadPacket).media);
+                break;
+            }
+            case PacketTypes.Session: {
+                sessionStorage.setItem(SESSION_KEY, (packet as SessionPacket).token);
+                this.reconnects = 0;
+                break;
+            }
+            case PacketTypes.MediaCue: {
+                mediaCue.set(packet as MediaCuePacket);
+                break;
+            }
+            case PacketTypes.TieBreaker: {
+                tieBreaker.set(packet as TieBreakerPacket);
+                break;
+            }
+            case PacketTypes.PlayerEliminated: {
+                eliminated.set(packet as PlayerEliminatedPacket);
+                break;
+            }
+            case PacketTypes.MatchLeaderboard: {
+                matchLeaderboard.set(packet as MatchLeaderboardPacket);
+                break;
+            }
+            case PacketTypes.GameInfo: {
+                gameInfo.set(packet as GameInfoPacket);
+                break;
+            }
+            case PacketTypes.GameSettings: {
+                settings.set((packet as GameSettingsPacket).settings);
+                break;
+            }
+            case PacketTypes.Playlist: {
+                if ((packet as PlaylistPacket).resetPoints) {
+                    points.set(0);
+                }
                 break;
             }
         }
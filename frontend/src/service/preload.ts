@@ -0,0 +1,26 @@
+import type { PreloadMedia } from "./net";
+
+// Keeps fetched files referenced until the page goes away, so the browser cache holds on to them
+const preloaded = new Map<string, HTMLImageElement | HTMLMediaElement>();
+
+export function preload(media: PreloadMedia[]){
+    for (let item of media) {
+        if (preloaded.has(item.url)) continue;
+
+        if (item.type == "image") {
+            let image = new Image();
+            image.src = item.url;
+            preloaded.set(item.url, image);
+        } else if (item.type == "audio") {
+            let audio = new Audio();
+            audio.preload = "auto";
+            audio.src = item.url;
+            preloaded.set(item.url, audio);
+        } else if (item.type == "video") {
+            let video = document.createElement("video");
+            video.preload = "auto";
+            video.src = item.url;
+            preloaded.set(item.url, video);
+        }
+    }
+}